@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// policyDeprecation is a usage of the SriovNetworkNodePolicy API kept for the clients of the previous versions of
+// the operator, the webhook warns the clients still relying on it
+type policyDeprecation struct {
+	// Field is the path of the deprecated field
+	Field string
+	// Usage describes what is deprecated in the field
+	Usage string
+	// Replacement describes what replaces it
+	Replacement string
+	// Values returns the deprecated values of the field set in the policy
+	Values func(p *SriovNetworkNodePolicy) []string
+}
+
+var policyDeprecations = []policyDeprecation{
+	{
+		Field:       "spec.nicSelector.pfNames",
+		Usage:       "the VF range encoded in the PF name",
+		Replacement: "the vfRange of spec.nicSelector.pfs in sriovnetwork.openshift.io/v2",
+		Values: func(p *SriovNetworkNodePolicy) []string {
+			values := []string{}
+			for _, name := range p.Spec.NicSelector.PfNames {
+				if strings.Contains(name, "#") {
+					values = append(values, name)
+				}
+			}
+			return values
+		},
+	},
+	{
+		Field:       "spec.nicSelector.netFilter",
+		Usage:       "the string encoded OpenStack network",
+		Replacement: "the openstackNetworkID of spec.nicSelector.netFilter in sriovnetwork.openshift.io/v2",
+		Values: func(p *SriovNetworkNodePolicy) []string {
+			prefix, _, err := ParseNetFilter(p.Spec.NicSelector.NetFilter)
+			if err != nil || prefix != OpenstackNetworkID.String() {
+				return nil
+			}
+			return []string{p.Spec.NicSelector.NetFilter}
+		},
+	},
+}
+
+// DeprecationWarnings returns a warning for each deprecated value set in the policy
+func (p *SriovNetworkNodePolicy) DeprecationWarnings() []string {
+	warnings := []string{}
+	for _, d := range policyDeprecations {
+		for _, value := range d.Values(p) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s, %s, is deprecated, use %s", d.Field, d.Usage, value, d.Replacement))
+		}
+	}
+	return warnings
+}
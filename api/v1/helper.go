@@ -4,19 +4,29 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -26,11 +36,24 @@ import (
 )
 
 const (
-	LASTNETWORKNAMESPACE    = "operator.sriovnetwork.openshift.io/last-network-namespace"
-	NETATTDEFFINALIZERNAME  = "netattdef.finalizers.sriovnetwork.openshift.io"
-	POOLCONFIGFINALIZERNAME = "poolconfig.finalizers.sriovnetwork.openshift.io"
-	ESwithModeLegacy        = "legacy"
-	ESwithModeSwitchDev     = "switchdev"
+	LASTNETWORKNAMESPACE     = "operator.sriovnetwork.openshift.io/last-network-namespace"
+	NETATTDEFFINALIZERNAME   = "netattdef.finalizers.sriovnetwork.openshift.io"
+	POOLCONFIGFINALIZERNAME  = "poolconfig.finalizers.sriovnetwork.openshift.io"
+	NETWORKTESTFINALIZERNAME = "networktest.finalizers.sriovnetwork.openshift.io"
+	ESwithModeLegacy         = "legacy"
+	ESwithModeSwitchDev      = "switchdev"
+
+	AllocationPolicySpread = "spread"
+	AllocationPolicyPack   = "pack"
+
+	DrainModeDrain  = "Drain"
+	DrainModeCordon = "Cordon"
+	DrainModeNone   = "None"
+
+	FlowActionTrap     = "trap"
+	FlowActionDrop     = "drop"
+	FlowActionMirror   = "mirror"
+	FlowActionRedirect = "redirect"
 
 	SriovCniStateEnable  = "enable"
 	SriovCniStateDisable = "disable"
@@ -39,6 +62,14 @@ const (
 	SriovCniStateOn      = "on"
 	SriovCniIpam         = "\"ipam\""
 	SriovCniIpamEmpty    = SriovCniIpam + ":{}"
+
+	// DefaultCniVersion is the CNI specification version of the networks without cniVersion
+	DefaultCniVersion = "0.3.1"
+
+	// OvnHardwareOffloadDefaultNumVfs is the number of VFs of each PF of the hardware offload of OVN-Kubernetes
+	OvnHardwareOffloadDefaultNumVfs = 8
+	// OvnHardwareOffloadDefaultResourceName is the resource name of the VFs of the hardware offload of OVN-Kubernetes
+	OvnHardwareOffloadDefaultResourceName = "ovn_hwol"
 )
 
 const invalidVfIndex = -1
@@ -235,6 +266,16 @@ func FindInterface(interfaces Interfaces, name string) (iface Interface, err err
 }
 
 func NeedToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	return needToUpdateSriov(ifaceSpec, ifaceStatus, true)
+}
+
+// NeedToDrainForSriov returns true if the update of the PF disrupts the workloads of the node, the hardware
+// timestamping of the VFs is enabled without disrupting their traffic
+func NeedToDrainForSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	return needToUpdateSriov(ifaceSpec, ifaceStatus, false)
+}
+
+func needToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt, hwTimestamping bool) bool {
 	if ifaceSpec.Mtu > 0 {
 		mtu := ifaceSpec.Mtu
 		if mtu != ifaceStatus.Mtu {
@@ -243,16 +284,33 @@ func NeedToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
 		}
 	}
 
+	if ifaceSpec.PortSplit > 0 && ifaceSpec.PortSplit != max(ifaceStatus.PortSplit, 1) {
+		log.V(2).Info("NeedToUpdateSriov(): port split needs update", "desired", ifaceSpec.PortSplit, "current", ifaceStatus.PortSplit)
+		return true
+	}
+
 	if ifaceSpec.NumVfs != ifaceStatus.NumVfs {
 		log.V(2).Info("NeedToUpdateSriov(): NumVfs needs update", "desired", ifaceSpec.NumVfs, "current", ifaceStatus.NumVfs)
 		return true
 	}
+	// the kernel drivers only probe the VFs when they are created, the VFs are created again when the setting changes
+	if ifaceSpec.NumVfs > 0 && !ifaceSpec.ExternallyManaged &&
+		ifaceSpec.DisableDriversAutoprobe != ifaceStatus.DriversAutoprobeDisabled {
+		log.V(2).Info("NeedToUpdateSriov(): drivers autoprobe needs update",
+			"desired", !ifaceSpec.DisableDriversAutoprobe, "current", !ifaceStatus.DriversAutoprobeDisabled)
+		return true
+	}
 	if ifaceSpec.NumVfs > 0 {
-		for _, vfStatus := range ifaceStatus.VFs {
+		for _, vfStatus := range ifaceStatus.GetVirtualFunctions() {
 			ingroup := false
 			for _, groupSpec := range ifaceSpec.VfGroups {
 				if IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
 					ingroup = true
+					if groupSpec.MsixCount > 0 && vfStatus.MsixCount > 0 && groupSpec.MsixCount != vfStatus.MsixCount {
+						log.V(2).Info("NeedToUpdateSriov(): VF MSI-X vectors need update",
+							"vf", vfStatus.VfID, "desired", groupSpec.MsixCount, "current", vfStatus.MsixCount)
+						return true
+					}
 					if groupSpec.DeviceType != consts.DeviceTypeNetDevice {
 						if groupSpec.DeviceType != vfStatus.Driver {
 							log.V(2).Info("NeedToUpdateSriov(): Driver needs update",
@@ -270,6 +328,11 @@ func NeedToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
 								"vf", vfStatus.VfID, "desired", groupSpec.Mtu, "current", vfStatus.Mtu)
 							return true
 						}
+						// the VFs not supporting the hardware timestamping are left as they are
+						if hwTimestamping && groupSpec.HwTimestamping && vfStatus.HwTimestamping == consts.HwTimestampingSupported {
+							log.V(2).Info("NeedToUpdateSriov(): VF hardware timestamping needs update", "vf", vfStatus.VfID)
+							return true
+						}
 
 						// this is needed to be sure the admin mac address is configured as expected
 						if ifaceSpec.ExternallyManaged {
@@ -318,6 +381,289 @@ func (p *SriovNetworkNodePolicy) Selected(node *corev1.Node) bool {
 	return true
 }
 
+// Selected returns true if the node is selected by the NodeSelector of the pool, a pool without
+// or with an invalid selector selects no node
+func (p *SriovNetworkPoolConfig) Selected(node *corev1.Node) bool {
+	if p.Spec.NodeSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(p.Spec.NodeSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(node.Labels))
+}
+
+// DrainMode returns the drain mode of the nodes of the pool, drainSkip stands for Cordon
+func (p *SriovNetworkPoolConfig) DrainMode() string {
+	if p.Spec.DrainMode == "" && p.Spec.DrainSkip {
+		return DrainModeCordon
+	}
+	return p.Spec.DrainMode
+}
+
+// ValidateDrainMode rejects a drainSkip contradicting the drainMode of the pool
+func (p *SriovNetworkPoolConfig) ValidateDrainMode() error {
+	if p.Spec.DrainSkip && p.Spec.DrainMode != "" && p.Spec.DrainMode != DrainModeCordon {
+		return fmt.Errorf("drainSkip cordons the nodes of the pool and conflicts with the %s drainMode", p.Spec.DrainMode)
+	}
+	return nil
+}
+
+// ValidateOvnHardwareOffload checks the pool selects its nodes by labels only, the node selector of the policies of
+// the hardware offload of OVN-Kubernetes being a map of labels, and selects whole PFs
+func (p *SriovNetworkPoolConfig) ValidateOvnHardwareOffload() error {
+	if p.Spec.OvnHardwareOffload == nil {
+		return nil
+	}
+	selector := p.Spec.NodeSelector
+	if selector == nil || len(selector.MatchLabels) == 0 || len(selector.MatchExpressions) > 0 {
+		return fmt.Errorf("ovnHardwareOffload requires a nodeSelector with matchLabels only")
+	}
+	if p.Spec.OvsHardwareOffloadConfig.Name != "" {
+		return fmt.Errorf("ovnHardwareOffload can't be combined with ovsHardwareOffloadConfig")
+	}
+	for _, name := range p.Spec.OvnHardwareOffload.PfNames {
+		if name == "" || strings.Contains(name, "#") {
+			return fmt.Errorf("invalid PF name %q in ovnHardwareOffload, the whole PFs are configured", name)
+		}
+	}
+	return nil
+}
+
+// OvnHardwareOffloadResourceNames returns the resource names of the VFs of the pods and of the management VF of the
+// hardware offload of OVN-Kubernetes
+func (p *SriovNetworkPoolConfig) OvnHardwareOffloadResourceNames() (string, string) {
+	resourceName := OvnHardwareOffloadDefaultResourceName
+	if p.Spec.OvnHardwareOffload != nil && p.Spec.OvnHardwareOffload.ResourceName != "" {
+		resourceName = p.Spec.OvnHardwareOffload.ResourceName
+	}
+	return resourceName, resourceName + "_mgmt"
+}
+
+// OvnHardwareOffloadPolicies renders the policies of the hardware offload of OVN-Kubernetes: the PFs in switchdev,
+// the first VF of the first PF as the management VF of the node and the other VFs for the pods
+func (p *SriovNetworkPoolConfig) OvnHardwareOffloadPolicies() ([]SriovNetworkNodePolicy, error) {
+	if p.Spec.OvnHardwareOffload == nil {
+		return nil, nil
+	}
+	if err := p.ValidateOvnHardwareOffload(); err != nil {
+		return nil, err
+	}
+	cfg := p.Spec.OvnHardwareOffload
+	numVfs := cfg.NumVfs
+	if numVfs == 0 {
+		numVfs = OvnHardwareOffloadDefaultNumVfs
+	}
+	resourceName, mgmtResourceName := p.OvnHardwareOffloadResourceNames()
+
+	pfNames := append([]string{fmt.Sprintf("%s#1-%d", cfg.PfNames[0], numVfs-1)}, cfg.PfNames[1:]...)
+	policy := func(name, resourceName string, pfNames []string) SriovNetworkNodePolicy {
+		return SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: p.Namespace,
+				Labels:    map[string]string{consts.OvnHardwareOffloadPoolLabel: p.Name},
+			},
+			Spec: SriovNetworkNodePolicySpec{
+				ResourceName: resourceName,
+				NodeSelector: p.Spec.NodeSelector.MatchLabels,
+				NumVfs:       numVfs,
+				NicSelector:  SriovNetworkNicSelector{PfNames: pfNames},
+				DeviceType:   consts.DeviceTypeNetDevice,
+				EswitchMode:  ESwithModeSwitchDev,
+			},
+		}
+	}
+	return []SriovNetworkNodePolicy{
+		policy("ovn-hwol-"+p.Name+"-mgmt", mgmtResourceName, []string{cfg.PfNames[0] + "#0-0"}),
+		policy("ovn-hwol-"+p.Name, resourceName, pfNames),
+	}, nil
+}
+
+// TracingEndpoint returns the OTLP collector endpoint the traces are exported to, empty if tracing is disabled
+func (c *SriovOperatorConfig) TracingEndpoint() string {
+	if c.Spec.Tracing == nil {
+		return ""
+	}
+	return c.Spec.Tracing.Endpoint
+}
+
+// Selects returns true if the namespace is selected by the NamespaceSelector of the grant, a grant
+// with an invalid selector selects no namespace
+func (g *SriovNetworkGrant) Selects(ns *corev1.Namespace) bool {
+	if g.Spec.NamespaceSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(g.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.Labels))
+}
+
+// Allows returns an error if the SriovNetwork uses a resource pool, a VLAN, an IPAM block, a VF setting or a CNI
+// capability not granted
+func (g *SriovNetworkGrant) Allows(network *SriovNetwork) error {
+	if !StringInArray(network.Spec.ResourceName, g.Spec.ResourceNames) {
+		return fmt.Errorf("resource %s is not granted", network.Spec.ResourceName)
+	}
+	sharing := network.Spec.AddressSharing
+	if !g.Spec.AllowTrust && (network.Spec.Trust == SriovCniStateOn || (sharing != nil && sharing.MultipleUnicastMacs)) {
+		return fmt.Errorf("trusted VFs are not granted")
+	}
+	if !g.Spec.AllowSpoofChkOff && (network.Spec.SpoofChk == SriovCniStateOff || (sharing != nil && sharing.AllowSpoofing)) {
+		return fmt.Errorf("disabling the spoof checking is not granted")
+	}
+	if !g.Spec.AllowLinkState && network.Spec.LinkState != "" && network.Spec.LinkState != SriovCniStateAuto {
+		return fmt.Errorf("linkState %s is not granted", network.Spec.LinkState)
+	}
+	if network.Spec.Capabilities != "" {
+		capabilities := map[string]bool{}
+		if err := json.Unmarshal([]byte(network.Spec.Capabilities), &capabilities); err != nil {
+			return fmt.Errorf("failed to parse the capabilities: %v", err)
+		}
+		for name, enabled := range capabilities {
+			if enabled && !StringInArray(name, g.Spec.Capabilities) {
+				return fmt.Errorf("capability %s is not granted", name)
+			}
+		}
+	}
+	if len(g.Spec.VlanRanges) > 0 {
+		granted := false
+		for _, r := range g.Spec.VlanRanges {
+			if r.Contains(network.Spec.Vlan) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return fmt.Errorf("VLAN %d is not granted", network.Spec.Vlan)
+		}
+	}
+	if len(g.Spec.IPAMBlocks) > 0 && network.Spec.IPAM != "" {
+		subnets, err := ipamSubnets(network.Spec.IPAM)
+		if err != nil {
+			return err
+		}
+		if len(subnets) == 0 {
+			return fmt.Errorf("IPAM configuration doesn't declare its addresses")
+		}
+		for _, subnet := range subnets {
+			if !subnetInBlocks(subnet, g.Spec.IPAMBlocks) {
+				return fmt.Errorf("IPAM subnet %s is not granted", subnet)
+			}
+		}
+	}
+	return nil
+}
+
+// Contains returns true if the VLAN is in the range, an invalid range contains no VLAN
+func (r VlanRange) Contains(vlan int) bool {
+	first, last, found := strings.Cut(string(r), "-")
+	if !found {
+		last = first
+	}
+	low, err := strconv.Atoi(first)
+	if err != nil {
+		return false
+	}
+	high, err := strconv.Atoi(last)
+	if err != nil {
+		return false
+	}
+	return vlan >= low && vlan <= high
+}
+
+// ValidateTenantNetwork checks a SriovNetwork created by a namespace owner outside of the operator namespace
+// against the SriovNetworkGrants of its namespace, the network must be allowed by at least one of them
+func ValidateTenantNetwork(network *SriovNetwork, ns *corev1.Namespace, grants []SriovNetworkGrant) error {
+	if network.Spec.NetworkNamespace != "" && network.Spec.NetworkNamespace != network.Namespace {
+		return fmt.Errorf("the networkNamespace of a SriovNetwork created in namespace %s must be empty or %s",
+			network.Namespace, network.Namespace)
+	}
+	if network.Spec.MetaPluginsConfig != "" {
+		return fmt.Errorf("metaPlugins are not allowed in the SriovNetworks created outside of the operator namespace")
+	}
+	if network.Spec.ResourceInjection != nil {
+		return fmt.Errorf("resourceInjection is not allowed in the SriovNetworks created outside of the operator namespace")
+	}
+
+	reasons := []string{}
+	for i := range grants {
+		grant := &grants[i]
+		if !grant.Selects(ns) {
+			continue
+		}
+		err := grant.Allows(network)
+		if err == nil {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %v", grant.Name, err))
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("no SriovNetworkGrant allows SriovNetworks in namespace %s", ns.Name)
+	}
+	return fmt.Errorf("SriovNetwork is not allowed by the SriovNetworkGrants of namespace %s: %s",
+		ns.Name, strings.Join(reasons, "; "))
+}
+
+// ipamSubnets returns the subnets of the IPAM configuration, declared in the subnet, range or address
+// fields of the host-local, whereabouts and static IPAM plugins
+func ipamSubnets(ipam string) ([]*net.IPNet, error) {
+	var config interface{}
+	if err := json.Unmarshal([]byte(ipam), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the IPAM configuration: %v", err)
+	}
+	subnets := []*net.IPNet{}
+	var walk func(value interface{}) error
+	walk = func(value interface{}) error {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, field := range v {
+				if cidr, ok := field.(string); ok && (key == "subnet" || key == "range" || key == "address") {
+					_, subnet, err := net.ParseCIDR(cidr)
+					if err != nil {
+						return fmt.Errorf("invalid IPAM %s %s: %v", key, cidr, err)
+					}
+					subnets = append(subnets, subnet)
+					continue
+				}
+				if err := walk(field); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(config); err != nil {
+		return nil, err
+	}
+	return subnets, nil
+}
+
+// subnetInBlocks returns true if the subnet is included in one of the CIDR blocks
+func subnetInBlocks(subnet *net.IPNet, blocks []string) bool {
+	ones, bits := subnet.Mask.Size()
+	for _, b := range blocks {
+		_, block, err := net.ParseCIDR(b)
+		if err != nil {
+			continue
+		}
+		blockOnes, blockBits := block.Mask.Size()
+		if blockBits == bits && blockOnes <= ones && block.Contains(subnet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
 func StringInArray(val string, array []string) bool {
 	for i := range array {
 		if array[i] == val {
@@ -327,6 +673,78 @@ func StringInArray(val string, array []string) bool {
 	return false
 }
 
+// NormalizePciAddress returns the PCI address in the format of the sysfs, with a lowercase domain of at least
+// four digits: 000C:01:00.0 becomes 000c:01:00.0 and 01:00.0 becomes 0000:01:00.0. The arm64 servers usually
+// have several PCI domains, and some platforms have domains wider than four digits.
+func NormalizePciAddress(addr string) string {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	parts := strings.Split(addr, ":")
+	switch len(parts) {
+	case 2:
+		return "0000:" + addr
+	case 3:
+		if len(parts[0]) < 4 {
+			parts[0] = strings.Repeat("0", 4-len(parts[0])) + parts[0]
+		}
+		return strings.Join(parts, ":")
+	}
+	return addr
+}
+
+// NormalizePciAddresses returns the normalized copy of the PCI addresses
+func NormalizePciAddresses(addrs []string) []string {
+	normalized := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		normalized = append(normalized, NormalizePciAddress(addr))
+	}
+	return normalized
+}
+
+// PciAddressInArray returns true if the PCI address is in the array, whatever the format of their domains
+func PciAddressInArray(addr string, array []string) bool {
+	addr = NormalizePciAddress(addr)
+	for i := range array {
+		if NormalizePciAddress(array[i]) == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ZpciUIDInArray returns true if the zPCI UID is in the array, the UIDs are compared as hexadecimal numbers
+// so 0x1F, 0x001f and 1f are the same UID
+func ZpciUIDInArray(uid string, array []string) bool {
+	if uid == "" {
+		return false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(uid), "0x"), 16, 32)
+	if err != nil {
+		return false
+	}
+	for i := range array {
+		other, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(array[i]), "0x"), 16, 32)
+		if err == nil && other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeOperatingSystem returns the operating system of the node, from its kubernetes.io/os label or its node info
+func NodeOperatingSystem(node *corev1.Node) string {
+	if nodeOS, ok := node.Labels[consts.NodeOSLabel]; ok {
+		return nodeOS
+	}
+	return node.Status.NodeInfo.OperatingSystem
+}
+
+// IsSupportedNode returns true if the operator can configure the node, i.e. its operating system is supported
+func IsSupportedNode(node *corev1.Node) bool {
+	nodeOS := NodeOperatingSystem(node)
+	// the nodes which don't report their operating system are expected to be Linux nodes
+	return nodeOS == "" || StringInArray(nodeOS, vars.SupportedNodeOperatingSystems)
+}
+
 func RemoveString(s string, slice []string) (result []string, found bool) {
 	if len(slice) != 0 {
 		for _, item := range slice {
@@ -353,21 +771,34 @@ func UniqueAppend(inSlice []string, strings ...string) []string {
 func (p *SriovNetworkNodePolicy) Apply(state *SriovNetworkNodeState, equalPriority bool) error {
 	s := p.Spec.NicSelector
 	if s.Vendor == "" && s.DeviceID == "" && len(s.RootDevices) == 0 && len(s.PfNames) == 0 &&
-		len(s.NetFilter) == 0 {
+		len(s.NetFilter) == 0 && len(s.ZpciUids) == 0 {
 		// Empty NicSelector match none
 		return nil
 	}
+	selected := []string{}
+	defer func() { p.latchSelectedPfs(state, selected) }()
 	for _, iface := range state.Status.Interfaces {
-		if s.Selected(&iface) {
+		if p.SelectedPf(state, &iface) {
+			selected = append(selected, iface.PciAddress)
 			log.Info("Update interface", "name:", iface.Name)
 			result := Interface{
-				PciAddress:        iface.PciAddress,
-				Mtu:               p.Spec.Mtu,
-				Name:              iface.Name,
-				LinkType:          p.Spec.LinkType,
-				EswitchMode:       p.Spec.EswitchMode,
-				NumVfs:            p.Spec.NumVfs,
-				ExternallyManaged: p.Spec.ExternallyManaged,
+				PciAddress:               iface.PciAddress,
+				Mtu:                      p.Spec.Mtu,
+				Name:                     iface.Name,
+				LinkType:                 p.Spec.LinkType,
+				EswitchMode:              p.Spec.EswitchMode,
+				NumVfs:                   p.Spec.NumVfs,
+				ExternallyManaged:        p.Spec.ExternallyManaged,
+				FlowRules:                p.Spec.FlowRules,
+				Qos:                      p.Spec.Qos,
+				CongestionControl:        p.Spec.CongestionControl,
+				AllowPtpSourceDisruption: p.Spec.AllowPtpSourceDisruption,
+				DisableDriversAutoprobe:  p.Spec.DisableDriversAutoprobe,
+				PortSplit:                p.Spec.PortSplit,
+			}
+			// the policies splitting the port of the PF configure it without VFs
+			if p.Spec.NumVfs == 0 && p.Spec.PortSplit == 0 {
+				continue
 			}
 			if p.Spec.NumVfs > 0 {
 				group, err := p.generateVfGroup(&iface)
@@ -375,24 +806,109 @@ func (p *SriovNetworkNodePolicy) Apply(state *SriovNetworkNodeState, equalPriori
 					return err
 				}
 				result.VfGroups = []VfGroup{*group}
-				found := false
-				for i := range state.Spec.Interfaces {
-					if state.Spec.Interfaces[i].PciAddress == result.PciAddress {
-						found = true
-						state.Spec.Interfaces[i].mergeConfigs(&result, equalPriority)
-						state.Spec.Interfaces[i] = result
-						break
+				if p.Spec.Mirror != nil {
+					rules, err := p.mirrorFlowRules(group)
+					if err != nil {
+						return err
 					}
+					result.FlowRules = append(append([]FlowRule{}, p.Spec.FlowRules...), rules...)
 				}
-				if !found {
-					state.Spec.Interfaces = append(state.Spec.Interfaces, result)
+			}
+			found := false
+			for i := range state.Spec.Interfaces {
+				if state.Spec.Interfaces[i].PciAddress == result.PciAddress {
+					found = true
+					state.Spec.Interfaces[i].mergeConfigs(&result, equalPriority)
+					state.Spec.Interfaces[i] = result
+					break
 				}
 			}
+			if !found {
+				state.Spec.Interfaces = append(state.Spec.Interfaces, result)
+			}
 		}
 	}
 	return nil
 }
 
+// latchedPfs is the entry of a policy in the SelectedPfsAnnotation of a node state
+type latchedPfs struct {
+	Generation   int64    `json:"generation"`
+	PciAddresses []string `json:"pciAddresses"`
+}
+
+// hasLinkStateSelector returns true if the policy selects the PFs by link state or transceiver presence
+func (p *SriovNetworkNodePolicy) hasLinkStateSelector() bool {
+	return p.Spec.NicSelector.LinkUp || p.Spec.NicSelector.TransceiverPresent
+}
+
+// getLatchedPfs returns the entries of the SelectedPfsAnnotation of the node state
+func getLatchedPfs(state *SriovNetworkNodeState) map[string]latchedPfs {
+	latched := map[string]latchedPfs{}
+	if value := state.GetAnnotations()[consts.SelectedPfsAnnotation]; value != "" {
+		if err := json.Unmarshal([]byte(value), &latched); err != nil {
+			log.Error(err, "ignoring the invalid selected PFs annotation", "node", state.Name)
+			return map[string]latchedPfs{}
+		}
+	}
+	return latched
+}
+
+func setLatchedPfs(state *SriovNetworkNodeState, latched map[string]latchedPfs) {
+	annotations := state.GetAnnotations()
+	if len(latched) == 0 {
+		delete(annotations, consts.SelectedPfsAnnotation)
+		return
+	}
+	value, _ := json.Marshal(latched)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[consts.SelectedPfsAnnotation] = string(value)
+	state.SetAnnotations(annotations)
+}
+
+// SelectedPf returns true if the nicSelector of the policy selects the PF of the node state. The PFs once selected
+// by the linkUp and transceiverPresent predicates stay selected until the policy changes, a link flap or an optic
+// swap would otherwise remove the PF from the spec and tear down its VFs.
+func (p *SriovNetworkNodePolicy) SelectedPf(state *SriovNetworkNodeState, iface *InterfaceExt) bool {
+	if p.Spec.NicSelector.Selected(iface) {
+		return true
+	}
+	if !p.hasLinkStateSelector() || iface.Unmanaged {
+		return false
+	}
+	latched, ok := getLatchedPfs(state)[p.Name]
+	return ok && latched.Generation == p.Generation && StringInArray(iface.PciAddress, latched.PciAddresses)
+}
+
+// latchSelectedPfs records the PFs selected by the policy in the node state when it selects them by link state or
+// transceiver presence
+func (p *SriovNetworkNodePolicy) latchSelectedPfs(state *SriovNetworkNodeState, pciAddresses []string) {
+	if !p.hasLinkStateSelector() {
+		return
+	}
+	latched := getLatchedPfs(state)
+	if len(pciAddresses) == 0 {
+		delete(latched, p.Name)
+	} else {
+		sort.Strings(pciAddresses)
+		latched[p.Name] = latchedPfs{Generation: p.Generation, PciAddresses: pciAddresses}
+	}
+	setLatchedPfs(state, latched)
+}
+
+// PruneSelectedPfs removes from the node state the PFs selected by the policies no longer applied to it
+func PruneSelectedPfs(state *SriovNetworkNodeState, policies []string) {
+	latched := getLatchedPfs(state)
+	for name := range latched {
+		if !StringInArray(name, policies) {
+			delete(latched, name)
+		}
+	}
+	setLatchedPfs(state, latched)
+}
+
 // mergeConfigs merges configs from multiple polices where the last one has the
 // highest priority. This merge is dependent on: 1. SR-IOV partition is
 // configured with the #-notation in pfName, 2. The VF groups are
@@ -403,12 +919,36 @@ func (iface Interface) mergeConfigs(input *Interface, equalPriority bool) {
 	// - skip group with same ResourceName,
 	// - skip overlapping groups (use only highest priority)
 	for _, gr := range iface.VfGroups {
-		if gr.ResourceName == input.VfGroups[0].ResourceName || gr.isVFRangeOverlapping(input.VfGroups[0]) {
+		if len(input.VfGroups) > 0 &&
+			(gr.ResourceName == input.VfGroups[0].ResourceName || gr.isVFRangeOverlapping(input.VfGroups[0])) {
 			continue
 		}
 		m = true
 		input.VfGroups = append(input.VfGroups, gr)
 	}
+	// flow rules are merged by name, the input ones win. The input rules are shared with the policy, so
+	// they are copied before appending.
+	for _, rule := range iface.FlowRules {
+		if !flowRuleInArray(rule.Name, input.FlowRules) {
+			input.FlowRules = append(input.FlowRules[:len(input.FlowRules):len(input.FlowRules)], rule)
+		}
+	}
+	// the QoS and congestion control settings apply to the whole PF, the ones of the input win
+	if input.Qos == nil {
+		input.Qos = iface.Qos
+	}
+	if input.CongestionControl == nil {
+		input.CongestionControl = iface.CongestionControl
+	}
+	// the reconfiguration of the PTP time source is allowed as soon as a policy of the PF allows it
+	input.AllowPtpSourceDisruption = input.AllowPtpSourceDisruption || iface.AllowPtpSourceDisruption
+	// the setting applies to all the VFs of the PF, the kernel drivers only skip probing them when all the policies of
+	// the PF disable it, the VFs of the other policies need their kernel driver
+	input.DisableDriversAutoprobe = input.DisableDriversAutoprobe && iface.DisableDriversAutoprobe
+	// the port split applies to the whole PF, the one of the input wins
+	if input.PortSplit == 0 {
+		input.PortSplit = iface.PortSplit
+	}
 
 	if !equalPriority && !m {
 		return
@@ -423,77 +963,565 @@ func (iface Interface) mergeConfigs(input *Interface, equalPriority bool) {
 	}
 }
 
-func (gr VfGroup) isVFRangeOverlapping(group VfGroup) bool {
-	rngSt, rngEnd, err := parseRange(gr.VfRange)
-	if err != nil {
+// QosNeedsUpdate returns true if a setting of the desired QoS differs from the current one, the settings not
+// set in the desired QoS are ignored
+func QosNeedsUpdate(desired, current *QosConfig) bool {
+	if desired == nil {
 		return false
 	}
-	rngSt2, rngEnd2, err := parseRange(group.VfRange)
-	if err != nil {
-		return false
+	if current == nil {
+		return true
 	}
-	// compare minimal range has overlap
-	if rngSt < rngSt2 {
-		return IndexInRange(rngSt2, gr.VfRange) || IndexInRange(rngEnd2, gr.VfRange)
+	if desired.Trust != "" && desired.Trust != current.Trust {
+		return true
 	}
-	return IndexInRange(rngSt, group.VfRange) || IndexInRange(rngEnd, group.VfRange)
-}
-
-func (p *SriovNetworkNodePolicy) generateVfGroup(iface *InterfaceExt) (*VfGroup, error) {
-	var err error
-	pfName := ""
-	var rngStart, rngEnd int
-	found := false
-	for _, selector := range p.Spec.NicSelector.PfNames {
-		pfName, rngStart, rngEnd, err = ParsePFName(selector)
-		if err != nil {
-			log.Error(err, "Unable to parse PF Name.")
-			return nil, err
-		}
-		if pfName == iface.Name {
-			found = true
-			if rngStart == invalidVfIndex && rngEnd == invalidVfIndex {
-				rngStart, rngEnd = 0, p.Spec.NumVfs-1
-			}
-			break
+	if desired.PfcPriorities != nil {
+		want := append([]int{}, desired.PfcPriorities...)
+		sort.Ints(want)
+		if len(want) != len(current.PfcPriorities) || !intsPrefixEqual(want, current.PfcPriorities) {
+			return true
 		}
 	}
-	if !found {
-		// assign the default vf index range if the pfName is not specified by the nicSelector
-		rngStart, rngEnd = 0, p.Spec.NumVfs-1
+	if desired.PrioToBuffer != nil && !intsPrefixEqual(desired.PrioToBuffer, current.PrioToBuffer) {
+		return true
 	}
-	rng := strconv.Itoa(rngStart) + "-" + strconv.Itoa(rngEnd)
-	return &VfGroup{
-		ResourceName: p.Spec.ResourceName,
-		DeviceType:   p.Spec.DeviceType,
-		VfRange:      rng,
-		PolicyName:   p.GetName(),
-		Mtu:          p.Spec.Mtu,
-		IsRdma:       p.Spec.IsRdma,
-		VdpaType:     p.Spec.VdpaType,
-	}, nil
+	if desired.BufferSizes != nil && !intsPrefixEqual(desired.BufferSizes, current.BufferSizes) {
+		return true
+	}
+	return false
 }
 
-func IndexInRange(i int, r string) bool {
-	rngSt, rngEnd, err := parseRange(r)
-	if err != nil {
+// PtpSourceDisrupted returns true if the PF is the PTP time source of the node and the desired configuration
+// recreates its VFs, splits its port or changes its eSwitch mode or link type, which interrupts the time
+// synchronization
+func PtpSourceDisrupted(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceStatus.Ptp == nil || !ifaceStatus.Ptp.TimeSource {
 		return false
 	}
-	if i <= rngEnd && i >= rngSt {
+	if ifaceSpec.NumVfs != ifaceStatus.NumVfs {
 		return true
 	}
-	return false
-}
-
-func parseRange(r string) (rngSt, rngEnd int, err error) {
-	rng := strings.Split(r, "-")
-	rngSt, err = strconv.Atoi(rng[0])
-	if err != nil {
-		return
+	if ifaceSpec.PortSplit > 0 && ifaceSpec.PortSplit != max(ifaceStatus.PortSplit, 1) {
+		return true
 	}
-	rngEnd, err = strconv.Atoi(rng[1])
-	if err != nil {
-		return
+	eswitchMode := ifaceSpec.EswitchMode
+	if eswitchMode == "" {
+		eswitchMode = ESwithModeLegacy
+	}
+	if ifaceStatus.EswitchMode != "" && eswitchMode != ifaceStatus.EswitchMode {
+		return true
+	}
+	return ifaceSpec.LinkType != "" && !strings.EqualFold(ifaceSpec.LinkType, ifaceStatus.LinkType)
+}
+
+// PtpSourceRefused returns true if the desired configuration disrupts the PTP time source of the node and no policy
+// of the PF allows it, the PF is left as it is
+func PtpSourceRefused(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	return !ifaceSpec.AllowPtpSourceDisruption && PtpSourceDisrupted(ifaceSpec, ifaceStatus)
+}
+
+// PcieLinkDegradedCondition returns the PcieLinkDegraded condition of the node from the PCIe links of its PFs
+func PcieLinkDegradedCondition(interfaces InterfaceExts, generation int64) metav1.Condition {
+	degraded := []string{}
+	for _, iface := range interfaces {
+		if l := iface.PcieLink; l != nil && l.Degraded {
+			degraded = append(degraded, fmt.Sprintf("%s(%s) x%d %s, capable of x%d %s",
+				iface.Name, iface.PciAddress, l.Width, l.Speed, l.MaxWidth, l.MaxSpeed))
+		}
+	}
+	if len(degraded) == 0 {
+		return metav1.Condition{
+			Type:               ConditionPcieLinkDegraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "LinksAtCapability",
+			Message:            "the PCIe links of the PFs trained at their capability",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionPcieLinkDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             "LinkBelowCapability",
+		Message:            "the PCIe links of the PFs trained below their capability: " + strings.Join(degraded, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// MetadataIncompleteCondition returns the MetadataIncomplete condition of the node from the errors of the
+// malformed entries of its OpenStack metadata and of the devices whose PCI address wasn't found by MAC address
+func MetadataIncompleteCondition(malformed, unresolved []string, generation int64) metav1.Condition {
+	if len(malformed) == 0 && len(unresolved) == 0 {
+		return metav1.Condition{
+			Type:               ConditionMetadataIncomplete,
+			Status:             metav1.ConditionFalse,
+			Reason:             "MetadataDecoded",
+			Message:            "the OpenStack metadata was fully decoded",
+			ObservedGeneration: generation,
+		}
+	}
+	reason := "MalformedEntries"
+	messages := []string{}
+	if len(malformed) > 0 {
+		messages = append(messages, "the malformed entries of the OpenStack metadata were skipped: "+strings.Join(malformed, "; "))
+	}
+	if len(unresolved) > 0 {
+		if len(malformed) == 0 {
+			reason = "UnresolvedDevices"
+		}
+		messages = append(messages, "the devices not found by MAC address keep the PCI address of the OpenStack metadata: "+
+			strings.Join(unresolved, "; "))
+	}
+	return metav1.Condition{
+		Type:               ConditionMetadataIncomplete,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            strings.Join(messages, ". "),
+		ObservedGeneration: generation,
+	}
+}
+
+// MtuMismatchCondition returns the MtuMismatch condition of the node from the devices whose MTU differs from the
+// MTU declared by the virtual platform
+func MtuMismatchCondition(mismatches []string, generation int64) metav1.Condition {
+	if len(mismatches) == 0 {
+		return metav1.Condition{
+			Type:               ConditionMtuMismatch,
+			Status:             metav1.ConditionFalse,
+			Reason:             "MtuMatchesPlatform",
+			Message:            "the MTU of the devices matches the MTU declared by the platform",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionMtuMismatch,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MtuDiffersFromPlatform",
+		Message:            "the MTU of the devices differs from the MTU declared by the platform: " + strings.Join(mismatches, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// InterfaceClaimConflictCondition returns the InterfaceClaimConflict condition of the node from the interfaces of its
+// spec claimed by another host network manager
+func InterfaceClaimConflictCondition(conflicts []string, generation int64) metav1.Condition {
+	if len(conflicts) == 0 {
+		return metav1.Condition{
+			Type:               ConditionInterfaceClaimConflict,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoConflict",
+			Message:            "no interface of the node state is claimed by another host network manager",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionInterfaceClaimConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ClaimedByAnotherManager",
+		Message:            "the interfaces claimed by another host network manager are not configured: " + strings.Join(conflicts, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// HostDriftCondition returns the HostDrift condition of the node from the PFs whose configuration differs from the
+// spec last applied to them
+func HostDriftCondition(drifts []string, generation int64) metav1.Condition {
+	if len(drifts) == 0 {
+		return metav1.Condition{
+			Type:               ConditionHostDrift,
+			Status:             metav1.ConditionFalse,
+			Reason:             "MatchesAppliedSpec",
+			Message:            "the configuration of the PFs matches the applied spec",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionHostDrift,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DiffersFromAppliedSpec",
+		Message:            "the configuration of the PFs differs from the applied spec: " + strings.Join(drifts, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// VfsUnboundCondition returns the VfsUnbound condition of the node from the VFs left without driver after the
+// attempts to bind them
+func VfsUnboundCondition(unbound []string, generation int64) metav1.Condition {
+	if len(unbound) == 0 {
+		return metav1.Condition{
+			Type:               ConditionVfsUnbound,
+			Status:             metav1.ConditionFalse,
+			Reason:             "VfsBound",
+			Message:            "the VFs of the VF groups are bound to their driver",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionVfsUnbound,
+		Status:             metav1.ConditionTrue,
+		Reason:             "BindRetriesExhausted",
+		Message:            "the VFs are still unbound after the attempts to bind them: " + strings.Join(unbound, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// DpdkPrerequisitesCondition returns the DpdkPrerequisitesMissing condition of the node, true when the node
+// has vfio-pci VFs but no hugepages, or when a policy of its vfio-pci VFs requires isolated CPUs and the node
+// has none
+func DpdkPrerequisitesCondition(interfaces Interfaces, hugepages int, isolatedCpus string, generation int64) metav1.Condition {
+	noHugepages := []string{}
+	noIsolatedCpus := []string{}
+	for _, iface := range interfaces {
+		for _, group := range iface.VfGroups {
+			if group.DeviceType != consts.DeviceTypeVfioPci {
+				continue
+			}
+			if hugepages == 0 && !slices.Contains(noHugepages, group.PolicyName) {
+				noHugepages = append(noHugepages, group.PolicyName)
+			}
+			if group.RequireIsolatedCpus && isolatedCpus == "" && !slices.Contains(noIsolatedCpus, group.PolicyName) {
+				noIsolatedCpus = append(noIsolatedCpus, group.PolicyName)
+			}
+		}
+	}
+	missing := []string{}
+	if len(noHugepages) > 0 {
+		sort.Strings(noHugepages)
+		missing = append(missing, "no hugepages are allocated for the vfio-pci VFs of the policies "+strings.Join(noHugepages, ", "))
+	}
+	if len(noIsolatedCpus) > 0 {
+		sort.Strings(noIsolatedCpus)
+		missing = append(missing, "no CPUs are isolated for the vfio-pci VFs of the policies "+strings.Join(noIsolatedCpus, ", "))
+	}
+	if len(missing) == 0 {
+		return metav1.Condition{
+			Type:               ConditionDpdkPrerequisitesMissing,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PrerequisitesMet",
+			Message:            "the node meets the prerequisites of the DPDK workloads of its vfio-pci VFs",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionDpdkPrerequisitesMissing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PrerequisitesMissing",
+		Message:            strings.Join(missing, "; "),
+		ObservedGeneration: generation,
+	}
+}
+
+// DrainingCondition returns the Draining condition of the node, true while the drain is in progress and false once
+// it completed or was aborted
+func DrainingCondition(drain *DrainStatus, generation int64) metav1.Condition {
+	if drain == nil {
+		return metav1.Condition{
+			Type:               ConditionDraining,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NotDraining",
+			Message:            "the node is not drained",
+			ObservedGeneration: generation,
+		}
+	}
+	failing := []string{}
+	for _, pod := range drain.FailingPods {
+		failing = append(failing, fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, pod.Reason))
+	}
+	message := fmt.Sprintf("%d pods remaining", drain.PodsRemaining)
+	if len(failing) > 0 {
+		message += fmt.Sprintf(", %d failing eviction: %s", len(failing), strings.Join(failing, ", "))
+	}
+	message += fmt.Sprintf(", started at %s", drain.StartTime.UTC().Format(time.RFC3339))
+	if drain.Deadline != nil {
+		message += fmt.Sprintf(", deadline %s", drain.Deadline.UTC().Format(time.RFC3339))
+	}
+	condition := metav1.Condition{
+		Type:               ConditionDraining,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DrainInProgress",
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+	switch drain.Phase {
+	case DrainPhaseStuck:
+		condition.Reason = "DrainStuck"
+	case DrainPhaseForcing:
+		condition.Reason = "DrainForced"
+	case DrainPhaseAborted:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "DrainAborted"
+	}
+	return condition
+}
+
+// Phase returns the phase of a drain escalated by the config after the elapsed time
+func (c *DrainEscalationConfig) Phase(elapsed time.Duration) string {
+	if c == nil {
+		return DrainPhaseDraining
+	}
+	after := func(minutes int) bool {
+		return minutes > 0 && elapsed >= time.Duration(minutes)*time.Minute
+	}
+	switch {
+	case after(c.AbortAfterMinutes):
+		return DrainPhaseAborted
+	case after(c.ForceAfterMinutes):
+		return DrainPhaseForcing
+	case after(c.WarnAfterMinutes):
+		return DrainPhaseStuck
+	}
+	return DrainPhaseDraining
+}
+
+// Deadline returns the time a drain started at start is aborted, nil if the drains are not aborted
+func (c *DrainEscalationConfig) Deadline(start time.Time) *metav1.Time {
+	if c == nil || c.AbortAfterMinutes == 0 {
+		return nil
+	}
+	deadline := metav1.NewTime(start.Add(time.Duration(c.AbortAfterMinutes) * time.Minute))
+	return &deadline
+}
+
+// Validate checks that the delays of the enabled escalation steps increase
+func (c *DrainEscalationConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	previous, previousName := 0, ""
+	for _, step := range []struct {
+		name    string
+		minutes int
+	}{
+		{"warnAfterMinutes", c.WarnAfterMinutes},
+		{"forceAfterMinutes", c.ForceAfterMinutes},
+		{"abortAfterMinutes", c.AbortAfterMinutes},
+	} {
+		if step.minutes == 0 {
+			continue
+		}
+		if step.minutes <= previous {
+			return fmt.Errorf("drainEscalation %s (%d) must be greater than %s (%d)", step.name, step.minutes, previousName, previous)
+		}
+		previous, previousName = step.minutes, step.name
+	}
+	return nil
+}
+
+// ValidateServiceIPFamilies checks the IP families of the rendered services are distinct IPv4 or IPv6 families
+// matching their IP family policy
+func (s *SriovOperatorConfigSpec) ValidateServiceIPFamilies() error {
+	seen := map[corev1.IPFamily]bool{}
+	for _, family := range s.ServiceIPFamilies {
+		if family != corev1.IPv4Protocol && family != corev1.IPv6Protocol {
+			return fmt.Errorf("serviceIPFamilies: unsupported IP family %q, the supported families are %s and %s", family, corev1.IPv4Protocol, corev1.IPv6Protocol)
+		}
+		if seen[family] {
+			return fmt.Errorf("serviceIPFamilies: the IP family %s is listed more than once", family)
+		}
+		seen[family] = true
+	}
+	switch {
+	case len(s.ServiceIPFamilies) == 2 && s.ServiceIPFamilyPolicy == "":
+		return fmt.Errorf("serviceIPFamilies: two IP families require a serviceIPFamilyPolicy of %s or %s", corev1.IPFamilyPolicyPreferDualStack, corev1.IPFamilyPolicyRequireDualStack)
+	case len(s.ServiceIPFamilies) == 2 && s.ServiceIPFamilyPolicy == corev1.IPFamilyPolicySingleStack:
+		return fmt.Errorf("serviceIPFamilies: the %s serviceIPFamilyPolicy allows a single IP family", corev1.IPFamilyPolicySingleStack)
+	}
+	return nil
+}
+
+// VfioPciModuleOptions returns the options of the vfio_pci module requested by the vfio-pci VF groups of the
+// interfaces, nil when no VF group requests one: the options set by the administrator are then left untouched
+func VfioPciModuleOptions(interfaces Interfaces) map[string]string {
+	for _, iface := range interfaces {
+		for _, group := range iface.VfGroups {
+			if group.DeviceType == consts.DeviceTypeVfioPci && group.DisableIdleD3 {
+				return map[string]string{consts.VfioPciOptionDisableIdleD3: "1"}
+			}
+		}
+	}
+	return nil
+}
+
+// CongestionControlNeedsUpdate returns true if a setting of the desired congestion control differs from the
+// current one, the settings not requested are ignored
+func CongestionControlNeedsUpdate(desired, current *CongestionControlConfig) bool {
+	if desired == nil {
+		return false
+	}
+	if current == nil {
+		return true
+	}
+	if desired.EcnPriorities != nil {
+		want := append([]int{}, desired.EcnPriorities...)
+		sort.Ints(want)
+		if len(want) != len(current.EcnPriorities) || !intsPrefixEqual(want, current.EcnPriorities) {
+			return true
+		}
+	}
+	for _, p := range [][2]*int{{desired.CnpDscp, current.CnpDscp}, {desired.CnpPriority, current.CnpPriority}} {
+		if p[0] != nil && (p[1] == nil || *p[0] != *p[1]) {
+			return true
+		}
+	}
+	for _, p := range [][2]int{
+		{desired.MinRate, current.MinRate},
+		{desired.AiRate, current.AiRate},
+		{desired.HaiRate, current.HaiRate},
+		{desired.TimeReset, current.TimeReset},
+		{desired.ByteReset, current.ByteReset},
+		{desired.RateReduceMonitorPeriod, current.RateReduceMonitorPeriod},
+	} {
+		if p[0] != 0 && p[0] != p[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// intsPrefixEqual returns true if the current values start with the desired ones
+func intsPrefixEqual(desired, current []int) bool {
+	if len(current) < len(desired) {
+		return false
+	}
+	for i := range desired {
+		if desired[i] != current[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorFlowRules returns the rules mirroring the traffic of the sources of the policy to the first VF of its group
+func (p *SriovNetworkNodePolicy) mirrorFlowRules(group *VfGroup) ([]FlowRule, error) {
+	target, _, err := parseRange(group.VfRange)
+	if err != nil {
+		return nil, err
+	}
+	rules := []FlowRule{}
+	if p.Spec.Mirror.SourcePf {
+		rules = append(rules, FlowRule{
+			Name:     fmt.Sprintf("mirror-%s-pf", p.Name),
+			Action:   FlowActionMirror,
+			TargetVf: &target,
+		})
+	}
+	for _, source := range p.Spec.Mirror.SourceVfs {
+		vf := source
+		rules = append(rules, FlowRule{
+			Name:     fmt.Sprintf("mirror-%s-vf%d", p.Name, vf),
+			Vf:       &vf,
+			Action:   FlowActionMirror,
+			TargetVf: &target,
+		})
+	}
+	return rules, nil
+}
+
+func flowRuleInArray(name string, rules []FlowRule) bool {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NumVfs returns the number of VFs in the range of the group, 0 when the range is invalid
+func (gr VfGroup) NumVfs() int {
+	if !strings.Contains(gr.VfRange, "-") {
+		return 0
+	}
+	rngSt, rngEnd, err := parseRange(gr.VfRange)
+	if err != nil || rngEnd < rngSt {
+		return 0
+	}
+	return rngEnd - rngSt + 1
+}
+
+func (gr VfGroup) isVFRangeOverlapping(group VfGroup) bool {
+	rngSt, rngEnd, err := parseRange(gr.VfRange)
+	if err != nil {
+		return false
+	}
+	rngSt2, rngEnd2, err := parseRange(group.VfRange)
+	if err != nil {
+		return false
+	}
+	// compare minimal range has overlap
+	if rngSt < rngSt2 {
+		return IndexInRange(rngSt2, gr.VfRange) || IndexInRange(rngEnd2, gr.VfRange)
+	}
+	return IndexInRange(rngSt, group.VfRange) || IndexInRange(rngEnd, group.VfRange)
+}
+
+func (p *SriovNetworkNodePolicy) generateVfGroup(iface *InterfaceExt) (*VfGroup, error) {
+	var err error
+	pfName := ""
+	var rngStart, rngEnd int
+	found := false
+	for _, selector := range p.Spec.NicSelector.PfNames {
+		pfName, rngStart, rngEnd, err = ParsePFName(selector)
+		if err != nil {
+			log.Error(err, "Unable to parse PF Name.")
+			return nil, err
+		}
+		if pfName == iface.Name || (iface.OriginalName != "" && pfName == iface.OriginalName) {
+			found = true
+			if rngStart == invalidVfIndex && rngEnd == invalidVfIndex {
+				rngStart, rngEnd = 0, p.Spec.NumVfs-1
+			}
+			break
+		}
+	}
+	if !found {
+		// assign the default vf index range if the pfName is not specified by the nicSelector
+		rngStart, rngEnd = 0, p.Spec.NumVfs-1
+	}
+	rng := strconv.Itoa(rngStart) + "-" + strconv.Itoa(rngEnd)
+	// only the unicast MACs of the VFs of the range apply to the PF
+	var unicastMacs []VfUnicastMacs
+	for _, vf := range p.Spec.UnicastMacs {
+		if vf.Vf >= rngStart && vf.Vf <= rngEnd {
+			unicastMacs = append(unicastMacs, *vf.DeepCopy())
+		}
+	}
+	return &VfGroup{
+		ResourceName:        p.Spec.ResourceName,
+		DeviceType:          p.Spec.DeviceType,
+		VfRange:             rng,
+		PolicyName:          p.GetName(),
+		Mtu:                 p.Spec.Mtu,
+		IsRdma:              p.Spec.IsRdma,
+		VdpaType:            p.Spec.VdpaType,
+		HwTimestamping:      p.Spec.HwTimestamping,
+		MsixCount:           p.Spec.MsixCount,
+		RequireIsolatedCpus: p.Spec.RequireIsolatedCpus,
+		DrainMode:           p.Spec.DrainMode,
+		DisableIdleD3:       p.Spec.DisableIdleD3,
+		SystemReserved:      p.Spec.SystemReserved,
+		UnicastMacs:         unicastMacs,
+	}, nil
+}
+
+func IndexInRange(i int, r string) bool {
+	rngSt, rngEnd, err := parseRange(r)
+	if err != nil {
+		return false
+	}
+	if i <= rngEnd && i >= rngSt {
+		return true
+	}
+	return false
+}
+
+func parseRange(r string) (rngSt, rngEnd int, err error) {
+	rng := strings.Split(r, "-")
+	rngSt, err = strconv.Atoi(rng[0])
+	if err != nil {
+		return
+	}
+	rngEnd, err = strconv.Atoi(rng[1])
+	if err != nil {
+		return
 	}
 	return
 }
@@ -512,13 +1540,16 @@ func ParsePFName(name string) (ifName string, rngSt, rngEnd int, err error) {
 }
 
 func (selector *SriovNetworkNicSelector) Selected(iface *InterfaceExt) bool {
+	if iface.Unmanaged {
+		return false
+	}
 	if selector.Vendor != "" && selector.Vendor != iface.Vendor {
 		return false
 	}
 	if selector.DeviceID != "" && selector.DeviceID != iface.DeviceID {
 		return false
 	}
-	if len(selector.RootDevices) > 0 && !StringInArray(iface.PciAddress, selector.RootDevices) {
+	if len(selector.RootDevices) > 0 && !PciAddressInArray(iface.PciAddress, selector.RootDevices) {
 		return false
 	}
 	if len(selector.PfNames) > 0 {
@@ -531,17 +1562,121 @@ func (selector *SriovNetworkNicSelector) Selected(iface *InterfaceExt) bool {
 				pfNames = append(pfNames, p)
 			}
 		}
-		if !StringInArray(iface.Name, pfNames) {
+		// the PFs renamed by the pfNamePrefix of their policies are still selected by their original names
+		if !StringInArray(iface.Name, pfNames) && (iface.OriginalName == "" || !StringInArray(iface.OriginalName, pfNames)) {
 			return false
 		}
 	}
-	if selector.NetFilter != "" && !NetFilterMatch(selector.NetFilter, iface.NetFilter) {
+	if selector.NetFilter != "" && !iface.NetFilterMatch(selector.NetFilter) {
+		return false
+	}
+	if selector.LinkUp && iface.LinkState != consts.LinkStateUp {
+		return false
+	}
+	if selector.TransceiverPresent && !iface.TransceiverPresent {
+		return false
+	}
+	if len(selector.ZpciUids) > 0 && !ZpciUIDInArray(iface.ZpciUID, selector.ZpciUids) {
 		return false
 	}
 
 	return true
 }
 
+// CompactVirtualFunctions groups the consecutive VFs sharing the same configuration into ranges
+func CompactVirtualFunctions(vfs []VirtualFunction) []VirtualFunctionRange {
+	sorted := make([]VirtualFunction, len(vfs))
+	copy(sorted, vfs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].VfID < sorted[j].VfID })
+
+	ranges := []VirtualFunctionRange{}
+	first, last := 0, 0
+	for _, vf := range sorted {
+		n := len(ranges)
+		if n > 0 && vf.VfID == last+1 && ranges[n-1].Driver == vf.Driver && ranges[n-1].Vendor == vf.Vendor &&
+			ranges[n-1].DeviceID == vf.DeviceID && ranges[n-1].Mtu == vf.Mtu && ranges[n-1].VdpaType == vf.VdpaType &&
+			ranges[n-1].HwTimestamping == vf.HwTimestamping && ranges[n-1].MsixCount == vf.MsixCount &&
+			ranges[n-1].DriverOverride == vf.DriverOverride && ranges[n-1].BindError == vf.BindError {
+			last = vf.VfID
+			ranges[n-1].VfRange = fmt.Sprintf("%d-%d", first, last)
+			continue
+		}
+		first, last = vf.VfID, vf.VfID
+		ranges = append(ranges, VirtualFunctionRange{
+			VfRange:        fmt.Sprintf("%d-%d", first, last),
+			Driver:         vf.Driver,
+			Vendor:         vf.Vendor,
+			DeviceID:       vf.DeviceID,
+			Mtu:            vf.Mtu,
+			VdpaType:       vf.VdpaType,
+			HwTimestamping: vf.HwTimestamping,
+			MsixCount:      vf.MsixCount,
+			DriverOverride: vf.DriverOverride,
+			BindError:      vf.BindError,
+		})
+	}
+	return ranges
+}
+
+// GetVirtualFunctions returns the VFs of the interface. For a compact node state the VFs are
+// expanded from the summary and only carry their index and configuration.
+func (iface *InterfaceExt) GetVirtualFunctions() []VirtualFunction {
+	if len(iface.VFs) > 0 || len(iface.VFsSummary) == 0 {
+		return iface.VFs
+	}
+	vfs := []VirtualFunction{}
+	for _, r := range iface.VFsSummary {
+		rngSt, rngEnd, err := parseRange(r.VfRange)
+		if err != nil {
+			log.Error(err, "GetVirtualFunctions(): invalid VF range", "range", r.VfRange)
+			continue
+		}
+		for i := rngSt; i <= rngEnd; i++ {
+			vfs = append(vfs, VirtualFunction{
+				VfID:           i,
+				Driver:         r.Driver,
+				Vendor:         r.Vendor,
+				DeviceID:       r.DeviceID,
+				Mtu:            r.Mtu,
+				VdpaType:       r.VdpaType,
+				HwTimestamping: r.HwTimestamping,
+				MsixCount:      r.MsixCount,
+				DriverOverride: r.DriverOverride,
+				BindError:      r.BindError,
+			})
+		}
+	}
+	return vfs
+}
+
+// IsPlatformDevice returns true for a device of a virtual platform, reported with the network of the platform or as
+// unmanaged, unlike the SR-IOV capable PFs a virtual machine provisions the VFs of itself
+func (iface *InterfaceExt) IsPlatformDevice() bool {
+	return iface.Unmanaged || iface.NetFilter != ""
+}
+
+// SelectPlatformDevices returns a copy of the node state restricted to the interfaces of the devices of the virtual
+// platform when platform is true, and to the SR-IOV capable PFs of the virtual machine otherwise
+func (s *SriovNetworkNodeState) SelectPlatformDevices(platform bool) *SriovNetworkNodeState {
+	selected := s.DeepCopy()
+	statuses, specs := selected.Status.Interfaces, selected.Spec.Interfaces
+	selected.Status.Interfaces = InterfaceExts{}
+	addresses := map[string]bool{}
+	for _, iface := range statuses {
+		if iface.IsPlatformDevice() == platform {
+			selected.Status.Interfaces = append(selected.Status.Interfaces, iface)
+			addresses[iface.PciAddress] = true
+		}
+	}
+	selected.Spec.Interfaces = Interfaces{}
+	for _, iface := range specs {
+		if addresses[iface.PciAddress] {
+			selected.Spec.Interfaces = append(selected.Spec.Interfaces, iface)
+		}
+	}
+	return selected
+}
+
 func (s *SriovNetworkNodeState) GetInterfaceStateByPciAddress(addr string) *InterfaceExt {
 	for _, iface := range s.Status.Interfaces {
 		if addr == iface.PciAddress {
@@ -560,6 +1695,65 @@ func (s *SriovNetworkNodeState) GetDriverByPciAddress(addr string) string {
 	return ""
 }
 
+// drainModes orders the drain modes from the least to the most disruptive
+var drainModes = []string{DrainModeNone, DrainModeCordon, DrainModeDrain}
+
+// EffectiveDrainMode returns how the node is drained when its configuration changes. Each VF group of the PFs to
+// update is drained with the drainMode of its policy, or else with the drainMode of the pool of the node, or else
+// according to the disableDrain of the SriovOperatorConfig, and the most disruptive mode of the groups applies.
+func (s *SriovNetworkNodeState) EffectiveDrainMode(disableDrain bool) string {
+	fallback := s.Spec.DrainMode
+	if fallback == "" {
+		fallback = DrainModeDrain
+		if disableDrain {
+			fallback = DrainModeNone
+		}
+	}
+	mode := ""
+	max := func(groupMode string) {
+		if groupMode == "" {
+			groupMode = fallback
+		}
+		if slices.Index(drainModes, groupMode) > slices.Index(drainModes, mode) {
+			mode = groupMode
+		}
+	}
+	for i := range s.Spec.Interfaces {
+		iface := &s.Spec.Interfaces[i]
+		ifaceStatus := s.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil || !NeedToUpdateSriov(iface, ifaceStatus) {
+			continue
+		}
+		if len(iface.VfGroups) == 0 {
+			max("")
+		}
+		for _, group := range iface.VfGroups {
+			max(group.DrainMode)
+		}
+	}
+	if mode == "" {
+		return fallback
+	}
+	return mode
+}
+
+// CniVersionOrDefault returns the CNI specification version of a network, DefaultCniVersion when unset
+func CniVersionOrDefault(version string) string {
+	if version == "" {
+		return DefaultCniVersion
+	}
+	return version
+}
+
+// setCniVersionData sets the CNI specification version of the NetworkAttachmentDefinition. From the 1.0.0
+// specification the network configurations are plugin lists, the single plugin configurations are only accepted by
+// the runtimes for compatibility, and the GC and STATUS verbs of 1.1.0 are only run on plugin lists.
+func setCniVersionData(data *render.RenderData, version string) {
+	version = CniVersionOrDefault(version)
+	data.Data["CniVersion"] = version
+	data.Data["PluginListConfigured"] = data.Data["MetaPluginsConfigured"] == true || !strings.HasPrefix(version, "0.")
+}
+
 // RenderNetAttDef renders a net-att-def for ib-sriov CNI
 func (cr *SriovIBNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 	logger := log.WithName("RenderNetAttDef")
@@ -574,7 +1768,7 @@ func (cr *SriovIBNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 	} else {
 		data.Data["SriovNetworkNamespace"] = cr.Spec.NetworkNamespace
 	}
-	data.Data["SriovCniResourceName"] = os.Getenv("RESOURCE_PREFIX") + "/" + cr.Spec.ResourceName
+	data.Data["SriovCniResourceName"] = string(ExtendedResourceName(cr.Spec.ResourceName))
 
 	data.Data["StateConfigured"] = true
 	switch cr.Spec.LinkState {
@@ -607,6 +1801,7 @@ func (cr *SriovIBNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 		data.Data["MetaPluginsConfigured"] = true
 		data.Data["MetaPlugins"] = cr.Spec.MetaPluginsConfig
 	}
+	setCniVersionData(&data, cr.Spec.CniVersion)
 
 	// logLevel and logFile are currently not supports by the ip-sriov-cni -> hardcode them to false.
 	data.Data["LogLevelConfigured"] = false
@@ -645,6 +1840,254 @@ func (cr *SriovIBNetwork) DeleteNetAttDef(c client.Client) error {
 	return nil
 }
 
+// InjectedResourceName returns the resource of the NetworkAttachmentDefinition of the network, empty when the
+// injection of the resource is skipped
+func (cr *SriovNetwork) InjectedResourceName() string {
+	if cr.Spec.ResourceInjection != nil {
+		if cr.Spec.ResourceInjection.Skip {
+			return ""
+		}
+		if cr.Spec.ResourceInjection.ResourceName != "" {
+			return cr.Spec.ResourceInjection.ResourceName
+		}
+	}
+	return string(ExtendedResourceName(cr.Spec.ResourceName))
+}
+
+// ValidateResourceInjection checks the resource injected for the network is a valid extended resource, and isn't
+// both overridden and skipped
+func (cr *SriovNetwork) ValidateResourceInjection() error {
+	injection := cr.Spec.ResourceInjection
+	if injection == nil {
+		return nil
+	}
+	if injection.Skip && injection.ResourceName != "" {
+		return fmt.Errorf("the resourceName of the resourceInjection must be empty when the injection is skipped")
+	}
+	if injection.ResourceName == "" {
+		return nil
+	}
+	if !strings.Contains(injection.ResourceName, "/") {
+		return fmt.Errorf("injected resource %s must be an extended resource, <prefix>/<name>", injection.ResourceName)
+	}
+	if errs := validation.IsQualifiedName(injection.ResourceName); len(errs) > 0 {
+		return fmt.Errorf("injected resource %s is invalid: %s", injection.ResourceName, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ValidatePolicing checks the policing of the network against the other SriovNetworks of the operator namespace, all
+// the networks policing a resource must agree as the policing applies to the VFs of the resource
+func (cr *SriovNetwork) ValidatePolicing(networks []SriovNetwork) error {
+	policing := cr.Spec.Policing
+	if policing == nil {
+		return nil
+	}
+	if policing.Egress == nil && policing.Ingress == nil {
+		return fmt.Errorf("policing requires an egress or an ingress rate")
+	}
+	for _, n := range networks {
+		if n.Name == cr.Name || n.Spec.ResourceName != cr.Spec.ResourceName || n.Spec.Policing == nil {
+			continue
+		}
+		if !reflect.DeepEqual(n.Spec.Policing, policing) {
+			return fmt.Errorf("resource %s is already policed differently by SriovNetwork %s", cr.Spec.ResourceName, n.Name)
+		}
+	}
+	return nil
+}
+
+// ResourcePolicing returns the policing of the resources of the SriovNetworks by resource name, the first network by
+// name wins for a resource whose networks disagree
+func ResourcePolicing(networks []SriovNetwork) map[string]*PolicingConfig {
+	sorted := make([]SriovNetwork, len(networks))
+	copy(sorted, networks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	policing := map[string]*PolicingConfig{}
+	for _, n := range sorted {
+		if _, found := policing[n.Spec.ResourceName]; found || n.Spec.Policing == nil {
+			continue
+		}
+		policing[n.Spec.ResourceName] = n.Spec.Policing
+	}
+	return policing
+}
+
+// VfPolicing returns the policing of the VFs of the interface by VF index
+func (iface *Interface) VfPolicing() map[int]*PolicingConfig {
+	policing := map[int]*PolicingConfig{}
+	for _, group := range iface.VfGroups {
+		if group.Policing == nil {
+			continue
+		}
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			if IndexInRange(vfID, group.VfRange) {
+				policing[vfID] = group.Policing
+			}
+		}
+	}
+	return policing
+}
+
+// SetPolicing sets the policing of their resource on the VF groups of the interfaces
+func (ifaces Interfaces) SetPolicing(policing map[string]*PolicingConfig) {
+	for i := range ifaces {
+		for j := range ifaces[i].VfGroups {
+			group := &ifaces[i].VfGroups[j]
+			group.Policing = policing[group.ResourceName].DeepCopy()
+		}
+	}
+}
+
+// maxInterfaceNameLength is the maximum length of the name of a netdev, IFNAMSIZ without the trailing NUL
+const maxInterfaceNameLength = 15
+
+// pfNameSuffixRe matches the domain, bus, device and function of a PCI address
+var pfNameSuffixRe = regexp.MustCompile(`^([0-9a-f]{4,}):([0-9a-f]{2}):([0-9a-f]{2})\.([0-7])$`)
+
+// PfNames returns the names the PFs are renamed to by the pfNamePrefix of the policies by PCI address. A PF is named
+// after the prefix followed by its PCI bus, device and function, and its domain when it isn't 0000, so that its name
+// doesn't depend on the other PFs of the node. The policy with the highest priority wins for a PF selected by
+// policies with different prefixes. The PFs whose name would exceed the maximum length of an interface name are
+// not renamed.
+func PfNames(policies []SriovNetworkNodePolicy, interfaces InterfaceExts) map[string]string {
+	names := map[string]string{}
+	for _, p := range policies {
+		s := p.Spec.NicSelector
+		if p.Spec.PfNamePrefix == "" || (s.Vendor == "" && s.DeviceID == "" && len(s.RootDevices) == 0 &&
+			len(s.PfNames) == 0 && len(s.NetFilter) == 0 && len(s.ZpciUids) == 0) {
+			continue
+		}
+		for i := range interfaces {
+			pciAddress := interfaces[i].PciAddress
+			if _, found := names[pciAddress]; found || !s.Selected(&interfaces[i]) {
+				continue
+			}
+			if name := pfName(p.Spec.PfNamePrefix, pciAddress); name != "" {
+				names[pciAddress] = name
+			}
+		}
+	}
+	return names
+}
+
+// pfName returns the name of the PF with the prefix, or an empty string if the name is too long
+func pfName(prefix, pciAddress string) string {
+	m := pfNameSuffixRe.FindStringSubmatch(strings.ToLower(pciAddress))
+	if m == nil {
+		return ""
+	}
+	domain := strings.TrimLeft(m[1], "0")
+	name := fmt.Sprintf("%s%s%s%sf%s", prefix, domain, m[2], m[3], m[4])
+	if len(name) > maxInterfaceNameLength {
+		return ""
+	}
+	return name
+}
+
+// SetPfNames sets the names the interfaces are renamed to
+func (ifaces Interfaces) SetPfNames(names map[string]string) {
+	for i := range ifaces {
+		ifaces[i].RenameTo = names[ifaces[i].PciAddress]
+	}
+}
+
+// ValidateAddressSharing checks the address sharing of the network against its spoof check and trust mode
+func (cr *SriovNetwork) ValidateAddressSharing() error {
+	sharing := cr.Spec.AddressSharing
+	if sharing == nil {
+		return nil
+	}
+	if *sharing == (AddressSharing{}) {
+		return fmt.Errorf("addressSharing requires at least one exception")
+	}
+	if sharing.AllowSpoofing && cr.Spec.SpoofChk == SriovCniStateOn {
+		return fmt.Errorf("addressSharing allowSpoofing requires the spoof check of the VFs off, spoofChk is on")
+	}
+	if sharing.MultipleUnicastMacs && cr.Spec.Trust == SriovCniStateOff {
+		return fmt.Errorf("addressSharing multipleUnicastMacs requires the trust mode of the VFs on, trust is off")
+	}
+	return nil
+}
+
+// ResourceAddressSharing returns the spoofing and unicast MACs exceptions of the resources of the SriovNetworks by
+// resource name. The VFs of a resource are shared by its networks, an exception only applies to them when all the
+// networks of the resource set it, the CNI applies the exceptions of the other networks to the VFs of their pods.
+func ResourceAddressSharing(networks []SriovNetwork) map[string]*AddressSharing {
+	sharing := map[string]*AddressSharing{}
+	for _, n := range networks {
+		s := n.Spec.AddressSharing
+		if s == nil {
+			s = &AddressSharing{}
+		}
+		shared, found := sharing[n.Spec.ResourceName]
+		if !found {
+			sharing[n.Spec.ResourceName] = &AddressSharing{AllowSpoofing: s.AllowSpoofing, MultipleUnicastMacs: s.MultipleUnicastMacs}
+			continue
+		}
+		shared.AllowSpoofing = shared.AllowSpoofing && s.AllowSpoofing
+		shared.MultipleUnicastMacs = shared.MultipleUnicastMacs && s.MultipleUnicastMacs
+	}
+	for resourceName, shared := range sharing {
+		if !shared.AllowSpoofing && !shared.MultipleUnicastMacs {
+			delete(sharing, resourceName)
+		}
+	}
+	return sharing
+}
+
+// VfAddressSharing returns the spoofing and unicast MACs exceptions of the VFs of the interface by VF index
+func (iface *Interface) VfAddressSharing() map[int]*AddressSharing {
+	sharing := map[int]*AddressSharing{}
+	for _, group := range iface.VfGroups {
+		if group.AddressSharing == nil {
+			continue
+		}
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			if IndexInRange(vfID, group.VfRange) {
+				sharing[vfID] = group.AddressSharing
+			}
+		}
+	}
+	return sharing
+}
+
+// UnicastMacsByVf returns the additional unicast MACs of the VFs of the interface by VF index
+func (iface *Interface) UnicastMacsByVf() map[int][]string {
+	macs := map[int][]string{}
+	for _, group := range iface.VfGroups {
+		for _, vf := range group.UnicastMacs {
+			if vf.Vf < iface.NumVfs && IndexInRange(vf.Vf, group.VfRange) {
+				macs[vf.Vf] = vf.Macs
+			}
+		}
+	}
+	return macs
+}
+
+// SetAddressSharing sets the spoofing and unicast MACs exceptions of their resource on the VF groups of the interfaces
+func (ifaces Interfaces) SetAddressSharing(sharing map[string]*AddressSharing) {
+	for i := range ifaces {
+		for j := range ifaces[i].VfGroups {
+			group := &ifaces[i].VfGroups[j]
+			group.AddressSharing = sharing[group.ResourceName].DeepCopy()
+		}
+	}
+}
+
+// proxyNeighborsPlugin returns the tuning plugin enabling the proxying of the ARP requests and of the IPv6 neighbor
+// solicitations on the interface of the pod, the tuning plugin replaces IFNAME by its name
+func proxyNeighborsPlugin(sharing *AddressSharing) string {
+	sysctls := []string{}
+	if sharing.ProxyArp {
+		sysctls = append(sysctls, `"net.ipv4.conf.IFNAME.proxy_arp":"1"`)
+	}
+	if sharing.ProxyNdp {
+		sysctls = append(sysctls, `"net.ipv6.conf.IFNAME.proxy_ndp":"1"`)
+	}
+	return fmt.Sprintf(`{"type":"tuning","sysctl":{%s}}`, strings.Join(sysctls, ","))
+}
+
 // RenderNetAttDef renders a net-att-def for sriov CNI
 func (cr *SriovNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 	logger := log.WithName("RenderNetAttDef")
@@ -659,7 +2102,7 @@ func (cr *SriovNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 	} else {
 		data.Data["SriovNetworkNamespace"] = cr.Spec.NetworkNamespace
 	}
-	data.Data["SriovCniResourceName"] = os.Getenv("RESOURCE_PREFIX") + "/" + cr.Spec.ResourceName
+	data.Data["SriovCniResourceName"] = cr.InjectedResourceName()
 	data.Data["SriovCniVlan"] = cr.Spec.Vlan
 
 	if cr.Spec.VlanQoS <= 7 && cr.Spec.VlanQoS >= 0 {
@@ -742,6 +2185,28 @@ func (cr *SriovNetwork) RenderNetAttDef() (*uns.Unstructured, error) {
 		data.Data["MetaPlugins"] = cr.Spec.MetaPluginsConfig
 	}
 
+	// the exceptions of the address sharing override the spoof check and the trust mode of the network, the proxying
+	// is chained after the meta plugins
+	if sharing := cr.Spec.AddressSharing; sharing != nil {
+		if sharing.AllowSpoofing {
+			data.Data["SpoofChkConfigured"] = true
+			data.Data["SriovCniSpoofChk"] = SriovCniStateOff
+		}
+		if sharing.MultipleUnicastMacs {
+			data.Data["TrustConfigured"] = true
+			data.Data["SriovCniTrust"] = SriovCniStateOn
+		}
+		if sharing.ProxyArp || sharing.ProxyNdp {
+			plugins := proxyNeighborsPlugin(sharing)
+			if cr.Spec.MetaPluginsConfig != "" {
+				plugins = cr.Spec.MetaPluginsConfig + ", " + plugins
+			}
+			data.Data["MetaPluginsConfigured"] = true
+			data.Data["MetaPlugins"] = plugins
+		}
+	}
+	setCniVersionData(&data, cr.Spec.CniVersion)
+
 	data.Data["LogLevelConfigured"] = (cr.Spec.LogLevel != "")
 	data.Data["LogLevel"] = cr.Spec.LogLevel
 	data.Data["LogFileConfigured"] = (cr.Spec.LogFile != "")
@@ -780,25 +2245,265 @@ func (cr *SriovNetwork) DeleteNetAttDef(c client.Client) error {
 	return nil
 }
 
+// NetFilterMatch returns true if the netFilter matches the network of the interface or of one of its subports
+func (iface *InterfaceExt) NetFilterMatch(netFilter string) bool {
+	if NetFilterMatch(netFilter, iface.NetFilter) {
+		return true
+	}
+	for _, subport := range iface.Subports {
+		if NetFilterMatch(netFilter, subport.NetFilter) {
+			return true
+		}
+	}
+	return false
+}
+
 // NetFilterMatch -- parse netFilter and check for a match
 func NetFilterMatch(netFilter string, netValue string) (isMatch bool) {
 	logger := log.WithName("NetFilterMatch")
 
-	var re = regexp.MustCompile(`(?m)^\s*([^\s]+)\s*:\s*([^\s]+)`)
-
-	netFilterResult := re.FindAllStringSubmatch(netFilter, -1)
-
-	if netFilterResult == nil {
+	filterPrefix, filterValue, err := ParseNetFilter(netFilter)
+	if err != nil {
 		logger.Info("Invalid NetFilter spec...", "netFilter", netFilter)
 		return false
 	}
 
-	netValueResult := re.FindAllStringSubmatch(netValue, -1)
-
-	if netValueResult == nil {
+	prefix, value, err := ParseNetFilter(netValue)
+	if err != nil {
 		logger.Info("Invalid netValue...", "netValue", netValue)
 		return false
 	}
 
-	return netFilterResult[0][1] == netValueResult[0][1] && netFilterResult[0][2] == netValueResult[0][2]
+	if filterPrefix != prefix {
+		return false
+	}
+	provider, exists := LookupNetFilterProvider(prefix)
+	if !exists {
+		logger.Info("Unsupported NetFilter...", "netFilter", netFilter)
+		return false
+	}
+	return provider.Match(filterValue, value)
+}
+
+// NetFilterProvider implements the NetFilters of a platform, of the form <prefix>:<value>, e.g.
+// openstack/NetworkID:<network UUID>. The provider of a prefix matches the NetFilters of the policies with the
+// NetFilters of the devices, both when the interfaces of the node states are selected and when the device plugin
+// config is rendered.
+type NetFilterProvider interface {
+	// Prefix of the NetFilters of the platform
+	Prefix() string
+	// Match returns true if the value of the netFilter of a policy selects the value of the NetFilter of a device
+	Match(filterValue, deviceValue string) bool
+}
+
+// openstackNetFilterProvider selects the devices attached to a Neutron network by the UUID of the network
+type openstackNetFilterProvider struct{}
+
+func (openstackNetFilterProvider) Prefix() string {
+	return OpenstackNetworkID.String()
+}
+
+func (openstackNetFilterProvider) Match(filterValue, deviceValue string) bool {
+	return filterValue == deviceValue
+}
+
+// netFilterProviders are the NetFilter providers keyed by their prefix, they are registered by init functions
+// of the packages imported by both the operator and the config daemon
+var netFilterProviders = map[string]NetFilterProvider{
+	OpenstackNetworkID.String(): openstackNetFilterProvider{},
+}
+
+// RegisterNetFilterProvider registers the NetFilter provider of a platform, replacing the provider of the same prefix.
+// It is not safe to call it concurrently with the matching of NetFilters, it is meant to be called from an init function.
+func RegisterNetFilterProvider(provider NetFilterProvider) {
+	netFilterProviders[provider.Prefix()] = provider
+}
+
+// LookupNetFilterProvider returns the NetFilter provider of a prefix
+func LookupNetFilterProvider(prefix string) (NetFilterProvider, bool) {
+	provider, exists := netFilterProviders[prefix]
+	return provider, exists
+}
+
+// ParseNetFilter splits a NetFilter into its prefix and its value, separated by the first colon
+func ParseNetFilter(netFilter string) (prefix, value string, err error) {
+	prefix, value, found := strings.Cut(netFilter, ":")
+	prefix, value = strings.TrimSpace(prefix), strings.TrimSpace(value)
+	if !found || prefix == "" || value == "" || strings.ContainsAny(prefix+value, " \t\r\n") {
+		return "", "", fmt.Errorf("invalid NetFilter %q, expected <prefix>:<value>", netFilter)
+	}
+	return prefix, value, nil
+}
+
+// NewNetFilter returns the NetFilter of a value for the platform of prefix
+func NewNetFilter(prefix, value string) string {
+	return prefix + ":" + value
+}
+
+// ExtendedResourceName returns the extended resource the device plugin advertises for the resourceName of the
+// policies, the resource name prefixed by the resource prefix of the operator
+func ExtendedResourceName(resourceName string) corev1.ResourceName {
+	return corev1.ResourceName(os.Getenv("RESOURCE_PREFIX") + "/" + resourceName)
+}
+
+// ValidateExtendedResourceName checks the extended resource of a resourceName follows the naming rules of the
+// kubelet: a qualified name whose prefix is a DNS subdomain outside of the kubernetes.io domain
+func ValidateExtendedResourceName(resourceName string) error {
+	name := ExtendedResourceName(resourceName)
+	prefix, _, _ := strings.Cut(string(name), "/")
+	if prefix == "" {
+		return fmt.Errorf("resource prefix of the operator is empty")
+	}
+	if prefix == "kubernetes.io" || strings.HasSuffix(prefix, ".kubernetes.io") {
+		return fmt.Errorf("resource prefix %s is reserved to the kubernetes resources", prefix)
+	}
+	if strings.HasPrefix(prefix, corev1.DefaultResourceRequestsPrefix) {
+		return fmt.Errorf("resource prefix %s is reserved to the resource quotas", prefix)
+	}
+	if errs := validation.IsQualifiedName(string(name)); len(errs) > 0 {
+		return fmt.Errorf("extended resource %s is invalid: %s", name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ApplyOverlays applies the overlays to the rendered objects they target, in the order of the overlays
+func ApplyOverlays(objs []*uns.Unstructured, overlays []ManifestOverlay) error {
+	for i := range overlays {
+		overlay := &overlays[i]
+		for _, obj := range objs {
+			if obj.GetKind() != overlay.Target.Kind || obj.GetName() != overlay.Target.Name {
+				continue
+			}
+			if err := overlay.Apply(obj); err != nil {
+				return fmt.Errorf("failed to apply overlay %s to %s %s: %v", overlay.Name, obj.GetKind(), obj.GetName(), err)
+			}
+			log.V(2).Info("ApplyOverlays(): overlay applied", "overlay", overlay.Name, "kind", obj.GetKind(), "name", obj.GetName())
+		}
+	}
+	return nil
+}
+
+// Apply patches the object with the overlay. The kinds without strategic merge metadata, e.g. the custom
+// resources, are patched with a JSON merge patch in place of a strategic merge patch.
+func (o *ManifestOverlay) Apply(obj *uns.Unstructured) error {
+	patch, err := o.patchJSON()
+	if err != nil {
+		return err
+	}
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if o.Type == JSON6902Overlay {
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return fmt.Errorf("invalid JSON patch: %v", err)
+		}
+		patched, err = p.Apply(original)
+		if err != nil {
+			return err
+		}
+	} else {
+		dataStruct, err := kscheme.Scheme.New(obj.GroupVersionKind())
+		if err == nil {
+			patched, err = strategicpatch.StrategicMergePatch(original, patch, dataStruct)
+		} else {
+			patched, err = jsonpatch.MergePatch(original, patch)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	result := &uns.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return err
+	}
+	if result.GroupVersionKind() != obj.GroupVersionKind() || result.GetName() != obj.GetName() || result.GetNamespace() != obj.GetNamespace() {
+		return fmt.Errorf("the patch can't change the kind, the name or the namespace of the object")
+	}
+	obj.Object = result.Object
+	return nil
+}
+
+// Validate checks the patch of the overlay can be parsed
+func (o *ManifestOverlay) Validate() error {
+	patch, err := o.patchJSON()
+	if err != nil {
+		return err
+	}
+	if o.Type == JSON6902Overlay {
+		if _, err := jsonpatch.DecodePatch(patch); err != nil {
+			return fmt.Errorf("overlay %s: invalid JSON patch: %v", o.Name, err)
+		}
+		return nil
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &content); err != nil {
+		return fmt.Errorf("overlay %s: the merge patch must be an object: %v", o.Name, err)
+	}
+	return nil
+}
+
+// patchJSON returns the patch of the overlay, written in YAML or JSON, as JSON
+func (o *ManifestOverlay) patchJSON() ([]byte, error) {
+	patch, err := yaml.ToJSON([]byte(o.Patch))
+	if err != nil {
+		return nil, fmt.Errorf("overlay %s: failed to parse the patch: %v", o.Name, err)
+	}
+	return patch, nil
+}
+
+// FeatureGates are the feature gates of the optional components of the operator
+var FeatureGates = []string{
+	consts.ResourceInjectorFeatureGate,
+	consts.OperatorWebhookFeatureGate,
+	consts.MetricsExporterFeatureGate,
+	consts.SystemdModeFeatureGate,
+	consts.MonitoringFeatureGate,
+	consts.ConfinedDaemonFeatureGate,
+	consts.NMStateIntegrationFeatureGate,
+}
+
+// FeatureGateEnabled returns the effective state of the optional component: the value of its feature gate when
+// set, else the value of the field which used to enable the component
+func (c *SriovOperatorConfig) FeatureGateEnabled(gate string) bool {
+	if enabled, ok := c.Spec.FeatureGates[gate]; ok {
+		return enabled
+	}
+	switch gate {
+	case consts.ResourceInjectorFeatureGate:
+		return c.Spec.EnableInjector
+	case consts.OperatorWebhookFeatureGate:
+		return c.Spec.EnableOperatorWebhook
+	case consts.SystemdModeFeatureGate:
+		return c.Spec.ConfigurationMode == SystemdConfigurationMode
+	}
+	return false
+}
+
+// ParseFeatureGates parses feature gates in the <gate>=<true|false>,... format
+func ParseFeatureGates(value string) (map[string]bool, error) {
+	gates := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, enabled, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid feature gate %q, expected <gate>=<true|false>", item)
+		}
+		if !StringInArray(name, FeatureGates) {
+			return nil, fmt.Errorf("unknown feature gate %s", name)
+		}
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of feature gate %s: %v", name, err)
+		}
+		gates[name] = b
+	}
+	return gates, nil
 }
@@ -5,12 +5,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	v1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
@@ -72,6 +77,10 @@ func newNodeState() *v1.SriovNetworkNodeState {
 	}
 }
 
+func intPtr(i int) *int {
+	return &i
+}
+
 func newNodePolicy() *v1.SriovNetworkNodePolicy {
 	return &v1.SriovNetworkNodePolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -169,6 +178,59 @@ func TestRendering(t *testing.T) {
 				},
 			},
 		},
+		{
+			tname: "injected",
+			network: v1.SriovNetwork{
+				Spec: v1.SriovNetworkSpec{
+					NetworkNamespace:  "testnamespace",
+					ResourceName:      "testresource",
+					ResourceInjection: &v1.ResourceInjection{ResourceName: "rdma/hca_shared_devices_a"},
+				},
+			},
+		},
+		{
+			tname: "skipped",
+			network: v1.SriovNetwork{
+				Spec: v1.SriovNetworkSpec{
+					NetworkNamespace:  "testnamespace",
+					ResourceName:      "testresource",
+					ResourceInjection: &v1.ResourceInjection{Skip: true},
+				},
+			},
+		},
+		{
+			tname: "cni11",
+			network: v1.SriovNetwork{
+				Spec: v1.SriovNetworkSpec{
+					NetworkNamespace: "testnamespace",
+					ResourceName:     "testresource",
+					CniVersion:       "1.1.0",
+				},
+			},
+		},
+		{
+			tname: "chainedcni10",
+			network: v1.SriovNetwork{
+				Spec: v1.SriovNetworkSpec{
+					NetworkNamespace:  "testnamespace",
+					ResourceName:      "testresource",
+					CniVersion:        "1.0.0",
+					MetaPluginsConfig: `{ "type": "tuning" }`,
+				},
+			},
+		},
+		{
+			tname: "addresssharing",
+			network: v1.SriovNetwork{
+				Spec: v1.SriovNetworkSpec{
+					NetworkNamespace:  "testnamespace",
+					ResourceName:      "testresource",
+					MetaPluginsConfig: `{ "type": "vrf", "vrfname": "blue" }`,
+					AddressSharing: &v1.AddressSharing{AllowSpoofing: true, MultipleUnicastMacs: true,
+						ProxyArp: true, ProxyNdp: true},
+				},
+			},
+		},
 	}
 	for _, tc := range testtable {
 		t.Run(tc.tname, func(t *testing.T) {
@@ -216,6 +278,16 @@ func TestIBRendering(t *testing.T) {
 				},
 			},
 		},
+		{
+			tname: "cni11ib",
+			network: v1.SriovIBNetwork{
+				Spec: v1.SriovIBNetworkSpec{
+					NetworkNamespace: "testnamespace",
+					ResourceName:     "testresource",
+					CniVersion:       "1.1.0",
+				},
+			},
+		},
 	}
 	for _, tc := range testtable {
 		t.Run(tc.tname, func(t *testing.T) {
@@ -693,6 +765,152 @@ func TestSriovNetworkNodePolicyApply(t *testing.T) {
 			expectedInterfaces: nil,
 			expectedErr:        true,
 		},
+		{
+			tname:        "link up selector with link down",
+			currentState: newNodeState(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector.LinkUp = true
+				return p
+			}(),
+			equalP:             false,
+			expectedInterfaces: nil,
+		},
+		{
+			tname: "link up selector with link up",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Status.Interfaces[1].LinkState = consts.LinkStateUp
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector.LinkUp = true
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:       "ens803f1",
+					NumVfs:     2,
+					PciAddress: "0000:86:00.1",
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "transceiver selector without module",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Status.Interfaces[1].LinkState = consts.LinkStateUp
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector.TransceiverPresent = true
+				return p
+			}(),
+			equalP:             false,
+			expectedInterfaces: nil,
+		},
+		{
+			tname: "flow rules merged by name",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Spec.Interfaces = []v1.Interface{
+					{
+						Name:       "ens803f1",
+						NumVfs:     4,
+						PciAddress: "0000:86:00.1",
+						VfGroups: []v1.VfGroup{
+							{
+								DeviceType:   consts.DeviceTypeNetDevice,
+								ResourceName: "prevres",
+								VfRange:      "2-3",
+								PolicyName:   "p2",
+							},
+						},
+						FlowRules: []v1.FlowRule{
+							{Name: "trap-lacp", Action: v1.FlowActionDrop},
+							{Name: "drop-arp", Match: v1.FlowMatch{EthType: "arp"}, Action: v1.FlowActionDrop},
+						},
+					},
+				}
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.FlowRules = []v1.FlowRule{
+					{Name: "trap-lacp", Match: v1.FlowMatch{EthType: "0x8809"}, Action: v1.FlowActionTrap},
+				}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:       "ens803f1",
+					NumVfs:     4,
+					PciAddress: "0000:86:00.1",
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "prevres",
+							VfRange:      "2-3",
+							PolicyName:   "p2",
+						},
+					},
+					FlowRules: []v1.FlowRule{
+						{Name: "trap-lacp", Match: v1.FlowMatch{EthType: "0x8809"}, Action: v1.FlowActionTrap},
+						{Name: "drop-arp", Match: v1.FlowMatch{EthType: "arp"}, Action: v1.FlowActionDrop},
+					},
+				},
+			},
+		},
+		{
+			tname:        "mirror VF",
+			currentState: newNodeState(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector.PfNames = []string{"ens803f1#1-1"}
+				p.Spec.EswitchMode = v1.ESwithModeSwitchDev
+				p.Spec.Mirror = &v1.MirrorConfig{SourcePf: true, SourceVfs: []int{0}}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:        "ens803f1",
+					NumVfs:      2,
+					PciAddress:  "0000:86:00.1",
+					EswitchMode: v1.ESwithModeSwitchDev,
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "1-1",
+							PolicyName:   "p1",
+						},
+					},
+					FlowRules: []v1.FlowRule{
+						{Name: "mirror-p1-pf", Action: v1.FlowActionMirror, TargetVf: intPtr(1)},
+						{Name: "mirror-p1-vf0", Vf: intPtr(0), Action: v1.FlowActionMirror, TargetVf: intPtr(1)},
+					},
+				},
+			},
+		},
 	}
 	for _, tc := range testtable {
 		t.Run(tc.tname, func(t *testing.T) {
@@ -709,6 +927,56 @@ func TestSriovNetworkNodePolicyApply(t *testing.T) {
 	}
 }
 
+func TestLinkStateSelectionLatched(t *testing.T) {
+	policy := newNodePolicy()
+	policy.Generation = 1
+	policy.Spec.NicSelector.LinkUp = true
+	state := newNodeState()
+	state.Status.Interfaces[1].LinkState = consts.LinkStateUp
+
+	render := func() v1.Interfaces {
+		// the spec is rendered again from the policies at each reconcile
+		state.Spec = v1.SriovNetworkNodeStateSpec{}
+		if err := policy.Apply(state, false); err != nil {
+			t.Fatalf("failed to apply the policy: %v", err)
+		}
+		v1.PruneSelectedPfs(state, []string{policy.Name})
+		return state.Spec.Interfaces
+	}
+	selected := render()
+	if len(selected) != 1 {
+		t.Fatalf("expected the connected PF to be selected, got %v", selected)
+	}
+	if got := state.Annotations[consts.SelectedPfsAnnotation]; got != `{"p1":{"generation":1,"pciAddresses":["0000:86:00.1"]}}` {
+		t.Errorf("unexpected selected PFs annotation %q", got)
+	}
+
+	// the link flaps, the spec stays the same
+	for _, linkState := range []string{consts.LinkStateDown, consts.LinkStateUp, consts.LinkStateDown} {
+		state.Status.Interfaces[1].LinkState = linkState
+		if diff := cmp.Diff(selected, render()); diff != "" {
+			t.Errorf("SriovNetworkNodeState spec changed with the link %s (-want +got):\n%s", linkState, diff)
+		}
+	}
+
+	// the policy changes, the predicates are evaluated again
+	policy.Generation = 2
+	if interfaces := render(); len(interfaces) != 0 {
+		t.Errorf("expected the PF with the link down to be unselected, got %v", interfaces)
+	}
+	if _, ok := state.Annotations[consts.SelectedPfsAnnotation]; ok {
+		t.Errorf("expected no selected PFs annotation")
+	}
+
+	// the policy no longer applied to the node is forgotten
+	state.Status.Interfaces[1].LinkState = consts.LinkStateUp
+	render()
+	v1.PruneSelectedPfs(state, nil)
+	if _, ok := state.Annotations[consts.SelectedPfsAnnotation]; ok {
+		t.Errorf("expected no selected PFs annotation")
+	}
+}
+
 func TestVirtioVdpaNodePolicyApply(t *testing.T) {
 	testtable := []struct {
 		tname              string
@@ -802,3 +1070,1151 @@ func TestVhostVdpaNodePolicyApply(t *testing.T) {
 		})
 	}
 }
+
+func TestCompactVirtualFunctions(t *testing.T) {
+	vfs := []v1.VirtualFunction{
+		{VfID: 2, Name: "ens1f0v2", PciAddress: "0000:86:02.2", Driver: "vfio-pci", Vendor: "8086", DeviceID: "154c"},
+		{VfID: 0, Name: "ens1f0v0", PciAddress: "0000:86:02.0", Driver: "iavf", Vendor: "8086", DeviceID: "154c", Mtu: 1500},
+		{VfID: 1, Name: "ens1f0v1", PciAddress: "0000:86:02.1", Driver: "iavf", Vendor: "8086", DeviceID: "154c", Mtu: 1500},
+		{VfID: 3, Name: "ens1f0v3", PciAddress: "0000:86:02.3", Driver: "vfio-pci", Vendor: "8086", DeviceID: "154c"},
+	}
+	expected := []v1.VirtualFunctionRange{
+		{VfRange: "0-1", Driver: "iavf", Vendor: "8086", DeviceID: "154c", Mtu: 1500},
+		{VfRange: "2-3", Driver: "vfio-pci", Vendor: "8086", DeviceID: "154c"},
+	}
+
+	ranges := v1.CompactVirtualFunctions(vfs)
+	if diff := cmp.Diff(expected, ranges); diff != "" {
+		t.Errorf("VF ranges diff (-want +got):\n%s", diff)
+	}
+
+	iface := v1.InterfaceExt{VFsSummary: ranges}
+	expanded := iface.GetVirtualFunctions()
+	if len(expanded) != len(vfs) {
+		t.Fatalf("expected %d VFs, got %d", len(vfs), len(expanded))
+	}
+	for i, vf := range expanded {
+		if vf.VfID != i {
+			t.Errorf("expected VF %d, got %d", i, vf.VfID)
+		}
+	}
+
+	spec := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{{DeviceType: "vfio-pci", VfRange: "2-3"}}}
+	if v1.NeedToUpdateSriov(spec, &v1.InterfaceExt{NumVfs: 4, VFsSummary: ranges}) {
+		t.Errorf("no update expected for a matching compact status")
+	}
+	spec.VfGroups[0].VfRange = "1-3"
+	if !v1.NeedToUpdateSriov(spec, &v1.InterfaceExt{NumVfs: 4, VFsSummary: ranges}) {
+		t.Errorf("update expected when a VF of the compact status has the wrong driver")
+	}
+}
+
+func TestValidateTenantNetwork(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"team": "a"}}}
+	grants := []v1.SriovNetworkGrant{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+			Spec: v1.SriovNetworkGrantSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+				ResourceNames:     []string{"nic1", "nic2"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec: v1.SriovNetworkGrantSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				ResourceNames:     []string{"nic1"},
+				VlanRanges:        []v1.VlanRange{"0", "100-199"},
+				IPAMBlocks:        []string{"10.10.0.0/16"},
+				AllowLinkState:    true,
+				Capabilities:      []string{"ips"},
+			},
+		},
+	}
+
+	tcs := []struct {
+		name string
+		spec v1.SriovNetworkSpec
+		err  string
+	}{
+		{
+			name: "granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", Vlan: 150,
+				IPAM: `{"type":"host-local","ranges":[[{"subnet":"10.10.1.0/24"}]]}`},
+		},
+		{
+			name: "untagged",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1"},
+		},
+		{
+			name: "resource not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic2"},
+			err:  "team-a: resource nic2 is not granted",
+		},
+		{
+			name: "VLAN not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", Vlan: 200},
+			err:  "team-a: VLAN 200 is not granted",
+		},
+		{
+			name: "IPAM subnet not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", IPAM: `{"type":"whereabouts","range":"10.0.0.0/8"}`},
+			err:  "team-a: IPAM subnet 10.0.0.0/8 is not granted",
+		},
+		{
+			name: "IPAM without addresses",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", IPAM: `{"type":"dhcp"}`},
+			err:  "team-a: IPAM configuration doesn't declare its addresses",
+		},
+		{
+			name: "other network namespace",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", NetworkNamespace: "default"},
+			err:  "networkNamespace of a SriovNetwork created in namespace tenant-a must be empty or tenant-a",
+		},
+		{
+			name: "VF settings and capabilities granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", Trust: "off", SpoofChk: "on", LinkState: "enable",
+				Capabilities: `{"ips": true, "mac": false}`},
+		},
+		{
+			name: "trust not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", Trust: "on"},
+			err:  "team-a: trusted VFs are not granted",
+		},
+		{
+			name: "multiple unicast MACs not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", AddressSharing: &v1.AddressSharing{MultipleUnicastMacs: true}},
+			err:  "team-a: trusted VFs are not granted",
+		},
+		{
+			name: "spoof checking not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", SpoofChk: "off"},
+			err:  "team-a: disabling the spoof checking is not granted",
+		},
+		{
+			name: "capability not granted",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", Capabilities: `{"mac": true}`},
+			err:  "team-a: capability mac is not granted",
+		},
+		{
+			name: "metaplugins",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", MetaPluginsConfig: `{"type":"tuning"}`},
+			err:  "metaPlugins are not allowed",
+		},
+		{
+			name: "resource injection",
+			spec: v1.SriovNetworkSpec{ResourceName: "nic1", ResourceInjection: &v1.ResourceInjection{Skip: true}},
+			err:  "resourceInjection is not allowed",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			network := &v1.SriovNetwork{ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: ns.Name}, Spec: tc.spec}
+			err := v1.ValidateTenantNetwork(network, ns, grants)
+			if tc.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+
+	other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-c"}}
+	network := &v1.SriovNetwork{ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: other.Name}, Spec: v1.SriovNetworkSpec{ResourceName: "nic1"}}
+	if err := v1.ValidateTenantNetwork(network, other, grants); err == nil || !strings.Contains(err.Error(), "no SriovNetworkGrant allows") {
+		t.Errorf("expected the network of a namespace without grant to be rejected, got %v", err)
+	}
+}
+
+func TestApplyOverlays(t *testing.T) {
+	newDaemonSet := func() *uns.Unstructured {
+		return &uns.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "DaemonSet",
+			"metadata":   map[string]interface{}{"name": "sriov-device-plugin", "namespace": "sriov-network-operator"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "sriov-device-plugin", "image": "device-plugin"},
+						},
+					},
+				},
+			},
+		}}
+	}
+	containerNames := func(obj *uns.Unstructured) []string {
+		containers, _, _ := uns.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		names := []string{}
+		for _, c := range containers {
+			names = append(names, c.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+	target := v1.OverlayTarget{Kind: "DaemonSet", Name: "sriov-device-plugin"}
+
+	// the strategic merge patch adds the sidecar to the containers
+	ds := newDaemonSet()
+	other := newDaemonSet()
+	other.SetName("sriov-network-config-daemon")
+	err := v1.ApplyOverlays([]*uns.Unstructured{ds, other}, []v1.ManifestOverlay{{
+		Name:   "sidecar",
+		Target: target,
+		Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: sidecar`,
+	}})
+	if err != nil {
+		t.Fatalf("ApplyOverlays() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"sidecar", "sriov-device-plugin"}, containerNames(ds)); diff != "" {
+		t.Errorf("unexpected containers (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"sriov-device-plugin"}, containerNames(other)); diff != "" {
+		t.Errorf("the object not targeted was patched (-want +got):\n%s", diff)
+	}
+
+	// JSON patch
+	ds = newDaemonSet()
+	err = v1.ApplyOverlays([]*uns.Unstructured{ds}, []v1.ManifestOverlay{{
+		Name:   "image",
+		Target: target,
+		Type:   v1.JSON6902Overlay,
+		Patch:  `[{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "custom"}]`,
+	}})
+	if err != nil {
+		t.Fatalf("ApplyOverlays() failed: %v", err)
+	}
+	containers, _, _ := uns.NestedSlice(ds.Object, "spec", "template", "spec", "containers")
+	if image := containers[0].(map[string]interface{})["image"]; image != "custom" {
+		t.Errorf("unexpected image %v", image)
+	}
+
+	// the patches can't rename the objects
+	err = v1.ApplyOverlays([]*uns.Unstructured{newDaemonSet()}, []v1.ManifestOverlay{{
+		Name:   "rename",
+		Target: target,
+		Patch:  `{"metadata": {"name": "other"}}`,
+	}})
+	if err == nil {
+		t.Errorf("expected an error renaming the object")
+	}
+}
+
+func TestManifestOverlayValidate(t *testing.T) {
+	testtable := []struct {
+		tname   string
+		overlay v1.ManifestOverlay
+		valid   bool
+	}{
+		{"yaml merge patch", v1.ManifestOverlay{Name: "a", Patch: "spec:\n  replicas: 1"}, true},
+		{"json patch", v1.ManifestOverlay{Name: "a", Type: v1.JSON6902Overlay, Patch: `[{"op": "remove", "path": "/spec"}]`}, true},
+		{"merge patch not an object", v1.ManifestOverlay{Name: "a", Patch: "- a"}, false},
+		{"invalid json patch", v1.ManifestOverlay{Name: "a", Type: v1.JSON6902Overlay, Patch: `{"op": "remove"}`}, false},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			err := tc.overlay.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestFeatureGateEnabled(t *testing.T) {
+	config := &v1.SriovOperatorConfig{
+		Spec: v1.SriovOperatorConfigSpec{
+			EnableInjector:    true,
+			ConfigurationMode: v1.SystemdConfigurationMode,
+			FeatureGates:      map[string]bool{consts.ResourceInjectorFeatureGate: false, consts.MetricsExporterFeatureGate: true},
+		},
+	}
+	expected := map[string]bool{
+		consts.ResourceInjectorFeatureGate: false,
+		consts.OperatorWebhookFeatureGate:  false,
+		consts.MetricsExporterFeatureGate:  true,
+		consts.SystemdModeFeatureGate:      true,
+		consts.MonitoringFeatureGate:       false,
+	}
+	for gate, enabled := range expected {
+		if config.FeatureGateEnabled(gate) != enabled {
+			t.Errorf("feature gate %s: expected %v", gate, enabled)
+		}
+	}
+}
+
+func TestParseFeatureGates(t *testing.T) {
+	testtable := []struct {
+		tname    string
+		value    string
+		expected map[string]bool
+		valid    bool
+	}{
+		{"empty", "", map[string]bool{}, true},
+		{"gates", "metricsExporter=true, resourceInjector=false,", map[string]bool{"metricsExporter": true, "resourceInjector": false}, true},
+		{"unknown gate", "foo=true", nil, false},
+		{"missing value", "metricsExporter", nil, false},
+		{"invalid value", "metricsExporter=maybe", nil, false},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			gates, err := v1.ParseFeatureGates(tc.value)
+			if !tc.valid {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, gates); diff != "" {
+				t.Errorf("unexpected feature gates (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNormalizePciAddress(t *testing.T) {
+	testtable := []struct {
+		addr     string
+		expected string
+	}{
+		{"0000:86:00.0", "0000:86:00.0"},
+		{"000C:01:00.0", "000c:01:00.0"},
+		{"c:01:00.0", "000c:01:00.0"},
+		{"01:00.0", "0000:01:00.0"},
+		{"10000:01:00.0", "10000:01:00.0"},
+	}
+	for _, tc := range testtable {
+		if got := v1.NormalizePciAddress(tc.addr); got != tc.expected {
+			t.Errorf("NormalizePciAddress(%s) = %s, expected %s", tc.addr, got, tc.expected)
+		}
+	}
+	if !v1.PciAddressInArray("000c:01:00.0", []string{"0000:86:00.0", "000C:01:00.0"}) {
+		t.Errorf("expected 000c:01:00.0 to match 000C:01:00.0")
+	}
+}
+
+func TestZpciUIDSelection(t *testing.T) {
+	selector := v1.SriovNetworkNicSelector{ZpciUids: []string{"0x1F", "20"}}
+	testtable := []struct {
+		uid      string
+		selected bool
+	}{
+		{"0x1f", true},
+		{"0x0020", true},
+		{"0x21", false},
+		{"", false},
+	}
+	for _, tc := range testtable {
+		iface := &v1.InterfaceExt{PciAddress: "001f:00:00.0", ZpciUID: tc.uid}
+		if selector.Selected(iface) != tc.selected {
+			t.Errorf("zPCI UID %q: expected selected to be %v", tc.uid, tc.selected)
+		}
+	}
+}
+
+func TestQosNeedsUpdate(t *testing.T) {
+	current := &v1.QosConfig{
+		Trust:         "pcp",
+		PfcPriorities: []int{3, 4},
+		PrioToBuffer:  []int{0, 0, 0, 1, 1, 0, 0, 0},
+		BufferSizes:   []int{130944, 130944, 0, 0, 0, 0, 0, 0},
+	}
+	testtable := []struct {
+		name     string
+		desired  *v1.QosConfig
+		expected bool
+	}{
+		{"same settings", &v1.QosConfig{Trust: "pcp", PfcPriorities: []int{4, 3}}, false},
+		{"buffers prefix", &v1.QosConfig{PrioToBuffer: []int{0, 0, 0, 1}, BufferSizes: []int{130944}}, false},
+		{"trust", &v1.QosConfig{Trust: "dscp"}, true},
+		{"pfc", &v1.QosConfig{PfcPriorities: []int{3}}, true},
+		{"pfc disabled", &v1.QosConfig{PfcPriorities: []int{}}, true},
+		{"buffer size", &v1.QosConfig{BufferSizes: []int{262016}}, true},
+	}
+	for _, tc := range testtable {
+		if got := v1.QosNeedsUpdate(tc.desired, current); got != tc.expected {
+			t.Errorf("%s: expected QosNeedsUpdate to be %v", tc.name, tc.expected)
+		}
+	}
+	if !v1.QosNeedsUpdate(&v1.QosConfig{Trust: "pcp"}, nil) {
+		t.Errorf("expected an update when the QoS is not reported")
+	}
+}
+
+func TestCongestionControlNeedsUpdate(t *testing.T) {
+	cnpDscp, otherDscp := 48, 26
+	current := &v1.CongestionControlConfig{
+		EcnPriorities: []int{3, 4},
+		CnpDscp:       &cnpDscp,
+		MinRate:       1,
+		TimeReset:     300,
+	}
+	testtable := []struct {
+		name     string
+		desired  *v1.CongestionControlConfig
+		expected bool
+	}{
+		{"same settings", &v1.CongestionControlConfig{EcnPriorities: []int{4, 3}, CnpDscp: &cnpDscp, MinRate: 1}, false},
+		{"unset parameters", &v1.CongestionControlConfig{}, false},
+		{"ecn priorities", &v1.CongestionControlConfig{EcnPriorities: []int{3}}, true},
+		{"cnp dscp", &v1.CongestionControlConfig{CnpDscp: &otherDscp}, true},
+		{"unreported parameter", &v1.CongestionControlConfig{CnpPriority: &cnpDscp}, true},
+		{"time reset", &v1.CongestionControlConfig{TimeReset: 100}, true},
+	}
+	for _, tc := range testtable {
+		if got := v1.CongestionControlNeedsUpdate(tc.desired, current); got != tc.expected {
+			t.Errorf("%s: expected CongestionControlNeedsUpdate to be %v", tc.name, tc.expected)
+		}
+	}
+	if !v1.CongestionControlNeedsUpdate(&v1.CongestionControlConfig{}, nil) {
+		t.Errorf("expected an update when the congestion control is not reported")
+	}
+}
+
+func TestPtpSourceDisrupted(t *testing.T) {
+	status := &v1.InterfaceExt{
+		Name:        "ens1f0",
+		NumVfs:      4,
+		EswitchMode: v1.ESwithModeLegacy,
+		LinkType:    "ETH",
+		Ptp:         &v1.PtpInfo{Clock: "ptp0", TimeSource: true},
+	}
+	testtable := []struct {
+		name     string
+		spec     *v1.Interface
+		expected bool
+	}{
+		{"same configuration", &v1.Interface{NumVfs: 4, LinkType: "eth"}, false},
+		{"numVfs", &v1.Interface{NumVfs: 8}, true},
+		{"eSwitch mode", &v1.Interface{NumVfs: 4, EswitchMode: v1.ESwithModeSwitchDev}, true},
+		{"link type", &v1.Interface{NumVfs: 4, LinkType: "ib"}, true},
+	}
+	for _, tc := range testtable {
+		if got := v1.PtpSourceDisrupted(tc.spec, status); got != tc.expected {
+			t.Errorf("%s: expected PtpSourceDisrupted to be %v", tc.name, tc.expected)
+		}
+	}
+	status.Ptp.TimeSource = false
+	if v1.PtpSourceDisrupted(&v1.Interface{NumVfs: 8}, status) {
+		t.Errorf("expected no disruption of a PF that is not the time source")
+	}
+}
+
+func TestNeedToUpdateSriovHwTimestamping(t *testing.T) {
+	spec := &v1.Interface{NumVfs: 2, VfGroups: []v1.VfGroup{{DeviceType: "netdevice", VfRange: "0-1", HwTimestamping: true}}}
+	testtable := []struct {
+		name     string
+		state    string
+		expected bool
+	}{
+		{"enabled", consts.HwTimestampingEnabled, false},
+		{"supported", consts.HwTimestampingSupported, true},
+		{"not supported", "", false},
+	}
+	for _, tc := range testtable {
+		status := &v1.InterfaceExt{NumVfs: 2, VFs: []v1.VirtualFunction{
+			{VfID: 0, Driver: "mlx5_core", HwTimestamping: consts.HwTimestampingEnabled},
+			{VfID: 1, Driver: "mlx5_core", HwTimestamping: tc.state},
+		}}
+		if got := v1.NeedToUpdateSriov(spec, status); got != tc.expected {
+			t.Errorf("%s: expected NeedToUpdateSriov to be %v", tc.name, tc.expected)
+		}
+		// the hardware timestamping is enabled without draining the node
+		if v1.NeedToDrainForSriov(spec, status) {
+			t.Errorf("%s: expected NeedToDrainForSriov to be false", tc.name)
+		}
+	}
+}
+
+func TestNeedToUpdateSriovDriversAutoprobe(t *testing.T) {
+	spec := &v1.Interface{NumVfs: 2, DisableDriversAutoprobe: true,
+		VfGroups: []v1.VfGroup{{DeviceType: "vfio-pci", VfRange: "0-1"}}}
+	status := &v1.InterfaceExt{NumVfs: 2, VFs: []v1.VirtualFunction{
+		{VfID: 0, Driver: "vfio-pci"},
+		{VfID: 1, Driver: "vfio-pci"},
+	}}
+	if !v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("expected the VFs to be created again to disable the drivers autoprobe")
+	}
+	status.DriversAutoprobeDisabled = true
+	if v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("expected no update once the drivers autoprobe is disabled")
+	}
+	spec.DisableDriversAutoprobe = false
+	if !v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("expected the VFs to be created again to enable the drivers autoprobe")
+	}
+}
+
+func TestNeedToUpdateSriovMsixCount(t *testing.T) {
+	spec := &v1.Interface{NumVfs: 2, VfGroups: []v1.VfGroup{{DeviceType: "vfio-pci", VfRange: "0-1", MsixCount: 16}}}
+	status := &v1.InterfaceExt{NumVfs: 2, VFs: []v1.VirtualFunction{
+		{VfID: 0, Driver: "vfio-pci", MsixCount: 16},
+		{VfID: 1, Driver: "vfio-pci", MsixCount: 16},
+	}}
+	if v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("no update expected for the VFs with the desired MSI-X vectors")
+	}
+	status.VFs[1].MsixCount = 8
+	if !v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("expected an update of the MSI-X vectors of the VF")
+	}
+}
+
+func TestPcieLinkDegradedCondition(t *testing.T) {
+	interfaces := v1.InterfaceExts{
+		{Name: "ens1f0", PciAddress: "0000:d8:00.0", PcieLink: &v1.PcieLinkInfo{
+			Speed: "16.0 GT/s PCIe", MaxSpeed: "16.0 GT/s PCIe", Width: 16, MaxWidth: 16}},
+		{Name: "ens2f0", PciAddress: "0000:3b:00.0"},
+	}
+	cond := v1.PcieLinkDegradedCondition(interfaces, 3)
+	if cond.Type != v1.ConditionPcieLinkDegraded || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 3 {
+		t.Errorf("unexpected condition for the links at their capability: %+v", cond)
+	}
+
+	interfaces[0].PcieLink.Width = 8
+	interfaces[0].PcieLink.Degraded = true
+	cond = v1.PcieLinkDegradedCondition(interfaces, 3)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "LinkBelowCapability" {
+		t.Errorf("unexpected condition for a degraded link: %+v", cond)
+	}
+	if !strings.Contains(cond.Message, "ens1f0(0000:d8:00.0) x8 16.0 GT/s PCIe, capable of x16 16.0 GT/s PCIe") {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+}
+
+func TestDpdkPrerequisitesCondition(t *testing.T) {
+	interfaces := v1.Interfaces{
+		{PciAddress: "0000:d8:00.0", VfGroups: []v1.VfGroup{
+			{PolicyName: "kernel", DeviceType: consts.DeviceTypeNetDevice, VfRange: "0-3"},
+			{PolicyName: "dpdk", DeviceType: consts.DeviceTypeVfioPci, VfRange: "4-7", RequireIsolatedCpus: true},
+		}},
+	}
+	cond := v1.DpdkPrerequisitesCondition(interfaces, 512, "2-15", 2)
+	if cond.Type != v1.ConditionDpdkPrerequisitesMissing || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 2 {
+		t.Errorf("unexpected condition for a node meeting the prerequisites: %+v", cond)
+	}
+
+	cond = v1.DpdkPrerequisitesCondition(interfaces, 0, "", 2)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "PrerequisitesMissing" {
+		t.Errorf("unexpected condition for a node without hugepages and isolated CPUs: %+v", cond)
+	}
+	if cond.Message != "no hugepages are allocated for the vfio-pci VFs of the policies dpdk; "+
+		"no CPUs are isolated for the vfio-pci VFs of the policies dpdk" {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+
+	// the netdevice VFs need neither hugepages nor isolated CPUs
+	interfaces[0].VfGroups = interfaces[0].VfGroups[:1]
+	cond = v1.DpdkPrerequisitesCondition(interfaces, 0, "", 2)
+	if cond.Status != metav1.ConditionFalse {
+		t.Errorf("unexpected condition for a node without vfio-pci VFs: %+v", cond)
+	}
+}
+
+func TestNicSelectorSelectsSubports(t *testing.T) {
+	iface := &v1.InterfaceExt{
+		PciAddress: "0000:04:00.0",
+		NetFilter:  "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7",
+		Subports: []v1.OpenstackSubport{
+			{NetFilter: "openstack/NetworkID:4dff9e7c-05e4-4a2a-9a5b-5bcd7e3c1b07", VlanID: 101},
+		},
+	}
+	for netFilter, selected := range map[string]bool{
+		"openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7": true,
+		"openstack/NetworkID:4dff9e7c-05e4-4a2a-9a5b-5bcd7e3c1b07": true,
+		"openstack/NetworkID:0b3e7d4f-6f4c-4a8e-9a54-7c3c2b1e0f11": false,
+	} {
+		selector := v1.SriovNetworkNicSelector{NetFilter: netFilter}
+		if selector.Selected(iface) != selected {
+			t.Errorf("unexpected selection of the interface by %s, expected %t", netFilter, selected)
+		}
+	}
+}
+
+func TestNicSelectorSkipsUnmanagedInterfaces(t *testing.T) {
+	iface := &v1.InterfaceExt{PciAddress: "0000:05:00.0", Vendor: "1af4", DeviceID: "1041", Unmanaged: true}
+	selector := v1.SriovNetworkNicSelector{Vendor: "1af4"}
+	if selector.Selected(iface) {
+		t.Errorf("unexpected selection of the unmanaged interface")
+	}
+	iface.Unmanaged = false
+	if !selector.Selected(iface) {
+		t.Errorf("expected the selection of the managed interface")
+	}
+}
+
+// subnetNetFilterProvider is the NetFilter provider of a fake platform selecting the devices by the prefix of their subnet
+type subnetNetFilterProvider struct{}
+
+func (subnetNetFilterProvider) Prefix() string {
+	return "test/SubnetID"
+}
+
+func (subnetNetFilterProvider) Match(filterValue, deviceValue string) bool {
+	return strings.HasPrefix(deviceValue, filterValue)
+}
+
+func TestNetFilterMatchUsesTheProviderOfThePrefix(t *testing.T) {
+	v1.RegisterNetFilterProvider(subnetNetFilterProvider{})
+	for _, tc := range []struct {
+		netFilter, netValue string
+		match               bool
+	}{
+		{"test/SubnetID:subnet-0b", "test/SubnetID:subnet-0bb1c79de3", true},
+		{"test/SubnetID:subnet-0c", "test/SubnetID:subnet-0bb1c79de3", false},
+		{"openstack/NetworkID:da5bb487", "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7", false},
+		{" openstack/NetworkID : da5bb487-5193-4a65-a3df-4a0055a8c0d7", "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7", true},
+		{"test/SubnetID:subnet-0b", "openstack/NetworkID:subnet-0bb1c79de3", false},
+		{"unknown/ID:a", "unknown/ID:a", false},
+		{"da5bb487", "openstack/NetworkID:da5bb487", false},
+	} {
+		if v1.NetFilterMatch(tc.netFilter, tc.netValue) != tc.match {
+			t.Errorf("unexpected match of %q by %q, expected %t", tc.netValue, tc.netFilter, tc.match)
+		}
+	}
+}
+
+func TestParseNetFilter(t *testing.T) {
+	prefix, value, err := v1.ParseNetFilter("openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7")
+	if err != nil || prefix != "openstack/NetworkID" || value != "da5bb487-5193-4a65-a3df-4a0055a8c0d7" {
+		t.Errorf("unexpected parsing: %q %q %v", prefix, value, err)
+	}
+	if v1.NewNetFilter(prefix, value) != "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7" {
+		t.Errorf("unexpected NetFilter %q", v1.NewNetFilter(prefix, value))
+	}
+	for _, netFilter := range []string{"", "openstack/NetworkID", "openstack/NetworkID:", ":da5bb487", "openstack/NetworkID:a b"} {
+		if _, _, err := v1.ParseNetFilter(netFilter); err == nil {
+			t.Errorf("expected an error parsing %q", netFilter)
+		}
+	}
+}
+
+func TestMtuMismatchCondition(t *testing.T) {
+	cond := v1.MtuMismatchCondition(nil, 5)
+	if cond.Type != v1.ConditionMtuMismatch || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 5 {
+		t.Errorf("unexpected condition for matching MTUs: %+v", cond)
+	}
+
+	cond = v1.MtuMismatchCondition([]string{"ens4(0000:04:00.0) 1500, declared 8942"}, 5)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "MtuDiffersFromPlatform" {
+		t.Errorf("unexpected condition for a MTU mismatch: %+v", cond)
+	}
+	if !strings.HasSuffix(cond.Message, ": ens4(0000:04:00.0) 1500, declared 8942") {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+}
+
+func TestInterfaceClaimConflictCondition(t *testing.T) {
+	cond := v1.InterfaceClaimConflictCondition(nil, 3)
+	if cond.Type != v1.ConditionInterfaceClaimConflict || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 3 {
+		t.Errorf("unexpected condition without conflicts: %+v", cond)
+	}
+
+	cond = v1.InterfaceClaimConflictCondition([]string{"ens1f0 is claimed by nmstate"}, 3)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "ClaimedByAnotherManager" {
+		t.Errorf("unexpected condition for a conflict: %+v", cond)
+	}
+	if !strings.HasSuffix(cond.Message, ": ens1f0 is claimed by nmstate") {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+}
+
+func TestHostDriftCondition(t *testing.T) {
+	cond := v1.HostDriftCondition(nil, 2)
+	if cond.Type != v1.ConditionHostDrift || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 2 {
+		t.Errorf("unexpected condition without drift: %+v", cond)
+	}
+
+	cond = v1.HostDriftCondition([]string{"ens1f0(0000:3b:00.0) numVfs 0, applied 4"}, 2)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "DiffersFromAppliedSpec" {
+		t.Errorf("unexpected condition for a drift: %+v", cond)
+	}
+	if !strings.HasSuffix(cond.Message, ": ens1f0(0000:3b:00.0) numVfs 0, applied 4") {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+}
+
+func TestMetadataIncompleteCondition(t *testing.T) {
+	cond := v1.MetadataIncompleteCondition(nil, nil, 4)
+	if cond.Type != v1.ConditionMetadataIncomplete || cond.Status != metav1.ConditionFalse || cond.ObservedGeneration != 4 {
+		t.Errorf("unexpected condition for a fully decoded metadata: %+v", cond)
+	}
+
+	cond = v1.MetadataIncompleteCondition([]string{"link tap1: invalid integer \"jumbo\"", "network #2: unexpected end of JSON input"}, nil, 4)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "MalformedEntries" {
+		t.Errorf("unexpected condition for malformed entries: %+v", cond)
+	}
+	if cond.Message != "the malformed entries of the OpenStack metadata were skipped: "+
+		"link tap1: invalid integer \"jumbo\"; network #2: unexpected end of JSON input" {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+
+	cond = v1.MetadataIncompleteCondition(nil, []string{"device fa:16:3e:22:22:22: no device found with MAC address fa:16:3e:22:22:22"}, 4)
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "UnresolvedDevices" {
+		t.Errorf("unexpected condition for unresolved devices: %+v", cond)
+	}
+	if cond.Message != "the devices not found by MAC address keep the PCI address of the OpenStack metadata: "+
+		"device fa:16:3e:22:22:22: no device found with MAC address fa:16:3e:22:22:22" {
+		t.Errorf("unexpected message: %s", cond.Message)
+	}
+}
+
+func TestVfioPciModuleOptions(t *testing.T) {
+	interfaces := v1.Interfaces{
+		{PciAddress: "0000:d8:00.0", VfGroups: []v1.VfGroup{
+			{PolicyName: "kernel", DeviceType: consts.DeviceTypeNetDevice, VfRange: "0-3"},
+		}},
+	}
+	if options := v1.VfioPciModuleOptions(interfaces); options != nil {
+		t.Errorf("unexpected options without vfio-pci VFs: %v", options)
+	}
+
+	interfaces[0].VfGroups = append(interfaces[0].VfGroups, v1.VfGroup{PolicyName: "dpdk", DeviceType: consts.DeviceTypeVfioPci, VfRange: "4-7"})
+	if options := v1.VfioPciModuleOptions(interfaces); options != nil {
+		t.Errorf("unexpected options without disableIdleD3: %v", options)
+	}
+
+	interfaces[0].VfGroups[1].DisableIdleD3 = true
+	if options := v1.VfioPciModuleOptions(interfaces); options[consts.VfioPciOptionDisableIdleD3] != "1" {
+		t.Errorf("unexpected options with disableIdleD3: %v", options)
+	}
+}
+
+func TestValidateExtendedResourceName(t *testing.T) {
+	t.Setenv("RESOURCE_PREFIX", "openshift.io")
+	if name := v1.ExtendedResourceName("nic1"); name != "openshift.io/nic1" {
+		t.Errorf("unexpected extended resource name: %s", name)
+	}
+	if err := v1.ValidateExtendedResourceName("nic1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := v1.ValidateExtendedResourceName(strings.Repeat("a", 64)); err == nil {
+		t.Error("expected an error for a resource name longer than 63 characters")
+	}
+
+	for prefix, expected := range map[string]string{
+		"":                     "resource prefix of the operator is empty",
+		"kubernetes.io":        "resource prefix kubernetes.io is reserved to the kubernetes resources",
+		"nvidia.kubernetes.io": "resource prefix nvidia.kubernetes.io is reserved to the kubernetes resources",
+		"requests.example.com": "resource prefix requests.example.com is reserved to the resource quotas",
+		"Example.com":          "extended resource Example.com/nic1 is invalid",
+	} {
+		t.Setenv("RESOURCE_PREFIX", prefix)
+		if err := v1.ValidateExtendedResourceName("nic1"); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("unexpected error for prefix %q: %v", prefix, err)
+		}
+	}
+}
+
+func TestValidateResourceInjection(t *testing.T) {
+	for injection, expected := range map[v1.ResourceInjection]string{
+		{Skip: true}: "",
+		{ResourceName: "rdma/hca_shared_devices_a"}:   "",
+		{ResourceName: "shared"}:                      "injected resource shared must be an extended resource",
+		{ResourceName: "rdma/hca shared"}:             "injected resource rdma/hca shared is invalid",
+		{ResourceName: "rdma/hca_shared", Skip: true}: "the resourceName of the resourceInjection must be empty",
+	} {
+		injection := injection
+		network := &v1.SriovNetwork{Spec: v1.SriovNetworkSpec{ResourceName: "nic1", ResourceInjection: &injection}}
+		err := network.ValidateResourceInjection()
+		if expected == "" && err != nil {
+			t.Errorf("unexpected error for %+v: %v", injection, err)
+		}
+		if expected != "" && (err == nil || !strings.HasPrefix(err.Error(), expected)) {
+			t.Errorf("unexpected error for %+v: %v", injection, err)
+		}
+	}
+}
+
+func TestPolicyDeprecationWarnings(t *testing.T) {
+	policy := &v1.SriovNetworkNodePolicy{Spec: v1.SriovNetworkNodePolicySpec{NicSelector: v1.SriovNetworkNicSelector{
+		PfNames:   []string{"ens1f0", "ens1f1#0-3"},
+		NetFilter: "openstack/NetworkID:ada9ec67-2c97-467c-b674-c47200e2f5da",
+	}}}
+	expected := []string{
+		"spec.nicSelector.pfNames: the VF range encoded in the PF name, ens1f1#0-3, is deprecated, " +
+			"use the vfRange of spec.nicSelector.pfs in sriovnetwork.openshift.io/v2",
+		"spec.nicSelector.netFilter: the string encoded OpenStack network, openstack/NetworkID:ada9ec67-2c97-467c-b674-c47200e2f5da, " +
+			"is deprecated, use the openstackNetworkID of spec.nicSelector.netFilter in sriovnetwork.openshift.io/v2",
+	}
+	if warnings := policy.DeprecationWarnings(); !cmp.Equal(warnings, expected) {
+		t.Errorf("unexpected warnings: %s", cmp.Diff(warnings, expected))
+	}
+
+	policy.Spec.NicSelector = v1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}}
+	if warnings := policy.DeprecationWarnings(); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestEffectiveDrainMode(t *testing.T) {
+	state := func(poolMode string, groupModes ...string) *v1.SriovNetworkNodeState {
+		s := &v1.SriovNetworkNodeState{Spec: v1.SriovNetworkNodeStateSpec{DrainMode: poolMode}}
+		for i, mode := range groupModes {
+			pciAddress := fmt.Sprintf("0000:86:00.%d", i)
+			s.Spec.Interfaces = append(s.Spec.Interfaces, v1.Interface{
+				PciAddress: pciAddress,
+				NumVfs:     4,
+				VfGroups:   []v1.VfGroup{{ResourceName: "nic1", VfRange: "0-3", DrainMode: mode}},
+			})
+			s.Status.Interfaces = append(s.Status.Interfaces, v1.InterfaceExt{PciAddress: pciAddress})
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		name         string
+		state        *v1.SriovNetworkNodeState
+		disableDrain bool
+		expected     string
+	}{
+		{"default", state(""), false, v1.DrainModeDrain},
+		{"drain disabled", state(""), true, v1.DrainModeNone},
+		{"pool overrides the operator config", state(v1.DrainModeCordon), true, v1.DrainModeCordon},
+		{"policy overrides the pool", state(v1.DrainModeCordon, v1.DrainModeNone), false, v1.DrainModeNone},
+		{"policy overrides the operator config", state("", v1.DrainModeDrain), true, v1.DrainModeDrain},
+		{"most disruptive policy", state("", v1.DrainModeNone, v1.DrainModeCordon), false, v1.DrainModeCordon},
+		{"policy without drain mode", state(v1.DrainModeNone, ""), false, v1.DrainModeNone},
+		{"policy without drain mode drains with the pool mode", state(v1.DrainModeCordon, v1.DrainModeNone, ""), false, v1.DrainModeCordon},
+		{"policy without drain mode drains by default", state("", v1.DrainModeCordon, ""), false, v1.DrainModeDrain},
+		{"policy without drain mode follows disableDrain", state("", v1.DrainModeNone, ""), true, v1.DrainModeNone},
+	} {
+		if mode := tc.state.EffectiveDrainMode(tc.disableDrain); mode != tc.expected {
+			t.Errorf("%s: unexpected drain mode %s, expected %s", tc.name, mode, tc.expected)
+		}
+	}
+
+	// the policies of the PFs that are already configured don't apply
+	s := state(v1.DrainModeCordon, v1.DrainModeDrain)
+	s.Status.Interfaces[0].NumVfs = 4
+	if mode := s.EffectiveDrainMode(false); mode != v1.DrainModeCordon {
+		t.Errorf("unexpected drain mode %s", mode)
+	}
+}
+
+func TestDrainingCondition(t *testing.T) {
+	if condition := v1.DrainingCondition(nil, 3); condition.Status != metav1.ConditionFalse || condition.ObservedGeneration != 3 {
+		t.Errorf("unexpected condition without drain %+v", condition)
+	}
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deadline := metav1.NewTime(start.Add(time.Hour))
+	drain := &v1.DrainStatus{
+		Phase:         v1.DrainPhaseStuck,
+		StartTime:     metav1.NewTime(start),
+		Deadline:      &deadline,
+		PodsRemaining: 2,
+		FailingPods:   []v1.DrainPodFailure{{Namespace: "ns", Name: "db-0", Reason: "the PodDisruptionBudget db allows no disruption"}},
+	}
+	expected := metav1.Condition{
+		Type:   v1.ConditionDraining,
+		Status: metav1.ConditionTrue,
+		Reason: "DrainStuck",
+		Message: "2 pods remaining, 1 failing eviction: ns/db-0 (the PodDisruptionBudget db allows no disruption), " +
+			"started at 2024-01-02T03:04:05Z, deadline 2024-01-02T04:04:05Z",
+		ObservedGeneration: 1,
+	}
+	if diff := cmp.Diff(expected, v1.DrainingCondition(drain, 1)); diff != "" {
+		t.Errorf("unexpected condition (-want +got):\n%s", diff)
+	}
+
+	drain.Phase = v1.DrainPhaseAborted
+	if condition := v1.DrainingCondition(drain, 1); condition.Status != metav1.ConditionFalse || condition.Reason != "DrainAborted" {
+		t.Errorf("unexpected condition of an aborted drain %+v", condition)
+	}
+}
+
+func TestDrainEscalation(t *testing.T) {
+	var disabled *v1.DrainEscalationConfig
+	if phase := disabled.Phase(24 * time.Hour); phase != v1.DrainPhaseDraining {
+		t.Errorf("unexpected phase %s without escalation", phase)
+	}
+	if deadline := disabled.Deadline(time.Now()); deadline != nil {
+		t.Errorf("unexpected deadline %s without escalation", deadline)
+	}
+
+	escalation := &v1.DrainEscalationConfig{WarnAfterMinutes: 10, ForceAfterMinutes: 30, AbortAfterMinutes: 60}
+	for elapsed, expected := range map[time.Duration]string{
+		5 * time.Minute:  v1.DrainPhaseDraining,
+		10 * time.Minute: v1.DrainPhaseStuck,
+		45 * time.Minute: v1.DrainPhaseForcing,
+		2 * time.Hour:    v1.DrainPhaseAborted,
+	} {
+		if phase := escalation.Phase(elapsed); phase != expected {
+			t.Errorf("unexpected phase %s after %s, expected %s", phase, elapsed, expected)
+		}
+	}
+	start := time.Now()
+	if deadline := escalation.Deadline(start); deadline == nil || !deadline.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected deadline %v", deadline)
+	}
+
+	// the disabled steps are skipped
+	escalation.ForceAfterMinutes = 0
+	if phase := escalation.Phase(45 * time.Minute); phase != v1.DrainPhaseStuck {
+		t.Errorf("unexpected phase %s with the force step disabled", phase)
+	}
+	if err := escalation.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	escalation.WarnAfterMinutes = 60
+	if err := escalation.Validate(); err == nil || err.Error() != "drainEscalation abortAfterMinutes (60) must be greater than warnAfterMinutes (60)" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSpecVersions(t *testing.T) {
+	// the legacy config daemons and operators don't report nor render a spec version
+	var legacy *v1.SpecVersionRange
+	if !legacy.Supports(0) || !legacy.Supports(1) || legacy.Supports(2) {
+		t.Errorf("unexpected versions supported by the legacy config daemons %s", legacy)
+	}
+	// the specs rendered by this operator aren't applied by the legacy config daemons
+	if legacy.Supports(v1.NodeStateSpecVersion) || !v1.SupportedSpecVersions().Supports(0) {
+		t.Errorf("unexpected spec version %d supported by the legacy config daemons", v1.NodeStateSpecVersion)
+	}
+	supported := &v1.SpecVersionRange{Min: 2, Max: 3}
+	if supported.Supports(0) || supported.Supports(1) || !supported.Supports(3) || supported.Supports(4) {
+		t.Errorf("unexpected versions supported by %s", supported)
+	}
+
+	state := &v1.SriovNetworkNodeState{}
+	if err := state.CheckSpecVersion(); err != nil {
+		t.Errorf("unexpected error for a legacy spec %v", err)
+	}
+	state.Spec.SpecVersion = v1.NodeStateSpecVersion + 1
+	expected := fmt.Sprintf("the spec version %d is not in the versions %d-%d supported by the config daemon",
+		v1.NodeStateSpecVersion+1, v1.MinNodeStateSpecVersion, v1.NodeStateSpecVersion)
+	if err := state.CheckSpecVersion(); err == nil || err.Error() != expected {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPolicing(t *testing.T) {
+	capped := &v1.PolicingConfig{Egress: &v1.PolicingRate{Rate: 1000, Burst: 64}}
+	networks := []v1.SriovNetwork{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic1",
+			Policing: &v1.PolicingConfig{Egress: &v1.PolicingRate{Rate: 2000, Burst: 64}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic1", Policing: capped}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic2"}},
+	}
+	policing := v1.ResourcePolicing(networks)
+	if diff := cmp.Diff(map[string]*v1.PolicingConfig{"nic1": capped}, policing); diff != "" {
+		t.Errorf("unexpected policing of the resources (-want +got):\n%s", diff)
+	}
+
+	ifaces := v1.Interfaces{{
+		Name:   "ens1f0",
+		NumVfs: 4,
+		VfGroups: []v1.VfGroup{
+			{ResourceName: "nic1", VfRange: "0-1"},
+			{ResourceName: "nic2", VfRange: "2-3"},
+		},
+	}}
+	ifaces.SetPolicing(policing)
+	if diff := cmp.Diff(map[int]*v1.PolicingConfig{0: capped, 1: capped}, ifaces[0].VfPolicing()); diff != "" {
+		t.Errorf("unexpected policing of the VFs (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddressSharing(t *testing.T) {
+	networks := []v1.SriovNetwork{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic1",
+			AddressSharing: &v1.AddressSharing{AllowSpoofing: true, MultipleUnicastMacs: true}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic1",
+			AddressSharing: &v1.AddressSharing{AllowSpoofing: true, ProxyArp: true}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic2",
+			AddressSharing: &v1.AddressSharing{ProxyNdp: true}}},
+		// the exception of a network isn't applied to the VFs of the other networks of its resource
+		{ObjectMeta: metav1.ObjectMeta{Name: "d"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic3",
+			AddressSharing: &v1.AddressSharing{AllowSpoofing: true}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "e"}, Spec: v1.SriovNetworkSpec{ResourceName: "nic3"}},
+	}
+	sharing := v1.ResourceAddressSharing(networks)
+	want := &v1.AddressSharing{AllowSpoofing: true}
+	if diff := cmp.Diff(map[string]*v1.AddressSharing{"nic1": want}, sharing); diff != "" {
+		t.Errorf("unexpected address sharing of the resources (-want +got):\n%s", diff)
+	}
+
+	ifaces := v1.Interfaces{{
+		Name:   "ens1f0",
+		NumVfs: 4,
+		VfGroups: []v1.VfGroup{
+			{ResourceName: "nic1", VfRange: "2-3"},
+			{ResourceName: "nic2", VfRange: "0-1"},
+		},
+	}}
+	ifaces.SetAddressSharing(sharing)
+	if diff := cmp.Diff(map[int]*v1.AddressSharing{2: want, 3: want}, ifaces[0].VfAddressSharing()); diff != "" {
+		t.Errorf("unexpected address sharing of the VFs (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		spec v1.SriovNetworkSpec
+		err  string
+	}{
+		{spec: v1.SriovNetworkSpec{AddressSharing: &v1.AddressSharing{AllowSpoofing: true}, SpoofChk: "off"}},
+		{spec: v1.SriovNetworkSpec{AddressSharing: &v1.AddressSharing{}}, err: "at least one exception"},
+		{spec: v1.SriovNetworkSpec{AddressSharing: &v1.AddressSharing{AllowSpoofing: true}, SpoofChk: "on"}, err: "spoofChk is on"},
+		{spec: v1.SriovNetworkSpec{AddressSharing: &v1.AddressSharing{MultipleUnicastMacs: true}, Trust: "off"}, err: "trust is off"},
+	} {
+		err := (&v1.SriovNetwork{Spec: tc.spec}).ValidateAddressSharing()
+		if tc.err == "" && err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+	}
+}
+
+func TestPfNames(t *testing.T) {
+	policy := func(name, prefix string, pfNames ...string) v1.SriovNetworkNodePolicy {
+		return v1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.SriovNetworkNodePolicySpec{
+			NumVfs: 4, PfNamePrefix: prefix, NicSelector: v1.SriovNetworkNicSelector{PfNames: pfNames}}}
+	}
+	interfaces := v1.InterfaceExts{
+		{Name: "ens2f0", PciAddress: "0000:d8:00.0"},
+		{Name: "sriov3b00f1", OriginalName: "ens1f1", PciAddress: "0000:3b:00.1"},
+		{Name: "ens1f0", PciAddress: "0000:3b:00.0"},
+		{Name: "ens3f0", PciAddress: "0000:af:00.0"},
+	}
+	names := v1.PfNames([]v1.SriovNetworkNodePolicy{
+		policy("a", "sriov", "ens1f0", "ens1f1#0-1", "ens2f0"),
+		policy("b", "data", "ens2f0", "ens3f0"),
+	}, interfaces)
+	want := map[string]string{
+		"0000:3b:00.0": "sriov3b00f0",
+		"0000:3b:00.1": "sriov3b00f1",
+		"0000:d8:00.0": "sriovd800f0",
+		"0000:af:00.0": "dataaf00f0",
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("unexpected names of the PFs (-want +got):\n%s", diff)
+	}
+
+	// the names don't depend on the other PFs of the node
+	names = v1.PfNames([]v1.SriovNetworkNodePolicy{policy("a", "sriov", "ens1f1")}, interfaces)
+	if diff := cmp.Diff(map[string]string{"0000:3b:00.1": "sriov3b00f1"}, names); diff != "" {
+		t.Errorf("unexpected names of the PFs (-want +got):\n%s", diff)
+	}
+	// the domain is kept when it isn't 0000, the names exceeding the maximum length are skipped
+	names = v1.PfNames([]v1.SriovNetworkNodePolicy{policy("a", "sriov", "ens5f0", "ens6f0")}, v1.InterfaceExts{
+		{Name: "ens5f0", PciAddress: "0001:3b:00.0"},
+		{Name: "ens6f0", PciAddress: "10000:3b:00.0"},
+	})
+	if diff := cmp.Diff(map[string]string{"0001:3b:00.0": "sriov13b00f0"}, names); diff != "" {
+		t.Errorf("unexpected names of the PFs (-want +got):\n%s", diff)
+	}
+
+	ifaces := v1.Interfaces{{PciAddress: "0000:3b:00.0", Name: "ens1f0"}, {PciAddress: "0000:5e:00.0", Name: "ens4f0"}}
+	ifaces.SetPfNames(map[string]string{"0000:3b:00.0": "sriov3b00f0"})
+	if ifaces[0].RenameTo != "sriov3b00f0" || ifaces[1].RenameTo != "" {
+		t.Errorf("unexpected renames of the interfaces: %+v", ifaces)
+	}
+}
+
+func TestPortSplit(t *testing.T) {
+	state := &v1.SriovNetworkNodeState{Status: v1.SriovNetworkNodeStateStatus{Interfaces: v1.InterfaceExts{
+		{Name: "ens1f0", PciAddress: "0000:3b:00.0", TotalVfs: 64},
+	}}}
+	split := v1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "split"}, Spec: v1.SriovNetworkNodePolicySpec{
+		PortSplit: 4, NicSelector: v1.SriovNetworkNicSelector{RootDevices: []string{"0000:3b:00.0"}}}}
+	vfs := v1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "vfs"}, Spec: v1.SriovNetworkNodePolicySpec{
+		ResourceName: "vfs", NumVfs: 8, NicSelector: v1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}}}}
+
+	// the policy splitting the port configures the PF without VFs
+	if err := split.Apply(state, false); err != nil {
+		t.Fatalf("failed to apply the policy: %v", err)
+	}
+	if len(state.Spec.Interfaces) != 1 || state.Spec.Interfaces[0].PortSplit != 4 || state.Spec.Interfaces[0].NumVfs != 0 {
+		t.Fatalf("unexpected spec of the split port: %+v", state.Spec.Interfaces)
+	}
+	// the VFs of the other policies are created on the split port
+	if err := vfs.Apply(state, false); err != nil {
+		t.Fatalf("failed to apply the policy: %v", err)
+	}
+	iface := state.Spec.Interfaces[0]
+	if iface.PortSplit != 4 || iface.NumVfs != 8 || len(iface.VfGroups) != 1 {
+		t.Errorf("unexpected spec of the split port: %+v", iface)
+	}
+
+	status := &v1.InterfaceExt{PciAddress: "0000:3b:00.0", NumVfs: 8}
+	spec := &v1.Interface{PciAddress: "0000:3b:00.0", NumVfs: 8, PortSplit: 4}
+	if !v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("expected an update of the port split")
+	}
+	status.PortSplit = 4
+	if v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("no update expected for the split port")
+	}
+	// an unsplit port is reported without port split
+	spec.PortSplit, status.PortSplit = 1, 0
+	if v1.NeedToUpdateSriov(spec, status) {
+		t.Errorf("no update expected for the unsplit port")
+	}
+}
+
+func TestUnicastMacs(t *testing.T) {
+	state := &v1.SriovNetworkNodeState{Status: v1.SriovNetworkNodeStateStatus{Interfaces: v1.InterfaceExts{
+		{Name: "ens1f0", PciAddress: "0000:3b:00.0", TotalVfs: 64},
+	}}}
+	policy := v1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "bond"}, Spec: v1.SriovNetworkNodePolicySpec{
+		ResourceName: "bond", NumVfs: 8, NicSelector: v1.SriovNetworkNicSelector{PfNames: []string{"ens1f0#2-3"}},
+		UnicastMacs: []v1.VfUnicastMacs{
+			{Vf: 2, Macs: []string{"02:00:5e:00:01:01"}},
+			{Vf: 5, Macs: []string{"02:00:5e:00:01:02"}},
+		}}}
+	if err := policy.Apply(state, false); err != nil {
+		t.Fatalf("failed to apply the policy: %v", err)
+	}
+	// the MACs of the VFs outside of the range of the policy are left out
+	want := map[int][]string{2: {"02:00:5e:00:01:01"}}
+	if diff := cmp.Diff(want, state.Spec.Interfaces[0].UnicastMacsByVf()); diff != "" {
+		t.Errorf("unexpected unicast MACs of the VFs (-want +got):\n%s", diff)
+	}
+}
+
+func TestSelectPlatformDevices(t *testing.T) {
+	state := &v1.SriovNetworkNodeState{
+		Spec: v1.SriovNetworkNodeStateSpec{Interfaces: v1.Interfaces{
+			{PciAddress: "0000:00:04.0", NumVfs: 1},
+			{PciAddress: "0000:00:05.0", NumVfs: 4},
+		}},
+		Status: v1.SriovNetworkNodeStateStatus{Interfaces: v1.InterfaceExts{
+			{PciAddress: "0000:00:04.0", NetFilter: "openstack/NetworkID:net-a", TotalVfs: 1},
+			{PciAddress: "0000:00:05.0", TotalVfs: 8},
+			{PciAddress: "0000:00:06.0", Unmanaged: true},
+		}},
+	}
+
+	platform := state.SelectPlatformDevices(true)
+	if len(platform.Spec.Interfaces) != 1 || platform.Spec.Interfaces[0].PciAddress != "0000:00:04.0" {
+		t.Errorf("expected the spec of the platform device only, got %v", platform.Spec.Interfaces)
+	}
+	if len(platform.Status.Interfaces) != 2 {
+		t.Errorf("expected the managed and unmanaged platform devices, got %v", platform.Status.Interfaces)
+	}
+	nested := state.SelectPlatformDevices(false)
+	if len(nested.Spec.Interfaces) != 1 || nested.Spec.Interfaces[0].PciAddress != "0000:00:05.0" {
+		t.Errorf("expected the spec of the SR-IOV capable PF only, got %v", nested.Spec.Interfaces)
+	}
+	if len(nested.Status.Interfaces) != 1 || nested.Status.Interfaces[0].PciAddress != "0000:00:05.0" {
+		t.Errorf("expected the status of the SR-IOV capable PF only, got %v", nested.Status.Interfaces)
+	}
+	if len(state.Spec.Interfaces) != 2 || len(state.Status.Interfaces) != 3 {
+		t.Errorf("expected the node state to be left unchanged")
+	}
+}
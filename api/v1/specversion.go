@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"fmt"
+)
+
+const (
+	// NodeStateSpecVersion is the version of the semantics of the SriovNetworkNodeState spec rendered by the operator.
+	// It is increased when a config daemon unaware of a change of the spec would misapply it, e.g. a new field
+	// changing how the existing fields are applied.
+	//
+	// Version 2: the port splits of the PFs, the rename of the PFs, the drivers autoprobe of the VFs disabled by the
+	// vfio-pci policies, the address sharing exceptions of the VFs reverted once removed, and the unicast MACs of the
+	// VFs.
+	NodeStateSpecVersion = 2
+	// MinNodeStateSpecVersion is the oldest version of the SriovNetworkNodeState spec applied by the config daemon
+	MinNodeStateSpecVersion = 1
+)
+
+// legacySpecVersions are the spec versions applied by the config daemons predating the version handshake, which
+// don't report their supported versions
+var legacySpecVersions = SpecVersionRange{Min: 1, Max: 1}
+
+// SupportedSpecVersions returns the versions of the SriovNetworkNodeState spec applied by the config daemon
+func SupportedSpecVersions() *SpecVersionRange {
+	return &SpecVersionRange{Min: MinNodeStateSpecVersion, Max: NodeStateSpecVersion}
+}
+
+// Supports returns whether the spec version is in the range, a nil range is the range of the legacy config daemons
+// and a 0 version is the version of the specs rendered by the legacy operators
+func (r *SpecVersionRange) Supports(version int) bool {
+	if r == nil {
+		r = &legacySpecVersions
+	}
+	if version == 0 {
+		version = legacySpecVersions.Max
+	}
+	return r.Min <= version && version <= r.Max
+}
+
+func (r *SpecVersionRange) String() string {
+	if r == nil {
+		r = &legacySpecVersions
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// CheckSpecVersion returns an error when the spec of the node state was rendered with a version the config daemon
+// doesn't apply, i.e. by a newer operator relying on semantics unknown to the daemon or by an older operator whose
+// semantics are no longer supported
+func (s *SriovNetworkNodeState) CheckSpecVersion() error {
+	supported := SupportedSpecVersions()
+	if supported.Supports(s.Spec.SpecVersion) {
+		return nil
+	}
+	return fmt.Errorf("the spec version %d is not in the versions %s supported by the config daemon", s.Spec.SpecVersion, supported)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SriovClusterResource is the number of VFs of a resource of the SR-IOV network device plugin across the cluster
+type SriovClusterResource struct {
+	// ResourceName is the resourceName of the SriovNetworkNodePolicies
+	ResourceName string `json:"resourceName"`
+	// TotalVfs is the number of VFs of the resource configured in the SriovNetworkNodeStates
+	TotalVfs int `json:"totalVfs"`
+	// Allocatable is the number of VFs of the resource allocatable on the nodes
+	Allocatable int64 `json:"allocatable"`
+	// Nodes is the number of nodes configuring VFs of the resource
+	Nodes int `json:"nodes"`
+}
+
+// SriovClusterFailingNode is a node whose last sync of its SriovNetworkNodeState failed
+type SriovClusterFailingNode struct {
+	// Name of the node
+	Name string `json:"name"`
+	// LastSyncError is the error of the last sync of the node
+	LastSyncError string `json:"lastSyncError,omitempty"`
+	// LastSyncErrorReason is the reason of the error of the last sync of the node
+	LastSyncErrorReason string `json:"lastSyncErrorReason,omitempty"`
+}
+
+// SriovClusterPendingReboot is a node waiting for a reboot to apply its configuration
+type SriovClusterPendingReboot struct {
+	// Name of the node
+	Name string `json:"name"`
+	// Reason the node waits for a reboot, e.g. BIOS settings enabled through the BMC or a port split the NIC
+	// applies at its next boot
+	Reason string `json:"reason"`
+}
+
+// SriovClusterPool is a SriovNetworkPoolConfig and the nodes it selects
+type SriovClusterPool struct {
+	// Name of the SriovNetworkPoolConfig
+	Name string `json:"name"`
+	// Nodes selected by the pool, sorted by name
+	Nodes []string `json:"nodes,omitempty"`
+	// Draining is the number of nodes of the pool being drained
+	Draining int `json:"draining"`
+}
+
+// SriovClusterPolicy is a SriovNetworkNodePolicy and the nodes it is applied to
+type SriovClusterPolicy struct {
+	// Name of the SriovNetworkNodePolicy
+	Name string `json:"name"`
+	// ResourceName is the resourceName of the policy
+	ResourceName string `json:"resourceName"`
+	// Nodes whose SriovNetworkNodeState holds VFs of the policy, sorted by name
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// SriovClusterStateStatus defines the observed state of SriovClusterState
+type SriovClusterStateStatus struct {
+	// Nodes is the number of SriovNetworkNodeStates
+	Nodes int `json:"nodes"`
+	// SyncedNodes is the number of SriovNetworkNodeStates whose last sync succeeded
+	SyncedNodes int `json:"syncedNodes"`
+	// Resources configured in the SriovNetworkNodeStates, sorted by resource name
+	Resources []SriovClusterResource `json:"resources,omitempty"`
+	// FailingNodes are the nodes whose last sync failed, sorted by name
+	FailingNodes []SriovClusterFailingNode `json:"failingNodes,omitempty"`
+	// PendingReboots are the nodes waiting for a reboot to apply their configuration, sorted by name
+	PendingReboots []SriovClusterPendingReboot `json:"pendingReboots,omitempty"`
+	// Pools are the SriovNetworkPoolConfigs, sorted by name
+	Pools []SriovClusterPool `json:"pools,omitempty"`
+	// Policies are the SriovNetworkNodePolicies and the nodes they are applied to, sorted by name
+	Policies []SriovClusterPolicy `json:"policies,omitempty"`
+	// UpdateTime is the last time the report changed
+	UpdateTime *metav1.Time `json:"updateTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Nodes",type=integer,JSONPath=`.status.nodes`
+//+kubebuilder:printcolumn:name="Synced",type=integer,JSONPath=`.status.syncedNodes`
+//+kubebuilder:printcolumn:name="Updated",type="date",JSONPath=".status.updateTime"
+
+// SriovClusterState is the Schema for the sriovclusterstates API, the operator maintains the default one reporting
+// the effective SR-IOV configuration of the cluster
+type SriovClusterState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status SriovClusterStateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SriovClusterStateList contains a list of SriovClusterState
+type SriovClusterStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovClusterState `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovClusterState{}, &SriovClusterStateList{})
+}
@@ -43,6 +43,10 @@ type SriovIBNetworkSpec struct {
 	// MetaPluginsConfig configuration to be used in order to chain metaplugins to the sriov interface returned
 	// by the operator.
 	MetaPluginsConfig string `json:"metaPlugins,omitempty"`
+	// CniVersion is the CNI specification version of the NetworkAttachmentDefinition, 0.3.1 when unset. From 1.0.0 the
+	// configuration is rendered as a plugin list, 1.1.0 enables the GC and STATUS verbs of the runtime
+	// +kubebuilder:validation:Enum={"0.3.1","0.4.0","1.0.0","1.1.0"}
+	CniVersion string `json:"cniVersion,omitempty"`
 }
 
 // SriovIBNetworkStatus defines the observed state of SriovIBNetwork
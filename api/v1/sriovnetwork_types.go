@@ -63,6 +63,10 @@ type SriovNetworkSpec struct {
 	// MetaPluginsConfig configuration to be used in order to chain metaplugins to the sriov interface returned
 	// by the operator.
 	MetaPluginsConfig string `json:"metaPlugins,omitempty"`
+	// CniVersion is the CNI specification version of the NetworkAttachmentDefinition, 0.3.1 when unset. From 1.0.0 the
+	// configuration is rendered as a plugin list, 1.1.0 enables the GC and STATUS verbs of the runtime
+	// +kubebuilder:validation:Enum={"0.3.1","0.4.0","1.0.0","1.1.0"}
+	CniVersion string `json:"cniVersion,omitempty"`
 	// LogLevel sets the log level of the SRIOV CNI plugin - either of panic, error, warning, info, debug. Defaults
 	// to info if left blank.
 	// +kubebuilder:validation:Enum={"panic", "error","warning","info","debug",""}
@@ -71,6 +75,87 @@ type SriovNetworkSpec struct {
 	// LogFile sets the log file of the SRIOV CNI plugin logs. If unset (default), this will log to stderr and thus
 	// to multus and container runtime logs.
 	LogFile string `json:"logFile,omitempty"`
+	// Quota limits the VFs of the network the pods of the namespaces can attach, it is enforced by the operator
+	// webhook when the quotas are enabled in the SriovOperatorConfig. The quota is checked at the admission of
+	// each pod without reserving the VFs, the pods created concurrently in a namespace may exceed it.
+	Quota *SriovNetworkQuota `json:"quota,omitempty"`
+	// ResourceInjection overrides the resource of the k8s.v1.cni.cncf.io/resourceName annotation of the
+	// NetworkAttachmentDefinition, requested by the network resources injector for the pods attaching the network
+	ResourceInjection *ResourceInjection `json:"resourceInjection,omitempty"`
+	// Policing caps the bandwidth of each VF of the resource with tc police actions offloaded to the eswitch on the
+	// representors of the VFs, the PFs of the resource must be in switchdev mode. It applies to all the VFs of the
+	// resource, whatever network the pods attach, and is only set by the SriovNetworks of the operator namespace.
+	Policing *PolicingConfig `json:"policing,omitempty"`
+	// AddressSharing configures the VFs of the network for the pods answering for the addresses of other hosts, e.g.
+	// the virtual routers of VRRP and keepalived. It is rendered in the CNI configuration, the spoofing and unicast
+	// MACs exceptions set by all the SriovNetworks of a resource in the operator namespace also apply to its VFs.
+	AddressSharing *AddressSharing `json:"addressSharing,omitempty"`
+}
+
+// AddressSharing are the exceptions to the anti-spoofing of the VFs and the proxying of the neighbor discovery of a
+// network
+type AddressSharing struct {
+	// AllowSpoofing disables the MAC and VLAN anti-spoofing check of the VFs, the pods can send with the virtual MAC of
+	// a virtual router
+	AllowSpoofing bool `json:"allowSpoofing,omitempty"`
+	// MultipleUnicastMacs trusts the VFs, the pods can add unicast MACs to the filter of their VF where the hardware
+	// supports it
+	MultipleUnicastMacs bool `json:"multipleUnicastMacs,omitempty"`
+	// ProxyArp answers the ARP requests received by the interface of the pod for the addresses it routes
+	ProxyArp bool `json:"proxyArp,omitempty"`
+	// ProxyNdp answers the IPv6 neighbor solicitations received by the interface of the pod for the addresses of its
+	// proxy neighbor entries
+	ProxyNdp bool `json:"proxyNdp,omitempty"`
+}
+
+// PolicingConfig is the bandwidth cap of the VFs of a resource, from the point of view of the pods
+type PolicingConfig struct {
+	// Egress caps the traffic sent by the VFs
+	Egress *PolicingRate `json:"egress,omitempty"`
+	// Ingress caps the traffic received by the VFs
+	Ingress *PolicingRate `json:"ingress,omitempty"`
+}
+
+// PolicingRate is a rate and the burst allowed above it, the traffic exceeding them is dropped
+type PolicingRate struct {
+	// +kubebuilder:validation:Minimum=1
+	// Rate in Mbps
+	Rate int `json:"rate"`
+	// +kubebuilder:validation:Minimum=1
+	// Burst in KB
+	Burst int `json:"burst"`
+}
+
+// ResourceInjection configures the resource requested for the pods attaching a SriovNetwork
+type ResourceInjection struct {
+	// ResourceName is the extended resource requested instead of the resource of the network, e.g. the resource of
+	// a shared pool or of the rdma shared device plugin
+	ResourceName string `json:"resourceName,omitempty"`
+	// Skip renders the NetworkAttachmentDefinition without resource annotation, the injector doesn't request any
+	// resource and the pods request their devices themselves
+	Skip bool `json:"skip,omitempty"`
+}
+
+// SriovNetworkQuota limits the VFs of a SriovNetwork attached by the pods of the namespaces
+type SriovNetworkQuota struct {
+	// +kubebuilder:validation:Minimum=0
+	// MaxVfsPerNamespace is the maximum number of VFs of the network attached by the pods of a namespace
+	MaxVfsPerNamespace *int `json:"maxVfsPerNamespace,omitempty"`
+	// Tenants overrides the quota for specific namespaces
+	Tenants []TenantQuota `json:"tenants,omitempty"`
+}
+
+// TenantQuota is the quota of a SriovNetwork for the pods of a namespace
+type TenantQuota struct {
+	// Namespace of the tenant
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:Minimum=0
+	// MaxVfs is the maximum number of VFs of the network attached by the pods of the namespace, it overrides
+	// MaxVfsPerNamespace
+	MaxVfs *int `json:"maxVfs,omitempty"`
+	// AllowedVlans are the VLANs the pods of the namespace may attach through the network, the pods are rejected
+	// if the VLAN of the network is not in the list. All the VLANs are allowed when empty.
+	AllowedVlans []int `json:"allowedVlans,omitempty"`
 }
 
 // SriovNetworkStatus defines the observed state of SriovNetwork
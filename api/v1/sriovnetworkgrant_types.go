@@ -0,0 +1,84 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SriovNetworkGrantSpec defines the resource pools, VLANs and IPAM blocks the SriovNetworks created in the
+// selected namespaces may use
+type SriovNetworkGrantSpec struct {
+	// NamespaceSelector selects the namespaces whose owners may create SriovNetworks constrained by the grant
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+	// +kubebuilder:validation:MinItems=1
+	// ResourceNames are the SR-IOV resource pools the SriovNetworks may use
+	ResourceNames []string `json:"resourceNames"`
+	// VlanRanges are the VLANs the SriovNetworks may use, as IDs or ranges of IDs, e.g. 100 or 100-199. The VLAN 0
+	// must be listed to allow the networks without VLAN. All the VLANs are allowed when empty.
+	VlanRanges []VlanRange `json:"vlanRanges,omitempty"`
+	// IPAMBlocks are the CIDRs the IPAM configuration of the SriovNetworks may allocate the addresses from, e.g.
+	// 10.10.0.0/16. The IPAM configuration is not restricted when empty.
+	IPAMBlocks []string `json:"ipamBlocks,omitempty"`
+	// AllowTrust allows the SriovNetworks to trust their VFs, also by the multipleUnicastMacs address sharing. The
+	// trusted VFs can change their MAC and enter the promiscuous mode.
+	AllowTrust bool `json:"allowTrust,omitempty"`
+	// AllowSpoofChkOff allows the SriovNetworks to disable the anti-spoofing check of their VFs, also by the
+	// allowSpoofing address sharing
+	AllowSpoofChkOff bool `json:"allowSpoofChkOff,omitempty"`
+	// AllowLinkState allows the SriovNetworks to force the link state of their VFs up or down, whatever the link
+	// state of the PF
+	AllowLinkState bool `json:"allowLinkState,omitempty"`
+	// Capabilities are the CNI capabilities the SriovNetworks may enable, e.g. mac or ips. No capability is allowed
+	// when empty.
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// +kubebuilder:validation:Pattern=`^[0-9]+(-[0-9]+)?$`
+// VlanRange is a VLAN ID or a range of VLAN IDs, e.g. 100 or 100-199
+type VlanRange string
+
+// SriovNetworkGrantStatus defines the observed state of SriovNetworkGrant
+type SriovNetworkGrantStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+
+// SriovNetworkGrant is the Schema for the sriovnetworkgrants API, it delegates the creation of SriovNetworks
+// to the owners of the selected namespaces
+type SriovNetworkGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SriovNetworkGrantSpec   `json:"spec,omitempty"`
+	Status SriovNetworkGrantStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SriovNetworkGrantList contains a list of SriovNetworkGrant
+type SriovNetworkGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovNetworkGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovNetworkGrant{}, &SriovNetworkGrantList{})
+}
@@ -0,0 +1,5 @@
+package v1
+
+// Hub marks v1 as the conversion hub of SriovNetworkNodePolicy, it's the storage version
+// the other versions are converted from and to.
+func (*SriovNetworkNodePolicy) Hub() {}
@@ -27,19 +27,40 @@ import (
 type SriovNetworkNodeStateSpec struct {
 	DpConfigVersion string     `json:"dpConfigVersion,omitempty"`
 	Interfaces      Interfaces `json:"interfaces,omitempty"`
+	// Hooks of the pools selecting the node
+	Hooks []Hook `json:"hooks,omitempty"`
+	// DrainMode of the first pool selecting the node, by name
+	DrainMode string `json:"drainMode,omitempty"`
+	// SpecVersion is the version of the semantics of the spec rendered by the operator, the config daemon only
+	// applies the spec versions it supports
+	SpecVersion int `json:"specVersion,omitempty"`
 }
 
+// Interfaces are keyed by PCI address so that server-side apply merges them per PF
+// +listType=map
+// +listMapKey=pciAddress
 type Interfaces []Interface
 
 type Interface struct {
-	PciAddress        string    `json:"pciAddress"`
-	NumVfs            int       `json:"numVfs,omitempty"`
-	Mtu               int       `json:"mtu,omitempty"`
-	Name              string    `json:"name,omitempty"`
-	LinkType          string    `json:"linkType,omitempty"`
-	EswitchMode       string    `json:"eSwitchMode,omitempty"`
-	VfGroups          []VfGroup `json:"vfGroups,omitempty"`
-	ExternallyManaged bool      `json:"externallyManaged,omitempty"`
+	PciAddress        string                   `json:"pciAddress"`
+	NumVfs            int                      `json:"numVfs,omitempty"`
+	Mtu               int                      `json:"mtu,omitempty"`
+	Name              string                   `json:"name,omitempty"`
+	LinkType          string                   `json:"linkType,omitempty"`
+	EswitchMode       string                   `json:"eSwitchMode,omitempty"`
+	VfGroups          []VfGroup                `json:"vfGroups,omitempty"`
+	ExternallyManaged bool                     `json:"externallyManaged,omitempty"`
+	FlowRules         []FlowRule               `json:"flowRules,omitempty"`
+	Qos               *QosConfig               `json:"qos,omitempty"`
+	CongestionControl *CongestionControlConfig `json:"congestionControl,omitempty"`
+	// AllowPtpSourceDisruption allows to reconfigure the PF when it is the PTP time source of the node
+	AllowPtpSourceDisruption bool `json:"allowPtpSourceDisruption,omitempty"`
+	// DisableDriversAutoprobe keeps the kernel drivers from probing the VFs when they are created
+	DisableDriversAutoprobe bool `json:"disableDriversAutoprobe,omitempty"`
+	// RenameTo is the name the PF is renamed to, set by the pfNamePrefix of its policies
+	RenameTo string `json:"renameTo,omitempty"`
+	// PortSplit is the number of ports the physical port of the PF is split into, 1 to unsplit it
+	PortSplit int `json:"portSplit,omitempty"`
 }
 
 type VfGroup struct {
@@ -50,27 +71,171 @@ type VfGroup struct {
 	Mtu          int    `json:"mtu,omitempty"`
 	IsRdma       bool   `json:"isRdma,omitempty"`
 	VdpaType     string `json:"vdpaType,omitempty"`
+	// HwTimestamping enables the hardware timestamping of the VFs
+	HwTimestamping bool `json:"hwTimestamping,omitempty"`
+	// MsixCount is the number of MSI-X vectors of each VF
+	MsixCount int `json:"msixCount,omitempty"`
+	// RequireIsolatedCpus warns when the node has no isolated CPUs for the DPDK workloads of the VFs
+	RequireIsolatedCpus bool `json:"requireIsolatedCpus,omitempty"`
+	// DisableIdleD3 keeps the vfio-pci VFs out of the D3 low power state while they are unused
+	DisableIdleD3 bool `json:"disableIdleD3,omitempty"`
+	// SystemReserved keeps the VFs out of the device plugin for the system components of the node
+	SystemReserved bool `json:"systemReserved,omitempty"`
+	// DrainMode of the policy, applied when the configuration of the PF changes
+	DrainMode string `json:"drainMode,omitempty"`
+	// Policing of the VFs, set by the SriovNetworks of the resource
+	Policing *PolicingConfig `json:"policing,omitempty"`
+	// AddressSharing are the spoofing and unicast MACs exceptions of the VFs, set by the SriovNetworks of the resource
+	AddressSharing *AddressSharing `json:"addressSharing,omitempty"`
+	// UnicastMacs are the additional unicast MACs of the VFs of the group
+	UnicastMacs []VfUnicastMacs `json:"unicastMacs,omitempty"`
 }
 
 type InterfaceExt struct {
-	Name              string            `json:"name,omitempty"`
-	Mac               string            `json:"mac,omitempty"`
-	Driver            string            `json:"driver,omitempty"`
-	PciAddress        string            `json:"pciAddress"`
-	Vendor            string            `json:"vendor,omitempty"`
-	DeviceID          string            `json:"deviceID,omitempty"`
-	NetFilter         string            `json:"netFilter,omitempty"`
-	Mtu               int               `json:"mtu,omitempty"`
-	NumVfs            int               `json:"numVfs,omitempty"`
-	LinkSpeed         string            `json:"linkSpeed,omitempty"`
-	LinkType          string            `json:"linkType,omitempty"`
-	EswitchMode       string            `json:"eSwitchMode,omitempty"`
-	ExternallyManaged bool              `json:"externallyManaged,omitempty"`
-	TotalVfs          int               `json:"totalvfs,omitempty"`
-	VFs               []VirtualFunction `json:"Vfs,omitempty"`
+	Name string `json:"name,omitempty"`
+	// OriginalName is the name of the PF before it was renamed by the pfNamePrefix of its policies
+	OriginalName string `json:"originalName,omitempty"`
+	Mac          string `json:"mac,omitempty"`
+	Driver       string `json:"driver,omitempty"`
+	// DriverVersion is the version of the driver of the PF reported by ethtool, the kernel release for the in-tree
+	// drivers
+	DriverVersion string `json:"driverVersion,omitempty"`
+	// FirmwareVersion is the firmware version of the PF reported by ethtool
+	FirmwareVersion    string                   `json:"firmwareVersion,omitempty"`
+	PciAddress         string                   `json:"pciAddress"`
+	Vendor             string                   `json:"vendor,omitempty"`
+	DeviceID           string                   `json:"deviceID,omitempty"`
+	NetFilter          string                   `json:"netFilter,omitempty"`
+	Mtu                int                      `json:"mtu,omitempty"`
+	NumVfs             int                      `json:"numVfs,omitempty"`
+	LinkSpeed          string                   `json:"linkSpeed,omitempty"`
+	LinkType           string                   `json:"linkType,omitempty"`
+	LinkState          string                   `json:"linkState,omitempty"`
+	EswitchMode        string                   `json:"eSwitchMode,omitempty"`
+	ExternallyManaged  bool                     `json:"externallyManaged,omitempty"`
+	TransceiverPresent bool                     `json:"transceiverPresent,omitempty"`
+	Transceiver        *TransceiverInfo         `json:"transceiver,omitempty"`
+	LldpNeighbor       *LldpNeighbor            `json:"lldpNeighbor,omitempty"`
+	Qos                *QosConfig               `json:"qos,omitempty"`
+	CongestionControl  *CongestionControlConfig `json:"congestionControl,omitempty"`
+	Ptp                *PtpInfo                 `json:"ptp,omitempty"`
+	TotalVfs           int                      `json:"totalvfs,omitempty"`
+	VFs                []VirtualFunction        `json:"Vfs,omitempty"`
+	// VFsSummary groups the consecutive VFs sharing the same configuration, it is reported in place of Vfs
+	// when the compact node state is enabled in the SriovOperatorConfig
+	VFsSummary []VirtualFunctionRange `json:"vfsSummary,omitempty"`
+	// ZpciUID is the UID of the zPCI function on IBM Z. The RoCE Express functions are already VFs provided by
+	// the firmware, they are reported with a single VF, the function itself.
+	ZpciUID string `json:"zpciUid,omitempty"`
+	// VfTotalMsix is the number of MSI-X vectors the PF distributes among its VFs, it is only reported by the
+	// drivers allowing to set the MSI-X vectors of each VF
+	VfTotalMsix int `json:"vfTotalMsix,omitempty"`
+	// DriversAutoprobeDisabled is true when the kernel drivers don't probe the VFs of the PF when they are created
+	DriversAutoprobeDisabled bool `json:"driversAutoprobeDisabled,omitempty"`
+	// PcieLink is the PCIe link of the PF
+	PcieLink *PcieLinkInfo `json:"pcieLink,omitempty"`
+	// Port is the position of the PF on its card, the ports of a card may differ in link type and speed
+	Port *NicPortInfo `json:"port,omitempty"`
+	// PortSplit is the number of ports the physical port of the PF is split into, it is only reported for a split
+	// port
+	PortSplit int `json:"portSplit,omitempty"`
+	// SplitPorts are the netdevs of the ports the physical port of the PF is split into, in the order of their
+	// devlink ports. The PF is configured through the netdev of its first port, reported as its name.
+	SplitPorts []string `json:"splitPorts,omitempty"`
+	// Subports are the VLAN subports of the OpenStack trunk port of the device
+	Subports []OpenstackSubport `json:"subports,omitempty"`
+	// PlatformMtu is the MTU of the network of the device declared by the virtual platform, e.g. in the
+	// network_data.json of OpenStack
+	PlatformMtu int `json:"platformMtu,omitempty"`
+	// Unmanaged is true for a device of a virtual platform that isn't found in the metadata of the platform,
+	// it is reported without NetFilter and isn't configured by the policies
+	Unmanaged bool `json:"unmanaged,omitempty"`
 }
+
+// OpenstackSubport is a VLAN subport of an OpenStack trunk port
+type OpenstackSubport struct {
+	// NetFilter is the network of the subport, e.g. openstack/NetworkID:<id>
+	NetFilter string `json:"netFilter"`
+	// VlanID is the VLAN the traffic of the subport is tagged with
+	VlanID int `json:"vlanId"`
+}
+
+// InterfaceExts are keyed by PCI address so that server-side apply merges them per PF
+// +listType=map
+// +listMapKey=pciAddress
 type InterfaceExts []InterfaceExt
 
+// TransceiverInfo contains the identification read from the EEPROM of the module plugged into a PF, the digital
+// diagnostic monitoring (DDM) readings change at each poll and are only exported by the config daemon metrics
+type TransceiverInfo struct {
+	Vendor       string `json:"vendor,omitempty"`
+	PartNumber   string `json:"partNumber,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// LldpNeighbor is the switch port connected to a PF as advertised by LLDP
+type LldpNeighbor struct {
+	ChassisID       string `json:"chassisID,omitempty"`
+	PortID          string `json:"portID,omitempty"`
+	PortDescription string `json:"portDescription,omitempty"`
+	SystemName      string `json:"systemName,omitempty"`
+}
+
+// PcieLinkInfo is the current and the maximum speed and width of the PCIe link of a PF
+type PcieLinkInfo struct {
+	// Speed of the link, e.g. "8.0 GT/s PCIe"
+	Speed    string `json:"speed,omitempty"`
+	MaxSpeed string `json:"maxSpeed,omitempty"`
+	// Width is the number of lanes of the link
+	Width    int `json:"width,omitempty"`
+	MaxWidth int `json:"maxWidth,omitempty"`
+	// Degraded is true when the link trained below the speed or the width the device supports
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// NicPortInfo locates a PF on its card, the PFs sharing the PCI domain, bus and device of a card are its ports
+type NicPortInfo struct {
+	// Card is the PCI domain, bus and device of the card, e.g. "0000:3b:00"
+	Card string `json:"card"`
+	// Index of the port on the card, from 0 in the order of the PCI functions
+	Index int `json:"index"`
+	// Ports is the number of ports of the card
+	Ports int `json:"ports"`
+	// LinkType of the port, ETH or IB
+	LinkType string `json:"linkType,omitempty"`
+	// Speed is the link speed of the port, e.g. "100000 Mb/s"
+	Speed string `json:"speed,omitempty"`
+	// Capabilities are the SR-IOV features the port supports
+	Capabilities []PortCapability `json:"capabilities,omitempty"`
+}
+
+// PortCapability is an SR-IOV feature a port of a card supports
+// +kubebuilder:validation:Enum=sriov;switchdev;rdma;ptp;vfMsix
+type PortCapability string
+
+const (
+	// PortCapabilitySriov is reported by the ports creating VFs
+	PortCapabilitySriov PortCapability = "sriov"
+	// PortCapabilitySwitchdev is reported by the ports whose eswitch mode can be changed through devlink
+	PortCapabilitySwitchdev PortCapability = "switchdev"
+	// PortCapabilityRdma is reported by the ports with an RDMA device
+	PortCapabilityRdma PortCapability = "rdma"
+	// PortCapabilityPtp is reported by the ports with a PTP hardware clock
+	PortCapabilityPtp PortCapability = "ptp"
+	// PortCapabilityVfMsix is reported by the ports allowing to set the MSI-X vectors of each VF
+	PortCapabilityVfMsix PortCapability = "vfMsix"
+)
+
+// PtpInfo is the PTP hardware clock of a PF and the linuxptp services using it
+type PtpInfo struct {
+	// Clock is the PTP hardware clock of the PF, e.g. "ptp0"
+	Clock string `json:"clock,omitempty"`
+	// Services are the linuxptp services bound to the PF, e.g. "ptp4l", "phc2sys"
+	Services []string `json:"services,omitempty"`
+	// TimeSource is true when the system clock of the node is synchronized from the clock of the PF
+	TimeSource bool `json:"timeSource,omitempty"`
+}
+
 type VirtualFunction struct {
 	Name       string `json:"name,omitempty"`
 	Mac        string `json:"mac,omitempty"`
@@ -83,6 +248,34 @@ type VirtualFunction struct {
 	Mtu        int    `json:"mtu,omitempty"`
 	VfID       int    `json:"vfID"`
 	VdpaType   string `json:"vdpaType,omitempty"`
+	// HwTimestamping is "enabled" when the VF timestamps the packets in hardware, "supported" when it can
+	HwTimestamping string `json:"hwTimestamping,omitempty"`
+	// MsixCount is the number of MSI-X vectors of the VF
+	MsixCount int `json:"msixCount,omitempty"`
+	// DriverOverride is the driver the VF is bound to by its driver_override
+	DriverOverride string `json:"driverOverride,omitempty"`
+	// BindError is the error of the last failed bind of the VF by the config daemon, the error of the probe of the VF
+	// in the kernel log when there is one, it is only reported for an unbound VF
+	BindError string `json:"bindError,omitempty"`
+}
+
+// VirtualFunctionRange is a range of consecutive VFs of a PF sharing the same configuration
+type VirtualFunctionRange struct {
+	// Range of the VF indexes, "<first>-<last>"
+	VfRange  string `json:"vfRange"`
+	Driver   string `json:"driver,omitempty"`
+	Vendor   string `json:"vendor,omitempty"`
+	DeviceID string `json:"deviceID,omitempty"`
+	Mtu      int    `json:"mtu,omitempty"`
+	VdpaType string `json:"vdpaType,omitempty"`
+	// HwTimestamping is "enabled" when the VFs timestamp the packets in hardware, "supported" when they can
+	HwTimestamping string `json:"hwTimestamping,omitempty"`
+	MsixCount      int    `json:"msixCount,omitempty"`
+	// DriverOverride is the driver the VF is bound to by its driver_override
+	DriverOverride string `json:"driverOverride,omitempty"`
+	// BindError is the error of the last failed bind of the VF by the config daemon, the error of the probe of the VF
+	// in the kernel log when there is one, it is only reported for an unbound VF
+	BindError string `json:"bindError,omitempty"`
 }
 
 // SriovNetworkNodeStateStatus defines the observed state of SriovNetworkNodeState
@@ -90,11 +283,136 @@ type SriovNetworkNodeStateStatus struct {
 	Interfaces    InterfaceExts `json:"interfaces,omitempty"`
 	SyncStatus    string        `json:"syncStatus,omitempty"`
 	LastSyncError string        `json:"lastSyncError,omitempty"`
+	// LastSyncErrorReason is the machine-readable reason of LastSyncError, e.g. VfInUse or MetadataUnavailable
+	LastSyncErrorReason string `json:"lastSyncErrorReason,omitempty"`
+	// Hooks reports the last run of each hook of the node
+	Hooks []HookStatus `json:"hooks,omitempty"`
+	// Architecture is the CPU architecture of the node, e.g. amd64 or arm64
+	Architecture string `json:"architecture,omitempty"`
+	// HugepageSizes are the hugepage sizes supported by the kernel of the node, e.g. 2048kB
+	HugepageSizes []string `json:"hugepageSizes,omitempty"`
+	// IsolatedCpus are the CPUs isolated from the kernel scheduler, e.g. 2-15
+	IsolatedCpus string `json:"isolatedCpus,omitempty"`
+	// Metadata is where the device information of the virtual platforms, e.g. OpenStack, was read from
+	Metadata *MetadataProvenance `json:"metadata,omitempty"`
+	// Drain reports the progress of the drain of the node, from its start until it completes
+	Drain *DrainStatus `json:"drain,omitempty"`
+	// SupportedSpecVersions are the versions of the spec applied by the config daemon of the node, the operator
+	// doesn't render a spec the daemon doesn't support
+	SupportedSpecVersions *SpecVersionRange `json:"supportedSpecVersions,omitempty"`
+	// CniPlugins are the SR-IOV CNI plugins installed on the node
+	CniPlugins []CniPluginStatus `json:"cniPlugins,omitempty"`
+	// Conditions of the node, e.g. PcieLinkDegraded
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CniPluginStatus reports a CNI plugin installed on the node
+type CniPluginStatus struct {
+	// Type of the plugin, e.g. sriov or ib-sriov
+	Type string `json:"type"`
+	// SupportedVersions are the CNI specification versions the plugin reports to the VERSION command
+	SupportedVersions []string `json:"supportedVersions,omitempty"`
+}
+
+// SpecVersionRange is a range of versions of the SriovNetworkNodeState spec
+type SpecVersionRange struct {
+	// Min is the oldest version of the range
+	Min int `json:"min"`
+	// Max is the newest version of the range
+	Max int `json:"max"`
+}
+
+const (
+	// ConditionPcieLinkDegraded is true when the PCIe link of a PF trained below its capability
+	ConditionPcieLinkDegraded = "PcieLinkDegraded"
+	// ConditionDpdkPrerequisitesMissing is true when the node lacks the hugepages or the isolated CPUs of the
+	// DPDK workloads of its vfio-pci VFs
+	ConditionDpdkPrerequisitesMissing = "DpdkPrerequisitesMissing"
+	// ConditionMetadataIncomplete is true when malformed devices, links or networks of the OpenStack metadata
+	// were skipped or when the PCI address of devices of the metadata wasn't found by MAC address
+	ConditionMetadataIncomplete = "MetadataIncomplete"
+	// ConditionMtuMismatch is true when the MTU of a device differs from the MTU of its network declared by the
+	// virtual platform
+	ConditionMtuMismatch = "MtuMismatch"
+	// ConditionDraining is true while the node is drained before being reconfigured
+	ConditionDraining = "Draining"
+	// ConditionInterfaceClaimConflict is true when interfaces selected by the policies are claimed by another host
+	// network manager, they are not configured
+	ConditionInterfaceClaimConflict = "InterfaceClaimConflict"
+	// ConditionHostDrift is true when the SR-IOV configuration of PFs differs from the spec last applied to them,
+	// e.g. after a manual change on the host
+	ConditionHostDrift = "HostDrift"
+	// ConditionVfsUnbound is true when VFs of the VF groups of the spec are still left without driver after the
+	// attempts of the config daemon to bind them
+	ConditionVfsUnbound = "VfsUnbound"
+)
+
+// DrainStatus is the progress of the drain of a node
+type DrainStatus struct {
+	// Phase of the drain in its escalation (Draining|Stuck|Forcing|Aborted)
+	Phase string `json:"phase"`
+	// StartTime of the drain, kept across its retries
+	StartTime metav1.Time `json:"startTime"`
+	// Deadline after which the drain is aborted, when the drain escalation aborts the drains
+	Deadline *metav1.Time `json:"deadline,omitempty"`
+	// PodsRemaining is the number of pods left to evict from the node
+	PodsRemaining int `json:"podsRemaining"`
+	// FailingPods are the pods whose eviction failed in the last attempt
+	FailingPods []DrainPodFailure `json:"failingPods,omitempty"`
+}
+
+// DrainPodFailure is a pod whose eviction failed
+type DrainPodFailure struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Reason of the failure, e.g. the PodDisruptionBudget allowing no disruption
+	Reason string `json:"reason"`
+}
+
+// Phases of a drain
+const (
+	DrainPhaseDraining = "Draining"
+	DrainPhaseStuck    = "Stuck"
+	DrainPhaseForcing  = "Forcing"
+	DrainPhaseAborted  = "Aborted"
+)
+
+// MetadataProvenance is where the device information of a virtual platform was read from
+type MetadataProvenance struct {
+	// Sources of the device information (ConfigDrive|MetadataService|NodeStatus|DevicesInfoCheckpoint), both the
+	// config drive and the metadata service are listed when their data were merged. NodeStatus is the node state
+	// checkpointed when the daemon first started on the node. DevicesInfoCheckpoint is the device information
+	// persisted by the daemon, used when the metadata is unavailable.
+	Sources []string `json:"sources,omitempty"`
+	// ReadTime is when the device information was read from the platform
+	ReadTime metav1.Time `json:"readTime,omitempty"`
+}
+
+// Sources of the device information of the virtual platforms
+const (
+	MetadataSourceConfigDrive     = "ConfigDrive"
+	MetadataSourceMetadataService = "MetadataService"
+	MetadataSourceNodeStatus      = "NodeStatus"
+	MetadataSourceCheckpoint      = "DevicesInfoCheckpoint"
+)
+
+// HookStatus is the result of the last run of a hook
+type HookStatus struct {
+	Name  string `json:"name"`
+	Point string `json:"point"`
+	// Result of the run (Succeeded|Failed|Ignored), a failure of a hook with the Ignore policy is reported as Ignored
+	Result string `json:"result"`
+	// Message contains the error and the output of a failed run
+	Message     string      `json:"message,omitempty"`
+	LastRunTime metav1.Time `json:"lastRunTime"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Sync Status",type=string,JSONPath=`.status.syncStatus`
+//+kubebuilder:printcolumn:name="Arch",type=string,JSONPath=`.status.architecture`,priority=1
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SriovNetworkNodeState is the Schema for the sriovnetworknodestates API
@@ -8,6 +8,61 @@ import (
 type SriovNetworkPoolConfigSpec struct {
 	// OvsHardwareOffloadConfig describes the OVS HWOL configuration for selected Nodes
 	OvsHardwareOffloadConfig OvsHardwareOffloadConfig `json:"ovsHardwareOffloadConfig,omitempty"`
+	// NodeSelector selects the nodes of the pool
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// DaemonProfile is the profile of the config daemon on the nodes of the pool.
+	// The lite profile reduces the daemon footprint for single-node edge deployments,
+	// it doesn't support the virtual platforms, doesn't serve metrics and only watches its own node.
+	// +kubebuilder:validation:Enum=full;lite
+	DaemonProfile string `json:"daemonProfile,omitempty"`
+	// DrainSkip skips the drain of the nodes of the pool when they are reconfigured, the nodes are
+	// only cordoned while the configuration is applied. The drain is always skipped on single node clusters.
+	DrainSkip bool `json:"drainSkip,omitempty"`
+	// DrainMode of the nodes of the pool when they are reconfigured: "Drain" evicts the workloads, "Cordon" only
+	// stops scheduling on the node and "None" leaves the node untouched. It overrides the disableDrain of the
+	// SriovOperatorConfig and is overridden by the drainMode of the policies. drainSkip is equivalent to "Cordon".
+	// +kubebuilder:validation:Enum=Drain;Cordon;None
+	DrainMode string `json:"drainMode,omitempty"`
+	// Hooks are commands run on the nodes of the pool at given points of the configuration, e.g. to quiesce
+	// a storage daemon using a VF before the node is drained
+	Hooks []Hook `json:"hooks,omitempty"`
+	// OvnHardwareOffload configures the nodes of the pool for the hardware offload of OVN-Kubernetes: the PFs in
+	// switchdev with their representors named by the switchdev udev rule, the hw-offload flag of Open vSwitch and the
+	// management VF of the node. The nodes are selected by the matchLabels of the nodeSelector.
+	OvnHardwareOffload *OvnHardwareOffloadConfig `json:"ovnHardwareOffload,omitempty"`
+}
+
+// OvnHardwareOffloadConfig selects the PFs of the nodes of the pool carrying the offloaded traffic of OVN-Kubernetes
+type OvnHardwareOffloadConfig struct {
+	// Names of the PFs, the first VF of the first PF is the management VF of the node
+	// +kubebuilder:validation:MinItems=1
+	PfNames []string `json:"pfNames"`
+	// +kubebuilder:validation:Minimum=2
+	// Number of VFs of each PF. Defaults to 8.
+	NumVfs int `json:"numVfs,omitempty"`
+	// Resource name of the VFs of the pods, the management VF is advertised as <resourceName>_mgmt. Defaults to
+	// ovn_hwol.
+	ResourceName string `json:"resourceName,omitempty"`
+}
+
+// Hook is a command run by the config daemon at a given point of the configuration of a node
+type Hook struct {
+	// Name of the hook, unique in the pool
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Point of the configuration the hook runs at
+	// +kubebuilder:validation:Enum=preDrain;preApply;postApply;preReboot
+	Point string `json:"point"`
+	// Command run chrooted on the host file system, e.g. ["/usr/local/bin/quiesce.sh", "--wait"]
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// Time in seconds after which the hook is killed and considered failed. Defaults to 60
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy defines how hook failures are handled, "Fail" aborts the configuration of the node
+	// and retries it later, while "Ignore" only reports the failure. Defaults to Fail
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
 }
 
 type OvsHardwareOffloadConfig struct {
@@ -21,6 +76,26 @@ type OvsHardwareOffloadConfig struct {
 
 // SriovNetworkPoolConfigStatus defines the observed state of SriovNetworkPoolConfig
 type SriovNetworkPoolConfigStatus struct {
+	// OvnHardwareOffload reports the progress of the hardware offload of OVN-Kubernetes on the nodes of the pool
+	OvnHardwareOffload *OvnHardwareOffloadStatus `json:"ovnHardwareOffload,omitempty"`
+}
+
+// OvnHardwareOffloadStatus reports the steps of the hardware offload of OVN-Kubernetes done on the nodes of the pool
+type OvnHardwareOffloadStatus struct {
+	// Nodes is the number of nodes of the pool
+	Nodes int `json:"nodes"`
+	// Steps of the configuration of the nodes, in order
+	Steps []OvnHardwareOffloadStep `json:"steps,omitempty"`
+}
+
+// OvnHardwareOffloadStep is a step of the hardware offload of OVN-Kubernetes
+type OvnHardwareOffloadStep struct {
+	// Name of the step: Switchdev, Representors, UdevNaming, OvsHardwareOffload or ManagementVf
+	Name string `json:"name"`
+	// ReadyNodes is the number of nodes of the pool the step is done on
+	ReadyNodes int `json:"readyNodes"`
+	// Message names the nodes the step is pending on
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
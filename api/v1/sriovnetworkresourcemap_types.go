@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SriovNetworkResourcePolicy is a SriovNetworkNodePolicy defining a resource and the nodes it is advertised on
+type SriovNetworkResourcePolicy struct {
+	// Name of the SriovNetworkNodePolicy
+	Name string `json:"name"`
+	// Nodes whose device plugin advertises the resource for the policy, sorted by name
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// SriovNetworkResource maps a resource of the SR-IOV network device plugin to the policies defining it
+type SriovNetworkResource struct {
+	// ResourceName is the resourceName of the SriovNetworkNodePolicies
+	ResourceName string `json:"resourceName"`
+	// ExtendedResourceName is the extended resource requested by the pods, the resource name prefixed by the
+	// resource prefix of the operator
+	ExtendedResourceName string `json:"extendedResourceName"`
+	// Policies defining the resource, sorted by name
+	Policies []SriovNetworkResourcePolicy `json:"policies,omitempty"`
+}
+
+// SriovNetworkResourcePool is the capacity and the utilization of a resource of the SR-IOV network device plugin
+type SriovNetworkResourcePool struct {
+	// ResourceName is the resourceName of the SriovNetworkNodePolicies
+	ResourceName string `json:"resourceName"`
+	// TotalVfs is the number of VFs of the resource configured in the SriovNetworkNodeStates
+	TotalVfs int `json:"totalVfs"`
+	// Allocatable is the number of VFs of the resource allocatable on the nodes
+	Allocatable int64 `json:"allocatable"`
+	// Allocated is the number of VFs of the resource requested by the pods scheduled on the nodes
+	Allocated int64 `json:"allocated"`
+	// Nodes with allocatable VFs of the resource, sorted by name
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// SriovNetworkResourceMapStatus defines the observed state of SriovNetworkResourceMap
+type SriovNetworkResourceMapStatus struct {
+	// Resources advertised by the device plugin, sorted by resource name
+	Resources []SriovNetworkResource `json:"resources,omitempty"`
+	// Pools is the capacity of each resource, sorted by resource name
+	Pools []SriovNetworkResourcePool `json:"pools,omitempty"`
+	// PoolsUpdateTime is the last time the capacity of the resources was computed
+	PoolsUpdateTime *metav1.Time `json:"poolsUpdateTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// SriovNetworkResourceMap is the Schema for the sriovnetworkresourcemaps API, the operator maintains the default one
+// mapping each resource of the device plugin to its policies and nodes
+type SriovNetworkResourceMap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status SriovNetworkResourceMapStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SriovNetworkResourceMapList contains a list of SriovNetworkResourceMap
+type SriovNetworkResourceMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovNetworkResourceMap `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovNetworkResourceMap{}, &SriovNetworkResourceMapList{})
+}
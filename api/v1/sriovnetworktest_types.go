@@ -0,0 +1,87 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SriovNetworkTestSpec defines the desired state of SriovNetworkTest
+type SriovNetworkTestSpec struct {
+	// Name of the SriovNetwork the test pods are attached to
+	NetworkName string `json:"networkName"`
+	// NodeSelector selects the nodes the test pods run on, each node runs a server pod
+	// and a client pod testing the server of the next selected node
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tests to run between the pods, all of them when empty
+	Tests []SriovNetworkTestType `json:"tests,omitempty"`
+	// Image of the test pods, it must provide ping, iperf3 and the perftest tools for rdma_bw
+	Image string `json:"image"`
+	// Timeout of the whole test run in seconds. Defaults to 300
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=ping;iperf;rdma_bw
+type SriovNetworkTestType string
+
+// SriovNetworkTestResult is the result of one test between two nodes
+type SriovNetworkTestResult struct {
+	Test            SriovNetworkTestType `json:"test"`
+	SourceNode      string               `json:"sourceNode"`
+	DestinationNode string               `json:"destinationNode"`
+	Passed          bool                 `json:"passed"`
+	// Summary of the test output, e.g. the packet loss or the measured bandwidth
+	Output string `json:"output,omitempty"`
+}
+
+// SriovNetworkTestStatus defines the observed state of SriovNetworkTest
+type SriovNetworkTestStatus struct {
+	// Phase of the test run (Pending|Running|Succeeded|Failed)
+	Phase          string                   `json:"phase,omitempty"`
+	Message        string                   `json:"message,omitempty"`
+	StartTime      *metav1.Time             `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time             `json:"completionTime,omitempty"`
+	Results        []SriovNetworkTestResult `json:"results,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Network",type=string,JSONPath=`.spec.networkName`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// SriovNetworkTest is the Schema for the sriovnetworktests API
+type SriovNetworkTest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SriovNetworkTestSpec   `json:"spec,omitempty"`
+	Status SriovNetworkTestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SriovNetworkTestList contains a list of SriovNetworkTest
+type SriovNetworkTestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovNetworkTest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovNetworkTest{}, &SriovNetworkTestList{})
+}
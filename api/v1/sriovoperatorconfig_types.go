@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -61,8 +62,279 @@ type SriovOperatorConfigSpec struct {
 	ConfigurationMode ConfigurationModeType `json:"configurationMode,omitempty"`
 	// Flag to enable Container Device Interface mode for SR-IOV Network Device Plugin
 	UseCDI bool `json:"useCDI,omitempty"`
+	// Flag to generate on the nodes the Container Device Interface specs of the VFs, for the container runtimes and the
+	// consumers of the VFs outside of Kubernetes
+	GenerateCDISpecs bool `json:"generateCDISpecs,omitempty"`
 	// DisablePlugins is a list of sriov-network-config-daemon plugins to disable
 	DisablePlugins PluginNameSlice `json:"disablePlugins,omitempty"`
+	// Flag to enable LLDP listening on the PFs to report the connected switch port in the SriovNetworkNodeState
+	EnableLldp bool `json:"enableLldp,omitempty"`
+	// Flag to report the VFs of the PFs as ranges of VFs sharing the same configuration in the SriovNetworkNodeState
+	// status, in place of the per-VF details, to keep the object small on nodes with many VFs
+	CompactNodeState bool `json:"compactNodeState,omitempty"`
+	// VlanValidation configures a hook to validate the SriovNetwork VLANs against the fabric before rendering the NetworkAttachmentDefinition
+	VlanValidation *VlanValidationConfig `json:"vlanValidation,omitempty"`
+	// PolicySignature requires the SriovNetworkNodePolicies to carry a signature, verified by an external verifier,
+	// before they are admitted and applied to the nodes
+	PolicySignature *PolicySignatureConfig `json:"policySignature,omitempty"`
+	// SoakTest enables the soak mode, a scratch policy is repeatedly applied and removed on a test node pool
+	SoakTest *SoakTestConfig `json:"soakTest,omitempty"`
+	// Redfish enables the detection, and optionally the remediation, of the BIOS settings preventing SR-IOV
+	// through the Redfish API of the node BMCs
+	Redfish *RedfishConfig `json:"redfish,omitempty"`
+	// Tracing exports OpenTelemetry traces of the reconciliation pipeline, from the policy admission to the
+	// host commands run by the config daemons
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+	// Flag to enforce the quotas of the SriovNetworks when the pods are admitted, it requires the operator webhook
+	EnableQuota bool `json:"enableQuota,omitempty"`
+	// Flag to warn on the creation of the pods whose SR-IOV resource requests don't match their attachments to the
+	// SriovNetworks, it requires the operator webhook
+	EnablePodNetworkWarnings bool `json:"enablePodNetworkWarnings,omitempty"`
+	// Overlays are named patches applied to the objects rendered by the operator, e.g. to add a sidecar to the
+	// device plugin daemonset. They are applied every time the objects are rendered, so they are kept across the
+	// upgrades of the operator
+	Overlays []ManifestOverlay `json:"overlays,omitempty"`
+	// DrainEscalation escalates the drains of the nodes that don't complete, by default a stuck drain is retried
+	// until it completes
+	DrainEscalation *DrainEscalationConfig `json:"drainEscalation,omitempty"`
+	// PauseNodeConfiguration freezes the configuration of all the nodes, e.g. during an incident. The config daemons
+	// keep discovering the devices of their node but don't apply any change to the host until it is unset
+	PauseNodeConfiguration bool `json:"pauseNodeConfiguration,omitempty"`
+	// RemediateHostDrift reapplies the spec of the nodes whose SR-IOV configuration was changed out of band, e.g. by a
+	// manual write of sriov_numvfs. The drift is reported in the HostDrift condition of the SriovNetworkNodeStates
+	// either way
+	RemediateHostDrift bool `json:"remediateHostDrift,omitempty"`
+	// DriverQuirks skip steps of the configuration of the VFs of the PFs using a driver, and optionally a range of
+	// firmware versions, to work around the bugs of the drivers and of the firmwares of the NICs
+	DriverQuirks []DriverQuirk `json:"driverQuirks,omitempty"`
+	// ReportNodeProblems reports the SR-IOV faults of the nodes, a PF down, a firmware fault or repeated failures to
+	// create the VFs, as node conditions and events in the format of node-problem-detector
+	ReportNodeProblems bool `json:"reportNodeProblems,omitempty"`
+	// ServiceIPFamilyPolicy is the ipFamilyPolicy of the services rendered by the operator, i.e. the webhooks and
+	// the metrics exporter. By default they are single-stack on the primary IP family of the cluster
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	ServiceIPFamilyPolicy corev1.IPFamilyPolicy `json:"serviceIPFamilyPolicy,omitempty"`
+	// ServiceIPFamilies are the ipFamilies of the services rendered by the operator, in order of preference. The
+	// first family of a service can't be changed once it is created
+	// +kubebuilder:validation:MaxItems=2
+	ServiceIPFamilies []corev1.IPFamily `json:"serviceIPFamilies,omitempty"`
+	// OpenstackMetadata configures the requests of the config daemons to the OpenStack metadata service
+	OpenstackMetadata *OpenstackMetadataConfig `json:"openstackMetadata,omitempty"`
+	// FeatureGates enable or disable the optional components of the operator: resourceInjector, operatorWebhook,
+	// metricsExporter, systemdMode and monitoring. A feature gate takes precedence over the enableInjector,
+	// enableOperatorWebhook and configurationMode fields
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// VfConfigStep is a step of the configuration of the VFs by the config daemon
+// +kubebuilder:validation:Enum=adminMac;guid;rebind;msixCount;mtu;hwTimestamping
+type VfConfigStep string
+
+const (
+	// VfConfigStepAdminMac is the administrative MAC address set on the Ethernet VFs through their PF
+	VfConfigStepAdminMac VfConfigStep = "adminMac"
+	// VfConfigStepGUID is the GUID set on the InfiniBand VFs through their PF
+	VfConfigStepGUID VfConfigStep = "guid"
+	// VfConfigStepRebind is the rebind of the VFs whose netdev is not ready to their default driver
+	VfConfigStepRebind VfConfigStep = "rebind"
+	// VfConfigStepMsixCount is the number of MSI-X vectors of the VFs
+	VfConfigStepMsixCount VfConfigStep = "msixCount"
+	// VfConfigStepMtu is the MTU of the VFs
+	VfConfigStepMtu VfConfigStep = "mtu"
+	// VfConfigStepHwTimestamping is the hardware timestamping of the VFs
+	VfConfigStepHwTimestamping VfConfigStep = "hwTimestamping"
+)
+
+// DriverQuirk skips steps of the configuration of the VFs of the PFs using a driver, e.g. the admin MAC address
+// breaking the RDMA auxiliary device of some ice firmwares
+type DriverQuirk struct {
+	// Name identifies the quirk in the logs of the config daemon
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Driver is the kernel driver of the PFs, e.g. ice
+	// +kubebuilder:validation:MinLength=1
+	Driver string `json:"driver"`
+	// MinFirmwareVersion is the first firmware version of the PFs affected, e.g. 4.20. All the versions up to
+	// maxFirmwareVersion are affected when empty
+	MinFirmwareVersion string `json:"minFirmwareVersion,omitempty"`
+	// MaxFirmwareVersion is the last firmware version of the PFs affected, its patch versions included, e.g. 4.30
+	// affects 4.30.1. All the versions from minFirmwareVersion are affected when empty
+	MaxFirmwareVersion string `json:"maxFirmwareVersion,omitempty"`
+	// Skip are the steps of the configuration of the VFs skipped on the PFs affected
+	// +kubebuilder:validation:MinItems=1
+	Skip []VfConfigStep `json:"skip"`
+}
+
+// ManifestOverlayType is the format of the patch of a ManifestOverlay
+type ManifestOverlayType string
+
+const (
+	// StrategicMergeOverlay patches the object with a strategic merge patch, or a JSON merge patch for the kinds
+	// without strategic merge metadata, e.g. the custom resources
+	StrategicMergeOverlay ManifestOverlayType = "StrategicMerge"
+	// JSON6902Overlay patches the object with a JSON patch (RFC 6902)
+	JSON6902Overlay ManifestOverlayType = "JSON6902"
+)
+
+// ManifestOverlay is a named patch applied to a rendered object
+type ManifestOverlay struct {
+	// Name identifies the overlay
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Target selects the rendered object the patch is applied to
+	Target OverlayTarget `json:"target"`
+	// Type of the patch. Defaults to StrategicMerge
+	// +kubebuilder:validation:Enum=StrategicMerge;JSON6902
+	Type ManifestOverlayType `json:"type,omitempty"`
+	// Patch in YAML or JSON
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
+// OverlayTarget selects a rendered object by kind and name
+type OverlayTarget struct {
+	// Kind of the object, e.g. DaemonSet
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+	// Name of the object, e.g. sriov-device-plugin
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// VlanValidationConfig defines the external hook validating that a VLAN is provisioned on the fabric.
+// The hook receives the SriovNetwork VLAN configuration as JSON and answers with {"allowed": bool, "message": string}.
+type VlanValidationConfig struct {
+	// URL of the HTTP endpoint the request is POSTed to
+	URL string `json:"url,omitempty"`
+	// Command executed in the operator container, the request is written to its stdin and the response read from its stdout
+	Command []string `json:"command,omitempty"`
+	// Timeout of the hook call in seconds. Defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy defines how hook errors are handled, "Fail" keeps the NetworkAttachmentDefinition
+	// from being rendered while "Ignore" renders it anyway. Defaults to Fail
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// PolicySignatureConfig defines the external verifier of the signatures of the SriovNetworkNodePolicies.
+// The verifier receives the policy and its signature as JSON and answers with {"allowed": bool, "message": string}.
+type PolicySignatureConfig struct {
+	// Annotation of the policies holding their signature or attestation. Defaults to sriovnetwork.openshift.io/signature
+	Annotation string `json:"annotation,omitempty"`
+	// URL of the HTTP endpoint the request is POSTed to
+	URL string `json:"url,omitempty"`
+	// Command executed in the operator and webhook containers, the request is written to its stdin and the response
+	// read from its stdout
+	Command []string `json:"command,omitempty"`
+	// Timeout of the verifier call in seconds. Defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy defines how verifier errors are handled, "Fail" rejects the policy while "Ignore" accepts it
+	// anyway. The policies without a signature are always rejected. Defaults to Fail
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// SoakTestConfig defines the scratch policy cycled on the test node pool to validate a driver/firmware/OS
+// combination before rolling out real policies
+type SoakTestConfig struct {
+	// NodeSelector selects the nodes of the test pool
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+	// NicSelector selects the NICs the scratch policy configures
+	NicSelector SriovNetworkNicSelector `json:"nicSelector"`
+	// Number of VFs created by the scratch policy
+	// +kubebuilder:validation:Minimum=1
+	NumVfs int `json:"numVfs"`
+	// The driver type of the VFs of the scratch policy
+	// +kubebuilder:validation:Enum=netdevice;vfio-pci
+	DeviceType string `json:"deviceType,omitempty"`
+	// Time in seconds after which applying or removing the scratch policy is considered failed. Defaults to 1800
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Pause in seconds between the end of a cycle and the start of the next one. Defaults to 60
+	// +kubebuilder:validation:Minimum=1
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+}
+
+// RedfishConfig defines how the operator connects to the BMCs of the nodes. The addresses of the BMCs are read from
+// a ConfigMap of the operator namespace, the nodes can't select the BMC the credentials are sent to
+type RedfishConfig struct {
+	// CredentialsSecret is the name of the secret in the operator namespace holding the "username" and
+	// "password" of the BMCs, and optionally in "ca.crt" the PEM bundle of the CAs signing their certificates. The
+	// certificates of the BMCs are verified against the system CAs when it's not set
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+	// AddressesConfigMap is the name of the ConfigMap in the operator namespace mapping the names of the nodes to
+	// the https URLs of their BMCs, e.g. "worker-0: https://10.0.0.5"
+	// +kubebuilder:validation:MinLength=1
+	AddressesConfigMap string `json:"addressesConfigMap"`
+	// Remediate enables the disabled settings through the BMC, they are applied at the next reboot of the node
+	Remediate bool `json:"remediate,omitempty"`
+}
+
+// DrainEscalationConfig defines the escalation steps of a drain that doesn't complete, in minutes since the start
+// of the drain. A step is disabled when its delay is 0, the delays of the enabled steps must increase.
+type DrainEscalationConfig struct {
+	// WarnAfterMinutes reports the drain as stuck, with a warning event listing the pods failing eviction
+	// +kubebuilder:validation:Minimum=0
+	WarnAfterMinutes int `json:"warnAfterMinutes,omitempty"`
+	// ForceAfterMinutes deletes the remaining pods instead of evicting them, ignoring their PodDisruptionBudgets
+	// +kubebuilder:validation:Minimum=0
+	ForceAfterMinutes int `json:"forceAfterMinutes,omitempty"`
+	// AbortAfterMinutes aborts the drain: the node is uncordoned and its configuration is not applied until its
+	// SriovNetworkNodeState changes
+	// +kubebuilder:validation:Minimum=0
+	AbortAfterMinutes int `json:"abortAfterMinutes,omitempty"`
+}
+
+// OpenstackMetadataConfig defines the timeout and the retries of the requests to the OpenStack metadata service,
+// the unset fields keep their default
+type OpenstackMetadataConfig struct {
+	// TimeoutSeconds is the timeout of an attempt of a request, 10 by default
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Retries is the number of retries of a failed request, 4 by default
+	// +kubebuilder:validation:Minimum=0
+	Retries *int `json:"retries,omitempty"`
+	// BackoffMinSeconds is the minimum wait between two attempts of a request, 1 by default
+	// +kubebuilder:validation:Minimum=1
+	BackoffMinSeconds int `json:"backoffMinSeconds,omitempty"`
+	// BackoffMaxSeconds is the maximum wait between two attempts of a request, 30 by default
+	// +kubebuilder:validation:Minimum=1
+	BackoffMaxSeconds int `json:"backoffMaxSeconds,omitempty"`
+	// DeadlineSeconds is the time after which the metadata service is given up, retries included, 120 by default
+	// +kubebuilder:validation:Minimum=1
+	DeadlineSeconds int `json:"deadlineSeconds,omitempty"`
+}
+
+// TracingConfig defines the OTLP collector the traces are exported to
+type TracingConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g. http://otel-collector.observability:4318
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+}
+
+// SoakTestStatus records the statistics of the soak mode cycles
+type SoakTestStatus struct {
+	// Current step of the cycle (Applying|Removing)
+	Phase string `json:"phase,omitempty"`
+	// Start time of the current step
+	PhaseStartTime *metav1.Time `json:"phaseStartTime,omitempty"`
+	// Number of completed create/apply/remove cycles
+	Cycles int `json:"cycles,omitempty"`
+	// Number of failed cycles
+	Failures int `json:"failures,omitempty"`
+	// Duration in seconds of the last apply and remove steps
+	LastApplySeconds  int `json:"lastApplySeconds,omitempty"`
+	LastRemoveSeconds int `json:"lastRemoveSeconds,omitempty"`
+	// Longest apply and remove steps in seconds
+	MaxApplySeconds  int `json:"maxApplySeconds,omitempty"`
+	MaxRemoveSeconds int `json:"maxRemoveSeconds,omitempty"`
+	// Reason of the last failure
+	LastFailure     string       `json:"lastFailure,omitempty"`
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
 }
 
 // SriovOperatorConfigStatus defines the observed state of SriovOperatorConfig
@@ -71,6 +343,28 @@ type SriovOperatorConfigStatus struct {
 	Injector string `json:"injector,omitempty"`
 	// Show the runtime status of the operator admission controller webhook
 	OperatorWebhook string `json:"operatorWebhook,omitempty"`
+	// PausedNodes are the nodes holding changes not applied while the configuration of the nodes is paused
+	PausedNodes []string `json:"pausedNodes,omitempty"`
+	// Statistics of the soak mode
+	SoakTest *SoakTestStatus `json:"soakTest,omitempty"`
+	// FeatureGates reports the effective state of the optional components
+	FeatureGates []FeatureGateStatus `json:"featureGates,omitempty"`
+	// Conditions of the operator, e.g. OperatorWebhookCertificateValid
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// FeatureGateStatus is the effective state of an optional component
+type FeatureGateStatus struct {
+	// Name of the feature gate
+	Name string `json:"name"`
+	// Enabled is the effective value of the feature gate
+	Enabled bool `json:"enabled"`
+	// State of the component: Enabled, Disabled, or Failed when it couldn't be reconciled
+	State string `json:"state"`
+	// Message explains the failure of the reconciliation of the component
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
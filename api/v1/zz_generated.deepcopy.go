@@ -22,9 +22,26 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressSharing) DeepCopyInto(out *AddressSharing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressSharing.
+func (in *AddressSharing) DeepCopy() *AddressSharing {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressSharing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ByPriority) DeepCopyInto(out *ByPriority) {
 	{
@@ -47,393 +64,1934 @@ func (in ByPriority) DeepCopy() ByPriority {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Interface) DeepCopyInto(out *Interface) {
+func (in *CniPluginStatus) DeepCopyInto(out *CniPluginStatus) {
 	*out = *in
-	if in.VfGroups != nil {
-		in, out := &in.VfGroups, &out.VfGroups
-		*out = make([]VfGroup, len(*in))
+	if in.SupportedVersions != nil {
+		in, out := &in.SupportedVersions, &out.SupportedVersions
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interface.
-func (in *Interface) DeepCopy() *Interface {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CniPluginStatus.
+func (in *CniPluginStatus) DeepCopy() *CniPluginStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Interface)
+	out := new(CniPluginStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InterfaceExt) DeepCopyInto(out *InterfaceExt) {
+func (in *CongestionControlConfig) DeepCopyInto(out *CongestionControlConfig) {
 	*out = *in
-	if in.VFs != nil {
-		in, out := &in.VFs, &out.VFs
-		*out = make([]VirtualFunction, len(*in))
+	if in.EcnPriorities != nil {
+		in, out := &in.EcnPriorities, &out.EcnPriorities
+		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.CnpDscp != nil {
+		in, out := &in.CnpDscp, &out.CnpDscp
+		*out = new(int)
+		**out = **in
+	}
+	if in.CnpPriority != nil {
+		in, out := &in.CnpPriority, &out.CnpPriority
+		*out = new(int)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceExt.
-func (in *InterfaceExt) DeepCopy() *InterfaceExt {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CongestionControlConfig.
+func (in *CongestionControlConfig) DeepCopy() *CongestionControlConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(InterfaceExt)
+	out := new(CongestionControlConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in InterfaceExts) DeepCopyInto(out *InterfaceExts) {
-	{
-		in := &in
-		*out = make(InterfaceExts, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+func (in *DrainEscalationConfig) DeepCopyInto(out *DrainEscalationConfig) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceExts.
-func (in InterfaceExts) DeepCopy() InterfaceExts {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainEscalationConfig.
+func (in *DrainEscalationConfig) DeepCopy() *DrainEscalationConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(InterfaceExts)
+	out := new(DrainEscalationConfig)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in Interfaces) DeepCopyInto(out *Interfaces) {
-	{
-		in := &in
-		*out = make(Interfaces, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+func (in *DrainPodFailure) DeepCopyInto(out *DrainPodFailure) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interfaces.
-func (in Interfaces) DeepCopy() Interfaces {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainPodFailure.
+func (in *DrainPodFailure) DeepCopy() *DrainPodFailure {
 	if in == nil {
 		return nil
 	}
-	out := new(Interfaces)
+	out := new(DrainPodFailure)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OvsHardwareOffloadConfig) DeepCopyInto(out *OvsHardwareOffloadConfig) {
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
 	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.Deadline != nil {
+		in, out := &in.Deadline, &out.Deadline
+		*out = (*in).DeepCopy()
+	}
+	if in.FailingPods != nil {
+		in, out := &in.FailingPods, &out.FailingPods
+		*out = make([]DrainPodFailure, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvsHardwareOffloadConfig.
-func (in *OvsHardwareOffloadConfig) DeepCopy() *OvsHardwareOffloadConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OvsHardwareOffloadConfig)
+	out := new(DrainStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in PluginNameSlice) DeepCopyInto(out *PluginNameSlice) {
-	{
-		in := &in
-		*out = make(PluginNameSlice, len(*in))
+func (in *DriverQuirk) DeepCopyInto(out *DriverQuirk) {
+	*out = *in
+	if in.Skip != nil {
+		in, out := &in.Skip, &out.Skip
+		*out = make([]VfConfigStep, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginNameSlice.
-func (in PluginNameSlice) DeepCopy() PluginNameSlice {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverQuirk.
+func (in *DriverQuirk) DeepCopy() *DriverQuirk {
 	if in == nil {
 		return nil
 	}
-	out := new(PluginNameSlice)
+	out := new(DriverQuirk)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovIBNetwork) DeepCopyInto(out *SriovIBNetwork) {
+func (in *FeatureGateStatus) DeepCopyInto(out *FeatureGateStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetwork.
-func (in *SriovIBNetwork) DeepCopy() *SriovIBNetwork {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureGateStatus.
+func (in *FeatureGateStatus) DeepCopy() *FeatureGateStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovIBNetwork)
+	out := new(FeatureGateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovIBNetwork) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovIBNetworkList) DeepCopyInto(out *SriovIBNetworkList) {
+func (in *FlowMatch) DeepCopyInto(out *FlowMatch) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SriovIBNetwork, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkList.
-func (in *SriovIBNetworkList) DeepCopy() *SriovIBNetworkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowMatch.
+func (in *FlowMatch) DeepCopy() *FlowMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovIBNetworkList)
+	out := new(FlowMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovIBNetworkList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovIBNetworkSpec) DeepCopyInto(out *SriovIBNetworkSpec) {
+func (in *FlowRule) DeepCopyInto(out *FlowRule) {
 	*out = *in
+	if in.Vf != nil {
+		in, out := &in.Vf, &out.Vf
+		*out = new(int)
+		**out = **in
+	}
+	out.Match = in.Match
+	if in.TargetVf != nil {
+		in, out := &in.TargetVf, &out.TargetVf
+		*out = new(int)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkSpec.
-func (in *SriovIBNetworkSpec) DeepCopy() *SriovIBNetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowRule.
+func (in *FlowRule) DeepCopy() *FlowRule {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovIBNetworkSpec)
+	out := new(FlowRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovIBNetworkStatus) DeepCopyInto(out *SriovIBNetworkStatus) {
+func (in *Hook) DeepCopyInto(out *Hook) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkStatus.
-func (in *SriovIBNetworkStatus) DeepCopy() *SriovIBNetworkStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hook.
+func (in *Hook) DeepCopy() *Hook {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovIBNetworkStatus)
+	out := new(Hook)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetwork) DeepCopyInto(out *SriovNetwork) {
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetwork.
-func (in *SriovNetwork) DeepCopy() *SriovNetwork {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetwork)
+	out := new(HookStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetwork) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkList) DeepCopyInto(out *SriovNetworkList) {
+func (in *Interface) DeepCopyInto(out *Interface) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SriovNetwork, len(*in))
+	if in.VfGroups != nil {
+		in, out := &in.VfGroups, &out.VfGroups
+		*out = make([]VfGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FlowRules != nil {
+		in, out := &in.FlowRules, &out.FlowRules
+		*out = make([]FlowRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Qos != nil {
+		in, out := &in.Qos, &out.Qos
+		*out = new(QosConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CongestionControl != nil {
+		in, out := &in.CongestionControl, &out.CongestionControl
+		*out = new(CongestionControlConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkList.
-func (in *SriovNetworkList) DeepCopy() *SriovNetworkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interface.
+func (in *Interface) DeepCopy() *Interface {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkList)
+	out := new(Interface)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNicSelector) DeepCopyInto(out *SriovNetworkNicSelector) {
+func (in *InterfaceExt) DeepCopyInto(out *InterfaceExt) {
 	*out = *in
-	if in.RootDevices != nil {
-		in, out := &in.RootDevices, &out.RootDevices
-		*out = make([]string, len(*in))
+	if in.VFs != nil {
+		in, out := &in.VFs, &out.VFs
+		*out = make([]VirtualFunction, len(*in))
 		copy(*out, *in)
 	}
-	if in.PfNames != nil {
-		in, out := &in.PfNames, &out.PfNames
+	if in.Transceiver != nil {
+		in, out := &in.Transceiver, &out.Transceiver
+		*out = new(TransceiverInfo)
+		**out = **in
+	}
+	if in.LldpNeighbor != nil {
+		in, out := &in.LldpNeighbor, &out.LldpNeighbor
+		*out = new(LldpNeighbor)
+		**out = **in
+	}
+	if in.VFsSummary != nil {
+		in, out := &in.VFsSummary, &out.VFsSummary
+		*out = make([]VirtualFunctionRange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Qos != nil {
+		in, out := &in.Qos, &out.Qos
+		*out = new(QosConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CongestionControl != nil {
+		in, out := &in.CongestionControl, &out.CongestionControl
+		*out = new(CongestionControlConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ptp != nil {
+		in, out := &in.Ptp, &out.Ptp
+		*out = new(PtpInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PcieLink != nil {
+		in, out := &in.PcieLink, &out.PcieLink
+		*out = new(PcieLinkInfo)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(NicPortInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SplitPorts != nil {
+		in, out := &in.SplitPorts, &out.SplitPorts
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Subports != nil {
+		in, out := &in.Subports, &out.Subports
+		*out = make([]OpenstackSubport, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNicSelector.
-func (in *SriovNetworkNicSelector) DeepCopy() *SriovNetworkNicSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceExt.
+func (in *InterfaceExt) DeepCopy() *InterfaceExt {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNicSelector)
+	out := new(InterfaceExt)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodePolicy) DeepCopyInto(out *SriovNetworkNodePolicy) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+func (in InterfaceExts) DeepCopyInto(out *InterfaceExts) {
+	{
+		in := &in
+		*out = make(InterfaceExts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicy.
-func (in *SriovNetworkNodePolicy) DeepCopy() *SriovNetworkNodePolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceExts.
+func (in InterfaceExts) DeepCopy() InterfaceExts {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodePolicy)
+	out := new(InterfaceExts)
 	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkNodePolicy) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
+	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodePolicyList) DeepCopyInto(out *SriovNetworkNodePolicyList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SriovNetworkNodePolicy, len(*in))
+func (in Interfaces) DeepCopyInto(out *Interfaces) {
+	{
+		in := &in
+		*out = make(Interfaces, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicyList.
-func (in *SriovNetworkNodePolicyList) DeepCopy() *SriovNetworkNodePolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interfaces.
+func (in Interfaces) DeepCopy() Interfaces {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodePolicyList)
+	out := new(Interfaces)
 	in.DeepCopyInto(out)
-	return out
+	return *out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LldpNeighbor) DeepCopyInto(out *LldpNeighbor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LldpNeighbor.
+func (in *LldpNeighbor) DeepCopy() *LldpNeighbor {
+	if in == nil {
+		return nil
+	}
+	out := new(LldpNeighbor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestOverlay) DeepCopyInto(out *ManifestOverlay) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestOverlay.
+func (in *ManifestOverlay) DeepCopy() *ManifestOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorConfig) DeepCopyInto(out *MirrorConfig) {
+	*out = *in
+	if in.SourceVfs != nil {
+		in, out := &in.SourceVfs, &out.SourceVfs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorConfig.
+func (in *MirrorConfig) DeepCopy() *MirrorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverlayTarget) DeepCopyInto(out *OverlayTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverlayTarget.
+func (in *OverlayTarget) DeepCopy() *OverlayTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(OverlayTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataProvenance) DeepCopyInto(out *MetadataProvenance) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ReadTime.DeepCopyInto(&out.ReadTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataProvenance.
+func (in *MetadataProvenance) DeepCopy() *MetadataProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicPortInfo) DeepCopyInto(out *NicPortInfo) {
+	*out = *in
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]PortCapability, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicPortInfo.
+func (in *NicPortInfo) DeepCopy() *NicPortInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NicPortInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackMetadataConfig) DeepCopyInto(out *OpenstackMetadataConfig) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackMetadataConfig.
+func (in *OpenstackMetadataConfig) DeepCopy() *OpenstackMetadataConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackMetadataConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackSubport) DeepCopyInto(out *OpenstackSubport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackSubport.
+func (in *OpenstackSubport) DeepCopy() *OpenstackSubport {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackSubport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnHardwareOffloadConfig) DeepCopyInto(out *OvnHardwareOffloadConfig) {
+	*out = *in
+	if in.PfNames != nil {
+		in, out := &in.PfNames, &out.PfNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnHardwareOffloadConfig.
+func (in *OvnHardwareOffloadConfig) DeepCopy() *OvnHardwareOffloadConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnHardwareOffloadConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnHardwareOffloadStatus) DeepCopyInto(out *OvnHardwareOffloadStatus) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]OvnHardwareOffloadStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnHardwareOffloadStatus.
+func (in *OvnHardwareOffloadStatus) DeepCopy() *OvnHardwareOffloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnHardwareOffloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnHardwareOffloadStep) DeepCopyInto(out *OvnHardwareOffloadStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnHardwareOffloadStep.
+func (in *OvnHardwareOffloadStep) DeepCopy() *OvnHardwareOffloadStep {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnHardwareOffloadStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvsHardwareOffloadConfig) DeepCopyInto(out *OvsHardwareOffloadConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvsHardwareOffloadConfig.
+func (in *OvsHardwareOffloadConfig) DeepCopy() *OvsHardwareOffloadConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OvsHardwareOffloadConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PcieLinkInfo) DeepCopyInto(out *PcieLinkInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PcieLinkInfo.
+func (in *PcieLinkInfo) DeepCopy() *PcieLinkInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PcieLinkInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PluginNameSlice) DeepCopyInto(out *PluginNameSlice) {
+	{
+		in := &in
+		*out = make(PluginNameSlice, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginNameSlice.
+func (in PluginNameSlice) DeepCopy() PluginNameSlice {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginNameSlice)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicingConfig) DeepCopyInto(out *PolicingConfig) {
+	*out = *in
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = new(PolicingRate)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(PolicingRate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicingConfig.
+func (in *PolicingConfig) DeepCopy() *PolicingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicingRate) DeepCopyInto(out *PolicingRate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicingRate.
+func (in *PolicingRate) DeepCopy() *PolicingRate {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicingRate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySignatureConfig) DeepCopyInto(out *PolicySignatureConfig) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySignatureConfig.
+func (in *PolicySignatureConfig) DeepCopy() *PolicySignatureConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySignatureConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PtpInfo) DeepCopyInto(out *PtpInfo) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PtpInfo.
+func (in *PtpInfo) DeepCopy() *PtpInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PtpInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QosConfig) DeepCopyInto(out *QosConfig) {
+	*out = *in
+	if in.PfcPriorities != nil {
+		in, out := &in.PfcPriorities, &out.PfcPriorities
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrioToBuffer != nil {
+		in, out := &in.PrioToBuffer, &out.PrioToBuffer
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.BufferSizes != nil {
+		in, out := &in.BufferSizes, &out.BufferSizes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QosConfig.
+func (in *QosConfig) DeepCopy() *QosConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QosConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedfishConfig) DeepCopyInto(out *RedfishConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedfishConfig.
+func (in *RedfishConfig) DeepCopy() *RedfishConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedfishConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakTestConfig) DeepCopyInto(out *SoakTestConfig) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.NicSelector.DeepCopyInto(&out.NicSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakTestConfig.
+func (in *SoakTestConfig) DeepCopy() *SoakTestConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakTestConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakTestStatus) DeepCopyInto(out *SoakTestStatus) {
+	*out = *in
+	if in.PhaseStartTime != nil {
+		in, out := &in.PhaseStartTime, &out.PhaseStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakTestStatus.
+func (in *SoakTestStatus) DeepCopy() *SoakTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceInjection) DeepCopyInto(out *ResourceInjection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceInjection.
+func (in *ResourceInjection) DeepCopy() *ResourceInjection {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecVersionRange) DeepCopyInto(out *SpecVersionRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecVersionRange.
+func (in *SpecVersionRange) DeepCopy() *SpecVersionRange {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecVersionRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterFailingNode) DeepCopyInto(out *SriovClusterFailingNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterFailingNode.
+func (in *SriovClusterFailingNode) DeepCopy() *SriovClusterFailingNode {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterFailingNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterPendingReboot) DeepCopyInto(out *SriovClusterPendingReboot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterPendingReboot.
+func (in *SriovClusterPendingReboot) DeepCopy() *SriovClusterPendingReboot {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterPendingReboot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterPolicy) DeepCopyInto(out *SriovClusterPolicy) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterPolicy.
+func (in *SriovClusterPolicy) DeepCopy() *SriovClusterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterPool) DeepCopyInto(out *SriovClusterPool) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterPool.
+func (in *SriovClusterPool) DeepCopy() *SriovClusterPool {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterResource) DeepCopyInto(out *SriovClusterResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterResource.
+func (in *SriovClusterResource) DeepCopy() *SriovClusterResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterState) DeepCopyInto(out *SriovClusterState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterState.
+func (in *SriovClusterState) DeepCopy() *SriovClusterState {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovClusterState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterStateList) DeepCopyInto(out *SriovClusterStateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovClusterState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterStateList.
+func (in *SriovClusterStateList) DeepCopy() *SriovClusterStateList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterStateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovClusterStateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovClusterStateStatus) DeepCopyInto(out *SriovClusterStateStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]SriovClusterResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailingNodes != nil {
+		in, out := &in.FailingNodes, &out.FailingNodes
+		*out = make([]SriovClusterFailingNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingReboots != nil {
+		in, out := &in.PendingReboots, &out.PendingReboots
+		*out = make([]SriovClusterPendingReboot, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]SriovClusterPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]SriovClusterPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateTime != nil {
+		in, out := &in.UpdateTime, &out.UpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovClusterStateStatus.
+func (in *SriovClusterStateStatus) DeepCopy() *SriovClusterStateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovClusterStateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovIBNetwork) DeepCopyInto(out *SriovIBNetwork) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetwork.
+func (in *SriovIBNetwork) DeepCopy() *SriovIBNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovIBNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovIBNetwork) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovIBNetworkList) DeepCopyInto(out *SriovIBNetworkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovIBNetwork, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkList.
+func (in *SriovIBNetworkList) DeepCopy() *SriovIBNetworkList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovIBNetworkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovIBNetworkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovIBNetworkSpec) DeepCopyInto(out *SriovIBNetworkSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkSpec.
+func (in *SriovIBNetworkSpec) DeepCopy() *SriovIBNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovIBNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovIBNetworkStatus) DeepCopyInto(out *SriovIBNetworkStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovIBNetworkStatus.
+func (in *SriovIBNetworkStatus) DeepCopy() *SriovIBNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovIBNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetwork) DeepCopyInto(out *SriovNetwork) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetwork.
+func (in *SriovNetwork) DeepCopy() *SriovNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetwork) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkGrant) DeepCopyInto(out *SriovNetworkGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkGrant.
+func (in *SriovNetworkGrant) DeepCopy() *SriovNetworkGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkGrantList) DeepCopyInto(out *SriovNetworkGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetworkGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkGrantList.
+func (in *SriovNetworkGrantList) DeepCopy() *SriovNetworkGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkGrantSpec) DeepCopyInto(out *SriovNetworkGrantSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceNames != nil {
+		in, out := &in.ResourceNames, &out.ResourceNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VlanRanges != nil {
+		in, out := &in.VlanRanges, &out.VlanRanges
+		*out = make([]VlanRange, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAMBlocks != nil {
+		in, out := &in.IPAMBlocks, &out.IPAMBlocks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkGrantSpec.
+func (in *SriovNetworkGrantSpec) DeepCopy() *SriovNetworkGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkGrantStatus) DeepCopyInto(out *SriovNetworkGrantStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkGrantStatus.
+func (in *SriovNetworkGrantStatus) DeepCopy() *SriovNetworkGrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkGrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkList) DeepCopyInto(out *SriovNetworkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetwork, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkList.
+func (in *SriovNetworkList) DeepCopy() *SriovNetworkList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNicSelector) DeepCopyInto(out *SriovNetworkNicSelector) {
+	*out = *in
+	if in.RootDevices != nil {
+		in, out := &in.RootDevices, &out.RootDevices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PfNames != nil {
+		in, out := &in.PfNames, &out.PfNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ZpciUids != nil {
+		in, out := &in.ZpciUids, &out.ZpciUids
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNicSelector.
+func (in *SriovNetworkNicSelector) DeepCopy() *SriovNetworkNicSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNicSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodePolicy) DeepCopyInto(out *SriovNetworkNodePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicy.
+func (in *SriovNetworkNodePolicy) DeepCopy() *SriovNetworkNodePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkNodePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodePolicyList) DeepCopyInto(out *SriovNetworkNodePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetworkNodePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicyList.
+func (in *SriovNetworkNodePolicyList) DeepCopy() *SriovNetworkNodePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
 func (in *SriovNetworkNodePolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodePolicySpec) DeepCopyInto(out *SriovNetworkNodePolicySpec) {
-	*out = *in
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodePolicySpec) DeepCopyInto(out *SriovNetworkNodePolicySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.NicSelector.DeepCopyInto(&out.NicSelector)
+	if in.FlowRules != nil {
+		in, out := &in.FlowRules, &out.FlowRules
+		*out = make([]FlowRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(MirrorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Qos != nil {
+		in, out := &in.Qos, &out.Qos
+		*out = new(QosConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CongestionControl != nil {
+		in, out := &in.CongestionControl, &out.CongestionControl
+		*out = new(CongestionControlConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnicastMacs != nil {
+		in, out := &in.UnicastMacs, &out.UnicastMacs
+		*out = make([]VfUnicastMacs, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicySpec.
+func (in *SriovNetworkNodePolicySpec) DeepCopy() *SriovNetworkNodePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodePolicyStatus) DeepCopyInto(out *SriovNetworkNodePolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicyStatus.
+func (in *SriovNetworkNodePolicyStatus) DeepCopy() *SriovNetworkNodePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodeState) DeepCopyInto(out *SriovNetworkNodeState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeState.
+func (in *SriovNetworkNodeState) DeepCopy() *SriovNetworkNodeState {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodeState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkNodeState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodeStateList) DeepCopyInto(out *SriovNetworkNodeStateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetworkNodeState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateList.
+func (in *SriovNetworkNodeStateList) DeepCopy() *SriovNetworkNodeStateList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodeStateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkNodeStateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodeStateSpec) DeepCopyInto(out *SriovNetworkNodeStateSpec) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make(Interfaces, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateSpec.
+func (in *SriovNetworkNodeStateSpec) DeepCopy() *SriovNetworkNodeStateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodeStateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkNodeStateStatus) DeepCopyInto(out *SriovNetworkNodeStateStatus) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make(InterfaceExts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HugepageSizes != nil {
+		in, out := &in.HugepageSizes, &out.HugepageSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(MetadataProvenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SupportedSpecVersions != nil {
+		in, out := &in.SupportedSpecVersions, &out.SupportedSpecVersions
+		*out = new(SpecVersionRange)
+		**out = **in
+	}
+	if in.CniPlugins != nil {
+		in, out := &in.CniPlugins, &out.CniPlugins
+		*out = make([]CniPluginStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateStatus.
+func (in *SriovNetworkNodeStateStatus) DeepCopy() *SriovNetworkNodeStateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkNodeStateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkPoolConfig) DeepCopyInto(out *SriovNetworkPoolConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfig.
+func (in *SriovNetworkPoolConfig) DeepCopy() *SriovNetworkPoolConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkPoolConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkPoolConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkPoolConfigList) DeepCopyInto(out *SriovNetworkPoolConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetworkPoolConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigList.
+func (in *SriovNetworkPoolConfigList) DeepCopy() *SriovNetworkPoolConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkPoolConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkPoolConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkPoolConfigSpec) DeepCopyInto(out *SriovNetworkPoolConfigSpec) {
+	*out = *in
+	out.OvsHardwareOffloadConfig = in.OvsHardwareOffloadConfig
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OvnHardwareOffload != nil {
+		in, out := &in.OvnHardwareOffload, &out.OvnHardwareOffload
+		*out = new(OvnHardwareOffloadConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigSpec.
+func (in *SriovNetworkPoolConfigSpec) DeepCopy() *SriovNetworkPoolConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkPoolConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkPoolConfigStatus) DeepCopyInto(out *SriovNetworkPoolConfigStatus) {
+	*out = *in
+	if in.OvnHardwareOffload != nil {
+		in, out := &in.OvnHardwareOffload, &out.OvnHardwareOffload
+		*out = new(OvnHardwareOffloadStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigStatus.
+func (in *SriovNetworkPoolConfigStatus) DeepCopy() *SriovNetworkPoolConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkPoolConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkQuota) DeepCopyInto(out *SriovNetworkQuota) {
+	*out = *in
+	if in.MaxVfsPerNamespace != nil {
+		in, out := &in.MaxVfsPerNamespace, &out.MaxVfsPerNamespace
+		*out = new(int)
+		**out = **in
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]TenantQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkQuota.
+func (in *SriovNetworkQuota) DeepCopy() *SriovNetworkQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResource) DeepCopyInto(out *SriovNetworkResource) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]SriovNetworkResourcePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResource.
+func (in *SriovNetworkResource) DeepCopy() *SriovNetworkResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResourceMap) DeepCopyInto(out *SriovNetworkResourceMap) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResourceMap.
+func (in *SriovNetworkResourceMap) DeepCopy() *SriovNetworkResourceMap {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResourceMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkResourceMap) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResourceMapList) DeepCopyInto(out *SriovNetworkResourceMapList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovNetworkResourceMap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResourceMapList.
+func (in *SriovNetworkResourceMapList) DeepCopy() *SriovNetworkResourceMapList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResourceMapList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovNetworkResourceMapList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResourcePool) DeepCopyInto(out *SriovNetworkResourcePool) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResourcePool.
+func (in *SriovNetworkResourcePool) DeepCopy() *SriovNetworkResourcePool {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResourcePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResourceMapStatus) DeepCopyInto(out *SriovNetworkResourceMapStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]SriovNetworkResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]SriovNetworkResourcePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PoolsUpdateTime != nil {
+		in, out := &in.PoolsUpdateTime, &out.PoolsUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResourceMapStatus.
+func (in *SriovNetworkResourceMapStatus) DeepCopy() *SriovNetworkResourceMapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResourceMapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkResourcePolicy) DeepCopyInto(out *SriovNetworkResourcePolicy) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkResourcePolicy.
+func (in *SriovNetworkResourcePolicy) DeepCopy() *SriovNetworkResourcePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkResourcePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkSpec) DeepCopyInto(out *SriovNetworkSpec) {
+	*out = *in
+	if in.MinTxRate != nil {
+		in, out := &in.MinTxRate, &out.MinTxRate
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxTxRate != nil {
+		in, out := &in.MaxTxRate, &out.MaxTxRate
+		*out = new(int)
+		**out = **in
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(SriovNetworkQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceInjection != nil {
+		in, out := &in.ResourceInjection, &out.ResourceInjection
+		*out = new(ResourceInjection)
+		**out = **in
+	}
+	if in.Policing != nil {
+		in, out := &in.Policing, &out.Policing
+		*out = new(PolicingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddressSharing != nil {
+		in, out := &in.AddressSharing, &out.AddressSharing
+		*out = new(AddressSharing)
+		**out = **in
 	}
-	in.NicSelector.DeepCopyInto(&out.NicSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicySpec.
-func (in *SriovNetworkNodePolicySpec) DeepCopy() *SriovNetworkNodePolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkSpec.
+func (in *SriovNetworkSpec) DeepCopy() *SriovNetworkSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodePolicySpec)
+	out := new(SriovNetworkSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodePolicyStatus) DeepCopyInto(out *SriovNetworkNodePolicyStatus) {
+func (in *SriovNetworkStatus) DeepCopyInto(out *SriovNetworkStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodePolicyStatus.
-func (in *SriovNetworkNodePolicyStatus) DeepCopy() *SriovNetworkNodePolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkStatus.
+func (in *SriovNetworkStatus) DeepCopy() *SriovNetworkStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodePolicyStatus)
+	out := new(SriovNetworkStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodeState) DeepCopyInto(out *SriovNetworkNodeState) {
+func (in *SriovNetworkTest) DeepCopyInto(out *SriovNetworkTest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -441,18 +1999,18 @@ func (in *SriovNetworkNodeState) DeepCopyInto(out *SriovNetworkNodeState) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeState.
-func (in *SriovNetworkNodeState) DeepCopy() *SriovNetworkNodeState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkTest.
+func (in *SriovNetworkTest) DeepCopy() *SriovNetworkTest {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodeState)
+	out := new(SriovNetworkTest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkNodeState) DeepCopyObject() runtime.Object {
+func (in *SriovNetworkTest) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -460,31 +2018,31 @@ func (in *SriovNetworkNodeState) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodeStateList) DeepCopyInto(out *SriovNetworkNodeStateList) {
+func (in *SriovNetworkTestList) DeepCopyInto(out *SriovNetworkTestList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SriovNetworkNodeState, len(*in))
+		*out = make([]SriovNetworkTest, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateList.
-func (in *SriovNetworkNodeStateList) DeepCopy() *SriovNetworkNodeStateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkTestList.
+func (in *SriovNetworkTestList) DeepCopy() *SriovNetworkTestList {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodeStateList)
+	out := new(SriovNetworkTestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkNodeStateList) DeepCopyObject() runtime.Object {
+func (in *SriovNetworkTestList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -492,70 +2050,96 @@ func (in *SriovNetworkNodeStateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodeStateSpec) DeepCopyInto(out *SriovNetworkNodeStateSpec) {
+func (in *SriovNetworkTestResult) DeepCopyInto(out *SriovNetworkTestResult) {
 	*out = *in
-	if in.Interfaces != nil {
-		in, out := &in.Interfaces, &out.Interfaces
-		*out = make(Interfaces, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkTestResult.
+func (in *SriovNetworkTestResult) DeepCopy() *SriovNetworkTestResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovNetworkTestResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovNetworkTestSpec) DeepCopyInto(out *SriovNetworkTestSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]SriovNetworkTestType, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateSpec.
-func (in *SriovNetworkNodeStateSpec) DeepCopy() *SriovNetworkNodeStateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkTestSpec.
+func (in *SriovNetworkTestSpec) DeepCopy() *SriovNetworkTestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodeStateSpec)
+	out := new(SriovNetworkTestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkNodeStateStatus) DeepCopyInto(out *SriovNetworkNodeStateStatus) {
+func (in *SriovNetworkTestStatus) DeepCopyInto(out *SriovNetworkTestStatus) {
 	*out = *in
-	if in.Interfaces != nil {
-		in, out := &in.Interfaces, &out.Interfaces
-		*out = make(InterfaceExts, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]SriovNetworkTestResult, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateStatus.
-func (in *SriovNetworkNodeStateStatus) DeepCopy() *SriovNetworkNodeStateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkTestStatus.
+func (in *SriovNetworkTestStatus) DeepCopy() *SriovNetworkTestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkNodeStateStatus)
+	out := new(SriovNetworkTestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkPoolConfig) DeepCopyInto(out *SriovNetworkPoolConfig) {
+func (in *SriovOperatorConfig) DeepCopyInto(out *SriovOperatorConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfig.
-func (in *SriovNetworkPoolConfig) DeepCopy() *SriovNetworkPoolConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfig.
+func (in *SriovOperatorConfig) DeepCopy() *SriovOperatorConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkPoolConfig)
+	out := new(SriovOperatorConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkPoolConfig) DeepCopyObject() runtime.Object {
+func (in *SriovOperatorConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -563,31 +2147,31 @@ func (in *SriovNetworkPoolConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkPoolConfigList) DeepCopyInto(out *SriovNetworkPoolConfigList) {
+func (in *SriovOperatorConfigList) DeepCopyInto(out *SriovOperatorConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SriovNetworkPoolConfig, len(*in))
+		*out = make([]SriovOperatorConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigList.
-func (in *SriovNetworkPoolConfigList) DeepCopy() *SriovNetworkPoolConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigList.
+func (in *SriovOperatorConfigList) DeepCopy() *SriovOperatorConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkPoolConfigList)
+	out := new(SriovOperatorConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovNetworkPoolConfigList) DeepCopyObject() runtime.Object {
+func (in *SriovOperatorConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -595,203 +2179,281 @@ func (in *SriovNetworkPoolConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkPoolConfigSpec) DeepCopyInto(out *SriovNetworkPoolConfigSpec) {
+func (in *SriovOperatorConfigSpec) DeepCopyInto(out *SriovOperatorConfigSpec) {
 	*out = *in
-	out.OvsHardwareOffloadConfig = in.OvsHardwareOffloadConfig
+	if in.ConfigDaemonNodeSelector != nil {
+		in, out := &in.ConfigDaemonNodeSelector, &out.ConfigDaemonNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DisablePlugins != nil {
+		in, out := &in.DisablePlugins, &out.DisablePlugins
+		*out = make(PluginNameSlice, len(*in))
+		copy(*out, *in)
+	}
+	if in.VlanValidation != nil {
+		in, out := &in.VlanValidation, &out.VlanValidation
+		*out = new(VlanValidationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PolicySignature != nil {
+		in, out := &in.PolicySignature, &out.PolicySignature
+		*out = new(PolicySignatureConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoakTest != nil {
+		in, out := &in.SoakTest, &out.SoakTest
+		*out = new(SoakTestConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Redfish != nil {
+		in, out := &in.Redfish, &out.Redfish
+		*out = new(RedfishConfig)
+		**out = **in
+	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(TracingConfig)
+		**out = **in
+	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]ManifestOverlay, len(*in))
+		copy(*out, *in)
+	}
+	if in.DrainEscalation != nil {
+		in, out := &in.DrainEscalation, &out.DrainEscalation
+		*out = new(DrainEscalationConfig)
+		**out = **in
+	}
+	if in.DriverQuirks != nil {
+		in, out := &in.DriverQuirks, &out.DriverQuirks
+		*out = make([]DriverQuirk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceIPFamilies != nil {
+		in, out := &in.ServiceIPFamilies, &out.ServiceIPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.OpenstackMetadata != nil {
+		in, out := &in.OpenstackMetadata, &out.OpenstackMetadata
+		*out = new(OpenstackMetadataConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigSpec.
-func (in *SriovNetworkPoolConfigSpec) DeepCopy() *SriovNetworkPoolConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigSpec.
+func (in *SriovOperatorConfigSpec) DeepCopy() *SriovOperatorConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkPoolConfigSpec)
+	out := new(SriovOperatorConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkPoolConfigStatus) DeepCopyInto(out *SriovNetworkPoolConfigStatus) {
+func (in *SriovOperatorConfigStatus) DeepCopyInto(out *SriovOperatorConfigStatus) {
 	*out = *in
+	if in.PausedNodes != nil {
+		in, out := &in.PausedNodes, &out.PausedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SoakTest != nil {
+		in, out := &in.SoakTest, &out.SoakTest
+		*out = new(SoakTestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make([]FeatureGateStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkPoolConfigStatus.
-func (in *SriovNetworkPoolConfigStatus) DeepCopy() *SriovNetworkPoolConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigStatus.
+func (in *SriovOperatorConfigStatus) DeepCopy() *SriovOperatorConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkPoolConfigStatus)
+	out := new(SriovOperatorConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkSpec) DeepCopyInto(out *SriovNetworkSpec) {
+func (in *TenantQuota) DeepCopyInto(out *TenantQuota) {
 	*out = *in
-	if in.MinTxRate != nil {
-		in, out := &in.MinTxRate, &out.MinTxRate
+	if in.MaxVfs != nil {
+		in, out := &in.MaxVfs, &out.MaxVfs
 		*out = new(int)
 		**out = **in
 	}
-	if in.MaxTxRate != nil {
-		in, out := &in.MaxTxRate, &out.MaxTxRate
-		*out = new(int)
-		**out = **in
+	if in.AllowedVlans != nil {
+		in, out := &in.AllowedVlans, &out.AllowedVlans
+		*out = make([]int, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkSpec.
-func (in *SriovNetworkSpec) DeepCopy() *SriovNetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantQuota.
+func (in *TenantQuota) DeepCopy() *TenantQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkSpec)
+	out := new(TenantQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovNetworkStatus) DeepCopyInto(out *SriovNetworkStatus) {
+func (in *TracingConfig) DeepCopyInto(out *TracingConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkStatus.
-func (in *SriovNetworkStatus) DeepCopy() *SriovNetworkStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TracingConfig.
+func (in *TracingConfig) DeepCopy() *TracingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovNetworkStatus)
+	out := new(TracingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovOperatorConfig) DeepCopyInto(out *SriovOperatorConfig) {
+func (in *TransceiverInfo) DeepCopyInto(out *TransceiverInfo) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfig.
-func (in *SriovOperatorConfig) DeepCopy() *SriovOperatorConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransceiverInfo.
+func (in *TransceiverInfo) DeepCopy() *TransceiverInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovOperatorConfig)
+	out := new(TransceiverInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovOperatorConfig) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovOperatorConfigList) DeepCopyInto(out *SriovOperatorConfigList) {
+func (in *VfGroup) DeepCopyInto(out *VfGroup) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SriovOperatorConfig, len(*in))
+	if in.Policing != nil {
+		in, out := &in.Policing, &out.Policing
+		*out = new(PolicingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddressSharing != nil {
+		in, out := &in.AddressSharing, &out.AddressSharing
+		*out = new(AddressSharing)
+		**out = **in
+	}
+	if in.UnicastMacs != nil {
+		in, out := &in.UnicastMacs, &out.UnicastMacs
+		*out = make([]VfUnicastMacs, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigList.
-func (in *SriovOperatorConfigList) DeepCopy() *SriovOperatorConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VfGroup.
+func (in *VfGroup) DeepCopy() *VfGroup {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovOperatorConfigList)
+	out := new(VfGroup)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SriovOperatorConfigList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovOperatorConfigSpec) DeepCopyInto(out *SriovOperatorConfigSpec) {
+func (in *VfUnicastMacs) DeepCopyInto(out *VfUnicastMacs) {
 	*out = *in
-	if in.ConfigDaemonNodeSelector != nil {
-		in, out := &in.ConfigDaemonNodeSelector, &out.ConfigDaemonNodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.DisablePlugins != nil {
-		in, out := &in.DisablePlugins, &out.DisablePlugins
-		*out = make(PluginNameSlice, len(*in))
+	if in.Macs != nil {
+		in, out := &in.Macs, &out.Macs
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigSpec.
-func (in *SriovOperatorConfigSpec) DeepCopy() *SriovOperatorConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VfUnicastMacs.
+func (in *VfUnicastMacs) DeepCopy() *VfUnicastMacs {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovOperatorConfigSpec)
+	out := new(VfUnicastMacs)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SriovOperatorConfigStatus) DeepCopyInto(out *SriovOperatorConfigStatus) {
+func (in *VirtualFunction) DeepCopyInto(out *VirtualFunction) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigStatus.
-func (in *SriovOperatorConfigStatus) DeepCopy() *SriovOperatorConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunction.
+func (in *VirtualFunction) DeepCopy() *VirtualFunction {
 	if in == nil {
 		return nil
 	}
-	out := new(SriovOperatorConfigStatus)
+	out := new(VirtualFunction)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VfGroup) DeepCopyInto(out *VfGroup) {
+func (in *VirtualFunctionRange) DeepCopyInto(out *VirtualFunctionRange) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VfGroup.
-func (in *VfGroup) DeepCopy() *VfGroup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunctionRange.
+func (in *VirtualFunctionRange) DeepCopy() *VirtualFunctionRange {
 	if in == nil {
 		return nil
 	}
-	out := new(VfGroup)
+	out := new(VirtualFunctionRange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualFunction) DeepCopyInto(out *VirtualFunction) {
+func (in *VlanValidationConfig) DeepCopyInto(out *VlanValidationConfig) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunction.
-func (in *VirtualFunction) DeepCopy() *VirtualFunction {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VlanValidationConfig.
+func (in *VlanValidationConfig) DeepCopy() *VlanValidationConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualFunction)
+	out := new(VlanValidationConfig)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,239 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// v1Fields are the v1 values of the fields v2 can't represent, kept in the V1FieldsAnnotation of the v2 policies
+type v1Fields struct {
+	LinkType  string   `json:"linkType,omitempty"`
+	NetFilter string   `json:"netFilter,omitempty"`
+	PfNames   []string `json:"pfNames,omitempty"`
+}
+
+// pfSelectors converts the v1 pfNames to the v2 PF selectors, the pfNames with an invalid VF range are skipped
+func pfSelectors(pfNames []string) (pfs []PfSelector, unsupported bool) {
+	for _, pfName := range pfNames {
+		name, rngStart, rngEnd, err := sriovnetworkv1.ParsePFName(pfName)
+		if err != nil {
+			unsupported = true
+			continue
+		}
+		pf := PfSelector{Name: name}
+		if strings.Contains(pfName, "#") {
+			pf.VfRange = &VfRange{Start: rngStart, End: rngEnd}
+		}
+		pfs = append(pfs, pf)
+	}
+	return pfs, unsupported
+}
+
+// ConvertTo converts this SriovNetworkNodePolicy to the hub (v1) version
+func (src *SriovNetworkNodePolicy) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*sriovnetworkv1.SriovNetworkNodePolicy)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	kept := v1Fields{}
+	if data, ok := dst.Annotations[consts.V1FieldsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &kept); err != nil {
+			return fmt.Errorf("invalid %s annotation: %v", consts.V1FieldsAnnotation, err)
+		}
+		delete(dst.Annotations, consts.V1FieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec = sriovnetworkv1.SriovNetworkNodePolicySpec{
+		ResourceName:      src.Spec.ResourceName,
+		NodeSelector:      src.Spec.NodeSelector,
+		Priority:          src.Spec.Priority,
+		Mtu:               src.Spec.Mtu,
+		NumVfs:            src.Spec.NumVfs,
+		DeviceType:        src.Spec.DeviceType,
+		IsRdma:            src.Spec.IsRdma,
+		NeedVhostNet:      src.Spec.NeedVhostNet,
+		LinkType:          src.Spec.LinkType,
+		EswitchMode:       src.Spec.EswitchMode,
+		VdpaType:          src.Spec.VdpaType,
+		ExcludeTopology:   src.Spec.ExcludeTopology,
+		AllocationPolicy:  src.Spec.AllocationPolicy,
+		DrainMode:         src.Spec.DrainMode,
+		ExternallyManaged: src.Spec.ExternallyManaged,
+		NicSelector: sriovnetworkv1.SriovNetworkNicSelector{
+			Vendor:             src.Spec.NicSelector.Vendor,
+			DeviceID:           src.Spec.NicSelector.DeviceID,
+			RootDevices:        src.Spec.NicSelector.RootDevices,
+			LinkUp:             src.Spec.NicSelector.LinkUp,
+			TransceiverPresent: src.Spec.NicSelector.TransceiverPresent,
+			ZpciUids:           src.Spec.NicSelector.ZpciUids,
+		},
+	}
+
+	for _, pf := range src.Spec.NicSelector.Pfs {
+		name := pf.Name
+		if pf.VfRange != nil {
+			name = fmt.Sprintf("%s#%d-%d", pf.Name, pf.VfRange.Start, pf.VfRange.End)
+		}
+		dst.Spec.NicSelector.PfNames = append(dst.Spec.NicSelector.PfNames, name)
+	}
+	if src.Spec.NicSelector.NetFilter != nil && src.Spec.NicSelector.NetFilter.OpenstackNetworkID != "" {
+		dst.Spec.NicSelector.NetFilter = sriovnetworkv1.NewNetFilter(sriovnetworkv1.OpenstackNetworkID.String(),
+			src.Spec.NicSelector.NetFilter.OpenstackNetworkID)
+	}
+	// the kept v1 values are restored as long as the v2 fields they were converted to are unchanged
+	if kept.LinkType != "" && strings.EqualFold(kept.LinkType, src.Spec.LinkType) {
+		dst.Spec.LinkType = kept.LinkType
+	}
+	if kept.PfNames != nil {
+		if pfs, _ := pfSelectors(kept.PfNames); reflect.DeepEqual(pfs, src.Spec.NicSelector.Pfs) {
+			dst.Spec.NicSelector.PfNames = kept.PfNames
+		}
+	}
+	if kept.NetFilter != "" && dst.Spec.NicSelector.NetFilter == "" {
+		dst.Spec.NicSelector.NetFilter = kept.NetFilter
+	}
+	for _, rule := range src.Spec.FlowRules {
+		dst.Spec.FlowRules = append(dst.Spec.FlowRules, sriovnetworkv1.FlowRule{
+			Name:     rule.Name,
+			Vf:       rule.Vf,
+			Match:    sriovnetworkv1.FlowMatch(rule.Match),
+			Action:   rule.Action,
+			TargetVf: rule.TargetVf,
+		})
+	}
+	if src.Spec.Mirror != nil {
+		mirror := sriovnetworkv1.MirrorConfig(*src.Spec.Mirror)
+		dst.Spec.Mirror = &mirror
+	}
+	if src.Spec.Qos != nil {
+		qos := sriovnetworkv1.QosConfig(*src.Spec.Qos)
+		dst.Spec.Qos = &qos
+	}
+	if src.Spec.CongestionControl != nil {
+		cc := sriovnetworkv1.CongestionControlConfig(*src.Spec.CongestionControl)
+		dst.Spec.CongestionControl = &cc
+	}
+	dst.Spec.AllowPtpSourceDisruption = src.Spec.AllowPtpSourceDisruption
+	dst.Spec.HwTimestamping = src.Spec.HwTimestamping
+	dst.Spec.MsixCount = src.Spec.MsixCount
+	dst.Spec.RequireIsolatedCpus = src.Spec.RequireIsolatedCpus
+	dst.Spec.DisableIdleD3 = src.Spec.DisableIdleD3
+	dst.Spec.SystemReserved = src.Spec.SystemReserved
+	dst.Spec.DisableDriversAutoprobe = src.Spec.DisableDriversAutoprobe
+	dst.Spec.PfNamePrefix = src.Spec.PfNamePrefix
+	dst.Spec.ExposePfs = src.Spec.ExposePfs
+	dst.Spec.PortSplit = src.Spec.PortSplit
+	for _, vf := range src.Spec.UnicastMacs {
+		dst.Spec.UnicastMacs = append(dst.Spec.UnicastMacs, sriovnetworkv1.VfUnicastMacs(vf))
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub (v1) version to this SriovNetworkNodePolicy
+func (dst *SriovNetworkNodePolicy) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*sriovnetworkv1.SriovNetworkNodePolicy)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	dst.Spec = SriovNetworkNodePolicySpec{
+		ResourceName:      src.Spec.ResourceName,
+		NodeSelector:      src.Spec.NodeSelector,
+		Priority:          src.Spec.Priority,
+		Mtu:               src.Spec.Mtu,
+		NumVfs:            src.Spec.NumVfs,
+		DeviceType:        src.Spec.DeviceType,
+		IsRdma:            src.Spec.IsRdma,
+		NeedVhostNet:      src.Spec.NeedVhostNet,
+		LinkType:          strings.ToLower(src.Spec.LinkType),
+		EswitchMode:       src.Spec.EswitchMode,
+		VdpaType:          src.Spec.VdpaType,
+		ExcludeTopology:   src.Spec.ExcludeTopology,
+		AllocationPolicy:  src.Spec.AllocationPolicy,
+		DrainMode:         src.Spec.DrainMode,
+		ExternallyManaged: src.Spec.ExternallyManaged,
+		NicSelector: NicSelector{
+			Vendor:             src.Spec.NicSelector.Vendor,
+			DeviceID:           src.Spec.NicSelector.DeviceID,
+			RootDevices:        src.Spec.NicSelector.RootDevices,
+			LinkUp:             src.Spec.NicSelector.LinkUp,
+			TransceiverPresent: src.Spec.NicSelector.TransceiverPresent,
+			ZpciUids:           src.Spec.NicSelector.ZpciUids,
+		},
+	}
+
+	// the v1 values v2 can't represent are kept in an annotation rather than failing the conversion of a policy
+	// v1 accepts
+	kept := v1Fields{}
+	if dst.Spec.LinkType != src.Spec.LinkType {
+		kept.LinkType = src.Spec.LinkType
+	}
+	pfs, unsupported := pfSelectors(src.Spec.NicSelector.PfNames)
+	dst.Spec.NicSelector.Pfs = pfs
+	if unsupported {
+		kept.PfNames = src.Spec.NicSelector.PfNames
+	}
+	if src.Spec.NicSelector.NetFilter != "" {
+		// the v2 NetFilter only has a field for the OpenStack networks
+		prefix, value, err := sriovnetworkv1.ParseNetFilter(src.Spec.NicSelector.NetFilter)
+		if err != nil || prefix != sriovnetworkv1.OpenstackNetworkID.String() {
+			kept.NetFilter = src.Spec.NicSelector.NetFilter
+		} else {
+			dst.Spec.NicSelector.NetFilter = &NetFilter{OpenstackNetworkID: value}
+		}
+	}
+	if !reflect.DeepEqual(kept, v1Fields{}) {
+		data, err := json.Marshal(kept)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[consts.V1FieldsAnnotation] = string(data)
+	} else {
+		delete(dst.Annotations, consts.V1FieldsAnnotation)
+	}
+	for _, rule := range src.Spec.FlowRules {
+		dst.Spec.FlowRules = append(dst.Spec.FlowRules, FlowRule{
+			Name:     rule.Name,
+			Vf:       rule.Vf,
+			Match:    FlowMatch(rule.Match),
+			Action:   rule.Action,
+			TargetVf: rule.TargetVf,
+		})
+	}
+	if src.Spec.Mirror != nil {
+		mirror := MirrorConfig(*src.Spec.Mirror)
+		dst.Spec.Mirror = &mirror
+	}
+	if src.Spec.Qos != nil {
+		qos := QosConfig(*src.Spec.Qos)
+		dst.Spec.Qos = &qos
+	}
+	if src.Spec.CongestionControl != nil {
+		cc := CongestionControlConfig(*src.Spec.CongestionControl)
+		dst.Spec.CongestionControl = &cc
+	}
+	dst.Spec.AllowPtpSourceDisruption = src.Spec.AllowPtpSourceDisruption
+	dst.Spec.HwTimestamping = src.Spec.HwTimestamping
+	dst.Spec.MsixCount = src.Spec.MsixCount
+	dst.Spec.RequireIsolatedCpus = src.Spec.RequireIsolatedCpus
+	dst.Spec.DisableIdleD3 = src.Spec.DisableIdleD3
+	dst.Spec.SystemReserved = src.Spec.SystemReserved
+	dst.Spec.DisableDriversAutoprobe = src.Spec.DisableDriversAutoprobe
+	dst.Spec.PfNamePrefix = src.Spec.PfNamePrefix
+	dst.Spec.ExposePfs = src.Spec.ExposePfs
+	dst.Spec.PortSplit = src.Spec.PortSplit
+	for _, vf := range src.Spec.UnicastMacs {
+		dst.Spec.UnicastMacs = append(dst.Spec.UnicastMacs, VfUnicastMacs(vf))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package v2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func TestConvertFromV1(t *testing.T) {
+	g := NewGomegaWithT(t)
+	vf, cnpDscp := 1, 48
+
+	v1Policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-1", Namespace: "sriov-network-operator"},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "intelnics",
+			NodeSelector: map[string]string{"feature.node.kubernetes.io/network-sriov.capable": "true"},
+			NumVfs:       8,
+			LinkType:     "ETH",
+			NicSelector: sriovnetworkv1.SriovNetworkNicSelector{
+				Vendor:    "8086",
+				PfNames:   []string{"ens1f0#0-3", "ens1f1"},
+				NetFilter: "openstack/NetworkID:ada9ec67-2c97-467c-b674-c47200e2f5da",
+				ZpciUids:  []string{"0x1f"},
+			},
+			FlowRules: []sriovnetworkv1.FlowRule{
+				{Name: "trap-lacp", Match: sriovnetworkv1.FlowMatch{EthType: "0x8809"}, Action: "trap"},
+				{Name: "mirror", Match: sriovnetworkv1.FlowMatch{VlanID: 100}, Action: "mirror", TargetVf: &vf},
+			},
+			Mirror: &sriovnetworkv1.MirrorConfig{SourcePf: true, SourceVfs: []int{2, 3}},
+			Qos:    &sriovnetworkv1.QosConfig{Trust: "dscp", PfcPriorities: []int{3}, BufferSizes: []int{130944}},
+			CongestionControl: &sriovnetworkv1.CongestionControlConfig{
+				EcnPriorities: []int{3}, CnpDscp: &cnpDscp, MinRate: 1,
+			},
+			AllowPtpSourceDisruption: true,
+			HwTimestamping:           true,
+			MsixCount:                16,
+			RequireIsolatedCpus:      true,
+			DisableIdleD3:            true,
+			SystemReserved:           true,
+			UnicastMacs:              []sriovnetworkv1.VfUnicastMacs{{Vf: 1, Macs: []string{"02:00:00:00:00:01"}}},
+		},
+	}
+
+	v2Policy := &SriovNetworkNodePolicy{}
+	g.Expect(v2Policy.ConvertFrom(v1Policy)).To(Succeed())
+	g.Expect(v2Policy.Name).To(Equal("policy-1"))
+	g.Expect(v2Policy.Spec.LinkType).To(Equal("eth"))
+	g.Expect(v2Policy.Annotations).To(HaveKeyWithValue(consts.V1FieldsAnnotation, `{"linkType":"ETH"}`))
+	g.Expect(v2Policy.Spec.NicSelector.Pfs).To(Equal([]PfSelector{
+		{Name: "ens1f0", VfRange: &VfRange{Start: 0, End: 3}},
+		{Name: "ens1f1"},
+	}))
+	g.Expect(v2Policy.Spec.NicSelector.NetFilter).To(Equal(&NetFilter{OpenstackNetworkID: "ada9ec67-2c97-467c-b674-c47200e2f5da"}))
+
+	// back to v1
+	converted := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	g.Expect(v2Policy.ConvertTo(converted)).To(Succeed())
+	g.Expect(converted.Spec.NicSelector).To(Equal(v1Policy.Spec.NicSelector))
+	g.Expect(converted.Spec.NumVfs).To(Equal(8))
+	g.Expect(converted.Spec.LinkType).To(Equal("ETH"))
+	g.Expect(converted.Annotations).To(BeNil())
+	g.Expect(converted.Spec.FlowRules).To(Equal(v1Policy.Spec.FlowRules))
+	g.Expect(converted.Spec.Mirror).To(Equal(v1Policy.Spec.Mirror))
+	g.Expect(converted.Spec.Qos).To(Equal(v1Policy.Spec.Qos))
+	g.Expect(converted.Spec.CongestionControl).To(Equal(v1Policy.Spec.CongestionControl))
+	g.Expect(converted.Spec.AllowPtpSourceDisruption).To(BeTrue())
+	g.Expect(converted.Spec.HwTimestamping).To(BeTrue())
+	g.Expect(converted.Spec.MsixCount).To(Equal(16))
+	g.Expect(converted.Spec.RequireIsolatedCpus).To(BeTrue())
+	g.Expect(converted.Spec.DisableIdleD3).To(BeTrue())
+	g.Expect(converted.Spec.SystemReserved).To(BeTrue())
+	g.Expect(converted.Spec.UnicastMacs).To(Equal(v1Policy.Spec.UnicastMacs))
+}
+
+func TestConvertFromV1UnsupportedFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	v1Policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"owner": "team-a"}},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			NicSelector: sriovnetworkv1.SriovNetworkNicSelector{
+				PfNames:   []string{"ens1f0#a-b", "ens1f1"},
+				NetFilter: "unknown:value",
+			},
+		},
+	}
+	v2Policy := &SriovNetworkNodePolicy{}
+	g.Expect(v2Policy.ConvertFrom(v1Policy)).To(Succeed())
+	g.Expect(v2Policy.Spec.NicSelector.Pfs).To(Equal([]PfSelector{{Name: "ens1f1"}}))
+	g.Expect(v2Policy.Spec.NicSelector.NetFilter).To(BeNil())
+	g.Expect(v2Policy.Annotations).To(HaveKeyWithValue(consts.V1FieldsAnnotation,
+		`{"netFilter":"unknown:value","pfNames":["ens1f0#a-b","ens1f1"]}`))
+	// the annotations of the v1 object are not modified
+	g.Expect(v1Policy.Annotations).To(Equal(map[string]string{"owner": "team-a"}))
+
+	converted := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	g.Expect(v2Policy.ConvertTo(converted)).To(Succeed())
+	g.Expect(converted.Spec.NicSelector).To(Equal(v1Policy.Spec.NicSelector))
+	g.Expect(converted.Annotations).To(Equal(map[string]string{"owner": "team-a"}))
+
+	// the v2 fields changed by the clients replace the kept values
+	v2Policy.Spec.NicSelector.Pfs = []PfSelector{{Name: "ens2f0"}}
+	v2Policy.Spec.NicSelector.NetFilter = &NetFilter{OpenstackNetworkID: "ada9ec67-2c97-467c-b674-c47200e2f5da"}
+	g.Expect(v2Policy.ConvertTo(converted)).To(Succeed())
+	g.Expect(converted.Spec.NicSelector.PfNames).To(Equal([]string{"ens2f0"}))
+	g.Expect(converted.Spec.NicSelector.NetFilter).To(Equal("openstack/NetworkID:ada9ec67-2c97-467c-b674-c47200e2f5da"))
+
+	v2Policy.Annotations[consts.V1FieldsAnnotation] = "{"
+	g.Expect(v2Policy.ConvertTo(converted)).ToNot(Succeed())
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SriovNetworkNodePolicySpec defines the desired state of SriovNetworkNodePolicy
+type SriovNetworkNodePolicySpec struct {
+	// SRIOV Network device plugin endpoint resource name
+	ResourceName string `json:"resourceName"`
+	// NodeSelector selects the nodes to be configured
+	NodeSelector map[string]string `json:"nodeSelector"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=99
+	// Priority of the policy, higher priority policies can override lower ones.
+	Priority int `json:"priority,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// MTU of VF
+	Mtu int `json:"mtu,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// Number of VFs for each PF
+	NumVfs int `json:"numVfs"`
+	// NicSelector selects the NICs to be configured
+	NicSelector NicSelector `json:"nicSelector"`
+	// +kubebuilder:validation:Enum=netdevice;vfio-pci
+	// The driver type for configured VFs. Allowed value "netdevice", "vfio-pci". Defaults to netdevice.
+	DeviceType string `json:"deviceType,omitempty"`
+	// RDMA mode. Defaults to false.
+	IsRdma bool `json:"isRdma,omitempty"`
+	// mount vhost-net device. Defaults to false.
+	NeedVhostNet bool `json:"needVhostNet,omitempty"`
+	// +kubebuilder:validation:Enum=eth;ib
+	// NIC Link Type.
+	LinkType string `json:"linkType,omitempty"`
+	// +kubebuilder:validation:Enum=legacy;switchdev
+	// NIC Device Mode. Allowed value "legacy","switchdev".
+	EswitchMode string `json:"eSwitchMode,omitempty"`
+	// +kubebuilder:validation:Enum=virtio;vhost
+	// VDPA device type. Allowed value "virtio", "vhost"
+	VdpaType string `json:"vdpaType,omitempty"`
+	// Exclude device's NUMA node when advertising this resource by SRIOV network device plugin. Default to false.
+	ExcludeTopology bool `json:"excludeTopology,omitempty"`
+	// +kubebuilder:validation:Enum=spread;pack
+	// VF allocation policy of the resource when its VFs belong to several PFs, passed to the SR-IOV network device plugin.
+	// "pack" allocates the VFs of a pod from as few PFs as possible. "spread" is rejected, the device plugin doesn't
+	// implement it. Defaults to the device plugin ordering.
+	AllocationPolicy string `json:"allocationPolicy,omitempty"`
+	// +kubebuilder:validation:Enum=Drain;Cordon;None
+	// DrainMode overrides the drain of the nodes when the configuration of the PFs selected by the policy changes.
+	// "Drain" evicts the workloads, "Cordon" only stops scheduling on the node and "None" leaves the node untouched.
+	// Defaults to the drain mode of the pool of the node, then to the disableDrain of the SriovOperatorConfig.
+	DrainMode string `json:"drainMode,omitempty"`
+	// don't create the virtual function only allocated them to the device plugin. Defaults to false.
+	ExternallyManaged bool `json:"externallyManaged,omitempty"`
+	// tc flower rules installed on the ingress of the selected PFs or of the representors of their VFs,
+	// they require the switchdev eSwitchMode.
+	FlowRules []FlowRule `json:"flowRules,omitempty"`
+	// Mirror makes the first VF of the policy the destination of the traffic mirrored from the PF or from other
+	// VFs, it requires the switchdev eSwitchMode.
+	Mirror *MirrorConfig `json:"mirror,omitempty"`
+	// Qos configures the trust mode, the priority flow control and the receive buffers of the PFs for lossless
+	// RoCE, it requires isRdma.
+	Qos *QosConfig `json:"qos,omitempty"`
+	// CongestionControl configures the ECN and the DCQCN congestion control of the PFs for RoCEv2, it requires
+	// isRdma.
+	CongestionControl *CongestionControlConfig `json:"congestionControl,omitempty"`
+	// Allow the disruptive changes, e.g. of numVfs, eSwitchMode or linkType, on a PF that is the PTP time
+	// source of the node. Defaults to false.
+	AllowPtpSourceDisruption bool `json:"allowPtpSourceDisruption,omitempty"`
+	// Enable the hardware timestamping of the packets sent and received by the VFs where the driver supports it,
+	// so the workloads can read the hardware timestamps without the NET_ADMIN capability. It requires the
+	// netdevice deviceType. Defaults to false.
+	HwTimestamping bool `json:"hwTimestamping,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Number of MSI-X vectors of each VF, e.g. more vectors to the DPDK VFs and fewer to the control VFs, on the
+	// PFs whose driver allows to distribute the MSI-X vectors among the VFs.
+	MsixCount int `json:"msixCount,omitempty"`
+	// Warn when the selected nodes have no isolated CPUs, for the DPDK workloads of the vfio-pci VFs. Defaults
+	// to false.
+	RequireIsolatedCpus bool `json:"requireIsolatedCpus,omitempty"`
+	// Keep the vfio-pci VFs out of the D3 low power state while they are unused, through the disable_idle_d3
+	// option of the vfio_pci module, for the devices that fail to resume from it. It requires the vfio-pci
+	// deviceType and reloads the vfio_pci module of the nodes where it is loaded. Defaults to false.
+	DisableIdleD3 bool `json:"disableIdleD3,omitempty"`
+	// Reserve the VFs of the policy for the system components of the nodes, e.g. the OVN encapsulation of the host
+	// over a VF: the VFs are configured but not advertised by the device plugin, and are listed with the
+	// resourceName of the policy in the reserved VFs file of the nodes. Defaults to false.
+	SystemReserved bool `json:"systemReserved,omitempty"`
+	// Disable the probing of the VFs by the kernel drivers when the VFs of the selected PFs are created, through the
+	// sriov_drivers_autoprobe file of the PFs: the VFs are only bound to the vfio-pci driver, they never create
+	// kernel netdevs. It requires the vfio-pci deviceType. Defaults to false.
+	DisableDriversAutoprobe bool `json:"disableDriversAutoprobe,omitempty"`
+	// Rename the PFs selected by the policy on each node to the prefix followed by their PCI bus, device and
+	// function, e.g. sriov3b00f0 for 0000:3b:00.0, through a udev rule. The pfNames of the nicSelectors keep
+	// matching the PFs by their original names.
+	// +kubebuilder:validation:Pattern=`^[a-z]([a-z0-9_]{0,5}[a-z_])?$`
+	PfNamePrefix string `json:"pfNamePrefix,omitempty"`
+	// Advertise the selected PFs themselves as the devices of the resource instead of creating VFs, e.g. as the
+	// parent interfaces of macvlan or ipvlan networks or to be moved into the pods by the host-device CNI. The PFs
+	// are left unconfigured by the policy, it requires numVfs 0 and the netdevice deviceType. Defaults to false.
+	ExposePfs bool `json:"exposePfs,omitempty"`
+	// Split the physical port of the selected PFs into the given number of ports through devlink port split, e.g. a
+	// 100G port into 4 25G ports over a breakout cable. The port is split before the VFs of the PF are created, the
+	// split ports are discovered as PFs the other policies can select. 1 unsplits the port, it is left as it is when
+	// not set.
+	// +kubebuilder:validation:Enum=1;2;4;8
+	PortSplit int `json:"portSplit,omitempty"`
+	// Additional unicast MACs the VFs of the policy accept besides their own MAC, e.g. the floating MAC of a bonded
+	// pod interface or of a migrating workload. The config daemon adds them to the unicast filter of the netdevs of
+	// the VFs, the drivers of some NICs only accept them on trusted VFs. It requires the netdevice deviceType.
+	UnicastMacs []VfUnicastMacs `json:"unicastMacs,omitempty"`
+}
+
+// NicSelector selects the PFs configured by the policy
+type NicSelector struct {
+	// The vendor hex code of SR-IoV device.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{4}$`
+	Vendor string `json:"vendor,omitempty"`
+	// The device hex code of SR-IoV device.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{4}$`
+	DeviceID string `json:"deviceID,omitempty"`
+	// PCI address of SR-IoV PF.
+	RootDevices []string `json:"rootDevices,omitempty"`
+	// PFs selected by name, optionally restricted to a range of their VFs.
+	Pfs []PfSelector `json:"pfs,omitempty"`
+	// Infrastructure Networking selection filter.
+	NetFilter *NetFilter `json:"netFilter,omitempty"`
+	// Select only PFs that report carrier (link up).
+	LinkUp bool `json:"linkUp,omitempty"`
+	// Select only PFs with a pluggable transceiver module or cable detected.
+	TransceiverPresent bool `json:"transceiverPresent,omitempty"`
+	// UIDs of the zPCI functions of the RoCE Express adapters on IBM Z, e.g. "0x1f".
+	ZpciUids []string `json:"zpciUids,omitempty"`
+}
+
+// PfSelector selects a PF by name
+type PfSelector struct {
+	// Name of SR-IoV PF.
+	Name string `json:"name"`
+	// VfRange restricts the policy to a group of VFs of the PF, all the VFs are selected when not set.
+	VfRange *VfRange `json:"vfRange,omitempty"`
+}
+
+// VfRange is an inclusive range of VF indexes
+type VfRange struct {
+	// +kubebuilder:validation:Minimum=0
+	Start int `json:"start"`
+	// +kubebuilder:validation:Minimum=0
+	End int `json:"end"`
+}
+
+// NetFilter selects the VFs attached to an infrastructure network
+type NetFilter struct {
+	// ID of the OpenStack network the VFs are attached to
+	OpenstackNetworkID string `json:"openstackNetworkID,omitempty"`
+}
+
+// FlowRule is a tc flower rule installed on the ingress of a PF or of the representor of one of its VFs
+type FlowRule struct {
+	// Name of the rule, unique among the rules of the policy.
+	Name string `json:"name"`
+	// +kubebuilder:validation:Minimum=0
+	// Index of the VF the rule is installed on the representor of. The rule is installed on the PF if unset.
+	Vf *int `json:"vf,omitempty"`
+	// Match selects the packets the action is applied to, an empty match selects all the packets.
+	Match FlowMatch `json:"match,omitempty"`
+	// +kubebuilder:validation:Enum=trap;drop;mirror;redirect
+	// Action applied to the matched packets. Allowed value "trap", "drop", "mirror", "redirect".
+	Action string `json:"action"`
+	// +kubebuilder:validation:Minimum=0
+	// Index of the VF whose representor the packets are mirrored or redirected to.
+	TargetVf *int `json:"targetVf,omitempty"`
+}
+
+// MirrorConfig selects the sources of the traffic mirrored to the VF of a policy
+type MirrorConfig struct {
+	// Mirror the traffic received by the PF from the wire.
+	SourcePf bool `json:"sourcePf,omitempty"`
+	// Indexes of the VFs whose sent traffic is mirrored.
+	SourceVfs []int `json:"sourceVfs,omitempty"`
+}
+
+// VfUnicastMacs are the additional unicast MACs of a VF
+type VfUnicastMacs struct {
+	// +kubebuilder:validation:Minimum=0
+	// Index of the VF on its PF, in the VF range of the policy.
+	Vf int `json:"vf"`
+	// +kubebuilder:validation:MinItems=1
+	// Unicast MACs accepted by the VF, e.g. "02:00:5e:00:01:01".
+	Macs []string `json:"macs"`
+}
+
+// QosConfig holds the lossless RoCE QoS settings of a PF
+type QosConfig struct {
+	// +kubebuilder:validation:Enum=pcp;dscp
+	// Trust mode of the PF, the priority of the received packets is taken from their VLAN PCP or from their
+	// DSCP. Allowed value "pcp", "dscp".
+	Trust string `json:"trust,omitempty"`
+	// +kubebuilder:validation:MaxItems=8
+	// Priorities with priority flow control enabled, from 0 to 7.
+	PfcPriorities []int `json:"pfcPriorities,omitempty"`
+	// +kubebuilder:validation:MaxItems=8
+	// Receive buffer of each priority, indexed by priority.
+	PrioToBuffer []int `json:"prioToBuffer,omitempty"`
+	// +kubebuilder:validation:MaxItems=8
+	// Size in bytes of each receive buffer, indexed by buffer.
+	BufferSizes []int `json:"bufferSizes,omitempty"`
+}
+
+// CongestionControlConfig holds the ECN and DCQCN settings of a PF, the parameters left unset keep their
+// current value
+type CongestionControlConfig struct {
+	// +kubebuilder:validation:MaxItems=8
+	// Priorities with ECN marking and the DCQCN reaction enabled, from 0 to 7. It is disabled on the others.
+	EcnPriorities []int `json:"ecnPriorities,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// DSCP of the congestion notification packets sent by the notification point.
+	CnpDscp *int `json:"cnpDscp,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=7
+	// Priority of the congestion notification packets sent by the notification point.
+	CnpPriority *int `json:"cnpPriority,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Minimum rate in Mbps the reaction point reduces a flow to.
+	MinRate int `json:"minRate,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Rate in Mbps added in the additive increase stage.
+	AiRate int `json:"aiRate,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Rate in Mbps added in the hyper increase stage.
+	HaiRate int `json:"haiRate,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Time in microseconds between two rate increases.
+	TimeReset int `json:"timeReset,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Bytes sent between two rate increases.
+	ByteReset int `json:"byteReset,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Minimum time in microseconds between two rate reductions.
+	RateReduceMonitorPeriod int `json:"rateReduceMonitorPeriod,omitempty"`
+}
+
+// FlowMatch holds the flower keys of a rule
+type FlowMatch struct {
+	// +kubebuilder:validation:Pattern=`^(ip|ipv6|arp|0x[0-9a-fA-F]{4})$`
+	// EtherType of the packets, "ip", "ipv6", "arp" or a hex value, e.g. "0x8809" for LACP.
+	EthType string `json:"ethType,omitempty"`
+	// Source MAC address.
+	SrcMac string `json:"srcMac,omitempty"`
+	// Destination MAC address, e.g. "01:80:c2:00:00:02" for LACP.
+	DstMac string `json:"dstMac,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4095
+	// VLAN ID of the 802.1Q tagged packets.
+	VlanID int `json:"vlanId,omitempty"`
+	// +kubebuilder:validation:Enum=tcp;udp;sctp;icmp;icmpv6
+	// IP protocol, requires the "ip" or "ipv6" ethType.
+	IPProto string `json:"ipProto,omitempty"`
+	// Source IP address or CIDR, requires the "ip" or "ipv6" ethType.
+	SrcIP string `json:"srcIp,omitempty"`
+	// Destination IP address or CIDR, requires the "ip" or "ipv6" ethType.
+	DstIP string `json:"dstIp,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	// Destination port, requires the "tcp", "udp" or "sctp" ipProto.
+	DstPort int `json:"dstPort,omitempty"`
+}
+
+// SriovNetworkNodePolicyStatus defines the observed state of SriovNetworkNodePolicy
+type SriovNetworkNodePolicyStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+
+// SriovNetworkNodePolicy is the Schema for the sriovnetworknodepolicies API.
+// The version is served by the operator when the operator webhook converting it to v1 is enabled.
+type SriovNetworkNodePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SriovNetworkNodePolicySpec   `json:"spec,omitempty"`
+	Status SriovNetworkNodePolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SriovNetworkNodePolicyList contains a list of SriovNetworkNodePolicy
+type SriovNetworkNodePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovNetworkNodePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovNetworkNodePolicy{}, &SriovNetworkNodePolicyList{})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/quirks"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/systemd"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// failSafeRetryInterval is the interval the API server is polled at in the fail-safe mode
+const failSafeRetryInterval = 10 * time.Second
+
+// apiUnreachable returns true if the error means the API server can't be reached, not that it refused the request
+func apiUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || apierrors.IsServiceUnavailable(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// runFailSafe applies the node spec last applied by the daemon, so the VFs of the workloads are recovered during the
+// outages of the control plane, and waits for the API server to return the node.
+// In systemd mode the sriov-config service already applied the spec at boot, the daemon only waits.
+func runFailSafe(setupLog logr.Logger, kubeclient kubernetes.Interface,
+	hostHelpers helper.HostHelpersInterface, interval time.Duration) (*corev1.Node, error) {
+	setupLog.Info("the API server is unreachable, running in fail-safe mode")
+	if vars.UsingSystemdMode {
+		setupLog.Info("the sriov-config service applied the last spec at boot")
+	} else if err := applyLastAppliedSpec(setupLog, hostHelpers); err != nil {
+		// the daemon still waits for the API server to reconcile the node
+		setupLog.Error(err, "failed to apply the last applied spec")
+	}
+
+	for {
+		time.Sleep(interval)
+		node, err := kubeclient.CoreV1().Nodes().Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+		if err == nil {
+			setupLog.Info("the API server is reachable, leaving the fail-safe mode")
+			// the supported NICs are read again from their config map
+			sriovnetworkv1.NicIDMap = []string{}
+			return node, nil
+		}
+		if !apiUnreachable(err) {
+			return nil, err
+		}
+		setupLog.V(2).Info("the API server is still unreachable", "error", err)
+	}
+}
+
+// applyLastAppliedSpec applies the spec persisted on the host by the daemon with the supported NICs and the platform
+// it was applied with, the config map of the supported NICs can't be read without the API server
+func applyLastAppliedSpec(setupLog logr.Logger, hostHelpers helper.HostHelpersInterface) error {
+	lastApplied, err := systemd.ReadLastAppliedSpec()
+	if err != nil {
+		if os.IsNotExist(err) {
+			setupLog.Info("no spec was applied on the node yet, nothing to apply")
+			return nil
+		}
+		return fmt.Errorf("failed to read the last applied spec: %v", err)
+	}
+	vars.DevMode = lastApplied.UnsupportedNics
+	vars.PlatformType = lastApplied.PlatformType
+	quirks.Set(lastApplied.DriverQuirks)
+	sriovnetworkv1.InitNicIDMapFromList(lastApplied.SupportedNicIds)
+
+	setupLog.Info("applying the last applied spec", "platform", lastApplied.PlatformType.String())
+	return callPlugin(setupLog, PhasePre, &lastApplied.SriovConfig, hostHelpers)
+}
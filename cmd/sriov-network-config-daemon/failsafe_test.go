@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	helperMock "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	pluginsMock "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	testHelpers "github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("Fail-safe mode", func() {
+	var (
+		hostHelpers   *helperMock.MockHostHelpersInterface
+		genericPlugin *pluginsMock.MockVendorPlugin
+		kubeclient    *fake.Clientset
+		unreachable   int
+	)
+
+	BeforeEach(func() {
+		restoreOrigFuncs()
+		origNodeName, origSystemdMode, origPlatform, origDevMode := vars.NodeName, vars.UsingSystemdMode, vars.PlatformType, vars.DevMode
+		DeferCleanup(func() {
+			vars.NodeName, vars.UsingSystemdMode, vars.PlatformType, vars.DevMode = origNodeName, origSystemdMode, origPlatform, origDevMode
+			sriovnetworkv1.NicIDMap = []string{}
+		})
+		vars.NodeName = "worker-0"
+		vars.UsingSystemdMode = false
+
+		testCtrl := gomock.NewController(GinkgoT())
+		hostHelpers = helperMock.NewMockHostHelpersInterface(testCtrl)
+		genericPlugin = pluginsMock.NewMockVendorPlugin(testCtrl)
+		newGenericPluginFunc = func(_ helper.HostHelpersInterface) (plugin.VendorPlugin, error) {
+			return genericPlugin, nil
+		}
+
+		kubeclient = fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}})
+		unreachable = 2
+		kubeclient.PrependReactor("get", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+			if unreachable == 0 {
+				return false, nil, nil
+			}
+			unreachable--
+			return true, nil, apierrors.NewServiceUnavailable("unreachable")
+		})
+	})
+
+	It("detects the unreachable API server", func() {
+		Expect(apiUnreachable(apierrors.NewServiceUnavailable("unreachable"))).To(BeTrue())
+		Expect(apiUnreachable(fmt.Errorf("failed to get the node: %w", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}))).To(BeTrue())
+		Expect(apiUnreachable(apierrors.NewNotFound(corev1.Resource("nodes"), "worker-0"))).To(BeFalse())
+		Expect(apiUnreachable(nil)).To(BeFalse())
+	})
+
+	It("applies the last applied spec until the API server is reachable", func() {
+		testHelpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/host/etc/sriov-operator"},
+			Files: map[string][]byte{
+				"/host/etc/sriov-operator/sriov-last-applied-spec.yaml": append(getTestSriovInterfaceConfig(0),
+					[]byte("supportedNicIds:\n    - 8086 1583 154c\n")...),
+			},
+		})
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return([]sriovnetworkv1.InterfaceExt{{
+			Name: "enp216s0f0np0",
+		}}, nil)
+		genericPlugin.EXPECT().OnNodeStateChange(newNodeStateContainsDeviceMatcher("enp216s0f0np0")).
+			DoAndReturn(func(*sriovnetworkv1.SriovNetworkNodeState) (bool, bool, error) {
+				Expect(sriovnetworkv1.NicIDMap).To(Equal([]string{"8086 1583 154c"}))
+				Expect(vars.PlatformType).To(Equal(consts.Baremetal))
+				return true, false, nil
+			})
+		genericPlugin.EXPECT().Apply().Return(nil)
+
+		node, err := runFailSafe(log.Log, kubeclient, hostHelpers, time.Millisecond)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Name).To(Equal("worker-0"))
+		Expect(unreachable).To(BeZero())
+		Expect(sriovnetworkv1.NicIDMap).To(BeEmpty())
+	})
+
+	It("only waits for the API server without persisted spec", func() {
+		testHelpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc/sriov-operator"}})
+
+		node, err := runFailSafe(log.Log, kubeclient, hostHelpers, time.Millisecond)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Name).To(Equal("worker-0"))
+	})
+
+	It("only waits for the API server in systemd mode", func() {
+		vars.UsingSystemdMode = true
+
+		node, err := runFailSafe(log.Log, kubeclient, hostHelpers, time.Millisecond)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Name).To(Equal("worker-0"))
+	})
+
+	It("fails on the errors of the reachable API server", func() {
+		testHelpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc/sriov-operator"}})
+		kubeclient.PrependReactor("get", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("nodes"), "worker-0", fmt.Errorf("forbidden"))
+		})
+
+		_, err := runFailSafe(log.Log, kubeclient, hostHelpers, time.Millisecond)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+})
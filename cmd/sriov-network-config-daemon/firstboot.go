@@ -0,0 +1,112 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/firstboot"
+	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+)
+
+const (
+	firstbootFormatIgnition = "ignition"
+	firstbootFormatBootc    = "bootc"
+)
+
+var (
+	firstbootCmd = &cobra.Command{
+		Use:   "firstboot-render",
+		Short: "Renders the initial SR-IOV configuration of the nodes of a profile for their first boot",
+		Long: "Renders the SR-IOV configuration the policies apply to the nodes of a profile as an Ignition config or as " +
+			"the files of a bootc image, configuring the VFs at the first boot of the nodes before the config daemon starts",
+		RunE: runFirstbootCmd,
+	}
+
+	firstbootOpts struct {
+		policies string
+		profile  string
+		format   string
+		output   string
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(firstbootCmd)
+	firstbootCmd.Flags().StringVar(&firstbootOpts.policies, "policies", "", "file of the SriovNetworkNodePolicies, e.g. the output of kubectl get sriovnetworknodepolicies -o yaml")
+	firstbootCmd.Flags().StringVar(&firstbootOpts.profile, "profile", "", "file of the node profile: the labels of the nodes and their SR-IOV interfaces")
+	firstbootCmd.Flags().StringVar(&firstbootOpts.format, "format", firstbootFormatIgnition, "output format, ignition or bootc")
+	firstbootCmd.Flags().StringVar(&firstbootOpts.output, "output", "", "file of the Ignition config, defaults to the standard output, or root directory of the bootc files")
+}
+
+func runFirstbootCmd(cmd *cobra.Command, args []string) error {
+	snolog.InitLog()
+
+	if firstbootOpts.policies == "" || firstbootOpts.profile == "" {
+		return fmt.Errorf("policies and profile are required")
+	}
+	if firstbootOpts.format != firstbootFormatIgnition && firstbootOpts.format != firstbootFormatBootc {
+		return fmt.Errorf("unsupported format %q, expected %s or %s", firstbootOpts.format, firstbootFormatIgnition, firstbootFormatBootc)
+	}
+	if firstbootOpts.format == firstbootFormatBootc && firstbootOpts.output == "" {
+		return fmt.Errorf("output is required for the bootc format")
+	}
+
+	f, err := os.Open(firstbootOpts.policies)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	policies, err := firstboot.LoadPolicies(f)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(firstbootOpts.profile)
+	if err != nil {
+		return err
+	}
+	profile := &firstboot.Profile{}
+	if err := yaml.Unmarshal(data, profile); err != nil {
+		return fmt.Errorf("failed to parse the node profile: %v", err)
+	}
+
+	cfg, err := firstboot.Render(policies, profile)
+	if err != nil {
+		return err
+	}
+	if firstbootOpts.format == firstbootFormatBootc {
+		return cfg.WriteTree(firstbootOpts.output)
+	}
+
+	out, err := cfg.Ignition()
+	if err != nil {
+		return err
+	}
+	if len(cfg.KernelArgs) > 0 {
+		fmt.Fprintf(os.Stderr, "the configuration requires the kernel arguments %s, add them to the installation of the nodes\n",
+			strings.Join(cfg.KernelArgs, " "))
+	}
+	if firstbootOpts.output == "" {
+		fmt.Println(string(out))
+		return nil
+	}
+	return os.WriteFile(firstbootOpts.output, append(out, '\n'), 0644)
+}
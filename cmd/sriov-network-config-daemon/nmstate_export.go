@@ -0,0 +1,94 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
+	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/nmstate"
+)
+
+var (
+	nmstateExportCmd = &cobra.Command{
+		Use:   "nmstate-export",
+		Short: "Exports the SR-IOV network state of a node in the nmstate schema",
+		Long: "Translates the status of the SriovNetworkNodeState of the node to the NetworkState schema of nmstate and " +
+			"prints it as YAML, for the network inventory and intent validation tools consuming that format",
+		RunE: runNmstateExportCmd,
+	}
+
+	nmstateExportOpts struct {
+		nodeName  string
+		namespace string
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(nmstateExportCmd)
+	nmstateExportCmd.Flags().StringVar(&nmstateExportOpts.nodeName, "node-name", "", "kubernetes node name to export, defaults to the NODE_NAME environment variable")
+	nmstateExportCmd.Flags().StringVar(&nmstateExportOpts.namespace, "namespace", "", "namespace of the SriovNetworkNodeStates, defaults to the NAMESPACE environment variable")
+}
+
+func runNmstateExportCmd(cmd *cobra.Command, args []string) error {
+	snolog.InitLog()
+
+	if nmstateExportOpts.nodeName == "" {
+		nmstateExportOpts.nodeName = os.Getenv("NODE_NAME")
+	}
+	if nmstateExportOpts.nodeName == "" {
+		return fmt.Errorf("node-name is required")
+	}
+	if nmstateExportOpts.namespace == "" {
+		nmstateExportOpts.namespace = os.Getenv("NAMESPACE")
+	}
+	if nmstateExportOpts.namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+
+	var config *rest.Config
+	var err error
+	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return err
+	}
+	snclient := snclientset.NewForConfigOrDie(config)
+	nodeState, err := snclient.SriovnetworkV1().SriovNetworkNodeStates(nmstateExportOpts.namespace).Get(
+		context.Background(), nmstateExportOpts.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the SriovNetworkNodeState of node %s: %v", nmstateExportOpts.nodeName, err)
+	}
+
+	out, err := yaml.Marshal(nmstate.Export(&nodeState.Status))
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
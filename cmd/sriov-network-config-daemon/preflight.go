@@ -0,0 +1,137 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/preflight"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// defaultPreflightKernelModules are the modules required to bind VFs to userspace drivers
+var defaultPreflightKernelModules = stringList{"vfio_pci"}
+
+var (
+	preflightCmd = &cobra.Command{
+		Use:   "preflight",
+		Short: "Validates the node before SR-IOV policies are applied to it",
+		Long: "Checks the BIOS SR-IOV enablement, the IOMMU, the NIC firmware versions and the kernel modules of the node " +
+			"without changing it, and stores the results in the sriovnetwork.openshift.io/preflight annotation of the node",
+		RunE: runPreflightCmd,
+	}
+
+	preflightOpts struct {
+		nodeName      string
+		minFirmware   stringList
+		kernelModules stringList
+		noAnnotate    bool
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+	preflightCmd.Flags().StringVar(&preflightOpts.nodeName, "node-name", "", "kubernetes node name to check, defaults to the NODE_NAME environment variable")
+	preflightCmd.Flags().Var(&preflightOpts.minFirmware, "min-firmware", "comma-separated list of driver=version minimum NIC firmware versions, e.g. ice=4.20,mlx5_core=16.35")
+	preflightCmd.Flags().Var(&preflightOpts.kernelModules, "kernel-modules", "comma-separated list of kernel modules that must be available (default vfio_pci)")
+	preflightCmd.Flags().BoolVar(&preflightOpts.noAnnotate, "no-annotate", false, "only print the results, don't annotate the node")
+}
+
+func runPreflightCmd(cmd *cobra.Command, args []string) error {
+	snolog.InitLog()
+	setupLog := log.Log.WithName("sriov-network-config-daemon")
+	vars.UsingSystemdMode = false
+
+	if preflightOpts.nodeName == "" {
+		preflightOpts.nodeName = os.Getenv("NODE_NAME")
+	}
+	if preflightOpts.nodeName == "" && !preflightOpts.noAnnotate {
+		return fmt.Errorf("node-name is required")
+	}
+
+	opts, err := preflightOptions(preflightOpts.minFirmware, preflightOpts.kernelModules)
+	if err != nil {
+		return err
+	}
+
+	hostHelpers, err := helper.NewDefaultHostHelpers()
+	if err != nil {
+		setupLog.Error(err, "failed to create hostHelpers")
+		return err
+	}
+
+	report, err := preflight.Run(hostHelpers, opts)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+
+	if !preflightOpts.noAnnotate {
+		var config *rest.Config
+		if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		} else {
+			config, err = rest.InClusterConfig()
+		}
+		if err != nil {
+			return err
+		}
+		kubeclient := kubernetes.NewForConfigOrDie(config)
+		if err := preflight.Annotate(context.Background(), kubeclient, preflightOpts.nodeName, report); err != nil {
+			return err
+		}
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("pre-flight checks failed: %s", strings.Join(report.Failed(), ", "))
+	}
+	return nil
+}
+
+// preflightOptions converts the command line flags to the options of the pre-flight checks
+func preflightOptions(minFirmware, kernelModules stringList) (preflight.Options, error) {
+	opts := preflight.Options{
+		MinFirmwareVersions: map[string]string{},
+		KernelModules:       kernelModules,
+	}
+	if len(opts.KernelModules) == 0 {
+		opts.KernelModules = defaultPreflightKernelModules
+	}
+	for _, elem := range minFirmware {
+		driver, version, found := strings.Cut(elem, "=")
+		if !found || driver == "" || version == "" {
+			return opts, fmt.Errorf("invalid minimum firmware version %q, expected driver=version", elem)
+		}
+		opts.MinFirmwareVersions[driver] = version
+	}
+	return opts, nil
+}
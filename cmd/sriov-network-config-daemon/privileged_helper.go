@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/privileged"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var (
+	privilegedHelperCmd = &cobra.Command{
+		Use:   "privileged-helper",
+		Short: "Runs the host commands of the confined SR-IOV Network Config Daemon",
+		Long: "Serves the host commands and the sysfs writes allowed by its policy on a unix socket shared with the " +
+			"config daemon running confined, see the confinedConfigDaemon feature gate",
+		RunE: runPrivilegedHelperCmd,
+	}
+
+	privilegedHelperOpts struct {
+		socket          string
+		installProfiles bool
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(privilegedHelperCmd)
+	privilegedHelperCmd.Flags().StringVar(&privilegedHelperOpts.socket, "socket", consts.PrivilegedHelperSocket, "unix socket the helper serves on")
+	privilegedHelperCmd.Flags().BoolVar(&privilegedHelperOpts.installProfiles, "install-profiles", false, "only install the seccomp profile and the SELinux policy of the confined daemon on the host, and exit")
+}
+
+func runPrivilegedHelperCmd(cmd *cobra.Command, args []string) error {
+	snolog.InitLog()
+	setupLog := log.Log.WithName("sriov-network-config-daemon")
+
+	if privilegedHelperOpts.installProfiles {
+		setupLog.Info("installing the confinement profiles of the config daemon")
+		return privileged.InstallProfiles(consts.Host, utils.New())
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	setupLog.Info("starting the privileged helper", "socket", privilegedHelperOpts.socket)
+	return privileged.NewServer().Serve(ctx, privilegedHelperOpts.socket)
+}
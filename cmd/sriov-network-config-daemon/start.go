@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -41,8 +42,14 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/daemon"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/watch"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/lldp"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/preflight"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/privileged"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -78,10 +85,19 @@ var (
 	}
 
 	startOpts struct {
-		kubeconfig      string
-		nodeName        string
-		systemd         bool
-		disabledPlugins stringList
+		kubeconfig          string
+		nodeName            string
+		systemd             bool
+		disabledPlugins     stringList
+		metricsAddr         string
+		vfConfigConcurrency int
+		strictOspMetadata   bool
+		strictOspPciLookup  bool
+		reportUnmanaged     bool
+		helperSocket        string
+		nmstate             bool
+		netns               string
+		failSafe            bool
 	}
 )
 
@@ -91,6 +107,15 @@ func init() {
 	startCmd.PersistentFlags().StringVar(&startOpts.nodeName, "node-name", "", "kubernetes node name daemon is managing")
 	startCmd.PersistentFlags().BoolVar(&startOpts.systemd, "use-systemd-service", false, "use config daemon in systemd mode")
 	startCmd.PersistentFlags().VarP(&startOpts.disabledPlugins, "disable-plugins", "", "comma-separated list of plugins to disable")
+	startCmd.PersistentFlags().StringVar(&startOpts.metricsAddr, "metrics-bind-address", "", "address the daemon metrics are served on, the metrics are not served if empty")
+	startCmd.PersistentFlags().IntVar(&startOpts.vfConfigConcurrency, "vf-config-concurrency", consts.DefaultVfConfigConcurrency, "maximum number of VFs of a PF configured concurrently")
+	startCmd.PersistentFlags().BoolVar(&startOpts.strictOspMetadata, "strict-openstack-metadata", false, "fail the discovery on a malformed entry of the OpenStack metadata instead of skipping it")
+	startCmd.PersistentFlags().BoolVar(&startOpts.strictOspPciLookup, "strict-openstack-pci-lookup", false, "fail the discovery when the PCI address of an OpenStack device can't be found from its MAC address instead of keeping the PCI address of the metadata")
+	startCmd.PersistentFlags().BoolVar(&startOpts.reportUnmanaged, "report-unmanaged-virtual-devices", false, "report the network devices of the virtual platforms missing from the metadata as unmanaged interfaces instead of skipping them")
+	startCmd.PersistentFlags().BoolVar(&startOpts.nmstate, "nmstate-integration", false, "leave the MTU of the PFs to kubernetes-nmstate and wait for it before configuring their VFs")
+	startCmd.PersistentFlags().StringVar(&startOpts.netns, "netns", "", "network namespace the network operations on the host are run in, the name of a namespace of the host or its path, e.g. for the representors of a DPU or for the integration tests")
+	startCmd.PersistentFlags().BoolVar(&startOpts.failSafe, "fail-safe", true, "apply the last applied spec of the node when the API server is unreachable at start and wait for it")
+	startCmd.PersistentFlags().StringVar(&startOpts.helperSocket, "privileged-helper-socket", "", "unix socket of the privileged helper running the host commands of the confined daemon, the daemon runs them itself if empty")
 }
 
 func runStartCmd(cmd *cobra.Command, args []string) error {
@@ -113,12 +138,32 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	}
 	vars.NodeName = startOpts.nodeName
 
+	if startOpts.vfConfigConcurrency < 1 {
+		return fmt.Errorf("vf-config-concurrency must be at least 1")
+	}
+	vars.VfConfigConcurrency = startOpts.vfConfigConcurrency
+	vars.StrictOpenstackMetadata = startOpts.strictOspMetadata
+	vars.StrictOpenstackPciLookup = startOpts.strictOspPciLookup
+
+	vars.ReportUnmanagedVirtualDevices = startOpts.reportUnmanaged
+	vars.NMStateIntegration = startOpts.nmstate
+
 	for _, p := range startOpts.disabledPlugins {
 		if _, ok := vars.DisableablePlugins[p]; !ok {
 			return fmt.Errorf("%s plugin cannot be disabled", p)
 		}
 	}
 
+	if startOpts.helperSocket != "" {
+		helperClient, err := privileged.Dial(startOpts.helperSocket)
+		if err != nil {
+			return err
+		}
+		defer helperClient.Close()
+		utils.SetHostExecutor(helperClient)
+		setupLog.Info("running confined, the host commands are run by the privileged helper", "socket", startOpts.helperSocket)
+	}
+
 	// This channel is used to ensure all spawned goroutines exit when we exit.
 	stopCh := make(chan struct{})
 	defer close(stopCh)
@@ -209,7 +254,13 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	snclient := snclientset.NewForConfigOrDie(config)
 	kubeclient := kubernetes.NewForConfigOrDie(config)
 
-	hostHelpers, err := helper.NewDefaultHostHelpers()
+	var hostHelpers helper.HostHelpersInterface
+	if startOpts.netns != "" {
+		setupLog.Info("running the network operations in the network namespace", "netns", startOpts.netns)
+		hostHelpers, err = helper.NewHostHelpersInNamespace(startOpts.netns)
+	} else {
+		hostHelpers, err = helper.NewDefaultHostHelpers()
+	}
 	if err != nil {
 		setupLog.Error(err, "failed to create hostHelpers")
 		return err
@@ -221,32 +272,67 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	hostWatcher := watch.New()
+	hostEvents := hostWatcher.Subscribe(1024)
+	go func() {
+		if err := hostWatcher.Run(stopCh); err != nil {
+			// the writer keeps polling the host periodically
+			setupLog.Error(err, "failed to watch host events")
+		}
+	}()
+
 	config.Timeout = 5 * time.Second
 	writerclient := snclientset.NewForConfigOrDie(config)
 
 	eventRecorder := daemon.NewEventRecorder(writerclient, kubeclient)
 	defer eventRecorder.Shutdown()
 
+	lldpListener := lldp.NewListener()
+	defer lldpListener.SetEnabled(false)
+
 	setupLog.V(0).Info("starting node writer")
 	nodeWriter := daemon.NewNodeStateStatusWriter(writerclient,
 		closeAllConns,
 		eventRecorder,
 		hostHelpers,
-		platformHelper)
+		platformHelper,
+		lldpListener)
 
 	nodeInfo, err := kubeclient.CoreV1().Nodes().Get(context.Background(), startOpts.nodeName, v1.GetOptions{})
-	if err == nil {
+	failSafe := false
+	if err != nil && startOpts.failSafe && apiUnreachable(err) {
+		setupLog.Error(err, "failed to reach the API server", "node-name", startOpts.nodeName)
+		failSafe = true
+		nodeInfo, err = runFailSafe(setupLog, kubeclient, hostHelpers, failSafeRetryInterval)
+	}
+	if err == nil && nodeInfo.Labels[consts.DaemonProfileLabel] == consts.DaemonProfileLite {
+		// the virtual platforms are not supported by the lite profile
+		vars.DaemonProfile = consts.DaemonProfileLite
+		setupLog.Info("Running with the lite profile")
+	} else if err == nil {
 		for key, pType := range vars.PlatformsMap {
 			if strings.Contains(strings.ToLower(nodeInfo.Spec.ProviderID), strings.ToLower(key)) {
 				vars.PlatformType = pType
 			}
 		}
+		// the virtual machines seeing a PF capable of SR-IOV, e.g. passed through or emulated by the hypervisor,
+		// provision its VFs like the bare metal nodes next to the devices of the platform
+		if vars.PlatformType != consts.Baremetal && nestedSriov(nodeInfo, hostHelpers) {
+			setupLog.Info("the virtual machine has SR-IOV capable PFs, provisioning their VFs", "platform", vars.PlatformType.String())
+			vars.NestedSriov = true
+		}
 	} else {
 		setupLog.Error(err, "failed to fetch node state, exiting", "node-name", startOpts.nodeName)
 		return err
 	}
 	setupLog.Info("Running on", "platform", vars.PlatformType.String())
 
+	// nodes joining the cluster are checked once, the preflight command can be run as a
+	// Job to check them again, e.g. after a firmware upgrade
+	if _, ok := nodeInfo.Annotations[consts.PreflightAnnotation]; !ok {
+		runStartupPreflight(hostHelpers, kubeclient, eventRecorder)
+	}
+
 	var namespace = os.Getenv("NAMESPACE")
 	if err := sriovnetworkv1.InitNicIDMapFromConfigMap(kubeclient, namespace); err != nil {
 		setupLog.Error(err, "failed to run init NicIdMap")
@@ -254,6 +340,13 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	eventRecorder.SendEvent("ConfigDaemonStart", "Config Daemon starting")
+	if failSafe {
+		eventRecorder.SendEvent("ConfigDaemonFailSafe", "Config Daemon applied the last applied spec while the API server was unreachable")
+	}
+
+	if startOpts.metricsAddr != "" && vars.DaemonProfile != consts.DaemonProfileLite {
+		go metrics.ServeDaemonMetrics(startOpts.metricsAddr, stopCh)
+	}
 
 	// block the deamon process until nodeWriter finish first its run
 	err = nodeWriter.RunOnce()
@@ -261,7 +354,7 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 		setupLog.Error(err, "failed to run writer")
 		return err
 	}
-	go nodeWriter.Run(stopCh, refreshCh, syncCh)
+	go nodeWriter.Run(stopCh, refreshCh, syncCh, hostEvents)
 
 	setupLog.V(0).Info("Starting SriovNetworkConfigDaemon")
 	err = daemon.New(
@@ -274,6 +367,8 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 		syncCh,
 		refreshCh,
 		eventRecorder,
+		lldpListener,
+		nodeWriter,
 		startOpts.disabledPlugins,
 	).Run(stopCh, exitCh)
 	if err != nil {
@@ -283,6 +378,41 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// nestedSriov returns true if the virtual machine of the node has SR-IOV capable PFs, the nested-sriov label of
+// the node forces the choice
+func nestedSriov(node *corev1.Node, hostHelpers helper.HostHelpersInterface) bool {
+	switch node.Labels[consts.NestedSriovLabel] {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	nested, err := hostHelpers.HasSriovCapablePfs()
+	if err != nil {
+		log.Log.Error(err, "failed to detect the SR-IOV capable PFs of the virtual machine")
+		return false
+	}
+	return nested
+}
+
+// runStartupPreflight runs the pre-flight checks with the default options and flags the
+// node if they fail, the daemon keeps running either way
+func runStartupPreflight(hostHelpers helper.HostHelpersInterface, kubeclient kubernetes.Interface, eventRecorder *daemon.EventRecorder) {
+	setupLog := log.Log.WithName("sriov-network-config-daemon")
+	opts, _ := preflightOptions(nil, nil)
+	report, err := preflight.Run(hostHelpers, opts)
+	if err != nil {
+		setupLog.Error(err, "failed to run the pre-flight checks")
+		return
+	}
+	if err := preflight.Annotate(context.Background(), kubeclient, vars.NodeName, report); err != nil {
+		setupLog.Error(err, "failed to store the pre-flight report")
+	}
+	if !report.Passed {
+		eventRecorder.SendEvent("PreflightFailed", fmt.Sprintf("Pre-flight checks failed: %s", strings.Join(report.Failed(), ", ")))
+	}
+}
+
 // updateDialer instruments a restconfig with a dial. the returned function allows forcefully closing all active connections.
 func updateDialer(clientConfig *rest.Config) (func(), error) {
 	if clientConfig.Transport != nil || clientConfig.Dial != nil {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	helperMock "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+)
+
+var _ = Describe("Nested SR-IOV", func() {
+	var (
+		hostHelpers *helperMock.MockHostHelpersInterface
+		node        *corev1.Node
+	)
+	BeforeEach(func() {
+		hostHelpers = helperMock.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "vm-0", Labels: map[string]string{}},
+			Spec: corev1.NodeSpec{ProviderID: "openstack:///vm-0"}}
+	})
+	It("detects the SR-IOV capable PFs of the virtual machine", func() {
+		hostHelpers.EXPECT().HasSriovCapablePfs().Return(true, nil)
+		Expect(nestedSriov(node, hostHelpers)).To(BeTrue())
+	})
+	It("uses the VFs of the platform when the detection fails", func() {
+		hostHelpers.EXPECT().HasSriovCapablePfs().Return(false, fmt.Errorf("no PCI devices"))
+		Expect(nestedSriov(node, hostHelpers)).To(BeFalse())
+	})
+	It("follows the label of the node", func() {
+		node.Labels[consts.NestedSriovLabel] = "false"
+		Expect(nestedSriov(node, hostHelpers)).To(BeFalse())
+		node.Labels[consts.NestedSriovLabel] = "true"
+		Expect(nestedSriov(node, hostHelpers)).To(BeTrue())
+	})
+})
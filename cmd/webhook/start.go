@@ -6,18 +6,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/webhook"
 )
 
+// certReloadInterval is the period of the check of the certificate files, besides their watch
+const certReloadInterval = time.Minute
+
 var (
 	certFile    string
 	keyFile     string
@@ -127,6 +133,10 @@ func serveValidateCustomResource(w http.ResponseWriter, r *http.Request) {
 	serve(w, r, newDelegateToV1AdmitHandler(webhook.ValidateCustomResource))
 }
 
+func serveValidatePodNetworks(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, newDelegateToV1AdmitHandler(webhook.ValidatePodNetworks))
+}
+
 func newDelegateToV1AdmitHandler(f admitv1Func) admitHandler {
 	return admitHandler{
 		v1: f,
@@ -158,6 +168,8 @@ func runStartCmd(cmd *cobra.Command, args []string) {
 
 	http.HandleFunc("/mutating-custom-resource", serveMutateCustomResource)
 	http.HandleFunc("/validating-custom-resource", serveValidateCustomResource)
+	http.HandleFunc("/validating-pod-networks", serveValidatePodNetworks)
+	http.Handle("/convert", conversion.NewWebhookHandler(webhook.Scheme))
 	http.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) })
 
 	go func() {
@@ -179,51 +191,42 @@ func runStartCmd(cmd *cobra.Command, args []string) {
 			panic(err)
 		}
 	}()
-	/* watch the cert file and restart http sever if the file updated. */
+	// the certificates are reloaded when they are renewed, the secret volumes are updated by replacing the symlink
+	// of their directory so the directories are watched rather than the files. The files are also checked
+	// periodically in case an event is missed.
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		setupLog.Error(err, "error starting fsnotify watcher")
 		panic(err)
 	}
 	defer watcher.Close()
-
-	certUpdated := false
-	keyUpdated := false
+	for _, dir := range []string{filepath.Dir(certFile), filepath.Dir(keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			setupLog.Error(err, "failed to watch the certificates", "dir", dir)
+			panic(err)
+		}
+	}
+	ticker := time.NewTicker(certReloadInterval)
+	defer ticker.Stop()
 
 	for {
-		watcher.Add(certFile)
-		watcher.Add(keyFile)
-
 		select {
 		case event, ok := <-watcher.Events:
 			if !ok {
 				continue
 			}
-			setupLog.Info("watcher event", "event", event)
-			mask := fsnotify.Create | fsnotify.Rename | fsnotify.Remove |
-				fsnotify.Write | fsnotify.Chmod
-			if (event.Op & mask) != 0 {
-				setupLog.Info("modified file", "name", event.Name)
-				if event.Name == certFile {
-					certUpdated = true
-				}
-				if event.Name == keyFile {
-					keyUpdated = true
-				}
-				if keyUpdated && certUpdated {
-					if err := keyPair.Reload(); err != nil {
-						setupLog.Error(err, "failed to reload certificate")
-						panic("failed to reload certificate")
-					}
-					certUpdated = false
-					keyUpdated = false
-				}
-			}
+			setupLog.V(2).Info("watcher event", "event", event)
 		case err, ok := <-watcher.Errors:
 			if !ok {
 				continue
 			}
 			setupLog.Error(err, "watcher error")
+			continue
+		case <-ticker.C:
+		}
+		// a failed reload keeps serving the current certificate, the renewal is retried on the next event or tick
+		if err := keyPair.Reload(); err != nil {
+			setupLog.Error(err, "failed to reload certificate, the current certificate is still served")
 		}
 	}
 }
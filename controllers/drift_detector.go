@@ -0,0 +1,171 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// driftCheckInterval is the period the rendered objects are checked for out-of-band modifications at
+	driftCheckInterval = 5 * time.Minute
+	// ConfigurationDriftReason is the reason of the events reporting a modified rendered object
+	ConfigurationDriftReason = "ConfigurationDrift"
+)
+
+// renderedKind is a kind of object rendered by the operator, the objects are listed in the operator namespace
+// unless clusterWide is set
+type renderedKind struct {
+	gvk         schema.GroupVersionKind
+	clusterWide bool
+}
+
+var renderedKinds = []renderedKind{
+	{gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Service"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, clusterWide: true},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"}, clusterWide: true},
+	{gvk: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"}, clusterWide: true},
+	{gvk: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}, clusterWide: true},
+	{gvk: schema.GroupVersionKind{Group: "machineconfiguration.openshift.io", Version: "v1", Kind: "MachineConfig"}, clusterWide: true},
+	{gvk: schema.GroupVersionKind{Group: "k8s.cni.cncf.io", Version: "v1", Kind: "NetworkAttachmentDefinition"}, clusterWide: true},
+}
+
+// DriftDetector periodically compares the objects rendered by the operator with the content recorded in their
+// hash annotation, and reports the objects modified out of band with a warning event and a metric. An object
+// is reported once it is found drifted by two consecutive checks, as the operator may be updating it.
+type DriftDetector struct {
+	Client   client.Reader
+	Recorder record.EventRecorder
+
+	// suspected holds the content hash of the objects found drifted by the last check
+	suspected map[types.UID]string
+	// reported holds the content hash of the drifted objects already reported
+	reported map[types.UID]string
+}
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupWithManager adds the detector to the Manager, it only runs on the leader
+func (d *DriftDetector) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(d)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (d *DriftDetector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start checks the rendered objects until the context is done
+func (d *DriftDetector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(driftCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.check(ctx)
+		}
+	}
+}
+
+// check lists the rendered objects of every kind and reports the drifted ones
+func (d *DriftDetector) check(ctx context.Context) {
+	logger := log.Log.WithName("DriftDetector")
+	if d.suspected == nil {
+		d.suspected = map[types.UID]string{}
+		d.reported = map[types.UID]string{}
+	}
+
+	drifted := map[types.UID]string{}
+	for _, kind := range renderedKinds {
+		list := &uns.UnstructuredList{}
+		list.SetGroupVersionKind(kind.gvk.GroupVersion().WithKind(kind.gvk.Kind + "List"))
+		opts := []client.ListOption{client.MatchingLabels{constants.ManagedByLabel: constants.ManagedByValue}}
+		if !kind.clusterWide {
+			opts = append(opts, client.InNamespace(vars.Namespace))
+		}
+		if err := d.Client.List(ctx, list, opts...); err != nil {
+			// the MachineConfigs only exist on OpenShift
+			if !meta.IsNoMatchError(err) {
+				logger.Error(err, "failed to list the rendered objects", "kind", kind.gvk.Kind)
+			}
+			continue
+		}
+
+		count := 0
+		for i := range list.Items {
+			obj := &list.Items[i]
+			isDrifted, err := audit.Drifted(obj)
+			if err != nil {
+				logger.Error(err, "failed to check the rendered object", "kind", kind.gvk.Kind,
+					"namespace", obj.GetNamespace(), "name", obj.GetName())
+				continue
+			}
+			if !isDrifted {
+				continue
+			}
+			hash, _ := audit.ContentHash(obj)
+			drifted[obj.GetUID()] = hash
+			if d.suspected[obj.GetUID()] != hash {
+				continue
+			}
+			count++
+			if d.reported[obj.GetUID()] == hash {
+				continue
+			}
+			d.reported[obj.GetUID()] = hash
+			owner := obj.GetAnnotations()[constants.OwnerAnnotation]
+			logger.Info("rendered object modified out of band", "kind", kind.gvk.Kind,
+				"namespace", obj.GetNamespace(), "name", obj.GetName(), "owner", owner)
+			if d.Recorder != nil {
+				d.Recorder.Eventf(obj, corev1.EventTypeWarning, ConfigurationDriftReason,
+					"%s %s was modified out of band, its content no longer matches the one rendered for %s",
+					kind.gvk.Kind, obj.GetName(), owner)
+			}
+		}
+		metrics.SetDriftedObjects(kind.gvk.Kind, count)
+	}
+
+	for uid := range d.reported {
+		if _, ok := drifted[uid]; !ok {
+			delete(d.reported, uid)
+		}
+	}
+	d.suspected = drifted
+}
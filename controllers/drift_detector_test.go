@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestDriftDetector(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.ConfigMapName, Namespace: vars.Namespace},
+		Data:       map[string]string{"node1": "{}"},
+	}
+	audit.Stamp(cm, "SriovOperatorConfig/"+vars.Namespace+"/default", constants.SourceGenerated)
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build()
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+	g.Expect(audit.Record(ctx, c, cm)).To(Succeed())
+
+	recorder := record.NewFakeRecorder(10)
+	detector := &DriftDetector{Client: c, Recorder: recorder}
+
+	detector.check(ctx)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	cm.Data["node1"] = `{"resourceList": []}`
+	g.Expect(c.Update(ctx, cm)).To(Succeed())
+
+	// reported once it is still drifted at the next check
+	detector.check(ctx)
+	g.Expect(recorder.Events).To(BeEmpty())
+	detector.check(ctx)
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(ConfigurationDriftReason)))
+	detector.check(ctx)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the operator rewrote the object
+	g.Expect(audit.Record(ctx, c, cm)).To(Succeed())
+	detector.check(ctx)
+	g.Expect(detector.suspected).To(BeEmpty())
+	g.Expect(detector.reported).To(BeEmpty())
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -37,25 +38,28 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/apply"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/render"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/signature"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
-var webhooks = map[string](string){
-	constants.InjectorWebHookName: constants.InjectorWebHookPath,
-	constants.OperatorWebHookName: constants.OperatorWebHookPath,
-}
-
 const (
 	clusterRoleResourceName               = "ClusterRole"
 	clusterRoleBindingResourceName        = "ClusterRoleBinding"
 	mutatingWebhookConfigurationCRDName   = "MutatingWebhookConfiguration"
 	validatingWebhookConfigurationCRDName = "ValidatingWebhookConfiguration"
 	machineConfigCRDName                  = "MachineConfig"
+	prometheusRuleKind                    = "PrometheusRule"
+	prometheusRuleCRDName                 = "prometheusrules.monitoring.coreos.com"
 	trueString                            = "true"
 )
 
+// policySignatures verifies the signatures of the policies for all the renderers, so that the node states, the device
+// plugin config, the resource map and the plugin daemons only see the same verified policies
+var policySignatures = signature.NewCache()
+
 func GetImagePullSecrets() []string {
 	imagePullSecrets := os.Getenv("IMAGE_PULL_SECRETS")
 	if imagePullSecrets != "" {
@@ -78,6 +82,19 @@ func GetDefaultNodeSelector() map[string]string {
 		"kubernetes.io/os": "linux"}
 }
 
+// daemonNodeSelector returns a copy of the node selector of the daemons restricted to the Linux nodes, unless it
+// already selects an operating system, so the daemons are not scheduled on the Windows nodes of mixed clusters
+func daemonNodeSelector(selector map[string]string) map[string]string {
+	result := make(map[string]string, len(selector)+1)
+	for key, value := range selector {
+		result[key] = value
+	}
+	if _, ok := result[constants.NodeOSLabel]; !ok {
+		result[constants.NodeOSLabel] = constants.NodeOSLinux
+	}
+	return result
+}
+
 func syncPluginDaemonObjs(ctx context.Context, client k8sclient.Client, scheme *runtime.Scheme, dp *sriovnetworkv1.SriovNetworkNodePolicy, pl *sriovnetworkv1.SriovNetworkNodePolicyList) error {
 	logger := log.Log.WithName("syncPluginDaemonObjs")
 	logger.V(1).Info("Start to sync sriov daemons objects")
@@ -103,6 +120,11 @@ func syncPluginDaemonObjs(ctx context.Context, client k8sclient.Client, scheme *
 		logger.Error(err, "Fail to render SR-IoV manifests")
 		return err
 	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, defaultConfig.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovNetworkNodePolicy", dp), constants.PluginPath)
 
 	if len(pl.Items) < 2 {
 		for _, obj := range objs {
@@ -124,7 +146,7 @@ func syncPluginDaemonObjs(ctx context.Context, client k8sclient.Client, scheme *
 				logger.Error(err, "Fail to convert to DaemonSet")
 				return err
 			}
-			ds.Spec.Template.Spec.NodeSelector = defaultConfig.Spec.ConfigDaemonNodeSelector
+			ds.Spec.Template.Spec.NodeSelector = daemonNodeSelector(defaultConfig.Spec.ConfigDaemonNodeSelector)
 			err = scheme.Convert(ds, obj, nil)
 			if err != nil {
 				logger.Error(err, "Fail to convert to Unstructured")
@@ -148,6 +170,13 @@ func deleteK8sResource(ctx context.Context, client k8sclient.Client, in *uns.Uns
 	return nil
 }
 
+// stampRendered sets the ownership metadata on the objects rendered from the manifests directory
+func stampRendered(objs []*uns.Unstructured, owner, manifestDir string) {
+	for _, obj := range objs {
+		audit.Stamp(obj, owner, filepath.Clean(manifestDir))
+	}
+}
+
 func syncDsObject(ctx context.Context, client k8sclient.Client, scheme *runtime.Scheme, dp *sriovnetworkv1.SriovNetworkNodePolicy, pl *sriovnetworkv1.SriovNetworkNodePolicyList, obj *uns.Unstructured) error {
 	logger := log.Log.WithName("syncDsObject")
 	kind := obj.GetKind()
@@ -219,14 +248,15 @@ func syncDaemonSet(ctx context.Context, client k8sclient.Client, scheme *runtime
 				equality.Semantic.DeepEqual(in.Spec.Template.Spec.Containers[0].Args,
 					ds.Spec.Template.Spec.Containers[0].Args) {
 				logger.V(1).Info("Daemonset spec did not change, not updating")
-				return nil
+				return audit.Adopt(ctx, client, ds)
 			}
 		}
+		audit.KeepHash(in, ds)
 		err = client.Update(ctx, in)
 		if err != nil {
 			logger.Error(err, "Fail to update DaemonSet", "Namespace", in.Namespace, "Name", in.Name)
 			return err
 		}
 	}
-	return nil
+	return audit.Record(ctx, client, in)
 }
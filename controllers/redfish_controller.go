@@ -0,0 +1,245 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/preflight"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/redfish"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const redfishRetryInterval = 10 * time.Minute
+
+// RedfishReconciler checks the BIOS settings of the nodes whose pre-flight checks found SR-IOV or the
+// IOMMU disabled, through the Redfish API of their BMC, and reports them in a node condition. When
+// remediation is enabled, the disabled settings are enabled through the BMC for the next reboot.
+type RedfishReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile updates the BIOS settings condition of a node
+func (r *RedfishReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("redfish")
+
+	config := &sriovnetworkv1.SriovOperatorConfig{}
+	err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultConfigName, Namespace: vars.Namespace}, config)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if config.Spec.Redfish == nil {
+		return reconcile.Result{}, nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	// the addresses are owned by the cluster admins, an annotation of the node would let its kubelet send the
+	// credentials anywhere
+	addresses := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: config.Spec.Redfish.AddressesConfigMap, Namespace: vars.Namespace}, addresses)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get the BMC addresses configmap: %v", err)
+	}
+	address := addresses.Data[node.Name]
+	if address == "" {
+		return reconcile.Result{}, nil
+	}
+
+	report, err := preflight.GetReport(node)
+	if err != nil {
+		logger.Error(err, "invalid pre-flight report", "node", node.Name)
+		return reconcile.Result{}, nil
+	}
+	if report == nil {
+		// the config daemon didn't check the node yet
+		return reconcile.Result{}, nil
+	}
+	if platformChecksPassed(report) {
+		return reconcile.Result{}, r.setCondition(ctx, node, corev1.ConditionTrue, constants.BiosSettingsReasonEnabled,
+			"the pre-flight checks found SR-IOV and the IOMMU enabled")
+	}
+	if cond := getNodeCondition(node); cond != nil && cond.Reason == constants.BiosSettingsReasonRemediationPending &&
+		!report.Time.After(cond.LastHeartbeatTime.Time) {
+		// wait for the node to reboot and the config daemon to check it again
+		return reconcile.Result{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: config.Spec.Redfish.CredentialsSecret, Namespace: vars.Namespace}, secret)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get the BMC credentials secret: %v", err)
+	}
+	bmc, err := redfish.NewClient(address, string(secret.Data["username"]), string(secret.Data["password"]),
+		secret.Data["ca.crt"])
+	if err != nil {
+		logger.Error(err, "invalid BMC configuration", "node", node.Name)
+		return reconcile.Result{}, r.setCondition(ctx, node, corev1.ConditionUnknown, constants.BiosSettingsReasonBmcError, err.Error())
+	}
+
+	state, err := bmc.GetBiosState(ctx)
+	if err != nil {
+		logger.Error(err, "failed to read the BIOS settings", "node", node.Name)
+		return reconcile.Result{RequeueAfter: redfishRetryInterval},
+			r.setCondition(ctx, node, corev1.ConditionUnknown, constants.BiosSettingsReasonBmcError, err.Error())
+	}
+	if len(state.Disabled) == 0 {
+		msg := "the BIOS settings required by SR-IOV are enabled"
+		if len(state.Unknown) > 0 {
+			msg = fmt.Sprintf("%s, %s not exposed by the BMC", msg, strings.Join(state.Unknown, ", "))
+		}
+		return reconcile.Result{}, r.setCondition(ctx, node, corev1.ConditionTrue, constants.BiosSettingsReasonEnabled, msg)
+	}
+
+	disabled := strings.Join(state.Disabled, ", ")
+	if !config.Spec.Redfish.Remediate {
+		return reconcile.Result{}, r.setCondition(ctx, node, corev1.ConditionFalse, constants.BiosSettingsReasonDisabled,
+			fmt.Sprintf("%s disabled in the BIOS", disabled))
+	}
+
+	logger.Info("enabling BIOS settings through the BMC", "node", node.Name, "settings", disabled)
+	if err := bmc.EnableSettings(ctx, state); err != nil {
+		logger.Error(err, "failed to enable the BIOS settings", "node", node.Name)
+		return reconcile.Result{RequeueAfter: redfishRetryInterval},
+			r.setCondition(ctx, node, corev1.ConditionUnknown, constants.BiosSettingsReasonBmcError, err.Error())
+	}
+	err = r.setCondition(ctx, node, corev1.ConditionFalse, constants.BiosSettingsReasonRemediationPending,
+		fmt.Sprintf("%s enabled through the BMC (%s), reboot the node to apply them",
+			disabled, strings.Join(state.FixedAttributes(), ", ")))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the config daemon runs the pre-flight checks again when it starts after the reboot
+	patch := client.MergeFrom(node.DeepCopy())
+	delete(node.Annotations, constants.PreflightAnnotation)
+	return reconcile.Result{}, r.Patch(ctx, node, patch)
+}
+
+// platformChecksPassed returns true if the pre-flight checks depending on the BIOS settings passed
+func platformChecksPassed(report *preflight.Report) bool {
+	for _, c := range report.Checks {
+		if !c.Passed && (c.Name == preflight.CheckSriovEnabled || c.Name == preflight.CheckIommuEnabled) {
+			return false
+		}
+	}
+	return true
+}
+
+func getNodeCondition(node *corev1.Node) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == constants.BiosSettingsConditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition patches the BIOS settings condition of the node if it changed
+func (r *RedfishReconciler) setCondition(ctx context.Context, node *corev1.Node, status corev1.ConditionStatus, reason, message string) error {
+	existing := getNodeCondition(node)
+	if existing != nil && existing.Status == status && existing.Reason == reason && existing.Message == message {
+		return nil
+	}
+
+	patch := client.StrategicMergeFrom(node.DeepCopy())
+	now := metav1.Now()
+	cond := corev1.NodeCondition{
+		Type:               constants.BiosSettingsConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}
+	if existing != nil {
+		if existing.Status == status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = cond
+	} else {
+		node.Status.Conditions = append(node.Status.Conditions, cond)
+	}
+	return r.Status().Patch(ctx, node, patch)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedfishReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// a change of the redfish configuration, of the BMC addresses or of the credentials re-evaluates all the nodes
+	// with a BMC
+	configHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		config := &sriovnetworkv1.SriovOperatorConfig{}
+		err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultConfigName, Namespace: vars.Namespace}, config)
+		if err != nil || config.Spec.Redfish == nil {
+			return nil
+		}
+		switch obj.(type) {
+		case *corev1.ConfigMap:
+			if obj.GetName() != config.Spec.Redfish.AddressesConfigMap {
+				return nil
+			}
+		case *corev1.Secret:
+			if obj.GetName() != config.Spec.Redfish.CredentialsSecret {
+				return nil
+			}
+		}
+		addresses := &corev1.ConfigMap{}
+		err = r.Get(ctx, types.NamespacedName{Name: config.Spec.Redfish.AddressesConfigMap, Namespace: vars.Namespace}, addresses)
+		if err != nil {
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for name := range addresses.Data {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+		return requests
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("redfish").
+		For(&corev1.Node{}, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Watches(&sriovnetworkv1.SriovOperatorConfig{}, configHandler).
+		Watches(&corev1.ConfigMap{}, configHandler).
+		Watches(&corev1.Secret{}, configHandler).
+		Complete(r)
+}
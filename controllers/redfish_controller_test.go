@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/preflight"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestRedfishRemediation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	patched := map[string]interface{}{}
+	bmc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Systems/1"}]}`))
+		case "/redfish/v1/Systems/1/Bios":
+			_, _ = w.Write([]byte(`{"Attributes": {"Sriov": "Disabled", "IntelProcVtd": "Enabled"}}`))
+		case "/redfish/v1/Systems/1/Bios/Settings":
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &patched)
+		}
+	}))
+	defer bmc.Close()
+
+	report, err := json.Marshal(&preflight.Report{
+		Time:   metav1.Now(),
+		Checks: []preflight.CheckResult{{Name: preflight.CheckSriovEnabled, Passed: false}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name: "worker-0",
+		Annotations: map[string]string{
+			constants.PreflightAnnotation: string(report),
+		},
+	}}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			Redfish: &sriovnetworkv1.RedfishConfig{CredentialsSecret: "bmc-credentials", AddressesConfigMap: "bmc-addresses"},
+		},
+	}
+	addresses := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-addresses", Namespace: vars.Namespace},
+		Data:       map[string]string{"worker-0": bmc.URL},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-credentials", Namespace: vars.Namespace},
+		Data: map[string][]byte{"username": []byte("admin"), "password": []byte("secret"),
+			"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: bmc.Certificate().Raw})},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(config, node, addresses, secret).
+		WithStatusSubresource(node).
+		Build()
+	reconciler := &RedfishReconciler{Client: c, Scheme: scheme}
+
+	reconcileAndGetNode := func() *corev1.Node {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+		g.Expect(err).ToNot(HaveOccurred())
+		updated := &corev1.Node{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, updated)).To(Succeed())
+		return updated
+	}
+
+	// detection only
+	updated := reconcileAndGetNode()
+	cond := getNodeCondition(updated)
+	g.Expect(cond).ToNot(BeNil())
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(constants.BiosSettingsReasonDisabled))
+	g.Expect(cond.Message).To(Equal("SR-IOV disabled in the BIOS"))
+	g.Expect(patched).To(BeEmpty())
+
+	// remediation
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: config.Name, Namespace: config.Namespace}, config)).To(Succeed())
+	config.Spec.Redfish.Remediate = true
+	g.Expect(c.Update(ctx, config)).To(Succeed())
+
+	updated = reconcileAndGetNode()
+	cond = getNodeCondition(updated)
+	g.Expect(cond.Reason).To(Equal(constants.BiosSettingsReasonRemediationPending))
+	g.Expect(cond.Message).To(ContainSubstring("(Sriov)"))
+	g.Expect(patched["Attributes"]).To(Equal(map[string]interface{}{"Sriov": "Enabled"}))
+	g.Expect(updated.Annotations).ToNot(HaveKey(constants.PreflightAnnotation))
+
+	// the node passes the checks after the reboot
+	report, err = json.Marshal(&preflight.Report{
+		Passed: true,
+		Time:   metav1.Now(),
+		Checks: []preflight.CheckResult{{Name: preflight.CheckSriovEnabled, Passed: true}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	updated.Annotations = map[string]string{constants.PreflightAnnotation: string(report)}
+	g.Expect(c.Update(ctx, updated)).To(Succeed())
+
+	updated = reconcileAndGetNode()
+	cond = getNodeCondition(updated)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal(constants.BiosSettingsReasonEnabled))
+}
+
+func TestRedfishNodeWithoutBmc(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			Redfish: &sriovnetworkv1.RedfishConfig{CredentialsSecret: "bmc-credentials", AddressesConfigMap: "bmc-addresses"},
+		},
+	}
+	// the address annotated by the node is ignored
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0",
+		Annotations: map[string]string{"sriovnetwork.openshift.io/bmc-address": "https://attacker.example.com"}}}
+	addresses := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-addresses", Namespace: vars.Namespace},
+		Data:       map[string]string{"worker-1": "https://10.0.0.6"},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, node, addresses).WithStatusSubresource(node).Build()
+	reconciler := &RedfishReconciler{Client: c, Scheme: scheme}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	g.Expect(err).ToNot(HaveOccurred())
+	updated := &corev1.Node{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, updated)).To(Succeed())
+	g.Expect(updated.Status.Conditions).To(BeEmpty())
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	soakTestDefaultTimeout  = 30 * time.Minute
+	soakTestDefaultInterval = time.Minute
+	soakTestPollInterval    = 30 * time.Second
+	soakTestPolicyPriority  = 99
+)
+
+// SoakTestReconciler runs the soak mode configured in the default SriovOperatorConfig: it
+// repeatedly creates a scratch policy on the test node pool, waits for the nodes to apply it,
+// removes it and waits for the nodes to clean up, recording timing and failure statistics.
+type SoakTestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile moves the soak mode cycle forward
+func (r *SoakTestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("soak-test")
+
+	config := &sriovnetworkv1.SriovOperatorConfig{}
+	err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultConfigName, Namespace: vars.Namespace}, config)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if config.Spec.SoakTest == nil {
+		if err := r.deletePolicy(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		if config.Status.SoakTest != nil && config.Status.SoakTest.Phase != "" {
+			logger.Info("soak mode disabled")
+			patch := client.MergeFrom(config.DeepCopy())
+			config.Status.SoakTest.Phase = ""
+			config.Status.SoakTest.PhaseStartTime = nil
+			return reconcile.Result{}, r.Status().Patch(ctx, config, patch)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	patch := client.MergeFrom(config.DeepCopy())
+	if config.Status.SoakTest == nil {
+		config.Status.SoakTest = &sriovnetworkv1.SoakTestStatus{}
+	}
+	status := config.Status.SoakTest
+	timeout := soakTestDefaultTimeout
+	if config.Spec.SoakTest.TimeoutSeconds > 0 {
+		timeout = time.Duration(config.Spec.SoakTest.TimeoutSeconds) * time.Second
+	}
+	interval := soakTestDefaultInterval
+	if config.Spec.SoakTest.IntervalSeconds > 0 {
+		interval = time.Duration(config.Spec.SoakTest.IntervalSeconds) * time.Second
+	}
+
+	switch status.Phase {
+	case constants.SoakTestPhaseApplying, constants.SoakTestPhaseRemoving:
+		if status.PhaseStartTime == nil {
+			// the step is timed from now when its start time is missing
+			r.setPhase(status, status.Phase)
+			return reconcile.Result{RequeueAfter: soakTestPollInterval}, r.Status().Patch(ctx, config, patch)
+		}
+		applied := status.Phase == constants.SoakTestPhaseApplying
+		elapsed := time.Since(status.PhaseStartTime.Time)
+		done, failure, err := r.checkNodeStates(ctx, config.Spec.SoakTest, applied)
+		if err != nil {
+			// the step still times out when the node states can't be read
+			if elapsed <= timeout {
+				return reconcile.Result{}, err
+			}
+			logger.Error(err, "failed to check the node states of the test pool")
+		}
+		if failure == "" && !done && elapsed > timeout {
+			failure = fmt.Sprintf("%s step timed out after %s", status.Phase, timeout)
+		}
+
+		switch {
+		case failure != "":
+			logger.Info("soak test cycle failed", "step", status.Phase, "reason", failure)
+			metrics.IncSoakTestFailures(status.Phase)
+			now := metav1.Now()
+			status.Failures++
+			status.LastFailure = failure
+			status.LastFailureTime = &now
+			if applied {
+				// clean up the node pool before starting the next cycle
+				if err := r.deletePolicy(ctx); err != nil {
+					return reconcile.Result{}, err
+				}
+				r.setPhase(status, constants.SoakTestPhaseRemoving)
+			} else {
+				r.setPhase(status, "")
+			}
+		case done && applied:
+			metrics.ObserveSoakTestStep(status.Phase, elapsed)
+			status.LastApplySeconds = int(elapsed.Seconds())
+			if status.LastApplySeconds > status.MaxApplySeconds {
+				status.MaxApplySeconds = status.LastApplySeconds
+			}
+			if err := r.deletePolicy(ctx); err != nil {
+				return reconcile.Result{}, err
+			}
+			r.setPhase(status, constants.SoakTestPhaseRemoving)
+		case done:
+			metrics.ObserveSoakTestStep(status.Phase, elapsed)
+			status.LastRemoveSeconds = int(elapsed.Seconds())
+			if status.LastRemoveSeconds > status.MaxRemoveSeconds {
+				status.MaxRemoveSeconds = status.LastRemoveSeconds
+			}
+			status.Cycles++
+			logger.Info("soak test cycle completed", "cycles", status.Cycles, "failures", status.Failures)
+			r.setPhase(status, "")
+		default:
+			return reconcile.Result{RequeueAfter: soakTestPollInterval}, nil
+		}
+	default:
+		// the start time of the idle phase is the end of the last cycle
+		if status.PhaseStartTime != nil {
+			if wait := interval - time.Since(status.PhaseStartTime.Time); wait > 0 {
+				return reconcile.Result{RequeueAfter: wait}, nil
+			}
+		}
+		logger.Info("soak test cycle started", "cycle", status.Cycles+status.Failures+1)
+		if err := r.createPolicy(ctx, config.Spec.SoakTest); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.setPhase(status, constants.SoakTestPhaseApplying)
+	}
+
+	if err := r.Status().Patch(ctx, config, patch); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: soakTestPollInterval}, nil
+}
+
+func (r *SoakTestReconciler) setPhase(status *sriovnetworkv1.SoakTestStatus, phase string) {
+	now := metav1.Now()
+	status.Phase = phase
+	status.PhaseStartTime = &now
+}
+
+// checkNodeStates reports whether all the nodes of the test pool have applied (or removed) the
+// scratch policy, or the reason why a node failed to do so
+func (r *SoakTestReconciler) checkNodeStates(ctx context.Context, cfg *sriovnetworkv1.SoakTestConfig, applied bool) (bool, string, error) {
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, client.MatchingLabels(cfg.NodeSelector)); err != nil {
+		return false, "", err
+	}
+	if len(nodeList.Items) == 0 {
+		return false, "no node matches the soak test nodeSelector", nil
+	}
+
+	configured := 0
+	for _, node := range nodeList.Items {
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+		err := r.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: vars.Namespace}, nodeState)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, "", nil
+			}
+			return false, "", err
+		}
+		if nodeState.Status.SyncStatus == constants.SyncStatusFailed {
+			return false, fmt.Sprintf("node %s failed to sync: %s", node.Name, nodeState.Status.LastSyncError), nil
+		}
+
+		rendered := false
+		for _, iface := range nodeState.Spec.Interfaces {
+			for _, group := range iface.VfGroups {
+				if group.PolicyName == constants.SoakTestPolicyName {
+					rendered = true
+				}
+			}
+		}
+
+		for i := range nodeState.Status.Interfaces {
+			iface := &nodeState.Status.Interfaces[i]
+			if !cfg.NicSelector.Selected(iface) {
+				continue
+			}
+			configured++
+			wantVfs := 0
+			if applied {
+				wantVfs = cfg.NumVfs
+			}
+			if rendered != applied || iface.NumVfs != wantVfs || nodeState.Status.SyncStatus != constants.SyncStatusSucceeded {
+				return false, "", nil
+			}
+		}
+	}
+	if configured == 0 {
+		return false, "no NIC of the test pool matches the soak test nicSelector", nil
+	}
+	return true, "", nil
+}
+
+func (r *SoakTestReconciler) createPolicy(ctx context.Context, cfg *sriovnetworkv1.SoakTestConfig) error {
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.SoakTestPolicyName,
+			Namespace: vars.Namespace,
+		},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: constants.SoakTestResourceName,
+			NodeSelector: cfg.NodeSelector,
+			NicSelector:  cfg.NicSelector,
+			NumVfs:       cfg.NumVfs,
+			DeviceType:   cfg.DeviceType,
+			Priority:     soakTestPolicyPriority,
+		},
+	}
+	err := r.Create(ctx, policy)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *SoakTestReconciler) deletePolicy(ctx context.Context) error {
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.SoakTestPolicyName,
+			Namespace: vars.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, policy); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SoakTestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// every change of a node state moves the cycle forward
+	nodeStateHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}}
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("soaktest").
+		For(&sriovnetworkv1.SriovOperatorConfig{}).
+		Watches(&sriovnetworkv1.SriovNetworkNodeState{}, nodeStateHandler).
+		Complete(r)
+}
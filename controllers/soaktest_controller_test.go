@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestSoakTestCycle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			SoakTest: &sriovnetworkv1.SoakTestConfig{
+				NodeSelector: map[string]string{"soak": "true"},
+				NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+				NumVfs:       4,
+			},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"soak": "true"}}}
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			SyncStatus: constants.SyncStatusSucceeded,
+			Interfaces: sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0"}},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(config, node, nodeState).
+		WithStatusSubresource(config, nodeState).
+		Build()
+	reconciler := &SoakTestReconciler{Client: c, Scheme: scheme}
+
+	reconcileAndGetStatus := func() *sriovnetworkv1.SoakTestStatus {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{})
+		g.Expect(err).ToNot(HaveOccurred())
+		cfg := &sriovnetworkv1.SriovOperatorConfig{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), cfg)).To(Succeed())
+		return cfg.Status.SoakTest
+	}
+	// backdatePhase moves the start of the current phase back in time
+	backdatePhase := func(d time.Duration) {
+		cfg := &sriovnetworkv1.SriovOperatorConfig{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), cfg)).To(Succeed())
+		start := metav1.NewTime(cfg.Status.SoakTest.PhaseStartTime.Add(-d))
+		cfg.Status.SoakTest.PhaseStartTime = &start
+		g.Expect(c.Status().Update(ctx, cfg)).To(Succeed())
+	}
+	updateNodeState := func(numVfs int, policyName string) {
+		ns := &sriovnetworkv1.SriovNetworkNodeState{}
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(nodeState), ns)).To(Succeed())
+		ns.Spec.Interfaces = nil
+		if policyName != "" {
+			ns.Spec.Interfaces = sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:86:00.0",
+				NumVfs:     numVfs,
+				VfGroups:   []sriovnetworkv1.VfGroup{{PolicyName: policyName}},
+			}}
+		}
+		g.Expect(c.Update(ctx, ns)).To(Succeed())
+		ns.Status.Interfaces[0].NumVfs = numVfs
+		g.Expect(c.Status().Update(ctx, ns)).To(Succeed())
+	}
+
+	// the cycle starts with the creation of the scratch policy
+	status := reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseApplying))
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: constants.SoakTestPolicyName, Namespace: vars.Namespace}, policy)).To(Succeed())
+	g.Expect(policy.Spec.NumVfs).To(Equal(4))
+
+	// the node didn't apply the policy yet
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseApplying))
+
+	updateNodeState(4, constants.SoakTestPolicyName)
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseRemoving))
+	err := c.Get(ctx, types.NamespacedName{Name: constants.SoakTestPolicyName, Namespace: vars.Namespace}, policy)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	updateNodeState(0, "")
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(BeEmpty())
+	g.Expect(status.Cycles).To(Equal(1))
+	g.Expect(status.Failures).To(Equal(0))
+
+	// the next cycle starts once the interval elapsed
+	result, err := reconciler.Reconcile(ctx, ctrl.Request{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Second))
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(BeEmpty())
+	backdatePhase(time.Minute)
+
+	// a sync failure of the node fails the cycle
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseApplying))
+	ns := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(nodeState), ns)).To(Succeed())
+	ns.Status.SyncStatus = constants.SyncStatusFailed
+	ns.Status.LastSyncError = "failed to create VFs"
+	g.Expect(c.Status().Update(ctx, ns)).To(Succeed())
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseRemoving))
+	g.Expect(status.Failures).To(Equal(1))
+	g.Expect(status.LastFailure).To(ContainSubstring("failed to create VFs"))
+
+	// a step the nodes don't complete times out
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(nodeState), ns)).To(Succeed())
+	ns.Status.SyncStatus = constants.SyncStatusSucceeded
+	ns.Status.Interfaces[0].NumVfs = 4
+	g.Expect(c.Status().Update(ctx, ns)).To(Succeed())
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(Equal(constants.SoakTestPhaseRemoving))
+	backdatePhase(soakTestDefaultTimeout + time.Second)
+	status = reconcileAndGetStatus()
+	g.Expect(status.Phase).To(BeEmpty())
+	g.Expect(status.Failures).To(Equal(2))
+	g.Expect(status.LastFailure).To(Equal("Removing step timed out after 30m0s"))
+}
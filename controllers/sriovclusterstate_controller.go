@@ -0,0 +1,273 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// clusterStateSyncInterval is the period of the computation of the report, the allocatable resources and the
+// conditions of the nodes are only read then
+const clusterStateSyncInterval = time.Minute
+
+// SriovClusterStateReconciler maintains the default SriovClusterState, the effective SR-IOV configuration of the
+// cluster computed from the policies, the pools, the SriovNetworkNodeStates and the nodes
+type SriovClusterStateReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovclusterstates,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovclusterstates/status,verbs=get;update;patch
+
+// Reconcile creates the default SriovClusterState and updates its report
+func (r *SriovClusterStateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("clusterstate")
+
+	cs := &sriovnetworkv1.SriovClusterState{}
+	if err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultConfigName}, cs); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		cs.Name = constants.DefaultConfigName
+		if err := r.Create(ctx, cs); err != nil {
+			return reconcile.Result{}, err
+		}
+		logger.Info("Created the default SriovClusterState")
+	}
+
+	nodeStates := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := r.List(ctx, nodeStates, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
+	pools := &sriovnetworkv1.SriovNetworkPoolConfigList{}
+	if err := r.List(ctx, pools, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	policies := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := renderClusterState(nodeStates, nodes, pools, policies)
+	status.UpdateTime = cs.Status.UpdateTime
+	if !equality.Semantic.DeepEqual(cs.Status, status) || cs.Status.UpdateTime == nil {
+		now := metav1.Now()
+		status.UpdateTime = &now
+		cs.Status = status
+		if err := r.Status().Update(ctx, cs); err != nil {
+			return reconcile.Result{}, err
+		}
+		logger.V(1).Info("Updated the cluster state", "nodes", status.Nodes, "failing", len(status.FailingNodes),
+			"pendingReboots", len(status.PendingReboots))
+	}
+	return reconcile.Result{RequeueAfter: clusterStateSyncInterval}, nil
+}
+
+// renderClusterState returns the report of the SR-IOV configuration of the cluster, without its update time
+func renderClusterState(nodeStates *sriovnetworkv1.SriovNetworkNodeStateList, nodes *corev1.NodeList,
+	pools *sriovnetworkv1.SriovNetworkPoolConfigList, policies *sriovnetworkv1.SriovNetworkNodePolicyList) sriovnetworkv1.SriovClusterStateStatus {
+	status := sriovnetworkv1.SriovClusterStateStatus{Nodes: len(nodeStates.Items)}
+
+	resources := map[string]*sriovnetworkv1.SriovClusterResource{}
+	policyNodes := map[string][]string{}
+	draining := map[string]bool{}
+	pendingReboots := map[string][]string{}
+	for _, ns := range nodeStates.Items {
+		switch ns.Status.SyncStatus {
+		case constants.SyncStatusSucceeded:
+			status.SyncedNodes++
+		case constants.SyncStatusFailed:
+			status.FailingNodes = append(status.FailingNodes, sriovnetworkv1.SriovClusterFailingNode{
+				Name: ns.Name, LastSyncError: ns.Status.LastSyncError, LastSyncErrorReason: ns.Status.LastSyncErrorReason})
+		}
+		draining[ns.Name] = ns.Status.Drain != nil
+
+		nodeResources := map[string]bool{}
+		nodePolicies := map[string]bool{}
+		for _, iface := range ns.Spec.Interfaces {
+			for _, group := range iface.VfGroups {
+				if group.PolicyName != "" && !nodePolicies[group.PolicyName] {
+					nodePolicies[group.PolicyName] = true
+					policyNodes[group.PolicyName] = append(policyNodes[group.PolicyName], ns.Name)
+				}
+				if group.ResourceName == "" {
+					continue
+				}
+				if resources[group.ResourceName] == nil {
+					resources[group.ResourceName] = &sriovnetworkv1.SriovClusterResource{ResourceName: group.ResourceName}
+				}
+				resources[group.ResourceName].TotalVfs += group.NumVfs()
+				if !nodeResources[group.ResourceName] {
+					nodeResources[group.ResourceName] = true
+					resources[group.ResourceName].Nodes++
+				}
+			}
+		}
+
+		// the NICs applying the split at their next boot report it once the node is rebooted
+		if ns.Status.SyncStatus == constants.SyncStatusSucceeded {
+			for _, iface := range ns.Spec.Interfaces {
+				if iface.PortSplit == 0 || iface.PortSplit == statusPortSplit(ns.Status.Interfaces, iface.PciAddress) {
+					continue
+				}
+				pendingReboots[ns.Name] = append(pendingReboots[ns.Name],
+					fmt.Sprintf("port split of %s into %d ports", iface.PciAddress, iface.PortSplit))
+			}
+		}
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		for _, r := range resources {
+			if allocatable, ok := node.Status.Allocatable[sriovnetworkv1.ExtendedResourceName(r.ResourceName)]; ok {
+				r.Allocatable += allocatable.Value()
+			}
+		}
+		for _, condition := range node.Status.Conditions {
+			if string(condition.Type) == constants.BiosSettingsConditionType &&
+				condition.Reason == constants.BiosSettingsReasonRemediationPending {
+				pendingReboots[node.Name] = append([]string{"BIOS settings enabled through the BMC"}, pendingReboots[node.Name]...)
+			}
+		}
+	}
+
+	for _, r := range resources {
+		status.Resources = append(status.Resources, *r)
+	}
+	sort.Slice(status.Resources, func(i, j int) bool {
+		return status.Resources[i].ResourceName < status.Resources[j].ResourceName
+	})
+	sort.Slice(status.FailingNodes, func(i, j int) bool {
+		return status.FailingNodes[i].Name < status.FailingNodes[j].Name
+	})
+	for name, reasons := range pendingReboots {
+		status.PendingReboots = append(status.PendingReboots,
+			sriovnetworkv1.SriovClusterPendingReboot{Name: name, Reason: strings.Join(reasons, ", ")})
+	}
+	sort.Slice(status.PendingReboots, func(i, j int) bool {
+		return status.PendingReboots[i].Name < status.PendingReboots[j].Name
+	})
+
+	for i := range pools.Items {
+		pool := &pools.Items[i]
+		p := sriovnetworkv1.SriovClusterPool{Name: pool.Name}
+		for j := range nodes.Items {
+			node := &nodes.Items[j]
+			if !pool.Selected(node) {
+				continue
+			}
+			p.Nodes = append(p.Nodes, node.Name)
+			if draining[node.Name] {
+				p.Draining++
+			}
+		}
+		sort.Strings(p.Nodes)
+		status.Pools = append(status.Pools, p)
+	}
+	sort.Slice(status.Pools, func(i, j int) bool {
+		return status.Pools[i].Name < status.Pools[j].Name
+	})
+
+	for _, policy := range policies.Items {
+		if policy.Name == constants.DefaultPolicyName {
+			continue
+		}
+		p := sriovnetworkv1.SriovClusterPolicy{Name: policy.Name, ResourceName: policy.Spec.ResourceName,
+			Nodes: policyNodes[policy.Name]}
+		sort.Strings(p.Nodes)
+		status.Policies = append(status.Policies, p)
+	}
+	sort.Slice(status.Policies, func(i, j int) bool {
+		return status.Policies[i].Name < status.Policies[j].Name
+	})
+	return status
+}
+
+// statusPortSplit returns the number of ports the PF reports its port split into, 1 for a port not split
+func statusPortSplit(ifaces sriovnetworkv1.InterfaceExts, pciAddress string) int {
+	for _, iface := range ifaces {
+		if iface.PciAddress == pciAddress && iface.PortSplit > 0 {
+			return iface.PortSplit
+		}
+	}
+	return 1
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SriovClusterStateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	defaultState := reconcile.Request{NamespacedName: types.NamespacedName{Name: constants.DefaultConfigName}}
+	enqueueDefaultState := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		return []reconcile.Request{defaultState}
+	})
+
+	// the node states update their status often, the report only depends on their spec, sync status and drain
+	nodeStateChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldState := e.ObjectOld.(*sriovnetworkv1.SriovNetworkNodeState)
+			newState := e.ObjectNew.(*sriovnetworkv1.SriovNetworkNodeState)
+			return oldState.Generation != newState.Generation ||
+				oldState.Status.SyncStatus != newState.Status.SyncStatus ||
+				oldState.Status.LastSyncError != newState.Status.LastSyncError ||
+				(oldState.Status.Drain == nil) != (newState.Status.Drain == nil)
+		},
+	}
+	// the nodes only matter to the pools through their labels
+	labelsChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sriovclusterstate").
+		For(&sriovnetworkv1.SriovClusterState{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == constants.DefaultConfigName
+		}))).
+		Watches(&sriovnetworkv1.SriovNetworkNodeState{}, enqueueDefaultState, builder.WithPredicates(nodeStateChanged)).
+		Watches(&sriovnetworkv1.SriovNetworkNodePolicy{}, enqueueDefaultState, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&sriovnetworkv1.SriovNetworkPoolConfig{}, enqueueDefaultState, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Node{}, enqueueDefaultState, builder.WithPredicates(labelsChanged)).
+		Complete(r)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestSriovClusterState(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("RESOURCE_PREFIX", "openshift.io")
+
+	node := func(name, role string, allocatable int64, conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"role": role}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{"openshift.io/nic1": *resource.NewQuantity(allocatable, resource.DecimalSI)},
+				Conditions:  conditions,
+			},
+		}
+	}
+	nodeState := func(name, syncStatus string, iface sriovnetworkv1.Interface) *sriovnetworkv1.SriovNetworkNodeState {
+		return &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+			Spec:       sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{iface}},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{SyncStatus: syncStatus,
+				Interfaces: sriovnetworkv1.InterfaceExts{{PciAddress: iface.PciAddress}}},
+		}
+	}
+	vfs := func(policyName, resourceName, vfRange string) sriovnetworkv1.VfGroup {
+		return sriovnetworkv1.VfGroup{PolicyName: policyName, ResourceName: resourceName, VfRange: vfRange}
+	}
+	policy := func(name, resourceName string) *sriovnetworkv1.SriovNetworkNodePolicy {
+		return &sriovnetworkv1.SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+			Spec:       sriovnetworkv1.SriovNetworkNodePolicySpec{ResourceName: resourceName},
+		}
+	}
+
+	node1 := nodeState("node1", constants.SyncStatusSucceeded, sriovnetworkv1.Interface{PciAddress: "0000:86:00.0", NumVfs: 8,
+		VfGroups: []sriovnetworkv1.VfGroup{vfs("policy1", "nic1", "0-3"), vfs("policy2", "nic2", "4-7")}})
+	node2 := nodeState("node2", constants.SyncStatusFailed, sriovnetworkv1.Interface{PciAddress: "0000:86:00.0", NumVfs: 2,
+		VfGroups: []sriovnetworkv1.VfGroup{vfs("policy1", "nic1", "0-1")}})
+	node2.Status.LastSyncError = "failed to create the VFs"
+	node2.Status.Drain = &sriovnetworkv1.DrainStatus{}
+	node3 := nodeState("node3", constants.SyncStatusSucceeded, sriovnetworkv1.Interface{PciAddress: "0000:3b:00.0", NumVfs: 4,
+		PortSplit: 2, VfGroups: []sriovnetworkv1.VfGroup{vfs("policy2", "nic2", "0-3")}})
+	workers := &sriovnetworkv1.SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{NodeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"role": "worker"}}},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(node1, node2, node3, workers,
+			node("node1", "worker", 4), node("node2", "worker", 2),
+			node("node3", "edge", 0, corev1.NodeCondition{Type: constants.BiosSettingsConditionType,
+				Status: corev1.ConditionFalse, Reason: constants.BiosSettingsReasonRemediationPending}),
+			policy(constants.DefaultPolicyName, ""), policy("policy1", "nic1"), policy("policy2", "nic2"), policy("policy3", "nic3")).
+		WithStatusSubresource(&sriovnetworkv1.SriovClusterState{}).
+		Build()
+	reconciler := &SriovClusterStateReconciler{Client: c, Scheme: scheme}
+	req := ctrl.Request{}
+	req.Name = constants.DefaultConfigName
+
+	result, err := reconciler.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(clusterStateSyncInterval))
+
+	cs := &sriovnetworkv1.SriovClusterState{}
+	g.Expect(c.Get(ctx, req.NamespacedName, cs)).To(Succeed())
+	g.Expect(cs.Status.UpdateTime).ToNot(BeNil())
+	g.Expect(cs.Status.Nodes).To(Equal(3))
+	g.Expect(cs.Status.SyncedNodes).To(Equal(2))
+	g.Expect(cs.Status.Resources).To(Equal([]sriovnetworkv1.SriovClusterResource{
+		{ResourceName: "nic1", TotalVfs: 6, Allocatable: 6, Nodes: 2},
+		{ResourceName: "nic2", TotalVfs: 8, Nodes: 2},
+	}))
+	g.Expect(cs.Status.FailingNodes).To(Equal([]sriovnetworkv1.SriovClusterFailingNode{
+		{Name: "node2", LastSyncError: "failed to create the VFs"},
+	}))
+	g.Expect(cs.Status.PendingReboots).To(Equal([]sriovnetworkv1.SriovClusterPendingReboot{
+		{Name: "node3", Reason: "BIOS settings enabled through the BMC, port split of 0000:3b:00.0 into 2 ports"},
+	}))
+	g.Expect(cs.Status.Pools).To(Equal([]sriovnetworkv1.SriovClusterPool{
+		{Name: "workers", Nodes: []string{"node1", "node2"}, Draining: 1},
+	}))
+	g.Expect(cs.Status.Policies).To(Equal([]sriovnetworkv1.SriovClusterPolicy{
+		{Name: "policy1", ResourceName: "nic1", Nodes: []string{"node1", "node2"}},
+		{Name: "policy2", ResourceName: "nic2", Nodes: []string{"node1", "node3"}},
+		{Name: "policy3", ResourceName: "nic3"},
+	}))
+
+	// an unchanged report doesn't update the state
+	_, err = reconciler.Reconcile(ctx, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	unchanged := &sriovnetworkv1.SriovClusterState{}
+	g.Expect(c.Get(ctx, req.NamespacedName, unchanged)).To(Succeed())
+	g.Expect(unchanged.ResourceVersion).To(Equal(cs.ResourceVersion))
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"path/filepath"
 	"reflect"
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -35,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -85,8 +87,9 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// then lets add the finalizer and update the object. This is equivalent
 		// registering our finalizer.
 		if !sriovnetworkv1.StringInArray(sriovnetworkv1.NETATTDEFFINALIZERNAME, instance.ObjectMeta.Finalizers) {
+			patch := client.MergeFrom(instance.DeepCopy())
 			instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, sriovnetworkv1.NETATTDEFFINALIZERNAME)
-			if err := r.Update(ctx, instance); err != nil {
+			if err := r.Patch(ctx, instance, patch); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
@@ -102,9 +105,10 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			}
 			// remove our finalizer from the list and update it.
 			var found bool
+			patch := client.MergeFrom(instance.DeepCopy())
 			instance.ObjectMeta.Finalizers, found = sriovnetworkv1.RemoveString(sriovnetworkv1.NETATTDEFFINALIZERNAME, instance.ObjectMeta.Finalizers)
 			if found {
-				if err := r.Update(ctx, instance); err != nil {
+				if err := r.Patch(ctx, instance, patch); err != nil {
 					return reconcile.Result{}, err
 				}
 			}
@@ -126,6 +130,7 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		reqLogger.Error(err, "Couldn't process rendered NetworkAttachmentDefinition config", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 		return reconcile.Result{}, err
 	}
+	audit.Stamp(netAttDef, audit.Owner("SriovIBNetwork", instance), filepath.Clean(sriovnetworkv1.ManifestsPath))
 	if lnns, ok := instance.GetAnnotations()[sriovnetworkv1.LASTNETWORKNAMESPACE]; ok && netAttDef.GetNamespace() != lnns {
 		err = r.Delete(ctx, &netattdefv1.NetworkAttachmentDefinition{
 			ObjectMeta: metav1.ObjectMeta{
@@ -156,9 +161,16 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 				reqLogger.Error(err, "Couldn't create NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 				return reconcile.Result{}, err
 			}
-			anno := map[string]string{sriovnetworkv1.LASTNETWORKNAMESPACE: netAttDef.Namespace}
-			instance.SetAnnotations(anno)
-			if err := r.Update(ctx, instance); err != nil {
+			if err := audit.Record(ctx, r.Client, netAttDef); err != nil {
+				return reconcile.Result{}, err
+			}
+			// patch only our annotation so the ones set by other managers of the object are kept
+			patch := client.MergeFrom(instance.DeepCopy())
+			if instance.Annotations == nil {
+				instance.Annotations = map[string]string{}
+			}
+			instance.Annotations[sriovnetworkv1.LASTNETWORKNAMESPACE] = netAttDef.Namespace
+			if err := r.Patch(ctx, instance, patch); err != nil {
 				return reconcile.Result{}, err
 			}
 		} else {
@@ -167,6 +179,7 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	} else {
 		reqLogger.Info("NetworkAttachmentDefinition CR already exist")
+		audit.KeepHash(netAttDef, found)
 		if !reflect.DeepEqual(found.Spec, netAttDef.Spec) || !reflect.DeepEqual(found.GetAnnotations(), netAttDef.GetAnnotations()) {
 			reqLogger.Info("Update NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 			netAttDef.SetResourceVersion(found.GetResourceVersion())
@@ -175,6 +188,11 @@ func (r *SriovIBNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 				reqLogger.Error(err, "Couldn't update NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 				return reconcile.Result{}, err
 			}
+			if err := audit.Record(ctx, r.Client, netAttDef); err != nil {
+				return reconcile.Result{}, err
+			}
+		} else if err := audit.Adopt(ctx, r.Client, found); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 	return ctrl.Result{}, nil
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"path/filepath"
 	"reflect"
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -35,6 +36,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/fabric"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -47,6 +51,7 @@ type SriovNetworkReconciler struct {
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworks/finalizers,verbs=update
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworkgrants,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -58,15 +63,26 @@ type SriovNetworkReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	req.Namespace = vars.Namespace
-	reqLogger := log.FromContext(ctx).WithValues("sriovnetwork", req.NamespacedName)
-
-	reqLogger.Info("Reconciling SriovNetwork")
 	var err error
 
-	// Fetch the SriovNetwork instance
+	// Fetch the SriovNetwork instance, the SriovNetworks created by the namespace owners are in their namespace,
+	// the other ones in the operator namespace
 	instance := &sriovnetworkv1.SriovNetwork{}
-	err = r.Get(ctx, req.NamespacedName, instance)
+	if req.Namespace != vars.Namespace {
+		err = r.Get(ctx, req.NamespacedName, instance)
+		if err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		if errors.IsNotFound(err) {
+			req.Namespace = vars.Namespace
+		}
+	}
+	reqLogger := log.FromContext(ctx).WithValues("sriovnetwork", req.NamespacedName)
+
+	reqLogger.Info("Reconciling SriovNetwork")
+	if req.Namespace == vars.Namespace {
+		err = r.Get(ctx, req.NamespacedName, instance)
+	}
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -85,8 +101,9 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// then lets add the finalizer and update the object. This is equivalent
 		// registering our finalizer.
 		if !sriovnetworkv1.StringInArray(sriovnetworkv1.NETATTDEFFINALIZERNAME, instance.ObjectMeta.Finalizers) {
+			patch := client.MergeFrom(instance.DeepCopy())
 			instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, sriovnetworkv1.NETATTDEFFINALIZERNAME)
-			if err := r.Update(ctx, instance); err != nil {
+			if err := r.Patch(ctx, instance, patch); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
@@ -102,15 +119,27 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 			// remove our finalizer from the list and update it.
 			var found bool
+			patch := client.MergeFrom(instance.DeepCopy())
 			instance.ObjectMeta.Finalizers, found = sriovnetworkv1.RemoveString(sriovnetworkv1.NETATTDEFFINALIZERNAME, instance.ObjectMeta.Finalizers)
 			if found {
-				if err := r.Update(ctx, instance); err != nil {
+				if err := r.Patch(ctx, instance, patch); err != nil {
 					return reconcile.Result{}, err
 				}
 			}
 		}
 		return reconcile.Result{}, err
 	}
+	if instance.Namespace != vars.Namespace {
+		if err := r.validateGrant(ctx, instance); err != nil {
+			reqLogger.Error(err, "SriovNetwork is not granted, NetworkAttachmentDefinition is not rendered")
+			// the network is reconciled again when the grants or the namespace labels change
+			return reconcile.Result{}, r.deleteOwnedNetAttDef(ctx, instance)
+		}
+	}
+	if err := r.validateVlan(ctx, instance); err != nil {
+		reqLogger.Error(err, "VLAN validation against the fabric failed, NetworkAttachmentDefinition is not rendered", "vlan", instance.Spec.Vlan)
+		return reconcile.Result{}, err
+	}
 	raw, err := instance.RenderNetAttDef()
 	if err != nil {
 		return reconcile.Result{}, err
@@ -126,6 +155,7 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		reqLogger.Error(err, "Couldn't process rendered NetworkAttachmentDefinition config", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 		return reconcile.Result{}, err
 	}
+	audit.Stamp(netAttDef, audit.Owner("SriovNetwork", instance), filepath.Clean(sriovnetworkv1.ManifestsPath))
 	if lnns, ok := instance.GetAnnotations()[sriovnetworkv1.LASTNETWORKNAMESPACE]; ok && netAttDef.GetNamespace() != lnns {
 		err = r.Delete(ctx, &netattdefv1.NetworkAttachmentDefinition{
 			ObjectMeta: metav1.ObjectMeta{
@@ -156,9 +186,16 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				reqLogger.Error(err, "Couldn't create NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 				return reconcile.Result{}, err
 			}
-			anno := map[string]string{sriovnetworkv1.LASTNETWORKNAMESPACE: netAttDef.Namespace}
-			instance.SetAnnotations(anno)
-			if err := r.Update(ctx, instance); err != nil {
+			if err := audit.Record(ctx, r.Client, netAttDef); err != nil {
+				return reconcile.Result{}, err
+			}
+			// patch only our annotation so the ones set by other managers of the object are kept
+			patch := client.MergeFrom(instance.DeepCopy())
+			if instance.Annotations == nil {
+				instance.Annotations = map[string]string{}
+			}
+			instance.Annotations[sriovnetworkv1.LASTNETWORKNAMESPACE] = netAttDef.Namespace
+			if err := r.Patch(ctx, instance, patch); err != nil {
 				return reconcile.Result{}, err
 			}
 		} else {
@@ -167,6 +204,7 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	} else {
 		reqLogger.Info("NetworkAttachmentDefinition CR already exist")
+		audit.KeepHash(netAttDef, found)
 		if !reflect.DeepEqual(found.Spec, netAttDef.Spec) || !reflect.DeepEqual(found.GetAnnotations(), netAttDef.GetAnnotations()) {
 			reqLogger.Info("Update NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 			netAttDef.SetResourceVersion(found.GetResourceVersion())
@@ -175,26 +213,133 @@ func (r *SriovNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				reqLogger.Error(err, "Couldn't update NetworkAttachmentDefinition CR", "Namespace", netAttDef.Namespace, "Name", netAttDef.Name)
 				return reconcile.Result{}, err
 			}
+			if err := audit.Record(ctx, r.Client, netAttDef); err != nil {
+				return reconcile.Result{}, err
+			}
+		} else if err := audit.Adopt(ctx, r.Client, found); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// deleteOwnedNetAttDef deletes the NetworkAttachmentDefinition of a SriovNetwork created outside of the operator
+// namespace if it was rendered from it, the namespace owner may have created one with the same name
+func (r *SriovNetworkReconciler) deleteOwnedNetAttDef(ctx context.Context, instance *sriovnetworkv1.SriovNetwork) error {
+	found := &netattdefv1.NetworkAttachmentDefinition{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if found.GetAnnotations()[constants.OwnerAnnotation] != audit.Owner("SriovNetwork", instance) {
+		log.FromContext(ctx).Info("NetworkAttachmentDefinition not rendered from the SriovNetwork, keeping it",
+			"Namespace", found.Namespace, "Name", found.Name)
+		return nil
+	}
+	return r.Delete(ctx, found, client.Preconditions{UID: &found.UID})
+}
+
+// validateGrant checks a SriovNetwork created outside of the operator namespace against the SriovNetworkGrants
+func (r *SriovNetworkReconciler) validateGrant(ctx context.Context, instance *sriovnetworkv1.SriovNetwork) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: instance.Namespace}, ns); err != nil {
+		return err
+	}
+	grants := &sriovnetworkv1.SriovNetworkGrantList{}
+	if err := r.List(ctx, grants); err != nil {
+		return err
+	}
+	return sriovnetworkv1.ValidateTenantNetwork(instance, ns, grants.Items)
+}
+
+// validateVlan checks the VLAN of the network with the fabric validation hook configured
+// in the default SriovOperatorConfig, if any
+func (r *SriovNetworkReconciler) validateVlan(ctx context.Context, instance *sriovnetworkv1.SriovNetwork) error {
+	if instance.Spec.Vlan == 0 {
+		return nil
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{}
+	err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultConfigName, Namespace: vars.Namespace}, config)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return fabric.ValidateNetworkVlan(ctx, config.Spec.VlanValidation, instance)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SriovNetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Reconcile when the target namespace is created after the SriovNetwork object.
+	// Reconcile the SriovNetworks of a namespace when its labels, selected by the grants, change.
 	namespaceHandler := handler.Funcs{
 		CreateFunc: r.namespaceHandlerCreate,
+		UpdateFunc: r.namespaceHandlerUpdate,
+	}
+	// Reconcile the SriovNetworks created outside of the operator namespace when the grants change.
+	grantHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTenantNetworks(ctx, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTenantNetworks(ctx, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTenantNetworks(ctx, q)
+		},
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sriovnetworkv1.SriovNetwork{}).
 		Watches(&netattdefv1.NetworkAttachmentDefinition{}, &handler.EnqueueRequestForObject{}).
 		Watches(&corev1.Namespace{}, &namespaceHandler).
+		Watches(&sriovnetworkv1.SriovNetworkGrant{}, &grantHandler).
 		Complete(r)
 }
 
+func (r *SriovNetworkReconciler) namespaceHandlerUpdate(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+		return
+	}
+	networkList := sriovnetworkv1.SriovNetworkList{}
+	err := r.List(ctx, &networkList, client.InNamespace(e.ObjectNew.GetName()))
+	if err != nil {
+		log.Log.WithName("SriovNetworkReconciler").
+			Info("Can't list SriovNetworks in namespace", "namespace", e.ObjectNew.GetName(), "error", err)
+		return
+	}
+	for _, network := range networkList.Items {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: network.Namespace,
+			Name:      network.Name,
+		}})
+	}
+}
+
+// enqueueTenantNetworks reconciles the SriovNetworks created outside of the operator namespace
+func (r *SriovNetworkReconciler) enqueueTenantNetworks(ctx context.Context, q workqueue.RateLimitingInterface) {
+	networkList := sriovnetworkv1.SriovNetworkList{}
+	err := r.List(ctx, &networkList)
+	if err != nil {
+		log.Log.WithName("SriovNetworkReconciler").Info("Can't list SriovNetworks", "error", err)
+		return
+	}
+	for _, network := range networkList.Items {
+		if network.Namespace == vars.Namespace {
+			continue
+		}
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: network.Namespace,
+			Name:      network.Name,
+		}})
+	}
+}
+
 func (r *SriovNetworkReconciler) namespaceHandlerCreate(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
 	networkList := sriovnetworkv1.SriovNetworkList{}
 	err := r.List(ctx,
@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
+	"testing"
 	"time"
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -362,3 +371,77 @@ func generateExpectedNetConfig(cr *sriovnetworkv1.SriovNetwork) string {
 	}
 	return configStr
 }
+
+func TestSriovNetworkTenantGrant(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	// the test may run after the envtest suite changed the working directory
+	_, file, _, _ := runtime.Caller(0)
+	manifestsPath := sriovnetworkv1.ManifestsPath
+	sriovnetworkv1.ManifestsPath = filepath.Join(filepath.Dir(file), "..", "bindata", "manifests", "cni-config")
+	defer func() { sriovnetworkv1.ManifestsPath = manifestsPath }()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"team": "a"}}}
+	grant := &sriovnetworkv1.SriovNetworkGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+		Spec: sriovnetworkv1.SriovNetworkGrantSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			ResourceNames:     []string{"nic1"},
+			VlanRanges:        []sriovnetworkv1.VlanRange{"100-199"},
+			IPAMBlocks:        []string{"10.10.0.0/16"},
+		},
+	}
+	granted := &sriovnetworkv1.SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "granted", Namespace: ns.Name},
+		Spec: sriovnetworkv1.SriovNetworkSpec{
+			ResourceName: "nic1",
+			Vlan:         100,
+			IPAM:         `{"type":"host-local","subnet":"10.10.1.0/24"}`,
+		},
+	}
+	denied := &sriovnetworkv1.SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "denied", Namespace: ns.Name},
+		Spec: sriovnetworkv1.SriovNetworkSpec{
+			ResourceName: "nic1",
+			Vlan:         300,
+		},
+	}
+
+	s := k8sruntime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(s))
+	utilruntime.Must(netattdefv1.AddToScheme(s))
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ns, grant, granted, denied).Build()
+	reconciler := &SriovNetworkReconciler{Client: c, Scheme: s}
+
+	reconcileNetwork := func(network *sriovnetworkv1.SriovNetwork) {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: dynclient.ObjectKeyFromObject(network)})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	// the NetworkAttachmentDefinition of a granted network is rendered in its namespace
+	reconcileNetwork(granted)
+	nad := &netattdefv1.NetworkAttachmentDefinition{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: granted.Name, Namespace: ns.Name}, nad)).To(Succeed())
+	g.Expect(nad.Spec.Config).To(ContainSubstring(`"vlan": 100`))
+
+	// a network using a VLAN not granted is not rendered
+	reconcileNetwork(denied)
+	err := c.Get(ctx, types.NamespacedName{Name: denied.Name, Namespace: ns.Name}, &netattdefv1.NetworkAttachmentDefinition{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// the NetworkAttachmentDefinitions not rendered from the network are kept
+	g.Expect(c.Create(ctx, &netattdefv1.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: denied.Name, Namespace: ns.Name},
+	})).To(Succeed())
+	reconcileNetwork(denied)
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: denied.Name, Namespace: ns.Name}, &netattdefv1.NetworkAttachmentDefinition{})).To(Succeed())
+
+	// the NetworkAttachmentDefinition is removed when the grant no longer selects the namespace
+	ns.Labels = map[string]string{"team": "b"}
+	g.Expect(c.Update(ctx, ns)).To(Succeed())
+	reconcileNetwork(granted)
+	err = c.Get(ctx, types.NamespacedName{Name: granted.Name, Namespace: ns.Name}, &netattdefv1.NetworkAttachmentDefinition{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+}
@@ -35,19 +35,21 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 
 	dptypes "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/types"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/render"
 )
@@ -63,6 +65,8 @@ type SriovNetworkNodePolicyReconciler struct {
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodepolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodepolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodepolicies/finalizers,verbs=update
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworkresourcemaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworkresourcemaps/status,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -121,33 +125,37 @@ func (r *SriovNetworkNodePolicyReconciler) Reconcile(ctx context.Context, req ct
 	}
 	// Fetch the Nodes
 	nodeList := &corev1.NodeList{}
-	lo := &client.MatchingLabels{
-		"node-role.kubernetes.io/worker": "",
-		"kubernetes.io/os":               "linux",
-	}
 	defaultOpConf := &sriovnetworkv1.SriovOperatorConfig{}
 	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, defaultOpConf); err != nil {
 		return reconcile.Result{}, err
 	}
-	if len(defaultOpConf.Spec.ConfigDaemonNodeSelector) > 0 {
-		labels := client.MatchingLabels(defaultOpConf.Spec.ConfigDaemonNodeSelector)
-		lo = &labels
-	}
-	err = r.List(ctx, nodeList, lo)
+	err = r.List(ctx, nodeList, client.MatchingLabels(configDaemonNodeSelector(defaultOpConf)))
 	if err != nil {
 		// Error reading the object - requeue the request.
 		reqLogger.Error(err, "Fail to list nodes")
 		return reconcile.Result{}, err
 	}
+	// the device plugin doesn't run on the nodes whose operating system is not supported
+	supportedNodes := nodeList.Items[:0]
+	for _, node := range nodeList.Items {
+		if sriovnetworkv1.IsSupportedNode(&node) {
+			supportedNodes = append(supportedNodes, node)
+		}
+	}
+	nodeList.Items = supportedNodes
 
 	// Sort the policies with priority, higher priority ones is applied later
 	sort.Sort(sriovnetworkv1.ByPriority(policyList.Items))
-	// Sync SriovNetworkNodeState objects
-	if err = r.syncAllSriovNetworkNodeStates(ctx, defaultPolicy, policyList, nodeList); err != nil {
+	// the resources of the policies whose signature isn't verified are not advertised
+	policyList.Items, _ = policySignatures.Filter(ctx, defaultOpConf.Spec.PolicySignature, policyList.Items)
+	// SriovNetworkNodeState objects are synced per node by the SriovNetworkNodeStateReconciler
+	// Sync Sriov device plugin ConfigMap object
+	nodeResources, err := r.syncDevicePluginConfigMap(ctx, defaultOpConf, policyList, nodeList)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	// Sync Sriov device plugin ConfigMap object
-	if err = r.syncDevicePluginConfigMap(ctx, defaultOpConf, policyList, nodeList); err != nil {
+	// Sync the SriovNetworkResourceMap of the resources advertised by the device plugin
+	if err = r.syncResourceMap(ctx, defaultOpConf, policyList, nodeList, nodeResources); err != nil {
 		return reconcile.Result{}, err
 	}
 	// Render and sync Daemon objects
@@ -187,28 +195,64 @@ func (r *SriovNetworkNodePolicyReconciler) SetupWithManager(mgr ctrl.Manager) er
 		},
 	}
 
+	// the device plugin is re-rendered when the overlays or the policy signature of the operator config change
+	overlaysEventHandler := handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldConfig, okOld := e.ObjectOld.(*sriovnetworkv1.SriovOperatorConfig)
+			newConfig, okNew := e.ObjectNew.(*sriovnetworkv1.SriovOperatorConfig)
+			if !okOld || !okNew || (equality.Semantic.DeepEqual(oldConfig.Spec.Overlays, newConfig.Spec.Overlays) &&
+				equality.Semantic.DeepEqual(oldConfig.Spec.PolicySignature, newConfig.Spec.PolicySignature)) {
+				return
+			}
+			log.Log.WithName("SriovNetworkNodePolicy").
+				Info("Enqueuing sync for overlays update event", "resource", e.ObjectNew.GetName())
+			qHandler(q)
+		},
+	}
+
+	// the resources of the device plugin of a node depend on the policies selecting it, they are re-rendered when
+	// a node is added, relabeled or removed so a node leaving a policy nodeSelector stops advertising its resource
+	nodeEventHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			qHandler(q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			log.Log.WithName("SriovNetworkNodePolicy").
+				Info("Enqueuing sync for node labels update event", "node", e.ObjectNew.GetName())
+			qHandler(q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			qHandler(q)
+		},
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sriovnetworkv1.SriovNetworkNodePolicy{}).
 		Watches(&sriovnetworkv1.SriovNetworkNodePolicy{}, delayedEventHandler).
+		Watches(&sriovnetworkv1.SriovOperatorConfig{}, overlaysEventHandler).
+		Watches(&corev1.Node{}, nodeEventHandler, builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Complete(r)
 }
 
+// syncDevicePluginConfigMap syncs the device plugin ConfigMap and returns the resources rendered for each node
 func (r *SriovNetworkNodePolicyReconciler) syncDevicePluginConfigMap(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig,
-	pl *sriovnetworkv1.SriovNetworkNodePolicyList, nl *corev1.NodeList) error {
+	pl *sriovnetworkv1.SriovNetworkNodePolicyList, nl *corev1.NodeList) (map[string]dptypes.ResourceConfList, error) {
 	logger := log.Log.WithName("syncDevicePluginConfigMap")
 	logger.V(1).Info("Start to sync device plugin ConfigMap")
 
 	configData := make(map[string]string)
+	nodeResources := make(map[string]dptypes.ResourceConfList)
 	for _, node := range nl.Items {
 		data, err := r.renderDevicePluginConfigData(ctx, pl, &node)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		config, err := json.Marshal(data)
+		config, err := marshalDevicePluginConfigData(data, pl)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		configData[node.Name] = string(config)
+		nodeResources[node.Name] = data
 	}
 
 	cm := &corev1.ConfigMap{
@@ -223,8 +267,9 @@ func (r *SriovNetworkNodePolicyReconciler) syncDevicePluginConfigMap(ctx context
 		Data: configData,
 	}
 
+	audit.Stamp(cm, audit.Owner("SriovOperatorConfig", dc), constants.SourceGenerated)
 	if err := controllerutil.SetControllerReference(dc, cm, r.Scheme); err != nil {
-		return err
+		return nil, err
 	}
 
 	found := &corev1.ConfigMap{}
@@ -233,136 +278,106 @@ func (r *SriovNetworkNodePolicyReconciler) syncDevicePluginConfigMap(ctx context
 		if errors.IsNotFound(err) {
 			err = r.Create(ctx, cm)
 			if err != nil {
-				return fmt.Errorf("couldn't create ConfigMap: %v", err)
+				return nil, fmt.Errorf("couldn't create ConfigMap: %v", err)
 			}
 			logger.V(1).Info("Created ConfigMap for", cm.Namespace, cm.Name)
 		} else {
-			return fmt.Errorf("failed to get ConfigMap: %v", err)
+			return nil, fmt.Errorf("failed to get ConfigMap: %v", err)
 		}
 	} else {
+		if equality.Semantic.DeepEqual(cm.Data, found.Data) {
+			logger.V(1).Info("ConfigMap did not change, not updating")
+			return nodeResources, audit.Adopt(ctx, r.Client, found)
+		}
 		logger.V(1).Info("ConfigMap already exists, updating")
+		audit.KeepHash(cm, found)
 		err = r.Update(ctx, cm)
 		if err != nil {
-			return fmt.Errorf("couldn't update ConfigMap: %v", err)
+			return nil, fmt.Errorf("couldn't update ConfigMap: %v", err)
 		}
 	}
-	return nil
+	return nodeResources, audit.Record(ctx, r.Client, cm)
 }
 
-func (r *SriovNetworkNodePolicyReconciler) syncAllSriovNetworkNodeStates(ctx context.Context, np *sriovnetworkv1.SriovNetworkNodePolicy, npl *sriovnetworkv1.SriovNetworkNodePolicyList, nl *corev1.NodeList) error {
-	logger := log.Log.WithName("syncAllSriovNetworkNodeStates")
-	logger.V(1).Info("Start to sync all SriovNetworkNodeState custom resource")
-	found := &corev1.ConfigMap{}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.ConfigMapName}, found); err != nil {
-		logger.V(1).Info("Fail to get", "ConfigMap", constants.ConfigMapName)
-	}
-	for _, node := range nl.Items {
-		logger.V(1).Info("Sync SriovNetworkNodeState CR", "name", node.Name)
-		ns := &sriovnetworkv1.SriovNetworkNodeState{}
-		ns.Name = node.Name
-		ns.Namespace = vars.Namespace
-		j, _ := json.Marshal(ns)
-		logger.V(2).Info("SriovNetworkNodeState CR", "content", j)
-		if err := r.syncSriovNetworkNodeState(ctx, np, npl, ns, &node, utils.HashConfigMap(found)); err != nil {
-			logger.Error(err, "Fail to sync", "SriovNetworkNodeState", ns.Name)
-			return err
-		}
-	}
-	logger.V(1).Info("Remove SriovNetworkNodeState custom resource for unselected node")
-	nsList := &sriovnetworkv1.SriovNetworkNodeStateList{}
-	err := r.List(ctx, nsList, &client.ListOptions{})
+// syncResourceMap maintains the default SriovNetworkResourceMap, mapping each resource of the device plugin to the
+// policies defining it and to the nodes advertising it for each of them
+func (r *SriovNetworkNodePolicyReconciler) syncResourceMap(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig,
+	pl *sriovnetworkv1.SriovNetworkNodePolicyList, nl *corev1.NodeList, nodeResources map[string]dptypes.ResourceConfList) error {
+	logger := log.Log.WithName("syncResourceMap")
+	logger.V(1).Info("Start to sync SriovNetworkResourceMap")
+
+	status := renderResourceMap(pl, nl, nodeResources)
+	rm := &sriovnetworkv1.SriovNetworkResourceMap{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, rm)
 	if err != nil {
 		if !errors.IsNotFound(err) {
-			logger.Error(err, "Fail to list SriovNetworkNodeState CRs")
+			return fmt.Errorf("failed to get SriovNetworkResourceMap: %v", err)
+		}
+		rm.Name = constants.DefaultConfigName
+		rm.Namespace = vars.Namespace
+		if err := controllerutil.SetControllerReference(dc, rm, r.Scheme); err != nil {
 			return err
 		}
-	} else {
-		for _, ns := range nsList.Items {
-			found := false
-			for _, node := range nl.Items {
-				if ns.GetName() == node.GetName() {
-					found = true
-					break
-				}
-			}
-			if !found {
-				err := r.Delete(ctx, &ns, &client.DeleteOptions{})
-				if err != nil {
-					logger.Error(err, "Fail to Delete", "SriovNetworkNodeState CR:", ns.GetName())
-					return err
-				}
-			}
+		if err := r.Create(ctx, rm); err != nil {
+			return fmt.Errorf("couldn't create SriovNetworkResourceMap: %v", err)
 		}
+		logger.V(1).Info("Created SriovNetworkResourceMap", "namespace", rm.Namespace, "name", rm.Name)
+	}
+	// the pools are maintained by the SriovNetworkResourceMap controller
+	if equality.Semantic.DeepEqual(rm.Status.Resources, status.Resources) {
+		return nil
+	}
+	rm.Status.Resources = status.Resources
+	if err := r.Status().Update(ctx, rm); err != nil {
+		return fmt.Errorf("couldn't update SriovNetworkResourceMap status: %v", err)
 	}
 	return nil
 }
 
-func (r *SriovNetworkNodePolicyReconciler) syncSriovNetworkNodeState(ctx context.Context, np *sriovnetworkv1.SriovNetworkNodePolicy, npl *sriovnetworkv1.SriovNetworkNodePolicyList, ns *sriovnetworkv1.SriovNetworkNodeState, node *corev1.Node, cksum string) error {
-	logger := log.Log.WithName("syncSriovNetworkNodeState")
-	logger.V(1).Info("Start to sync SriovNetworkNodeState", "Name", ns.Name, "cksum", cksum)
-
-	if err := controllerutil.SetControllerReference(np, ns, r.Scheme); err != nil {
-		return err
-	}
-	found := &sriovnetworkv1.SriovNetworkNodeState{}
-	err := r.Get(ctx, types.NamespacedName{Namespace: ns.Namespace, Name: ns.Name}, found)
-	if err != nil {
-		logger.Error(err, "Fail to get SriovNetworkNodeState", "namespace", ns.Namespace, "name", ns.Name)
-		if errors.IsNotFound(err) {
-			ns.Spec.DpConfigVersion = cksum
-			err = r.Create(ctx, ns)
-			if err != nil {
-				return fmt.Errorf("couldn't create SriovNetworkNodeState: %v", err)
-			}
-			logger.Info("Created SriovNetworkNodeState for", ns.Namespace, ns.Name)
-		} else {
-			return fmt.Errorf("failed to get SriovNetworkNodeState: %v", err)
+// renderResourceMap maps the resources of the policies to the policies defining them and each policy to the nodes
+// whose device plugin advertises the resource for it
+func renderResourceMap(pl *sriovnetworkv1.SriovNetworkNodePolicyList, nl *corev1.NodeList,
+	nodeResources map[string]dptypes.ResourceConfList) sriovnetworkv1.SriovNetworkResourceMapStatus {
+	resources := map[string]map[string][]string{}
+	for _, p := range pl.Items {
+		if p.Name == constants.DefaultPolicyName || p.Spec.SystemReserved {
+			continue
 		}
-	} else {
-		if len(found.Status.Interfaces) == 0 {
-			logger.Info("SriovNetworkNodeState Status Interfaces are empty. Skip update of policies in spec",
-				"namespace", ns.Namespace, "name", ns.Name)
-			return nil
-		}
-
-		logger.V(1).Info("SriovNetworkNodeState already exists, updating")
-		newVersion := found.DeepCopy()
-		newVersion.Spec = ns.Spec
-
-		// Previous Policy Priority(ppp) records the priority of previous evaluated policy in node policy list.
-		// Since node policy list is already sorted with priority number, comparing current priority with ppp shall
-		// be sufficient.
-		// ppp is set to 100 as initial value to avoid matching with the first policy in policy list, although
-		// it should not matter since the flag used in p.Apply() will only be applied when VF partition is detected.
-		ppp := 100
-		for _, p := range npl.Items {
-			if p.Name == constants.DefaultPolicyName {
-				continue
-			}
-			if p.Selected(node) {
-				logger.Info("apply", "policy", p.Name, "node", node.Name)
-				// Merging only for policies with the same priority (ppp == p.Spec.Priority)
-				// This boolean flag controls merging of PF configuration (e.g. mtu, numvfs etc)
-				// when VF partition is configured.
-				err = p.Apply(newVersion, ppp == p.Spec.Priority)
-				if err != nil {
-					return err
+		if resources[p.Spec.ResourceName] == nil {
+			resources[p.Spec.ResourceName] = map[string][]string{}
+		}
+		resources[p.Spec.ResourceName][p.Name] = nil
+	}
+	for _, node := range nl.Items {
+		for _, rc := range nodeResources[node.Name].ResourceList {
+			for _, p := range pl.Items {
+				if p.Name == constants.DefaultPolicyName || p.Spec.ResourceName != rc.ResourceName || !p.Selected(&node) {
+					continue
 				}
-				// record the evaluated policy priority for next loop
-				ppp = p.Spec.Priority
+				resources[rc.ResourceName][p.Name] = append(resources[rc.ResourceName][p.Name], node.Name)
 			}
 		}
-		newVersion.Spec.DpConfigVersion = cksum
-		if equality.Semantic.DeepEqual(newVersion.Spec, found.Spec) {
-			logger.V(1).Info("SriovNetworkNodeState did not change, not updating")
-			return nil
+	}
+
+	status := sriovnetworkv1.SriovNetworkResourceMapStatus{}
+	for resourceName, policies := range resources {
+		resource := sriovnetworkv1.SriovNetworkResource{
+			ResourceName:         resourceName,
+			ExtendedResourceName: string(sriovnetworkv1.ExtendedResourceName(resourceName)),
 		}
-		err = r.Update(ctx, newVersion)
-		if err != nil {
-			return fmt.Errorf("couldn't update SriovNetworkNodeState: %v", err)
+		for policyName, nodes := range policies {
+			sort.Strings(nodes)
+			resource.Policies = append(resource.Policies, sriovnetworkv1.SriovNetworkResourcePolicy{Name: policyName, Nodes: nodes})
 		}
+		sort.Slice(resource.Policies, func(i, j int) bool {
+			return resource.Policies[i].Name < resource.Policies[j].Name
+		})
+		status.Resources = append(status.Resources, resource)
 	}
-	return nil
+	sort.Slice(status.Resources, func(i, j int) bool {
+		return status.Resources[i].ResourceName < status.Resources[j].ResourceName
+	})
+	return status
 }
 
 func setDsNodeAffinity(pl *sriovnetworkv1.SriovNetworkNodePolicyList, ds *appsv1.DaemonSet) error {
@@ -428,6 +443,10 @@ func (r *SriovNetworkNodePolicyReconciler) renderDevicePluginConfigData(ctx cont
 		if p.Name == constants.DefaultPolicyName {
 			continue
 		}
+		// the reserved VFs are left to the system components of the node
+		if p.Spec.SystemReserved {
+			continue
+		}
 
 		// render node specific data for device plugin config
 		if !p.Selected(node) {
@@ -440,6 +459,12 @@ func (r *SriovNetworkNodePolicyReconciler) renderDevicePluginConfigData(ctx cont
 			return rcl, err
 		}
 
+		// don't advertise the resource on nodes where none of the selected PFs is connected
+		if (hasNodeStateSelector(&p) || p.Spec.ExposePfs) && len(nodeStateRootDevices(&p, nodeState)) == 0 {
+			logger.V(1).Info("No connected PF found for policy, skipping resource", "policy", p.Name, "node", node.Name)
+			continue
+		}
+
 		found, i := resourceNameInList(p.Spec.ResourceName, &rcl)
 
 		if found {
@@ -460,6 +485,43 @@ func (r *SriovNetworkNodePolicyReconciler) renderDevicePluginConfigData(ctx cont
 	return rcl, nil
 }
 
+// devicePluginAllocatePolicies maps the allocation policies of the SriovNetworkNodePolicy to the ones of the device
+// plugin, the device plugin has no spread allocation and the webhook rejects it
+var devicePluginAllocatePolicies = map[string]string{
+	sriovnetworkv1.AllocationPolicyPack: "packed",
+}
+
+// devicePluginResourceConfig adds the VF allocation policy, unknown to the vendored device plugin types,
+// to the resource configuration
+type devicePluginResourceConfig struct {
+	dptypes.ResourceConfig
+	AllocatePolicy string `json:"allocatePolicy,omitempty"`
+}
+
+type devicePluginResourceConfList struct {
+	ResourceList []devicePluginResourceConfig `json:"resourceList"`
+}
+
+// marshalDevicePluginConfigData marshals the device plugin configuration of a node with the allocation policy
+// of the policies of each resource, the webhook ensures the policies of a resource agree on it
+func marshalDevicePluginConfigData(rcl dptypes.ResourceConfList, pl *sriovnetworkv1.SriovNetworkNodePolicyList) ([]byte, error) {
+	allocatePolicies := map[string]string{}
+	for _, p := range pl.Items {
+		if p.Spec.AllocationPolicy != "" {
+			allocatePolicies[p.Spec.ResourceName] = devicePluginAllocatePolicies[p.Spec.AllocationPolicy]
+		}
+	}
+
+	data := devicePluginResourceConfList{}
+	for _, rc := range rcl.ResourceList {
+		data.ResourceList = append(data.ResourceList, devicePluginResourceConfig{
+			ResourceConfig: rc,
+			AllocatePolicy: allocatePolicies[rc.ResourceName],
+		})
+	}
+	return json.Marshal(data)
+}
+
 func resourceNameInList(name string, rcl *dptypes.ResourceConfList) (bool, int) {
 	for i, rc := range rcl.ResourceList {
 		if rc.ResourceName == name {
@@ -497,8 +559,8 @@ func createDevicePluginResource(
 			netDeviceSelectors.Devices = append(netDeviceSelectors.Devices, deviceID)
 		}
 	}
-	if len(p.Spec.NicSelector.PfNames) > 0 {
-		netDeviceSelectors.PfNames = append(netDeviceSelectors.PfNames, p.Spec.NicSelector.PfNames...)
+	if len(p.Spec.NicSelector.PfNames) > 0 && !p.Spec.ExposePfs {
+		netDeviceSelectors.PfNames = append(netDeviceSelectors.PfNames, renamedPfNames(p.Spec.NicSelector.PfNames, nodeState)...)
 	}
 	// vfio-pci device link type is not detectable
 	if p.Spec.DeviceType != constants.DeviceTypeVfioPci {
@@ -510,8 +572,13 @@ func createDevicePluginResource(
 			netDeviceSelectors.LinkTypes = sriovnetworkv1.UniqueAppend(netDeviceSelectors.LinkTypes, linkType)
 		}
 	}
-	if len(p.Spec.NicSelector.RootDevices) > 0 {
-		netDeviceSelectors.RootDevices = append(netDeviceSelectors.RootDevices, p.Spec.NicSelector.RootDevices...)
+	if p.Spec.ExposePfs {
+		netDeviceSelectors.PciAddresses = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PciAddresses, nodeStateRootDevices(p, nodeState)...)
+	} else if len(p.Spec.NicSelector.RootDevices) > 0 {
+		netDeviceSelectors.RootDevices = append(netDeviceSelectors.RootDevices, sriovnetworkv1.NormalizePciAddresses(p.Spec.NicSelector.RootDevices)...)
+	}
+	if hasNodeStateSelector(p) && !p.Spec.ExposePfs {
+		netDeviceSelectors.RootDevices = sriovnetworkv1.UniqueAppend(netDeviceSelectors.RootDevices, nodeStateRootDevices(p, nodeState)...)
 	}
 	// Removed driver constraint for "netdevice" DeviceType
 	if p.Spec.DeviceType == constants.DeviceTypeVfioPci {
@@ -524,7 +591,7 @@ func createDevicePluginResource(
 			return nil, fmt.Errorf("node state %s doesn't contain interfaces data", nodeState.Name)
 		}
 		for _, intf := range nodeState.Status.Interfaces {
-			if sriovnetworkv1.NetFilterMatch(p.Spec.NicSelector.NetFilter, intf.NetFilter) {
+			if intf.NetFilterMatch(p.Spec.NicSelector.NetFilter) {
 				// Found a match add the Interfaces PciAddress
 				netDeviceSelectors.PciAddresses = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PciAddresses, intf.PciAddress)
 			}
@@ -569,8 +636,8 @@ func updateDevicePluginResource(
 			netDeviceSelectors.Devices = append(netDeviceSelectors.Devices, deviceID)
 		}
 	}
-	if len(p.Spec.NicSelector.PfNames) > 0 {
-		netDeviceSelectors.PfNames = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PfNames, p.Spec.NicSelector.PfNames...)
+	if len(p.Spec.NicSelector.PfNames) > 0 && !p.Spec.ExposePfs {
+		netDeviceSelectors.PfNames = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PfNames, renamedPfNames(p.Spec.NicSelector.PfNames, nodeState)...)
 	}
 	// vfio-pci device link type is not detectable
 	if p.Spec.DeviceType != constants.DeviceTypeVfioPci {
@@ -584,8 +651,13 @@ func updateDevicePluginResource(
 			}
 		}
 	}
-	if len(p.Spec.NicSelector.RootDevices) > 0 {
-		netDeviceSelectors.RootDevices = sriovnetworkv1.UniqueAppend(netDeviceSelectors.RootDevices, p.Spec.NicSelector.RootDevices...)
+	if p.Spec.ExposePfs {
+		netDeviceSelectors.PciAddresses = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PciAddresses, nodeStateRootDevices(p, nodeState)...)
+	} else if len(p.Spec.NicSelector.RootDevices) > 0 {
+		netDeviceSelectors.RootDevices = sriovnetworkv1.UniqueAppend(netDeviceSelectors.RootDevices, sriovnetworkv1.NormalizePciAddresses(p.Spec.NicSelector.RootDevices)...)
+	}
+	if hasNodeStateSelector(p) && !p.Spec.ExposePfs {
+		netDeviceSelectors.RootDevices = sriovnetworkv1.UniqueAppend(netDeviceSelectors.RootDevices, nodeStateRootDevices(p, nodeState)...)
 	}
 	// Removed driver constraint for "netdevice" DeviceType
 	if p.Spec.DeviceType == constants.DeviceTypeVfioPci {
@@ -595,7 +667,7 @@ func updateDevicePluginResource(
 	if p.Spec.NicSelector.NetFilter != "" {
 		// Loop through interfaces status to find a match for NetworkID or NetworkTag
 		for _, intf := range nodeState.Status.Interfaces {
-			if sriovnetworkv1.NetFilterMatch(p.Spec.NicSelector.NetFilter, intf.NetFilter) {
+			if intf.NetFilterMatch(p.Spec.NicSelector.NetFilter) {
 				// Found a match add the Interfaces PciAddress
 				netDeviceSelectors.PciAddresses = sriovnetworkv1.UniqueAppend(netDeviceSelectors.PciAddresses, intf.PciAddress)
 			}
@@ -613,3 +685,42 @@ func updateDevicePluginResource(
 
 	return nil
 }
+
+// hasNodeStateSelector returns true if the policy selects PFs by link state, transceiver presence or zPCI UID
+func hasNodeStateSelector(p *sriovnetworkv1.SriovNetworkNodePolicy) bool {
+	return p.Spec.NicSelector.LinkUp || p.Spec.NicSelector.TransceiverPresent || len(p.Spec.NicSelector.ZpciUids) > 0
+}
+
+// nodeStateRootDevices returns the PCI addresses of the PFs reported in the node state
+// that are selected by the policy, including the link state, transceiver and zPCI UID predicates.
+// The PFs once selected by the link state and transceiver predicates stay selected until the policy changes.
+// The device plugin has no notion of the PF link nor of the zPCI UID, so the selection is pinned to these PFs.
+func nodeStateRootDevices(p *sriovnetworkv1.SriovNetworkNodePolicy, nodeState *sriovnetworkv1.SriovNetworkNodeState) []string {
+	rootDevices := []string{}
+	for i := range nodeState.Status.Interfaces {
+		if p.SelectedPf(nodeState, &nodeState.Status.Interfaces[i]) {
+			rootDevices = append(rootDevices, nodeState.Status.Interfaces[i].PciAddress)
+		}
+	}
+	return rootDevices
+}
+
+// renamedPfNames returns the pfNames of the nicSelector of a policy with the new names of the PFs of the node state
+// renamed by the pfNamePrefix of their policies, the device plugin only knows the PFs by their current names
+func renamedPfNames(pfNames []string, nodeState *sriovnetworkv1.SriovNetworkNodeState) []string {
+	names := append([]string{}, pfNames...)
+	for _, pfName := range pfNames {
+		name, vfRange, _ := strings.Cut(pfName, "#")
+		for _, iface := range nodeState.Status.Interfaces {
+			if iface.OriginalName != name || iface.Name == name {
+				continue
+			}
+			if vfRange != "" {
+				names = sriovnetworkv1.UniqueAppend(names, iface.Name+"#"+vfRange)
+			} else {
+				names = sriovnetworkv1.UniqueAppend(names, iface.Name)
+			}
+		}
+	}
+	return names
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -209,6 +210,16 @@ func TestRenderDevicePluginConfigData(t *testing.T) {
 				},
 			},
 		},
+		{
+			tname: "testSystemReserved",
+			policy: sriovnetworkv1.SriovNetworkNodePolicy{
+				Spec: v1.SriovNetworkNodePolicySpec{
+					ResourceName:   "ovnEncap",
+					SystemReserved: true,
+				},
+			},
+			expResource: dptypes.ResourceConfList{},
+		},
 	}
 
 	reconciler := SriovNetworkNodePolicyReconciler{}
@@ -237,3 +248,149 @@ func TestRenderDevicePluginConfigData(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderDevicePluginConfigDataExposePfs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	nodeState := sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{
+			{Name: "ens1f0", PciAddress: "0000:3b:00.0", Vendor: "8086", DeviceID: "158b"},
+			{Name: "ens1f1", PciAddress: "0000:3b:00.1", Vendor: "8086", DeviceID: "158b"},
+		}},
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	reconciler := SriovNetworkNodePolicyReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&nodeState).Build(),
+	}
+
+	policy := sriovnetworkv1.SriovNetworkNodePolicy{Spec: v1.SriovNetworkNodePolicySpec{
+		ResourceName: "macvlanParents",
+		DeviceType:   consts.DeviceTypeNetDevice,
+		ExposePfs:    true,
+		NicSelector:  v1.SriovNetworkNicSelector{Vendor: "8086", DeviceID: "158b", PfNames: []string{"ens1f1"}},
+	}}
+	resourceList, err := reconciler.renderDevicePluginConfigData(context.TODO(),
+		&sriovnetworkv1.SriovNetworkNodePolicyList{Items: []sriovnetworkv1.SriovNetworkNodePolicy{policy}}, &node)
+	g.Expect(err).ToNot(HaveOccurred())
+	// the PFs are pinned by their PCI addresses with their own device ID
+	g.Expect(resourceList).To(Equal(dptypes.ResourceConfList{ResourceList: []dptypes.ResourceConfig{{
+		ResourceName: "macvlanParents",
+		Selectors: mustMarshallSelector(t, &dptypes.NetDeviceSelectors{DeviceSelectors: dptypes.DeviceSelectors{
+			Vendors:      []string{"8086"},
+			Devices:      []string{"158b"},
+			PciAddresses: []string{"0000:3b:00.1"},
+		}}),
+	}}}))
+
+	// no resource is advertised on the nodes without the selected PFs
+	policy.Spec.NicSelector.PfNames = []string{"ens2f0"}
+	resourceList, err = reconciler.renderDevicePluginConfigData(context.TODO(),
+		&sriovnetworkv1.SriovNetworkNodePolicyList{Items: []sriovnetworkv1.SriovNetworkNodePolicy{policy}}, &node)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resourceList.ResourceList).To(BeEmpty())
+}
+
+func TestRenamedPfNames(t *testing.T) {
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+		Interfaces: sriovnetworkv1.InterfaceExts{
+			{Name: "sriov3b00f0", OriginalName: "ens1f0", PciAddress: "0000:3b:00.0"},
+			{Name: "ens1f1", PciAddress: "0000:3b:00.1"},
+		},
+	}}
+	names := renamedPfNames([]string{"ens1f0#0-3", "ens1f1"}, nodeState)
+	if diff := cmp.Diff([]string{"ens1f0#0-3", "ens1f1", "sriov3b00f0#0-3"}, names); diff != "" {
+		t.Errorf("unexpected pfNames (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalDevicePluginConfigData(t *testing.T) {
+	rcl := dptypes.ResourceConfList{
+		ResourceList: []dptypes.ResourceConfig{
+			{ResourceName: "packed"},
+			{ResourceName: "spread"},
+			{ResourceName: "default"},
+		},
+	}
+	pl := &sriovnetworkv1.SriovNetworkNodePolicyList{
+		Items: []sriovnetworkv1.SriovNetworkNodePolicy{
+			{Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "packed", AllocationPolicy: v1.AllocationPolicyPack}},
+			{Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "spread", AllocationPolicy: v1.AllocationPolicySpread}},
+			{Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "default"}},
+		},
+	}
+
+	config, err := marshalDevicePluginConfigData(rcl, pl)
+	if err != nil {
+		t.Fatal("marshalDevicePluginConfigData has failed", err)
+	}
+
+	data := struct {
+		ResourceList []map[string]interface{} `json:"resourceList"`
+	}{}
+	if err := json.Unmarshal(config, &data); err != nil {
+		t.Fatal("device plugin config is not valid JSON", err)
+	}
+	// the spread allocation stored before the webhook rejected it falls back to the ordering of the device plugin
+	expected := map[string]interface{}{"packed": "packed", "spread": nil, "default": nil}
+	for _, rc := range data.ResourceList {
+		if rc["allocatePolicy"] != expected[rc["resourceName"].(string)] {
+			t.Error("unexpected allocatePolicy for resource", rc["resourceName"], rc["allocatePolicy"])
+		}
+	}
+
+	config, err = marshalDevicePluginConfigData(dptypes.ResourceConfList{ResourceList: []dptypes.ResourceConfig{{ResourceName: "default"}}}, pl)
+	if err != nil {
+		t.Fatal("marshalDevicePluginConfigData has failed", err)
+	}
+	previous, _ := json.Marshal(dptypes.ResourceConfList{ResourceList: []dptypes.ResourceConfig{{ResourceName: "default"}}})
+	if string(config) != string(previous) {
+		t.Error("config of a resource without allocation policy changed", cmp.Diff(string(config), string(previous)))
+	}
+}
+
+func TestRenderResourceMap(t *testing.T) {
+	t.Setenv("RESOURCE_PREFIX", "openshift.io")
+	nodeSelector := map[string]string{"sriov": "true"}
+	pl := &sriovnetworkv1.SriovNetworkNodePolicyList{
+		Items: []sriovnetworkv1.SriovNetworkNodePolicy{
+			{ObjectMeta: metav1.ObjectMeta{Name: consts.DefaultPolicyName}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "policy-b"}, Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "nic1", NodeSelector: nodeSelector}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "policy-a"}, Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "nic1", NodeSelector: map[string]string{"zone": "a"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "policy-c"}, Spec: v1.SriovNetworkNodePolicySpec{ResourceName: "nic0", NodeSelector: map[string]string{"none": "true"}}},
+		},
+	}
+	nl := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{"sriov": "true"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"sriov": "true", "zone": "a"}}},
+		},
+	}
+	nodeResources := map[string]dptypes.ResourceConfList{
+		"node1": {ResourceList: []dptypes.ResourceConfig{{ResourceName: "nic1"}}},
+		"node2": {ResourceList: []dptypes.ResourceConfig{{ResourceName: "nic1"}}},
+	}
+
+	status := renderResourceMap(pl, nl, nodeResources)
+	expected := sriovnetworkv1.SriovNetworkResourceMapStatus{
+		Resources: []sriovnetworkv1.SriovNetworkResource{
+			{
+				ResourceName:         "nic0",
+				ExtendedResourceName: "openshift.io/nic0",
+				Policies:             []sriovnetworkv1.SriovNetworkResourcePolicy{{Name: "policy-c"}},
+			},
+			{
+				ResourceName:         "nic1",
+				ExtendedResourceName: "openshift.io/nic1",
+				Policies: []sriovnetworkv1.SriovNetworkResourcePolicy{
+					{Name: "policy-a", Nodes: []string{"node1"}},
+					{Name: "policy-b", Nodes: []string{"node1", "node2"}},
+				},
+			},
+		},
+	}
+	if !cmp.Equal(status, expected) {
+		t.Error("SriovNetworkResourceMapStatus not as expected", cmp.Diff(status, expected))
+	}
+}
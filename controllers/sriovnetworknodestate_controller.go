@@ -0,0 +1,549 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/tracing"
+	utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// events of a node are batched for this delay before the node is synced
+	nodeStateSyncDelay = time.Second
+	// number of nodes synced in parallel
+	nodeStateSyncWorkers = 10
+	// rate of the writes to the SriovNetworkNodeState objects
+	nodeStateWriteQPS   = 20
+	nodeStateWriteBurst = 50
+	// minimum delay between the renders of the node states rolled out one at a time, e.g. first applying restored
+	// policies
+	rolloutInterval = 30 * time.Second
+)
+
+// SriovNetworkNodeStateReconciler renders the spec of the SriovNetworkNodeState of a single node
+// from the policies selecting it. The requests are keyed by node name so that a change only
+// syncs the nodes it affects.
+type SriovNetworkNodeStateReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	writeLimiter *rate.Limiter
+	// traceparents holds the traceparent of the last policy change of each node, the parent of the next render
+	traceparents sync.Map
+
+	// rolloutMu serializes the renders of the node states rolled out one at a time
+	rolloutMu sync.Mutex
+	// lastRolloutRender is the time of the last render of a node state rolled out one at a time
+	lastRolloutRender time.Time
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodestates,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile syncs the SriovNetworkNodeState of the node named by the request
+func (r *SriovNetworkNodeStateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.FromContext(ctx).WithValues("node", req.Name)
+	reqLogger.V(1).Info("Reconciling")
+
+	if traceparent, ok := r.traceparents.LoadAndDelete(req.Name); ok {
+		ctx = tracing.ContextWithTraceparent(ctx, traceparent.(string))
+	}
+	ctx, span := tracing.Start(ctx, "nodestate.render", "node", req.Name)
+	defer span.End()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	err := r.Get(ctx, types.NamespacedName{Name: constants.DefaultPolicyName, Namespace: vars.Namespace}, defaultPolicy)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the node is requeued by the creation of the default policy
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	defaultOpConf := &sriovnetworkv1.SriovOperatorConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, defaultOpConf); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	node := &corev1.Node{}
+	err = r.Get(ctx, types.NamespacedName{Name: req.Name}, node)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	if err == nil && !sriovnetworkv1.IsSupportedNode(node) {
+		reqLogger.Info("Skip the node, its operating system is not supported", "os", sriovnetworkv1.NodeOperatingSystem(node))
+	}
+	if errors.IsNotFound(err) || !labels.SelectorFromSet(configDaemonNodeSelector(defaultOpConf)).Matches(labels.Set(node.Labels)) ||
+		!sriovnetworkv1.IsSupportedNode(node) {
+		reqLogger.V(1).Info("Remove SriovNetworkNodeState custom resource for unselected node")
+		ns := &sriovnetworkv1.SriovNetworkNodeState{}
+		ns.Name = req.Name
+		ns.Namespace = vars.Namespace
+		if err := r.Delete(ctx, ns); err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Fail to Delete", "SriovNetworkNodeState CR:", ns.Name)
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	policyList := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	if err := r.List(ctx, policyList, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	// Sort the policies with priority, higher priority ones is applied later
+	sort.Sort(sriovnetworkv1.ByPriority(policyList.Items))
+	var unsigned []string
+	policyList.Items, unsigned = policySignatures.Filter(ctx, defaultOpConf.Spec.PolicySignature, policyList.Items)
+
+	// the policing and the address sharing exceptions of a resource are set by the SriovNetworks of the operator
+	// namespace
+	networkList := &sriovnetworkv1.SriovNetworkList{}
+	if err := r.List(ctx, networkList, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	policing := sriovnetworkv1.ResourcePolicing(networkList.Items)
+	sharing := sriovnetworkv1.ResourceAddressSharing(networkList.Items)
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.ConfigMapName}, cm); err != nil {
+		reqLogger.V(1).Info("Fail to get", "ConfigMap", constants.ConfigMapName)
+	}
+
+	ns := &sriovnetworkv1.SriovNetworkNodeState{}
+	ns.Name = node.Name
+	ns.Namespace = vars.Namespace
+	ns.Spec.SpecVersion = sriovnetworkv1.NodeStateSpecVersion
+	// the node state is rendered again from the policies after a restore
+	ns.Labels = map[string]string{constants.BackupExcludeLabel: "true"}
+	pools, err := r.getNodePools(ctx, node)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range pools {
+		ns.Spec.Hooks = append(ns.Spec.Hooks, pools[i].Spec.Hooks...)
+		if ns.Spec.DrainMode == "" {
+			ns.Spec.DrainMode = pools[i].DrainMode()
+		}
+	}
+	result, err := r.syncSriovNetworkNodeState(ctx, defaultPolicy, policyList, unsigned, policing, sharing, ns, node,
+		utils.HashConfigMapKey(cm, node.Name))
+	if err != nil {
+		reqLogger.Error(err, "Fail to sync", "SriovNetworkNodeState", ns.Name)
+		span.RecordError(err)
+		return reconcile.Result{}, err
+	}
+	return result, nil
+}
+
+func (r *SriovNetworkNodeStateReconciler) syncSriovNetworkNodeState(ctx context.Context, np *sriovnetworkv1.SriovNetworkNodePolicy, npl *sriovnetworkv1.SriovNetworkNodePolicyList, unsigned []string,
+	policing map[string]*sriovnetworkv1.PolicingConfig, sharing map[string]*sriovnetworkv1.AddressSharing, ns *sriovnetworkv1.SriovNetworkNodeState, node *corev1.Node, cksum string) (reconcile.Result, error) {
+	logger := log.Log.WithName("syncSriovNetworkNodeState")
+	logger.V(1).Info("Start to sync SriovNetworkNodeState", "Name", ns.Name, "cksum", cksum)
+
+	if err := controllerutil.SetControllerReference(np, ns, r.Scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+	found := &sriovnetworkv1.SriovNetworkNodeState{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: ns.Namespace, Name: ns.Name}, found)
+	if err != nil {
+		logger.Error(err, "Fail to get SriovNetworkNodeState", "namespace", ns.Namespace, "name", ns.Name)
+		if errors.IsNotFound(err) {
+			ns.Spec.DpConfigVersion = cksum
+			setTraceparent(ctx, ns)
+			if err := r.waitForWrite(ctx); err != nil {
+				return reconcile.Result{}, err
+			}
+			err = r.Create(ctx, ns)
+			if err != nil {
+				return reconcile.Result{}, fmt.Errorf("couldn't create SriovNetworkNodeState: %v", err)
+			}
+			logger.Info("Created SriovNetworkNodeState for", ns.Namespace, ns.Name)
+		} else {
+			return reconcile.Result{}, fmt.Errorf("failed to get SriovNetworkNodeState: %v", err)
+		}
+	} else {
+		if len(found.Status.Interfaces) == 0 {
+			logger.Info("SriovNetworkNodeState Status Interfaces are empty. Skip update of policies in spec",
+				"namespace", ns.Namespace, "name", ns.Name)
+			return reconcile.Result{}, nil
+		}
+
+		logger.V(1).Info("SriovNetworkNodeState already exists, updating")
+		newVersion := found.DeepCopy()
+		newVersion.Spec = ns.Spec
+		if newVersion.Labels == nil {
+			newVersion.Labels = map[string]string{}
+		}
+		for key, value := range ns.Labels {
+			newVersion.Labels[key] = value
+		}
+		restores := map[string]bool{}
+		renaming := []sriovnetworkv1.SriovNetworkNodePolicy{}
+
+		// Previous Policy Priority(ppp) records the priority of previous evaluated policy in node policy list.
+		// Since node policy list is already sorted with priority number, comparing current priority with ppp shall
+		// be sufficient.
+		// ppp is set to 100 as initial value to avoid matching with the first policy in policy list, although
+		// it should not matter since the flag used in p.Apply() will only be applied when VF partition is detected.
+		ppp := 100
+		applied := []string{}
+		for _, p := range npl.Items {
+			if p.Name == constants.DefaultPolicyName {
+				continue
+			}
+			if p.Selected(node) {
+				// the PFs advertised by the device plugin are left unconfigured
+				if p.Spec.ExposePfs {
+					continue
+				}
+				logger.Info("apply", "policy", p.Name, "node", node.Name)
+				// Merging only for policies with the same priority (ppp == p.Spec.Priority)
+				// This boolean flag controls merging of PF configuration (e.g. mtu, numvfs etc)
+				// when VF partition is configured.
+				err = p.Apply(newVersion, ppp == p.Spec.Priority)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+				applied = append(applied, p.Name)
+				if restore := p.Labels[constants.RestoreNameLabel]; restore != "" {
+					restores[restore] = true
+				}
+				if p.Spec.PfNamePrefix != "" {
+					renaming = append(renaming, p)
+				}
+				// record the evaluated policy priority for next loop
+				ppp = p.Spec.Priority
+			}
+		}
+		sriovnetworkv1.PruneSelectedPfs(newVersion, applied)
+		newVersion.Spec.Interfaces.SetPolicing(policing)
+		newVersion.Spec.Interfaces.SetAddressSharing(sharing)
+		newVersion.Spec.Interfaces.SetPfNames(sriovnetworkv1.PfNames(renaming, newVersion.Status.Interfaces))
+		newVersion.Spec.DpConfigVersion = cksum
+		if equality.Semantic.DeepEqual(newVersion.Spec, found.Spec) {
+			if !equality.Semantic.DeepEqual(newVersion.Labels, found.Labels) ||
+				newVersion.Annotations[constants.SelectedPfsAnnotation] != found.Annotations[constants.SelectedPfsAnnotation] {
+				// the labels and the annotations don't change the generation of the node state, the config daemon
+				// doesn't sync it again
+				if err := r.Patch(ctx, newVersion, client.MergeFrom(found)); err != nil {
+					return reconcile.Result{}, fmt.Errorf("couldn't update SriovNetworkNodeState labels: %v", err)
+				}
+			}
+			logger.V(1).Info("SriovNetworkNodeState did not change, not updating")
+			return reconcile.Result{}, nil
+		}
+		// the config daemon of another version would misapply the spec, the node state is updated once the daemon
+		// reports the versions it supports, after its upgrade
+		if supported := found.Status.SupportedSpecVersions; !supported.Supports(newVersion.Spec.SpecVersion) {
+			logger.Info("The config daemon doesn't support the spec version, not updating until it is upgraded",
+				"name", ns.Name, "specVersion", newVersion.Spec.SpecVersion, "supportedSpecVersions", supported.String())
+			return reconcile.Result{}, nil
+		}
+		// the nodes first applying the policies restored from a backup are reconfigured one at a time, instead of
+		// all the nodes of the restored cluster draining at once
+		if restored := restoreNames(restores); restored != "" && found.Annotations[constants.RestoredAnnotation] != restored {
+			wait, err := r.rolloutDelay(ctx)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if wait > 0 {
+				logger.Info("Restored policies, delaying the update of SriovNetworkNodeState", "name", ns.Name, "restores", restored, "delay", wait)
+				return reconcile.Result{RequeueAfter: wait}, nil
+			}
+			if newVersion.Annotations == nil {
+				newVersion.Annotations = map[string]string{}
+			}
+			newVersion.Annotations[constants.RestoredAnnotation] = restored
+		} else if dropped := appliedPolicies(&found.Spec, unsigned); len(dropped) > 0 {
+			// the policies dropped once the signatures are required are removed from one node at a time as well
+			wait, err := r.rolloutDelay(ctx)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if wait > 0 {
+				logger.Info("Unsigned policies, delaying the update of SriovNetworkNodeState", "name", ns.Name, "policies", dropped, "delay", wait)
+				return reconcile.Result{RequeueAfter: wait}, nil
+			}
+		}
+		setTraceparent(ctx, newVersion)
+		if err := r.waitForWrite(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		// patch the spec only, the status belongs to the config daemon of the node
+		err = r.Patch(ctx, newVersion, client.MergeFromWithOptions(found, client.MergeFromWithOptimisticLock{}))
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("couldn't update SriovNetworkNodeState: %v", err)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// restoreNames returns the sorted names of the restores, as recorded in the node state
+func restoreNames(restores map[string]bool) string {
+	names := make([]string, 0, len(restores))
+	for name := range restores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// appliedPolicies returns the policies of the names applied by the spec of a node state
+func appliedPolicies(spec *sriovnetworkv1.SriovNetworkNodeStateSpec, names []string) []string {
+	applied := []string{}
+	for _, iface := range spec.Interfaces {
+		for _, group := range iface.VfGroups {
+			if sriovnetworkv1.StringInArray(group.PolicyName, names) && !sriovnetworkv1.StringInArray(group.PolicyName, applied) {
+				applied = append(applied, group.PolicyName)
+			}
+		}
+	}
+	return applied
+}
+
+// rolloutDelay returns how long the update of a node state rolled out one node at a time is delayed: the updates
+// are spaced by rolloutInterval and wait for the nodes being configured
+func (r *SriovNetworkNodeStateReconciler) rolloutDelay(ctx context.Context) (time.Duration, error) {
+	r.rolloutMu.Lock()
+	defer r.rolloutMu.Unlock()
+	if wait := rolloutInterval - time.Since(r.lastRolloutRender); wait > 0 {
+		return wait, nil
+	}
+	nodeStates := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := r.List(ctx, nodeStates, client.InNamespace(vars.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list SriovNetworkNodeStates: %v", err)
+	}
+	for _, nodeState := range nodeStates.Items {
+		if nodeState.Status.SyncStatus == constants.SyncStatusInProgress {
+			return rolloutInterval, nil
+		}
+	}
+	r.lastRolloutRender = time.Now()
+	return 0, nil
+}
+
+// getNodePools returns the pools selecting the node, ordered by name
+func (r *SriovNetworkNodeStateReconciler) getNodePools(ctx context.Context, node *corev1.Node) ([]sriovnetworkv1.SriovNetworkPoolConfig, error) {
+	pools := &sriovnetworkv1.SriovNetworkPoolConfigList{}
+	if err := r.List(ctx, pools, client.InNamespace(vars.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list SriovNetworkPoolConfigs: %v", err)
+	}
+	sort.Slice(pools.Items, func(i, j int) bool {
+		return pools.Items[i].Name < pools.Items[j].Name
+	})
+	var selected []sriovnetworkv1.SriovNetworkPoolConfig
+	for i := range pools.Items {
+		if pools.Items[i].Selected(node) {
+			selected = append(selected, pools.Items[i])
+		}
+	}
+	return selected, nil
+}
+
+// setTraceparent annotates the node state with the traceparent of the render, so that the spans of the
+// config daemon applying the spec belong to the same trace
+func setTraceparent(ctx context.Context, ns *sriovnetworkv1.SriovNetworkNodeState) {
+	traceparent := tracing.Traceparent(ctx)
+	if traceparent == "" {
+		delete(ns.Annotations, constants.TraceparentAnnotation)
+		return
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	ns.Annotations[constants.TraceparentAnnotation] = traceparent
+}
+
+// waitForWrite throttles the writes to the node states, so that a change selecting a large
+// number of nodes doesn't flood the API server
+func (r *SriovNetworkNodeStateReconciler) waitForWrite(ctx context.Context) error {
+	if r.writeLimiter == nil {
+		return nil
+	}
+	return r.writeLimiter.Wait(ctx)
+}
+
+// configDaemonNodeSelector returns the labels of the nodes running the config daemon
+func configDaemonNodeSelector(defaultOpConf *sriovnetworkv1.SriovOperatorConfig) map[string]string {
+	if len(defaultOpConf.Spec.ConfigDaemonNodeSelector) > 0 {
+		return defaultOpConf.Spec.ConfigDaemonNodeSelector
+	}
+	return map[string]string{
+		"node-role.kubernetes.io/worker": "",
+		"kubernetes.io/os":               "linux",
+	}
+}
+
+// enqueueNodes adds the nodes matching the selector to the queue, all the nodes are added for an empty selector
+func (r *SriovNetworkNodeStateReconciler) enqueueNodes(ctx context.Context, selector map[string]string, q workqueue.RateLimitingInterface) {
+	r.enqueueTracedNodes(ctx, selector, "", q)
+}
+
+// enqueueTracedNodes adds the nodes matching the selector to the queue, their next render continues the trace
+// of the traceparent if not empty
+func (r *SriovNetworkNodeStateReconciler) enqueueTracedNodes(ctx context.Context, selector map[string]string, traceparent string, q workqueue.RateLimitingInterface) {
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, client.MatchingLabels(selector)); err != nil {
+		log.Log.WithName("SriovNetworkNodeState").Error(err, "Fail to list nodes")
+		return
+	}
+	for _, node := range nodeList.Items {
+		if traceparent != "" {
+			r.traceparents.Store(node.Name, traceparent)
+		}
+		q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: node.Name}}, nodeStateSyncDelay)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SriovNetworkNodeStateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.writeLimiter = rate.NewLimiter(nodeStateWriteQPS, nodeStateWriteBurst)
+
+	// a policy change only syncs the nodes selected by the policy before or after the change
+	// the render continues the trace of the admission of the policy
+	policyHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTracedNodes(ctx, e.Object.(*sriovnetworkv1.SriovNetworkNodePolicy).Spec.NodeSelector,
+				e.Object.GetAnnotations()[constants.TraceparentAnnotation], q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			traceparent := e.ObjectNew.GetAnnotations()[constants.TraceparentAnnotation]
+			r.enqueueTracedNodes(ctx, e.ObjectOld.(*sriovnetworkv1.SriovNetworkNodePolicy).Spec.NodeSelector, traceparent, q)
+			r.enqueueTracedNodes(ctx, e.ObjectNew.(*sriovnetworkv1.SriovNetworkNodePolicy).Spec.NodeSelector, traceparent, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			policySignatures.Forget(e.Object.GetUID())
+			r.enqueueNodes(ctx, e.Object.(*sriovnetworkv1.SriovNetworkNodePolicy).Spec.NodeSelector, q)
+		},
+	}
+
+	// the checksum of the device plugin config of a node only changes with the entry of the node
+	configMapHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			for nodeName := range e.Object.(*corev1.ConfigMap).Data {
+				q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: nodeName}}, nodeStateSyncDelay)
+			}
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldData := e.ObjectOld.(*corev1.ConfigMap).Data
+			newData := e.ObjectNew.(*corev1.ConfigMap).Data
+			for nodeName, data := range newData {
+				if oldData[nodeName] != data {
+					q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: nodeName}}, nodeStateSyncDelay)
+				}
+			}
+		},
+	}
+
+	// a change of the config daemon node selector can select or unselect any node
+	operatorConfigHandler := handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			if e.ObjectNew.GetName() != constants.DefaultConfigName {
+				return
+			}
+			r.enqueueNodes(ctx, nil, q)
+		},
+	}
+
+	// the hooks of a pool can apply to any node, the pool selector being a label selector
+	poolHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueNodes(ctx, nil, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueNodes(ctx, nil, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueNodes(ctx, nil, q)
+		},
+	}
+
+	// the policing and the address sharing of a resource can apply to any node, the nodes of the resource are only
+	// known once rendered. They are set by the networks of the operator namespace, the address sharing of a resource
+	// depends on all its networks.
+	configuresVfs := func(network *sriovnetworkv1.SriovNetwork) bool {
+		return network.Namespace == vars.Namespace
+	}
+	networkHandler := handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			if configuresVfs(e.Object.(*sriovnetworkv1.SriovNetwork)) {
+				r.enqueueNodes(ctx, nil, q)
+			}
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldSpec := e.ObjectOld.(*sriovnetworkv1.SriovNetwork).Spec
+			newSpec := e.ObjectNew.(*sriovnetworkv1.SriovNetwork).Spec
+			if configuresVfs(e.ObjectNew.(*sriovnetworkv1.SriovNetwork)) &&
+				(!equality.Semantic.DeepEqual(oldSpec.Policing, newSpec.Policing) ||
+					!equality.Semantic.DeepEqual(oldSpec.AddressSharing, newSpec.AddressSharing) ||
+					oldSpec.ResourceName != newSpec.ResourceName) {
+				r.enqueueNodes(ctx, nil, q)
+			}
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			if configuresVfs(e.Object.(*sriovnetworkv1.SriovNetwork)) {
+				r.enqueueNodes(ctx, nil, q)
+			}
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sriovnetworknodestate").
+		For(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Watches(&corev1.Node{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		// the signature of a policy is an annotation, the nodes are rendered again when it changes
+		Watches(&sriovnetworkv1.SriovNetworkNodePolicy{}, policyHandler,
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&corev1.ConfigMap{}, configMapHandler, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == constants.ConfigMapName && o.GetNamespace() == vars.Namespace
+		}))).
+		Watches(&sriovnetworkv1.SriovOperatorConfig{}, operatorConfigHandler, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&sriovnetworkv1.SriovNetworkPoolConfig{}, poolHandler, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&sriovnetworkv1.SriovNetwork{}, networkHandler, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == vars.Namespace
+		}))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: nodeStateSyncWorkers}).
+		Complete(r)
+}
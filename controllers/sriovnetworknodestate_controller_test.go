@@ -0,0 +1,554 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestSriovNetworkNodeStateSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "resource1",
+			NodeSelector: map[string]string{"sriov": "true"},
+			NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			NumVfs:       4,
+		},
+	}
+	pfPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy2", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "resource2",
+			NodeSelector: map[string]string{"sriov": "true"},
+			NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f1"}},
+			ExposePfs:    true,
+		},
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+		},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.ConfigMapName, Namespace: vars.Namespace},
+		Data:       map[string]string{"node1": "config1", "node2": "config2"},
+	}
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"sriov": "true"}}}
+	node2 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}
+	nodeState2 := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: node2.Name, Namespace: vars.Namespace},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(defaultPolicy, policy, pfPolicy, config, cm, node1, node2, nodeState2).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	reconcileNode := func(name string) {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	// the node state of a selected node is created
+	reconcileNode(node1.Name)
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: node1.Name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+	g.Expect(nodeState.Spec.DpConfigVersion).To(Equal(utils.HashConfigMapKey(cm, node1.Name)))
+	g.Expect(nodeState.Spec.Interfaces).To(BeEmpty())
+
+	// the policies are applied once the daemon reported the interfaces, the PFs advertised by the device plugin
+	// are left unconfigured
+	nodeState.Status.Interfaces = sriovnetworkv1.InterfaceExts{
+		{Name: "ens1f0", PciAddress: "0000:86:00.0", TotalVfs: 8},
+		{Name: "ens1f1", PciAddress: "0000:86:00.1", TotalVfs: 8},
+	}
+	nodeState.Status.SupportedSpecVersions = sriovnetworkv1.SupportedSpecVersions()
+	g.Expect(c.Status().Update(ctx, nodeState)).To(Succeed())
+	reconcileNode(node1.Name)
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(nodeState), nodeState)).To(Succeed())
+	g.Expect(nodeState.Spec.Interfaces).To(HaveLen(1))
+	g.Expect(nodeState.Spec.Interfaces[0].Name).To(Equal("ens1f0"))
+	g.Expect(nodeState.Spec.Interfaces[0].NumVfs).To(Equal(4))
+
+	// the interfaces are de-configured once the node leaves the policy nodeSelector
+	node1.Labels["sriov"] = "false"
+	node1.Labels["daemon"] = "true"
+	config.Spec.ConfigDaemonNodeSelector = map[string]string{"daemon": "true"}
+	g.Expect(c.Update(ctx, node1)).To(Succeed())
+	g.Expect(c.Update(ctx, config)).To(Succeed())
+	reconcileNode(node1.Name)
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(nodeState), nodeState)).To(Succeed())
+	g.Expect(nodeState.Spec.Interfaces).To(BeEmpty())
+
+	// the node state of a node no longer selected by the config daemon is removed
+	reconcileNode(node2.Name)
+	err := c.Get(ctx, client.ObjectKeyFromObject(nodeState2), &sriovnetworkv1.SriovNetworkNodeState{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+}
+
+func TestSriovNetworkNodeStateHooks(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+		},
+	}
+	storageHook := sriovnetworkv1.Hook{Name: "quiesce", Point: constants.HookPointPreDrain, Command: []string{"/usr/local/bin/quiesce.sh"}}
+	rebootHook := sriovnetworkv1.Hook{Name: "notify", Point: constants.HookPointPreReboot, Command: []string{"/usr/local/bin/notify.sh"}}
+	pools := []client.Object{
+		&sriovnetworkv1.SriovNetworkPoolConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-storage", Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"storage": "true"}},
+				Hooks:        []sriovnetworkv1.Hook{storageHook},
+				DrainSkip:    true,
+			},
+		},
+		&sriovnetworkv1.SriovNetworkPoolConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-all", Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"sriov": "true"}},
+				Hooks:        []sriovnetworkv1.Hook{rebootHook},
+			},
+		},
+		&sriovnetworkv1.SriovNetworkPoolConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "c-other", Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"other": "true"}},
+				Hooks:        []sriovnetworkv1.Hook{{Name: "unused", Point: constants.HookPointPreApply, Command: []string{"true"}}},
+				DrainMode:    sriovnetworkv1.DrainModeNone,
+			},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"sriov": "true", "storage": "true"}}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(append(pools, defaultPolicy, config, node)...).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	g.Expect(err).ToNot(HaveOccurred())
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+	// the hooks are ordered by pool name
+	g.Expect(nodeState.Spec.Hooks).To(Equal([]sriovnetworkv1.Hook{rebootHook, storageHook}))
+	// the first pool with a drain mode applies, drainSkip stands for Cordon
+	g.Expect(nodeState.Spec.DrainMode).To(Equal(sriovnetworkv1.DrainModeCordon))
+}
+
+func TestSriovNetworkNodeStateTraceparent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{
+		"node-role.kubernetes.io/worker": "",
+		"kubernetes.io/os":               "linux",
+	}}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(defaultPolicy, config, node).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	// the traceparent of the policy change is propagated to the node state read by the config daemon
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	reconciler.traceparents.Store(node.Name, traceparent)
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	g.Expect(err).ToNot(HaveOccurred())
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+	g.Expect(nodeState.Annotations).To(HaveKeyWithValue(constants.TraceparentAnnotation, traceparent))
+
+	_, ok := reconciler.traceparents.Load(node.Name)
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestSriovNetworkNodeStateSkipsWindowsNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	// the custom selector of the config daemon doesn't restrict the operating system
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"feature.node.kubernetes.io/network-sriov.capable": "true"},
+		},
+	}
+	linuxNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "linux-0", Labels: map[string]string{
+		"feature.node.kubernetes.io/network-sriov.capable": "true",
+		"kubernetes.io/os": "linux",
+	}}}
+	windowsNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "win-0", Labels: map[string]string{
+		"feature.node.kubernetes.io/network-sriov.capable": "true",
+		"kubernetes.io/os": "windows",
+	}}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(defaultPolicy, config, linuxNode, windowsNode).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	for _, node := range []*corev1.Node{linuxNode, windowsNode} {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: linuxNode.Name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+	err := c.Get(ctx, types.NamespacedName{Name: windowsNode.Name, Namespace: vars.Namespace}, nodeState)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	g.Expect(daemonNodeSelector(config.Spec.ConfigDaemonNodeSelector)).To(HaveKeyWithValue("kubernetes.io/os", "linux"))
+	g.Expect(config.Spec.ConfigDaemonNodeSelector).ToNot(HaveKey("kubernetes.io/os"))
+}
+
+func TestSriovNetworkNodeStateSpecVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"sriov": "true"}}}
+	// the config daemon of the node no longer applies the specs of this operator version
+	existing := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			Interfaces:            sriovnetworkv1.InterfaceExts{{PciAddress: "0000:86:00.0"}},
+			SupportedSpecVersions: &sriovnetworkv1.SpecVersionRange{Min: sriovnetworkv1.NodeStateSpecVersion + 1, Max: sriovnetworkv1.NodeStateSpecVersion + 1},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(defaultPolicy, config, node, existing).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+	key := types.NamespacedName{Name: node.Name, Namespace: vars.Namespace}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	g.Expect(err).ToNot(HaveOccurred())
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, key, nodeState)).To(Succeed())
+	g.Expect(nodeState.Spec.SpecVersion).To(BeZero())
+
+	// the spec is rendered once the config daemon supports its version
+	nodeState.Status.SupportedSpecVersions = sriovnetworkv1.SupportedSpecVersions()
+	g.Expect(c.Status().Update(ctx, nodeState)).To(Succeed())
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Get(ctx, key, nodeState)).To(Succeed())
+	g.Expect(nodeState.Spec.SpecVersion).To(Equal(sriovnetworkv1.NodeStateSpecVersion))
+}
+
+func TestSriovNetworkNodeStateRestoreRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	// the policy restored by Velero
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: vars.Namespace,
+			Labels: map[string]string{constants.RestoreNameLabel: "restore-1"}},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "resource1",
+			NodeSelector: map[string]string{"sriov": "true"},
+			NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			NumVfs:       4,
+		},
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+		},
+	}
+	objs := []client.Object{defaultPolicy, policy, config}
+	for _, name := range []string{"node1", "node2"} {
+		objs = append(objs,
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"sriov": "true"}}},
+			&sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces:            sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", TotalVfs: 8}},
+					SyncStatus:            constants.SyncStatusSucceeded,
+					SupportedSpecVersions: sriovnetworkv1.SupportedSpecVersions(),
+				},
+			})
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	reconcileNode := func(name string) (ctrl.Result, *sriovnetworkv1.SriovNetworkNodeState) {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+		g.Expect(err).ToNot(HaveOccurred())
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+		return result, nodeState
+	}
+
+	// the first node applies the restored policy
+	_, nodeState1 := reconcileNode("node1")
+	g.Expect(nodeState1.Spec.Interfaces).To(HaveLen(1))
+	g.Expect(nodeState1.Annotations).To(HaveKeyWithValue(constants.RestoredAnnotation, "restore-1"))
+	g.Expect(nodeState1.Labels).To(HaveKeyWithValue(constants.BackupExcludeLabel, "true"))
+
+	// the second node waits for the rollout interval
+	result, nodeState2 := reconcileNode("node2")
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	g.Expect(nodeState2.Spec.Interfaces).To(BeEmpty())
+
+	// then for the first node to be configured
+	reconciler.lastRolloutRender = time.Time{}
+	nodeState1.Status.SyncStatus = constants.SyncStatusInProgress
+	g.Expect(c.Status().Update(ctx, nodeState1)).To(Succeed())
+	result, nodeState2 = reconcileNode("node2")
+	g.Expect(result.RequeueAfter).To(Equal(rolloutInterval))
+	g.Expect(nodeState2.Spec.Interfaces).To(BeEmpty())
+
+	nodeState1.Status.SyncStatus = constants.SyncStatusSucceeded
+	g.Expect(c.Status().Update(ctx, nodeState1)).To(Succeed())
+	_, nodeState2 = reconcileNode("node2")
+	g.Expect(nodeState2.Spec.Interfaces).To(HaveLen(1))
+
+	// the later changes of the restored policy are not delayed
+	policy.Spec.NumVfs = 8
+	g.Expect(c.Update(ctx, policy)).To(Succeed())
+	result, nodeState1 = reconcileNode("node1")
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(nodeState1.Spec.Interfaces[0].NumVfs).To(Equal(8))
+}
+
+func TestSriovNetworkNodeStateSignedPolicies(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: vars.Namespace, UID: "policy1-uid"},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "resource1",
+			NodeSelector: map[string]string{"sriov": "true"},
+			NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			NumVfs:       4,
+		},
+	}
+	// the verifier records its calls and only trusts the "trusted" signature
+	calls := filepath.Join(t.TempDir(), "calls")
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+			PolicySignature: &sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/sh", "-c",
+				`echo call >> ` + calls + `; grep -q '"signature":"trusted"' && echo '{"allowed": true}' || echo '{"allowed": false}'`}},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"sriov": "true"}}}
+	existing := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			Interfaces:            sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", TotalVfs: 8}},
+			SupportedSpecVersions: sriovnetworkv1.SupportedSpecVersions(),
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(defaultPolicy, policy, config, node, existing).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	reconcileNode := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Get(ctx, client.ObjectKeyFromObject(existing), nodeState)).To(Succeed())
+	}
+
+	// the policy without a signature isn't applied, the verifier isn't called
+	reconcileNode()
+	g.Expect(nodeState.Spec.Interfaces).To(BeEmpty())
+	g.Expect(calls).ToNot(BeAnExistingFile())
+
+	// the policy with a rejected signature isn't applied
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(policy), policy)).To(Succeed())
+	policy.Annotations = map[string]string{constants.PolicySignatureAnnotation: "forged"}
+	g.Expect(c.Update(ctx, policy)).To(Succeed())
+	reconcileNode()
+	g.Expect(nodeState.Spec.Interfaces).To(BeEmpty())
+	// the rejection is cached as well
+	reconcileNode()
+	g.Expect(nodeState.Spec.Interfaces).To(BeEmpty())
+
+	// the signed policy is applied, its signature is only verified once
+	policy.Annotations[constants.PolicySignatureAnnotation] = "trusted"
+	g.Expect(c.Update(ctx, policy)).To(Succeed())
+	reconcileNode()
+	g.Expect(nodeState.Spec.Interfaces).To(HaveLen(1))
+	reconcileNode()
+	g.Expect(nodeState.Spec.Interfaces).To(HaveLen(1))
+	g.Expect(os.ReadFile(calls)).To(Equal([]byte("call\ncall\n")))
+}
+
+func TestSriovNetworkNodeStateUnsignedPoliciesRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	defaultPolicy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace},
+	}
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: vars.Namespace, UID: "unsigned-policy1-uid"},
+		Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+			ResourceName: "resource1",
+			NodeSelector: map[string]string{"sriov": "true"},
+			NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			NumVfs:       4,
+		},
+	}
+	// the signatures are now required, the policy applied to the nodes has none
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
+			ConfigDaemonNodeSelector: map[string]string{"sriov": "true"},
+			PolicySignature:          &sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/false"}},
+		},
+	}
+	objs := []client.Object{defaultPolicy, policy, config}
+	for _, name := range []string{"node1", "node2"} {
+		objs = append(objs,
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"sriov": "true"}}},
+			&sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 4,
+						VfGroups: []sriovnetworkv1.VfGroup{{ResourceName: "resource1", PolicyName: "policy1", VfRange: "0-3"}}}},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces:            sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", TotalVfs: 8}},
+					SyncStatus:            constants.SyncStatusSucceeded,
+					SupportedSpecVersions: sriovnetworkv1.SupportedSpecVersions(),
+				},
+			})
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).
+		Build()
+	reconciler := &SriovNetworkNodeStateReconciler{Client: c, Scheme: scheme}
+
+	reconcileNode := func(name string) (ctrl.Result, *sriovnetworkv1.SriovNetworkNodeState) {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+		g.Expect(err).ToNot(HaveOccurred())
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: vars.Namespace}, nodeState)).To(Succeed())
+		return result, nodeState
+	}
+
+	// the first node drops the unsigned policy
+	_, nodeState1 := reconcileNode("node1")
+	g.Expect(nodeState1.Spec.Interfaces).To(BeEmpty())
+
+	// the second node keeps it until the first one is configured
+	result, nodeState2 := reconcileNode("node2")
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	g.Expect(nodeState2.Spec.Interfaces).To(HaveLen(1))
+
+	reconciler.lastRolloutRender = time.Time{}
+	nodeState1.Status.SyncStatus = constants.SyncStatusInProgress
+	g.Expect(c.Status().Update(ctx, nodeState1)).To(Succeed())
+	result, nodeState2 = reconcileNode("node2")
+	g.Expect(result.RequeueAfter).To(Equal(rolloutInterval))
+	g.Expect(nodeState2.Spec.Interfaces).To(HaveLen(1))
+
+	nodeState1.Status.SyncStatus = constants.SyncStatusSucceeded
+	g.Expect(c.Status().Update(ctx, nodeState1)).To(Succeed())
+	result, nodeState2 = reconcileNode("node2")
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(nodeState2.Spec.Interfaces).To(BeEmpty())
+}
@@ -5,14 +5,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
@@ -83,6 +92,12 @@ func (r *SriovNetworkPoolConfigReconciler) Reconcile(ctx context.Context, req ct
 				return reconcile.Result{}, err
 			}
 		}
+		if err = r.syncPoolNodeLabels(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err = r.syncOvnHardwareOffload(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
 		if vars.ClusterType == constants.ClusterTypeOpenshift {
 			if !isHypershift {
 				if err = r.syncOvsHardwareOffloadMachineConfigs(ctx, instance, false); err != nil {
@@ -97,6 +112,9 @@ func (r *SriovNetworkPoolConfigReconciler) Reconcile(ctx context.Context, req ct
 		if sriovnetworkv1.StringInArray(sriovnetworkv1.POOLCONFIGFINALIZERNAME, instance.ObjectMeta.Finalizers) {
 			// our finalizer is present, so lets handle any external dependency
 			logger.Info("delete SriovNetworkPoolConfig CR", "Namespace", instance.Namespace, "Name", instance.Name)
+			if err = r.syncOvnHardwareOffload(ctx, instance); err != nil {
+				return reconcile.Result{}, err
+			}
 			if vars.ClusterType == constants.ClusterTypeOpenshift && !isHypershift {
 				if err = r.syncOvsHardwareOffloadMachineConfigs(ctx, instance, true); err != nil {
 					// if fail to delete the external dependency here, return with error
@@ -112,6 +130,10 @@ func (r *SriovNetworkPoolConfigReconciler) Reconcile(ctx context.Context, req ct
 					return reconcile.Result{}, err
 				}
 			}
+			// the labels of the deleted pool are removed from its nodes
+			if err = r.syncPoolNodeLabels(ctx); err != nil {
+				return reconcile.Result{}, err
+			}
 		}
 		return reconcile.Result{}, err
 	}
@@ -121,11 +143,307 @@ func (r *SriovNetworkPoolConfigReconciler) Reconcile(ctx context.Context, req ct
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SriovNetworkPoolConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the pool labels depend on the node labels, re-evaluate them when a node is added or relabeled
+	nodeHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		pools := &sriovnetworkv1.SriovNetworkPoolConfigList{}
+		if err := r.List(ctx, pools, client.InNamespace(vars.Namespace)); err != nil {
+			log.Log.Error(err, "failed to list SriovNetworkPoolConfigs")
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, pool := range pools.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}})
+		}
+		return requests
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sriovnetworkv1.SriovNetworkPoolConfig{}).
+		Watches(&corev1.Node{}, nodeHandler, builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Complete(r)
 }
 
+// poolNodeLabel is a label the operator puts on the nodes selected by the pools enabling a feature
+type poolNodeLabel struct {
+	key     string
+	value   string
+	enabled func(*sriovnetworkv1.SriovNetworkPoolConfigSpec) bool
+}
+
+var poolNodeLabels = []poolNodeLabel{
+	{
+		key:   constants.DaemonProfileLabel,
+		value: constants.DaemonProfileLite,
+		enabled: func(spec *sriovnetworkv1.SriovNetworkPoolConfigSpec) bool {
+			return spec.DaemonProfile == constants.DaemonProfileLite
+		},
+	},
+	{
+		key:   constants.DrainSkipLabel,
+		value: "true",
+		enabled: func(spec *sriovnetworkv1.SriovNetworkPoolConfigSpec) bool {
+			return spec.DrainSkip
+		},
+	},
+	{
+		key:   constants.OvsHwOffloadLabel,
+		value: "true",
+		enabled: func(spec *sriovnetworkv1.SriovNetworkPoolConfigSpec) bool {
+			return spec.OvnHardwareOffload != nil
+		},
+	},
+}
+
+// syncPoolNodeLabels labels the nodes selected by the pools enabling the lite daemon profile or
+// the drain skip, and removes the labels from the nodes no longer selected by such a pool
+func (r *SriovNetworkPoolConfigReconciler) syncPoolNodeLabels(ctx context.Context) error {
+	logger := log.Log.WithName("syncPoolNodeLabels")
+
+	pools := &sriovnetworkv1.SriovNetworkPoolConfigList{}
+	if err := r.List(ctx, pools, client.InNamespace(vars.Namespace)); err != nil {
+		return fmt.Errorf("failed to list SriovNetworkPoolConfigs: %v", err)
+	}
+	// the selectors of the pools enabling each label
+	selectors := make([][]labels.Selector, len(poolNodeLabels))
+	for _, pool := range pools.Items {
+		if pool.Spec.NodeSelector == nil || !pool.DeletionTimestamp.IsZero() {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pool.Spec.NodeSelector)
+		if err != nil {
+			logger.Error(err, "invalid node selector, skipping", "pool", pool.Name)
+			continue
+		}
+		for i, l := range poolNodeLabels {
+			if l.enabled(&pool.Spec) {
+				selectors[i] = append(selectors[i], selector)
+			}
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		patch := client.MergeFrom(node.DeepCopy())
+		changed := false
+		for j, l := range poolNodeLabels {
+			selected := false
+			for _, selector := range selectors[j] {
+				if selector.Matches(labels.Set(node.Labels)) {
+					selected = true
+					break
+				}
+			}
+
+			_, labeled := node.Labels[l.key]
+			if selected == labeled {
+				continue
+			}
+			if selected {
+				if node.Labels == nil {
+					node.Labels = map[string]string{}
+				}
+				node.Labels[l.key] = l.value
+			} else {
+				delete(node.Labels, l.key)
+			}
+			logger.Info("update node label", "node", node.Name, "label", l.key, "set", selected)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := r.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to label node %s: %v", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// syncOvnHardwareOffload maintains the policies of the hardware offload of OVN-Kubernetes of the pool and reports the
+// progress of its steps on the nodes of the pool, the policies are deleted when it's disabled or the pool is deleted
+func (r *SriovNetworkPoolConfigReconciler) syncOvnHardwareOffload(ctx context.Context, pool *sriovnetworkv1.SriovNetworkPoolConfig) error {
+	logger := log.Log.WithName("syncOvnHardwareOffload")
+
+	var policies []sriovnetworkv1.SriovNetworkNodePolicy
+	if pool.DeletionTimestamp.IsZero() {
+		var err error
+		if policies, err = pool.OvnHardwareOffloadPolicies(); err != nil {
+			return err
+		}
+	}
+	existing := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	if err := r.List(ctx, existing, client.InNamespace(pool.Namespace),
+		client.MatchingLabels{constants.OvnHardwareOffloadPoolLabel: pool.Name}); err != nil {
+		return fmt.Errorf("failed to list the policies of pool %s: %v", pool.Name, err)
+	}
+	found := map[string]*sriovnetworkv1.SriovNetworkNodePolicy{}
+	for i := range existing.Items {
+		found[existing.Items[i].Name] = &existing.Items[i]
+	}
+	for i := range policies {
+		policy := &policies[i]
+		current, ok := found[policy.Name]
+		delete(found, policy.Name)
+		if !ok {
+			logger.Info("create policy", "pool", pool.Name, "policy", policy.Name)
+			if err := r.Create(ctx, policy); err != nil {
+				return fmt.Errorf("couldn't create policy %s: %v", policy.Name, err)
+			}
+			continue
+		}
+		if equality.Semantic.DeepEqual(policy.Spec, current.Spec) {
+			continue
+		}
+		logger.Info("update policy", "pool", pool.Name, "policy", policy.Name)
+		current.Spec = policy.Spec
+		if err := r.Update(ctx, current); err != nil {
+			return fmt.Errorf("couldn't update policy %s: %v", policy.Name, err)
+		}
+	}
+	for _, policy := range found {
+		logger.Info("delete policy", "pool", pool.Name, "policy", policy.Name)
+		if err := r.Delete(ctx, policy); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete policy %s: %v", policy.Name, err)
+		}
+	}
+	if !pool.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	status, err := r.ovnHardwareOffloadStatus(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(status, pool.Status.OvnHardwareOffload) {
+		return nil
+	}
+	pool.Status.OvnHardwareOffload = status
+	if err := r.Status().Update(ctx, pool); err != nil {
+		return fmt.Errorf("failed to update the status of pool %s: %v", pool.Name, err)
+	}
+	return nil
+}
+
+// ovnHardwareOffloadStep is a step of the hardware offload of OVN-Kubernetes, done on a node when its check passes
+type ovnHardwareOffloadStep struct {
+	name string
+	done func(pool *sriovnetworkv1.SriovNetworkPoolConfig, node *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool
+}
+
+var ovnHardwareOffloadSteps = []ovnHardwareOffloadStep{
+	{name: "Switchdev", done: ovnHardwareOffloadSwitchdev},
+	{
+		// the config daemon reports the representors of the VFs of the switchdev PFs it finds on the node
+		name: "Representors",
+		done: func(pool *sriovnetworkv1.SriovNetworkPoolConfig, node *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+			representors := node.Annotations[constants.OvsHwOffloadRepresentorsAnnotation]
+			return ovnHardwareOffloadSwitchdev(pool, node, state) &&
+				(representors == constants.OvsHwOffloadRepresentorsCreated || representors == constants.OvsHwOffloadRepresentorsNamed)
+		},
+	},
+	{
+		// OVN-Kubernetes finds the representors by the <PF>_<VF> names the switchdev udev rule gives them
+		name: "UdevNaming",
+		done: func(pool *sriovnetworkv1.SriovNetworkPoolConfig, node *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+			return ovnHardwareOffloadSwitchdev(pool, node, state) &&
+				node.Annotations[constants.OvsHwOffloadRepresentorsAnnotation] == constants.OvsHwOffloadRepresentorsNamed
+		},
+	},
+	{
+		name: "OvsHardwareOffload",
+		done: func(pool *sriovnetworkv1.SriovNetworkPoolConfig, node *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+			return node.Annotations[constants.OvsHwOffloadStateAnnotation] == constants.OvsHwOffloadStateEnabled
+		},
+	},
+	{
+		name: "ManagementVf",
+		done: func(pool *sriovnetworkv1.SriovNetworkPoolConfig, node *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+			_, mgmtResourceName := pool.OvnHardwareOffloadResourceNames()
+			quantity, ok := node.Status.Allocatable[sriovnetworkv1.ExtendedResourceName(mgmtResourceName)]
+			return ok && quantity.Value() > 0
+		},
+	},
+}
+
+// ovnHardwareOffloadSwitchdev returns whether the node state reports all the PFs of the pool in switchdev
+func ovnHardwareOffloadSwitchdev(pool *sriovnetworkv1.SriovNetworkPoolConfig, _ *corev1.Node, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+	if state == nil {
+		return false
+	}
+	for _, pfName := range pool.Spec.OvnHardwareOffload.PfNames {
+		switchdev := false
+		for _, iface := range state.Status.Interfaces {
+			if iface.Name == pfName {
+				switchdev = iface.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev
+				break
+			}
+		}
+		if !switchdev {
+			return false
+		}
+	}
+	return true
+}
+
+// ovnHardwareOffloadStatus reports the steps of the hardware offload of OVN-Kubernetes done on the nodes of the pool
+func (r *SriovNetworkPoolConfigReconciler) ovnHardwareOffloadStatus(ctx context.Context,
+	pool *sriovnetworkv1.SriovNetworkPoolConfig) (*sriovnetworkv1.OvnHardwareOffloadStatus, error) {
+	if pool.Spec.OvnHardwareOffload == nil {
+		return nil, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	status := &sriovnetworkv1.OvnHardwareOffloadStatus{}
+	pending := make([][]string, len(ovnHardwareOffloadSteps))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !pool.Selected(node) {
+			continue
+		}
+		status.Nodes++
+		state := &sriovnetworkv1.SriovNetworkNodeState{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: node.Name}, state); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			state = nil
+		}
+		for j, step := range ovnHardwareOffloadSteps {
+			if !step.done(pool, node, state) {
+				pending[j] = append(pending[j], node.Name)
+			}
+		}
+	}
+	for j, step := range ovnHardwareOffloadSteps {
+		status.Steps = append(status.Steps, sriovnetworkv1.OvnHardwareOffloadStep{
+			Name:       step.name,
+			ReadyNodes: status.Nodes - len(pending[j]),
+			Message:    pendingNodesMessage(pending[j]),
+		})
+	}
+	return status, nil
+}
+
+// pendingNodesMessage names the first nodes a step is pending on
+func pendingNodesMessage(nodes []string) string {
+	const maxNodes = 3
+	if len(nodes) == 0 {
+		return ""
+	}
+	sort.Strings(nodes)
+	if len(nodes) > maxNodes {
+		return fmt.Sprintf("pending on %s and %d more nodes", strings.Join(nodes[:maxNodes], ", "), len(nodes)-maxNodes)
+	}
+	return "pending on " + strings.Join(nodes, ", ")
+}
+
 func (r *SriovNetworkPoolConfigReconciler) syncOvsHardwareOffloadMachineConfigs(ctx context.Context, nc *sriovnetworkv1.SriovNetworkPoolConfig, deletion bool) error {
 	logger := log.Log.WithName("syncOvsHardwareOffloadMachineConfigs")
 
@@ -3,10 +3,17 @@ package controllers
 import (
 	"context"
 	"sync"
+	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -135,3 +142,144 @@ var _ = Describe("SriovNetworkPoolConfig controller", Ordered, func() {
 		})
 	})
 })
+
+func TestSyncPoolNodeLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	pool := &sriovnetworkv1.SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+			NodeSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"edge": "true"}},
+			DaemonProfile: consts.DaemonProfileLite,
+			DrainSkip:     true,
+		},
+	}
+	edgeNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "edge", Labels: map[string]string{"edge": "true"}}}
+	// a node that left the pool keeps a stale label
+	formerEdgeNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "former-edge",
+		Labels: map[string]string{consts.DaemonProfileLabel: consts.DaemonProfileLite}}}
+	workerNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool, edgeNode, formerEdgeNode, workerNode).Build()
+	reconciler := &SriovNetworkPoolConfigReconciler{Client: c, Scheme: scheme}
+
+	g.Expect(reconciler.syncPoolNodeLabels(ctx)).To(Succeed())
+
+	node := &corev1.Node{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(edgeNode), node)).To(Succeed())
+	g.Expect(node.Labels).To(HaveKeyWithValue(consts.DaemonProfileLabel, consts.DaemonProfileLite))
+	g.Expect(node.Labels).To(HaveKeyWithValue(consts.DrainSkipLabel, "true"))
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(formerEdgeNode), node)).To(Succeed())
+	g.Expect(node.Labels).ToNot(HaveKey(consts.DaemonProfileLabel))
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(workerNode), node)).To(Succeed())
+	g.Expect(node.Labels).ToNot(HaveKey(consts.DaemonProfileLabel))
+
+	// switching the pool back to the full profile only removes the profile label
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(pool), pool)).To(Succeed())
+	pool.Spec.DaemonProfile = consts.DaemonProfileFull
+	g.Expect(c.Update(ctx, pool)).To(Succeed())
+	g.Expect(reconciler.syncPoolNodeLabels(ctx)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(edgeNode), node)).To(Succeed())
+	g.Expect(node.Labels).ToNot(HaveKey(consts.DaemonProfileLabel))
+	g.Expect(node.Labels).To(HaveKeyWithValue(consts.DrainSkipLabel, "true"))
+}
+
+func TestSyncOvnHardwareOffload(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	pool := &sriovnetworkv1.SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "hwol", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"hwol": "true"}},
+			OvnHardwareOffload: &sriovnetworkv1.OvnHardwareOffloadConfig{
+				PfNames: []string{"ens1f0", "ens1f1"},
+				NumVfs:  4,
+			},
+		},
+	}
+	// the node is configured and ovs-vswitchd offloads, the device plugin doesn't advertise the management VF yet
+	readyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready", Labels: map[string]string{"hwol": "true"},
+			Annotations: map[string]string{
+				consts.OvsHwOffloadStateAnnotation:        consts.OvsHwOffloadStateEnabled,
+				consts.OvsHwOffloadRepresentorsAnnotation: consts.OvsHwOffloadRepresentorsNamed,
+			}},
+	}
+	// the representors of the node keep the names of the kernel
+	pendingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "pending", Labels: map[string]string{"hwol": "true"},
+		Annotations: map[string]string{consts.OvsHwOffloadRepresentorsAnnotation: consts.OvsHwOffloadRepresentorsCreated}}}
+	pendingState := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			SyncStatus: consts.SyncStatusSucceeded,
+			Interfaces: sriovnetworkv1.InterfaceExts{
+				{Name: "ens1f0", EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+				{Name: "ens1f1", EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+			},
+		},
+	}
+	readyState := &sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			SyncStatus: consts.SyncStatusSucceeded,
+			Interfaces: sriovnetworkv1.InterfaceExts{
+				{Name: "ens1f0", EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+				{Name: "ens1f1", EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+			},
+		},
+	}
+	// a policy of a former configuration of the pool
+	stalePolicy := &sriovnetworkv1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "ovn-hwol-hwol-stale",
+		Namespace: vars.Namespace, Labels: map[string]string{consts.OvnHardwareOffloadPoolLabel: "hwol"}}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(pool, readyNode, pendingNode, readyState, pendingState, stalePolicy).
+		WithStatusSubresource(pool).
+		Build()
+	reconciler := &SriovNetworkPoolConfigReconciler{Client: c, Scheme: scheme}
+
+	g.Expect(reconciler.syncOvnHardwareOffload(ctx, pool)).To(Succeed())
+
+	policies := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	g.Expect(c.List(ctx, policies)).To(Succeed())
+	g.Expect(policies.Items).To(HaveLen(2))
+	mgmt := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: "ovn-hwol-hwol-mgmt"}, mgmt)).To(Succeed())
+	g.Expect(mgmt.Spec.ResourceName).To(Equal("ovn_hwol_mgmt"))
+	g.Expect(mgmt.Spec.NicSelector.PfNames).To(Equal([]string{"ens1f0#0-0"}))
+	g.Expect(mgmt.Spec.EswitchMode).To(Equal(sriovnetworkv1.ESwithModeSwitchDev))
+	vfs := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: "ovn-hwol-hwol"}, vfs)).To(Succeed())
+	g.Expect(vfs.Spec.ResourceName).To(Equal("ovn_hwol"))
+	g.Expect(vfs.Spec.NicSelector.PfNames).To(Equal([]string{"ens1f0#1-3", "ens1f1"}))
+	g.Expect(vfs.Spec.NodeSelector).To(Equal(map[string]string{"hwol": "true"}))
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(pool), pool)).To(Succeed())
+	g.Expect(pool.Status.OvnHardwareOffload).To(Equal(&sriovnetworkv1.OvnHardwareOffloadStatus{
+		Nodes: 2,
+		Steps: []sriovnetworkv1.OvnHardwareOffloadStep{
+			{Name: "Switchdev", ReadyNodes: 2},
+			{Name: "Representors", ReadyNodes: 2},
+			{Name: "UdevNaming", ReadyNodes: 1, Message: "pending on pending"},
+			{Name: "OvsHardwareOffload", ReadyNodes: 1, Message: "pending on pending"},
+			{Name: "ManagementVf", ReadyNodes: 0, Message: "pending on pending, ready"},
+		},
+	}))
+
+	// disabling the hardware offload removes the policies and the status
+	pool.Spec.OvnHardwareOffload = nil
+	g.Expect(c.Update(ctx, pool)).To(Succeed())
+	g.Expect(reconciler.syncOvnHardwareOffload(ctx, pool)).To(Succeed())
+	g.Expect(c.List(ctx, policies)).To(Succeed())
+	g.Expect(policies.Items).To(BeEmpty())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(pool), pool)).To(Succeed())
+	g.Expect(pool.Status.OvnHardwareOffload).To(BeNil())
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// resourcePoolsSyncInterval is the period of the computation of the allocated VFs, the pods requesting them
+// are read from the cache but not watched
+const resourcePoolsSyncInterval = time.Minute
+
+// SriovNetworkResourceMapReconciler maintains the capacity of the resources of the default SriovNetworkResourceMap,
+// computed from the SriovNetworkNodeStates, the allocatable resources of the nodes and the requests of the pods
+type SriovNetworkResourceMapReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworkresourcemaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworkresourcemaps/status,verbs=get;update;patch
+
+// Reconcile updates the pools of the default SriovNetworkResourceMap
+func (r *SriovNetworkResourceMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("resourcemap")
+
+	rm := &sriovnetworkv1.SriovNetworkResourceMap{}
+	if err := r.Get(ctx, req.NamespacedName, rm); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	nodeStates := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := r.List(ctx, nodeStates, client.InNamespace(vars.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	pools := renderResourcePools(rm.Status.Resources, nodeStates, nodes, pods)
+	if !equality.Semantic.DeepEqual(rm.Status.Pools, pools) || rm.Status.PoolsUpdateTime == nil {
+		now := metav1.Now()
+		rm.Status.Pools = pools
+		rm.Status.PoolsUpdateTime = &now
+		if err := r.Status().Update(ctx, rm); err != nil {
+			return reconcile.Result{}, err
+		}
+		logger.V(1).Info("Updated the resource pools", "pools", len(pools))
+	}
+	return reconcile.Result{RequeueAfter: resourcePoolsSyncInterval}, nil
+}
+
+// renderResourcePools returns the capacity of the resources of the map and of the resources configured in the
+// node states, the VFs of a node count once its node state is found
+func renderResourcePools(resources []sriovnetworkv1.SriovNetworkResource, nodeStates *sriovnetworkv1.SriovNetworkNodeStateList,
+	nodes *corev1.NodeList, pods *corev1.PodList) []sriovnetworkv1.SriovNetworkResourcePool {
+	pools := map[string]*sriovnetworkv1.SriovNetworkResourcePool{}
+	pool := func(resourceName string) *sriovnetworkv1.SriovNetworkResourcePool {
+		if pools[resourceName] == nil {
+			pools[resourceName] = &sriovnetworkv1.SriovNetworkResourcePool{ResourceName: resourceName}
+		}
+		return pools[resourceName]
+	}
+	for _, resource := range resources {
+		pool(resource.ResourceName)
+	}
+	for _, ns := range nodeStates.Items {
+		for _, iface := range ns.Spec.Interfaces {
+			for _, group := range iface.VfGroups {
+				if group.ResourceName == "" {
+					continue
+				}
+				pool(group.ResourceName).TotalVfs += group.NumVfs()
+			}
+		}
+	}
+
+	for resourceName, p := range pools {
+		extendedResourceName := sriovnetworkv1.ExtendedResourceName(resourceName)
+		for _, node := range nodes.Items {
+			allocatable, ok := node.Status.Allocatable[extendedResourceName]
+			if !ok || allocatable.IsZero() {
+				continue
+			}
+			p.Allocatable += allocatable.Value()
+			p.Nodes = append(p.Nodes, node.Name)
+		}
+		sort.Strings(p.Nodes)
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			p.Allocated += podResourceRequest(pod, extendedResourceName)
+		}
+	}
+
+	result := []sriovnetworkv1.SriovNetworkResourcePool{}
+	for _, p := range pools {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ResourceName < result[j].ResourceName
+	})
+	return result
+}
+
+// podResourceRequest returns the request of the pod for the resource, the largest of the sum of the requests of the
+// containers and of the request of each init container, as the scheduler accounts it
+func podResourceRequest(pod *corev1.Pod, name corev1.ResourceName) int64 {
+	var request int64
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[name]; ok {
+			request += q.Value()
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if q, ok := c.Resources.Requests[name]; ok && q.Value() > request {
+			request = q.Value()
+		}
+	}
+	return request
+}
+
+// TrimPodRequests is the transform of the cache of the pods of all the namespaces, it keeps the node, the phase and
+// the resource requests of the containers the pools are computed from
+func TrimPodRequests(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	trimContainers := func(containers []corev1.Container) []corev1.Container {
+		trimmed := make([]corev1.Container, 0, len(containers))
+		for _, c := range containers {
+			trimmed = append(trimmed, corev1.Container{Name: c.Name, Resources: corev1.ResourceRequirements{Requests: c.Resources.Requests}})
+		}
+		return trimmed
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:       pod.Spec.NodeName,
+			Containers:     trimContainers(pod.Spec.Containers),
+			InitContainers: trimContainers(pod.Spec.InitContainers),
+		},
+		Status: corev1.PodStatus{Phase: pod.Status.Phase},
+	}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SriovNetworkResourceMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	defaultMap := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}}
+	enqueueDefaultMap := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		return []reconcile.Request{defaultMap}
+	})
+
+	// the pools only depend on the allocatable resources of the nodes
+	allocatableChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !equality.Semantic.DeepEqual(e.ObjectOld.(*corev1.Node).Status.Allocatable,
+				e.ObjectNew.(*corev1.Node).Status.Allocatable)
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sriovnetworkresourcemap").
+		For(&sriovnetworkv1.SriovNetworkResourceMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == constants.DefaultConfigName
+		}))).
+		Watches(&corev1.Node{}, enqueueDefaultMap, builder.WithPredicates(allocatableChanged)).
+		Watches(&sriovnetworkv1.SriovNetworkNodeState{}, enqueueDefaultMap, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
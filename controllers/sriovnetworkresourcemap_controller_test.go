@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestSriovNetworkResourceMapPools(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("RESOURCE_PREFIX", "openshift.io")
+
+	nodeWithResource := func(name string, allocatable int64) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				"openshift.io/nic1": *resource.NewQuantity(allocatable, resource.DecimalSI),
+			}},
+		}
+	}
+	podRequesting := func(name, nodeName string, phase corev1.PodPhase, request int64) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "workloads"},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					"openshift.io/nic1": *resource.NewQuantity(request, resource.DecimalSI),
+				}}}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	nodeState := func(name string, groups ...sriovnetworkv1.VfGroup) *sriovnetworkv1.SriovNetworkNodeState {
+		return &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:86:00.0",
+				NumVfs:     8,
+				VfGroups:   groups,
+			}}},
+		}
+	}
+	rm := &sriovnetworkv1.SriovNetworkResourceMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Status: sriovnetworkv1.SriovNetworkResourceMapStatus{Resources: []sriovnetworkv1.SriovNetworkResource{
+			{ResourceName: "nic0", ExtendedResourceName: "openshift.io/nic0"},
+			{ResourceName: "nic1", ExtendedResourceName: "openshift.io/nic1"},
+		}},
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(rm,
+			nodeWithResource("node1", 4), nodeWithResource("node2", 2), nodeWithResource("node3", 0),
+			nodeState("node1", sriovnetworkv1.VfGroup{ResourceName: "nic1", VfRange: "0-3"}, sriovnetworkv1.VfGroup{ResourceName: "nic2", VfRange: "4-7"}),
+			nodeState("node2", sriovnetworkv1.VfGroup{ResourceName: "nic1", VfRange: "0-1"}),
+			podRequesting("running", "node1", corev1.PodRunning, 2),
+			podRequesting("pending", "node2", corev1.PodPending, 1),
+			podRequesting("completed", "node2", corev1.PodSucceeded, 1),
+			podRequesting("unscheduled", "", corev1.PodPending, 1)).
+		WithStatusSubresource(rm).
+		Build()
+	reconciler := &SriovNetworkResourceMapReconciler{Client: c, Scheme: scheme}
+
+	result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(rm)})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(resourcePoolsSyncInterval))
+
+	updated := &sriovnetworkv1.SriovNetworkResourceMap{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(rm), updated)).To(Succeed())
+	g.Expect(updated.Status.PoolsUpdateTime).ToNot(BeNil())
+	g.Expect(updated.Status.Resources).To(Equal(rm.Status.Resources))
+	g.Expect(updated.Status.Pools).To(Equal([]sriovnetworkv1.SriovNetworkResourcePool{
+		{ResourceName: "nic0"},
+		{ResourceName: "nic1", TotalVfs: 6, Allocatable: 6, Allocated: 3, Nodes: []string{"node1", "node2"}},
+		{ResourceName: "nic2", TotalVfs: 4},
+	}))
+
+	// an unchanged capacity doesn't update the map
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(rm)})
+	g.Expect(err).ToNot(HaveOccurred())
+	unchanged := &sriovnetworkv1.SriovNetworkResourceMap{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(rm), unchanged)).To(Succeed())
+	g.Expect(unchanged.ResourceVersion).To(Equal(updated.ResourceVersion))
+}
+
+func TestPodResourceRequest(t *testing.T) {
+	g := NewGomegaWithT(t)
+	requests := func(n int64) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			"openshift.io/nic1": *resource.NewQuantity(n, resource.DecimalSI),
+		}}
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers:     []corev1.Container{{Resources: requests(1)}, {Resources: requests(1)}, {}},
+		InitContainers: []corev1.Container{{Resources: requests(1)}},
+	}}
+	g.Expect(podResourceRequest(pod, "openshift.io/nic1")).To(Equal(int64(2)))
+	g.Expect(podResourceRequest(pod, "openshift.io/nic0")).To(Equal(int64(0)))
+
+	pod.Spec.InitContainers[0].Resources = requests(3)
+	g.Expect(podResourceRequest(pod, "openshift.io/nic1")).To(Equal(int64(3)))
+}
+
+func TestTrimPodRequests(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "tenant", Labels: map[string]string{"app": "test"}},
+		Spec: corev1.PodSpec{
+			NodeName: "worker-0",
+			Containers: []corev1.Container{{Name: "app", Image: "app:latest", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{"openshift.io/nic1": *resource.NewQuantity(2, resource.DecimalSI)},
+			}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+	}
+	obj, err := TrimPodRequests(pod)
+	g.Expect(err).ToNot(HaveOccurred())
+	trimmed := obj.(*corev1.Pod)
+	g.Expect(trimmed.Labels).To(BeEmpty())
+	g.Expect(trimmed.Spec.Containers[0].Image).To(BeEmpty())
+	g.Expect(trimmed.Status.PodIP).To(BeEmpty())
+	g.Expect(trimmed.Spec.NodeName).To(Equal("worker-0"))
+	g.Expect(trimmed.Status.Phase).To(Equal(corev1.PodRunning))
+	g.Expect(podResourceRequest(trimmed, "openshift.io/nic1")).To(Equal(int64(2)))
+}
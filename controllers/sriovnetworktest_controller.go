@@ -0,0 +1,365 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	networkTestDefaultTimeout = 300 * time.Second
+	networkTestPollInterval   = 5 * time.Second
+)
+
+var allNetworkTests = []sriovnetworkv1.SriovNetworkTestType{"ping", "iperf", "rdma_bw"}
+
+// the server pod answers iperf3 and rdma_bw requests until it's deleted
+const networkTestServerScript = `iperf3 -s -D
+while true; do ib_write_bw -D 5 >/dev/null 2>&1 || sleep 1; done`
+
+// the client pod writes one "<test> <pass|fail> <summary>" line per test to its termination message
+const networkTestClientScript = `for t in $TESTS; do
+  case $t in
+  ping) out=$(ping -c 5 -W 2 $SERVER_IP 2>&1); rc=$?; sum=$(echo "$out" | grep "packet loss") ;;
+  iperf) out=$(iperf3 -c $SERVER_IP -t 5 2>&1); rc=$?; sum=$(echo "$out" | grep receiver | tail -1) ;;
+  rdma_bw) out=$(ib_write_bw -D 5 $SERVER_IP 2>&1); rc=$?; sum=$(echo "$out" | tail -2 | head -1) ;;
+  esac
+  if [ $rc -eq 0 ]; then r=pass; else r=fail; sum=$(echo "$out" | tail -1); fi
+  echo "$t $r $sum" | tr -s ' ' >> /dev/termination-log
+done`
+
+// SriovNetworkTestReconciler reconciles a SriovNetworkTest object
+type SriovNetworkTestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// APIReader reads the test pods, they run in the network namespace which is not cached
+	APIReader client.Reader
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworktests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworktests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworktests/finalizers,verbs=update
+
+// Reconcile runs the test pods of a SriovNetworkTest and records their results in its status
+func (r *SriovNetworkTestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.FromContext(ctx).WithValues("sriovnetworktest", req.NamespacedName)
+	reqLogger.Info("Reconciling SriovNetworkTest")
+
+	instance := &sriovnetworkv1.SriovNetworkTest{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		if sriovnetworkv1.StringInArray(sriovnetworkv1.NETWORKTESTFINALIZERNAME, instance.ObjectMeta.Finalizers) {
+			if err := r.deleteTestPods(ctx, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+			instance.ObjectMeta.Finalizers, _ = sriovnetworkv1.RemoveString(sriovnetworkv1.NETWORKTESTFINALIZERNAME, instance.ObjectMeta.Finalizers)
+			if err := r.Update(ctx, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	switch instance.Status.Phase {
+	case constants.NetworkTestPhaseSucceeded, constants.NetworkTestPhaseFailed:
+		return reconcile.Result{}, r.deleteTestPods(ctx, instance)
+	case "":
+		if !sriovnetworkv1.StringInArray(sriovnetworkv1.NETWORKTESTFINALIZERNAME, instance.ObjectMeta.Finalizers) {
+			instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, sriovnetworkv1.NETWORKTESTFINALIZERNAME)
+			if err := r.Update(ctx, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		now := metav1.Now()
+		instance.Status.Phase = constants.NetworkTestPhasePending
+		instance.Status.StartTime = &now
+		return reconcile.Result{Requeue: true}, r.Status().Update(ctx, instance)
+	}
+
+	timeout := networkTestDefaultTimeout
+	if instance.Spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(instance.Spec.TimeoutSeconds) * time.Second
+	}
+	if instance.Status.StartTime != nil && time.Since(instance.Status.StartTime.Time) > timeout {
+		return reconcile.Result{}, r.finish(ctx, instance, constants.NetworkTestPhaseFailed, "timed out waiting for the test pods")
+	}
+
+	network := &sriovnetworkv1.SriovNetwork{}
+	err = r.Get(ctx, types.NamespacedName{Name: instance.Spec.NetworkName, Namespace: vars.Namespace}, network)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, r.finish(ctx, instance, constants.NetworkTestPhaseFailed,
+				fmt.Sprintf("SriovNetwork %s not found", instance.Spec.NetworkName))
+		}
+		return reconcile.Result{}, err
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList, client.MatchingLabels(instance.Spec.NodeSelector)); err != nil {
+		return reconcile.Result{}, err
+	}
+	nodes := []string{}
+	for _, node := range nodeList.Items {
+		nodes = append(nodes, node.Name)
+	}
+	if len(nodes) < 2 {
+		return reconcile.Result{}, r.finish(ctx, instance, constants.NetworkTestPhaseFailed,
+			fmt.Sprintf("at least 2 nodes are needed, %d selected", len(nodes)))
+	}
+	sort.Strings(nodes)
+
+	results, done, err := r.runTestPods(ctx, instance, network, nodes)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !done {
+		if instance.Status.Phase != constants.NetworkTestPhaseRunning {
+			instance.Status.Phase = constants.NetworkTestPhaseRunning
+			if err := r.Status().Update(ctx, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{RequeueAfter: networkTestPollInterval}, nil
+	}
+
+	instance.Status.Results = results
+	phase := constants.NetworkTestPhaseSucceeded
+	message := fmt.Sprintf("%d tests passed", len(results))
+	for _, result := range results {
+		if !result.Passed {
+			phase = constants.NetworkTestPhaseFailed
+			message = "some tests failed"
+			break
+		}
+	}
+	reqLogger.Info("SriovNetworkTest completed", "phase", phase)
+	return reconcile.Result{}, r.finish(ctx, instance, phase, message)
+}
+
+// runTestPods makes sure every node runs a server pod, and once the servers are up a client pod
+// testing the server of the next node. It returns the results when all the clients completed.
+func (r *SriovNetworkTestReconciler) runTestPods(ctx context.Context, instance *sriovnetworkv1.SriovNetworkTest,
+	network *sriovnetworkv1.SriovNetwork, nodes []string) ([]sriovnetworkv1.SriovNetworkTestResult, bool, error) {
+	namespace := network.Spec.NetworkNamespace
+	if namespace == "" {
+		namespace = network.Namespace
+	}
+
+	serverIPs := make([]string, len(nodes))
+	serversReady := true
+	for i, node := range nodes {
+		pod, err := r.ensureTestPod(ctx, instance, network, namespace, networkTestPodName(instance, "server", i), node, networkTestServerScript, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		serverIPs[i] = networkIP(pod, namespace+"/"+network.Name)
+		if pod.Status.Phase != corev1.PodRunning || serverIPs[i] == "" {
+			serversReady = false
+		}
+	}
+	if !serversReady {
+		return nil, false, nil
+	}
+
+	tests := instance.Spec.Tests
+	if len(tests) == 0 {
+		tests = allNetworkTests
+	}
+	testNames := []string{}
+	for _, t := range tests {
+		testNames = append(testNames, string(t))
+	}
+
+	results := []sriovnetworkv1.SriovNetworkTestResult{}
+	done := true
+	for i, node := range nodes {
+		next := (i + 1) % len(nodes)
+		env := []corev1.EnvVar{
+			{Name: "SERVER_IP", Value: serverIPs[next]},
+			{Name: "TESTS", Value: strings.Join(testNames, " ")},
+		}
+		pod, err := r.ensureTestPod(ctx, instance, network, namespace, networkTestPodName(instance, "client", i), node, networkTestClientScript, env)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].State.Terminated == nil {
+			done = false
+			continue
+		}
+		results = append(results, parseNetworkTestOutput(pod.Status.ContainerStatuses[0].State.Terminated.Message, node, nodes[next])...)
+	}
+	return results, done, nil
+}
+
+// networkTestPodName returns the name of a test pod of the run, the tests of the same name in other namespaces or
+// recreated meanwhile run their pods in the same network namespace
+func networkTestPodName(instance *sriovnetworkv1.SriovNetworkTest, role string, i int) string {
+	run := string(instance.UID)
+	if len(run) > 8 {
+		run = run[:8]
+	}
+	return fmt.Sprintf("%s-%s-%s-%d", instance.Name, run, role, i)
+}
+
+// ensureTestPod returns the named test pod, creating it when it doesn't exist
+func (r *SriovNetworkTestReconciler) ensureTestPod(ctx context.Context, instance *sriovnetworkv1.SriovNetworkTest,
+	network *sriovnetworkv1.SriovNetwork, namespace, name, node, script string, env []corev1.EnvVar) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, pod)
+	if err == nil {
+		return pod, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	resourceName := sriovnetworkv1.ExtendedResourceName(network.Spec.ResourceName)
+	pod = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				constants.NetworkTestLabel:    instance.Name,
+				constants.NetworkTestRunLabel: string(instance.UID),
+			},
+			Annotations: map[string]string{netattdefv1.NetworkAttachmentAnnot: network.Name},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      node,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    "test",
+				Image:   instance.Spec.Image,
+				Command: []string{"/bin/sh", "-c", script},
+				Env:     env,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{resourceName: resource.MustParse("1")},
+					Limits:   corev1.ResourceList{resourceName: resource.MustParse("1")},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"IPC_LOCK", "NET_RAW"}},
+				},
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			}},
+		},
+	}
+	log.FromContext(ctx).Info("Create test pod", "Namespace", namespace, "Name", name, "node", node)
+	if err := r.Create(ctx, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// deleteTestPods removes all the pods created for the run of the test, the pods of the other runs are kept
+func (r *SriovNetworkTestReconciler) deleteTestPods(ctx context.Context, instance *sriovnetworkv1.SriovNetworkTest) error {
+	podList := &corev1.PodList{}
+	if err := r.APIReader.List(ctx, podList, client.MatchingLabels{constants.NetworkTestRunLabel: string(instance.UID)}); err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		if err := r.Delete(ctx, &podList.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SriovNetworkTestReconciler) finish(ctx context.Context, instance *sriovnetworkv1.SriovNetworkTest, phase, message string) error {
+	now := metav1.Now()
+	instance.Status.Phase = phase
+	instance.Status.Message = message
+	instance.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, instance); err != nil {
+		return err
+	}
+	return r.deleteTestPods(ctx, instance)
+}
+
+// networkIP returns the first IP of the pod on the named network from the network-status annotation
+func networkIP(pod *corev1.Pod, network string) string {
+	status, ok := pod.Annotations[netattdefv1.NetworkStatusAnnot]
+	if !ok {
+		return ""
+	}
+	networks := []netattdefv1.NetworkStatus{}
+	if err := json.Unmarshal([]byte(status), &networks); err != nil {
+		return ""
+	}
+	for _, n := range networks {
+		if n.Name == network && len(n.IPs) > 0 {
+			return n.IPs[0]
+		}
+	}
+	return ""
+}
+
+// parseNetworkTestOutput parses the client termination message
+func parseNetworkTestOutput(output, source, destination string) []sriovnetworkv1.SriovNetworkTestResult {
+	results := []sriovnetworkv1.SriovNetworkTestResult{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		result := sriovnetworkv1.SriovNetworkTestResult{
+			Test:            sriovnetworkv1.SriovNetworkTestType(fields[0]),
+			SourceNode:      source,
+			DestinationNode: destination,
+			Passed:          fields[1] == "pass",
+		}
+		if len(fields) == 3 {
+			result.Output = fields[2]
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SriovNetworkTestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sriovnetworkv1.SriovNetworkTest{}).
+		Complete(r)
+}
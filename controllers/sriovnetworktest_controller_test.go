@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+var _ = Describe("SriovNetworkTest controller", func() {
+	Context("parseNetworkTestOutput", func() {
+		It("should parse the client termination message", func() {
+			output := "ping pass 5 packets transmitted, 5 received, 0% packet loss, time 4005ms\n" +
+				"iperf fail iperf3: error - unable to connect to server\n"
+			Expect(parseNetworkTestOutput(output, "node1", "node2")).To(Equal([]sriovnetworkv1.SriovNetworkTestResult{
+				{
+					Test:            "ping",
+					SourceNode:      "node1",
+					DestinationNode: "node2",
+					Passed:          true,
+					Output:          "5 packets transmitted, 5 received, 0% packet loss, time 4005ms",
+				},
+				{
+					Test:            "iperf",
+					SourceNode:      "node1",
+					DestinationNode: "node2",
+					Passed:          false,
+					Output:          "iperf3: error - unable to connect to server",
+				},
+			}))
+		})
+
+		It("should ignore empty output", func() {
+			Expect(parseNetworkTestOutput("", "node1", "node2")).To(BeEmpty())
+		})
+	})
+
+	Context("networkIP", func() {
+		It("should return the IP on the test network", func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				netattdefv1.NetworkStatusAnnot: `[{"name":"ovn-kubernetes","ips":["10.128.0.10"],"default":true},` +
+					`{"name":"test-ns/test-net","interface":"net1","ips":["192.168.10.2"]}]`,
+			}}}
+			Expect(networkIP(pod, "test-ns/test-net")).To(Equal("192.168.10.2"))
+			Expect(networkIP(pod, "test-ns/other-net")).To(BeEmpty())
+		})
+
+		It("should return an empty IP when the pod has no network status", func() {
+			Expect(networkIP(&corev1.Pod{}, "test-ns/test-net")).To(BeEmpty())
+		})
+	})
+
+})
+
+func TestNetworkTestPodsOfRuns(t *testing.T) {
+	g := NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &SriovNetworkTestReconciler{Client: c, Scheme: scheme, APIReader: c}
+	network := &sriovnetworkv1.SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-net"},
+		Spec:       sriovnetworkv1.SriovNetworkSpec{ResourceName: "nic1"},
+	}
+	// the tests of the same name run their pods in the namespace of the network
+	run1 := &sriovnetworkv1.SriovNetworkTest{ObjectMeta: metav1.ObjectMeta{Name: "smoke", Namespace: "team-a", UID: "11111111-aaaa"}}
+	run2 := &sriovnetworkv1.SriovNetworkTest{ObjectMeta: metav1.ObjectMeta{Name: "smoke", Namespace: "team-b", UID: "22222222-bbbb"}}
+
+	for _, run := range []*sriovnetworkv1.SriovNetworkTest{run1, run2} {
+		pod, err := r.ensureTestPod(context.TODO(), run, network, "test-ns",
+			networkTestPodName(run, "server", 0), "node1", networkTestServerScript, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pod.Labels).To(HaveKeyWithValue(constants.NetworkTestRunLabel, string(run.UID)))
+	}
+
+	g.Expect(r.deleteTestPods(context.TODO(), run1)).To(Succeed())
+	pods := &corev1.PodList{}
+	g.Expect(c.List(context.TODO(), pods, client.InNamespace("test-ns"))).To(Succeed())
+	g.Expect(pods.Items).To(HaveLen(1))
+	g.Expect(pods.Items[0].Name).To(Equal("smoke-22222222-server-0"))
+}
@@ -18,31 +18,45 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	machinev1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	apply "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/apply"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
 	consts "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/nmstate"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
 	render "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/render"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/tracing"
 	utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
@@ -56,7 +70,9 @@ type SriovOperatorConfigReconciler struct {
 
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovoperatorconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovoperatorconfigs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovoperatorconfigs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,6 +101,11 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 				return reconcile.Result{}, fmt.Errorf("couldn't get cluster single node status: %s", err)
 			}
 
+			featureGates, err := sriovnetworkv1.ParseFeatureGates(os.Getenv("FEATURE_GATES"))
+			if err != nil {
+				return reconcile.Result{}, fmt.Errorf("invalid FEATURE_GATES: %v", err)
+			}
+
 			// Default Config object not found, create it.
 			defaultConfig.SetNamespace(vars.Namespace)
 			defaultConfig.SetName(consts.DefaultConfigName)
@@ -95,6 +116,7 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 				LogLevel:                 2,
 				DisableDrain:             singleNode,
 				ConfigurationMode:        sriovnetworkv1.DaemonConfigurationMode,
+				FeatureGates:             featureGates,
 			}
 
 			err = r.Create(ctx, defaultConfig)
@@ -110,6 +132,7 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 	}
 
 	snolog.SetLogLevel(defaultConfig.Spec.LogLevel)
+	tracing.Configure(defaultConfig.TracingEndpoint(), "sriov-network-operator")
 
 	// Fetch the SriovNetworkNodePolicyList
 	policyList := &sriovnetworkv1.SriovNetworkNodePolicyList{}
@@ -130,30 +153,22 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		return reconcile.Result{}, nil
 	}
 
-	// Render and sync webhook objects
-	if err = r.syncWebhookObjs(ctx, defaultConfig); err != nil {
-		return reconcile.Result{}, err
-	}
-
 	// Sync SriovNetworkConfigDaemon objects
 	if err = r.syncConfigDaemonSet(ctx, defaultConfig); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	policyList.Items, _ = policySignatures.Filter(ctx, defaultConfig.Spec.PolicySignature, policyList.Items)
 	if err = syncPluginDaemonObjs(ctx, r.Client, r.Scheme, defaultPolicy, policyList); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// For Openshift we need to create the systemd files using a machine config
-	if vars.ClusterType == consts.ClusterTypeOpenshift {
-		// TODO: add support for hypershift as today there is no MCO on hypershift clusters
-		if r.PlatformHelper.IsHypershift() {
-			return ctrl.Result{}, fmt.Errorf("systemd mode is not supported on hypershift")
-		}
+	if err = r.syncFeatureGates(ctx, defaultConfig); err != nil {
+		return reconcile.Result{}, err
+	}
 
-		if err = r.syncOpenShiftSystemdService(ctx, defaultConfig); err != nil {
-			return reconcile.Result{}, err
-		}
+	if err = r.syncPausedNodes(ctx, defaultConfig); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	logger.Info("Reconcile SriovOperatorConfig completed successfully")
@@ -162,13 +177,62 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SriovOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the monitoring objects are derived from the resource pools, they are re-rendered when a pool is added or removed
+	defaultConfigHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: consts.DefaultConfigName, Namespace: vars.Namespace}}}
+	})
+	resourceNameChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPolicy, okOld := e.ObjectOld.(*sriovnetworkv1.SriovNetworkNodePolicy)
+			newPolicy, okNew := e.ObjectNew.(*sriovnetworkv1.SriovNetworkNodePolicy)
+			return !okOld || !okNew || oldPolicy.Spec.ResourceName != newPolicy.Spec.ResourceName
+		},
+	}
+
+	// the paused nodes are reported in the status
+	syncStatusChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldState, okOld := e.ObjectOld.(*sriovnetworkv1.SriovNetworkNodeState)
+			newState, okNew := e.ObjectNew.(*sriovnetworkv1.SriovNetworkNodeState)
+			return !okOld || !okNew || oldState.Status.SyncStatus != newState.Status.SyncStatus
+		},
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sriovnetworkv1.SriovOperatorConfig{}).
 		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.ConfigMap{}).
+		Watches(&sriovnetworkv1.SriovNetworkNodePolicy{}, defaultConfigHandler, builder.WithPredicates(resourceNameChanged)).
+		Watches(&sriovnetworkv1.SriovNetworkNodeState{}, defaultConfigHandler, builder.WithPredicates(syncStatusChanged)).
+		// the daemon isn't confined while a pool has hooks
+		Watches(&sriovnetworkv1.SriovNetworkPoolConfig{}, defaultConfigHandler, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
+// openstackMetadataEnv returns the environment variables of the config daemon for the fields set in the
+// openstackMetadata of the SriovOperatorConfig
+func openstackMetadataEnv(config *sriovnetworkv1.OpenstackMetadataConfig) map[string]string {
+	env := map[string]string{}
+	if config == nil {
+		return env
+	}
+	seconds := map[string]int{
+		consts.OpenstackMetadataTimeoutEnv:    config.TimeoutSeconds,
+		consts.OpenstackMetadataBackoffMinEnv: config.BackoffMinSeconds,
+		consts.OpenstackMetadataBackoffMaxEnv: config.BackoffMaxSeconds,
+		consts.OpenstackMetadataDeadlineEnv:   config.DeadlineSeconds,
+	}
+	for name, value := range seconds {
+		if value > 0 {
+			env[name] = fmt.Sprintf("%ds", value)
+		}
+	}
+	if config.Retries != nil {
+		env[consts.OpenstackMetadataRetriesEnv] = strconv.Itoa(*config.Retries)
+	}
+	return env
+}
+
 func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig) error {
 	logger := log.Log.WithName("syncConfigDaemonset")
 	logger.V(1).Info("Start to sync config daemonset")
@@ -182,11 +246,21 @@ func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context,
 	data.Data["ClusterType"] = vars.ClusterType
 	data.Data["DevMode"] = os.Getenv("DEV_MODE")
 	data.Data["ImagePullSecrets"] = GetImagePullSecrets()
-	if dc.Spec.ConfigurationMode == sriovnetworkv1.SystemdConfigurationMode {
-		data.Data["UsedSystemdMode"] = true
-	} else {
-		data.Data["UsedSystemdMode"] = false
+	data.Data["UsedSystemdMode"] = dc.FeatureGateEnabled(consts.SystemdModeFeatureGate)
+	// the daemon running as a systemd service or the hooks of a pool isn't confined, see syncConfinedDaemon
+	confined := dc.FeatureGateEnabled(consts.ConfinedDaemonFeatureGate) && !dc.FeatureGateEnabled(consts.SystemdModeFeatureGate)
+	if confined {
+		poolWithHooks, err := r.poolWithHooks(ctx)
+		if err != nil {
+			return err
+		}
+		confined = poolWithHooks == ""
 	}
+	data.Data["ConfinedDaemon"] = confined
+	data.Data["PrivilegedHelperSocket"] = consts.PrivilegedHelperSocket
+	data.Data["ConfinedDaemonSeccompProfile"] = consts.ConfinedDaemonSeccompProfile
+	data.Data["ConfinedDaemonSELinuxType"] = consts.ConfinedDaemonSELinuxType
+	data.Data["NMStateIntegration"] = dc.FeatureGateEnabled(consts.NMStateIntegrationFeatureGate) && !dc.FeatureGateEnabled(consts.SystemdModeFeatureGate)
 
 	envCniBinPath := os.Getenv("SRIOV_CNI_BIN_PATH")
 	if envCniBinPath == "" {
@@ -201,11 +275,18 @@ func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context,
 		data.Data["DisablePlugins"] = strings.Join(dc.Spec.DisablePlugins.ToStringSlice(), ",")
 	}
 
+	data.Data["OpenstackMetadataEnv"] = openstackMetadataEnv(dc.Spec.OpenstackMetadata)
+
 	objs, err := render.RenderDir(consts.ConfigDaemonPath, &data)
 	if err != nil {
 		logger.Error(err, "Fail to render config daemon manifests")
 		return err
 	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, dc.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovOperatorConfig", dc), consts.ConfigDaemonPath)
 	// Sync DaemonSets
 	for _, obj := range objs {
 		if obj.GetKind() == "DaemonSet" && len(dc.Spec.ConfigDaemonNodeSelector) > 0 {
@@ -216,7 +297,7 @@ func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context,
 				logger.Error(err, "Fail to convert to DaemonSet")
 				return err
 			}
-			ds.Spec.Template.Spec.NodeSelector = dc.Spec.ConfigDaemonNodeSelector
+			ds.Spec.Template.Spec.NodeSelector = daemonNodeSelector(dc.Spec.ConfigDaemonNodeSelector)
 			err = scheme.Convert(ds, obj, nil)
 			if err != nil {
 				logger.Error(err, "Fail to convert to Unstructured")
@@ -232,77 +313,459 @@ func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context,
 	return nil
 }
 
-func (r *SriovOperatorConfigReconciler) syncWebhookObjs(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig) error {
+// optionalComponent is a component of the operator enabled by a feature gate
+type optionalComponent struct {
+	gate string
+	sync func(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error
+}
+
+// syncFeatureGates enables or disables the optional components according to their feature gates and reports
+// their effective state in the status. The components are reconciled independently, the failure of one of them
+// doesn't keep the others from being enabled or disabled.
+func (r *SriovOperatorConfigReconciler) syncFeatureGates(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig) error {
+	logger := log.Log.WithName("syncFeatureGates")
+	components := []optionalComponent{
+		{gate: consts.ResourceInjectorFeatureGate, sync: r.syncInjectorWebhook},
+		{gate: consts.OperatorWebhookFeatureGate, sync: r.syncOperatorWebhook},
+		{gate: consts.MetricsExporterFeatureGate, sync: r.syncMetricsExporter},
+		{gate: consts.SystemdModeFeatureGate, sync: r.syncSystemdMode},
+		{gate: consts.MonitoringFeatureGate, sync: r.syncMonitoring},
+		{gate: consts.ConfinedDaemonFeatureGate, sync: r.syncConfinedDaemon},
+		{gate: consts.NMStateIntegrationFeatureGate, sync: r.syncNMStateIntegration},
+	}
+
+	statuses := []sriovnetworkv1.FeatureGateStatus{}
+	var errs []error
+	for _, component := range components {
+		enabled := dc.FeatureGateEnabled(component.gate)
+		status := sriovnetworkv1.FeatureGateStatus{Name: component.gate, Enabled: enabled, State: consts.FeatureGateStateDisabled}
+		if enabled {
+			status.State = consts.FeatureGateStateEnabled
+		}
+		if err := component.sync(ctx, dc, enabled); err != nil {
+			logger.Error(err, "Couldn't sync the optional component", "featureGate", component.gate, "enabled", enabled)
+			status.State = consts.FeatureGateStateFailed
+			status.Message = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %v", component.gate, err))
+		}
+		statuses = append(statuses, status)
+	}
+
+	if !equality.Semantic.DeepEqual(dc.Status.FeatureGates, statuses) {
+		patch := client.MergeFrom(dc.DeepCopy())
+		dc.Status.FeatureGates = statuses
+		if err := r.Status().Patch(ctx, dc, patch); err != nil {
+			return fmt.Errorf("failed to update the feature gates status: %v", err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// syncPausedNodes reports the nodes holding changes while the configuration of the nodes is paused
+func (r *SriovOperatorConfigReconciler) syncPausedNodes(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig) error {
+	nodeStates := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := r.List(ctx, nodeStates, client.InNamespace(vars.Namespace)); err != nil {
+		return fmt.Errorf("failed to list the node states: %v", err)
+	}
+	var pausedNodes []string
+	for _, nodeState := range nodeStates.Items {
+		if nodeState.Status.SyncStatus == consts.SyncStatusPaused {
+			pausedNodes = append(pausedNodes, nodeState.Name)
+		}
+	}
+	sort.Strings(pausedNodes)
+
+	if !equality.Semantic.DeepEqual(dc.Status.PausedNodes, pausedNodes) {
+		patch := client.MergeFrom(dc.DeepCopy())
+		dc.Status.PausedNodes = pausedNodes
+		if err := r.Status().Patch(ctx, dc, patch); err != nil {
+			return fmt.Errorf("failed to update the paused nodes status: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *SriovOperatorConfigReconciler) syncInjectorWebhook(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	return r.syncWebhookObjs(ctx, dc, consts.InjectorWebHookName, consts.InjectorWebHookPath, enabled)
+}
+
+func (r *SriovOperatorConfigReconciler) syncOperatorWebhook(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	if err := r.syncWebhookObjs(ctx, dc, consts.OperatorWebHookName, consts.OperatorWebHookPath, enabled); err != nil {
+		return err
+	}
+	return r.syncPolicyConversionWebhook(ctx, enabled)
+}
+
+func (r *SriovOperatorConfigReconciler) syncWebhookObjs(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, name, path string, enabled bool) error {
 	logger := log.Log.WithName("syncWebhookObjs")
-	logger.V(1).Info("Start to sync webhook objects")
-
-	for name, path := range webhooks {
-		// Render Webhook manifests
-		data := render.MakeRenderData()
-		data.Data["Namespace"] = vars.Namespace
-		data.Data["SRIOVMutatingWebhookName"] = name
-		data.Data["NetworkResourcesInjectorImage"] = os.Getenv("NETWORK_RESOURCES_INJECTOR_IMAGE")
-		data.Data["SriovNetworkWebhookImage"] = os.Getenv("SRIOV_NETWORK_WEBHOOK_IMAGE")
-		data.Data["ReleaseVersion"] = os.Getenv("RELEASEVERSION")
-		data.Data["ClusterType"] = vars.ClusterType
-		data.Data["DevMode"] = os.Getenv("DEV_MODE")
-		data.Data["ImagePullSecrets"] = GetImagePullSecrets()
-		data.Data["CertManagerEnabled"] = strings.ToLower(os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_CERT_MANAGER_ENABLED")) == trueString
-		data.Data["OperatorWebhookSecretName"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME")
-		data.Data["OperatorWebhookCA"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_CA_CRT")
-		data.Data["InjectorWebhookSecretName"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_SECRET_NAME")
-		data.Data["InjectorWebhookCA"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_CA_CRT")
-
-		data.Data["ExternalControlPlane"] = false
-		if r.PlatformHelper.IsOpenshiftCluster() {
-			external := r.PlatformHelper.IsHypershift()
-			data.Data["ExternalControlPlane"] = external
-		}
-
-		objs, err := render.RenderDir(path, &data)
+	logger.V(1).Info("Start to sync webhook objects", "name", name)
+
+	// Render Webhook manifests
+	data := render.MakeRenderData()
+	data.Data["Namespace"] = vars.Namespace
+	data.Data["SRIOVMutatingWebhookName"] = name
+	data.Data["NetworkResourcesInjectorImage"] = os.Getenv("NETWORK_RESOURCES_INJECTOR_IMAGE")
+	data.Data["SriovNetworkWebhookImage"] = os.Getenv("SRIOV_NETWORK_WEBHOOK_IMAGE")
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASEVERSION")
+	data.Data["ClusterType"] = vars.ClusterType
+	data.Data["DevMode"] = os.Getenv("DEV_MODE")
+	data.Data["ResourcePrefix"] = os.Getenv("RESOURCE_PREFIX")
+	data.Data["ImagePullSecrets"] = GetImagePullSecrets()
+	data.Data["CertManagerEnabled"] = strings.ToLower(os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_CERT_MANAGER_ENABLED")) == trueString
+	data.Data["OperatorWebhookSecretName"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME")
+	data.Data["InjectorWebhookSecretName"] = os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_SECRET_NAME")
+	operatorCA, err := webhookCABundle(ctx, r, os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME"),
+		"ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_CA_CRT")
+	if err != nil {
+		return err
+	}
+	data.Data["OperatorWebhookCA"] = operatorCA
+	injectorCA, err := webhookCABundle(ctx, r, os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_SECRET_NAME"),
+		"ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_CA_CRT")
+	if err != nil {
+		return err
+	}
+	data.Data["InjectorWebhookCA"] = injectorCA
+	data.Data["EnableQuota"] = dc.Spec.EnableQuota
+	data.Data["EnablePodNetworkWarnings"] = dc.Spec.EnablePodNetworkWarnings
+	setServiceNetworking(&data, dc)
+
+	data.Data["ExternalControlPlane"] = false
+	if r.PlatformHelper.IsOpenshiftCluster() {
+		external := r.PlatformHelper.IsHypershift()
+		data.Data["ExternalControlPlane"] = external
+	}
+
+	objs, err := render.RenderDir(path, &data)
+	if err != nil {
+		logger.Error(err, "Fail to render webhook manifests")
+		return err
+	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, dc.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovOperatorConfig", dc), path)
+
+	if !enabled {
+		for _, obj := range objs {
+			err = r.deleteWebhookObject(ctx, obj)
+			if err != nil {
+				return err
+			}
+		}
+		logger.Info("Webhook is disabled, set its feature gate to true in the SriovOperatorConfig to enable it", "name", name)
+		return nil
+	}
+
+	// Sync Webhook
+	for _, obj := range objs {
+		err = r.syncK8sResource(ctx, dc, obj)
 		if err != nil {
-			logger.Error(err, "Fail to render webhook manifests")
+			logger.Error(err, "Couldn't sync webhook objects")
 			return err
 		}
+	}
+	return nil
+}
+
+// setServiceNetworking sets the IP families of the rendered services and the address the injector binds to. The
+// injector binds to all the IPv6 and IPv4 addresses of its pod when its service may be reached over IPv6, only to
+// the IPv4 addresses otherwise since IPv6 may be disabled in the pods of the IPv4 clusters.
+func setServiceNetworking(data *render.RenderData, dc *sriovnetworkv1.SriovOperatorConfig) {
+	bindAddress := "0.0.0.0"
+	if utils.ClusterPrimaryIPFamily() == corev1.IPv6Protocol || dc.Spec.ServiceIPFamilyPolicy == corev1.IPFamilyPolicyPreferDualStack ||
+		dc.Spec.ServiceIPFamilyPolicy == corev1.IPFamilyPolicyRequireDualStack {
+		bindAddress = "::"
+	}
+	families := []string{}
+	for _, family := range dc.Spec.ServiceIPFamilies {
+		families = append(families, string(family))
+		if family == corev1.IPv6Protocol {
+			bindAddress = "::"
+		}
+	}
+	data.Data["ServiceIPFamilyPolicy"] = string(dc.Spec.ServiceIPFamilyPolicy)
+	data.Data["ServiceIPFamilies"] = families
+	data.Data["BindAddress"] = bindAddress
+}
 
-		// Delete injector webhook
-		if !dc.Spec.EnableInjector && path == consts.InjectorWebHookPath {
-			for _, obj := range objs {
-				err = r.deleteWebhookObject(ctx, obj)
-				if err != nil {
-					return err
-				}
+// syncMetricsExporter deploys the daemonset exporting the statistics of the VFs to Prometheus
+func (r *SriovOperatorConfigReconciler) syncMetricsExporter(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	logger := log.Log.WithName("syncMetricsExporter")
+	logger.V(1).Info("Start to sync metrics exporter objects", "enabled", enabled)
+
+	image := os.Getenv("METRICS_EXPORTER_IMAGE")
+	data := render.MakeRenderData()
+	data.Data["Namespace"] = vars.Namespace
+	data.Data["MetricsExporterImage"] = image
+	data.Data["MetricsExporterPort"] = consts.MetricsExporterPort
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASEVERSION")
+	data.Data["ImagePullSecrets"] = GetImagePullSecrets()
+	data.Data["NodeSelectorField"] = GetDefaultNodeSelector()
+	if len(dc.Spec.ConfigDaemonNodeSelector) > 0 {
+		data.Data["NodeSelectorField"] = daemonNodeSelector(dc.Spec.ConfigDaemonNodeSelector)
+	}
+	setServiceNetworking(&data, dc)
+
+	objs, err := render.RenderDir(consts.MetricsExporterPath, &data)
+	if err != nil {
+		logger.Error(err, "Fail to render metrics exporter manifests")
+		return err
+	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, dc.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovOperatorConfig", dc), consts.MetricsExporterPath)
+
+	if !enabled {
+		for _, obj := range objs {
+			if err := r.deleteK8sResource(ctx, obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if image == "" {
+		return fmt.Errorf("the METRICS_EXPORTER_IMAGE environment variable of the operator is not set")
+	}
+	for _, obj := range objs {
+		if err := r.syncK8sResource(ctx, dc, obj); err != nil {
+			logger.Error(err, "Couldn't sync metrics exporter objects")
+			return err
+		}
+	}
+	return nil
+}
+
+// syncMonitoring deploys the Grafana dashboard and the Prometheus alert rules built from the metrics of the operator
+// and from the resource pools of the policies. The alert rules require the PrometheusRule CRD of the Prometheus
+// operator, the dashboard is deployed without it.
+func (r *SriovOperatorConfigReconciler) syncMonitoring(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	logger := log.Log.WithName("syncMonitoring")
+	logger.V(1).Info("Start to sync monitoring objects", "enabled", enabled)
+
+	pools, err := r.resourcePools(ctx)
+	if err != nil {
+		return err
+	}
+	dashboard, err := metrics.GrafanaDashboard(pools)
+	if err != nil {
+		return fmt.Errorf("failed to build the Grafana dashboard: %v", err)
+	}
+	data := render.MakeRenderData()
+	data.Data["Namespace"] = vars.Namespace
+	data.Data["GrafanaDashboard"] = dashboard
+	data.Data["ResourcePools"] = pools
+
+	objs, err := render.RenderDir(consts.MonitoringPath, &data)
+	if err != nil {
+		logger.Error(err, "Fail to render monitoring manifests")
+		return err
+	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, dc.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovOperatorConfig", dc), consts.MonitoringPath)
+
+	rulesInstalled, err := r.crdInstalled(ctx, prometheusRuleCRDName)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, obj := range objs {
+		if obj.GetKind() == prometheusRuleKind && !rulesInstalled {
+			if enabled {
+				errs = append(errs, fmt.Errorf("the %s CRD of the Prometheus operator is not installed, the alert rules are not deployed", prometheusRuleCRDName))
 			}
-			logger.Info("SR-IOV Admission Controller is disabled.")
-			logger.Info("To enable SR-IOV Admission Controller,")
-			logger.Info("Set 'SriovOperatorConfig.Spec.EnableInjector' to true(bool).")
 			continue
 		}
-		// Delete operator webhook
-		if !dc.Spec.EnableOperatorWebhook && path == consts.OperatorWebHookPath {
-			for _, obj := range objs {
-				err = r.deleteWebhookObject(ctx, obj)
-				if err != nil {
-					return err
-				}
+		if !enabled {
+			if err := r.deleteK8sResource(ctx, obj); err != nil {
+				return err
 			}
-			logger.Info("Operator Admission Controller is disabled.")
-			logger.Info("To enable Operator Admission Controller,")
-			logger.Info("Set 'SriovOperatorConfig.Spec.EnableOperatorWebhook' to true(bool).")
 			continue
 		}
+		if err := r.syncK8sResource(ctx, dc, obj); err != nil {
+			logger.Error(err, "Couldn't sync monitoring objects")
+			return err
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
 
-		// Sync Webhook
-		for _, obj := range objs {
-			err = r.syncK8sResource(ctx, dc, obj)
+// resourcePools returns the resource pools of the policies, sorted by name
+func (r *SriovOperatorConfigReconciler) resourcePools(ctx context.Context) ([]metrics.ResourcePool, error) {
+	policies := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(vars.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list SriovNetworkNodePolicies: %v", err)
+	}
+	names := []string{}
+	for _, p := range policies.Items {
+		if p.Spec.ResourceName != "" && !sriovnetworkv1.StringInArray(p.Spec.ResourceName, names) {
+			names = append(names, p.Spec.ResourceName)
+		}
+	}
+	sort.Strings(names)
+	pools := make([]metrics.ResourcePool, 0, len(names))
+	for _, name := range names {
+		pools = append(pools, metrics.NewResourcePool(name, sriovnetworkv1.ExtendedResourceName(name)))
+	}
+	return pools, nil
+}
+
+// crdInstalled returns whether the CRD of the given name is installed in the cluster
+func (r *SriovOperatorConfigReconciler) crdInstalled(ctx context.Context, name string) (bool, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := r.Get(ctx, types.NamespacedName{Name: name}, crd)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get the %s CRD: %v", name, err)
+	}
+	return true, nil
+}
+
+// syncSystemdMode deploys the systemd service configuring the SR-IOV devices on boot. The config daemon runs in
+// systemd mode on every cluster, the service is deployed with a machine config on OpenShift only.
+func (r *SriovOperatorConfigReconciler) syncSystemdMode(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	if vars.ClusterType != consts.ClusterTypeOpenshift {
+		return nil
+	}
+	// TODO: add support for hypershift as today there is no MCO on hypershift clusters
+	if r.PlatformHelper.IsHypershift() {
+		if enabled {
+			return fmt.Errorf("systemd mode is not supported on hypershift")
+		}
+		return nil
+	}
+	return r.syncOpenShiftSystemdService(ctx, dc, enabled)
+}
+
+// syncConfinedDaemon reports the confined config daemon as failed when the systemd mode is enabled too, the
+// daemon is then run as a systemd service of the host, or when a pool has hooks, the helper doesn't run their
+// commands. The config daemon daemonset renders the privileged daemon in both cases.
+func (r *SriovOperatorConfigReconciler) syncConfinedDaemon(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	if dc.FeatureGateEnabled(consts.SystemdModeFeatureGate) {
+		return fmt.Errorf("the confined config daemon is not supported in systemd mode")
+	}
+	poolWithHooks, err := r.poolWithHooks(ctx)
+	if err != nil {
+		return err
+	}
+	if poolWithHooks != "" {
+		return fmt.Errorf("the confined config daemon doesn't run the hooks of the SriovNetworkPoolConfig %s", poolWithHooks)
+	}
+	return nil
+}
+
+// poolWithHooks returns the name of a SriovNetworkPoolConfig with hooks, empty when there is none
+func (r *SriovOperatorConfigReconciler) poolWithHooks(ctx context.Context) (string, error) {
+	pools := &sriovnetworkv1.SriovNetworkPoolConfigList{}
+	if err := r.List(ctx, pools, client.InNamespace(vars.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list the pool configs: %v", err)
+	}
+	for _, pool := range pools.Items {
+		if len(pool.Spec.Hooks) > 0 {
+			return pool.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// syncNMStateIntegration reports the NMState integration as failed when kubernetes-nmstate is not installed or when
+// the systemd mode is enabled too, the config daemon daemonset renders the daemon leaving the PF settings to NMState
+func (r *SriovOperatorConfigReconciler) syncNMStateIntegration(ctx context.Context, dc *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	if dc.FeatureGateEnabled(consts.SystemdModeFeatureGate) {
+		return fmt.Errorf("the NMState integration is not supported in systemd mode")
+	}
+	if _, err := r.RESTMapper().RESTMapping(nmstate.PolicyGVK.GroupKind(), nmstate.PolicyGVK.Version); err != nil {
+		return fmt.Errorf("kubernetes-nmstate is not installed: %v", err)
+	}
+	return nil
+}
+
+// syncPolicyConversionWebhook serves the v2 version of the SriovNetworkNodePolicy CRD only when the
+// operator webhook, which converts between v1 and v2, is deployed
+func (r *SriovOperatorConfigReconciler) syncPolicyConversionWebhook(ctx context.Context, enabled bool) error {
+	logger := log.Log.WithName("syncPolicyConversionWebhook")
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := r.Get(ctx, types.NamespacedName{Name: consts.PolicyCRDName}, crd)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	conversion := &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter}
+	if enabled {
+		path := "/convert"
+		conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ConversionReviewVersions: []string{"v1"},
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					Service: &apiextensionsv1.ServiceReference{
+						Name:      consts.OperatorWebHookServiceName,
+						Namespace: vars.Namespace,
+						Path:      &path,
+					},
+				},
+			},
+		}
+		if crd.Spec.Conversion != nil && crd.Spec.Conversion.Webhook != nil && crd.Spec.Conversion.Webhook.ClientConfig != nil {
+			// keep the CA bundle injected by the service CA operator or cert-manager
+			conversion.Webhook.ClientConfig.CABundle = crd.Spec.Conversion.Webhook.ClientConfig.CABundle
+		}
+
+		annotations := crd.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		switch {
+		case vars.ClusterType == consts.ClusterTypeOpenshift:
+			annotations[consts.ServiceCAConfigMapAnnotation] = trueString
+		case strings.ToLower(os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_CERT_MANAGER_ENABLED")) == trueString:
+			annotations["cert-manager.io/inject-ca-from"] = vars.Namespace + "/" + os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME")
+		default:
+			bundle, err := webhookCABundle(ctx, r, os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME"),
+				"ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_CA_CRT")
 			if err != nil {
-				logger.Error(err, "Couldn't sync webhook objects")
 				return err
 			}
+			ca, err := base64.StdEncoding.DecodeString(bundle)
+			if err != nil {
+				return fmt.Errorf("failed to decode the operator webhook CA: %v", err)
+			}
+			conversion.Webhook.ClientConfig.CABundle = ca
 		}
+		crd.SetAnnotations(annotations)
 	}
 
-	return nil
+	changed := !equality.Semantic.DeepEqual(crd.Spec.Conversion, conversion)
+	crd.Spec.Conversion = conversion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == "v2" && crd.Spec.Versions[i].Served != enabled {
+			crd.Spec.Versions[i].Served = enabled
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	logger.Info("Update SriovNetworkNodePolicy CRD conversion", "v2-served", enabled)
+	return r.Update(ctx, crd)
 }
 
 func (r *SriovOperatorConfigReconciler) deleteWebhookObject(ctx context.Context, obj *uns.Unstructured) error {
@@ -335,10 +798,10 @@ func (r *SriovOperatorConfigReconciler) syncK8sResource(ctx context.Context, cr
 }
 
 // syncOpenShiftSystemdService creates the Machine Config to deploy the systemd service on openshift ONLY
-func (r *SriovOperatorConfigReconciler) syncOpenShiftSystemdService(ctx context.Context, cr *sriovnetworkv1.SriovOperatorConfig) error {
+func (r *SriovOperatorConfigReconciler) syncOpenShiftSystemdService(ctx context.Context, cr *sriovnetworkv1.SriovOperatorConfig, enabled bool) error {
 	logger := log.Log.WithName("syncSystemdService")
 
-	if cr.Spec.ConfigurationMode != sriovnetworkv1.SystemdConfigurationMode {
+	if !enabled {
 		obj := &machinev1.MachineConfig{}
 		err := r.Get(context.TODO(), types.NamespacedName{Name: consts.SystemdServiceOcpMachineConfigName}, obj)
 		if err != nil {
@@ -368,6 +831,11 @@ func (r *SriovOperatorConfigReconciler) syncOpenShiftSystemdService(ctx context.
 		logger.Error(err, "Fail to render config daemon manifests")
 		return err
 	}
+	if err := sriovnetworkv1.ApplyOverlays(objs, cr.Spec.Overlays); err != nil {
+		logger.Error(err, "Fail to apply the overlays")
+		return err
+	}
+	stampRendered(objs, audit.Owner("SriovOperatorConfig", cr), consts.SystemdServiceOcpPath)
 
 	// Sync machine config
 	return r.setLabelInsideObject(ctx, cr, objs)
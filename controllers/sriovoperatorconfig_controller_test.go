@@ -2,12 +2,29 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
 	"strings"
 	"sync"
+	"testing"
 
 	admv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -15,8 +32,10 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/nmstate"
 	mock_platforms "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms/openshift"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	util "github.com/k8snetworkplumbingwg/sriov-network-operator/test/util"
 )
 
@@ -287,3 +306,308 @@ var _ = Describe("SriovOperatorConfig controller", Ordered, func() {
 		})
 	})
 })
+
+func TestSyncMonitoring(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("RESOURCE_PREFIX", "openshift.io")
+
+	// the manifests are rendered from the repository root, the test may run before the envtest suite changed to it
+	_, file, _, _ := goruntime.Caller(0)
+	wd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(filepath.Join(filepath.Dir(file), ".."))).To(Succeed())
+	defer func() { g.Expect(os.Chdir(wd)).To(Succeed()) }()
+
+	ruleGVK := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(ruleGVK, &unstructured.Unstructured{})
+
+	config := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}
+	policies := []client.Object{
+		&sriovnetworkv1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPolicyName, Namespace: vars.Namespace}},
+		&sriovnetworkv1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{ResourceName: "nic1"}},
+		&sriovnetworkv1.SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy2", Namespace: vars.Namespace},
+			Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{ResourceName: "nic1"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(policies, config)...).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme}
+
+	dashboard := &corev1.ConfigMap{}
+	dashboardKey := types.NamespacedName{Name: "sriov-network-operator-dashboard", Namespace: vars.Namespace}
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(ruleGVK)
+	ruleKey := types.NamespacedName{Name: "sriov-network-operator-rules", Namespace: vars.Namespace}
+
+	// the dashboard is deployed without the Prometheus operator
+	err = r.syncMonitoring(ctx, config, true)
+	g.Expect(err).To(MatchError(ContainSubstring("the prometheusrules.monitoring.coreos.com CRD of the Prometheus operator is not installed")))
+	g.Expect(c.Get(ctx, dashboardKey, dashboard)).To(Succeed())
+	g.Expect(json.Valid([]byte(dashboard.Data["sriov-network-operator.json"]))).To(BeTrue())
+	g.Expect(dashboard.Data["sriov-network-operator.json"]).To(ContainSubstring("Utilization of the nic1 pool"))
+
+	// the alert rules of each pool are deployed with the Prometheus operator
+	g.Expect(c.Create(ctx, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: prometheusRuleCRDName}})).To(Succeed())
+	g.Expect(r.syncMonitoring(ctx, config, true)).To(Succeed())
+	g.Expect(c.Get(ctx, ruleKey, rule)).To(Succeed())
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	g.Expect(groups).To(HaveLen(2))
+	poolRules := groups[1].(map[string]interface{})["rules"].([]interface{})
+	g.Expect(poolRules).To(HaveLen(1))
+	g.Expect(poolRules[0].(map[string]interface{})["expr"]).To(Equal(
+		`sum(kube_pod_container_resource_requests{resource="openshift_io_nic1"}) / sum(kube_node_status_allocatable{resource="openshift_io_nic1"}) > 0.9`))
+
+	// the objects are removed when the feature gate is disabled
+	g.Expect(r.syncMonitoring(ctx, config, false)).To(Succeed())
+	g.Expect(errors.IsNotFound(c.Get(ctx, dashboardKey, dashboard))).To(BeTrue())
+	g.Expect(errors.IsNotFound(c.Get(ctx, ruleKey, rule))).To(BeTrue())
+}
+
+func TestSyncPausedNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	nodeState := func(name, syncStatus string) *sriovnetworkv1.SriovNetworkNodeState {
+		return &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vars.Namespace},
+			Status:     sriovnetworkv1.SriovNetworkNodeStateStatus{SyncStatus: syncStatus},
+		}
+	}
+	config := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(config).WithObjects(config,
+		nodeState("worker-2", constants.SyncStatusPaused),
+		nodeState("worker-1", constants.SyncStatusPaused),
+		nodeState("worker-0", constants.SyncStatusSucceeded),
+	).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme}
+
+	g.Expect(r.syncPausedNodes(ctx, config)).To(Succeed())
+	updated := &sriovnetworkv1.SriovOperatorConfig{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+	g.Expect(updated.Status.PausedNodes).To(Equal([]string{"worker-1", "worker-2"}))
+
+	// the nodes are no longer reported once their changes are applied
+	applied := &sriovnetworkv1.SriovNetworkNodeState{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "worker-1", Namespace: vars.Namespace}, applied)).To(Succeed())
+	applied.Status.SyncStatus = constants.SyncStatusInProgress
+	g.Expect(c.Update(ctx, applied)).To(Succeed())
+	g.Expect(r.syncPausedNodes(ctx, updated)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(config), updated)).To(Succeed())
+	g.Expect(updated.Status.PausedNodes).To(Equal([]string{"worker-2"}))
+}
+
+func TestSyncServiceIPFamilies(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("NETWORK_RESOURCES_INJECTOR_IMAGE", "mock-image")
+	t.Setenv("METRICS_EXPORTER_IMAGE", "mock-image")
+
+	// the manifests are rendered from the repository root, the test may run before the envtest suite changed to it
+	_, file, _, _ := goruntime.Caller(0)
+	wd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(filepath.Join(filepath.Dir(file), ".."))).To(Succeed())
+	defer func() { g.Expect(os.Chdir(wd)).To(Succeed()) }()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+	platformHelper.EXPECT().IsOpenshiftCluster().Return(false).AnyTimes()
+
+	config := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme, PlatformHelper: platformHelper}
+	injectorArgs := func() string {
+		injector := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: "network-resources-injector", Namespace: vars.Namespace}, injector)).To(Succeed())
+		return strings.Join(injector.Spec.Template.Spec.Containers[0].Args, " ")
+	}
+
+	// the services keep the defaults of the cluster and the injector binds to the IPv4 addresses on an IPv4 cluster
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	g.Expect(r.syncInjectorWebhook(ctx, config, true)).To(Succeed())
+	service := &corev1.Service{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "network-resources-injector-service", Namespace: vars.Namespace}, service)).To(Succeed())
+	g.Expect(service.Spec.IPFamilyPolicy).To(BeNil())
+	g.Expect(service.Spec.IPFamilies).To(BeEmpty())
+	g.Expect(injectorArgs()).To(ContainSubstring("-bind-address=0.0.0.0"))
+
+	// the injector binds to the IPv6 addresses on an IPv6-only cluster
+	t.Setenv("KUBERNETES_SERVICE_HOST", "fd00:10:96::1")
+	g.Expect(r.syncInjectorWebhook(ctx, config, true)).To(Succeed())
+	g.Expect(injectorArgs()).To(ContainSubstring("-bind-address=::"))
+
+	// the dual-stack options are rendered in all the services
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	config.Spec.ServiceIPFamilyPolicy = corev1.IPFamilyPolicyRequireDualStack
+	config.Spec.ServiceIPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	g.Expect(r.syncInjectorWebhook(ctx, config, true)).To(Succeed())
+	g.Expect(r.syncMetricsExporter(ctx, config, true)).To(Succeed())
+	g.Expect(injectorArgs()).To(ContainSubstring("-bind-address=::"))
+	for _, name := range []string{"network-resources-injector-service", "sriov-network-metrics-exporter-service"} {
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: vars.Namespace}, service)).To(Succeed())
+		g.Expect(service.Spec.IPFamilyPolicy).To(HaveValue(Equal(corev1.IPFamilyPolicyRequireDualStack)))
+		g.Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}))
+	}
+}
+
+func TestSyncConfinedDaemon(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("SRIOV_NETWORK_CONFIG_DAEMON_IMAGE", "mock-image")
+
+	_, file, _, _ := goruntime.Caller(0)
+	wd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(filepath.Join(filepath.Dir(file), ".."))).To(Succeed())
+	defer func() { g.Expect(os.Chdir(wd)).To(Succeed()) }()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	config := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme}
+	daemonSet := func() *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: "sriov-network-config-daemon", Namespace: vars.Namespace}, ds)).To(Succeed())
+		return ds
+	}
+
+	// the daemon runs privileged by default
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	ds := daemonSet()
+	g.Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(1))
+	g.Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.Privileged).To(HaveValue(BeTrue()))
+
+	// the confined daemon runs with its profiles and delegates the host commands to the privileged helper
+	config.Spec.FeatureGates = map[string]bool{constants.ConfinedDaemonFeatureGate: true}
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	ds = daemonSet()
+	g.Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(2))
+	daemon, helper := ds.Spec.Template.Spec.Containers[0], ds.Spec.Template.Spec.Containers[1]
+	g.Expect(daemon.SecurityContext.Privileged).To(HaveValue(BeFalse()))
+	g.Expect(daemon.SecurityContext.Capabilities.Add).To(ConsistOf(corev1.Capability("NET_ADMIN"), corev1.Capability("NET_RAW"), corev1.Capability("SYS_CHROOT")))
+	g.Expect(daemon.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeLocalhost))
+	g.Expect(daemon.SecurityContext.SeccompProfile.LocalhostProfile).To(HaveValue(Equal(constants.ConfinedDaemonSeccompProfile)))
+	g.Expect(daemon.SecurityContext.SELinuxOptions.Type).To(Equal(constants.ConfinedDaemonSELinuxType))
+	g.Expect(daemon.Args).To(ContainElement("--privileged-helper-socket=" + constants.PrivilegedHelperSocket))
+	g.Expect(daemon.VolumeMounts[0].Name).To(Equal("host"))
+	g.Expect(daemon.VolumeMounts[0].ReadOnly).To(BeTrue())
+	// the configuration of the host running programs is written by the helper
+	for _, dir := range []string{"/host/etc/udev", "/host/etc/modprobe.d", "/host/etc/systemd/system"} {
+		g.Expect(daemon.VolumeMounts).ToNot(ContainElement(HaveField("MountPath", dir)))
+	}
+	g.Expect(helper.Name).To(Equal("privileged-helper"))
+	g.Expect(helper.SecurityContext.Privileged).To(HaveValue(BeTrue()))
+	g.Expect(helper.Args).To(Equal([]string{"privileged-helper", "--socket=" + constants.PrivilegedHelperSocket}))
+	g.Expect(ds.Spec.Template.Spec.InitContainers).To(ContainElement(HaveField("Args", []string{"privileged-helper", "--install-profiles"})))
+
+	g.Expect(r.syncConfinedDaemon(ctx, config, true)).To(Succeed())
+
+	// the helper doesn't run the hooks of the pools, the daemon runs privileged while a pool has hooks
+	pool := &sriovnetworkv1.SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovNetworkPoolConfigSpec{
+			Hooks: []sriovnetworkv1.Hook{{Name: "quiesce", Point: constants.HookPointPreDrain, Command: []string{"/usr/local/bin/quiesce"}}},
+		},
+	}
+	g.Expect(c.Create(ctx, pool)).To(Succeed())
+	g.Expect(r.syncConfinedDaemon(ctx, config, true)).To(MatchError(ContainSubstring("SriovNetworkPoolConfig workers")))
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	g.Expect(daemonSet().Spec.Template.Spec.Containers).To(HaveLen(1))
+	g.Expect(c.Delete(ctx, pool)).To(Succeed())
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	g.Expect(daemonSet().Spec.Template.Spec.Containers).To(HaveLen(2))
+
+	// the daemon running as a systemd service isn't confined
+	config.Spec.FeatureGates[constants.SystemdModeFeatureGate] = true
+	g.Expect(r.syncConfinedDaemon(ctx, config, true)).ToNot(Succeed())
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	g.Expect(daemonSet().Spec.Template.Spec.Containers).To(HaveLen(1))
+}
+
+func TestSyncNMStateIntegration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("SRIOV_NETWORK_CONFIG_DAEMON_IMAGE", "mock-image")
+
+	_, file, _, _ := goruntime.Caller(0)
+	wd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(filepath.Join(filepath.Dir(file), ".."))).To(Succeed())
+	defer func() { g.Expect(os.Chdir(wd)).To(Succeed()) }()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	config := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec:       sriovnetworkv1.SriovOperatorConfigSpec{FeatureGates: map[string]bool{constants.NMStateIntegrationFeatureGate: true}},
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(config).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme}
+
+	// the integration fails until kubernetes-nmstate is installed
+	g.Expect(r.syncNMStateIntegration(ctx, config, true)).To(MatchError(ContainSubstring("kubernetes-nmstate is not installed")))
+	mapper.Add(nmstate.PolicyGVK, meta.RESTScopeRoot)
+	g.Expect(r.syncNMStateIntegration(ctx, config, true)).To(Succeed())
+
+	// the daemon leaves the PF settings to kubernetes-nmstate
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "sriov-network-config-daemon", Namespace: vars.Namespace}, ds)).To(Succeed())
+	g.Expect(ds.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--nmstate-integration"))
+
+	// not supported with the daemon running as a systemd service
+	config.Spec.FeatureGates[constants.SystemdModeFeatureGate] = true
+	g.Expect(r.syncNMStateIntegration(ctx, config, true)).ToNot(Succeed())
+	g.Expect(r.syncNMStateIntegration(ctx, config, false)).To(Succeed())
+}
+
+func TestSyncOpenstackMetadataConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("SRIOV_NETWORK_CONFIG_DAEMON_IMAGE", "mock-image")
+
+	_, file, _, _ := goruntime.Caller(0)
+	wd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(filepath.Join(filepath.Dir(file), ".."))).To(Succeed())
+	defer func() { g.Expect(os.Chdir(wd)).To(Succeed()) }()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	config := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).Build()
+	r := &SriovOperatorConfigReconciler{Client: c, Scheme: scheme}
+	daemonEnv := func() []corev1.EnvVar {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Name: "sriov-network-config-daemon", Namespace: vars.Namespace}, ds)).To(Succeed())
+		return ds.Spec.Template.Spec.Containers[0].Env
+	}
+
+	// the daemon keeps its defaults
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	g.Expect(daemonEnv()).ToNot(ContainElement(HaveField("Name", HavePrefix("OPENSTACK_METADATA_"))))
+
+	retries := 0
+	config.Spec.OpenstackMetadata = &sriovnetworkv1.OpenstackMetadataConfig{TimeoutSeconds: 5, Retries: &retries, DeadlineSeconds: 30}
+	g.Expect(r.syncConfigDaemonSet(ctx, config)).To(Succeed())
+	env := daemonEnv()
+	g.Expect(env).To(ContainElement(corev1.EnvVar{Name: constants.OpenstackMetadataTimeoutEnv, Value: "5s"}))
+	g.Expect(env).To(ContainElement(corev1.EnvVar{Name: constants.OpenstackMetadataRetriesEnv, Value: "0"}))
+	g.Expect(env).To(ContainElement(corev1.EnvVar{Name: constants.OpenstackMetadataDeadlineEnv, Value: "30s"}))
+	g.Expect(env).ToNot(ContainElement(HaveField("Name", constants.OpenstackMetadataBackoffMinEnv)))
+}
@@ -28,6 +28,7 @@ import (
 
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -113,6 +114,8 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	err = openshiftconfigv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
+	err = apiextensionsv1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 
 	By("creating K8s client")
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -131,6 +134,7 @@ var _ = BeforeSuite(func() {
 	os.Setenv("NETWORK_RESOURCES_INJECTOR_IMAGE", "mock-image")
 	os.Setenv("SRIOV_NETWORK_CONFIG_DAEMON_IMAGE", "mock-image")
 	os.Setenv("SRIOV_NETWORK_WEBHOOK_IMAGE", "mock-image")
+	os.Setenv("METRICS_EXPORTER_IMAGE", "mock-image")
 	os.Setenv("RELEASE_VERSION", "4.7.0")
 	os.Setenv("OPERATOR_NAME", "sriov-network-operator")
 
@@ -0,0 +1,385 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/certs"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// webhookCertSyncInterval is the period of the check of the certificates of the webhooks
+	webhookCertSyncInterval = time.Hour
+	// webhookCertExpiryWarning is the remaining validity under which a certificate is reported as expiring soon
+	webhookCertExpiryWarning = 30 * 24 * time.Hour
+	// webhookCAValidity and webhookServingValidity are the validity of the certificates generated by the operator,
+	// they are renewed once two thirds of it elapsed
+	webhookCAValidity      = 3 * 365 * 24 * time.Hour
+	webhookServingValidity = 365 * 24 * time.Hour
+)
+
+// webhookCert is the certificate of a webhook deployed by the operator
+type webhookCert struct {
+	// name of the webhook in the metrics
+	name        string
+	gate        string
+	condition   string
+	secretName  string
+	serviceName string
+	configName  string
+}
+
+func webhookCerts() []webhookCert {
+	return []webhookCert{
+		{name: "operator", gate: constants.OperatorWebhookFeatureGate, condition: constants.OperatorWebhookCertificateCondition,
+			secretName:  os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME"),
+			serviceName: constants.OperatorWebHookServiceName, configName: constants.OperatorWebHookName},
+		{name: "injector", gate: constants.ResourceInjectorFeatureGate, condition: constants.InjectorWebhookCertificateCondition,
+			secretName:  os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_SECRET_NAME"),
+			serviceName: constants.InjectorWebHookServiceName, configName: constants.InjectorWebHookName},
+	}
+}
+
+// operatorManagedCertificates returns whether the operator generates and renews the certificates of the webhooks
+func operatorManagedCertificates() bool {
+	return vars.ClusterType == constants.ClusterTypeKubernetes &&
+		strings.ToLower(os.Getenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_MANAGED")) == trueString
+}
+
+// caSecretName is the secret of the CA signing the certificate of a webhook, when it is managed by the operator
+func caSecretName(secretName string) string {
+	return secretName + "-ca"
+}
+
+// webhookCABundle returns the base64 encoded CA bundle of the webhook configurations: the bundle of the secret of the
+// webhook for the certificates managed by the operator, else the CA of the environment
+func webhookCABundle(ctx context.Context, c client.Reader, secretName, caEnv string) (string, error) {
+	if !operatorManagedCertificates() {
+		return os.Getenv(caEnv), nil
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: secretName}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(secret.Data["ca.crt"]), nil
+}
+
+// WebhookCertReconciler reports the expiration of the certificates of the webhooks in the metrics and in the
+// conditions of the default SriovOperatorConfig, and renews the certificates managed by the operator well before
+// they expire
+type WebhookCertReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile checks the certificates of the enabled webhooks
+func (r *WebhookCertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("webhookcert")
+
+	dc := &sriovnetworkv1.SriovOperatorConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, dc); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	conditions := append([]metav1.Condition{}, dc.Status.Conditions...)
+	for _, wc := range webhookCerts() {
+		if !dc.FeatureGateEnabled(wc.gate) || wc.secretName == "" {
+			metrics.DeleteWebhookCertificateExpiration(wc.name, "serving")
+			metrics.DeleteWebhookCertificateExpiration(wc.name, "ca")
+			meta.RemoveStatusCondition(&conditions, wc.condition)
+			continue
+		}
+		if operatorManagedCertificates() {
+			if err := r.renew(ctx, wc, now); err != nil {
+				logger.Error(err, "failed to renew the certificate of the webhook", "webhook", wc.name)
+				return reconcile.Result{}, err
+			}
+		}
+		condition, err := r.check(ctx, wc, now)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		condition.ObservedGeneration = dc.Generation
+		if condition.Status != metav1.ConditionTrue {
+			logger.Info("The certificate of the webhook is not valid", "webhook", wc.name, "reason", condition.Reason,
+				"message", condition.Message)
+		}
+		meta.SetStatusCondition(&conditions, condition)
+	}
+
+	if !equality.Semantic.DeepEqual(conditions, dc.Status.Conditions) {
+		patch := client.MergeFrom(dc.DeepCopy())
+		dc.Status.Conditions = conditions
+		if err := r.Status().Patch(ctx, dc, patch); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to update the certificate conditions: %v", err)
+		}
+	}
+	return reconcile.Result{RequeueAfter: webhookCertSyncInterval}, nil
+}
+
+// check returns the condition of the certificate of the webhook and records its expiration in the metrics
+func (r *WebhookCertReconciler) check(ctx context.Context, wc webhookCert, now time.Time) (metav1.Condition, error) {
+	condition := metav1.Condition{Type: wc.condition, Status: metav1.ConditionFalse, Reason: constants.CertificateReasonInvalid}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: wc.secretName}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return condition, err
+		}
+		metrics.DeleteWebhookCertificateExpiration(wc.name, "serving")
+		metrics.DeleteWebhookCertificateExpiration(wc.name, "ca")
+		condition.Message = fmt.Sprintf("the secret %s of the certificate is not found", wc.secretName)
+		return condition, nil
+	}
+
+	notAfter, err := certs.Expiration(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		metrics.DeleteWebhookCertificateExpiration(wc.name, "serving")
+		condition.Message = fmt.Sprintf("the certificate of the secret %s is not valid: %v", wc.secretName, err)
+		return condition, nil
+	}
+	metrics.SetWebhookCertificateExpiration(wc.name, "serving", notAfter)
+	what := "certificate"
+	// the CA of the secrets created with the helm chart is base64 encoded, the current CA of a bundle comes first
+	if caCerts, err := parseCA(secret.Data["ca.crt"]); err == nil {
+		metrics.SetWebhookCertificateExpiration(wc.name, "ca", caCerts[0].NotAfter)
+		if caCerts[0].NotAfter.Before(notAfter) {
+			notAfter, what = caCerts[0].NotAfter, "CA"
+		}
+	} else {
+		metrics.DeleteWebhookCertificateExpiration(wc.name, "ca")
+	}
+
+	switch {
+	case !now.Before(notAfter):
+		condition.Reason = constants.CertificateReasonExpired
+		condition.Message = fmt.Sprintf("the %s of the secret %s expired at %s", what, wc.secretName, notAfter.UTC().Format(time.RFC3339))
+	case notAfter.Sub(now) < webhookCertExpiryWarning:
+		condition.Reason = constants.CertificateReasonExpiringSoon
+		condition.Message = fmt.Sprintf("the %s of the secret %s expires at %s", what, wc.secretName, notAfter.UTC().Format(time.RFC3339))
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = constants.CertificateReasonValid
+		condition.Message = fmt.Sprintf("the %s of the secret %s expires at %s", what, wc.secretName, notAfter.UTC().Format(time.RFC3339))
+	}
+	return condition, nil
+}
+
+func parseCA(data []byte) ([]*x509.Certificate, error) {
+	caCerts, err := certs.Parse(data)
+	if err == nil {
+		return caCerts, nil
+	}
+	decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if decodeErr != nil {
+		return nil, err
+	}
+	return certs.Parse(decoded)
+}
+
+// renew generates the CA and the certificate of the webhook when they are missing or when two thirds of their
+// validity elapsed. The CA bundle of the webhook configurations trusts the new CA before the certificate it signed is
+// written to the secret, and keeps trusting the previous CA until it expires, so the webhook is never unreachable.
+func (r *WebhookCertReconciler) renew(ctx context.Context, wc webhookCert, now time.Time) error {
+	logger := log.FromContext(ctx).WithName("webhookcert")
+
+	caSecret := &corev1.Secret{}
+	caFound, err := r.getSecret(ctx, caSecretName(wc.secretName), caSecret)
+	if err != nil {
+		return err
+	}
+	ca := &certs.KeyPair{Cert: caSecret.Data[corev1.TLSCertKey], Key: caSecret.Data[corev1.TLSPrivateKeyKey]}
+	if certs.NeedsRotation(ca.Cert, now) {
+		if ca, err = certs.NewCA(wc.serviceName+"-ca", webhookCAValidity, now); err != nil {
+			return err
+		}
+		caSecret.Type = corev1.SecretTypeTLS
+		caSecret.Data = map[string][]byte{corev1.TLSCertKey: ca.Cert, corev1.TLSPrivateKeyKey: ca.Key}
+		if err := r.saveSecret(ctx, caSecret, caFound); err != nil {
+			return err
+		}
+		logger.Info("Renewed the CA of the webhook", "webhook", wc.name)
+	}
+
+	secret := &corev1.Secret{}
+	found, err := r.getSecret(ctx, wc.secretName, secret)
+	if err != nil {
+		return err
+	}
+	bundle := certs.Bundle(ca.Cert, secret.Data["ca.crt"], now)
+	if err := r.injectCABundle(ctx, wc, bundle); err != nil {
+		return err
+	}
+
+	serving := &certs.KeyPair{Cert: secret.Data[corev1.TLSCertKey], Key: secret.Data[corev1.TLSPrivateKeyKey]}
+	if certs.NeedsRotation(serving.Cert, now) || !certs.SignedBy(serving.Cert, ca.Cert) {
+		ns := vars.Namespace
+		dnsNames := []string{wc.serviceName + "." + ns + ".svc", wc.serviceName + "." + ns, wc.serviceName}
+		if serving, err = certs.NewServingCert(ca, dnsNames, webhookServingValidity, now); err != nil {
+			return err
+		}
+		logger.Info("Renewed the certificate of the webhook", "webhook", wc.name)
+	}
+	data := map[string][]byte{corev1.TLSCertKey: serving.Cert, corev1.TLSPrivateKeyKey: serving.Key, "ca.crt": bundle}
+	if found && equality.Semantic.DeepEqual(secret.Data, data) {
+		return nil
+	}
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = data
+	return r.saveSecret(ctx, secret, found)
+}
+
+func (r *WebhookCertReconciler) getSecret(ctx context.Context, name string, secret *corev1.Secret) (bool, error) {
+	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: name}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		secret.Namespace = vars.Namespace
+		secret.Name = name
+		return false, nil
+	}
+	return true, nil
+}
+
+func (r *WebhookCertReconciler) saveSecret(ctx context.Context, secret *corev1.Secret, found bool) error {
+	if found {
+		return r.Update(ctx, secret)
+	}
+	return r.Create(ctx, secret)
+}
+
+// injectCABundle sets the CA bundle of the webhook configurations and of the conversion of the policy CRD served by
+// the webhook, the configurations not deployed yet are rendered with the bundle of the secret
+func (r *WebhookCertReconciler) injectCABundle(ctx context.Context, wc webhookCert, bundle []byte) error {
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	if err := r.Get(ctx, types.NamespacedName{Name: wc.configName}, mutating); err == nil {
+		changed := false
+		for i := range mutating.Webhooks {
+			if !bytes.Equal(mutating.Webhooks[i].ClientConfig.CABundle, bundle) {
+				mutating.Webhooks[i].ClientConfig.CABundle = bundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := r.Update(ctx, mutating); err != nil {
+				return err
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := r.Get(ctx, types.NamespacedName{Name: wc.configName}, validating); err == nil {
+		changed := false
+		for i := range validating.Webhooks {
+			if !bytes.Equal(validating.Webhooks[i].ClientConfig.CABundle, bundle) {
+				validating.Webhooks[i].ClientConfig.CABundle = bundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := r.Update(ctx, validating); err != nil {
+				return err
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	if wc.configName != constants.OperatorWebHookName {
+		return nil
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := r.Get(ctx, types.NamespacedName{Name: constants.PolicyCRDName}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil ||
+		bytes.Equal(conversion.Webhook.ClientConfig.CABundle, bundle) {
+		return nil
+	}
+	conversion.Webhook.ClientConfig.CABundle = bundle
+	return r.Update(ctx, crd)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WebhookCertReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	defaultConfig := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}}
+	enqueueDefaultConfig := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+		return []reconcile.Request{defaultConfig}
+	})
+
+	secretNames := map[string]bool{}
+	for _, wc := range webhookCerts() {
+		if wc.secretName != "" {
+			secretNames[wc.secretName] = true
+			secretNames[caSecretName(wc.secretName)] = true
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("webhookcert").
+		For(&sriovnetworkv1.SriovOperatorConfig{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == constants.DefaultConfigName
+		}), predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, enqueueDefaultConfig, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == vars.Namespace && secretNames[o.GetName()]
+		}))).
+		Complete(r)
+}
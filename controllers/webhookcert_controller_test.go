@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/certs"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func newWebhookCertClient(objs ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	dc := &sriovnetworkv1.SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: vars.Namespace},
+		Spec: sriovnetworkv1.SriovOperatorConfigSpec{FeatureGates: map[string]bool{
+			constants.OperatorWebhookFeatureGate: true, constants.ResourceInjectorFeatureGate: false}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, dc)...).WithStatusSubresource(dc).Build()
+	return c, scheme
+}
+
+func TestWebhookCertRenewal(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME", "operator-webhook-cert")
+	t.Setenv("ADMISSION_CONTROLLERS_CERTIFICATES_INJECTOR_SECRET_NAME", "network-resources-injector-cert")
+	t.Setenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_MANAGED", "true")
+	clusterType := vars.ClusterType
+	vars.ClusterType = constants.ClusterTypeKubernetes
+	defer func() { vars.ClusterType = clusterType }()
+
+	webhookConfig := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.OperatorWebHookName},
+		Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "operator-webhook.sriovnetwork.openshift.io"}},
+	}
+	c, scheme := newWebhookCertClient(webhookConfig)
+	reconciler := &WebhookCertReconciler{Client: c, Scheme: scheme}
+
+	result, err := reconciler.Reconcile(ctx, ctrl.Request{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(webhookCertSyncInterval))
+
+	caSecret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: "operator-webhook-cert-ca"}, caSecret)).To(Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: "operator-webhook-cert"}, secret)).To(Succeed())
+	g.Expect(certs.SignedBy(secret.Data[corev1.TLSCertKey], caSecret.Data[corev1.TLSCertKey])).To(BeTrue())
+	g.Expect(secret.Data["ca.crt"]).To(Equal(caSecret.Data[corev1.TLSCertKey]))
+	// the disabled injector gets no certificate
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: "network-resources-injector-cert"}, &corev1.Secret{})).ToNot(Succeed())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(webhookConfig), webhookConfig)).To(Succeed())
+	g.Expect(webhookConfig.Webhooks[0].ClientConfig.CABundle).To(Equal(secret.Data["ca.crt"]))
+
+	dc := &sriovnetworkv1.SriovOperatorConfig{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, dc)).To(Succeed())
+	condition := meta.FindStatusCondition(dc.Status.Conditions, constants.OperatorWebhookCertificateCondition)
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(constants.CertificateReasonValid))
+	g.Expect(meta.FindStatusCondition(dc.Status.Conditions, constants.InjectorWebhookCertificateCondition)).To(BeNil())
+
+	// the valid certificates are kept
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{})
+	g.Expect(err).ToNot(HaveOccurred())
+	unchanged := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), unchanged)).To(Succeed())
+	g.Expect(unchanged.ResourceVersion).To(Equal(secret.ResourceVersion))
+
+	// a renewed CA signs a new certificate, the previous CA is still trusted
+	g.Expect(reconciler.renew(ctx, webhookCerts()[0], time.Now().Add(webhookCAValidity*3/4))).To(Succeed())
+	renewedCA := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(caSecret), renewedCA)).To(Succeed())
+	g.Expect(renewedCA.Data[corev1.TLSCertKey]).ToNot(Equal(caSecret.Data[corev1.TLSCertKey]))
+	renewed := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), renewed)).To(Succeed())
+	g.Expect(certs.SignedBy(renewed.Data[corev1.TLSCertKey], renewedCA.Data[corev1.TLSCertKey])).To(BeTrue())
+	bundle, err := certs.Parse(renewed.Data["ca.crt"])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(2))
+}
+
+func TestWebhookCertExpiration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	t.Setenv("ADMISSION_CONTROLLERS_CERTIFICATES_OPERATOR_SECRET_NAME", "operator-webhook-cert")
+
+	now := time.Now()
+	ca, err := certs.NewCA("ca", 365*24*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	serving, err := certs.NewServingCert(ca, []string{"operator-webhook-service.ns.svc"}, 10*24*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator-webhook-cert", Namespace: vars.Namespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: serving.Cert, corev1.TLSPrivateKeyKey: serving.Key, "ca.crt": ca.Cert},
+	}
+	c, scheme := newWebhookCertClient(secret)
+	reconciler := &WebhookCertReconciler{Client: c, Scheme: scheme}
+
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{})
+	g.Expect(err).ToNot(HaveOccurred())
+	dc := &sriovnetworkv1.SriovOperatorConfig{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.DefaultConfigName}, dc)).To(Succeed())
+	condition := meta.FindStatusCondition(dc.Status.Conditions, constants.OperatorWebhookCertificateCondition)
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(constants.CertificateReasonExpiringSoon))
+
+	// the certificates not managed by the operator are only reported
+	kept := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), kept)).To(Succeed())
+	g.Expect(kept.Data[corev1.TLSCertKey]).To(Equal(serving.Cert))
+
+	g.Expect(c.Delete(ctx, secret)).To(Succeed())
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(dc), dc)).To(Succeed())
+	condition = meta.FindStatusCondition(dc.Status.Conditions, constants.OperatorWebhookCertificateCondition)
+	g.Expect(condition.Reason).To(Equal(constants.CertificateReasonInvalid))
+}
@@ -25,6 +25,8 @@ import (
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
@@ -52,6 +54,7 @@ import (
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/leaderelection"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	//+kubebuilder:scaffold:imports
 )
@@ -68,6 +71,7 @@ func init() {
 	utilruntime.Must(netattdefv1.AddToScheme(scheme))
 	utilruntime.Must(mcfgv1.AddToScheme(scheme))
 	utilruntime.Must(openshiftconfigv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -106,7 +110,14 @@ func main() {
 		RenewDeadline:          &le.RenewDeadline,
 		RetryPeriod:            &le.RetryPeriod,
 		LeaderElectionID:       "a56def2a.openshift.io",
-		Cache:                  cache.Options{DefaultNamespaces: map[string]cache.Config{namespace: {}}},
+		Cache: cache.Options{
+			DefaultNamespaces: map[string]cache.Config{namespace: {}},
+			// the resource pools count the requests of the pods of all the namespaces
+			ByObject: map[client.Object]cache.ByObject{&corev1.Pod{}: {
+				Namespaces: map[string]cache.Config{cache.AllNamespaces: {}},
+				Transform:  controllers.TrimPodRequests,
+			}},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -165,6 +176,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SriovNetworkNodePolicy")
 		os.Exit(1)
 	}
+	if err = (&controllers.SriovNetworkNodeStateReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SriovNetworkNodeState")
+		os.Exit(1)
+	}
 	if err = (&controllers.SriovOperatorConfigReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
@@ -181,8 +199,60 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SriovNetworkPoolConfig")
 		os.Exit(1)
 	}
+	if err = (&controllers.SriovNetworkTestReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SriovNetworkTest")
+		os.Exit(1)
+	}
+	if err = (&controllers.SriovNetworkResourceMapReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SriovNetworkResourceMap")
+		os.Exit(1)
+	}
+	if err = (&controllers.SriovClusterStateReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SriovClusterState")
+		os.Exit(1)
+	}
+	if err = (&controllers.WebhookCertReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "WebhookCert")
+		os.Exit(1)
+	}
+	if err = (&controllers.SoakTestReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SoakTest")
+		os.Exit(1)
+	}
+	if err = (&controllers.RedfishReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Redfish")
+		os.Exit(1)
+	}
+	if err = (&controllers.DriftDetector{
+		Client:   mgr.GetAPIReader(),
+		Recorder: mgr.GetEventRecorderFor("sriov-network-operator"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create the drift detector")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	metrics.RegisterOperatorMetrics(mgr.GetClient(), namespace)
+
 	// Create a default SriovNetworkNodePolicy
 	err = createDefaultPolicy(kubeClient)
 	if err != nil {
@@ -268,6 +338,10 @@ func createDefaultOperatorConfig(c client.Client) error {
 		return fmt.Errorf("couldn't get cluster single node status: %s", err)
 	}
 
+	featureGates, err := sriovnetworkv1.ParseFeatureGates(os.Getenv("FEATURE_GATES"))
+	if err != nil {
+		return fmt.Errorf("invalid FEATURE_GATES: %v", err)
+	}
 	enableAdmissionController := os.Getenv("ADMISSION_CONTROLLERS_ENABLED") == "true"
 	config := &sriovnetworkv1.SriovOperatorConfig{
 		Spec: sriovnetworkv1.SriovOperatorConfigSpec{
@@ -276,6 +350,7 @@ func createDefaultOperatorConfig(c client.Client) error {
 			ConfigDaemonNodeSelector: map[string]string{},
 			LogLevel:                 2,
 			DisableDrain:             singleNode,
+			FeatureGates:             featureGates,
 		},
 	}
 	namespace := os.Getenv("NAMESPACE")
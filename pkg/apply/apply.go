@@ -11,6 +11,8 @@ import (
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/audit"
 )
 
 // DeleteObject deletes the desired object against the apiserver,
@@ -72,7 +74,7 @@ func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstruct
 		if err != nil {
 			return errors.Wrapf(err, "could not create %s", objDesc)
 		}
-		return nil
+		return audit.Record(ctx, client, obj)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "could not retrieve existing %s", objDesc)
@@ -86,7 +88,8 @@ func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstruct
 		if err := client.Update(ctx, obj); err != nil {
 			return errors.Wrapf(err, "could not update object %s", objDesc)
 		}
+		return audit.Record(ctx, client, obj)
 	}
 
-	return nil
+	return audit.Adopt(ctx, client, existing)
 }
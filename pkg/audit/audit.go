@@ -0,0 +1,172 @@
+// Package audit stamps the objects rendered by the operator with ownership metadata and records the
+// content they were applied with, so the out-of-band modifications of the objects can be detected.
+//
+// Every rendered object carries the sriovnetwork.io/managed-by label and the owner, source and hash
+// annotations. The hash is computed on the content of the object as stored by the API server, after
+// the operator created or updated it, and is refreshed on every write of the operator. An object
+// whose current content no longer matches its recorded hash was modified by someone else.
+//
+// The rendered objects are also excluded from the Velero backups, they are rendered again from the
+// restored custom resources.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// Owner returns the value of the owner annotation for an object rendered for the kind/namespace/name owner
+func Owner(kind string, owner metav1.Object) string {
+	if owner.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", kind, owner.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, owner.GetNamespace(), owner.GetName())
+}
+
+// Stamp sets the managed-by and backup exclusion labels and the owner and source annotations on the rendered object
+func Stamp(obj metav1.Object, owner, source string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[consts.ManagedByLabel] = consts.ManagedByValue
+	labels[consts.BackupExcludeLabel] = "true"
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[consts.OwnerAnnotation] = owner
+	annotations[consts.SourceAnnotation] = source
+	obj.SetAnnotations(annotations)
+}
+
+// IsManaged returns true if the object was rendered by the operator
+func IsManaged(obj metav1.Object) bool {
+	return obj.GetLabels()[consts.ManagedByLabel] == consts.ManagedByValue
+}
+
+// KeepHash copies the recorded hash of the live object to the desired one, so the comparisons of their
+// annotations don't trigger an update
+func KeepHash(desired, live metav1.Object) {
+	hash, ok := live.GetAnnotations()[consts.HashAnnotation]
+	if !ok {
+		return
+	}
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[consts.HashAnnotation] = hash
+	desired.SetAnnotations(annotations)
+}
+
+// ContentHash returns the hash of the content of the object. The metadata and the status are not part of the
+// content, nor are the fields filled by other controllers: the CA bundles injected in the webhook
+// configurations and the secrets linked to the service accounts.
+func ContentHash(obj runtime.Object) (string, error) {
+	// go through the JSON encoding, so the typed objects and the unstructured ones returned by the API server
+	// hash the same
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the object: %v", err)
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the object: %v", err)
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		if k, ok := content["kind"].(string); ok {
+			kind = k
+		}
+	}
+	for _, field := range []string{"apiVersion", "kind", "metadata", "status"} {
+		delete(content, field)
+	}
+	switch kind {
+	case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+		webhooks, _, _ := uns.NestedSlice(content, "webhooks")
+		for _, w := range webhooks {
+			if webhook, ok := w.(map[string]interface{}); ok {
+				uns.RemoveNestedField(webhook, "clientConfig", "caBundle")
+			}
+		}
+		if webhooks != nil {
+			content["webhooks"] = webhooks
+		}
+	case consts.ServiceAccount:
+		delete(content, "secrets")
+		delete(content, "imagePullSecrets")
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the object: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Drifted returns true if the content of the managed object no longer matches its recorded hash. The
+// objects without a recorded hash are not reported.
+func Drifted(obj client.Object) (bool, error) {
+	recorded, ok := obj.GetAnnotations()[consts.HashAnnotation]
+	if !IsManaged(obj) || !ok {
+		return false, nil
+	}
+	hash, err := ContentHash(obj)
+	if err != nil {
+		return false, err
+	}
+	return hash != recorded, nil
+}
+
+// Record records the hash of the content of the managed object, as returned by the API server after the
+// operator wrote it. It has to be called with the object returned by a create or an update.
+func Record(ctx context.Context, c client.Client, obj client.Object) error {
+	if !IsManaged(obj) {
+		return nil
+	}
+	hash, err := ContentHash(obj)
+	if err != nil {
+		return err
+	}
+	if obj.GetAnnotations()[consts.HashAnnotation] == hash {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{consts.HashAnnotation: hash},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to record the hash of %s %s/%s: %v",
+			obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// Adopt records the hash of the managed object the operator didn't need to update, unless a hash was already
+// recorded: the content of the object may have drifted since the last write of the operator.
+func Adopt(ctx context.Context, c client.Client, obj client.Object) error {
+	if _, ok := obj.GetAnnotations()[consts.HashAnnotation]; ok {
+		return nil
+	}
+	return Record(ctx, c, obj)
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func newConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "device-plugin-config", Namespace: "sriov-network-operator"},
+		Data:       map[string]string{"node1": "{}"},
+	}
+}
+
+func TestStamp(t *testing.T) {
+	g := NewGomegaWithT(t)
+	owner := &metav1.ObjectMeta{Name: "default", Namespace: "sriov-network-operator"}
+	cm := newConfigMap()
+
+	Stamp(cm, Owner("SriovOperatorConfig", owner), "bindata/manifests/daemon")
+	g.Expect(IsManaged(cm)).To(BeTrue())
+	g.Expect(cm.Labels).To(HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByValue))
+	g.Expect(cm.Labels).To(HaveKeyWithValue(consts.BackupExcludeLabel, "true"))
+	g.Expect(cm.Annotations).To(HaveKeyWithValue(consts.OwnerAnnotation, "SriovOperatorConfig/sriov-network-operator/default"))
+	g.Expect(cm.Annotations).To(HaveKeyWithValue(consts.SourceAnnotation, "bindata/manifests/daemon"))
+
+	g.Expect(Owner("ClusterRole", &metav1.ObjectMeta{Name: "operator"})).To(Equal("ClusterRole/operator"))
+}
+
+func TestContentHash(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cm := newConfigMap()
+	hash, err := ContentHash(cm)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the metadata is not part of the content
+	cm.Annotations = map[string]string{"a": "b"}
+	g.Expect(ContentHash(cm)).To(Equal(hash))
+
+	cm.Data["node2"] = "{}"
+	g.Expect(ContentHash(cm)).ToNot(Equal(hash))
+
+	// the injected CA bundles are not part of the content of the webhook configurations
+	webhook := &admissionv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{Kind: "ValidatingWebhookConfiguration"},
+		Webhooks: []admissionv1.ValidatingWebhook{{Name: "operator-webhook.sriovnetwork.openshift.io"}},
+	}
+	hash, err = ContentHash(webhook)
+	g.Expect(err).ToNot(HaveOccurred())
+	webhook.Webhooks[0].ClientConfig.CABundle = []byte("ca")
+	g.Expect(ContentHash(webhook)).To(Equal(hash))
+}
+
+func TestRecordAndDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	cm := newConfigMap()
+	Stamp(cm, "SriovOperatorConfig/sriov-network-operator/default", consts.SourceGenerated)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build()
+
+	live := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), live)).To(Succeed())
+	g.Expect(Drifted(live)).To(BeFalse())
+	g.Expect(Adopt(ctx, c, live)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(cm), live)).To(Succeed())
+	g.Expect(live.Annotations).To(HaveKey(consts.HashAnnotation))
+	g.Expect(Drifted(live)).To(BeFalse())
+
+	// out-of-band modification
+	live.Data["node1"] = `{"resourceList": []}`
+	g.Expect(c.Update(ctx, live)).To(Succeed())
+	g.Expect(Drifted(live)).To(BeTrue())
+
+	// adopting doesn't hide the drift
+	g.Expect(Adopt(ctx, c, live)).To(Succeed())
+	g.Expect(Drifted(live)).To(BeTrue())
+
+	// the operator rewrote the object
+	g.Expect(Record(ctx, c, live)).To(Succeed())
+	g.Expect(Drifted(live)).To(BeFalse())
+
+	// the objects not rendered by the operator are ignored
+	unmanaged := newConfigMap()
+	unmanaged.Name = "other"
+	g.Expect(Record(ctx, c, unmanaged)).To(Succeed())
+	g.Expect(unmanaged.Annotations).ToNot(HaveKey(consts.HashAnnotation))
+}
+
+func TestKeepHash(t *testing.T) {
+	g := NewGomegaWithT(t)
+	live := newConfigMap()
+	live.Annotations = map[string]string{consts.HashAnnotation: "abc"}
+	desired := newConfigMap()
+
+	KeepHash(desired, live)
+	g.Expect(desired.Annotations).To(HaveKeyWithValue(consts.HashAnnotation, "abc"))
+}
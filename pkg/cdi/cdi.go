@@ -0,0 +1,143 @@
+// Package cdi generates the Container Device Interface spec of the VFs of the node, so the container runtimes
+// consuming CDI can inject the VFs, by their PCI address, in the containers started outside of Kubernetes as well as
+// in the pods of the device plugin.
+package cdi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// Version is the version of the CDI specification of the generated spec
+	Version = "0.6.0"
+	// SpecFileName is the file of the spec in the CDI spec directory of the host
+	SpecFileName = "sriovnetwork.openshift.io-vf.json"
+
+	// the environment of the containers a VF is injected in
+	envPciAddress = "SRIOV_VF_PCI_ADDRESS"
+	envPfName     = "SRIOV_VF_PF_NAME"
+	envNetdev     = "SRIOV_VF_NETDEV"
+	envRdmaDevice = "SRIOV_VF_RDMA_DEVICE"
+)
+
+// Spec is a CDI spec
+type Spec struct {
+	CdiVersion string   `json:"cdiVersion"`
+	Kind       string   `json:"kind"`
+	Devices    []Device `json:"devices"`
+}
+
+// Device is a device of a CDI spec
+type Device struct {
+	Name           string         `json:"name"`
+	ContainerEdits ContainerEdits `json:"containerEdits"`
+}
+
+// ContainerEdits are the changes of the container a device is injected in
+type ContainerEdits struct {
+	Env         []string     `json:"env,omitempty"`
+	DeviceNodes []DeviceNode `json:"deviceNodes,omitempty"`
+}
+
+// DeviceNode is a device node of the host created in the container
+type DeviceNode struct {
+	Path string `json:"path"`
+}
+
+// BuildSpec returns the spec of the VFs of the interfaces, a device per VF named after its PCI address. The VFs bound
+// to vfio-pci get the device nodes of their IOMMU group, the other ones the device nodes of their RDMA device when
+// they have one.
+func BuildSpec(interfaces sriovnetworkv1.InterfaceExts) *Spec {
+	spec := &Spec{CdiVersion: Version, Kind: consts.CDIKind, Devices: []Device{}}
+	for _, iface := range interfaces {
+		for _, vf := range iface.VFs {
+			edits := ContainerEdits{Env: []string{envPciAddress + "=" + vf.PciAddress, envPfName + "=" + iface.Name}}
+			if vf.Driver == consts.DeviceTypeVfioPci {
+				if group := iommuGroup(vf.PciAddress); group != "" {
+					edits.DeviceNodes = []DeviceNode{{Path: "/dev/vfio/vfio"}, {Path: filepath.Join("/dev/vfio", group)}}
+				}
+			} else {
+				if vf.Name != "" {
+					edits.Env = append(edits.Env, envNetdev+"="+vf.Name)
+				}
+				if rdmaDevice, uverbs := rdmaDevices(vf.PciAddress); rdmaDevice != "" {
+					edits.Env = append(edits.Env, envRdmaDevice+"="+rdmaDevice)
+					if uverbs != "" {
+						edits.DeviceNodes = []DeviceNode{{Path: "/dev/infiniband/rdma_cm"}, {Path: filepath.Join("/dev/infiniband", uverbs)}}
+					}
+				}
+			}
+			spec.Devices = append(spec.Devices, Device{Name: vf.PciAddress, ContainerEdits: edits})
+		}
+	}
+	sort.Slice(spec.Devices, func(i, j int) bool { return spec.Devices[i].Name < spec.Devices[j].Name })
+	return spec
+}
+
+// iommuGroup returns the IOMMU group of the device, empty when the IOMMU is disabled
+func iommuGroup(pciAddr string) string {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "iommu_group"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
+
+// rdmaDevices returns the RDMA device of the device and its uverbs character device, empty without RDMA
+func rdmaDevices(pciAddr string) (string, string) {
+	devDir := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr)
+	rdmaDevice := firstEntry(filepath.Join(devDir, "infiniband"))
+	if rdmaDevice == "" {
+		return "", ""
+	}
+	return rdmaDevice, firstEntry(filepath.Join(devDir, "infiniband_verbs"))
+}
+
+func firstEntry(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+	return entries[0].Name()
+}
+
+// specFile returns the path of the spec on the host
+func specFile() string {
+	return filepath.Join(utils.GetHostExtensionPath(consts.CDISpecDir), SpecFileName)
+}
+
+// WriteSpec writes the spec to the CDI spec directory of the host when it changed, the runtimes watching the
+// directory never read a partially written spec. It returns whether the spec was written.
+func WriteSpec(spec *Spec) (bool, error) {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	file := specFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, fmt.Errorf("failed to create the CDI spec directory: %v", err)
+	}
+	written, err := utils.ReplaceFile(file, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to write the CDI spec: %v", err)
+	}
+	return written, nil
+}
+
+// RemoveSpec removes the spec from the CDI spec directory of the host
+func RemoveSpec() error {
+	if err := os.Remove(specFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the CDI spec: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cdi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func useFakeFilesystem(t *testing.T, fs *fakefilesystem.FS) string {
+	root, clean, err := fs.Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+	return root
+}
+
+func TestBuildSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+	useFakeFilesystem(t, &fakefilesystem.FS{
+		Dirs: []string{
+			"sys/bus/pci/devices/0000:3b:02.0",
+			"sys/bus/pci/devices/0000:3b:02.1",
+			"sys/bus/pci/devices/0000:d8:00.2/infiniband/mlx5_2",
+			"sys/bus/pci/devices/0000:d8:00.2/infiniband_verbs/uverbs2",
+			"sys/kernel/iommu_groups/42",
+		},
+		Symlinks: map[string]string{
+			"sys/bus/pci/devices/0000:3b:02.1/iommu_group": "../../../../kernel/iommu_groups/42",
+		},
+	})
+
+	spec := BuildSpec(sriovnetworkv1.InterfaceExts{
+		{Name: "ens2f0", VFs: []sriovnetworkv1.VirtualFunction{
+			{PciAddress: "0000:d8:00.2", Name: "ens2f0v0", Driver: "mlx5_core"},
+		}},
+		{Name: "ens1f0", VFs: []sriovnetworkv1.VirtualFunction{
+			{PciAddress: "0000:3b:02.1", Driver: consts.DeviceTypeVfioPci},
+			{PciAddress: "0000:3b:02.0", Name: "ens1f0v0", Driver: "iavf"},
+		}},
+	})
+	g.Expect(spec.CdiVersion).To(Equal(Version))
+	g.Expect(spec.Kind).To(Equal(consts.CDIKind))
+	g.Expect(spec.Devices).To(Equal([]Device{
+		{Name: "0000:3b:02.0", ContainerEdits: ContainerEdits{
+			Env: []string{"SRIOV_VF_PCI_ADDRESS=0000:3b:02.0", "SRIOV_VF_PF_NAME=ens1f0", "SRIOV_VF_NETDEV=ens1f0v0"},
+		}},
+		{Name: "0000:3b:02.1", ContainerEdits: ContainerEdits{
+			Env:         []string{"SRIOV_VF_PCI_ADDRESS=0000:3b:02.1", "SRIOV_VF_PF_NAME=ens1f0"},
+			DeviceNodes: []DeviceNode{{Path: "/dev/vfio/vfio"}, {Path: "/dev/vfio/42"}},
+		}},
+		{Name: "0000:d8:00.2", ContainerEdits: ContainerEdits{
+			Env: []string{"SRIOV_VF_PCI_ADDRESS=0000:d8:00.2", "SRIOV_VF_PF_NAME=ens2f0", "SRIOV_VF_NETDEV=ens2f0v0",
+				"SRIOV_VF_RDMA_DEVICE=mlx5_2"},
+			DeviceNodes: []DeviceNode{{Path: "/dev/infiniband/rdma_cm"}, {Path: "/dev/infiniband/uverbs2"}},
+		}},
+	}))
+}
+
+func TestWriteSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := useFakeFilesystem(t, &fakefilesystem.FS{})
+	file := filepath.Join(root, consts.CDISpecDir, SpecFileName)
+
+	spec := &Spec{CdiVersion: Version, Kind: consts.CDIKind, Devices: []Device{{Name: "0000:3b:02.0"}}}
+	written, err := WriteSpec(spec)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(written).To(BeTrue())
+	data, err := os.ReadFile(file)
+	g.Expect(err).ToNot(HaveOccurred())
+	read := &Spec{}
+	g.Expect(json.Unmarshal(data, read)).To(Succeed())
+	g.Expect(read).To(Equal(spec))
+
+	// an unchanged spec is not written again
+	written, err = WriteSpec(spec)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(written).To(BeFalse())
+
+	g.Expect(RemoveSpec()).To(Succeed())
+	g.Expect(file).ToNot(BeAnExistingFile())
+	g.Expect(RemoveSpec()).To(Succeed())
+}
@@ -0,0 +1,167 @@
+// Package certs generates and inspects the certificates of the webhooks, for the certificates managed by the operator
+package certs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// KeyPair is a PEM encoded certificate and its private key
+type KeyPair struct {
+	Cert []byte
+	Key  []byte
+}
+
+// Parse returns the certificates of the PEM data, in their order
+func Parse(data []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return certs, nil
+}
+
+// Expiration returns the earliest expiration time of the certificates of the PEM data
+func Expiration(data []byte) (time.Time, error) {
+	certs, err := Parse(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+	notAfter := certs[0].NotAfter
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+	return notAfter, nil
+}
+
+// NeedsRotation returns true once two thirds of the validity of the first certificate of the PEM data elapsed, well
+// before it expires, or when the data holds no valid certificate
+func NeedsRotation(data []byte, now time.Time) bool {
+	certs, err := Parse(data)
+	if err != nil {
+		return true
+	}
+	validity := certs[0].NotAfter.Sub(certs[0].NotBefore)
+	return now.After(certs[0].NotBefore.Add(validity * 2 / 3))
+}
+
+// SignedBy returns true if the first certificate of the PEM data is signed by the first certificate of the CA
+func SignedBy(data, ca []byte) bool {
+	certs, err := Parse(data)
+	if err != nil {
+		return false
+	}
+	caCerts, err := Parse(ca)
+	if err != nil {
+		return false
+	}
+	return certs[0].CheckSignatureFrom(caCerts[0]) == nil
+}
+
+// NewCA returns a self-signed CA valid for the duration
+func NewCA(commonName string, validity time.Duration, now time.Time) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newCert(template, nil)
+}
+
+// NewServingCert returns a serving certificate for the DNS names signed by the CA, valid for the duration
+func NewServingCert(ca *KeyPair, dnsNames []string, validity time.Duration, now time.Time) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return newCert(template, ca)
+}
+
+// newCert signs the template with the CA, or self-signs it without CA
+func newCert(template *x509.Certificate, ca *KeyPair) (*KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the serial number: %v", err)
+	}
+	template.SerialNumber = serial
+
+	parent, signer := template, interface{}(key)
+	if ca != nil {
+		caCerts, err := Parse(ca.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the CA: %v", err)
+		}
+		block, _ := pem.Decode(ca.Key)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode the key of the CA")
+		}
+		caKey, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the key of the CA: %v", err)
+		}
+		parent, signer = caCerts[0], caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the key: %v", err)
+	}
+	return &KeyPair{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}, nil
+}
+
+// Bundle returns the PEM bundle of the certificate followed by the certificates of the previous bundle not expired
+// yet, so the clients trusting the previous CA keep trusting the certificates it signed until they are renewed
+func Bundle(cert, previous []byte, now time.Time) []byte {
+	bundle := bytes.NewBuffer(append([]byte{}, cert...))
+	current, _ := Parse(cert)
+	prevCerts, _ := Parse(previous)
+	for _, c := range prevCerts {
+		if !now.Before(c.NotAfter) || (len(current) > 0 && c.Equal(current[0])) {
+			continue
+		}
+		bundle.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}))
+	}
+	return bundle.Bytes()
+}
@@ -0,0 +1,66 @@
+package certs
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestServingCert(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+
+	ca, err := NewCA("webhook-ca", 3*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	serving, err := NewServingCert(ca, []string{"webhook.ns.svc"}, 30*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(SignedBy(serving.Cert, ca.Cert)).To(BeTrue())
+	g.Expect(SignedBy(ca.Cert, serving.Cert)).To(BeFalse())
+
+	certs, err := Parse(serving.Cert)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(certs[0].DNSNames).To(Equal([]string{"webhook.ns.svc"}))
+
+	// the earliest expiration of the chain is reported
+	notAfter, err := Expiration(append(append([]byte{}, serving.Cert...), ca.Cert...))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(notAfter.Unix()).To(Equal(now.Add(3 * time.Hour).Unix()))
+
+	_, err = Expiration([]byte("garbage"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestNeedsRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+
+	// valid from an hour before now, for 4 hours in total
+	ca, err := NewCA("webhook-ca", 3*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(NeedsRotation(ca.Cert, now)).To(BeFalse())
+	g.Expect(NeedsRotation(ca.Cert, now.Add(90*time.Minute))).To(BeFalse())
+	g.Expect(NeedsRotation(ca.Cert, now.Add(2*time.Hour))).To(BeTrue())
+	g.Expect(NeedsRotation(nil, now)).To(BeTrue())
+}
+
+func TestBundle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+
+	previous, err := NewCA("previous-ca", time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	current, err := NewCA("current-ca", 3*time.Hour, now)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	bundle := Bundle(current.Cert, previous.Cert, now)
+	certs, err := Parse(bundle)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(certs).To(HaveLen(2))
+	g.Expect(certs[0].Subject.CommonName).To(Equal("current-ca"))
+	g.Expect(certs[1].Subject.CommonName).To(Equal("previous-ca"))
+
+	// the current CA is not repeated and the expired CAs are dropped
+	g.Expect(Bundle(current.Cert, bundle, now)).To(Equal(bundle))
+	g.Expect(Bundle(current.Cert, bundle, now.Add(2*time.Hour))).To(Equal(current.Cert))
+}
@@ -16,6 +16,10 @@ func (c *FakeSriovnetworkV1) SriovNetworks(namespace string) v1.SriovNetworkInte
 	return &FakeSriovNetworks{c, namespace}
 }
 
+func (c *FakeSriovnetworkV1) SriovNetworkGrants() v1.SriovNetworkGrantInterface {
+	return &FakeSriovNetworkGrants{c}
+}
+
 func (c *FakeSriovnetworkV1) SriovNetworkNodePolicies(namespace string) v1.SriovNetworkNodePolicyInterface {
 	return &FakeSriovNetworkNodePolicies{c, namespace}
 }
@@ -4,6 +4,8 @@ package v1
 
 type SriovNetworkExpansion interface{}
 
+type SriovNetworkGrantExpansion interface{}
+
 type SriovNetworkNodePolicyExpansion interface{}
 
 type SriovNetworkNodeStateExpansion interface{}
@@ -11,6 +11,7 @@ import (
 type SriovnetworkV1Interface interface {
 	RESTClient() rest.Interface
 	SriovNetworksGetter
+	SriovNetworkGrantsGetter
 	SriovNetworkNodePoliciesGetter
 	SriovNetworkNodeStatesGetter
 	SriovOperatorConfigsGetter
@@ -25,6 +26,10 @@ func (c *SriovnetworkV1Client) SriovNetworks(namespace string) SriovNetworkInter
 	return newSriovNetworks(c, namespace)
 }
 
+func (c *SriovnetworkV1Client) SriovNetworkGrants() SriovNetworkGrantInterface {
+	return newSriovNetworkGrants(c)
+}
+
 func (c *SriovnetworkV1Client) SriovNetworkNodePolicies(namespace string) SriovNetworkNodePolicyInterface {
 	return newSriovNetworkNodePolicies(c, namespace)
 }
@@ -40,6 +40,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=sriovnetwork, Version=v1
 	case v1.SchemeGroupVersion.WithResource("sriovnetworks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sriovnetwork().V1().SriovNetworks().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("sriovnetworkgrants"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sriovnetwork().V1().SriovNetworkGrants().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("sriovnetworknodepolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sriovnetwork().V1().SriovNetworkNodePolicies().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("sriovnetworknodestates"):
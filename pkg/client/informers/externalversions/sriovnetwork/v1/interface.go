@@ -10,6 +10,8 @@ import (
 type Interface interface {
 	// SriovNetworks returns a SriovNetworkInformer.
 	SriovNetworks() SriovNetworkInformer
+	// SriovNetworkGrants returns a SriovNetworkGrantInformer.
+	SriovNetworkGrants() SriovNetworkGrantInformer
 	// SriovNetworkNodePolicies returns a SriovNetworkNodePolicyInformer.
 	SriovNetworkNodePolicies() SriovNetworkNodePolicyInformer
 	// SriovNetworkNodeStates returns a SriovNetworkNodeStateInformer.
@@ -34,6 +36,11 @@ func (v *version) SriovNetworks() SriovNetworkInformer {
 	return &sriovNetworkInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// SriovNetworkGrants returns a SriovNetworkGrantInformer.
+func (v *version) SriovNetworkGrants() SriovNetworkGrantInformer {
+	return &sriovNetworkGrantInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SriovNetworkNodePolicies returns a SriovNetworkNodePolicyInformer.
 func (v *version) SriovNetworkNodePolicies() SriovNetworkNodePolicyInformer {
 	return &sriovNetworkNodePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
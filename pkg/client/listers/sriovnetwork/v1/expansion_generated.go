@@ -10,6 +10,10 @@ type SriovNetworkListerExpansion interface{}
 // SriovNetworkNamespaceLister.
 type SriovNetworkNamespaceListerExpansion interface{}
 
+// SriovNetworkGrantListerExpansion allows custom methods to be added to
+// SriovNetworkGrantLister.
+type SriovNetworkGrantListerExpansion interface{}
+
 // SriovNetworkNodePolicyListerExpansion allows custom methods to be added to
 // SriovNetworkNodePolicyLister.
 type SriovNetworkNodePolicyListerExpansion interface{}
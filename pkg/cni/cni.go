@@ -0,0 +1,86 @@
+// Package cni reads the CNI specification versions supported by the SR-IOV CNI plugins installed on the node, the
+// operator webhook rejects the networks rendered with a version the plugins of the nodes don't support.
+package cni
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+const versionTimeout = 10 * time.Second
+
+// PluginTypes are the CNI plugins of the SR-IOV networks
+var PluginTypes = []string{"sriov", "ib-sriov"}
+
+// versionResult is the result of the VERSION command of the CNI specification
+type versionResult struct {
+	CNIVersion        string   `json:"cniVersion"`
+	SupportedVersions []string `json:"supportedVersions"`
+}
+
+// SupportedVersions runs the VERSION command of the plugin and returns the CNI specification versions it supports
+func SupportedVersions(binDir, pluginType string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, filepath.Join(binDir, pluginType))
+	cmd.Env = append(os.Environ(), "CNI_COMMAND=VERSION")
+	cmd.Stdin = bytes.NewReader([]byte(fmt.Sprintf(`{"cniVersion":%q}`, sriovnetworkv1.DefaultCniVersion)))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("VERSION command of the %s CNI plugin failed: %v: %s", pluginType, err, stderr.String())
+	}
+	result := &versionResult{}
+	if err := json.Unmarshal(stdout.Bytes(), result); err != nil {
+		return nil, fmt.Errorf("failed to decode the VERSION result of the %s CNI plugin: %v", pluginType, err)
+	}
+	return result.SupportedVersions, nil
+}
+
+// Plugins returns the SR-IOV CNI plugins installed in the directory, the plugins failing to report their versions are
+// reported without versions
+func Plugins(binDir string) []sriovnetworkv1.CniPluginStatus {
+	plugins := []sriovnetworkv1.CniPluginStatus{}
+	for _, pluginType := range PluginTypes {
+		if _, err := os.Stat(filepath.Join(binDir, pluginType)); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		versions, err := SupportedVersions(binDir, pluginType)
+		if err != nil {
+			log.Log.Error(err, "cni.Plugins(): failed to read the supported versions", "plugin", pluginType)
+		}
+		plugins = append(plugins, sriovnetworkv1.CniPluginStatus{Type: pluginType, SupportedVersions: versions})
+	}
+	return plugins
+}
+
+// UnsupportedNodes returns the nodes whose plugin of the type doesn't support the CNI specification version. The
+// nodes without the plugin, or whose plugin didn't report its versions, are not checked.
+func UnsupportedNodes(states []sriovnetworkv1.SriovNetworkNodeState, pluginType, version string) []string {
+	nodes := []string{}
+	for _, state := range states {
+		for _, plugin := range state.Status.CniPlugins {
+			if plugin.Type != pluginType || len(plugin.SupportedVersions) == 0 {
+				continue
+			}
+			if !slices.Contains(plugin.SupportedVersions, version) {
+				nodes = append(nodes, state.Name)
+			}
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,52 @@
+package cni
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func TestPlugins(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	binDir := t.TempDir()
+	plugin := `#!/bin/sh
+[ "$CNI_COMMAND" = VERSION ] || exit 1
+cat > /dev/null
+echo '{"cniVersion":"1.1.0","supportedVersions":["0.3.1","0.4.0","1.0.0","1.1.0"]}'
+`
+	g.Expect(os.WriteFile(filepath.Join(binDir, "sriov"), []byte(plugin), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(binDir, "ib-sriov"), []byte("#!/bin/sh\nexit 1\n"), 0755)).To(Succeed())
+
+	g.Expect(Plugins(binDir)).To(Equal([]sriovnetworkv1.CniPluginStatus{
+		{Type: "sriov", SupportedVersions: []string{"0.3.1", "0.4.0", "1.0.0", "1.1.0"}},
+		{Type: "ib-sriov"},
+	}))
+	g.Expect(Plugins(t.TempDir())).To(BeEmpty())
+}
+
+func TestUnsupportedNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	state := func(name string, plugins ...sriovnetworkv1.CniPluginStatus) sriovnetworkv1.SriovNetworkNodeState {
+		return sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     sriovnetworkv1.SriovNetworkNodeStateStatus{CniPlugins: plugins},
+		}
+	}
+	states := []sriovnetworkv1.SriovNetworkNodeState{
+		state("worker-0", sriovnetworkv1.CniPluginStatus{Type: "sriov", SupportedVersions: []string{"0.3.1", "0.4.0", "1.0.0", "1.1.0"}}),
+		state("worker-1", sriovnetworkv1.CniPluginStatus{Type: "sriov", SupportedVersions: []string{"0.3.1", "0.4.0", "1.0.0"}}),
+		state("worker-2", sriovnetworkv1.CniPluginStatus{Type: "sriov"}),
+		state("worker-3"),
+	}
+
+	g.Expect(UnsupportedNodes(states, "sriov", "1.0.0")).To(BeEmpty())
+	g.Expect(UnsupportedNodes(states, "sriov", "1.1.0")).To(Equal([]string{"worker-1"}))
+	g.Expect(UnsupportedNodes(states, "ib-sriov", "1.1.0")).To(BeEmpty())
+}
@@ -0,0 +1,175 @@
+// Package compat holds the compatibility database of the features of the operator, the minimum driver and firmware
+// versions of the NIC models supporting them. The operator webhook rejects the policies and the networks enabling a
+// feature on a NIC whose versions, reported in the SriovNetworkNodeStates, don't meet the requirements.
+package compat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+const (
+	// ConfigMapName is the ConfigMap of the operator namespace overriding the built-in database
+	ConfigMapName = "sriov-compatibility-database"
+	// ConfigMapKey is the key of the ConfigMap holding the entries of the database
+	ConfigMapKey = "database.yaml"
+)
+
+// Feature is a feature of the operator whose support depends on the NIC model, its driver and its firmware
+type Feature string
+
+const (
+	FeatureSwitchdev Feature = "switchdev"
+	FeatureVdpa      Feature = "vdpa"
+	FeatureMinTxRate Feature = "min_tx_rate"
+	FeatureTrust     Feature = "trust"
+)
+
+var features = []Feature{FeatureSwitchdev, FeatureVdpa, FeatureMinTxRate, FeatureTrust}
+
+// Entry is a requirement of a feature on the NICs matching its vendor, device ID and driver, the empty fields match
+// all the NICs. An entry without minimum version marks the feature as unsupported on the NICs.
+type Entry struct {
+	Feature            Feature `yaml:"feature"`
+	Vendor             string  `yaml:"vendor,omitempty"`
+	DeviceID           string  `yaml:"deviceID,omitempty"`
+	Driver             string  `yaml:"driver,omitempty"`
+	MinDriverVersion   string  `yaml:"minDriverVersion,omitempty"`
+	MinFirmwareVersion string  `yaml:"minFirmwareVersion,omitempty"`
+	// Reason explains the requirement in the rejections, e.g. the bug fixed by the minimum version
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Database is the list of the requirements of the features
+type Database []Entry
+
+// BuiltIn is the database used without ConfigMap, the features the drivers don't implement
+var BuiltIn = Database{
+	{Feature: FeatureSwitchdev, Driver: "i40e", Reason: "the driver has no switchdev mode"},
+	{Feature: FeatureSwitchdev, Driver: "ixgbe", Reason: "the driver has no switchdev mode"},
+	{Feature: FeatureSwitchdev, Driver: "mlx4_core", Reason: "the driver has no switchdev mode"},
+	{Feature: FeatureVdpa, Vendor: "8086", Reason: "the vDPA devices are only created on the VFs of mlx5_core"},
+	{Feature: FeatureVdpa, Vendor: "15b3", DeviceID: "1015", Reason: "vDPA requires a ConnectX-6 Dx or newer"},
+	{Feature: FeatureVdpa, Vendor: "15b3", DeviceID: "1017", Reason: "vDPA requires a ConnectX-6 Dx or newer"},
+	{Feature: FeatureVdpa, Vendor: "15b3", DeviceID: "1019", Reason: "vDPA requires a ConnectX-6 Dx or newer"},
+	{Feature: FeatureMinTxRate, Driver: "i40e", Reason: "the driver rejects a minimum rate"},
+	{Feature: FeatureMinTxRate, Driver: "ixgbe", Reason: "the driver rejects a minimum rate"},
+}
+
+// Load returns the database of the ConfigMap read from the cache of the lister, or the built-in database without
+// ConfigMap. A ConfigMap that can't be parsed is ignored and the built-in database used until it is fixed, so it
+// doesn't reject all the policies and networks.
+func Load(lister corev1listers.ConfigMapNamespaceLister) Database {
+	cm, err := lister.Get(ConfigMapName)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Log.Error(err, "Load(): failed to get the compatibility database, using the built-in one")
+		}
+		return BuiltIn
+	}
+	db, err := Parse(cm.Data[ConfigMapKey])
+	if err != nil {
+		log.Log.Error(err, "Load(): invalid compatibility database, using the built-in one",
+			"namespace", cm.Namespace, "name", cm.Name)
+		return BuiltIn
+	}
+	return db
+}
+
+// Parse decodes and checks the entries of a database
+func Parse(data string) (Database, error) {
+	db := Database{}
+	if strings.TrimSpace(data) == "" {
+		return db, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBufferString(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&db); err != nil {
+		return nil, err
+	}
+	for i, entry := range db {
+		if !isFeature(entry.Feature) {
+			return nil, fmt.Errorf("entry %d: unknown feature %q", i, entry.Feature)
+		}
+		for _, version := range []string{entry.MinDriverVersion, entry.MinFirmwareVersion} {
+			if version != "" {
+				if _, err := utils.ParseFirmwareVersion(version); err != nil {
+					return nil, fmt.Errorf("entry %d: invalid version %q", i, version)
+				}
+			}
+		}
+	}
+	return db, nil
+}
+
+// Check returns an error explaining why the feature is not supported on the PF, the requirements on a version the
+// PF didn't report are not checked
+func (db Database) Check(feature Feature, iface *sriovnetworkv1.InterfaceExt) error {
+	for _, entry := range db {
+		if entry.Feature != feature || !entry.matches(iface) {
+			continue
+		}
+		if entry.MinDriverVersion == "" && entry.MinFirmwareVersion == "" {
+			return entry.error(iface, "is not supported")
+		}
+		if older(iface.DriverVersion, entry.MinDriverVersion) {
+			return entry.error(iface, fmt.Sprintf("requires driver version %s or newer, the driver version is %s",
+				entry.MinDriverVersion, iface.DriverVersion))
+		}
+		if older(iface.FirmwareVersion, entry.MinFirmwareVersion) {
+			return entry.error(iface, fmt.Sprintf("requires firmware version %s or newer, the firmware version is %s",
+				entry.MinFirmwareVersion, iface.FirmwareVersion))
+		}
+	}
+	return nil
+}
+
+func (e *Entry) matches(iface *sriovnetworkv1.InterfaceExt) bool {
+	return (e.Vendor == "" || strings.EqualFold(e.Vendor, iface.Vendor)) &&
+		(e.DeviceID == "" || strings.EqualFold(e.DeviceID, iface.DeviceID)) &&
+		(e.Driver == "" || e.Driver == iface.Driver)
+}
+
+func (e *Entry) error(iface *sriovnetworkv1.InterfaceExt, message string) error {
+	err := fmt.Sprintf("%s on interface(%s), model %s:%s with driver %s, %s", e.Feature, iface.Name, iface.Vendor,
+		iface.DeviceID, iface.Driver, message)
+	if e.Reason != "" {
+		err = fmt.Sprintf("%s: %s", err, e.Reason)
+	}
+	return errors.New(err)
+}
+
+func isFeature(feature Feature) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// older returns true if the version is older than the minimum, the versions that can't be parsed are not compared
+func older(version, minimum string) bool {
+	if minimum == "" {
+		return false
+	}
+	v, err := utils.ParseFirmwareVersion(version)
+	if err != nil {
+		return false
+	}
+	m, err := utils.ParseFirmwareVersion(minimum)
+	if err != nil {
+		return false
+	}
+	return utils.CompareVersions(v, m) < 0
+}
@@ -0,0 +1,91 @@
+package compat
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+const testDatabase = `
+- feature: switchdev
+  vendor: "15b3"
+  deviceID: "1015"
+- feature: min_tx_rate
+  driver: ice
+  minDriverVersion: "5.14"
+  minFirmwareVersion: "4.30"
+  reason: the rate is not enforced by the older firmwares
+`
+
+func TestCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := Parse(testDatabase)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(db).To(HaveLen(2))
+
+	cx4lx := &sriovnetworkv1.InterfaceExt{Name: "ens1f0", Vendor: "15b3", DeviceID: "1015", Driver: "mlx5_core"}
+	g.Expect(db.Check(FeatureSwitchdev, cx4lx)).To(MatchError("switchdev on interface(ens1f0), model 15b3:1015 with driver mlx5_core, is not supported"))
+	g.Expect(db.Check(FeatureTrust, cx4lx)).To(Succeed())
+	g.Expect(db.Check(FeatureSwitchdev, &sriovnetworkv1.InterfaceExt{Vendor: "15b3", DeviceID: "101d"})).To(Succeed())
+
+	ice := &sriovnetworkv1.InterfaceExt{Name: "ens2f0", Vendor: "8086", DeviceID: "159b", Driver: "ice",
+		DriverVersion: "5.14.0-284.el9", FirmwareVersion: "4.20 0x80017785 1.3346.0"}
+	g.Expect(db.Check(FeatureMinTxRate, ice)).To(MatchError(ContainSubstring(
+		"requires firmware version 4.30 or newer, the firmware version is 4.20 0x80017785 1.3346.0: the rate is not enforced by the older firmwares")))
+	ice.FirmwareVersion = "4.30"
+	g.Expect(db.Check(FeatureMinTxRate, ice)).To(Succeed())
+	ice.DriverVersion = "4.18.0"
+	g.Expect(db.Check(FeatureMinTxRate, ice)).To(MatchError(ContainSubstring("requires driver version 5.14 or newer")))
+	// the versions not reported are not checked
+	ice.DriverVersion, ice.FirmwareVersion = "", "N/A"
+	g.Expect(db.Check(FeatureMinTxRate, ice)).To(Succeed())
+
+	_, err = Parse("- feature: sriov\n")
+	g.Expect(err).To(MatchError(ContainSubstring(`unknown feature "sriov"`)))
+	_, err = Parse("- feature: trust\n  minFirmwareVersion: latest\n")
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid version "latest"`)))
+	_, err = Parse("- feature: trust\n  minVersion: \"1.0\"\n")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestLoad(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	lister := corev1listers.NewConfigMapLister(indexer).ConfigMaps("sriov-network-operator")
+	g.Expect(Load(lister)).To(Equal(BuiltIn))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "sriov-network-operator"},
+		Data:       map[string]string{ConfigMapKey: testDatabase},
+	}
+	g.Expect(indexer.Add(cm)).To(Succeed())
+	g.Expect(Load(lister)).To(HaveLen(2))
+
+	// the built-in database is used until an invalid database is fixed
+	cm.Data[ConfigMapKey] = "- feature: sriov\n"
+	g.Expect(indexer.Update(cm)).To(Succeed())
+	g.Expect(Load(lister)).To(Equal(BuiltIn))
+}
+
+func TestBuiltIn(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	i40e := &sriovnetworkv1.InterfaceExt{Name: "ens803f0", Vendor: "8086", DeviceID: "158b", Driver: "i40e"}
+	g.Expect(BuiltIn.Check(FeatureSwitchdev, i40e)).To(MatchError(ContainSubstring("the driver has no switchdev mode")))
+	g.Expect(BuiltIn.Check(FeatureMinTxRate, i40e)).To(MatchError(ContainSubstring("the driver rejects a minimum rate")))
+	g.Expect(BuiltIn.Check(FeatureTrust, i40e)).To(Succeed())
+
+	cx6dx := &sriovnetworkv1.InterfaceExt{Name: "ens1f0", Vendor: "15b3", DeviceID: "101d", Driver: "mlx5_core"}
+	g.Expect(BuiltIn.Check(FeatureVdpa, cx6dx)).To(Succeed())
+	g.Expect(BuiltIn.Check(FeatureSwitchdev, cx6dx)).To(Succeed())
+	cx5 := &sriovnetworkv1.InterfaceExt{Name: "ens1f0", Vendor: "15b3", DeviceID: "1017", Driver: "mlx5_core"}
+	g.Expect(BuiltIn.Check(FeatureVdpa, cx5)).To(MatchError(ContainSubstring("vDPA requires a ConnectX-6 Dx or newer")))
+}
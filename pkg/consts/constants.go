@@ -22,10 +22,15 @@ const (
 	SystemdServiceOcpPath              = "./bindata/manifests/sriov-config-service/openshift"
 	SystemdServiceOcpMachineConfigName = "sriov-config-service"
 	ServiceCAConfigMapAnnotation       = "service.beta.openshift.io/inject-cabundle"
+	OperatorWebHookServiceName         = "operator-webhook-service"
+	InjectorWebHookServiceName         = "network-resources-injector-service"
+	PolicyCRDName                      = "sriovnetworknodepolicies.sriovnetwork.openshift.io"
 	InjectorWebHookName                = "network-resources-injector-config"
 	OperatorWebHookName                = "sriov-operator-webhook-config"
 	DeprecatedOperatorWebHookName      = "operator-webhook-config"
 	PluginPath                         = "./bindata/manifests/plugins"
+	MetricsExporterPath                = "./bindata/manifests/metrics-exporter"
+	MonitoringPath                     = "./bindata/manifests/monitoring"
 	DaemonPath                         = "./bindata/manifests/daemon"
 	DefaultPolicyName                  = "default"
 	ConfigMapName                      = "device-plugin-config"
@@ -42,17 +47,42 @@ const (
 	LinkTypeIB  = "IB"
 	LinkTypeETH = "ETH"
 
+	LinkStateUp   = "up"
+	LinkStateDown = "down"
+
+	HwTimestampingSupported = "supported"
+	HwTimestampingEnabled   = "enabled"
+
+	FailurePolicyFail   = "Fail"
+	FailurePolicyIgnore = "Ignore"
+
 	DeviceTypeVfioPci   = "vfio-pci"
 	DeviceTypeNetDevice = "netdevice"
 	VdpaTypeVirtio      = "virtio"
 	VdpaTypeVhost       = "vhost"
 
+	// the vfio modules and their options managed through modprobe.d
+	VfioModule                  = "vfio"
+	VfioIommuType1Module        = "vfio_iommu_type1"
+	VfioPciModule               = "vfio_pci"
+	VfioOptionUnsafeNoIommuMode = "enable_unsafe_noiommu_mode"
+	VfioPciOptionDisableIdleD3  = "disable_idle_d3"
+
 	ClusterTypeOpenshift  = "openshift"
 	ClusterTypeKubernetes = "kubernetes"
 
 	SriovConfBasePath          = "/etc/sriov-operator"
 	PfAppliedConfig            = SriovConfBasePath + "/pci"
 	SriovSwitchDevConfPath     = SriovConfBasePath + "/sriov_config.json"
+	ExternalPluginsDir         = SriovConfBasePath + "/plugins"
+	PciGuardFile               = SriovConfBasePath + "/pci-guard.yaml"
+	ReservedVfsFile            = SriovConfBasePath + "/reserved-vfs.json"
+	DataplaneVfsFile           = SriovConfBasePath + "/dataplane-vfs.json"
+	PfRenamesFile              = SriovConfBasePath + "/pf-renames.json"
+	PortSplitsFile             = SriovConfBasePath + "/port-splits.json"
+	UnicastMacsFile            = SriovConfBasePath + "/unicast-macs.json"
+	NMStateMtusFile            = SriovConfBasePath + "/nmstate-mtus.json"
+	BootIDFile                 = SriovConfBasePath + "/boot-id"
 	SriovHostSwitchDevConfPath = Host + SriovSwitchDevConfPath
 
 	DrainAnnotationState         = "sriovnetwork.openshift.io/state"
@@ -72,6 +102,20 @@ const (
 	SyncStatusSucceeded  = "Succeeded"
 	SyncStatusFailed     = "Failed"
 	SyncStatusInProgress = "InProgress"
+	// SyncStatusPaused is the sync status of the nodes holding changes while the configuration of the nodes is paused
+	SyncStatusPaused = "Paused"
+
+	NetworkTestPhasePending   = "Pending"
+	NetworkTestPhaseRunning   = "Running"
+	NetworkTestPhaseSucceeded = "Succeeded"
+	NetworkTestPhaseFailed    = "Failed"
+	NetworkTestLabel          = "sriovnetwork.openshift.io/network-test"
+	NetworkTestRunLabel       = "sriovnetwork.openshift.io/network-test-run"
+
+	SoakTestPolicyName    = "sriov-soak-test"
+	SoakTestResourceName  = "sriovsoaktest"
+	SoakTestPhaseApplying = "Applying"
+	SoakTestPhaseRemoving = "Removing"
 
 	MCPPauseAnnotationState = "sriovnetwork.openshift.io/state"
 	MCPPauseAnnotationTime  = "sriovnetwork.openshift.io/time"
@@ -79,16 +123,31 @@ const (
 	CheckpointFileName = "sno-initial-node-state.json"
 	Unknown            = "Unknown"
 
+	// OpenstackDevicesInfoFileName is the file the device information of OpenStack is persisted to, next to the
+	// checkpoint file
+	OpenstackDevicesInfoFileName = "openstack-devices-info.json"
+
 	SysBus                = "/sys/bus"
 	SysBusPciDevices      = SysBus + "/pci/devices"
 	SysBusPciDrivers      = SysBus + "/pci/drivers"
 	SysBusPciDriversProbe = SysBus + "/pci/drivers_probe"
 	SysClassNet           = "/sys/class/net"
-	ProcKernelCmdLine     = "/proc/cmdline"
-	NetClass              = 0x02
-	NumVfsFile            = "sriov_numvfs"
-	BusPci                = "pci"
-	BusVdpa               = "vdpa"
+	// NetNsDir is the directory of the named network namespaces of the host
+	NetNsDir             = "/var/run/netns"
+	ProcKernelCmdLine    = "/proc/cmdline"
+	Proc                 = "/proc"
+	ProcBootID           = "/proc/sys/kernel/random/boot_id"
+	SysKernelIommuGroups = "/sys/kernel/iommu_groups"
+	SysKernelDebug       = "/sys/kernel/debug"
+	SysKernelMmHugepages = "/sys/kernel/mm/hugepages"
+	SysCpuIsolated       = "/sys/devices/system/cpu/isolated"
+	SysModule            = "/sys/module"
+	ModprobeConfDir      = "/etc/modprobe.d"
+	NetClass             = 0x02
+	NumVfsFile           = "sriov_numvfs"
+	DriversAutoprobeFile = "sriov_drivers_autoprobe"
+	BusPci               = "pci"
+	BusVdpa              = "vdpa"
 
 	UdevFolder          = "/etc/udev"
 	UdevRulesFolder     = UdevFolder + "/rules.d"
@@ -106,10 +165,202 @@ const (
 		`ATTR{phys_port_name}=="pf%svf*", ` +
 		`IMPORT{program}="/etc/udev/switchdev-vf-link-name.sh $attr{phys_port_name}", ` +
 		`NAME="%s_$env{NUMBER}"`
+	// nolint:goconst
+	PfRenameUdevRule = `SUBSYSTEM=="net", ` +
+		`ACTION=="add", ` +
+		`KERNELS=="%s", ` +
+		`ATTR{phys_port_name}!="pf*", ` +
+		`NAME="%s"`
+	// nolint:goconst
+	PfPortRenameUdevRule = `SUBSYSTEM=="net", ` +
+		`ACTION=="add", ` +
+		`KERNELS=="%s", ` +
+		`ATTR{phys_port_name}=="%s", ` +
+		`NAME="%s"`
+
+	DefaultVfConfigConcurrency = 16
+
+	// defaults of the requests to the OpenStack metadata service, the deadline bounds all the attempts of the
+	// requests so that a flaky metadata service doesn't stall the daemon startup
+	DefaultOpenstackMetadataTimeout    = 10 * time.Second
+	DefaultOpenstackMetadataRetries    = 4
+	DefaultOpenstackMetadataBackoffMin = 1 * time.Second
+	DefaultOpenstackMetadataBackoffMax = 30 * time.Second
+	DefaultOpenstackMetadataDeadline   = 2 * time.Minute
+
+	// NMStateMtuTimeout is how long the VFs of a PF wait for kubernetes-nmstate to raise the MTU of the PF to the
+	// MTU of its policies, the config daemon sets it itself afterwards
+	NMStateMtuTimeout = 5 * time.Minute
+
+	// environment variables of the config daemon overriding these defaults, rendered from the openstackMetadata of
+	// the SriovOperatorConfig
+	OpenstackMetadataTimeoutEnv    = "OPENSTACK_METADATA_TIMEOUT"
+	OpenstackMetadataRetriesEnv    = "OPENSTACK_METADATA_RETRIES"
+	OpenstackMetadataBackoffMinEnv = "OPENSTACK_METADATA_BACKOFF_MIN"
+	OpenstackMetadataBackoffMaxEnv = "OPENSTACK_METADATA_BACKOFF_MAX"
+	OpenstackMetadataDeadlineEnv   = "OPENSTACK_METADATA_DEADLINE"
+
+	// FlowRulePrefBase is the first tc filter preference of the flow rules, the ingress filters with a
+	// preference in [FlowRulePrefBase, FlowRulePrefBase+MaxFlowRules) belong to the operator
+	FlowRulePrefBase = 40000
+	MaxFlowRules     = 1000
+	// PolicingPref is the tc filter preference of the policing of a VF on the hooks of its representor, out of the
+	// range of the flow rules
+	PolicingPref = FlowRulePrefBase + MaxFlowRules
+
+	DaemonProfileLabel = "sriovnetwork.openshift.io/daemon-profile"
+	DaemonProfileFull  = "full"
+	DaemonProfileLite  = "lite"
+	DrainSkipLabel     = "sriovnetwork.openshift.io/drain-skip"
+	// OvsHwOffloadLabel is set on the nodes of the pools enabling the hardware offload of OVN-Kubernetes, the config
+	// daemon enables the hw-offload flag of Open vSwitch and reports it in the OvsHwOffloadStateAnnotation
+	OvsHwOffloadLabel           = "sriovnetwork.openshift.io/ovs-hw-offload"
+	OvsHwOffloadStateAnnotation = "sriovnetwork.openshift.io/ovs-hw-offload-state"
+	OvsHwOffloadStateEnabled    = "Enabled"
+	OvsHwOffloadStateFailed     = "Failed"
+	// OvsHwOffloadRepresentorsAnnotation reports whether the representors of all the VFs of the switchdev PFs of the
+	// node exist, and whether the switchdev udev rule named them after their PF
+	OvsHwOffloadRepresentorsAnnotation = "sriovnetwork.openshift.io/ovs-hw-offload-representors"
+	OvsHwOffloadRepresentorsPending    = "Pending"
+	OvsHwOffloadRepresentorsCreated    = "Created"
+	OvsHwOffloadRepresentorsNamed      = "Named"
+	// OvnHardwareOffloadPoolLabel labels the policies of the hardware offload of OVN-Kubernetes with their pool
+	OvnHardwareOffloadPoolLabel = "sriovnetwork.openshift.io/ovn-hardware-offload-pool"
+	// NestedSriovLabel set to true or false on a virtual machine node forces whether the daemon provisions the VFs
+	// of its PFs instead of using the VFs created by the platform, it is detected when missing
+	NestedSriovLabel = "sriovnetwork.openshift.io/nested-sriov"
+	// VfsNotReadyTaint is set on a node rebooted with a VF layout not matching the last applied spec, until the
+	// config daemon applied the spec again
+	VfsNotReadyTaint = "sriovnetwork.openshift.io/vfs-not-ready"
+
+	PreflightAnnotation   = "sriovnetwork.openshift.io/preflight"
+	TraceparentAnnotation = "sriovnetwork.openshift.io/traceparent"
+	DrainModeAnnotation   = "sriovnetwork.openshift.io/drain-mode"
+	DrainStartAnnotation  = "sriovnetwork.openshift.io/drain-start"
+
+	// ManagedByLabel marks the objects rendered by the operator, the owner, source and hash annotations
+	// record who rendered them, from which manifests and with which content
+	ManagedByLabel   = "sriovnetwork.io/managed-by"
+	ManagedByValue   = "sriov-network-operator"
+	OwnerAnnotation  = "sriovnetwork.io/owner"
+	SourceAnnotation = "sriovnetwork.io/source"
+	HashAnnotation   = "sriovnetwork.io/hash"
+	// SourceGenerated is the source of the objects built in code rather than rendered from manifests
+	SourceGenerated = "generated"
+
+	// BackupExcludeLabel excludes the objects derived by the operator from the Velero backups, they are rendered
+	// again from the backed up custom resources after a restore
+	BackupExcludeLabel = "velero.io/exclude-from-backup"
+	// RestoreNameLabel is set by Velero on the objects it restored
+	RestoreNameLabel = "velero.io/restore-name"
+	// RestoredAnnotation records the restores of the policies the node state was rendered with
+	RestoredAnnotation = "sriovnetwork.openshift.io/restored"
+	// SelectedPfsAnnotation records on the node state the PFs selected by the linkUp and transceiverPresent
+	// predicates of the policies, by policy, they stay selected until the policy changes
+	SelectedPfsAnnotation = "sriovnetwork.openshift.io/selected-pfs"
+	// V1FieldsAnnotation keeps on the v2 SriovNetworkNodePolicies the v1 values of the fields v2 can't represent,
+	// e.g. the upper case linkType or a netFilter of another platform than OpenStack, they are restored in v1
+	V1FieldsAnnotation = "sriovnetwork.openshift.io/v1-fields"
+
+	// PolicySignatureAnnotation is the default annotation of the policies holding their signature, see the
+	// policySignature of the SriovOperatorConfig
+	PolicySignatureAnnotation = "sriovnetwork.openshift.io/signature"
+
+	// InterfaceClaimAnnotationPrefix prefixes the annotations of the nodes by which the host network managers claim
+	// the interfaces they own, the name of the annotation is the name of the manager and its value the list of
+	// interface names or PCI addresses, e.g. interface-claims.sriovnetwork.openshift.io/nmstate: "ens1f0,0000:3b:00.1"
+	InterfaceClaimAnnotationPrefix = "interface-claims.sriovnetwork.openshift.io/"
+	// InterfaceClaimOwner is the name of the claim of the SR-IOV operator
+	InterfaceClaimOwner = "sriov-network-operator"
+
+	// feature gates of the optional components, set in the featureGates of the SriovOperatorConfig
+	ResourceInjectorFeatureGate   = "resourceInjector"
+	OperatorWebhookFeatureGate    = "operatorWebhook"
+	MetricsExporterFeatureGate    = "metricsExporter"
+	SystemdModeFeatureGate        = "systemdMode"
+	MonitoringFeatureGate         = "monitoring"
+	ConfinedDaemonFeatureGate     = "confinedConfigDaemon"
+	NMStateIntegrationFeatureGate = "nmstateIntegration"
+
+	MetricsExporterPort = 9110
+
+	// PrivilegedHelperSocket is the socket the privileged helper of the confined config daemon serves on
+	PrivilegedHelperSocket = "/var/run/sriov-network-operator/privileged-helper.sock"
+	// KubeletSeccompDir is the directory of the seccomp profiles of the kubelet
+	KubeletSeccompDir = "/var/lib/kubelet/seccomp"
+	// ConfinedDaemonSeccompProfile is the seccomp profile of the confined config daemon, relative to KubeletSeccompDir
+	ConfinedDaemonSeccompProfile = "sriov-network-operator/config-daemon.json"
+	// ConfinedDaemonSELinuxType is the SELinux type of the confined config daemon
+	ConfinedDaemonSELinuxType = "sriov_config_daemon_t"
+	// ConfinedDaemonSELinuxModule is the SELinux policy module of the confined config daemon installed on the host
+	ConfinedDaemonSELinuxModule = "/var/lib/sriov/sriov_config_daemon.cil"
+	// CniBinDir is the directory of the CNI plugins of the node mounted in the config daemon container
+	CniBinDir = "/opt/cni/bin"
+	// CDISpecDir is the directory of the Container Device Interface specs of the host
+	CDISpecDir = "/var/run/cdi"
+	// CDIKind is the kind of the VFs in the generated CDI spec, the VFs are injected as sriovnetwork.openshift.io/vf=<PCI address>
+	CDIKind = "sriovnetwork.openshift.io/vf"
+
+	FeatureGateStateEnabled  = "Enabled"
+	FeatureGateStateDisabled = "Disabled"
+	FeatureGateStateFailed   = "Failed"
+
+	// BiosSettingsConditionType is the node condition reporting whether the BIOS settings required by SR-IOV are enabled
+	BiosSettingsConditionType            = "SriovBiosSettings"
+	BiosSettingsReasonEnabled            = "Enabled"
+	BiosSettingsReasonDisabled           = "Disabled"
+	BiosSettingsReasonRemediationPending = "RemediationPending"
+	BiosSettingsReasonBmcError           = "BmcError"
+
+	// the node conditions of the SR-IOV faults of the node, in the format of node-problem-detector: a condition is
+	// True while its problem is present
+	PfDownConditionType            = "SriovPfDown"
+	FirmwareFaultConditionType     = "SriovFirmwareFault"
+	VfCreationFailureConditionType = "SriovVfCreationFailure"
+	PfDownReason                   = "PfLinkDown"
+	PfUpReason                     = "PfLinkUp"
+	FirmwareFaultReason            = "FirmwareFault"
+	FirmwareHealthyReason          = "FirmwareHealthy"
+	VfCreationFailureReason        = "RepeatedVfCreationFailure"
+	VfCreationSucceededReason      = "VfCreationSucceeded"
+
+	// OperatorWebhookCertificateCondition and InjectorWebhookCertificateCondition are the conditions of the
+	// SriovOperatorConfig reporting whether the certificates of the webhooks are valid
+	OperatorWebhookCertificateCondition = "OperatorWebhookCertificateValid"
+	InjectorWebhookCertificateCondition = "InjectorWebhookCertificateValid"
+	CertificateReasonValid              = "Valid"
+	CertificateReasonExpiringSoon       = "ExpiringSoon"
+	CertificateReasonExpired            = "Expired"
+	CertificateReasonInvalid            = "Invalid"
+
+	HookPointPreDrain  = "preDrain"
+	HookPointPreApply  = "preApply"
+	HookPointPostApply = "postApply"
+	HookPointPreReboot = "preReboot"
+
+	HookFailurePolicyFail   = "Fail"
+	HookFailurePolicyIgnore = "Ignore"
+
+	HookResultSucceeded = "Succeeded"
+	HookResultFailed    = "Failed"
+	HookResultIgnored   = "Ignored"
+
+	DefaultHookTimeout = 60 * time.Second
 
 	KernelArgPciRealloc = "pci=realloc"
 	KernelArgIntelIommu = "intel_iommu=on"
 	KernelArgIommuPt    = "iommu=pt"
+	// KernelArgIommuPassthrough is the arm64 equivalent of iommu=pt, the SMMU is enabled by the firmware
+	KernelArgIommuPassthrough = "iommu.passthrough=1"
+
+	ArchAMD64 = "amd64"
+	ArchARM64 = "arm64"
+	ArchS390X = "s390x"
+
+	// NodeOSLabel is the well-known label of the operating system of the nodes
+	NodeOSLabel   = "kubernetes.io/os"
+	NodeOSLinux   = "linux"
+	NodeOSWindows = "windows"
 )
 
 const (
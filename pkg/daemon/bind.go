@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/dataplane"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// bindRetryKey is the work item of the retries of the binding of the unbound VFs, it is queued with the
+	// generations of the node state so the retries never run during a sync
+	bindRetryKey int64 = -2
+	// bindRetryBaseDelay is the delay before the second attempt to bind a VF, doubled after each attempt up to
+	// bindRetryMaxDelay
+	bindRetryBaseDelay = 30 * time.Second
+	bindRetryMaxDelay  = 10 * time.Minute
+	// bindRetryEscalation is the number of attempts after which a VF still unbound is reported
+	bindRetryEscalation = 5
+)
+
+// bindRetry are the attempts to bind a VF left without driver
+type bindRetry struct {
+	attempts  int
+	next      time.Time
+	escalated bool
+}
+
+// unboundVf is a VF of a VF group of the spec bound to no driver
+type unboundVf struct {
+	pciAddress string
+	deviceType string
+	// description identifies the VF and the last error of its probe in the events and in the node state
+	description string
+}
+
+// unboundVfs returns the VFs of the VF groups of the spec the discovered PFs report without driver, the VFs outside
+// of the VF groups are left out
+func unboundVfs(spec sriovnetworkv1.Interfaces, ifaces []sriovnetworkv1.InterfaceExt) []unboundVf {
+	groups := map[string][]sriovnetworkv1.VfGroup{}
+	for _, iface := range spec {
+		groups[iface.PciAddress] = iface.VfGroups
+	}
+	unbound := []unboundVf{}
+	for i := range ifaces {
+		iface := &ifaces[i]
+		for _, vf := range iface.GetVirtualFunctions() {
+			if vf.Driver != "" {
+				continue
+			}
+			for _, group := range groups[iface.PciAddress] {
+				if !sriovnetworkv1.IndexInRange(vf.VfID, group.VfRange) {
+					continue
+				}
+				description := fmt.Sprintf("%s VF %d(%s)", iface.Name, vf.VfID, vf.PciAddress)
+				if vf.BindError != "" {
+					description += ": " + vf.BindError
+				}
+				unbound = append(unbound, unboundVf{pciAddress: vf.PciAddress, deviceType: group.DeviceType, description: description})
+				break
+			}
+		}
+	}
+	return unbound
+}
+
+// bindRetryDelay returns the delay after the attempt to bind a VF
+func bindRetryDelay(attempts int) time.Duration {
+	delay := bindRetryBaseDelay
+	for i := 1; i < attempts && delay < bindRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > bindRetryMaxDelay {
+		delay = bindRetryMaxDelay
+	}
+	return delay
+}
+
+// retryUnboundVfs binds again the VFs of the applied node state left without driver, with a backoff between the
+// attempts. The VFs still unbound after bindRetryEscalation attempts are reported in the VfsUnbound condition of the
+// node state and by a warning event.
+func (dn *Daemon) retryUnboundVfs() {
+	// the host is configured by the systemd service or by the virtual platform, or the configuration is frozen
+	if dn.nodeState.GetGeneration() == 0 || vars.UsingSystemdMode || vars.PlatformType != consts.Baremetal ||
+		dn.pauseNodeConfiguration.Load() || dn.isNodeDraining() {
+		return
+	}
+	ifaces, err := dn.HostHelpers.DiscoverSriovDevices(dn.HostHelpers)
+	if err != nil {
+		log.Log.Error(err, "retryUnboundVfs(): failed to discover the SR-IOV devices")
+		return
+	}
+
+	now := time.Now()
+	retries := map[string]*bindRetry{}
+	stuck := []string{}
+	for _, vf := range unboundVfs(dn.nodeState.Spec.Interfaces, ifaces) {
+		retry, found := dn.bindRetries[vf.pciAddress]
+		if !found {
+			retry = &bindRetry{}
+		}
+		// the VFs bound again are forgotten
+		retries[vf.pciAddress] = retry
+		if retry.attempts >= bindRetryEscalation {
+			stuck = append(stuck, vf.description)
+			if !retry.escalated {
+				retry.escalated = true
+				log.Log.Info("retryUnboundVfs(): the VF is still unbound after the attempts to bind it",
+					"vf", vf.description, "attempts", retry.attempts)
+				dn.eventRecorder.SendWarningEvent("VfStuckUnbound", fmt.Sprintf(
+					"VF %s is still unbound after %d attempts to bind it", vf.description, retry.attempts))
+			}
+			continue
+		}
+		if now.Before(retry.next) {
+			continue
+		}
+		retry.attempts++
+		retry.next = now.Add(bindRetryDelay(retry.attempts))
+		log.Log.Info("retryUnboundVfs(): bind the unbound VF again", "vf", vf.description, "attempt", retry.attempts)
+		if vf.deviceType == consts.DeviceTypeVfioPci {
+			err = dn.HostHelpers.BindDpdkDriver(vf.pciAddress, consts.DeviceTypeVfioPci)
+		} else {
+			err = dn.HostHelpers.BindDefaultDriver(vf.pciAddress)
+		}
+		metrics.IncVfBindRetries(err)
+		if err != nil {
+			log.Log.Error(err, "retryUnboundVfs(): failed to bind the VF", "vf", vf.pciAddress)
+		}
+	}
+	dn.bindRetries = retries
+
+	if strings.Join(stuck, ",") != strings.Join(dn.stuckVfs, ",") || dn.stuckVfs == nil {
+		if err := dn.statusWriter.SetUnboundVfs(stuck); err != nil {
+			log.Log.Error(err, "retryUnboundVfs(): failed to report the unbound VFs")
+			return
+		}
+		dn.stuckVfs = stuck
+	}
+}
+
+// syncDataplaneVfs rewrites the dataplane VFs file of the applied node state when the drivers of its VFs changed since
+// the last sync, e.g. a VF bound again by retryUnboundVfs or rebound on the node, the file is left untouched otherwise
+func (dn *Daemon) syncDataplaneVfs() {
+	if dn.nodeState.GetGeneration() == 0 || vars.UsingSystemdMode || dn.pauseNodeConfiguration.Load() || dn.isNodeDraining() {
+		return
+	}
+	if err := dataplane.Sync(dn.nodeState.Spec.Interfaces); err != nil {
+		log.Log.Error(err, "syncDataplaneVfs(): failed to write the dataplane VFs file")
+	}
+}
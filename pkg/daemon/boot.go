@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// startBootVerification verifies the VF layout of the node when the daemon starts on a new boot of the node. The
+// layout is compared with the last applied spec of the PFs, a node with a diverging layout is tainted until the first
+// sync of the daemon applied the spec again. The boot is only recorded once verified, a daemon restarted before the
+// end of the verification verifies the layout again.
+func (dn *Daemon) startBootVerification() error {
+	bootID, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcBootID))
+	if err != nil {
+		return fmt.Errorf("failed to read the boot ID of the node: %v", err)
+	}
+	dn.bootID = strings.TrimSpace(string(bootID))
+	lastBootID, err := os.ReadFile(utils.GetHostExtensionPath(consts.BootIDFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read the last verified boot ID: %v", err)
+	}
+	if strings.TrimSpace(string(lastBootID)) == dn.bootID {
+		return nil
+	}
+
+	log.Log.Info("startBootVerification(): verify the VF layout of the new boot of the node", "bootID", dn.bootID)
+	spec := sriovnetworkv1.Interfaces{}
+	nodeState, err := dn.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+	if err == nil {
+		spec = nodeState.Spec.Interfaces
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the node state: %v", err)
+	}
+	drifts, err := dn.detectHostDrift(spec)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		// the taint of a daemon restarted during the verification is removed
+		if err := dn.setVfsNotReadyTaint(false); err != nil {
+			return err
+		}
+		return dn.recordBoot()
+	}
+	log.Log.Info("startBootVerification(): the VF layout doesn't match the last applied spec, taint the node",
+		"drifts", drifts)
+	dn.eventRecorder.SendWarningEvent("BootVerificationFailed",
+		fmt.Sprintf("VF layout doesn't match the last applied spec, reapplying it: %s", strings.Join(drifts, ", ")))
+	if err := dn.setVfsNotReadyTaint(true); err != nil {
+		return err
+	}
+	dn.bootVerificationPending = true
+	return nil
+}
+
+// completeBootVerification verifies the VF layout again once the daemon synced the spec, and removes the taint of the
+// node when the spec has been applied again
+func (dn *Daemon) completeBootVerification(spec sriovnetworkv1.Interfaces) error {
+	if !dn.bootVerificationPending {
+		return nil
+	}
+	drifts, err := dn.detectHostDrift(spec)
+	if err != nil {
+		return err
+	}
+	if len(drifts) > 0 {
+		return fmt.Errorf("the VF layout doesn't match the applied spec: %s", strings.Join(drifts, ", "))
+	}
+	if err := dn.setVfsNotReadyTaint(false); err != nil {
+		return err
+	}
+	if err := dn.recordBoot(); err != nil {
+		return err
+	}
+	dn.bootVerificationPending = false
+	log.Log.Info("completeBootVerification(): the VF layout matches the applied spec, node untainted")
+	dn.eventRecorder.SendEvent("BootVerificationSucceeded", "VF layout matches the applied spec")
+	return nil
+}
+
+// recordBoot records the boot as verified
+func (dn *Daemon) recordBoot() error {
+	if err := os.WriteFile(utils.GetHostExtensionPath(consts.BootIDFile), []byte(dn.bootID+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to record the verified boot ID: %v", err)
+	}
+	return nil
+}
+
+// setVfsNotReadyTaint adds or removes the NoSchedule taint of the node keeping the pods away while its VFs are not
+// ready
+func (dn *Daemon) setVfsNotReadyTaint(present bool) error {
+	taint := corev1.Taint{Key: consts.VfsNotReadyTaint, Effect: corev1.TaintEffectNoSchedule}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		taints := []corev1.Taint{}
+		found := false
+		for _, t := range node.Spec.Taints {
+			if t.MatchTaint(&taint) {
+				found = true
+				if !present {
+					continue
+				}
+			}
+			taints = append(taints, t)
+		}
+		if found == present {
+			return nil
+		}
+		if present {
+			taints = append(taints, taint)
+		}
+		node.Spec.Taints = taints
+		_, err = dn.kubeClient.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
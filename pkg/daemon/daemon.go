@@ -1,17 +1,16 @@
 package daemon
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
-	"os/exec"
 	"path"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
@@ -40,11 +39,20 @@ import (
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
 	sninformer "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/informers/externalversions"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/dataplane"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/lldp"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	genericplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/quirks"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/reserved"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/systemd"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/tracing"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -60,6 +68,8 @@ const (
 type Message struct {
 	syncStatus    string
 	lastSyncError string
+	// lastSyncErrorReason is the reason of lastSyncError in the error taxonomy
+	lastSyncErrorReason snerrors.Reason
 }
 
 type Daemon struct {
@@ -98,6 +108,14 @@ type Daemon struct {
 
 	disableDrain bool
 
+	// pauseNodeConfiguration keeps the daemon from applying any change to the host, it is written by the informer of
+	// the operator config and read by the worker and by the periodic refresh
+	pauseNodeConfiguration atomic.Bool
+
+	// singleNode is true when the node is the only node of the cluster, it is written by the node informer and
+	// read by the sync of the node state
+	singleNode atomic.Bool
+
 	nodeLister listerv1.NodeLister
 
 	workqueue workqueue.RateLimitingInterface
@@ -105,6 +123,52 @@ type Daemon struct {
 	mcpName string
 
 	eventRecorder *EventRecorder
+
+	lldpListener *lldp.Listener
+
+	statusWriter *NodeStateStatusWriter
+
+	// hookStatuses are the statuses of the last runs of the hooks of the node
+	hookStatuses []sriovnetworkv1.HookStatus
+
+	// drainEscalation escalates the drains not completed in time
+	drainEscalation *sriovnetworkv1.DrainEscalationConfig
+
+	// drainStatus is the last reported progress of the drain of the node
+	drainStatus *sriovnetworkv1.DrainStatus
+
+	// drainAbortedGeneration is the generation of the node state whose drain was aborted
+	drainAbortedGeneration int64
+
+	// startTime and synced are used to report the time the daemon needed to complete its first sync
+	startTime time.Time
+	synced    bool
+
+	// bootID is the boot of the node the daemon runs on, bootVerificationPending is true while the VF layout of a new
+	// boot doesn't match the last applied spec
+	bootID                  string
+	bootVerificationPending bool
+
+	// remediateHostDrift syncs the node state again when the configuration of the PFs differs from the applied spec,
+	// hostDrifts are the drifted PFs of the last audit and lastDriftAudit its time
+	remediateHostDrift bool
+	hostDrifts         []string
+	lastDriftAudit     time.Time
+	// bindRetries are the attempts to bind the unbound VFs by PCI address, stuckVfs the VFs still unbound after them
+	bindRetries map[string]*bindRetry
+	stuckVfs    []string
+	// syncAgain applies the generation of the node state again, even if it has already been applied
+	syncAgain bool
+
+	// reportProblems sets the node conditions of the SR-IOV faults, nodeProblemsReported is true while the conditions
+	// may be set on the node, lastNodeProblems is the time of the last detection
+	reportProblems       bool
+	nodeProblemsReported bool
+	lastNodeProblems     time.Time
+	// vfCreationFailures is the number of consecutive syncs failing to configure the PFs, lastVfCreationError the
+	// error of the last of them
+	vfCreationFailures  int
+	lastVfCreationError string
 }
 
 const (
@@ -136,6 +200,8 @@ func New(
 	syncCh <-chan struct{},
 	refreshCh chan<- Message,
 	er *EventRecorder,
+	lldpListener *lldp.Listener,
+	statusWriter *NodeStateStatusWriter,
 	disabledPlugins []string,
 ) *Daemon {
 	return &Daemon{
@@ -169,8 +235,13 @@ func New(
 		workqueue: workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(
 			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(updateDelay), 1)},
 			workqueue.NewItemExponentialFailureRateLimiter(1*time.Second, maxUpdateBackoff)), "SriovNetworkNodeState"),
+		startTime:       time.Now(),
 		eventRecorder:   er,
+		lldpListener:    lldpListener,
+		statusWriter:    statusWriter,
 		disabledPlugins: disabledPlugins,
+		// the conditions left by a previous run of the daemon are removed by the first detection
+		nodeProblemsReported: true,
 	}
 }
 
@@ -204,8 +275,8 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	if err := dn.prepareNMUdevRule(); err != nil {
 		log.Log.Error(err, "failed to prepare udev files to disable network manager on requested VFs")
 	}
-	if err := dn.tryCreateSwitchdevUdevRule(); err != nil {
-		log.Log.Error(err, "failed to create udev files for switchdev")
+	if err := dn.startBootVerification(); err != nil {
+		log.Log.Error(err, "failed to verify the VF layout of the boot of the node")
 	}
 
 	var timeout int64 = 5
@@ -246,6 +317,17 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	nodeInformerFactory := informers.NewSharedInformerFactory(dn.kubeClient,
 		time.Second*15,
 	)
+	if vars.DaemonProfile == consts.DaemonProfileLite {
+		// the lite profile targets single node deployments, there is no other node to
+		// coordinate the drain with so only the own node is cached
+		nodeInformerFactory = informers.NewSharedInformerFactoryWithOptions(dn.kubeClient,
+			time.Second*15,
+			informers.WithTweakListOptions(func(lo *metav1.ListOptions) {
+				lo.FieldSelector = metadataKey + "=" + vars.NodeName
+			}),
+		)
+		dn.detectSingleNode()
+	}
 	dn.nodeLister = nodeInformerFactory.Core().V1().Nodes().Lister()
 	nodeInformer := nodeInformerFactory.Core().V1().Nodes().Informer()
 	nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -259,6 +341,10 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	if ok := cache.WaitForCacheSync(stopCh, cfgInformer.HasSynced, nodeInformer.HasSynced, informer.HasSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
+	// the udev rule is written once the operator config tells whether the node configuration is paused
+	if err := dn.tryCreateSwitchdevUdevRule(); err != nil {
+		log.Log.Error(err, "failed to create udev files for switchdev")
+	}
 
 	log.Log.Info("Starting workers")
 	// Launch one worker to process
@@ -274,8 +360,9 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 			log.Log.Error(err, "got an error")
 			if more {
 				dn.refreshCh <- Message{
-					syncStatus:    consts.SyncStatusFailed,
-					lastSyncError: err.Error(),
+					syncStatus:          consts.SyncStatusFailed,
+					lastSyncError:       err.Error(),
+					lastSyncErrorReason: snerrors.ReasonOf(err),
 				}
 			}
 			return err
@@ -284,6 +371,15 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 			if err := dn.tryCreateSwitchdevUdevRule(); err != nil {
 				log.Log.V(2).Error(err, "Could not create udev rule")
 			}
+			if time.Since(dn.lastDriftAudit) >= driftAuditInterval {
+				dn.lastDriftAudit = time.Now()
+				dn.workqueue.Add(driftAuditKey)
+			}
+			dn.workqueue.Add(bindRetryKey)
+			if time.Since(dn.lastNodeProblems) >= nodeProblemsInterval {
+				dn.lastNodeProblems = time.Now()
+				dn.workqueue.Add(nodeProblemsKey)
+			}
 		}
 	}
 }
@@ -328,12 +424,31 @@ func (dn *Daemon) processNextWorkItem() bool {
 			return nil
 		}
 
+		if key == driftAuditKey {
+			dn.workqueue.Forget(obj)
+			dn.auditHostDrift()
+			return nil
+		}
+		if key == bindRetryKey {
+			dn.workqueue.Forget(obj)
+			dn.retryUnboundVfs()
+			dn.syncDataplaneVfs()
+			return nil
+		}
+		if key == nodeProblemsKey {
+			dn.workqueue.Forget(obj)
+			dn.reportNodeProblems()
+			return nil
+		}
+
 		err := dn.nodeStateSyncHandler()
+		dn.recordSyncResult(err)
 		if err != nil {
 			// Ereport error message, and put the item back to work queue for retry.
 			dn.refreshCh <- Message{
-				syncStatus:    consts.SyncStatusFailed,
-				lastSyncError: err.Error(),
+				syncStatus:          consts.SyncStatusFailed,
+				lastSyncError:       err.Error(),
+				lastSyncErrorReason: snerrors.ReasonOf(err),
 			}
 			<-dn.syncCh
 			dn.workqueue.AddRateLimited(key)
@@ -342,6 +457,10 @@ func (dn *Daemon) processNextWorkItem() bool {
 		// Finally, if no error occurs we Forget this item so it does not
 		// get queued again until another change happens.
 		dn.workqueue.Forget(obj)
+		if !dn.synced {
+			metrics.ObserveDaemonStartup(dn.startTime)
+			dn.synced = true
+		}
 		log.Log.Info("Successfully synced")
 		return nil
 	}(obj)
@@ -357,6 +476,19 @@ func (dn *Daemon) nodeAddHandler(obj interface{}) {
 	dn.nodeUpdateHandler(nil, obj)
 }
 
+// detectSingleNode counts the nodes of the cluster once, for the lite profile whose node informer only caches
+// its own node
+func (dn *Daemon) detectSingleNode() {
+	// a second node is enough to tell the cluster has several nodes
+	nodes, err := dn.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{Limit: 2})
+	if err != nil {
+		log.Log.Error(err, "detectSingleNode(): failed to list nodes, the node is drained as in a multi node cluster")
+		return
+	}
+	dn.singleNode.Store(len(nodes.Items) == 1 && nodes.Continue == "")
+	log.Log.V(0).Info("detectSingleNode()", "single-node", dn.singleNode.Load())
+}
+
 func (dn *Daemon) nodeUpdateHandler(old, new interface{}) {
 	node, err := dn.nodeLister.Get(vars.NodeName)
 	if errors.IsNotFound(err) {
@@ -364,12 +496,28 @@ func (dn *Daemon) nodeUpdateHandler(old, new interface{}) {
 		return
 	}
 	dn.node = node.DeepCopy()
+	guard.SetClaims(guard.ParseClaims(node.Annotations))
+
+	profile := node.Labels[consts.DaemonProfileLabel]
+	if profile == "" {
+		profile = consts.DaemonProfileFull
+	}
+	if profile != vars.DaemonProfile {
+		// the profile is selected at startup, restart the daemon to apply the new one
+		log.Log.Info("nodeUpdateHandler(): daemon profile changed, restarting", "current", vars.DaemonProfile, "new", profile)
+		dn.exitCh <- fmt.Errorf("daemon profile changed from %s to %s", vars.DaemonProfile, profile)
+		return
+	}
 
 	nodes, err := dn.nodeLister.List(labels.Everything())
 	if err != nil {
 		log.Log.Error(err, "nodeUpdateHandler(): failed to list nodes")
 		return
 	}
+	// the lite profile only caches its own node, the nodes are counted once by detectSingleNode
+	if vars.DaemonProfile != consts.DaemonProfileLite {
+		dn.singleNode.Store(len(nodes) == 1)
+	}
 
 	// Checking if other nodes are draining
 	for _, otherNode := range nodes {
@@ -400,15 +548,37 @@ func (dn *Daemon) operatorConfigAddHandler(obj interface{}) {
 func (dn *Daemon) operatorConfigChangeHandler(old, new interface{}) {
 	newCfg := new.(*sriovnetworkv1.SriovOperatorConfig)
 	snolog.SetLogLevel(newCfg.Spec.LogLevel)
+	tracing.Configure(newCfg.TracingEndpoint(), "sriov-network-config-daemon")
 
 	newDisableDrain := newCfg.Spec.DisableDrain
 	if dn.disableDrain != newDisableDrain {
 		dn.disableDrain = newDisableDrain
 		log.Log.Info("Set Disable Drain", "value", dn.disableDrain)
 	}
+
+	if dn.pauseNodeConfiguration.Swap(newCfg.Spec.PauseNodeConfiguration) != newCfg.Spec.PauseNodeConfiguration {
+		log.Log.Info("Set Pause Node Configuration", "value", newCfg.Spec.PauseNodeConfiguration)
+	}
+
+	if dn.remediateHostDrift != newCfg.Spec.RemediateHostDrift {
+		dn.remediateHostDrift = newCfg.Spec.RemediateHostDrift
+		log.Log.Info("Set Remediate Host Drift", "value", dn.remediateHostDrift)
+	}
+
+	if dn.reportProblems != newCfg.Spec.ReportNodeProblems {
+		dn.reportProblems = newCfg.Spec.ReportNodeProblems
+		log.Log.Info("Set Report Node Problems", "value", dn.reportProblems)
+	}
+
+	dn.drainEscalation = newCfg.Spec.DrainEscalation
+	quirks.Set(newCfg.Spec.DriverQuirks)
+
+	dn.lldpListener.SetEnabled(newCfg.Spec.EnableLldp)
+	dn.statusWriter.SetCompact(newCfg.Spec.CompactNodeState)
+	dn.statusWriter.SetCDISpec(newCfg.Spec.GenerateCDISpecs)
 }
 
-func (dn *Daemon) nodeStateSyncHandler() error {
+func (dn *Daemon) nodeStateSyncHandler() (syncErr error) {
 	var err error
 	// Get the latest NodeState
 	var latestState *sriovnetworkv1.SriovNetworkNodeState
@@ -427,7 +597,9 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		}
 	}
 
-	if dn.nodeState.GetGeneration() == latest {
+	// a generation is applied again to remediate the drift of the host, until it is applied successfully
+	syncAgain := dn.syncAgain
+	if dn.nodeState.GetGeneration() == latest && !syncAgain {
 		if vars.UsingSystemdMode {
 			serviceEnabled, err := dn.HostHelpers.IsServiceEnabled(systemd.SriovServicePath)
 			if err != nil {
@@ -466,6 +638,12 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 				return nil
 			}
 		}
+		// the flow rules are lost when the driver of a PF is reloaded, they are reinstalled on every resync unless
+		// the node configuration is paused
+		if !dn.pauseNodeConfiguration.Load() {
+			dn.syncFlowRules(latestState)
+		}
+
 		log.Log.V(0).Info("nodeStateSyncHandler(): Interface not changed")
 		if latestState.Status.LastSyncError != "" ||
 			latestState.Status.SyncStatus != consts.SyncStatusSucceeded {
@@ -480,6 +658,26 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		return nil
 	}
 
+	// the spec rendered by an operator of another version is not applied until the operator and the daemon agree
+	if err := latestState.CheckSpecVersion(); err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): incompatible node state, waiting for the operator or the daemon to be upgraded")
+		return snerrors.Wrap(snerrors.ErrIncompatibleSpecVersion, err)
+	}
+
+	if dn.pauseNodeConfiguration.Load() {
+		// the devices are still discovered by the writer, the changes are applied once the configuration is resumed
+		log.Log.Info("nodeStateSyncHandler(): node configuration paused, the new generation is not applied", "generation", latest)
+		if latestState.Status.SyncStatus != consts.SyncStatusPaused {
+			dn.refreshCh <- Message{
+				syncStatus:    consts.SyncStatusPaused,
+				lastSyncError: "",
+			}
+			// wait for writer to refresh the status
+			<-dn.syncCh
+		}
+		return nil
+	}
+
 	if latestState.GetGeneration() == 1 && len(latestState.Spec.Interfaces) == 0 {
 		err = dn.HostHelpers.ClearPCIAddressFolder()
 		if err != nil {
@@ -501,6 +699,15 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		return nil
 	}
 
+	// the sync continues the trace of the render of the node state
+	ctx, span := tracing.Start(
+		tracing.ContextWithTraceparent(context.Background(), latestState.Annotations[consts.TraceparentAnnotation]),
+		"daemon.sync", "node", vars.NodeName, "generation", strconv.FormatInt(latest, 10))
+	defer func() {
+		span.RecordError(syncErr)
+		span.End()
+	}()
+
 	dn.refreshCh <- Message{
 		syncStatus:    consts.SyncStatusInProgress,
 		lastSyncError: "",
@@ -526,11 +733,16 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 			return err
 		}
 	}
+	// the generic plugin skips the spec it already applied, the remediation of the host drift applies it again
+	if p, ok := dn.loadedPlugins[GenericPluginName].(*genericplugin.GenericPlugin); ok && syncAgain {
+		p.LastState = nil
+	}
 
 	reqReboot := false
 	reqDrain := false
 
 	// check if any of the plugins required to drain or reboot the node
+	phaseStart := time.Now()
 	for k, p := range dn.loadedPlugins {
 		d, r := false, false
 		if dn.nodeState.GetName() == "" {
@@ -538,7 +750,10 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		} else {
 			log.Log.V(0).Info("nodeStateSyncHandler(): calling OnNodeStateChange for an updated node state")
 		}
-		d, r, err = p.OnNodeStateChange(latestState)
+		err = traceStep(ctx, "plugin.onNodeStateChange", func() error {
+			d, r, err = p.OnNodeStateChange(latestState)
+			return err
+		}, "plugin", k)
 		if err != nil {
 			log.Log.Error(err, "nodeStateSyncHandler(): OnNodeStateChange plugin error", "plugin-name", k)
 			return err
@@ -547,6 +762,30 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		reqDrain = reqDrain || d
 		reqReboot = reqReboot || r
 	}
+	metrics.ObserveDaemonPhase(metrics.DaemonPhaseOnNodeStateChange, phaseStart)
+	if dn.statusWriter != nil && dn.statusWriter.platformDrainRequired.Load() {
+		log.Log.Info("nodeStateSyncHandler(): the device information of the platform was restored from the node status, drain required")
+		reqDrain = true
+	}
+	// the rename of a PF sets its link down, the PFs are renamed once the node is drained
+	renamePending, err := dn.pfNamesPending(latestState)
+	if err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): failed to check the names of the PFs")
+		return err
+	}
+	if renamePending {
+		log.Log.Info("nodeStateSyncHandler(): the PFs are renamed, drain required")
+		reqDrain = true
+	}
+	// enabling the hardware offload of Open vSwitch restarts ovs-vswitchd once the node is drained
+	ovsRestartPending, err := dn.ovsHardwareOffloadPending()
+	if err != nil {
+		return err
+	}
+	if ovsRestartPending {
+		log.Log.Info("nodeStateSyncHandler(): ovs-vswitchd is restarted to enable the hardware offload, drain required")
+		reqDrain = true
+	}
 
 	// When running using systemd check if the applied configuration is the latest one
 	// or there is a new config we need to apply
@@ -581,24 +820,47 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	log.Log.V(0).Info("nodeStateSyncHandler(): aggregated daemon",
 		"drain-required", reqDrain, "reboot-required", reqReboot, "disable-drain", dn.disableDrain)
 
-	for k, p := range dn.loadedPlugins {
-		// Skip both the general and virtual plugin apply them last
-		if k != GenericPluginName && k != VirtualPluginName {
-			err := p.Apply()
-			if err != nil {
-				log.Log.Error(err, "nodeStateSyncHandler(): plugin Apply failed", "plugin-name", k)
-				return err
-			}
-		}
+	// the interfaces of the spec are claimed before they are configured, the interfaces claimed by another host
+	// network manager are left out and not configured
+	if err := dn.setNodeAnnotations(vars.NodeName, map[string]string{
+		consts.InterfaceClaimAnnotationPrefix + consts.InterfaceClaimOwner: guard.Claim(latestState.Spec.Interfaces),
+	}); err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): failed to claim the interfaces")
+		return err
 	}
+
 	if dn.platformHelpers.IsOpenshiftCluster() && !dn.platformHelpers.IsHypershift() {
 		if err = dn.getNodeMachinePool(); err != nil {
 			return err
 		}
 	}
 	if reqDrain {
+		if latestState.Generation == dn.drainAbortedGeneration {
+			return snerrors.Wrap(snerrors.ErrDrainAborted,
+				fmt.Errorf("the drain of the generation %d of the node state was aborted, waiting for a new generation", latestState.Generation))
+		}
+		mode := dn.drainMode(latestState)
+		// the node is neither drained nor cordoned with the None drain mode, there is nothing to prepare
+		if mode != sriovnetworkv1.DrainModeNone {
+			if err := dn.runHooks(ctx, latestState, consts.HookPointPreDrain); err != nil {
+				return err
+			}
+		}
+		drainStart := dn.drainStartTime()
 		if !dn.isNodeDraining() {
-			if !dn.disableDrain {
+			// the mode is kept until the drain completes, even if the node is reconfigured again meanwhile
+			if err := dn.setNodeAnnotations(vars.NodeName, map[string]string{consts.DrainModeAnnotation: mode}); err != nil {
+				log.Log.Error(err, "nodeStateSyncHandler(): failed to annotate node")
+				return err
+			}
+			switch mode {
+			case sriovnetworkv1.DrainModeCordon:
+				// the node is not drained, there is no need to coordinate with the other nodes
+				if err := dn.annotateNode(vars.NodeName, annoDraining); err != nil {
+					log.Log.Error(err, "nodeStateSyncHandler(): failed to annotate node")
+					return err
+				}
+			case sriovnetworkv1.DrainModeDrain:
 				ctx, cancel := context.WithCancel(context.TODO())
 				defer cancel()
 
@@ -607,6 +869,14 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 				go dn.getDrainLock(ctx, done)
 				<-done
 			}
+			// the drain escalation doesn't account for the wait of the drain lock
+			drainStart = time.Now().UTC().Truncate(time.Second)
+			if err := dn.setNodeAnnotations(vars.NodeName, map[string]string{
+				consts.DrainStartAnnotation: drainStart.Format(time.RFC3339),
+			}); err != nil {
+				log.Log.Error(err, "nodeStateSyncHandler(): failed to annotate node")
+				return err
+			}
 		}
 
 		if dn.platformHelpers.IsOpenshiftCluster() && !dn.platformHelpers.IsHypershift() {
@@ -616,22 +886,56 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 			}
 		}
 
-		if dn.disableDrain {
-			log.Log.Info("nodeStateSyncHandler(): disable drain is true skipping drain")
-		} else {
+		switch mode {
+		case sriovnetworkv1.DrainModeNone:
+			log.Log.Info("nodeStateSyncHandler(): drain mode is None, skipping drain")
+		case sriovnetworkv1.DrainModeCordon:
+			log.Log.Info("nodeStateSyncHandler(): drain skipped, pause workloads")
+			if err := dn.pauseWorkloads(); err != nil {
+				return err
+			}
+		default:
 			log.Log.Info("nodeStateSyncHandler(): drain node")
-			if err := dn.drainNode(); err != nil {
+			phaseStart := time.Now()
+			if err := traceStep(ctx, "daemon.drain", func() error { return dn.drainNode(drainStart) }); err != nil {
+				if snerrors.ReasonOf(err) == snerrors.ReasonDrainAborted {
+					dn.abortDrain(latestState.Generation)
+				}
+				return snerrors.Wrap(snerrors.ErrDrainFailed, err)
+			}
+			metrics.ObserveDaemonPhase(metrics.DaemonPhaseDrain, phaseStart)
+		}
+	}
+
+	// the node is drained first, the hooks and the plugins apply the configuration to a drained node
+	if err := dn.runHooks(ctx, latestState, consts.HookPointPreApply); err != nil {
+		return err
+	}
+
+	for k, p := range dn.loadedPlugins {
+		// Skip both the general and virtual plugin apply them last
+		if k != GenericPluginName && k != VirtualPluginName {
+			err := traceStep(ctx, "plugin.apply", p.Apply, "plugin", k)
+			if err != nil {
+				log.Log.Error(err, "nodeStateSyncHandler(): plugin Apply failed", "plugin-name", k)
 				return err
 			}
 		}
 	}
 
+	// the plugins configure the PFs by their new names
+	if err := dn.syncPfNames(latestState); err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): failed to rename the PFs")
+		return err
+	}
+
 	if !reqReboot && !vars.UsingSystemdMode {
+		applyStart := time.Now()
 		// For BareMetal machines apply the generic plugin
 		selectedPlugin, ok := dn.loadedPlugins[GenericPluginName]
 		if ok {
 			// Apply generic plugin last
-			err = selectedPlugin.Apply()
+			err = traceStep(ctx, "plugin.apply", selectedPlugin.Apply, "plugin", GenericPluginName)
 			if err != nil {
 				log.Log.Error(err, "nodeStateSyncHandler(): generic plugin fail to apply")
 				return err
@@ -642,15 +946,39 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		selectedPlugin, ok = dn.loadedPlugins[VirtualPluginName]
 		if ok {
 			// Apply virtual plugin last
-			err = selectedPlugin.Apply()
+			err = traceStep(ctx, "plugin.apply", selectedPlugin.Apply, "plugin", VirtualPluginName)
 			if err != nil {
 				log.Log.Error(err, "nodeStateSyncHandler(): virtual plugin failed to apply")
 				return err
 			}
 		}
+		metrics.ObserveDaemonPhase(metrics.DaemonPhaseApply, applyStart)
+
+		if err := dn.runHooks(ctx, latestState, consts.HookPointPostApply); err != nil {
+			return err
+		}
+
+		// the reservations follow the policies, the node tooling reads the VFs of the system components from the file
+		if err := reserved.Sync(latestState.Spec.Interfaces); err != nil {
+			log.Log.Error(err, "nodeStateSyncHandler(): failed to write the reserved VFs file")
+			return err
+		}
+		// the userspace dataplanes of the node take the layout of their vfio-pci VFs from the file
+		if err := dataplane.Sync(latestState.Spec.Interfaces); err != nil {
+			log.Log.Error(err, "nodeStateSyncHandler(): failed to write the dataplane VFs file")
+			return err
+		}
+
+		// the PFs are in switchdev once applied, Open vSwitch can offload the flows of their representors
+		if err := dn.syncOvsHardwareOffload(latestState, ovsRestartPending); err != nil {
+			return err
+		}
 	}
 
 	if reqReboot {
+		if err := dn.runHooks(ctx, latestState, consts.HookPointPreReboot); err != nil {
+			return err
+		}
 		log.Log.Info("nodeStateSyncHandler(): reboot node")
 		dn.eventRecorder.SendEvent("RebootNode", "Reboot node has been initiated")
 		dn.rebootNode()
@@ -664,7 +992,7 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		return err
 	}
 	if dn.isNodeDraining() {
-		if err := dn.completeDrain(); err != nil {
+		if err := dn.completeDrain(dn.drainMode(latestState)); err != nil {
 			log.Log.Error(err, "nodeStateSyncHandler(): failed to complete draining")
 			return err
 		}
@@ -676,14 +1004,28 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 			}
 		}
 	}
+	// the aborted drain of a previous generation is no longer relevant
+	dn.reportDrain(nil)
 	log.Log.Info("nodeStateSyncHandler(): sync succeeded")
+	if err := dn.completeBootVerification(latestState.Spec.Interfaces); err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): failed to complete the boot verification")
+		return err
+	}
+	if dn.statusWriter != nil {
+		dn.statusWriter.platformDrainRequired.Store(false)
+	}
 	dn.nodeState = latestState.DeepCopy()
+	dn.syncAgain = false
 	if vars.UsingSystemdMode {
 		dn.refreshCh <- Message{
 			syncStatus:    sriovResult.SyncStatus,
 			lastSyncError: sriovResult.LastSyncError,
 		}
 	} else {
+		// the daemon starting while the API server is unreachable applies the spec again, see the fail-safe mode
+		if err := systemd.WriteLastAppliedSpec(latestState); err != nil {
+			log.Log.Error(err, "nodeStateSyncHandler(): failed to persist the applied spec")
+		}
 		dn.refreshCh <- Message{
 			syncStatus:    consts.SyncStatusSucceeded,
 			lastSyncError: "",
@@ -694,6 +1036,19 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	return nil
 }
 
+// syncFlowRules reinstalls the flow rules of the switchdev PFs, the errors are only logged as the rules are synced
+// again at the next resync. The PFs without rules are synced too, to remove the rules dropped from the policies.
+func (dn *Daemon) syncFlowRules(state *sriovnetworkv1.SriovNetworkNodeState) {
+	for _, iface := range state.Spec.Interfaces {
+		if iface.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+			continue
+		}
+		if err := dn.HostHelpers.SyncFlowRules(iface.Name, iface.FlowRules); err != nil {
+			log.Log.Error(err, "nodeStateSyncHandler(): failed to sync the flow rules", "name", iface.Name)
+		}
+	}
+}
+
 func (dn *Daemon) nodeHasAnnotation(annoKey string, value string) bool {
 	// Check if node already contains annotation
 	if anno, ok := dn.node.Annotations[annoKey]; ok && (anno == value) {
@@ -713,11 +1068,14 @@ func (dn *Daemon) isNodeDraining() bool {
 	return anno == annoDraining || anno == annoMcpPaused
 }
 
-func (dn *Daemon) completeDrain() error {
-	if !dn.disableDrain {
+func (dn *Daemon) completeDrain(mode string) error {
+	if mode != sriovnetworkv1.DrainModeNone {
 		if err := drain.RunCordonOrUncordon(dn.drainer, dn.node, false); err != nil {
 			return err
 		}
+		if mode == sriovnetworkv1.DrainModeCordon {
+			dn.eventRecorder.SendEvent("ResumeWorkloads", "Node has been uncordoned")
+		}
 	}
 
 	if dn.platformHelpers.IsOpenshiftCluster() && !dn.platformHelpers.IsHypershift() {
@@ -729,10 +1087,11 @@ func (dn *Daemon) completeDrain() error {
 		}
 	}
 
-	if err := dn.annotateNode(vars.NodeName, annoIdle); err != nil {
+	if err := dn.setNodeAnnotations(vars.NodeName, map[string]string{annoKey: annoIdle, consts.DrainStartAnnotation: ""}); err != nil {
 		log.Log.Error(err, "completeDrain(): failed to annotate node")
 		return err
 	}
+	dn.reportDrain(nil)
 	return nil
 }
 
@@ -808,16 +1167,20 @@ func (dn *Daemon) rebootNode() {
 	// However note we use `;` instead of `&&` so we keep rebooting even
 	// if kubelet failed to shutdown - that way the machine will still eventually reboot
 	// as systemd will time out the stop invocation.
-	cmd := exec.Command("systemd-run", "--unit", "sriov-network-config-daemon-reboot",
+	_, _, err = dn.HostHelpers.RunCommand("systemd-run", "--unit", "sriov-network-config-daemon-reboot",
 		"--description", "sriov-network-config-daemon reboot node", "/bin/sh", "-c", "systemctl stop kubelet.service; reboot")
-
-	if err := cmd.Run(); err != nil {
+	if err != nil {
 		log.Log.Error(err, "failed to reboot node")
 	}
 }
 
 func (dn *Daemon) annotateNode(node, value string) error {
-	log.Log.Info("annotateNode(): Annotate node", "name", node, "value", value)
+	return dn.setNodeAnnotations(node, map[string]string{annoKey: value})
+}
+
+// setNodeAnnotations patches the annotations of the node whose values differ
+func (dn *Daemon) setNodeAnnotations(node string, annotations map[string]string) error {
+	log.Log.Info("annotateNode(): Annotate node", "name", node, "annotations", annotations)
 
 	oldNode, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), vars.NodeName, metav1.GetOptions{})
 	if err != nil {
@@ -834,8 +1197,14 @@ func (dn *Daemon) annotateNode(node, value string) error {
 	if newNode.Annotations == nil {
 		newNode.Annotations = map[string]string{}
 	}
-	if newNode.Annotations[annoKey] != value {
-		newNode.Annotations[annoKey] = value
+	changed := false
+	for key, value := range annotations {
+		if newNode.Annotations[key] != value {
+			newNode.Annotations[key] = value
+			changed = true
+		}
+	}
+	if changed {
 		newData, err := json.Marshal(newNode)
 		if err != nil {
 			return err
@@ -1019,7 +1388,39 @@ func (dn *Daemon) pauseMCP() error {
 	return err
 }
 
-func (dn *Daemon) drainNode() error {
+// drainMode returns how the node is drained before being reconfigured, a drain in progress keeps the mode it was
+// started with. On single node clusters the evicted workloads can't be rescheduled anywhere, the node is only
+// cordoned instead.
+func (dn *Daemon) drainMode(state *sriovnetworkv1.SriovNetworkNodeState) string {
+	if mode := dn.node.Annotations[consts.DrainModeAnnotation]; mode != "" && dn.isNodeDraining() {
+		return mode
+	}
+	if state.Spec.DrainMode == "" && dn.node.Labels[consts.DrainSkipLabel] == "true" {
+		// the node state is not rendered yet with the drain mode of the pool
+		state = state.DeepCopy()
+		state.Spec.DrainMode = sriovnetworkv1.DrainModeCordon
+	}
+	mode := state.EffectiveDrainMode(dn.disableDrain)
+	if mode == sriovnetworkv1.DrainModeDrain && dn.singleNode.Load() {
+		return sriovnetworkv1.DrainModeCordon
+	}
+	return mode
+}
+
+// pauseWorkloads cordons the node instead of draining it, the running workloads are kept and
+// no new workload is scheduled until the configuration is applied and completeDrain uncordons the node
+func (dn *Daemon) pauseWorkloads() error {
+	dn.eventRecorder.SendEvent("PauseWorkloads", "Node has been cordoned, the drain is skipped")
+	if err := drain.RunCordonOrUncordon(dn.drainer, dn.node, true); err != nil {
+		log.Log.Error(err, "pauseWorkloads(): failed to cordon node")
+		return err
+	}
+	return nil
+}
+
+// drainNode drains the node, the drain started at start is escalated according to the drain escalation of the
+// operator config and its progress is reported in the node state
+func (dn *Daemon) drainNode(start time.Time) error {
 	log.Log.Info("drainNode(): Update prepared")
 	var err error
 
@@ -1032,14 +1433,19 @@ func (dn *Daemon) drainNode() error {
 
 	log.Log.Info("drainNode(): Start draining")
 	dn.eventRecorder.SendEvent("DrainNode", "Drain node has been initiated")
+	progress := dn.drainProgress(start)
+	dn.reportDrain(progress)
 	if err = wait.ExponentialBackoff(backoff, func() (bool, error) {
+		if err := dn.escalateDrain(progress); err != nil {
+			return false, err
+		}
 		err := drain.RunCordonOrUncordon(dn.drainer, dn.node, true)
 		if err != nil {
 			lastErr = err
 			log.Log.Error(err, "cordon failed, retrying")
 			return false, nil
 		}
-		err = drain.RunNodeDrain(dn.drainer, vars.NodeName)
+		err = dn.runNodeDrain(progress)
 		if err == nil {
 			return true, nil
 		}
@@ -1062,6 +1468,10 @@ func (dn *Daemon) drainNode() error {
 // TODO: move this to host interface
 func (dn *Daemon) tryCreateSwitchdevUdevRule() error {
 	log.Log.V(2).Info("tryCreateSwitchdevUdevRule()")
+	if dn.pauseNodeConfiguration.Load() {
+		log.Log.V(2).Info("tryCreateSwitchdevUdevRule(): node configuration paused, skipping")
+		return nil
+	}
 	nodeState, nodeStateErr := dn.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(
 		context.Background(),
 		vars.NodeName,
@@ -1108,16 +1518,13 @@ func (dn *Daemon) tryCreateSwitchdevUdevRule() error {
 		return err
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("/bin/bash", path.Join(vars.FilesystemRoot, udevScriptsPath))
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	stdout, _, err := dn.HostHelpers.RunCommand("/bin/bash", path.Join(vars.FilesystemRoot, udevScriptsPath))
+	if err != nil {
 		return err
 	}
-	log.Log.V(2).Info("tryCreateSwitchdevUdevRule(): stdout", "output", cmd.Stdout)
+	log.Log.V(2).Info("tryCreateSwitchdevUdevRule(): stdout", "output", stdout)
 
-	i, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	i, err := strconv.Atoi(strings.TrimSpace(stdout))
 	if err == nil {
 		if i == 0 {
 			log.Log.V(2).Info("tryCreateSwitchdevUdevRule(): switchdev udev rules loaded")
@@ -1143,3 +1550,12 @@ func (dn *Daemon) prepareNMUdevRule() error {
 
 	return dn.HostHelpers.PrepareNMUdevRule(supportedVfIds)
 }
+
+// traceStep runs a step of the sync in a span, child of the span of the context
+func traceStep(ctx context.Context, name string, step func() error, attrs ...string) error {
+	_, span := tracing.Start(ctx, name, attrs...)
+	err := step()
+	span.RecordError(err)
+	span.End()
+	return err
+}
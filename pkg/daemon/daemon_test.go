@@ -3,27 +3,42 @@ package daemon
 import (
 	"context"
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	mock_platforms "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
 	fakesnclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned/fake"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
 	mock_helper "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/lldp"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/pfrename"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms/openshift"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/fake"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/systemd"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -127,6 +142,7 @@ var _ = Describe("Config Daemon", func() {
 		vendorHelper.EXPECT().TryEnableVhostNet().AnyTimes()
 		vendorHelper.EXPECT().TryEnableTun().AnyTimes()
 		vendorHelper.EXPECT().PrepareNMUdevRule([]string{"0x1014", "0x154c"}).Return(nil).AnyTimes()
+		vendorHelper.EXPECT().RunCommand("/bin/bash", gomock.Any()).Return("", "", nil).AnyTimes()
 
 		sut = New(
 			client,
@@ -138,6 +154,8 @@ var _ = Describe("Config Daemon", func() {
 			syncCh,
 			refreshCh,
 			er,
+			lldp.NewListener(),
+			NewNodeStateStatusWriter(client, nil, er, vendorHelper, platformHelper, nil),
 			nil,
 		)
 
@@ -218,6 +236,35 @@ var _ = Describe("Config Daemon", func() {
 
 		})
 
+		It("persists the applied spec for the fail-safe mode", func() {
+			_, err := sut.kubeClient.CoreV1().Nodes().
+				Create(context.Background(), &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				}, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-node",
+					Generation: 123,
+				},
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{DpConfigVersion: "1"},
+			}
+			Expect(createSriovNetworkNodeState(sut.client, nodeState)).To(BeNil())
+
+			var msg Message
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal("InProgress"))
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal("Succeeded"))
+
+			lastApplied, err := systemd.ReadLastAppliedSpec()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastApplied.PlatformType).To(Equal(consts.Baremetal))
+			Expect(lastApplied.Spec.DpConfigVersion).To(BeEmpty())
+			Expect(lastApplied.SupportedNicIds).To(Equal(sriovnetworkv1.NicIDMap))
+		})
+
 		It("ignore non latest SriovNetworkNodeState generations", func() {
 
 			_, err := sut.kubeClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
@@ -256,6 +303,224 @@ var _ = Describe("Config Daemon", func() {
 
 			Expect(sut.nodeState.GetGeneration()).To(BeNumerically("==", 777))
 		})
+
+		It("refuse the node states rendered with an unsupported spec version", func() {
+			_, err := sut.kubeClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node",
+				},
+			}, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-node",
+					Generation: 123,
+				},
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{SpecVersion: sriovnetworkv1.NodeStateSpecVersion + 1},
+			}
+			Expect(
+				createSriovNetworkNodeState(sut.client, nodeState)).
+				To(BeNil())
+
+			var msg Message
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal(consts.SyncStatusFailed))
+			Expect(msg.lastSyncErrorReason).To(Equal(snerrors.ReasonIncompatibleSpecVersion))
+			Expect(sut.nodeState.GetGeneration()).To(BeZero())
+		})
+
+		It("apply the generation again until the remediation of the host drift succeeds", func() {
+			sut.syncAgain = true
+
+			_, err := sut.kubeClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node",
+				},
+			}, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-node",
+					Generation: 123,
+				},
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{SpecVersion: sriovnetworkv1.NodeStateSpecVersion + 1},
+			}
+			Expect(createSriovNetworkNodeState(sut.client, nodeState)).To(BeNil())
+
+			var msg Message
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal(consts.SyncStatusFailed))
+			// the failed sync leaves the remediation pending
+			Expect(sut.syncAgain).To(BeTrue())
+
+			nodeState.Generation = 124
+			nodeState.Spec.SpecVersion = sriovnetworkv1.NodeStateSpecVersion
+			Expect(updateSriovNetworkNodeState(sut.client, nodeState)).To(BeNil())
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal("InProgress"))
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal("Succeeded"))
+			Expect(sut.nodeState.GetGeneration()).To(BeNumerically("==", 124))
+			Expect(sut.syncAgain).To(BeFalse())
+		})
+
+		It("hold the new generations while the node configuration is paused", func() {
+			sut.pauseNodeConfiguration.Store(true)
+
+			_, err := sut.kubeClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node",
+				},
+			}, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-node",
+					Generation: 123,
+				},
+			}
+			Expect(
+				createSriovNetworkNodeState(sut.client, nodeState)).
+				To(BeNil())
+
+			var msg Message
+			Eventually(refreshCh, "10s").Should(Receive(&msg))
+			Expect(msg.syncStatus).To(Equal(consts.SyncStatusPaused))
+			Expect(sut.nodeState.GetGeneration()).To(BeZero())
+			// nor are the udev rules of the host written
+			Expect(filepath.Join(vars.FilesystemRoot, "host/etc/udev/rules.d/20-switchdev.rules")).ToNot(BeAnExistingFile())
+		})
+	})
+})
+
+var _ = Describe("Config Daemon drain mode", func() {
+	var dn *Daemon
+	var state *sriovnetworkv1.SriovNetworkNodeState
+
+	BeforeEach(func() {
+		dn = &Daemon{node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}}
+		state = &sriovnetworkv1.SriovNetworkNodeState{}
+	})
+
+	It("drains the node by default", func() {
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeDrain))
+		dn.disableDrain = true
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeNone))
+	})
+
+	It("only cordons the node of a single node cluster", func() {
+		dn.singleNode.Store(true)
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeCordon))
+		state.Spec.DrainMode = sriovnetworkv1.DrainModeNone
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeNone))
+	})
+
+	It("detects the single node cluster of the lite profile", func() {
+		node := func(name string) *corev1.Node { return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}} }
+		dn.kubeClient = fakek8s.NewSimpleClientset(node("test-node"))
+		dn.detectSingleNode()
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeCordon))
+
+		dn.kubeClient = fakek8s.NewSimpleClientset(node("test-node"), node("other-node"))
+		dn.detectSingleNode()
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeDrain))
+	})
+
+	It("cordons the nodes labeled by a pool skipping the drain", func() {
+		dn.node.Labels = map[string]string{consts.DrainSkipLabel: "true"}
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeCordon))
+	})
+
+	It("keeps the mode of the drain in progress", func() {
+		dn.node.Annotations = map[string]string{annoKey: annoDraining, consts.DrainModeAnnotation: sriovnetworkv1.DrainModeCordon}
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeCordon))
+
+		dn.node.Annotations[annoKey] = annoIdle
+		Expect(dn.drainMode(state)).To(Equal(sriovnetworkv1.DrainModeDrain))
+	})
+})
+
+var _ = Describe("Config Daemon drain progress", func() {
+	var dn *Daemon
+
+	BeforeEach(func() {
+		minAvailable := intstr.FromInt(1)
+		kubeClient := fakek8s.NewSimpleClientset(&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		})
+		client := fakesnclientset.NewSimpleClientset()
+		dn = &Daemon{
+			node:          &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+			kubeClient:    kubeClient,
+			eventRecorder: NewEventRecorder(client, kubeClient),
+		}
+	})
+
+	It("reports why the pods are not evicted", func() {
+		deleted := metav1.Now()
+		pods := []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "ns", Labels: map[string]string{"app": "db"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "ns", DeletionTimestamp: &deleted}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "job-0", Namespace: "ns"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cache-0", Namespace: "ns"}},
+		}
+		drainErr := utilerrors.NewAggregate([]error{fmt.Errorf(`error when evicting pods/"job-0" -n "ns": timeout`)})
+
+		failures := dn.podEvictionFailures(pods, drainErr)
+		Expect(failures).To(HaveLen(4))
+		Expect(failures[0].Reason).To(Equal("the PodDisruptionBudget db allows no disruption"))
+		Expect(failures[1].Reason).To(HavePrefix("terminating since"))
+		Expect(failures[2].Reason).To(Equal(`error when evicting pods/"job-0" -n "ns": timeout`))
+		Expect(failures[3].Reason).To(Equal("not evicted yet"))
+	})
+
+	It("reports a bounded number of pods", func() {
+		pods := []corev1.Pod{}
+		for i := 0; i < 2*maxReportedDrainFailures; i++ {
+			pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "ns"}})
+		}
+		Expect(dn.podEvictionFailures(pods, fmt.Errorf("timeout"))).To(HaveLen(maxReportedDrainFailures))
+	})
+
+	It("escalates the drains not completed in time", func() {
+		progress := dn.drainProgress(time.Now().Add(-45 * time.Minute))
+		Expect(dn.escalateDrain(progress)).To(Succeed())
+		Expect(progress.Phase).To(Equal(sriovnetworkv1.DrainPhaseDraining))
+		Expect(progress.Deadline).To(BeNil())
+
+		dn.drainEscalation = &sriovnetworkv1.DrainEscalationConfig{WarnAfterMinutes: 10, ForceAfterMinutes: 30, AbortAfterMinutes: 60}
+		Expect(dn.escalateDrain(progress)).To(Succeed())
+		Expect(progress.Phase).To(Equal(sriovnetworkv1.DrainPhaseForcing))
+		Expect(progress.Deadline).ToNot(BeNil())
+		Expect(dn.drainStatus).To(Equal(progress))
+
+		progress.StartTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		err := dn.escalateDrain(progress)
+		Expect(snerrors.ReasonOf(err)).To(Equal(snerrors.ReasonDrainAborted))
+		Expect(progress.Phase).To(Equal(sriovnetworkv1.DrainPhaseAborted))
+	})
+
+	It("continues the progress of the same drain", func() {
+		start := time.Now().UTC().Truncate(time.Second)
+		dn.node.Annotations = map[string]string{consts.DrainStartAnnotation: start.Format(time.RFC3339)}
+		Expect(dn.drainStartTime()).To(BeTemporally("==", start))
+
+		progress := dn.drainProgress(start)
+		progress.PodsRemaining = 3
+		dn.reportDrain(progress)
+		Expect(dn.drainProgress(start).PodsRemaining).To(Equal(3))
+		Expect(dn.drainProgress(start.Add(time.Minute)).PodsRemaining).To(Equal(0))
+
+		dn.reportDrain(nil)
+		Expect(dn.drainStatus).To(BeNil())
 	})
 })
 
@@ -272,3 +537,530 @@ func updateSriovNetworkNodeState(c snclientset.Interface, nodeState *sriovnetwor
 		Update(context.Background(), nodeState, metav1.UpdateOptions{})
 	return err
 }
+
+var _ = Describe("Config Daemon OVS hardware offload", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+	var state *sriovnetworkv1.SriovNetworkNodeState
+
+	BeforeEach(func() {
+		vars.NodeName = "test-node"
+		origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+		DeferCleanup(func() { vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot })
+		vars.InChroot = false
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"sys/class/net/ens1f0", "sys/class/net/ens1f0_0", "sys/class/net/eth5"},
+			Files: map[string][]byte{
+				"sys/class/net/ens1f0/phys_switch_id":   []byte("aabbcc\n"),
+				"sys/class/net/ens1f0/phys_port_name":   []byte("p0\n"),
+				"sys/class/net/ens1f0_0/phys_switch_id": []byte("aabbcc\n"),
+				"sys/class/net/ens1f0_0/phys_port_name": []byte("pf0vf0\n"),
+				"sys/class/net/eth5/phys_switch_id":     []byte("aabbcc\n"),
+				"sys/class/net/eth5/phys_port_name":     []byte("pf0vf1\n"),
+			},
+		})
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node",
+			Labels: map[string]string{consts.OvsHwOffloadLabel: "true"}}}
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		dn = &Daemon{node: node, kubeClient: fakek8s.NewSimpleClientset(node), HostHelpers: hostHelpers}
+		state = &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{
+				{Name: "ens1f0", PciAddress: "0000:3b:00.0", NumVfs: 2, EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+			},
+		}}
+	})
+
+	annotation := func(key string) string {
+		node, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), "test-node", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return node.Annotations[key]
+	}
+
+	It("enables the hardware offload and restarts ovs-vswitchd once pending", func() {
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("\"false\"\n", "", nil)
+		pending, err := dn.ovsHardwareOffloadPending()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pending).To(BeTrue())
+
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", utils.GetChrootExtension()+" ovs-vsctl --timeout=10 set Open_vSwitch . other_config:hw-offload=true").Return("", "", nil)
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", utils.GetChrootExtension()+" systemctl try-restart ovs-vswitchd.service").Return("", "", nil)
+		Expect(dn.syncOvsHardwareOffload(state, pending)).To(Succeed())
+		Expect(annotation(consts.OvsHwOffloadStateAnnotation)).To(Equal(consts.OvsHwOffloadStateEnabled))
+	})
+
+	It("doesn't restart ovs-vswitchd when the hardware offload is enabled", func() {
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("\"true\"\n", "", nil)
+		pending, err := dn.ovsHardwareOffloadPending()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pending).To(BeFalse())
+		Expect(dn.syncOvsHardwareOffload(state, pending)).To(Succeed())
+		Expect(annotation(consts.OvsHwOffloadStateAnnotation)).To(Equal(consts.OvsHwOffloadStateEnabled))
+	})
+
+	It("reports the failures", func() {
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "database connection failed", fmt.Errorf("exit status 1"))
+		_, err := dn.ovsHardwareOffloadPending()
+		Expect(err).To(HaveOccurred())
+		Expect(annotation(consts.OvsHwOffloadStateAnnotation)).To(Equal(consts.OvsHwOffloadStateFailed))
+
+		hostHelpers.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "database connection failed", fmt.Errorf("exit status 1"))
+		Expect(dn.syncOvsHardwareOffload(state, true)).ToNot(Succeed())
+		Expect(annotation(consts.OvsHwOffloadStateAnnotation)).To(Equal(consts.OvsHwOffloadStateFailed))
+	})
+
+	It("reports the representors of the switchdev PFs and their names", func() {
+		Expect(representorsState(state)).To(Equal(consts.OvsHwOffloadRepresentorsCreated))
+		Expect(os.Rename(filepath.Join(vars.FilesystemRoot, "sys/class/net/eth5"),
+			filepath.Join(vars.FilesystemRoot, "sys/class/net/ens1f0_1"))).To(Succeed())
+		Expect(representorsState(state)).To(Equal(consts.OvsHwOffloadRepresentorsNamed))
+		state.Spec.Interfaces[0].NumVfs = 4
+		Expect(representorsState(state)).To(Equal(consts.OvsHwOffloadRepresentorsPending))
+
+		Expect(dn.syncOvsHardwareOffload(state, false)).To(Succeed())
+		Expect(annotation(consts.OvsHwOffloadRepresentorsAnnotation)).To(Equal(consts.OvsHwOffloadRepresentorsPending))
+	})
+
+	It("ignores the nodes of the pools without hardware offload", func() {
+		dn.node.Labels = nil
+		pending, err := dn.ovsHardwareOffloadPending()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pending).To(BeFalse())
+		Expect(dn.syncOvsHardwareOffload(state, false)).To(Succeed())
+		Expect(annotation(consts.OvsHwOffloadStateAnnotation)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Config Daemon PF rename", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+
+	BeforeEach(func() {
+		origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+		DeferCleanup(func() { vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot })
+		vars.InChroot = false
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"host/etc/sriov-operator"}})
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		dn = &Daemon{HostHelpers: hostHelpers}
+	})
+
+	It("renames the PFs and restores their original names", func() {
+		state := &sriovnetworkv1.SriovNetworkNodeState{
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 4, RenameTo: "sriov3b00f0"}},
+			},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+				Interfaces: sriovnetworkv1.InterfaceExts{{PciAddress: "0000:3b:00.0", Name: "ens1f0"}},
+			},
+		}
+		// the rename is pending until the PF is renamed, the node is drained first
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("ens1f0")
+		Expect(dn.pfNamesPending(state)).To(BeTrue())
+
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("ens1f0")
+		hostHelpers.EXPECT().RenameNetDev("ens1f0", "sriov3b00f0").Return(nil)
+		hostHelpers.EXPECT().GetPhysPortName("sriov3b00f0").Return("", fmt.Errorf("not supported"))
+		hostHelpers.EXPECT().AddPfRenameUdevRule("0000:3b:00.0", "", "sriov3b00f0").Return(nil)
+		Expect(dn.syncPfNames(state)).To(Succeed())
+		Expect(state.Spec.Interfaces[0].Name).To(Equal("sriov3b00f0"))
+		Expect(state.Status.Interfaces[0].Name).To(Equal("sriov3b00f0"))
+		Expect(pfrename.OriginalNames()).To(Equal(map[string]string{"0000:3b:00.0": "ens1f0"}))
+
+		// the renamed PF keeps its original name in the rename map, the rule of a split port matches the port
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("sriov3b00f0")
+		Expect(dn.pfNamesPending(state)).To(BeFalse())
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("sriov3b00f0")
+		hostHelpers.EXPECT().GetPhysPortName("sriov3b00f0").Return("p0s1", nil)
+		hostHelpers.EXPECT().AddPfRenameUdevRule("0000:3b:00.0", "p0s1", "sriov3b00f0").Return(nil)
+		Expect(dn.syncPfNames(state)).To(Succeed())
+		Expect(pfrename.OriginalNames()).To(Equal(map[string]string{"0000:3b:00.0": "ens1f0"}))
+
+		state.Spec.Interfaces[0].RenameTo = ""
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("sriov3b00f0")
+		Expect(dn.pfNamesPending(state)).To(BeTrue())
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("sriov3b00f0")
+		hostHelpers.EXPECT().RenameNetDev("sriov3b00f0", "ens1f0").Return(nil)
+		hostHelpers.EXPECT().RemovePfRenameUdevRule("0000:3b:00.0").Return(nil)
+		Expect(dn.syncPfNames(state)).To(Succeed())
+		Expect(state.Spec.Interfaces[0].Name).To(Equal("ens1f0"))
+		Expect(state.Status.Interfaces[0].Name).To(Equal("ens1f0"))
+		Expect(pfrename.OriginalNames()).To(BeEmpty())
+	})
+
+	It("skips the PFs without netdev", func() {
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:3b:00.0", NumVfs: 4, RenameTo: "sriov3b00f0"}},
+		}}
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("").Times(2)
+		Expect(dn.pfNamesPending(state)).To(BeFalse())
+		Expect(dn.syncPfNames(state)).To(Succeed())
+		Expect(pfrename.OriginalNames()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Config Daemon flow rules", func() {
+	It("removes the rules of the switchdev PFs without rules", func() {
+		hostHelpers := mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		dn := &Daemon{HostHelpers: hostHelpers}
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 4, EswitchMode: sriovnetworkv1.ESwithModeSwitchDev},
+				{PciAddress: "0000:3b:00.1", Name: "ens1f1", NumVfs: 4},
+			},
+		}}
+		hostHelpers.EXPECT().SyncFlowRules("ens1f0", nil).Return(nil)
+		dn.syncFlowRules(state)
+	})
+})
+
+var _ = Describe("Config Daemon boot verification", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+
+	applied := &sriovnetworkv1.Interface{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 4}
+	spec := sriovnetworkv1.Interfaces{*applied}
+	discovered := func(numVfs int) []sriovnetworkv1.InterfaceExt {
+		return []sriovnetworkv1.InterfaceExt{{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: numVfs, TotalVfs: 8}}
+	}
+
+	BeforeEach(func() {
+		origRoot, origInChroot, origNodeName := vars.FilesystemRoot, vars.InChroot, vars.NodeName
+		DeferCleanup(func() { vars.FilesystemRoot, vars.InChroot, vars.NodeName = origRoot, origInChroot, origNodeName })
+		vars.InChroot = false
+		vars.NodeName = "test-node"
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:  []string{"host/etc/sriov-operator", "proc/sys/kernel/random"},
+			Files: map[string][]byte{"proc/sys/kernel/random/boot_id": []byte("boot-2\n")},
+		})
+		kubeClient := fakek8s.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		hostHelpers.EXPECT().LoadPfsStatus("0000:3b:00.0").Return(applied, true, nil).AnyTimes()
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", Namespace: vars.Namespace},
+			Spec:       sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: spec},
+		})
+		dn = &Daemon{client: client, kubeClient: kubeClient, HostHelpers: hostHelpers,
+			eventRecorder: NewEventRecorder(client, kubeClient)}
+	})
+
+	tainted := func() bool {
+		node, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), "test-node", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == consts.VfsNotReadyTaint && taint.Effect == corev1.TaintEffectNoSchedule {
+				return true
+			}
+		}
+		return false
+	}
+
+	recordedBoot := func() string {
+		data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, "host", consts.BootIDFile))
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+
+	It("taints the node until the VF layout of the new boot is applied again", func() {
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(0), nil)
+		Expect(dn.startBootVerification()).To(Succeed())
+		Expect(tainted()).To(BeTrue())
+		Expect(recordedBoot()).To(BeEmpty())
+
+		// the VFs are still missing after a failed sync
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(0), nil)
+		Expect(dn.completeBootVerification(spec)).ToNot(Succeed())
+		Expect(tainted()).To(BeTrue())
+
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(4), nil)
+		Expect(dn.completeBootVerification(spec)).To(Succeed())
+		Expect(tainted()).To(BeFalse())
+		Expect(recordedBoot()).To(Equal("boot-2\n"))
+
+		// the next syncs don't verify the layout again
+		Expect(dn.completeBootVerification(spec)).To(Succeed())
+	})
+
+	It("doesn't taint the node when the VF layout matches", func() {
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(4), nil)
+		Expect(dn.startBootVerification()).To(Succeed())
+		Expect(tainted()).To(BeFalse())
+		Expect(recordedBoot()).To(Equal("boot-2\n"))
+	})
+
+	It("ignores the PFs removed from the spec", func() {
+		// the applied spec of the PF is kept on the host once it is removed from the spec
+		dn.bootID, dn.bootVerificationPending = "boot-2", true
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(0), nil)
+		Expect(dn.completeBootVerification(nil)).To(Succeed())
+		Expect(dn.bootVerificationPending).To(BeFalse())
+		Expect(recordedBoot()).To(Equal("boot-2\n"))
+	})
+
+	It("doesn't verify the VF layout again on a restart of the daemon", func() {
+		Expect(os.WriteFile(filepath.Join(vars.FilesystemRoot, "host", consts.BootIDFile), []byte("boot-2\n"), 0644)).To(Succeed())
+		Expect(dn.startBootVerification()).To(Succeed())
+		Expect(tainted()).To(BeFalse())
+	})
+})
+
+var _ = Describe("Config Daemon host drift", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+
+	applied := &sriovnetworkv1.Interface{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 4}
+	discovered := func(numVfs int) []sriovnetworkv1.InterfaceExt {
+		return []sriovnetworkv1.InterfaceExt{{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: numVfs, TotalVfs: 8}}
+	}
+
+	BeforeEach(func() {
+		origNodeName, origPlatform, origSystemd := vars.NodeName, vars.PlatformType, vars.UsingSystemdMode
+		DeferCleanup(func() {
+			vars.NodeName, vars.PlatformType, vars.UsingSystemdMode = origNodeName, origPlatform, origSystemd
+		})
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.Baremetal
+		vars.UsingSystemdMode = false
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", Namespace: vars.Namespace, Generation: 1, ResourceVersion: "1"},
+			Spec:       sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{*applied}},
+		}
+		kubeClient := fakek8s.NewSimpleClientset()
+		client := fakesnclientset.NewSimpleClientset(nodeState)
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		hostHelpers.EXPECT().LoadPfsStatus("0000:3b:00.0").Return(applied, true, nil).AnyTimes()
+		er := NewEventRecorder(client, kubeClient)
+		dn = &Daemon{client: client, kubeClient: kubeClient, HostHelpers: hostHelpers, eventRecorder: er,
+			statusWriter: NewNodeStateStatusWriter(client, nil, er, hostHelpers, nil, nil),
+			node:         &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+			nodeState:    nodeState.DeepCopy(),
+			workqueue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		}
+		DeferCleanup(dn.workqueue.ShutDown)
+	})
+
+	condition := func() *metav1.Condition {
+		nodeState, err := dn.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), "test-node", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return meta.FindStatusCondition(nodeState.Status.Conditions, sriovnetworkv1.ConditionHostDrift)
+	}
+
+	It("reports the drift of the PFs in the node state", func() {
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(2), nil)
+		dn.auditHostDrift()
+		Expect(condition()).ToNot(BeNil())
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition().Message).To(ContainSubstring("ens1f0(0000:3b:00.0) numVfs 2, applied 4"))
+		// the drift is only reported
+		Expect(dn.syncAgain).To(BeFalse())
+		Expect(dn.workqueue.Len()).To(Equal(0))
+
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(4), nil)
+		dn.auditHostDrift()
+		Expect(condition().Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("syncs the node state again when the remediation is enabled", func() {
+		dn.remediateHostDrift = true
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(0), nil)
+		dn.auditHostDrift()
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(dn.syncAgain).To(BeTrue())
+		Expect(dn.workqueue.Len()).To(Equal(1))
+		item, _ := dn.workqueue.Get()
+		Expect(item).To(Equal(int64(1)))
+	})
+
+	It("programs the unicast MACs of the VFs again", func() {
+		dn.nodeState.Spec.Interfaces[0].VfGroups = []sriovnetworkv1.VfGroup{{VfRange: "0-3", ResourceName: "bond",
+			UnicastMacs: []sriovnetworkv1.VfUnicastMacs{{Vf: 0, Macs: []string{"02:00:00:00:00:01"}}}}}
+		hostHelpers.EXPECT().SyncUnicastMacs(&dn.nodeState.Spec.Interfaces[0]).Return(nil)
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(4), nil)
+		dn.auditHostDrift()
+	})
+
+	It("doesn't audit the host while the configuration is paused", func() {
+		dn.pauseNodeConfiguration.Store(true)
+		dn.auditHostDrift()
+		Expect(condition()).To(BeNil())
+	})
+})
+
+var _ = Describe("Config Daemon unbound VFs", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+
+	spec := sriovnetworkv1.Interface{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 2, VfGroups: []sriovnetworkv1.VfGroup{
+		{ResourceName: "netdevice", DeviceType: consts.DeviceTypeNetDevice, VfRange: "0-0"},
+		{ResourceName: "dpdk", DeviceType: consts.DeviceTypeVfioPci, VfRange: "1-1"},
+	}}
+	discovered := func(driver string) []sriovnetworkv1.InterfaceExt {
+		return []sriovnetworkv1.InterfaceExt{{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 2, TotalVfs: 8,
+			VFs: []sriovnetworkv1.VirtualFunction{
+				{PciAddress: "0000:3b:02.0", VfID: 0, Driver: "iavf"},
+				{PciAddress: "0000:3b:02.1", VfID: 1, Driver: driver, BindError: "probe with driver vfio-pci failed with error -22"},
+			}}}
+	}
+
+	BeforeEach(func() {
+		origNodeName, origPlatform, origSystemd := vars.NodeName, vars.PlatformType, vars.UsingSystemdMode
+		DeferCleanup(func() {
+			vars.NodeName, vars.PlatformType, vars.UsingSystemdMode = origNodeName, origPlatform, origSystemd
+		})
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.Baremetal
+		vars.UsingSystemdMode = false
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", Namespace: vars.Namespace, Generation: 1, ResourceVersion: "1"},
+			Spec:       sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{spec}},
+		}
+		kubeClient := fakek8s.NewSimpleClientset()
+		client := fakesnclientset.NewSimpleClientset(nodeState)
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		er := NewEventRecorder(client, kubeClient)
+		dn = &Daemon{client: client, kubeClient: kubeClient, HostHelpers: hostHelpers, eventRecorder: er,
+			statusWriter: NewNodeStateStatusWriter(client, nil, er, hostHelpers, nil, nil),
+			node:         &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+			nodeState:    nodeState.DeepCopy(),
+			workqueue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		}
+		DeferCleanup(dn.workqueue.ShutDown)
+	})
+
+	condition := func() *metav1.Condition {
+		nodeState, err := dn.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), "test-node", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return meta.FindStatusCondition(nodeState.Status.Conditions, sriovnetworkv1.ConditionVfsUnbound)
+	}
+
+	It("binds the unbound VF again with a backoff", func() {
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(""), nil).Times(2)
+		hostHelpers.EXPECT().BindDpdkDriver("0000:3b:02.1", consts.DeviceTypeVfioPci).Return(nil).Times(1)
+		dn.retryUnboundVfs()
+		// the second attempt waits for the backoff
+		dn.retryUnboundVfs()
+		Expect(dn.bindRetries).To(HaveKey("0000:3b:02.1"))
+		Expect(dn.bindRetries["0000:3b:02.1"].attempts).To(Equal(1))
+		Expect(condition().Status).To(Equal(metav1.ConditionFalse))
+
+		// the VF bound again is forgotten
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered("vfio-pci"), nil)
+		dn.retryUnboundVfs()
+		Expect(dn.bindRetries).To(BeEmpty())
+	})
+
+	It("reports the VF still unbound after the attempts to bind it", func() {
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return(discovered(""), nil).Times(bindRetryEscalation + 1)
+		hostHelpers.EXPECT().BindDpdkDriver("0000:3b:02.1", consts.DeviceTypeVfioPci).Return(nil).Times(bindRetryEscalation)
+		for i := 0; i <= bindRetryEscalation; i++ {
+			dn.retryUnboundVfs()
+			dn.bindRetries["0000:3b:02.1"].next = time.Time{}
+		}
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition().Message).To(ContainSubstring(
+			"ens1f0 VF 1(0000:3b:02.1): probe with driver vfio-pci failed with error -22"))
+	})
+
+	It("increases the delay between the attempts up to its maximum", func() {
+		Expect(bindRetryDelay(1)).To(Equal(bindRetryBaseDelay))
+		Expect(bindRetryDelay(2)).To(Equal(2 * bindRetryBaseDelay))
+		Expect(bindRetryDelay(10)).To(Equal(bindRetryMaxDelay))
+	})
+})
+
+var _ = Describe("Config Daemon node problems", func() {
+	var dn *Daemon
+	var hostHelpers *mock_helper.MockHostHelpersInterface
+
+	BeforeEach(func() {
+		origNodeName, origPlatform := vars.NodeName, vars.PlatformType
+		DeferCleanup(func() {
+			vars.NodeName, vars.PlatformType = origNodeName, origPlatform
+		})
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.Baremetal
+		nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", Namespace: vars.Namespace, Generation: 1},
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 4}}},
+		}
+		kubeClient := fakek8s.NewSimpleClientset(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+		})
+		client := fakesnclientset.NewSimpleClientset(nodeState)
+		hostHelpers = mock_helper.NewMockHostHelpersInterface(gomock.NewController(GinkgoT()))
+		hostHelpers.EXPECT().TryGetInterfaceName("0000:3b:00.0").Return("ens1f0").AnyTimes()
+		dn = &Daemon{client: client, kubeClient: kubeClient, HostHelpers: hostHelpers,
+			eventRecorder: NewEventRecorder(client, kubeClient), nodeState: nodeState, reportProblems: true}
+	})
+
+	condition := func(conditionType string) *corev1.NodeCondition {
+		node, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), "test-node", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		for i := range node.Status.Conditions {
+			if string(node.Status.Conditions[i].Type) == conditionType {
+				return &node.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	It("reports the PFs down and the firmware faults", func() {
+		hostHelpers.EXPECT().GetNetDevLinkState("ens1f0").Return(consts.LinkStateDown)
+		hostHelpers.EXPECT().GetFirmwareFaults("0000:3b:00.0").Return([]string{"fw_fatal (1 errors)"}, nil)
+		dn.reportNodeProblems()
+		Expect(condition(consts.PfDownConditionType).Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition(consts.PfDownConditionType).Message).To(ContainSubstring("ens1f0(0000:3b:00.0)"))
+		Expect(condition(consts.FirmwareFaultConditionType).Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition(consts.FirmwareFaultConditionType).Reason).To(Equal(consts.FirmwareFaultReason))
+		Expect(condition(consts.VfCreationFailureConditionType).Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition(string(corev1.NodeReady))).ToNot(BeNil())
+
+		hostHelpers.EXPECT().GetNetDevLinkState("ens1f0").Return(consts.LinkStateUp)
+		hostHelpers.EXPECT().GetFirmwareFaults("0000:3b:00.0").Return([]string{}, nil)
+		dn.reportNodeProblems()
+		Expect(condition(consts.PfDownConditionType).Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition(consts.PfDownConditionType).Reason).To(Equal(consts.PfUpReason))
+		Expect(condition(consts.FirmwareFaultConditionType).Status).To(Equal(corev1.ConditionFalse))
+	})
+
+	It("reports the repeated failures to configure the VFs", func() {
+		hostHelpers.EXPECT().GetNetDevLinkState("ens1f0").Return(consts.LinkStateUp).AnyTimes()
+		hostHelpers.EXPECT().GetFirmwareFaults("0000:3b:00.0").Return(nil, fmt.Errorf("not supported")).AnyTimes()
+		for i := 0; i < vfCreationFailureThreshold-1; i++ {
+			dn.recordSyncResult(fmt.Errorf("failed to set the number of VFs"))
+		}
+		// the failures of the drain are not counted
+		dn.recordSyncResult(snerrors.Wrap(snerrors.ErrDrainFailed, fmt.Errorf("eviction refused")))
+		dn.reportNodeProblems()
+		Expect(condition(consts.VfCreationFailureConditionType).Status).To(Equal(corev1.ConditionFalse))
+
+		dn.recordSyncResult(fmt.Errorf("failed to set the number of VFs"))
+		dn.reportNodeProblems()
+		Expect(condition(consts.VfCreationFailureConditionType).Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition(consts.VfCreationFailureConditionType).Message).To(
+			Equal("the configuration of the VFs failed 3 times: failed to set the number of VFs"))
+
+		dn.recordSyncResult(nil)
+		dn.reportNodeProblems()
+		Expect(condition(consts.VfCreationFailureConditionType).Status).To(Equal(corev1.ConditionFalse))
+	})
+
+	It("removes the conditions when the reporting is disabled", func() {
+		hostHelpers.EXPECT().GetNetDevLinkState("ens1f0").Return(consts.LinkStateDown)
+		hostHelpers.EXPECT().GetFirmwareFaults("0000:3b:00.0").Return([]string{}, nil)
+		dn.reportNodeProblems()
+		Expect(condition(consts.PfDownConditionType)).ToNot(BeNil())
+
+		dn.reportProblems = false
+		dn.reportNodeProblems()
+		Expect(condition(consts.PfDownConditionType)).To(BeNil())
+		Expect(condition(consts.FirmwareFaultConditionType)).To(BeNil())
+		Expect(condition(string(corev1.NodeReady))).ToNot(BeNil())
+		// the node is left alone afterwards
+		Expect(dn.kubeClient.CoreV1().Nodes().Delete(context.Background(), "test-node", metav1.DeleteOptions{})).To(Succeed())
+		dn.reportNodeProblems()
+	})
+})
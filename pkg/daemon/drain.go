@@ -0,0 +1,183 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// maxReportedDrainFailures bounds the pods failing eviction reported in the node state
+const maxReportedDrainFailures = 10
+
+// drainStartTime returns the start of the drain in progress, persisted in the node annotations so that the
+// escalation of the drain accounts for its previous attempts
+func (dn *Daemon) drainStartTime() time.Time {
+	if start, err := time.Parse(time.RFC3339, dn.node.Annotations[consts.DrainStartAnnotation]); err == nil {
+		return start
+	}
+	return time.Now()
+}
+
+// drainProgress returns the progress of the drain started at start, continuing the progress reported by the
+// previous attempts of the same drain
+func (dn *Daemon) drainProgress(start time.Time) *sriovnetworkv1.DrainStatus {
+	if dn.drainStatus != nil && dn.drainStatus.StartTime.Time.Equal(start) {
+		return dn.drainStatus.DeepCopy()
+	}
+	return &sriovnetworkv1.DrainStatus{
+		Phase:     sriovnetworkv1.DrainPhaseDraining,
+		StartTime: metav1.NewTime(start),
+		Deadline:  dn.drainEscalation.Deadline(start),
+	}
+}
+
+// reportDrain reports the progress of the drain in the node state, a nil progress clears it
+func (dn *Daemon) reportDrain(progress *sriovnetworkv1.DrainStatus) {
+	if progress == nil && dn.drainStatus == nil {
+		return
+	}
+	dn.drainStatus = progress.DeepCopy()
+	if dn.statusWriter == nil {
+		return
+	}
+	if err := dn.statusWriter.SetDrainStatus(progress); err != nil {
+		log.Log.Error(err, "reportDrain(): failed to report the drain progress")
+	}
+}
+
+// escalateDrain moves the drain to the phase of its escalation and warns on each new phase. An error is
+// returned when the drain is aborted.
+func (dn *Daemon) escalateDrain(progress *sriovnetworkv1.DrainStatus) error {
+	elapsed := time.Since(progress.StartTime.Time).Round(time.Second)
+	phase := dn.drainEscalation.Phase(elapsed)
+	if phase == progress.Phase {
+		return nil
+	}
+	progress.Phase = phase
+	progress.Deadline = dn.drainEscalation.Deadline(progress.StartTime.Time)
+	dn.reportDrain(progress)
+	status := sriovnetworkv1.DrainingCondition(progress, 0).Message
+	switch phase {
+	case sriovnetworkv1.DrainPhaseStuck:
+		dn.eventRecorder.SendWarningEvent("DrainStuck", fmt.Sprintf("Drain node not completed after %s: %s", elapsed, status))
+	case sriovnetworkv1.DrainPhaseForcing:
+		dn.eventRecorder.SendWarningEvent("DrainForced",
+			fmt.Sprintf("Drain node not completed after %s, deleting the remaining pods regardless of their PodDisruptionBudgets: %s", elapsed, status))
+	case sriovnetworkv1.DrainPhaseAborted:
+		dn.eventRecorder.SendWarningEvent("DrainAborted", fmt.Sprintf("Drain node not completed after %s, aborted: %s", elapsed, status))
+		return snerrors.Wrap(snerrors.ErrDrainAborted, fmt.Errorf("drain not completed after %s", elapsed))
+	}
+	return nil
+}
+
+// abortDrain uncordons the node whose drain was aborted, its configuration is not applied until the generation
+// of its node state changes
+func (dn *Daemon) abortDrain(generation int64) {
+	log.Log.Info("abortDrain(): drain aborted", "generation", generation)
+	dn.drainAbortedGeneration = generation
+	progress := dn.drainStatus
+	if err := dn.completeDrain(sriovnetworkv1.DrainModeDrain); err != nil {
+		log.Log.Error(err, "abortDrain(): failed to release the node")
+	}
+	// the aborted drain stays reported until the next drain or configuration
+	dn.reportDrain(progress)
+}
+
+// runNodeDrain evicts the pods of the node, or deletes them once the drain is forced, and reports the pods
+// remaining and failing eviction
+func (dn *Daemon) runNodeDrain(progress *sriovnetworkv1.DrainStatus) error {
+	drainer := dn.drainer
+	if progress.Phase == sriovnetworkv1.DrainPhaseForcing {
+		forced := *dn.drainer
+		forced.DisableEviction = true
+		drainer = &forced
+	}
+
+	list, errs := drainer.GetPodsForDeletion(vars.NodeName)
+	if errs != nil {
+		return utilerrors.NewAggregate(errs)
+	}
+	if warnings := list.Warnings(); warnings != "" {
+		log.Log.Info("runNodeDrain(): drain warnings", "warnings", warnings)
+	}
+	progress.PodsRemaining = len(list.Pods())
+	dn.reportDrain(progress)
+
+	drainErr := drainer.DeleteOrEvictPods(list.Pods())
+	if drainErr == nil {
+		progress.PodsRemaining, progress.FailingPods = 0, nil
+		dn.reportDrain(progress)
+		return nil
+	}
+	if remaining, errs := drainer.GetPodsForDeletion(vars.NodeName); errs == nil {
+		progress.PodsRemaining = len(remaining.Pods())
+		progress.FailingPods = dn.podEvictionFailures(remaining.Pods(), drainErr)
+	}
+	dn.reportDrain(progress)
+	return drainErr
+}
+
+// podEvictionFailures returns why the pods remaining on the node weren't evicted: a PodDisruptionBudget allowing
+// no disruption, a termination still in progress or the error of their eviction
+func (dn *Daemon) podEvictionFailures(pods []corev1.Pod, drainErr error) []sriovnetworkv1.DrainPodFailure {
+	errs := []error{drainErr}
+	if aggregate, ok := drainErr.(utilerrors.Aggregate); ok {
+		errs = aggregate.Errors()
+	}
+	budgets := map[string][]policyv1.PodDisruptionBudget{}
+	failures := []sriovnetworkv1.DrainPodFailure{}
+	for i := range pods {
+		if len(failures) == maxReportedDrainFailures {
+			break
+		}
+		pod := &pods[i]
+		if _, ok := budgets[pod.Namespace]; !ok {
+			list, err := dn.kubeClient.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				log.Log.Error(err, "podEvictionFailures(): failed to list the PodDisruptionBudgets", "namespace", pod.Namespace)
+			} else {
+				budgets[pod.Namespace] = list.Items
+			}
+		}
+		failures = append(failures, sriovnetworkv1.DrainPodFailure{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Reason:    podEvictionFailure(pod, budgets[pod.Namespace], errs),
+		})
+	}
+	return failures
+}
+
+func podEvictionFailure(pod *corev1.Pod, budgets []policyv1.PodDisruptionBudget, errs []error) string {
+	for _, budget := range budgets {
+		selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if budget.Status.DisruptionsAllowed <= 0 {
+			return fmt.Sprintf("the PodDisruptionBudget %s allows no disruption", budget.Name)
+		}
+	}
+	if pod.DeletionTimestamp != nil {
+		return fmt.Sprintf("terminating since %s", pod.DeletionTimestamp.UTC().Format(time.RFC3339))
+	}
+	for _, err := range errs {
+		if strings.Contains(err.Error(), fmt.Sprintf("%q", pod.Name)) && strings.Contains(err.Error(), fmt.Sprintf("%q", pod.Namespace)) {
+			return err.Error()
+		}
+	}
+	return "not evicted yet"
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// driftAuditKey is the work item of the host drift audit, it is queued with the generations of the node state so
+	// the audit never runs during a sync
+	driftAuditKey int64 = -1
+	// driftAuditInterval is the interval between the host drift audits
+	driftAuditInterval = 2 * time.Minute
+)
+
+// detectHostDrift returns the PFs of the spec whose configuration differs from the spec last applied to them, the PFs
+// never configured by the daemon and the externally managed ones are left out. The applied spec of the PFs removed
+// from the spec is kept by the host, they are left out too.
+func (dn *Daemon) detectHostDrift(spec sriovnetworkv1.Interfaces) ([]string, error) {
+	ifaces, err := dn.HostHelpers.DiscoverSriovDevices(dn.HostHelpers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the SR-IOV devices: %v", err)
+	}
+	inSpec := map[string]bool{}
+	for _, iface := range spec {
+		inSpec[iface.PciAddress] = true
+	}
+	drifts := []string{}
+	for i := range ifaces {
+		iface := &ifaces[i]
+		if !inSpec[iface.PciAddress] {
+			continue
+		}
+		drift, err := dn.pfHostDrift(iface)
+		if err != nil {
+			return nil, err
+		}
+		if drift != "" {
+			drifts = append(drifts, fmt.Sprintf("%s(%s) %s", iface.Name, iface.PciAddress, drift))
+		}
+	}
+	return drifts, nil
+}
+
+// pfHostDrift compares the discovered PF with the spec last applied to it, the applied spec is read under the lock of
+// the PF so it is never read while the PF is configured and its applied spec saved
+func (dn *Daemon) pfHostDrift(iface *sriovnetworkv1.InterfaceExt) (string, error) {
+	defer host.LockPf(iface.PciAddress)()
+	applied, found, err := dn.HostHelpers.LoadPfsStatus(iface.PciAddress)
+	if err != nil {
+		return "", err
+	}
+	if !found || applied.ExternallyManaged {
+		return "", nil
+	}
+	return hostDrift(applied, iface), nil
+}
+
+// hostDrift describes how the PF differs from the spec last applied to it, empty when it doesn't
+func hostDrift(applied *sriovnetworkv1.Interface, iface *sriovnetworkv1.InterfaceExt) string {
+	if applied.NumVfs != iface.NumVfs {
+		return fmt.Sprintf("numVfs %d, applied %d", iface.NumVfs, applied.NumVfs)
+	}
+	eswitchMode := applied.EswitchMode
+	if eswitchMode == "" {
+		eswitchMode = sriovnetworkv1.ESwithModeLegacy
+	}
+	if iface.EswitchMode != "" && iface.EswitchMode != eswitchMode {
+		return fmt.Sprintf("eSwitchMode %s, applied %s", iface.EswitchMode, eswitchMode)
+	}
+	if applied.Mtu > 0 && applied.Mtu != iface.Mtu {
+		return fmt.Sprintf("mtu %d, applied %d", iface.Mtu, applied.Mtu)
+	}
+	if sriovnetworkv1.NeedToUpdateSriov(applied, iface) {
+		return "VF drivers or MTU differ"
+	}
+	return ""
+}
+
+// auditHostDrift compares the PFs of the applied node state with the spec last applied to them, and reports the
+// drift in the HostDrift condition of the node state. The node state is synced again when the remediation is enabled.
+func (dn *Daemon) auditHostDrift() {
+	// the host is configured by the systemd service or by the virtual platform, or the configuration is frozen
+	if dn.nodeState.GetGeneration() == 0 || vars.UsingSystemdMode || vars.PlatformType != consts.Baremetal ||
+		dn.pauseNodeConfiguration.Load() || dn.isNodeDraining() {
+		return
+	}
+	dn.refreshUnicastMacs()
+	drifts, err := dn.detectHostDrift(dn.nodeState.Spec.Interfaces)
+	if err != nil {
+		log.Log.Error(err, "auditHostDrift(): failed to audit the host drift")
+		return
+	}
+	if strings.Join(drifts, ",") != strings.Join(dn.hostDrifts, ",") || dn.hostDrifts == nil {
+		if len(drifts) > 0 {
+			log.Log.Info("auditHostDrift(): the configuration of the PFs differs from the applied spec", "drifts", drifts)
+			dn.eventRecorder.SendWarningEvent("HostDrift",
+				"configuration of the PFs differs from the applied spec: "+strings.Join(drifts, ", "))
+		}
+		if err := dn.statusWriter.SetHostDrift(drifts); err != nil {
+			log.Log.Error(err, "auditHostDrift(): failed to report the host drift")
+			return
+		}
+		dn.hostDrifts = drifts
+	}
+	if len(drifts) > 0 && dn.remediateHostDrift {
+		log.Log.Info("auditHostDrift(): remediate the host drift", "generation", dn.nodeState.GetGeneration())
+		dn.syncAgain = true
+		dn.workqueue.Add(dn.nodeState.GetGeneration())
+	}
+}
+
+// refreshUnicastMacs programs again the additional unicast MACs of the VFs, the kernel flushes them when a VF moves
+// to the network namespace of a pod and back
+func (dn *Daemon) refreshUnicastMacs() {
+	for i := range dn.nodeState.Spec.Interfaces {
+		iface := &dn.nodeState.Spec.Interfaces[i]
+		if len(iface.UnicastMacsByVf()) == 0 {
+			continue
+		}
+		if err := dn.HostHelpers.SyncUnicastMacs(iface); err != nil {
+			log.Log.Error(err, "refreshUnicastMacs(): failed to program the unicast MACs", "device", iface.PciAddress)
+		}
+	}
+}
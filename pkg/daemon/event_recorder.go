@@ -5,6 +5,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedv1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -36,12 +37,28 @@ func NewEventRecorder(c snclientset.Interface, kubeclient kubernetes.Interface)
 
 // SendEvent Send an Event on the NodeState object
 func (e *EventRecorder) SendEvent(eventType string, msg string) {
+	e.sendEvent(corev1.EventTypeNormal, eventType, msg)
+}
+
+// SendWarningEvent sends a Warning Event on the NodeState object
+func (e *EventRecorder) SendWarningEvent(reason string, msg string) {
+	e.sendEvent(corev1.EventTypeWarning, reason, msg)
+}
+
+// SendNodeEvent sends an Event on the Node object, like the events of node-problem-detector
+func (e *EventRecorder) SendNodeEvent(eventType, reason, msg string) {
+	// the kubelet and node-problem-detector use the name of the node as its UID in the events
+	node := &corev1.ObjectReference{Kind: "Node", Name: vars.NodeName, UID: types.UID(vars.NodeName)}
+	e.eventRecorder.Event(node, eventType, reason, msg)
+}
+
+func (e *EventRecorder) sendEvent(eventType, reason, msg string) {
 	nodeState, err := e.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
 	if err != nil {
 		log.Log.V(2).Error(err, "SendEvent(): Failed to fetch node state, skip SendEvent", "name", vars.NodeName)
 		return
 	}
-	e.eventRecorder.Event(nodeState, corev1.EventTypeNormal, eventType, msg)
+	e.eventRecorder.Event(nodeState, eventType, reason, msg)
 }
 
 // Shutdown Close the EventBroadcaster
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// maxHookOutput bounds the output of a failed hook reported in the node state status
+const maxHookOutput = 1024
+
+// runHooks runs the hooks of the node state registered at the point, in order. A failure of a
+// hook with the Fail policy stops the run and is returned, so that the sync is retried.
+func (dn *Daemon) runHooks(ctx context.Context, state *sriovnetworkv1.SriovNetworkNodeState, point string) error {
+	var hookErr error
+	for _, hook := range state.Spec.Hooks {
+		if hook.Point != point {
+			continue
+		}
+		log.Log.Info("runHooks(): run hook", "name", hook.Name, "point", point)
+		status := sriovnetworkv1.HookStatus{
+			Name:        hook.Name,
+			Point:       point,
+			Result:      consts.HookResultSucceeded,
+			LastRunTime: metav1.Now(),
+		}
+		err := traceStep(ctx, "hook", func() error { return runHook(&hook) },
+			"name", hook.Name, "point", point)
+		if err != nil {
+			status.Message = err.Error()
+			if hook.FailurePolicy == consts.HookFailurePolicyIgnore {
+				log.Log.Error(err, "runHooks(): hook failed, ignoring", "name", hook.Name, "point", point)
+				status.Result = consts.HookResultIgnored
+			} else {
+				log.Log.Error(err, "runHooks(): hook failed", "name", hook.Name, "point", point)
+				status.Result = consts.HookResultFailed
+				hookErr = snerrors.Wrap(snerrors.ErrHookFailed, fmt.Errorf("%s hook %s failed: %v", point, hook.Name, err))
+			}
+		}
+		dn.setHookStatus(state, status)
+		if hookErr != nil {
+			break
+		}
+	}
+	return hookErr
+}
+
+// setHookStatus records the last run of a hook, dropping the statuses of the hooks removed from the spec
+func (dn *Daemon) setHookStatus(state *sriovnetworkv1.SriovNetworkNodeState, status sriovnetworkv1.HookStatus) {
+	statuses := []sriovnetworkv1.HookStatus{}
+	for _, s := range dn.hookStatuses {
+		if s.Name == status.Name || !hasHook(state.Spec.Hooks, s.Name) {
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+	dn.hookStatuses = append(statuses, status)
+	dn.statusWriter.SetHookStatuses(dn.hookStatuses)
+}
+
+func hasHook(hooks []sriovnetworkv1.Hook, name string) bool {
+	for _, h := range hooks {
+		if h.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runHook runs the command of the hook chrooted to the host file system, killing it after its timeout. The
+// privileged helper of the confined daemon doesn't run the hooks, the operator doesn't confine the daemon of the
+// pools with hooks.
+func runHook(hook *sriovnetworkv1.Hook) error {
+	if utils.HostExecutorSet() {
+		return fmt.Errorf("hooks are not run by the confined config daemon")
+	}
+	timeout := consts.DefaultHookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if vars.InChroot {
+		name := hook.Command[0]
+		if filepath.IsAbs(name) {
+			name = filepath.Join(vars.FilesystemRoot, name)
+		}
+		cmd = exec.CommandContext(ctx, name, hook.Command[1:]...)
+	} else {
+		cmd = exec.CommandContext(ctx, "chroot", append([]string{utils.GetHostExtension()}, hook.Command...)...)
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		out := strings.TrimSpace(output.String())
+		if len(out) > maxHookOutput {
+			out = out[len(out)-maxHookOutput:]
+		}
+		if out == "" {
+			return err
+		}
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
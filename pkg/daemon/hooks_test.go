@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+var _ = Describe("config daemon hooks", func() {
+	var dn *Daemon
+	var root string
+
+	BeforeEach(func() {
+		prevRoot, prevInChroot := vars.FilesystemRoot, vars.InChroot
+		DeferCleanup(func() {
+			vars.FilesystemRoot, vars.InChroot = prevRoot, prevInChroot
+		})
+		root = GinkgoT().TempDir()
+		vars.FilesystemRoot, vars.InChroot = root, true
+		Expect(os.WriteFile(filepath.Join(root, "ok.sh"), []byte("#!/bin/sh\necho \"$@\" >> \"$(dirname \"$0\")/ran\"\n"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "fail.sh"), []byte("#!/bin/sh\necho device busy\nexit 3\n"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "hang.sh"), []byte("#!/bin/sh\nexec sleep 10\n"), 0755)).To(Succeed())
+		dn = &Daemon{statusWriter: NewNodeStateStatusWriter(nil, nil, nil, nil, nil, nil)}
+	})
+
+	It("runs the hooks of the point in order", func() {
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Hooks: []sriovnetworkv1.Hook{
+				{Name: "first", Point: consts.HookPointPreDrain, Command: []string{"/ok.sh", "first"}},
+				{Name: "other", Point: consts.HookPointPostApply, Command: []string{"/ok.sh", "other"}},
+				{Name: "second", Point: consts.HookPointPreDrain, Command: []string{"/ok.sh", "second"}},
+			},
+		}}
+		Expect(dn.runHooks(context.Background(), state, consts.HookPointPreDrain)).To(Succeed())
+
+		ran, err := os.ReadFile(filepath.Join(root, "ran"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(ran)).To(Equal("first\nsecond\n"))
+		statuses := dn.statusWriter.hookStatuses()
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses[0].Name).To(Equal("first"))
+		Expect(statuses[0].Result).To(Equal(consts.HookResultSucceeded))
+		Expect(statuses[1].Name).To(Equal("second"))
+	})
+
+	It("stops on a failed hook with the Fail policy", func() {
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Hooks: []sriovnetworkv1.Hook{
+				{Name: "quiesce", Point: consts.HookPointPreApply, Command: []string{"/fail.sh"}},
+				{Name: "never", Point: consts.HookPointPreApply, Command: []string{"/ok.sh"}},
+			},
+		}}
+		err := dn.runHooks(context.Background(), state, consts.HookPointPreApply)
+		Expect(err).To(MatchError(ContainSubstring("preApply hook quiesce failed")))
+
+		_, err = os.Stat(filepath.Join(root, "ran"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		statuses := dn.statusWriter.hookStatuses()
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Result).To(Equal(consts.HookResultFailed))
+		Expect(statuses[0].Message).To(ContainSubstring("device busy"))
+	})
+
+	It("reports ignored failures and timeouts", func() {
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Hooks: []sriovnetworkv1.Hook{
+				{Name: "slow", Point: consts.HookPointPreReboot, Command: []string{"/hang.sh"},
+					TimeoutSeconds: 1, FailurePolicy: consts.HookFailurePolicyIgnore},
+			},
+		}}
+		Expect(dn.runHooks(context.Background(), state, consts.HookPointPreReboot)).To(Succeed())
+
+		statuses := dn.statusWriter.hookStatuses()
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Result).To(Equal(consts.HookResultIgnored))
+		Expect(statuses[0].Message).To(Equal("timed out after 1s"))
+	})
+
+	It("drops the statuses of the removed hooks", func() {
+		dn.hookStatuses = []sriovnetworkv1.HookStatus{{Name: "removed", Result: consts.HookResultFailed}}
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Hooks: []sriovnetworkv1.Hook{{Name: "new", Point: consts.HookPointPostApply, Command: []string{"/ok.sh"}}},
+		}}
+		Expect(dn.runHooks(context.Background(), state, consts.HookPointPostApply)).To(Succeed())
+
+		statuses := dn.statusWriter.hookStatuses()
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Name).To(Equal("new"))
+	})
+
+	It("fails the hooks in the confined daemon", func() {
+		utils.SetHostExecutor(deniedExecutor{})
+		DeferCleanup(func() { utils.SetHostExecutor(nil) })
+		state := &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Hooks: []sriovnetworkv1.Hook{{Name: "quiesce", Point: consts.HookPointPreDrain, Command: []string{"/ok.sh"}}},
+		}}
+		Expect(dn.runHooks(context.Background(), state, consts.HookPointPreDrain)).To(MatchError(ContainSubstring("not run by the confined config daemon")))
+
+		Expect(filepath.Join(root, "ran")).ToNot(BeAnExistingFile())
+		statuses := dn.statusWriter.hookStatuses()
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Result).To(Equal(consts.HookResultFailed))
+	})
+})
+
+// deniedExecutor stands for the privileged helper of the confined daemon
+type deniedExecutor struct{}
+
+func (deniedExecutor) RunCommand(root, command string, args ...string) (string, string, error) {
+	return "", "", errors.New("not allowed")
+}
+
+func (deniedExecutor) WriteFile(root, path string, data []byte, perm os.FileMode) error {
+	return errors.New("not allowed")
+}
+
+func (deniedExecutor) RemoveFile(root, path string) error {
+	return errors.New("not allowed")
+}
@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// representorPortName matches the phys_port_name of the representor of a VF, e.g. pf0vf3
+var representorPortName = regexp.MustCompile(`^pf(\d+)vf(\d+)$`)
+
+// ovsHardwareOffloadPending returns true when the pool of the node enables the hardware offload of OVN-Kubernetes
+// and the hw-offload flag of Open vSwitch is not set yet. Setting it restarts ovs-vswitchd, which interrupts the
+// traffic of the node, the node is drained first.
+func (dn *Daemon) ovsHardwareOffloadPending() (bool, error) {
+	if dn.node == nil || dn.node.Labels[consts.OvsHwOffloadLabel] != "true" {
+		return false, nil
+	}
+	enabled, err := dn.ovsHardwareOffloadEnabled()
+	if err != nil {
+		log.Log.Error(err, "ovsHardwareOffloadPending(): failed to read the hardware offload flag of Open vSwitch")
+		if annotateErr := dn.setNodeAnnotations(vars.NodeName, map[string]string{
+			consts.OvsHwOffloadStateAnnotation: consts.OvsHwOffloadStateFailed,
+		}); annotateErr != nil {
+			return false, annotateErr
+		}
+		return false, err
+	}
+	return !enabled, nil
+}
+
+// syncOvsHardwareOffload enables the hardware offload of the Open vSwitch of the node when its pool enables the
+// hardware offload of OVN-Kubernetes, and reports the result and the representors of the switchdev PFs in the
+// annotations of the node. ovs-vswitchd only reads the flag when it starts, it is restarted when the flag is set,
+// pending is the result of ovsHardwareOffloadPending before the node was drained.
+func (dn *Daemon) syncOvsHardwareOffload(state *sriovnetworkv1.SriovNetworkNodeState, pending bool) error {
+	if dn.node == nil || dn.node.Labels[consts.OvsHwOffloadLabel] != "true" {
+		return nil
+	}
+	var err error
+	offloadState := consts.OvsHwOffloadStateEnabled
+	if pending {
+		if err = dn.enableOvsHardwareOffload(); err != nil {
+			log.Log.Error(err, "syncOvsHardwareOffload(): failed to enable the hardware offload of Open vSwitch")
+			offloadState = consts.OvsHwOffloadStateFailed
+		}
+	}
+	if annotateErr := dn.setNodeAnnotations(vars.NodeName, map[string]string{
+		consts.OvsHwOffloadStateAnnotation:        offloadState,
+		consts.OvsHwOffloadRepresentorsAnnotation: representorsState(state),
+	}); annotateErr != nil {
+		return annotateErr
+	}
+	return err
+}
+
+func (dn *Daemon) ovsHardwareOffloadEnabled() (bool, error) {
+	stdout, stderr, err := dn.HostHelpers.RunCommand("/bin/sh", "-c",
+		fmt.Sprintf("%s ovs-vsctl --timeout=10 --if-exists get Open_vSwitch . other_config:hw-offload", utils.GetChrootExtension()))
+	if err != nil {
+		return false, fmt.Errorf("failed to read the hardware offload flag of Open vSwitch: %v: %s", err, stderr)
+	}
+	return strings.Trim(strings.TrimSpace(stdout), `"`) == "true", nil
+}
+
+func (dn *Daemon) enableOvsHardwareOffload() error {
+	chroot := utils.GetChrootExtension()
+	log.Log.Info("enableOvsHardwareOffload(): enable the hardware offload of Open vSwitch and restart ovs-vswitchd")
+	_, stderr, err := dn.HostHelpers.RunCommand("/bin/sh", "-c",
+		fmt.Sprintf("%s ovs-vsctl --timeout=10 set Open_vSwitch . other_config:hw-offload=true", chroot))
+	if err != nil {
+		return fmt.Errorf("failed to set the hardware offload flag of Open vSwitch: %v: %s", err, stderr)
+	}
+	_, stderr, err = dn.HostHelpers.RunCommand("/bin/sh", "-c",
+		fmt.Sprintf("%s systemctl try-restart ovs-vswitchd.service", chroot))
+	if err != nil {
+		return fmt.Errorf("failed to restart ovs-vswitchd: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// representorsState returns OvsHwOffloadRepresentorsNamed when the representors of all the VFs of the switchdev PFs
+// of the node state exist and the switchdev udev rule named them <PF>_<VF>, OvsHwOffloadRepresentorsCreated when
+// some keep their kernel names, and OvsHwOffloadRepresentorsPending otherwise. The representors of a PF share its
+// phys_switch_id, their phys_port_name is pf<port>vf<VF>.
+func representorsState(state *sriovnetworkv1.SriovNetworkNodeState) string {
+	netDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet)
+	readAttr := func(name, attr string) string {
+		data, err := os.ReadFile(filepath.Join(netDir, name, attr))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	entries, err := os.ReadDir(netDir)
+	if err != nil {
+		log.Log.Error(err, "representorsState(): failed to list the netdevs")
+		return consts.OvsHwOffloadRepresentorsPending
+	}
+
+	named := true
+	for _, iface := range state.Spec.Interfaces {
+		if iface.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev || iface.NumVfs == 0 {
+			continue
+		}
+		switchID := readAttr(iface.Name, "phys_switch_id")
+		port := strings.TrimPrefix(readAttr(iface.Name, "phys_port_name"), "p")
+		if switchID == "" || port == "" {
+			return consts.OvsHwOffloadRepresentorsPending
+		}
+		found := map[string]bool{}
+		for _, entry := range entries {
+			match := representorPortName.FindStringSubmatch(readAttr(entry.Name(), "phys_port_name"))
+			if match == nil || match[1] != port || readAttr(entry.Name(), "phys_switch_id") != switchID {
+				continue
+			}
+			found[match[2]] = true
+			named = named && entry.Name() == fmt.Sprintf("%s_%s", iface.Name, match[2])
+		}
+		if len(found) < iface.NumVfs {
+			return consts.OvsHwOffloadRepresentorsPending
+		}
+	}
+	if !named {
+		return consts.OvsHwOffloadRepresentorsCreated
+	}
+	return consts.OvsHwOffloadRepresentorsNamed
+}
@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"regexp"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/pfrename"
+)
+
+// splitPortNameRe matches the phys_port_name of a port of a split PF, e.g. p0s1
+var splitPortNameRe = regexp.MustCompile(`^p[0-9]+s[0-9]+$`)
+
+// pfNamesPending returns true if a PF of the node state has to be renamed, or given its original name back. The
+// rename sets the link of the PF down, the node is drained first.
+func (dn *Daemon) pfNamesPending(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	renames, err := pfrename.Load()
+	if err != nil {
+		return false, err
+	}
+	desired := map[string]bool{}
+	for _, iface := range state.Spec.Interfaces {
+		if iface.RenameTo == "" {
+			continue
+		}
+		desired[iface.PciAddress] = true
+		if guard.Check(iface.PciAddress) != nil {
+			continue
+		}
+		if name := dn.HostHelpers.TryGetInterfaceName(iface.PciAddress); name != "" && name != iface.RenameTo {
+			return true, nil
+		}
+	}
+	for pciAddress, rename := range renames {
+		if desired[pciAddress] || guard.Check(pciAddress) != nil {
+			continue
+		}
+		if name := dn.HostHelpers.TryGetInterfaceName(pciAddress); name != "" && name == rename.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// syncPfNames renames the PFs of the node state to the names set by the pfNamePrefix of their policies, and gives
+// the PFs no policy renames anymore their original names back. The udev rules keep the names across the reboots and
+// the rename map of the node keeps the original names. The names of the PFs are updated in the spec and the status,
+// the PFs are configured by their new names.
+func (dn *Daemon) syncPfNames(state *sriovnetworkv1.SriovNetworkNodeState) error {
+	renames, err := pfrename.Load()
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]bool{}
+	for i := range state.Spec.Interfaces {
+		iface := &state.Spec.Interfaces[i]
+		if iface.RenameTo == "" {
+			continue
+		}
+		desired[iface.PciAddress] = true
+		if err := guard.Check(iface.PciAddress); err != nil {
+			log.Log.Info("syncPfNames(): skipping the rename of the interface", "address", iface.PciAddress, "reason", err.Error())
+			continue
+		}
+		name := dn.HostHelpers.TryGetInterfaceName(iface.PciAddress)
+		if name == "" {
+			// the PF has no netdev, e.g. it is bound to a userspace driver
+			continue
+		}
+		rename, found := renames[iface.PciAddress]
+		if !found {
+			rename.OriginalName = name
+		}
+		if name != iface.RenameTo {
+			log.Log.Info("syncPfNames(): rename PF", "address", iface.PciAddress, "name", name, "newName", iface.RenameTo)
+			if err := dn.HostHelpers.RenameNetDev(name, iface.RenameTo); err != nil {
+				return err
+			}
+		}
+		rename.Name = iface.RenameTo
+		renames[iface.PciAddress] = rename
+		// the rename map is saved before the udev rule, the original name is never lost
+		if err := pfrename.Save(renames); err != nil {
+			return err
+		}
+		// the rule of a split port only renames the port, the other ports of the PF keep their names
+		portName, _ := dn.HostHelpers.GetPhysPortName(iface.RenameTo)
+		if !splitPortNameRe.MatchString(portName) {
+			portName = ""
+		}
+		if err := dn.HostHelpers.AddPfRenameUdevRule(iface.PciAddress, portName, iface.RenameTo); err != nil {
+			return err
+		}
+		setPfName(state, iface.PciAddress, iface.RenameTo)
+	}
+
+	for pciAddress, rename := range renames {
+		if desired[pciAddress] {
+			continue
+		}
+		if err := guard.Check(pciAddress); err != nil {
+			log.Log.Info("syncPfNames(): skipping the restore of the interface name", "address", pciAddress, "reason", err.Error())
+			continue
+		}
+		if name := dn.HostHelpers.TryGetInterfaceName(pciAddress); name != "" && name == rename.Name {
+			log.Log.Info("syncPfNames(): restore PF name", "address", pciAddress, "name", name, "originalName", rename.OriginalName)
+			if err := dn.HostHelpers.RenameNetDev(name, rename.OriginalName); err != nil {
+				return err
+			}
+		}
+		if err := dn.HostHelpers.RemovePfRenameUdevRule(pciAddress); err != nil {
+			return err
+		}
+		delete(renames, pciAddress)
+		if err := pfrename.Save(renames); err != nil {
+			return err
+		}
+		setPfName(state, pciAddress, rename.OriginalName)
+	}
+	return nil
+}
+
+// setPfName sets the name of the PF in the spec and the status of the node state
+func setPfName(state *sriovnetworkv1.SriovNetworkNodeState, pciAddress, name string) {
+	for i := range state.Spec.Interfaces {
+		if state.Spec.Interfaces[i].PciAddress == pciAddress {
+			state.Spec.Interfaces[i].Name = name
+		}
+	}
+	for i := range state.Status.Interfaces {
+		if state.Status.Interfaces[i].PciAddress == pciAddress {
+			state.Status.Interfaces[i].Name = name
+		}
+	}
+}
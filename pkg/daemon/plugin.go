@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -9,6 +10,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/external"
 	genericplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
 	intelplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/intel"
 	k8splugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/k8s"
@@ -28,6 +30,7 @@ var (
 	VirtualPlugin     = virtualplugin.NewVirtualPlugin
 	VirtualPluginName = virtualplugin.PluginName
 	K8sPlugin         = k8splugin.NewK8sPlugin
+	ExternalPlugins   = external.Discover
 )
 
 func loadPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.HostHelpersInterface, disabledPlugins []string) (map[string]plugin.VendorPlugin, error) {
@@ -44,6 +47,18 @@ func loadPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.HostHe
 		if !isPluginDisabled(pluginName, disabledPlugins) {
 			loadedPlugins[pluginName] = virtualPlugin
 		}
+		// the generic plugin provisions the VFs of the SR-IOV capable PFs of the virtual machine
+		if vars.NestedSriov {
+			genericPlugin, err := GenericPlugin(helpers)
+			if err != nil {
+				log.Log.Error(err, "loadPlugins(): failed to load the generic plugin")
+				return nil, err
+			}
+			pluginName := genericPlugin.Name()
+			if !isPluginDisabled(pluginName, disabledPlugins) {
+				loadedPlugins[pluginName] = genericPlugin
+			}
+		}
 	} else {
 		loadedVendorPlugins, err := loadVendorPlugins(ns, helpers, disabledPlugins)
 		if err != nil {
@@ -102,9 +117,43 @@ func loadVendorPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.
 		}
 	}
 
+	externalPlugins, err := ExternalPlugins()
+	if errors.Is(err, external.ErrConfinedDaemon) {
+		return vendorPlugins, fmt.Errorf("loadVendorPlugins(): %v, disable the %s feature gate", err, consts.ConfinedDaemonFeatureGate)
+	}
+	if err != nil {
+		// the in-tree plugins can still configure the node
+		log.Log.Error(err, "loadVendorPlugins(): failed to discover the external plugins")
+		return vendorPlugins, nil
+	}
+	for _, plug := range externalPlugins {
+		pluginName := plug.Name()
+		if isInTreePlugin(pluginName) {
+			log.Log.Error(nil, "loadVendorPlugins(): external plugin name conflicts with an in-tree plugin, skipping", "plugin-name", pluginName)
+			continue
+		}
+		if _, ok := vendorPlugins[pluginName]; ok || isPluginDisabled(pluginName, disabledPlugins) {
+			continue
+		}
+		for _, iface := range ns.Status.Interfaces {
+			if plug.HandlesVendor(iface.Vendor) {
+				vendorPlugins[pluginName] = plug
+				break
+			}
+		}
+	}
+
 	return vendorPlugins, nil
 }
 
+func isInTreePlugin(pluginName string) bool {
+	switch pluginName {
+	case intelplugin.PluginName, mellanoxplugin.PluginName, GenericPluginName, VirtualPluginName, k8splugin.PluginName:
+		return true
+	}
+	return false
+}
+
 func isPluginDisabled(pluginName string, disabledPlugins []string) bool {
 	for _, p := range disabledPlugins {
 		if p == pluginName {
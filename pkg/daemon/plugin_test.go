@@ -1,6 +1,10 @@
 package daemon
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	gomock "github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,6 +15,8 @@ import (
 	helperMocks "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	fakePlugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/fake"
+	intelplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/intel"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -92,6 +98,21 @@ var _ = Describe("config daemon plugin loading tests", func() {
 			validateVendorPlugins(vendorPlugins, []string{"virtual"})
 		})
 
+		It("loads the generic plugin next to the virtual one for the SR-IOV capable PFs of the virtual machine", func() {
+			vars.PlatformType = consts.VirtualOpenStack
+			vars.NestedSriov = true
+			DeferCleanup(func() { vars.NestedSriov = false })
+			ns := &v1.SriovNetworkNodeState{
+				Status: v1.SriovNetworkNodeStateStatus{
+					Interfaces: v1.InterfaceExts{v1.InterfaceExt{Vendor: "15b3"}},
+				},
+			}
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			validateVendorPlugins(vendorPlugins, []string{"virtual", "generic"})
+		})
+
 		It("loads vendor plugin according to vendors present", func() {
 			ns := &v1.SriovNetworkNodeState{
 				Status: v1.SriovNetworkNodeStateStatus{
@@ -132,5 +153,55 @@ var _ = Describe("config daemon plugin loading tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"intel", "k8s", "mellanox"})
 		})
+
+		It("loads the external plugins handling the vendors present", func() {
+			root := GinkgoT().TempDir()
+			dir := filepath.Join(root, consts.ExternalPluginsDir)
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			for name, vendor := range map[string]string{"acme": "1d0f", "other": "abcd", "intel": "8086"} {
+				script := fmt.Sprintf("#!/bin/sh\necho '{\"name\": \"%s\", \"vendors\": [\"%s\"]}'\n", name, vendor)
+				Expect(os.WriteFile(filepath.Join(dir, name), []byte(script), 0755)).To(Succeed())
+			}
+			prevRoot, prevInChroot := vars.FilesystemRoot, vars.InChroot
+			DeferCleanup(func() {
+				vars.FilesystemRoot, vars.InChroot = prevRoot, prevInChroot
+			})
+			vars.FilesystemRoot, vars.InChroot = root, true
+
+			ns := &v1.SriovNetworkNodeState{
+				Status: v1.SriovNetworkNodeStateStatus{
+					Interfaces: v1.InterfaceExts{
+						v1.InterfaceExt{Vendor: "1d0f"},
+						v1.InterfaceExt{Vendor: "8086"}},
+				},
+			}
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			validateVendorPlugins(vendorPlugins, []string{"acme", "intel", "generic", "k8s"})
+			Expect(vendorPlugins["intel"]).To(BeAssignableToTypeOf(&intelplugin.IntelPlugin{}))
+		})
+
+		It("refuses the external plugins in the confined daemon", func() {
+			root := GinkgoT().TempDir()
+			dir := filepath.Join(root, consts.ExternalPluginsDir)
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "acme"), []byte("#!/bin/sh\necho '{\"name\": \"acme\"}'\n"), 0755)).To(Succeed())
+			prevRoot, prevInChroot := vars.FilesystemRoot, vars.InChroot
+			DeferCleanup(func() {
+				vars.FilesystemRoot, vars.InChroot = prevRoot, prevInChroot
+				utils.SetHostExecutor(nil)
+			})
+			vars.FilesystemRoot, vars.InChroot = root, true
+			utils.SetHostExecutor(deniedExecutor{})
+
+			ns := &v1.SriovNetworkNodeState{
+				Status: v1.SriovNetworkNodeStateStatus{
+					Interfaces: v1.InterfaceExts{v1.InterfaceExt{Vendor: "8086"}},
+				},
+			}
+			_, err := loadPlugins(ns, helperMock, nil)
+			Expect(err).To(MatchError(ContainSubstring(consts.ConfinedDaemonFeatureGate)))
+		})
 	})
 })
@@ -0,0 +1,210 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// nodeProblemsKey is the work item of the detection of the SR-IOV faults of the node, it is queued with the
+	// generations of the node state so the detection never runs during a sync
+	nodeProblemsKey int64 = -3
+	// nodeProblemsInterval is the interval between the detections of the SR-IOV faults of the node
+	nodeProblemsInterval = time.Minute
+	// vfCreationFailureThreshold is the number of consecutive syncs failing to configure the PFs after which the VF
+	// creation failure is reported
+	vfCreationFailureThreshold = 3
+)
+
+// nodeProblemConditionTypes are the node conditions of the SR-IOV faults
+var nodeProblemConditionTypes = []corev1.NodeConditionType{
+	consts.PfDownConditionType,
+	consts.FirmwareFaultConditionType,
+	consts.VfCreationFailureConditionType,
+}
+
+// isVfCreationFailure returns true if the sync failed to configure the PFs, the failures of the drain, of the hooks
+// and of the checks refusing the configuration are left out
+func isVfCreationFailure(reason snerrors.Reason) bool {
+	switch reason {
+	case snerrors.ReasonDrainFailed, snerrors.ReasonDrainAborted, snerrors.ReasonHookFailed,
+		snerrors.ReasonPtpTimeSource, snerrors.ReasonIncompatibleSpecVersion, snerrors.ReasonPciAddressGuarded,
+		snerrors.ReasonInterfaceClaimed, snerrors.ReasonNMStatePending, snerrors.ReasonMetadataUnavailable:
+		return false
+	}
+	return true
+}
+
+// recordSyncResult counts the consecutive syncs failing to configure the PFs, a successful sync resets the count
+func (dn *Daemon) recordSyncResult(err error) {
+	if err == nil {
+		dn.vfCreationFailures, dn.lastVfCreationError = 0, ""
+		return
+	}
+	if isVfCreationFailure(snerrors.ReasonOf(err)) {
+		dn.vfCreationFailures++
+		dn.lastVfCreationError = err.Error()
+	}
+}
+
+// detectNodeProblems returns the node conditions of the SR-IOV faults of the PFs of the applied node state
+func (dn *Daemon) detectNodeProblems() []corev1.NodeCondition {
+	down := []string{}
+	faults := []string{}
+	for _, iface := range dn.nodeState.Spec.Interfaces {
+		if name := dn.HostHelpers.TryGetInterfaceName(iface.PciAddress); name != "" &&
+			dn.HostHelpers.GetNetDevLinkState(name) == consts.LinkStateDown {
+			down = append(down, fmt.Sprintf("%s(%s)", name, iface.PciAddress))
+		}
+		reporters, err := dn.HostHelpers.GetFirmwareFaults(iface.PciAddress)
+		if err != nil {
+			// the drivers without devlink health reporters
+			log.Log.V(2).Info("detectNodeProblems(): unable to read the firmware health", "device", iface.PciAddress, "error", err)
+			continue
+		}
+		if len(reporters) > 0 {
+			faults = append(faults, fmt.Sprintf("%s %s", iface.PciAddress, strings.Join(reporters, ", ")))
+		}
+	}
+
+	return []corev1.NodeCondition{
+		nodeProblemCondition(consts.PfDownConditionType, len(down) > 0, consts.PfDownReason, consts.PfUpReason,
+			"the link of the PFs is down: "+strings.Join(down, ", "), "the link of the PFs is up"),
+		nodeProblemCondition(consts.FirmwareFaultConditionType, len(faults) > 0, consts.FirmwareFaultReason,
+			consts.FirmwareHealthyReason, "the firmware health reporters of the PFs are in error: "+strings.Join(faults, "; "),
+			"the firmware of the PFs is healthy"),
+		nodeProblemCondition(consts.VfCreationFailureConditionType, dn.vfCreationFailures >= vfCreationFailureThreshold,
+			consts.VfCreationFailureReason, consts.VfCreationSucceededReason,
+			fmt.Sprintf("the configuration of the VFs failed %d times: %s", dn.vfCreationFailures, dn.lastVfCreationError),
+			"the VFs are configured"),
+	}
+}
+
+func nodeProblemCondition(conditionType string, present bool, reason, okReason, message, okMessage string) corev1.NodeCondition {
+	if present {
+		return corev1.NodeCondition{Type: corev1.NodeConditionType(conditionType), Status: corev1.ConditionTrue,
+			Reason: reason, Message: message}
+	}
+	return corev1.NodeCondition{Type: corev1.NodeConditionType(conditionType), Status: corev1.ConditionFalse,
+		Reason: okReason, Message: okMessage}
+}
+
+// reportNodeProblems sets the node conditions of the SR-IOV faults of the node, and records an event on the node
+// when a problem appears or clears, as node-problem-detector does. The conditions are removed when the reporting
+// is disabled.
+func (dn *Daemon) reportNodeProblems() {
+	if !dn.reportProblems && !dn.nodeProblemsReported {
+		return
+	}
+	var conditions []corev1.NodeCondition
+	if dn.reportProblems {
+		// the host is configured by the virtual platform or by the systemd service before the daemon runs
+		if dn.nodeState.GetGeneration() == 0 || vars.PlatformType != consts.Baremetal {
+			return
+		}
+		conditions = dn.detectNodeProblems()
+	}
+	if err := dn.setNodeProblemConditions(conditions); err != nil {
+		log.Log.Error(err, "reportNodeProblems(): failed to report the SR-IOV problems of the node")
+		return
+	}
+	dn.nodeProblemsReported = dn.reportProblems
+}
+
+// setNodeProblemConditions writes the node conditions of the SR-IOV faults, the conditions not given are removed
+func (dn *Daemon) setNodeProblemConditions(conditions []corev1.NodeCondition) error {
+	// the transitions of the conditions written, an event is recorded for each of them
+	var transitions []corev1.NodeCondition
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		transitions = nil
+		node, err := dn.kubeClient.CoreV1().Nodes().Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		desired := map[corev1.NodeConditionType]corev1.NodeCondition{}
+		for _, condition := range conditions {
+			desired[condition.Type] = condition
+		}
+		now := metav1.Now()
+		changed := false
+		updated := []corev1.NodeCondition{}
+		for _, existing := range node.Status.Conditions {
+			if !isNodeProblemCondition(existing.Type) {
+				updated = append(updated, existing)
+				continue
+			}
+			condition, ok := desired[existing.Type]
+			if !ok {
+				changed = true
+				continue
+			}
+			delete(desired, existing.Type)
+			if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+				updated = append(updated, existing)
+				continue
+			}
+			condition.LastHeartbeatTime, condition.LastTransitionTime = now, existing.LastTransitionTime
+			if existing.Status != condition.Status {
+				condition.LastTransitionTime = now
+				transitions = append(transitions, condition)
+			}
+			updated = append(updated, condition)
+			changed = true
+		}
+		for _, conditionType := range nodeProblemConditionTypes {
+			condition, ok := desired[conditionType]
+			if !ok {
+				continue
+			}
+			condition.LastHeartbeatTime, condition.LastTransitionTime = now, now
+			if condition.Status == corev1.ConditionTrue {
+				transitions = append(transitions, condition)
+			}
+			updated = append(updated, condition)
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		node.Status.Conditions = updated
+		_, err = dn.kubeClient.CoreV1().Nodes().UpdateStatus(context.Background(), node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, condition := range transitions {
+		dn.recordNodeProblemEvent(condition)
+	}
+	return nil
+}
+
+func isNodeProblemCondition(conditionType corev1.NodeConditionType) bool {
+	for _, t := range nodeProblemConditionTypes {
+		if t == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// recordNodeProblemEvent records a warning event on the node when the problem appears, a normal one when it clears
+func (dn *Daemon) recordNodeProblemEvent(condition corev1.NodeCondition) {
+	eventType := corev1.EventTypeNormal
+	if condition.Status == corev1.ConditionTrue {
+		eventType = corev1.EventTypeWarning
+		log.Log.Info("reportNodeProblems(): SR-IOV problem detected", "condition", condition.Type, "message", condition.Message)
+	}
+	dn.eventRecorder.SendNodeEvent(eventType, condition.Reason, condition.Message)
+}
@@ -1,23 +1,36 @@
 package daemon
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/cdi"
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/cni"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/watch"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/lldp"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
@@ -27,6 +40,18 @@ const (
 	Unknown            = "Unknown"
 )
 
+const (
+	// hostEventSettleTime is how long the writer waits for the host changes to settle before refreshing the status
+	hostEventSettleTime = 2 * time.Second
+	// pollInterval is the period of the status refresh when the host changes are not watched
+	pollInterval = 30 * time.Second
+	// watchedPollInterval is the period of the status refresh when the host changes are watched
+	watchedPollInterval = 5 * time.Minute
+	// statusUpdateMinInterval is the minimum interval between the status refreshes triggered by the host changes,
+	// the changes in between are coalesced in the next refresh. The refreshes of the syncs are written right away
+	statusUpdateMinInterval = 10 * time.Second
+)
+
 type NodeStateStatusWriter struct {
 	client             snclientset.Interface
 	status             sriovnetworkv1.SriovNetworkNodeStateStatus
@@ -34,17 +59,46 @@ type NodeStateStatusWriter struct {
 	platformHelper     platforms.Interface
 	hostHelper         helper.HostHelpersInterface
 	eventRecorder      *EventRecorder
+	lldpListener       *lldp.Listener
+	// compact reports the VFs as ranges instead of the per-VF details
+	compact atomic.Bool
+	// cdiSpec generates the CDI spec of the VFs of the node
+	cdiSpec atomic.Bool
+	// platformDrainRequired is set when the device information of the virtual platform was restored from a node
+	// status that may predate the boot of the node, the node is drained by the next sync
+	platformDrainRequired atomic.Bool
+	// hugepages is the number of hugepages allocated on the node, of all the sizes
+	hugepages int
+
+	hooksMu sync.Mutex
+	// hooks are the statuses of the last runs of the hooks of the node
+	hooks []sriovnetworkv1.HookStatus
+
+	drainMu sync.Mutex
+	// drain is the progress of the drain of the node, nil when the node is not drained
+	drain *sriovnetworkv1.DrainStatus
+
+	hostDriftMu sync.Mutex
+	// hostDrift are the PFs whose configuration differs from the spec last applied to them, nil until the first
+	// audit of the node
+	hostDrift []string
+
+	unboundVfsMu sync.Mutex
+	// unboundVfs are the VFs still unbound after the attempts to bind them, nil until the first attempts
+	unboundVfs []string
 }
 
 // NewNodeStateStatusWriter Create a new NodeStateStatusWriter
 func NewNodeStateStatusWriter(c snclientset.Interface,
 	f func(), er *EventRecorder,
 	hostHelper helper.HostHelpersInterface,
-	platformHelper platforms.Interface) *NodeStateStatusWriter {
+	platformHelper platforms.Interface,
+	lldpListener *lldp.Listener) *NodeStateStatusWriter {
 	return &NodeStateStatusWriter{
 		client:             c,
 		OnHeartbeatFailure: f,
 		eventRecorder:      er,
+		lldpListener:       lldpListener,
 		hostHelper:         hostHelper,
 		platformHelper:     platformHelper,
 	}
@@ -64,13 +118,34 @@ func (w *NodeStateStatusWriter) RunOnce() error {
 		if ns == nil {
 			err = w.platformHelper.CreateOpenstackDevicesInfo()
 			if err != nil {
-				return err
+				if !errors.Is(err, snerrors.ErrMetadataUnavailable) {
+					return err
+				}
+				if err = w.recoverOpenstackDevicesInfo(err); err != nil {
+					return err
+				}
 			}
 		} else {
 			w.platformHelper.CreateOpenstackDevicesInfoFromNodeStatus(ns)
 		}
 	}
 
+	// the architecture, the hugepage sizes and the isolated CPUs don't change until the next reboot, nor the CNI
+	// plugins until the daemon restarts
+	w.status.Architecture = vars.Architecture
+	hugepageSizes, err := w.hostHelper.GetHugepageSizes()
+	if err != nil {
+		log.Log.Error(err, "RunOnce(): failed to read the hugepage sizes")
+	}
+	w.status.HugepageSizes = hugepageSizes
+	isolatedCpus, err := w.hostHelper.GetIsolatedCpus()
+	if err != nil {
+		log.Log.Error(err, "RunOnce(): failed to read the isolated CPUs")
+	}
+	w.status.IsolatedCpus = isolatedCpus
+	// the CNI plugins are installed by the init containers of the daemon pod
+	w.status.CniPlugins = cni.Plugins(consts.CniBinDir)
+
 	log.Log.V(0).Info("RunOnce(): first poll for nic status")
 	if err := w.pollNicStatus(); err != nil {
 		log.Log.Error(err, "RunOnce(): first poll failed")
@@ -85,10 +160,19 @@ func (w *NodeStateStatusWriter) RunOnce() error {
 
 // Run reads from the writer channel and sets the interface status. It will
 // return if the stop channel is closed. Intended to be run via a goroutine.
-func (w *NodeStateStatusWriter) Run(stop <-chan struct{}, refresh <-chan Message, syncCh chan<- struct{}) error {
+// The status is refreshed when the host reports a device or link change on the
+// hostEvents channel, and periodically as a safety net for missed events.
+func (w *NodeStateStatusWriter) Run(stop <-chan struct{}, refresh <-chan Message, syncCh chan<- struct{}, hostEvents <-chan watch.Event) error {
 	log.Log.V(0).Info("Run(): start writer")
 	msg := Message{}
 
+	interval := pollInterval
+	if hostEvents != nil {
+		interval = watchedPollInterval
+	}
+	// a burst of events, e.g. when the VFs of a PF are created, triggers a single refresh
+	var settle <-chan time.Time
+	var lastRefresh time.Time
 	for {
 		select {
 		case <-stop:
@@ -103,15 +187,70 @@ func (w *NodeStateStatusWriter) Run(stop <-chan struct{}, refresh <-chan Message
 			if err != nil {
 				log.Log.Error(err, "Run() refresh: writing to node status failed")
 			}
+			lastRefresh = time.Now()
 			syncCh <- struct{}{}
-		case <-time.After(30 * time.Second):
+		case e, ok := <-hostEvents:
+			if !ok {
+				log.Log.V(0).Info("Run(): host events are no longer watched, polling the host")
+				hostEvents = nil
+				interval = pollInterval
+				continue
+			}
+			if e.Type == watch.Resync {
+				log.Log.Info("Run(): host events lost, rescan the host")
+			} else {
+				log.Log.V(2).Info("Run(): host event", "event", e.String())
+			}
+			// the inventory is discovered again whatever the event, a resync rescans the whole host
+			w.hostHelper.InvalidateInventory()
+			if settle == nil {
+				settle = time.After(hostEventSettleTime)
+			}
+		case <-settle:
+			if wait := statusUpdateMinInterval - time.Since(lastRefresh); wait > 0 {
+				log.Log.V(2).Info("Run(): host change refresh delayed", "wait", wait)
+				settle = time.After(wait)
+				continue
+			}
+			settle = nil
+			log.Log.V(2).Info("Run(): host change refresh")
+			if err := w.pollNicStatus(); err != nil {
+				continue
+			}
+			w.setNodeStateStatus(msg)
+			lastRefresh = time.Now()
+		case <-time.After(interval):
 			log.Log.V(2).Info("Run(): period refresh")
 			if err := w.pollNicStatus(); err != nil {
 				continue
 			}
 			w.setNodeStateStatus(msg)
+			lastRefresh = time.Now()
+		}
+	}
+}
+
+// addNestedPfs reports the SR-IOV capable PFs of the virtual machine as on the bare metal nodes, in place of the
+// device of the platform of the same PCI address, e.g. a PF passed through by the hypervisor
+func (w *NodeStateStatusWriter) addNestedPfs(platformDevices []sriovnetworkv1.InterfaceExt) ([]sriovnetworkv1.InterfaceExt, error) {
+	pfs, err := w.hostHelper.DiscoverSriovDevices(w.hostHelper)
+	if err != nil {
+		return nil, err
+	}
+	nested := map[string]bool{}
+	result := []sriovnetworkv1.InterfaceExt{}
+	for _, pf := range pfs {
+		if pf.TotalVfs > 0 {
+			nested[pf.PciAddress] = true
+			result = append(result, pf)
 		}
 	}
+	for _, device := range platformDevices {
+		if !nested[device.PciAddress] {
+			result = append(result, device)
+		}
+	}
+	return result, nil
 }
 
 func (w *NodeStateStatusWriter) pollNicStatus() error {
@@ -119,22 +258,168 @@ func (w *NodeStateStatusWriter) pollNicStatus() error {
 	var iface []sriovnetworkv1.InterfaceExt
 	var err error
 
+	defer metrics.ObserveDaemonPhase(metrics.DaemonPhaseDiscovery, time.Now())
+
 	if vars.PlatformType == consts.VirtualOpenStack {
 		iface, err = w.platformHelper.DiscoverSriovDevicesVirtual()
+		if err == nil && vars.NestedSriov {
+			iface, err = w.addNestedPfs(iface)
+		}
 	} else {
 		iface, err = w.hostHelper.DiscoverSriovDevices(w.hostHelper)
 	}
 	if err != nil {
 		return err
 	}
+	w.lldpListener.UpdateInterfaces(iface)
 	w.status.Interfaces = iface
+	if w.cdiSpec.Load() {
+		w.writeCDISpec()
+	}
+
+	// the hugepages can be allocated at runtime, unlike the isolated CPUs
+	hugepages, err := w.hostHelper.GetNumHugepages()
+	if err != nil {
+		log.Log.Error(err, "pollNicStatus(): failed to read the number of hugepages")
+	} else {
+		w.hugepages = hugepages
+	}
 
 	return nil
 }
 
+// SetCompact selects whether the VFs are reported as ranges of VFs sharing the same
+// configuration instead of the per-VF details
+func (w *NodeStateStatusWriter) SetCompact(compact bool) {
+	if w.compact.Swap(compact) != compact {
+		log.Log.Info("SetCompact(): compact node state", "enabled", compact)
+	}
+}
+
+// SetCDISpec selects whether the CDI spec of the VFs is generated on the node, the spec is removed when disabled
+func (w *NodeStateStatusWriter) SetCDISpec(enabled bool) {
+	if w.cdiSpec.Swap(enabled) == enabled {
+		return
+	}
+	log.Log.Info("SetCDISpec(): CDI spec generation", "enabled", enabled)
+	if !enabled {
+		if err := cdi.RemoveSpec(); err != nil {
+			log.Log.Error(err, "SetCDISpec(): failed to remove the CDI spec")
+		}
+	}
+}
+
+// writeCDISpec writes the CDI spec of the VFs discovered last, the errors are only logged as the spec is written
+// again at the next refresh
+func (w *NodeStateStatusWriter) writeCDISpec() {
+	written, err := cdi.WriteSpec(cdi.BuildSpec(w.status.Interfaces))
+	if err != nil {
+		log.Log.Error(err, "writeCDISpec(): failed to write the CDI spec")
+		return
+	}
+	if written {
+		log.Log.V(2).Info("writeCDISpec(): CDI spec updated")
+	}
+}
+
+// SetHookStatuses sets the statuses of the hooks reported with the next status update
+func (w *NodeStateStatusWriter) SetHookStatuses(hooks []sriovnetworkv1.HookStatus) {
+	w.hooksMu.Lock()
+	defer w.hooksMu.Unlock()
+	w.hooks = append([]sriovnetworkv1.HookStatus{}, hooks...)
+}
+
+// SetDrainStatus reports the progress of the drain of the node right away, a nil drain clears it
+func (w *NodeStateStatusWriter) SetDrainStatus(drain *sriovnetworkv1.DrainStatus) error {
+	w.drainMu.Lock()
+	w.drain = drain.DeepCopy()
+	w.drainMu.Unlock()
+	_, err := w.updateNodeStateStatusRetry(func(nodeState *sriovnetworkv1.SriovNetworkNodeState) {
+		nodeState.Status.Drain = w.drainStatus()
+		meta.SetStatusCondition(&nodeState.Status.Conditions,
+			sriovnetworkv1.DrainingCondition(nodeState.Status.Drain, nodeState.Generation))
+	})
+	return err
+}
+
+// SetHostDrift reports the PFs whose configuration differs from the spec last applied to them right away
+func (w *NodeStateStatusWriter) SetHostDrift(drifts []string) error {
+	w.hostDriftMu.Lock()
+	w.hostDrift = append([]string{}, drifts...)
+	w.hostDriftMu.Unlock()
+	_, err := w.updateNodeStateStatusRetry(func(nodeState *sriovnetworkv1.SriovNetworkNodeState) {
+		drifts, _ := w.hostDrifts()
+		meta.SetStatusCondition(&nodeState.Status.Conditions,
+			sriovnetworkv1.HostDriftCondition(drifts, nodeState.Generation))
+	})
+	return err
+}
+
+// hostDrifts returns the drifted PFs of the last audit, and whether the node has been audited
+func (w *NodeStateStatusWriter) hostDrifts() ([]string, bool) {
+	w.hostDriftMu.Lock()
+	defer w.hostDriftMu.Unlock()
+	if w.hostDrift == nil {
+		return nil, false
+	}
+	return append([]string{}, w.hostDrift...), true
+}
+
+// SetUnboundVfs reports the VFs still unbound after the attempts to bind them right away
+func (w *NodeStateStatusWriter) SetUnboundVfs(unbound []string) error {
+	w.unboundVfsMu.Lock()
+	w.unboundVfs = append([]string{}, unbound...)
+	w.unboundVfsMu.Unlock()
+	_, err := w.updateNodeStateStatusRetry(func(nodeState *sriovnetworkv1.SriovNetworkNodeState) {
+		unbound, _ := w.stuckVfs()
+		meta.SetStatusCondition(&nodeState.Status.Conditions,
+			sriovnetworkv1.VfsUnboundCondition(unbound, nodeState.Generation))
+	})
+	return err
+}
+
+// stuckVfs returns the VFs still unbound after the attempts to bind them, and whether they have been attempted
+func (w *NodeStateStatusWriter) stuckVfs() ([]string, bool) {
+	w.unboundVfsMu.Lock()
+	defer w.unboundVfsMu.Unlock()
+	if w.unboundVfs == nil {
+		return nil, false
+	}
+	return append([]string{}, w.unboundVfs...), true
+}
+
+func (w *NodeStateStatusWriter) drainStatus() *sriovnetworkv1.DrainStatus {
+	w.drainMu.Lock()
+	defer w.drainMu.Unlock()
+	return w.drain.DeepCopy()
+}
+
+func (w *NodeStateStatusWriter) hookStatuses() []sriovnetworkv1.HookStatus {
+	w.hooksMu.Lock()
+	defer w.hooksMu.Unlock()
+	if len(w.hooks) == 0 {
+		return nil
+	}
+	return append([]sriovnetworkv1.HookStatus{}, w.hooks...)
+}
+
+// statusInterfaces returns the interfaces reported in the node state status
+func (w *NodeStateStatusWriter) statusInterfaces() sriovnetworkv1.InterfaceExts {
+	if !w.compact.Load() {
+		return w.status.Interfaces
+	}
+	interfaces := make(sriovnetworkv1.InterfaceExts, len(w.status.Interfaces))
+	for i, iface := range w.status.Interfaces {
+		iface.VFsSummary = sriovnetworkv1.CompactVirtualFunctions(iface.VFs)
+		iface.VFs = nil
+		interfaces[i] = iface
+	}
+	return interfaces
+}
+
 func (w *NodeStateStatusWriter) updateNodeStateStatusRetry(f func(*sriovnetworkv1.SriovNetworkNodeState)) (*sriovnetworkv1.SriovNetworkNodeState, error) {
 	var nodeState *sriovnetworkv1.SriovNetworkNodeState
-	var oldStatus, newStatus, lastError string
+	var oldStatus, newStatus, lastError, lastErrorReason string
 
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		n, getErr := w.getNodeState()
@@ -142,42 +427,108 @@ func (w *NodeStateStatusWriter) updateNodeStateStatusRetry(f func(*sriovnetworkv
 			return getErr
 		}
 		oldStatus = n.Status.SyncStatus
+		original := n.DeepCopy()
 
 		// Call the status modifier.
 		f(n)
 
+		// the unchanged status is not written, the refreshes of a node without change don't reach the API server
+		if statusUnchanged(&original.Status, &n.Status) {
+			log.Log.V(2).Info("updateNodeStateStatusRetry(): status unchanged, skipping the update")
+			metrics.IncNodeStateStatusUpdates(true)
+			nodeState = n
+			return nil
+		}
+
 		newStatus = n.Status.SyncStatus
 		lastError = n.Status.LastSyncError
+		lastErrorReason = n.Status.LastSyncErrorReason
 
-		var err error
-		nodeState, err = w.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).UpdateStatus(context.Background(), n, metav1.UpdateOptions{})
+		// Send only the changed status fields, the patch carries the resourceVersion of the
+		// object it was computed from so that a concurrent write is reported as a conflict
+		patch, err := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}).Data(n)
+		if err != nil {
+			return err
+		}
+		nodeState, err = w.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Patch(context.Background(),
+			n.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
 		if err != nil {
 			log.Log.V(0).Error(err, "updateNodeStateStatusRetry(): fail to update the node status")
+			return err
 		}
-		return err
+		metrics.IncNodeStateStatusUpdates(false)
+		return nil
 	})
 	if err != nil {
 		// may be conflict if max retries were hit
 		return nil, fmt.Errorf("unable to update node %v: %v", nodeState, err)
 	}
 
-	w.recordStatusChangeEvent(oldStatus, newStatus, lastError)
+	w.recordStatusChangeEvent(oldStatus, newStatus, lastError, lastErrorReason)
 
 	return nodeState, nil
 }
 
+// statusUnchanged compares the statuses as they are stored by the API server, the times are only stored to the
+// second and the times kept by the writer, e.g. the last run of the hooks, would otherwise always differ from
+// the written ones
+func statusUnchanged(original, updated *sriovnetworkv1.SriovNetworkNodeStateStatus) bool {
+	originalData, err := json.Marshal(original)
+	if err != nil {
+		return false
+	}
+	updatedData, err := json.Marshal(updated)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(originalData, updatedData)
+}
+
 func (w *NodeStateStatusWriter) setNodeStateStatus(msg Message) (*sriovnetworkv1.SriovNetworkNodeState, error) {
 	nodeState, err := w.updateNodeStateStatusRetry(func(nodeState *sriovnetworkv1.SriovNetworkNodeState) {
-		nodeState.Status.Interfaces = w.status.Interfaces
+		nodeState.Status.Interfaces = w.statusInterfaces()
+		nodeState.Status.Hooks = w.hookStatuses()
+		nodeState.Status.Drain = w.drainStatus()
+		meta.SetStatusCondition(&nodeState.Status.Conditions,
+			sriovnetworkv1.DrainingCondition(nodeState.Status.Drain, nodeState.Generation))
+		nodeState.Status.SupportedSpecVersions = sriovnetworkv1.SupportedSpecVersions()
+		nodeState.Status.Architecture = w.status.Architecture
+		nodeState.Status.HugepageSizes = w.status.HugepageSizes
+		nodeState.Status.IsolatedCpus = w.status.IsolatedCpus
+		nodeState.Status.CniPlugins = w.status.CniPlugins
+		meta.SetStatusCondition(&nodeState.Status.Conditions,
+			sriovnetworkv1.PcieLinkDegradedCondition(w.status.Interfaces, nodeState.Generation))
+		meta.SetStatusCondition(&nodeState.Status.Conditions, sriovnetworkv1.DpdkPrerequisitesCondition(
+			nodeState.Spec.Interfaces, w.hugepages, w.status.IsolatedCpus, nodeState.Generation))
+		meta.SetStatusCondition(&nodeState.Status.Conditions, sriovnetworkv1.InterfaceClaimConflictCondition(
+			guard.Conflicts(nodeState.Spec.Interfaces), nodeState.Generation))
+		if drifts, audited := w.hostDrifts(); audited {
+			meta.SetStatusCondition(&nodeState.Status.Conditions,
+				sriovnetworkv1.HostDriftCondition(drifts, nodeState.Generation))
+		}
+		if unbound, attempted := w.stuckVfs(); attempted {
+			meta.SetStatusCondition(&nodeState.Status.Conditions,
+				sriovnetworkv1.VfsUnboundCondition(unbound, nodeState.Generation))
+		}
+		if vars.PlatformType == consts.VirtualOpenStack {
+			nodeState.Status.Metadata = w.platformHelper.GetOpenstackMetadataProvenance()
+			meta.SetStatusCondition(&nodeState.Status.Conditions, sriovnetworkv1.MetadataIncompleteCondition(
+				w.platformHelper.GetOpenstackMetadataErrors(), w.platformHelper.GetOpenstackUnresolvedDevices(),
+				nodeState.Generation))
+			meta.SetStatusCondition(&nodeState.Status.Conditions, sriovnetworkv1.MtuMismatchCondition(
+				w.platformHelper.GetOpenstackMtuMismatches(), nodeState.Generation))
+		}
 		if msg.lastSyncError != "" || msg.syncStatus == consts.SyncStatusSucceeded {
 			// clear lastSyncError when sync Succeeded
 			nodeState.Status.LastSyncError = msg.lastSyncError
+			nodeState.Status.LastSyncErrorReason = string(msg.lastSyncErrorReason)
 		}
 		nodeState.Status.SyncStatus = msg.syncStatus
 
 		log.Log.V(0).Info("setNodeStateStatus(): status",
 			"sync-status", nodeState.Status.SyncStatus,
-			"last-sync-error", nodeState.Status.LastSyncError)
+			"last-sync-error", nodeState.Status.LastSyncError,
+			"last-sync-error-reason", nodeState.Status.LastSyncErrorReason)
 	})
 	if err != nil {
 		return nil, err
@@ -185,8 +536,9 @@ func (w *NodeStateStatusWriter) setNodeStateStatus(msg Message) (*sriovnetworkv1
 	return nodeState, nil
 }
 
-// recordStatusChangeEvent sends event in case oldStatus differs from newStatus
-func (w *NodeStateStatusWriter) recordStatusChangeEvent(oldStatus, newStatus, lastError string) {
+// recordStatusChangeEvent sends event in case oldStatus differs from newStatus, the reason of the event
+// is the reason of the last error when it is part of the error taxonomy
+func (w *NodeStateStatusWriter) recordStatusChangeEvent(oldStatus, newStatus, lastError, lastErrorReason string) {
 	if oldStatus != newStatus {
 		if oldStatus == "" {
 			oldStatus = Unknown
@@ -198,7 +550,11 @@ func (w *NodeStateStatusWriter) recordStatusChangeEvent(oldStatus, newStatus, la
 		if lastError != "" {
 			eventMsg = fmt.Sprintf("%s. Last Error: %s", eventMsg, lastError)
 		}
-		w.eventRecorder.SendEvent("SyncStatusChanged", eventMsg)
+		eventReason := "SyncStatusChanged"
+		if lastError != "" && lastErrorReason != "" && lastErrorReason != string(snerrors.ReasonUnknown) {
+			eventReason = lastErrorReason
+		}
+		w.eventRecorder.SendEvent(eventReason, eventMsg)
 	}
 }
 
@@ -225,6 +581,31 @@ func (w *NodeStateStatusWriter) getNodeState() (*sriovnetworkv1.SriovNetworkNode
 	return n, nil
 }
 
+// recoverOpenstackDevicesInfo restores the device information when the OpenStack metadata is unavailable and the
+// node state wasn't checkpointed since the boot of the node. The device information persisted since the boot is used
+// first. Otherwise the device information is restored from the status of the node state, which may have been
+// written before a reboot with other devices, and the node is drained so that the pods using the SR-IOV devices
+// are scheduled again. The metadata error is returned when the node state reports no interface.
+func (w *NodeStateStatusWriter) recoverOpenstackDevicesInfo(metadataErr error) error {
+	restored, err := w.platformHelper.CreateOpenstackDevicesInfoFromCheckpoint()
+	if err != nil {
+		log.Log.Error(err, "recoverOpenstackDevicesInfo(): failed to restore the persisted devices info")
+	}
+	if restored {
+		log.Log.Info("recoverOpenstackDevicesInfo(): OpenStack metadata unavailable, using the persisted devices info")
+		return nil
+	}
+
+	ns, err := w.client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+	if err != nil || len(ns.Status.Interfaces) == 0 {
+		return metadataErr
+	}
+	log.Log.Info("recoverOpenstackDevicesInfo(): OpenStack metadata unavailable, using the node state status, drain required")
+	w.platformHelper.CreateOpenstackDevicesInfoFromNodeStatus(ns)
+	w.platformDrainRequired.Store(true)
+	return nil
+}
+
 func (w *NodeStateStatusWriter) writeCheckpointFile(ns *sriovnetworkv1.SriovNetworkNodeState) error {
 	configdir := filepath.Join(vars.Destdir, CheckpointFileName)
 	file, err := os.OpenFile(configdir, os.O_RDWR|os.O_CREATE, 0644)
@@ -0,0 +1,467 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	fakesnclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned/fake"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	mock_helper "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/watch"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/lldp"
+	mock_platforms "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+var _ = Describe("NodeStateStatusWriter", func() {
+	It("patches the status without touching the spec", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:86:00.0", NumVfs: 4}},
+			},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{LastSyncError: "old error"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.status.Interfaces = sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 4}}
+
+		_, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+
+		ns, err := client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Spec.Interfaces).To(HaveLen(1))
+		Expect(ns.Spec.Interfaces[0].NumVfs).To(Equal(4))
+		Expect(ns.Status.SyncStatus).To(Equal(consts.SyncStatusSucceeded))
+		Expect(ns.Status.LastSyncError).To(BeEmpty())
+		Expect(ns.Status.Interfaces).To(Equal(w.status.Interfaces))
+	})
+
+	It("skips the status updates without change", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.status.Interfaces = sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 4}}
+		patches := func() int {
+			count := 0
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "patch" {
+					count++
+				}
+			}
+			return count
+		}
+
+		_, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patches()).To(Equal(1))
+
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.SyncStatus).To(Equal(consts.SyncStatusSucceeded))
+		Expect(patches()).To(Equal(1))
+
+		w.status.Interfaces[0].NumVfs = 8
+		_, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patches()).To(Equal(2))
+	})
+
+	It("skips the status updates when only the precision of its times differs from the written status", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.status.Interfaces = sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 4}}
+		// the written times are truncated to the second
+		w.SetHookStatuses([]sriovnetworkv1.HookStatus{{Name: "tune", Point: "post-configure", Result: "Succeeded",
+			LastRunTime: metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 123456789, time.UTC))}})
+		Expect(w.SetDrainStatus(&sriovnetworkv1.DrainStatus{Phase: "Draining",
+			StartTime: metav1.NewTime(time.Date(2024, 1, 1, 9, 59, 0, 987654321, time.UTC))})).To(Succeed())
+
+		patches := func() int {
+			count := 0
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "patch" {
+					count++
+				}
+			}
+			return count
+		}
+
+		_, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusInProgress})
+		Expect(err).ToNot(HaveOccurred())
+		written := patches()
+
+		_, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusInProgress})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patches()).To(Equal(written))
+	})
+
+	It("reports the reason of the sync error", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		ns, err := w.setNodeStateStatus(Message{
+			syncStatus:          consts.SyncStatusFailed,
+			lastSyncError:       "write sriov_numvfs: device or resource busy",
+			lastSyncErrorReason: snerrors.ReasonVfInUse,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.LastSyncErrorReason).To(Equal("VfInUse"))
+
+		// the reason is cleared with the error
+		ns, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.LastSyncError).To(BeEmpty())
+		Expect(ns.Status.LastSyncErrorReason).To(BeEmpty())
+	})
+
+	It("reports the VFs as ranges in compact mode", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.SetCompact(true)
+		w.status.Interfaces = sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 2,
+			VFs: []sriovnetworkv1.VirtualFunction{
+				{VfID: 0, PciAddress: "0000:86:02.0", Driver: "iavf"},
+				{VfID: 1, PciAddress: "0000:86:02.1", Driver: "iavf"},
+			}}}
+
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.Interfaces[0].VFs).To(BeEmpty())
+		Expect(ns.Status.Interfaces[0].VFsSummary).To(Equal([]sriovnetworkv1.VirtualFunctionRange{{VfRange: "0-1", Driver: "iavf"}}))
+		// the full details are kept for the daemon
+		Expect(w.status.Interfaces[0].VFs).To(HaveLen(2))
+	})
+
+	It("reports the PCIe links trained below their capability", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.status.Interfaces = sriovnetworkv1.InterfaceExts{{Name: "ens1f0", PciAddress: "0000:86:00.0",
+			PcieLink: &sriovnetworkv1.PcieLinkInfo{Speed: "8.0 GT/s PCIe", MaxSpeed: "8.0 GT/s PCIe", Width: 16, MaxWidth: 16}}}
+
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		cond := meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionPcieLinkDegraded)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+
+		w.status.Interfaces[0].PcieLink.Width = 8
+		w.status.Interfaces[0].PcieLink.Degraded = true
+		ns, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		cond = meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionPcieLinkDegraded)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring("ens1f0(0000:86:00.0) x8"))
+	})
+
+	It("reports the missing prerequisites of the DPDK workloads", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:86:00.0", NumVfs: 4, VfGroups: []sriovnetworkv1.VfGroup{{
+					PolicyName: "dpdk", DeviceType: consts.DeviceTypeVfioPci, VfRange: "0-3", RequireIsolatedCpus: true}}}}},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, nil, nil)
+		w.hugepages = 512
+		w.status.IsolatedCpus = "2-15"
+
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.IsolatedCpus).To(Equal("2-15"))
+		cond := meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionDpdkPrerequisitesMissing)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+
+		w.hugepages = 0
+		ns, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		cond = meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionDpdkPrerequisitesMissing)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(Equal("no hugepages are allocated for the vfio-pci VFs of the policies dpdk"))
+	})
+
+	It("reports the malformed entries of the OpenStack metadata", func() {
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.VirtualOpenStack
+		DeferCleanup(func() {
+			vars.PlatformType = consts.Baremetal
+		})
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+		platformHelper.EXPECT().GetOpenstackMetadataErrors().Return([]string{"device fa:16:3e:22:22:22: invalid integer \"none\""})
+		platformHelper.EXPECT().GetOpenstackUnresolvedDevices().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMetadataProvenance().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMtuMismatches().Return(nil)
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, platformHelper, nil)
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		cond := meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionMetadataIncomplete)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring("device fa:16:3e:22:22:22"))
+	})
+
+	It("reports where the OpenStack device information was read from", func() {
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.VirtualOpenStack
+		DeferCleanup(func() {
+			vars.PlatformType = consts.Baremetal
+		})
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		readTime := metav1.NewTime(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+		platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+		platformHelper.EXPECT().GetOpenstackMetadataErrors().Return(nil)
+		platformHelper.EXPECT().GetOpenstackUnresolvedDevices().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMtuMismatches().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMetadataProvenance().Return(&sriovnetworkv1.MetadataProvenance{
+			Sources:  []string{sriovnetworkv1.MetadataSourceConfigDrive, sriovnetworkv1.MetadataSourceMetadataService},
+			ReadTime: readTime,
+		})
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, platformHelper, nil)
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ns.Status.Metadata).ToNot(BeNil())
+		Expect(ns.Status.Metadata.Sources).To(Equal([]string{"ConfigDrive", "MetadataService"}))
+		Expect(ns.Status.Metadata.ReadTime.Equal(&readTime)).To(BeTrue())
+	})
+
+	It("reports the devices whose MTU differs from the MTU of their OpenStack network", func() {
+		vars.NodeName = "test-node"
+		vars.PlatformType = consts.VirtualOpenStack
+		DeferCleanup(func() {
+			vars.PlatformType = consts.Baremetal
+		})
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+		platformHelper.EXPECT().GetOpenstackMetadataErrors().Return(nil)
+		platformHelper.EXPECT().GetOpenstackUnresolvedDevices().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMetadataProvenance().Return(nil)
+		platformHelper.EXPECT().GetOpenstackMtuMismatches().Return([]string{"ens4(0000:04:00.0) 1500, declared 8942"})
+
+		w := NewNodeStateStatusWriter(client, nil, er, nil, platformHelper, nil)
+		ns, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		cond := meta.FindStatusCondition(ns.Status.Conditions, sriovnetworkv1.ConditionMtuMismatch)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring("ens4(0000:04:00.0) 1500, declared 8942"))
+	})
+
+	It("reports the SR-IOV capable PFs of the virtual machine in place of the devices of the platform", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		hostHelper := mock_helper.NewMockHostHelpersInterface(mockCtrl)
+		hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return([]sriovnetworkv1.InterfaceExt{
+			{PciAddress: "0000:00:05.0", TotalVfs: 8},
+			{PciAddress: "0000:00:06.0"},
+		}, nil)
+
+		w := NewNodeStateStatusWriter(nil, nil, nil, hostHelper, nil, nil)
+		ifaces, err := w.addNestedPfs([]sriovnetworkv1.InterfaceExt{
+			{PciAddress: "0000:00:04.0", NetFilter: "openstack/NetworkID:net-a"},
+			{PciAddress: "0000:00:05.0", NetFilter: "openstack/NetworkID:net-b"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ifaces).To(Equal([]sriovnetworkv1.InterfaceExt{
+			{PciAddress: "0000:00:05.0", TotalVfs: 8},
+			{PciAddress: "0000:00:04.0", NetFilter: "openstack/NetworkID:net-a"},
+		}))
+	})
+
+	It("refreshes the status once the host events settled", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		hostHelper := mock_helper.NewMockHostHelpersInterface(mockCtrl)
+		hostHelper.EXPECT().InvalidateInventory().Times(4)
+		// the burst of events triggers a single discovery
+		hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return(
+			[]sriovnetworkv1.InterfaceExt{{Name: "ens1f0", PciAddress: "0000:86:00.0", NumVfs: 2}}, nil).Times(1)
+		hostHelper.EXPECT().GetNumHugepages().Return(0, nil).Times(1)
+
+		w := NewNodeStateStatusWriter(client, nil, er, hostHelper, nil, lldp.NewListener())
+		stop := make(chan struct{})
+		hostEvents := make(chan watch.Event, 4)
+		hostEvents <- watch.Event{Type: watch.DeviceAdded, PciAddress: "0000:86:02.0"}
+		hostEvents <- watch.Event{Type: watch.DeviceAdded, PciAddress: "0000:86:02.1"}
+		hostEvents <- watch.Event{Type: watch.NetdevAdded, Interface: "ens1f0v0"}
+		// the events lost by the kernel are caught by the same discovery
+		hostEvents <- watch.Event{Type: watch.Resync}
+		go w.Run(stop, nil, nil, hostEvents)
+		defer close(stop)
+
+		Eventually(func() sriovnetworkv1.InterfaceExts {
+			ns, err := client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			return ns.Status.Interfaces
+		}, 5*time.Second, 100*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("reports the transceiver identification without rewriting the status at each poll", func() {
+		vars.NodeName = "test-node"
+		client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace, ResourceVersion: "1"},
+		})
+		er := NewEventRecorder(client, fakek8s.NewSimpleClientset())
+		defer er.Shutdown()
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		hostHelper := mock_helper.NewMockHostHelpersInterface(mockCtrl)
+		transceiver := &sriovnetworkv1.TransceiverInfo{Vendor: "FINISAR CORP.", PartNumber: "FTLX8574D3BCL", SerialNumber: "ALJ1234"}
+		hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return([]sriovnetworkv1.InterfaceExt{
+			{Name: "ens1f0", PciAddress: "0000:86:00.0", TransceiverPresent: true, Transceiver: transceiver},
+			{Name: "ens1f1", PciAddress: "0000:86:00.1"},
+		}, nil).Times(2)
+		hostHelper.EXPECT().GetNumHugepages().Return(0, nil).Times(2)
+
+		w := NewNodeStateStatusWriter(client, nil, er, hostHelper, nil, lldp.NewListener())
+		Expect(w.pollNicStatus()).To(Succeed())
+		_, err := w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		resourceVersion := func() string {
+			ns, err := client.SriovnetworkV1().SriovNetworkNodeStates(vars.Namespace).Get(context.Background(), vars.NodeName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ns.Status.Interfaces[0].Transceiver).To(Equal(transceiver))
+			return ns.ResourceVersion
+		}
+		written := resourceVersion()
+
+		Expect(w.pollNicStatus()).To(Succeed())
+		_, err = w.setNodeStateStatus(Message{syncStatus: consts.SyncStatusSucceeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceVersion()).To(Equal(written))
+	})
+
+	Context("recoverOpenstackDevicesInfo", func() {
+		metadataErr := snerrors.Wrap(snerrors.ErrMetadataUnavailable, errors.New("no config drive nor metadata service"))
+
+		BeforeEach(func() {
+			vars.NodeName = "test-node"
+		})
+
+		It("uses the persisted devices info", func() {
+			client := fakesnclientset.NewSimpleClientset()
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+			platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+			platformHelper.EXPECT().CreateOpenstackDevicesInfoFromCheckpoint().Return(true, nil)
+
+			w := NewNodeStateStatusWriter(client, nil, nil, nil, platformHelper, nil)
+			Expect(w.recoverOpenstackDevicesInfo(metadataErr)).To(Succeed())
+			Expect(w.platformDrainRequired.Load()).To(BeFalse())
+		})
+
+		It("restores the node status and requires a drain without persisted devices info", func() {
+			ns := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{PciAddress: "0000:04:00.0",
+						NetFilter: "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7"}},
+				},
+			}
+			client := fakesnclientset.NewSimpleClientset(ns)
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+			platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+			platformHelper.EXPECT().CreateOpenstackDevicesInfoFromCheckpoint().Return(false, nil)
+			platformHelper.EXPECT().CreateOpenstackDevicesInfoFromNodeStatus(gomock.Any()).Do(
+				func(state *sriovnetworkv1.SriovNetworkNodeState) {
+					Expect(state.Status.Interfaces).To(Equal(ns.Status.Interfaces))
+				})
+
+			w := NewNodeStateStatusWriter(client, nil, nil, nil, platformHelper, nil)
+			Expect(w.recoverOpenstackDevicesInfo(metadataErr)).To(Succeed())
+			Expect(w.platformDrainRequired.Load()).To(BeTrue())
+		})
+
+		It("returns the metadata error when the node state has no interface", func() {
+			client := fakesnclientset.NewSimpleClientset(&sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: vars.NodeName, Namespace: vars.Namespace},
+			})
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+			platformHelper := mock_platforms.NewMockInterface(mockCtrl)
+			platformHelper.EXPECT().CreateOpenstackDevicesInfoFromCheckpoint().Return(false, nil)
+
+			w := NewNodeStateStatusWriter(client, nil, nil, nil, platformHelper, nil)
+			Expect(w.recoverOpenstackDevicesInfo(metadataErr)).To(MatchError(snerrors.ErrMetadataUnavailable))
+			Expect(w.platformDrainRequired.Load()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+// Package dataplane maintains the dataplane VFs file of the node, listing the VFs bound to vfio-pci with their NUMA
+// node for the userspace dataplanes of the node, e.g. Calico/VPP or OVS-DPDK, which take their VFs from the file
+// instead of discovering them. The file is replaced at once whenever the layout of the VFs changes, a dataplane
+// watching it never reads a partial layout.
+package dataplane
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// File is the content of the dataplane VFs file
+type File struct {
+	Vfs []Vf `json:"vfs"`
+}
+
+// Vf is a VF bound to vfio-pci
+type Vf struct {
+	PciAddress   string `json:"pciAddress"`
+	VfID         int    `json:"vfID"`
+	PfName       string `json:"pfName,omitempty"`
+	PfPciAddress string `json:"pfPciAddress"`
+	// NumaNode of the VF, -1 when the platform doesn't report it
+	NumaNode int `json:"numaNode"`
+	// ResourceName and PolicyName of the policy configuring the VF
+	ResourceName string `json:"resourceName"`
+	PolicyName   string `json:"policyName"`
+}
+
+// Build returns the VFs of the vfio-pci VF groups of the interfaces, the VFs not created or not bound to vfio-pci yet
+// are left out
+func Build(interfaces sriovnetworkv1.Interfaces) *File {
+	file := &File{Vfs: []Vf{}}
+	for _, iface := range interfaces {
+		for _, group := range iface.VfGroups {
+			if group.DeviceType != consts.DeviceTypeVfioPci {
+				continue
+			}
+			for vfID := 0; vfID < iface.NumVfs; vfID++ {
+				if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				pciAddress := utils.GetVfPciAddress(iface.PciAddress, vfID)
+				if pciAddress == "" || driver(pciAddress) != consts.DeviceTypeVfioPci {
+					continue
+				}
+				file.Vfs = append(file.Vfs, Vf{
+					PciAddress:   pciAddress,
+					VfID:         vfID,
+					PfName:       iface.Name,
+					PfPciAddress: iface.PciAddress,
+					NumaNode:     numaNode(pciAddress),
+					ResourceName: group.ResourceName,
+					PolicyName:   group.PolicyName,
+				})
+			}
+		}
+	}
+	sort.Slice(file.Vfs, func(i, j int) bool { return file.Vfs[i].PciAddress < file.Vfs[j].PciAddress })
+	return file
+}
+
+func devicePath(pciAddr string, elem ...string) string {
+	return filepath.Join(append([]string{vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr}, elem...)...)
+}
+
+// driver returns the driver the device is bound to, empty when unbound
+func driver(pciAddr string) string {
+	link, err := os.Readlink(devicePath(pciAddr, "driver"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
+
+// numaNode returns the NUMA node of the device, -1 when unknown
+func numaNode(pciAddr string) int {
+	data, err := os.ReadFile(devicePath(pciAddr, "numa_node"))
+	if err != nil {
+		return -1
+	}
+	node, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return -1
+	}
+	return node
+}
+
+// Sync writes the vfio-pci VFs of the interfaces to the dataplane VFs file of the host when they changed, and removes
+// the file when there is no vfio-pci VF
+func Sync(interfaces sriovnetworkv1.Interfaces) error {
+	path := utils.GetHostExtensionPath(consts.DataplaneVfsFile)
+	file := Build(interfaces)
+	if len(file.Vfs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the dataplane VFs file: %v", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the dataplane VFs file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package dataplane
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func useFakeFilesystem(t *testing.T, fs *fakefilesystem.FS) string {
+	root, clean, err := fs.Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+	return root
+}
+
+func TestSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := useFakeFilesystem(t, &fakefilesystem.FS{
+		Dirs: []string{
+			consts.SriovConfBasePath,
+			"sys/bus/pci/devices/0000:3b:00.0",
+			"sys/bus/pci/devices/0000:3b:02.0",
+			"sys/bus/pci/devices/0000:3b:02.1",
+			"sys/bus/pci/devices/0000:3b:02.2",
+			"sys/bus/pci/drivers/vfio-pci",
+			"sys/bus/pci/drivers/iavf",
+		},
+		Symlinks: map[string]string{
+			"sys/bus/pci/devices/0000:3b:00.0/virtfn0": "../0000:3b:02.0",
+			"sys/bus/pci/devices/0000:3b:00.0/virtfn1": "../0000:3b:02.1",
+			"sys/bus/pci/devices/0000:3b:00.0/virtfn2": "../0000:3b:02.2",
+			"sys/bus/pci/devices/0000:3b:02.0/driver":  "../../drivers/vfio-pci",
+			"sys/bus/pci/devices/0000:3b:02.1/driver":  "../../drivers/vfio-pci",
+			"sys/bus/pci/devices/0000:3b:02.2/driver":  "../../drivers/iavf",
+		},
+		Files: map[string][]byte{
+			"sys/bus/pci/devices/0000:3b:02.0/numa_node": []byte("1\n"),
+		},
+	})
+	file := filepath.Join(root, consts.DataplaneVfsFile)
+
+	interfaces := sriovnetworkv1.Interfaces{{
+		PciAddress: "0000:3b:00.0",
+		Name:       "ens1f0",
+		NumVfs:     4,
+		VfGroups: []sriovnetworkv1.VfGroup{
+			{ResourceName: "vpp", PolicyName: "policy-vpp", VfRange: "0-2", DeviceType: consts.DeviceTypeVfioPci},
+			{ResourceName: "intel", PolicyName: "policy-intel", VfRange: "3-3", DeviceType: consts.DeviceTypeNetDevice},
+		},
+	}}
+	g.Expect(Sync(interfaces)).To(Succeed())
+	data, err := os.ReadFile(file)
+	g.Expect(err).ToNot(HaveOccurred())
+	read := &File{}
+	g.Expect(json.Unmarshal(data, read)).To(Succeed())
+	// the VF 2 is still bound to its kernel driver
+	g.Expect(read.Vfs).To(Equal([]Vf{
+		{PciAddress: "0000:3b:02.0", VfID: 0, PfName: "ens1f0", PfPciAddress: "0000:3b:00.0", NumaNode: 1,
+			ResourceName: "vpp", PolicyName: "policy-vpp"},
+		{PciAddress: "0000:3b:02.1", VfID: 1, PfName: "ens1f0", PfPciAddress: "0000:3b:00.0", NumaNode: -1,
+			ResourceName: "vpp", PolicyName: "policy-vpp"},
+	}))
+
+	// the file is removed with the last vfio-pci VF
+	interfaces[0].VfGroups = interfaces[0].VfGroups[1:]
+	g.Expect(Sync(interfaces)).To(Succeed())
+	g.Expect(file).ToNot(BeAnExistingFile())
+	g.Expect(Sync(interfaces)).To(Succeed())
+}
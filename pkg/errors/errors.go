@@ -0,0 +1,138 @@
+// Package errors defines the taxonomy of the errors of the SR-IOV configuration. Each error has a
+// machine-readable reason, reported in the SriovNetworkNodeState status and as the reason of the
+// events, so that automation can react to specific failures instead of parsing the error messages.
+//
+// The errors are matched with errors.Is, the functions returning them wrap the error of the failed
+// operation with Wrap or fmt.Errorf("...: %w", ErrX) to keep its message.
+package errors
+
+import (
+	"errors"
+)
+
+// Reason is the machine-readable reason of an error
+type Reason string
+
+const (
+	// ReasonUnknown is the reason of the errors outside of the taxonomy
+	ReasonUnknown Reason = "Unknown"
+
+	ReasonUnsupportedNic            Reason = "UnsupportedNic"
+	ReasonFirmwareTooOld            Reason = "FirmwareTooOld"
+	ReasonVfInUse                   Reason = "VfInUse"
+	ReasonMetadataUnavailable       Reason = "MetadataUnavailable"
+	ReasonSriovDisabled             Reason = "SriovDisabled"
+	ReasonIommuDisabled             Reason = "IommuDisabled"
+	ReasonKernelModuleMissing       Reason = "KernelModuleMissing"
+	ReasonKernelLockdown            Reason = "KernelLockdown"
+	ReasonExternallyManagedMismatch Reason = "ExternallyManagedMismatch"
+	ReasonDrainFailed               Reason = "DrainFailed"
+	ReasonDrainAborted              Reason = "DrainAborted"
+	ReasonHookFailed                Reason = "HookFailed"
+	ReasonPtpTimeSource             Reason = "PtpTimeSource"
+	ReasonIncompatibleSpecVersion   Reason = "IncompatibleSpecVersion"
+	ReasonPciAddressGuarded         Reason = "PciAddressGuarded"
+	ReasonInterfaceClaimed          Reason = "InterfaceClaimed"
+	ReasonNMStatePending            Reason = "NMStatePending"
+)
+
+var (
+	// ErrUnsupportedNic is returned when the NIC can't provide the requested configuration, e.g. more VFs than it supports
+	ErrUnsupportedNic = errors.New("unsupported NIC")
+	// ErrFirmwareTooOld is returned when the NIC firmware is older than the minimum version of its driver
+	ErrFirmwareTooOld = errors.New("firmware too old")
+	// ErrVfInUse is returned when the VFs of a PF can't be changed because they are in use
+	ErrVfInUse = errors.New("VF in use")
+	// ErrMetadataUnavailable is returned when the metadata of the virtual platform can't be read
+	ErrMetadataUnavailable = errors.New("metadata unavailable")
+	// ErrSriovDisabled is returned when SR-IOV is disabled for a NIC, usually in the BIOS
+	ErrSriovDisabled = errors.New("SR-IOV disabled")
+	// ErrIommuDisabled is returned when the IOMMU is disabled
+	ErrIommuDisabled = errors.New("IOMMU disabled")
+	// ErrKernelModuleMissing is returned when a required kernel module is not available
+	ErrKernelModuleMissing = errors.New("kernel module missing")
+	// ErrKernelLockdown is returned when the kernel lockdown mode prevents the configuration of a NIC
+	ErrKernelLockdown = errors.New("kernel lockdown")
+	// ErrExternallyManagedMismatch is returned when an externally managed PF doesn't provide the requested configuration
+	ErrExternallyManagedMismatch = errors.New("externally managed PF mismatch")
+	// ErrDrainFailed is returned when the node can't be drained
+	ErrDrainFailed = errors.New("drain failed")
+	// ErrDrainAborted is returned when the drain escalation aborted a drain that didn't complete
+	ErrDrainAborted = errors.New("drain aborted")
+	// ErrHookFailed is returned when a configuration hook with the Fail policy failed
+	ErrHookFailed = errors.New("hook failed")
+	// ErrPtpTimeSource is returned when a disruptive change of a PF is refused because it is the PTP time source of the node
+	ErrPtpTimeSource = errors.New("PTP time source")
+	// ErrIncompatibleSpecVersion is returned when the node state was rendered by an operator version whose spec
+	// semantics are not supported by the config daemon
+	ErrIncompatibleSpecVersion = errors.New("incompatible spec version")
+	// ErrPciAddressGuarded is returned when a PCI device outside of the allowlist, or in the denylist, of the node
+	// would be changed
+	ErrPciAddressGuarded = errors.New("PCI address guarded")
+	// ErrInterfaceClaimed is returned when an interface claimed by another host network manager would be changed
+	ErrInterfaceClaimed = errors.New("interface claimed")
+	// ErrNMStatePending is returned when the PF settings delegated to kubernetes-nmstate are not applied yet, e.g.
+	// while the node boots
+	ErrNMStatePending = errors.New("NMState settings pending")
+)
+
+// reasons maps the errors of the taxonomy to their reason, the first match wins
+var reasons = []struct {
+	err    error
+	reason Reason
+}{
+	{ErrUnsupportedNic, ReasonUnsupportedNic},
+	{ErrFirmwareTooOld, ReasonFirmwareTooOld},
+	{ErrVfInUse, ReasonVfInUse},
+	{ErrMetadataUnavailable, ReasonMetadataUnavailable},
+	{ErrSriovDisabled, ReasonSriovDisabled},
+	{ErrIommuDisabled, ReasonIommuDisabled},
+	{ErrKernelModuleMissing, ReasonKernelModuleMissing},
+	{ErrKernelLockdown, ReasonKernelLockdown},
+	{ErrExternallyManagedMismatch, ReasonExternallyManagedMismatch},
+	// an aborted drain is also a failed drain
+	{ErrDrainAborted, ReasonDrainAborted},
+	{ErrDrainFailed, ReasonDrainFailed},
+	{ErrHookFailed, ReasonHookFailed},
+	{ErrPtpTimeSource, ReasonPtpTimeSource},
+	{ErrIncompatibleSpecVersion, ReasonIncompatibleSpecVersion},
+	{ErrPciAddressGuarded, ReasonPciAddressGuarded},
+	{ErrInterfaceClaimed, ReasonInterfaceClaimed},
+	{ErrNMStatePending, ReasonNMStatePending},
+}
+
+// ReasonOf returns the reason of the error, ReasonUnknown if it is outside of the taxonomy and an
+// empty reason for a nil error
+func ReasonOf(err error) Reason {
+	if err == nil {
+		return ""
+	}
+	for _, r := range reasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return ReasonUnknown
+}
+
+// Wrap returns an error matching both the error of the taxonomy and err with errors.Is, with the
+// message of err. A nil err is returned as is.
+func Wrap(taxonomyErr, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &wrappedError{taxonomyErr: taxonomyErr, err: err}
+}
+
+type wrappedError struct {
+	taxonomyErr error
+	err         error
+}
+
+func (e *wrappedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wrappedError) Unwrap() []error {
+	return []error{e.err, e.taxonomyErr}
+}
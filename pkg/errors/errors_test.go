@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestReasonOf(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(ReasonOf(nil)).To(BeEmpty())
+	g.Expect(ReasonOf(errors.New("boom"))).To(Equal(ReasonUnknown))
+	g.Expect(ReasonOf(ErrVfInUse)).To(Equal(ReasonVfInUse))
+	g.Expect(ReasonOf(fmt.Errorf("GetOpenStackData(): %w", ErrMetadataUnavailable))).To(Equal(ReasonMetadataUnavailable))
+	// the reason survives further wrapping
+	g.Expect(ReasonOf(fmt.Errorf("sync failed: %w", Wrap(ErrDrainFailed, errors.New("eviction timeout"))))).
+		To(Equal(ReasonDrainFailed))
+	// an aborted drain takes precedence over the failed drain wrapping it
+	g.Expect(ReasonOf(Wrap(ErrDrainFailed, Wrap(ErrDrainAborted, errors.New("aborted after 60 minutes"))))).
+		To(Equal(ReasonDrainAborted))
+}
+
+func TestWrap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(Wrap(ErrVfInUse, nil)).To(BeNil())
+
+	cause := &fs.PathError{Op: "write", Path: "sriov_numvfs", Err: syscall.EBUSY}
+	err := Wrap(ErrVfInUse, cause)
+	g.Expect(err.Error()).To(Equal(cause.Error()))
+	g.Expect(errors.Is(err, ErrVfInUse)).To(BeTrue())
+	g.Expect(errors.Is(err, syscall.EBUSY)).To(BeTrue())
+	g.Expect(errors.Is(err, ErrDrainFailed)).To(BeFalse())
+}
@@ -0,0 +1,157 @@
+package fabric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// VlanRequest is the payload sent to the validation hook
+type VlanRequest struct {
+	Network          string `json:"network"`
+	NetworkNamespace string `json:"networkNamespace"`
+	ResourceName     string `json:"resourceName"`
+	Vlan             int    `json:"vlan"`
+	VlanQoS          int    `json:"vlanQoS,omitempty"`
+	VlanProto        string `json:"vlanProto,omitempty"`
+}
+
+// VlanResponse is the answer expected from the validation hook
+type VlanResponse struct {
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message,omitempty"`
+}
+
+// VlanValidator validates a VLAN against the fabric before it is used in a NetworkAttachmentDefinition
+type VlanValidator interface {
+	ValidateVlan(ctx context.Context, req *VlanRequest) (*VlanResponse, error)
+}
+
+// NewVlanValidator returns the validator for the hook configured in the SriovOperatorConfig
+func NewVlanValidator(cfg *sriovnetworkv1.VlanValidationConfig) (VlanValidator, error) {
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	switch {
+	case cfg.URL != "" && len(cfg.Command) > 0:
+		return nil, fmt.Errorf("only one of url or command can be set for the vlan validation hook")
+	case cfg.URL != "":
+		return &httpValidator{url: cfg.URL, client: &http.Client{Timeout: timeout}}, nil
+	case len(cfg.Command) > 0:
+		return &execValidator{command: cfg.Command, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("one of url or command must be set for the vlan validation hook")
+	}
+}
+
+// ValidateNetworkVlan checks the VLAN of the network with the hook, the errors of the hook are ignored when its
+// failure policy is Ignore. The networks without VLAN are not checked.
+func ValidateNetworkVlan(ctx context.Context, cfg *sriovnetworkv1.VlanValidationConfig, network *sriovnetworkv1.SriovNetwork) error {
+	if cfg == nil || network.Spec.Vlan == 0 {
+		return nil
+	}
+	validator, err := NewVlanValidator(cfg)
+	if err != nil {
+		return err
+	}
+	resp, err := validator.ValidateVlan(ctx, &VlanRequest{
+		Network:          network.Name,
+		NetworkNamespace: network.Spec.NetworkNamespace,
+		ResourceName:     network.Spec.ResourceName,
+		Vlan:             network.Spec.Vlan,
+		VlanQoS:          network.Spec.VlanQoS,
+		VlanProto:        network.Spec.VlanProto,
+	})
+	if err != nil {
+		if cfg.FailurePolicy == consts.FailurePolicyIgnore {
+			log.FromContext(ctx).Error(err, "VLAN validation hook failed, ignoring", "vlan", network.Spec.Vlan)
+			return nil
+		}
+		return err
+	}
+	if !resp.Allowed {
+		return fmt.Errorf("vlan %d is rejected by the fabric: %s", network.Spec.Vlan, resp.Message)
+	}
+	return nil
+}
+
+// httpValidator POSTs the request as JSON to the hook URL
+type httpValidator struct {
+	url    string
+	client *http.Client
+}
+
+func (v *httpValidator) ValidateVlan(ctx context.Context, req *VlanRequest) (*VlanResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call vlan validation hook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vlan validation hook returned %d: %s", resp.StatusCode, string(data))
+	}
+	return decodeResponse(data)
+}
+
+// execValidator runs the hook command with the request as JSON on stdin
+// and reads the response from stdout
+type execValidator struct {
+	command []string
+	timeout time.Duration
+}
+
+func (v *execValidator) ValidateVlan(ctx context.Context, req *VlanRequest) (*VlanResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, v.command[0], v.command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("vlan validation hook command failed: %v: %s", err, stderr.String())
+	}
+	return decodeResponse(stdout.Bytes())
+}
+
+func decodeResponse(data []byte) (*VlanResponse, error) {
+	resp := &VlanResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("failed to decode vlan validation hook response: %v", err)
+	}
+	return resp, nil
+}
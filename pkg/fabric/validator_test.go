@@ -0,0 +1,78 @@
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func TestNewVlanValidator(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{})
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{URL: "http://fabric", Command: []string{"/bin/true"}})
+	g.Expect(err).To(HaveOccurred())
+
+	v, err := NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{URL: "http://fabric"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(BeAssignableToTypeOf(&httpValidator{}))
+
+	v, err = NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{Command: []string{"/bin/true"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(BeAssignableToTypeOf(&execValidator{}))
+}
+
+func TestHTTPValidator(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &VlanRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp := VlanResponse{Allowed: req.Vlan == 100}
+		if !resp.Allowed {
+			resp.Message = "vlan not provisioned on the ToR"
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	v, err := NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{URL: server.URL})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	resp, err := v.ValidateVlan(context.Background(), &VlanRequest{Network: "net", Vlan: 100})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.Allowed).To(BeTrue())
+
+	resp, err = v.ValidateVlan(context.Background(), &VlanRequest{Network: "net", Vlan: 200})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(resp.Message).To(Equal("vlan not provisioned on the ToR"))
+}
+
+func TestExecValidator(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	v, err := NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{
+		Command: []string{"/bin/sh", "-c", `cat >/dev/null; echo '{"allowed": true}'`},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	resp, err := v.ValidateVlan(context.Background(), &VlanRequest{Network: "net", Vlan: 100})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.Allowed).To(BeTrue())
+
+	v, err = NewVlanValidator(&sriovnetworkv1.VlanValidationConfig{Command: []string{"/bin/false"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = v.ValidateVlan(context.Background(), &VlanRequest{Network: "net", Vlan: 100})
+	g.Expect(err).To(HaveOccurred())
+}
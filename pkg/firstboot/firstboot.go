@@ -0,0 +1,424 @@
+// Package firstboot renders the initial SR-IOV configuration of the nodes of a profile from the policies, as the files
+// and systemd units of an Ignition config or of a bootc image. A freshly provisioned node applies it at its first boot,
+// before the config daemon starts: the daemon finds the VFs, the vfio-pci module and the kernel arguments of its node
+// state in place and doesn't reboot the node for its first sync. The daemon configures the node from then on.
+package firstboot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/openshift/machine-config-operator/pkg/controller/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	sriovnetworkv2 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v2"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/pfrename"
+)
+
+const (
+	ScriptPath   = "/usr/local/bin/sriov-firstboot.sh"
+	UnitName     = "sriov-firstboot.service"
+	unitWantedBy = "multi-user.target"
+	// kargsPath is the kernel arguments file of bootc, applied when the image is installed
+	kargsPath = "/usr/lib/bootc/kargs.d/10-sriov-network-operator.toml"
+)
+
+// Profile describes the nodes provisioned with the initial configuration: the labels matched by the nodeSelector of
+// the policies, and the SR-IOV devices of the nodes as reported in the status of the SriovNetworkNodeState of a node
+// with the same hardware
+type Profile struct {
+	Labels     map[string]string            `json:"labels"`
+	Interfaces sriovnetworkv1.InterfaceExts `json:"interfaces"`
+}
+
+// File is a file of the initial configuration
+type File struct {
+	Path     string
+	Mode     int
+	Contents string
+}
+
+// Unit is a systemd unit of the initial configuration, enabled for the target
+type Unit struct {
+	Name     string
+	Contents string
+	WantedBy string
+}
+
+// Config is the initial configuration of the nodes of a profile
+type Config struct {
+	Files []File
+	Units []Unit
+	// KernelArgs required by the configuration, an Ignition config can't set them
+	KernelArgs []string
+}
+
+// LoadPolicies reads the SriovNetworkNodePolicies of the YAML or JSON documents, as single policies or as lists like
+// the output of kubectl get -o yaml. The v2 policies are converted to v1.
+func LoadPolicies(r io.Reader) ([]sriovnetworkv1.SriovNetworkNodePolicy, error) {
+	policies := []sriovnetworkv1.SriovNetworkNodePolicy{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return policies, nil
+			}
+			return nil, fmt.Errorf("failed to parse the policies: %v", err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		doc := struct {
+			metav1.TypeMeta `json:",inline"`
+			Items           []json.RawMessage `json:"items"`
+		}{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse the policies: %v", err)
+		}
+		items := []json.RawMessage{raw}
+		if strings.HasSuffix(doc.Kind, "List") {
+			items = doc.Items
+		}
+		for _, item := range items {
+			policy, err := decodePolicy(item)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, *policy)
+		}
+	}
+}
+
+func decodePolicy(raw json.RawMessage) (*sriovnetworkv1.SriovNetworkNodePolicy, error) {
+	meta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse the policy: %v", err)
+	}
+	if meta.Kind != "SriovNetworkNodePolicy" {
+		return nil, fmt.Errorf("unexpected object of kind %q, only SriovNetworkNodePolicies are supported", meta.Kind)
+	}
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	switch meta.APIVersion {
+	case sriovnetworkv1.GroupVersion.String():
+		if err := json.Unmarshal(raw, policy); err != nil {
+			return nil, fmt.Errorf("failed to parse the policy: %v", err)
+		}
+	case sriovnetworkv2.GroupVersion.String():
+		v2 := &sriovnetworkv2.SriovNetworkNodePolicy{}
+		if err := json.Unmarshal(raw, v2); err != nil {
+			return nil, fmt.Errorf("failed to parse the policy: %v", err)
+		}
+		if err := v2.ConvertTo(policy); err != nil {
+			return nil, fmt.Errorf("failed to convert the policy %s: %v", v2.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported apiVersion %q of the policy", meta.APIVersion)
+	}
+	return policy, nil
+}
+
+// RenderSpec returns the interfaces of the spec of the node state the policies render for the nodes of the profile,
+// the same way the operator renders them
+func RenderSpec(policies []sriovnetworkv1.SriovNetworkNodePolicy, profile *Profile) (sriovnetworkv1.Interfaces, error) {
+	policies = append([]sriovnetworkv1.SriovNetworkNodePolicy{}, policies...)
+	sort.Sort(sriovnetworkv1.ByPriority(policies))
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: profile.Labels}}
+	state := &sriovnetworkv1.SriovNetworkNodeState{}
+	state.Status.Interfaces = profile.Interfaces
+	renaming := []sriovnetworkv1.SriovNetworkNodePolicy{}
+
+	// the policies of the same priority are merged, see the node state controller
+	ppp := 100
+	for i := range policies {
+		p := &policies[i]
+		if p.Name == consts.DefaultPolicyName || !p.Selected(node) || p.Spec.ExposePfs {
+			continue
+		}
+		if err := p.Apply(state, ppp == p.Spec.Priority); err != nil {
+			return nil, fmt.Errorf("failed to apply the policy %s: %v", p.Name, err)
+		}
+		if p.Spec.PfNamePrefix != "" {
+			renaming = append(renaming, *p)
+		}
+		ppp = p.Spec.Priority
+	}
+	state.Spec.Interfaces.SetPfNames(sriovnetworkv1.PfNames(renaming, profile.Interfaces))
+	return state.Spec.Interfaces, nil
+}
+
+// Render returns the initial configuration of the nodes of the profile. It covers the VFs of the PFs in legacy mode,
+// their MTU and the binding of the vfio-pci VFs, the names of the renamed PFs, and the options and kernel arguments
+// of vfio-pci. The switchdev, externally managed and split PFs and the vDPA devices are left to the config daemon.
+func Render(policies []sriovnetworkv1.SriovNetworkNodePolicy, profile *Profile) (*Config, error) {
+	interfaces, err := RenderSpec(policies, profile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	pfs := []scriptPf{}
+	renames := map[string]pfrename.Rename{}
+	vfio := false
+	for _, iface := range interfaces {
+		if iface.ExternallyManaged || iface.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev || iface.PortSplit > 0 {
+			continue
+		}
+		pf := scriptPf{PciAddress: iface.PciAddress, NumVfs: iface.NumVfs, Mtu: iface.Mtu,
+			DriversAutoprobe: !iface.DisableDriversAutoprobe}
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			for _, group := range iface.VfGroups {
+				if group.VdpaType != "" || !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				vf := scriptVf{ID: vfID}
+				if group.DeviceType == consts.DeviceTypeVfioPci {
+					vf.Vfio, vfio = true, true
+				} else if group.Mtu > 0 {
+					vf.Mtu = group.Mtu
+				}
+				if vf.Vfio || vf.Mtu > 0 {
+					pf.Vfs = append(pf.Vfs, vf)
+				}
+				break
+			}
+		}
+		pfs = append(pfs, pf)
+
+		if iface.RenameTo != "" && iface.RenameTo != iface.Name {
+			renames[iface.PciAddress] = pfrename.Rename{OriginalName: iface.Name, Name: iface.RenameTo}
+			cfg.Files = append(cfg.Files, File{
+				Path:     fmt.Sprintf("%s/30-pf-rename-%s.rules", consts.UdevRulesFolder, iface.PciAddress),
+				Mode:     0644,
+				Contents: fmt.Sprintf(consts.PfRenameUdevRule, iface.PciAddress, iface.RenameTo),
+			})
+		}
+	}
+
+	script := &bytes.Buffer{}
+	if err := scriptTemplate.Execute(script, pfs); err != nil {
+		return nil, err
+	}
+	cfg.Files = append(cfg.Files, File{Path: ScriptPath, Mode: 0755, Contents: script.String()})
+	cfg.Units = append(cfg.Units, Unit{Name: UnitName, Contents: unit, WantedBy: unitWantedBy})
+
+	// the config daemon reads the original names of the renamed PFs from the rename map
+	if len(renames) > 0 {
+		data, err := json.MarshalIndent(renames, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		cfg.Files = append(cfg.Files, File{Path: consts.PfRenamesFile, Mode: 0644, Contents: string(data)})
+	}
+
+	if vfio {
+		cfg.Files = append(cfg.Files,
+			File{Path: "/etc/modules-load.d/sriov-network-operator-vfio_pci.conf", Mode: 0644, Contents: consts.VfioPciModule + "\n"})
+		if options := sriovnetworkv1.VfioPciModuleOptions(interfaces); options != nil {
+			cfg.Files = append(cfg.Files, File{Path: filepath.Join(consts.ModprobeConfDir, "sriov-network-operator-"+consts.VfioPciModule+".conf"),
+				Mode: 0644, Contents: modprobeConf(consts.VfioPciModule, options)})
+		}
+		cfg.KernelArgs = []string{consts.KernelArgIntelIommu, consts.KernelArgIommuPt}
+		if profile.Labels[corev1.LabelArchStable] == consts.ArchARM64 {
+			cfg.KernelArgs = []string{consts.KernelArgIommuPassthrough}
+		}
+	}
+	sort.Slice(cfg.Files, func(i, j int) bool { return cfg.Files[i].Path < cfg.Files[j].Path })
+	return cfg, nil
+}
+
+// modprobeConf returns the modprobe.d file of the options of the kernel module, the same the config daemon writes
+func modprobeConf(module string, options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	line := "options " + module
+	for _, name := range names {
+		line += " " + name + "=" + options[name]
+	}
+	return "# managed by the sriov-network-operator\n" + line + "\n"
+}
+
+// Ignition returns the Ignition config of the initial configuration, in JSON
+func (c *Config) Ignition() ([]byte, error) {
+	files := []string{}
+	for _, f := range c.Files {
+		data, err := json.Marshal(map[string]interface{}{
+			"path":     f.Path,
+			"mode":     f.Mode,
+			"contents": map[string]string{"inline": f.Contents},
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, string(data))
+	}
+	units := []string{}
+	for _, u := range c.Units {
+		data, err := json.Marshal(map[string]interface{}{"name": u.Name, "enabled": true, "contents": u.Contents})
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, string(data))
+	}
+	ignCfg, err := common.TranspileCoreOSConfigToIgn(files, units)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transpile the initial configuration to an Ignition config: %v", err)
+	}
+	ignCfg.Ignition.Version = "3.2.0"
+	return json.MarshalIndent(ignCfg, "", "  ")
+}
+
+// WriteTree writes the initial configuration under the root directory, to be copied to the root of a bootc image.
+// The units are enabled and the kernel arguments are written to the kargs.d directory of bootc.
+func (c *Config) WriteTree(root string) error {
+	for _, f := range c.Files {
+		if err := writeFile(filepath.Join(root, f.Path), []byte(f.Contents), os.FileMode(f.Mode)); err != nil {
+			return err
+		}
+	}
+	for _, u := range c.Units {
+		path := filepath.Join(root, "/etc/systemd/system", u.Name)
+		if err := writeFile(path, []byte(u.Contents), 0644); err != nil {
+			return err
+		}
+		wants := filepath.Join(root, "/etc/systemd/system", u.WantedBy+".wants")
+		if err := os.MkdirAll(wants, 0755); err != nil {
+			return err
+		}
+		link := filepath.Join(wants, u.Name)
+		if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Symlink(filepath.Join("..", u.Name), link); err != nil {
+			return fmt.Errorf("failed to enable %s: %v", u.Name, err)
+		}
+	}
+	if len(c.KernelArgs) > 0 {
+		kargs := "# managed by the sriov-network-operator\nkargs = [\"" + strings.Join(c.KernelArgs, "\", \"") + "\"]\n"
+		if err := writeFile(filepath.Join(root, kargsPath), []byte(kargs), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return os.Chmod(path, mode)
+}
+
+type scriptPf struct {
+	PciAddress       string
+	NumVfs           int
+	Mtu              int
+	DriversAutoprobe bool
+	Vfs              []scriptVf
+}
+
+type scriptVf struct {
+	ID   int
+	Vfio bool
+	Mtu  int
+}
+
+// unit runs the script once, until the config daemon configures the node: the daemon creates the directory of the
+// applied spec of the PFs when it starts
+const unit = `[Unit]
+Description=Configures SRIOV NIC - first boot configuration
+ConditionPathExists=!` + consts.PfAppliedConfig + `
+DefaultDependencies=no
+After=systemd-udev-settle.service systemd-modules-load.service
+Before=network-pre.target kubelet.service
+Wants=network-pre.target
+
+[Service]
+Type=oneshot
+ExecStart=` + ScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=` + unitWantedBy + `
+`
+
+var scriptTemplate = template.Must(template.New("script").Parse(`#!/bin/bash
+# rendered by the sriov-network-operator, configures the PFs until the config daemon starts
+set -eu
+
+pci_devices=/sys/bus/pci/devices
+
+netdev() {
+  ls "$pci_devices/$1/net" 2>/dev/null | head -n1
+}
+
+configure_pf() {
+  local pci=$1 numvfs=$2 mtu=$3 autoprobe=$4
+  if [ ! -e "$pci_devices/$pci/sriov_numvfs" ]; then
+    echo "PF $pci not found, skipped"
+    return 0
+  fi
+  if [ "$mtu" -gt 0 ]; then
+    ip link set dev "$(netdev "$pci")" mtu "$mtu"
+  fi
+  if [ "$(cat "$pci_devices/$pci/sriov_numvfs")" != "$numvfs" ]; then
+    echo 0 > "$pci_devices/$pci/sriov_numvfs"
+    if [ -e "$pci_devices/$pci/sriov_drivers_autoprobe" ]; then
+      echo "$autoprobe" > "$pci_devices/$pci/sriov_drivers_autoprobe"
+    fi
+    echo "$numvfs" > "$pci_devices/$pci/sriov_numvfs"
+  fi
+}
+
+vf_pci() {
+  basename "$(readlink "$pci_devices/$1/virtfn$2")"
+}
+
+bind_vfio() {
+  local vf
+  vf=$(vf_pci "$1" "$2")
+  echo vfio-pci > "$pci_devices/$vf/driver_override"
+  if [ -e "$pci_devices/$vf/driver" ]; then
+    echo "$vf" > "$pci_devices/$vf/driver/unbind"
+  fi
+  echo "$vf" > /sys/bus/pci/drivers_probe
+}
+
+set_vf_mtu() {
+  local vf
+  vf=$(vf_pci "$1" "$2")
+  ip link set dev "$(netdev "$vf")" mtu "$3"
+}
+{{ range . }}
+configure_pf {{ .PciAddress }} {{ .NumVfs }} {{ .Mtu }} {{ if .DriversAutoprobe }}1{{ else }}0{{ end }}
+{{- if .Vfs }}
+if [ -e "$pci_devices/{{ .PciAddress }}/sriov_numvfs" ]; then
+  udevadm settle
+{{- $pf := .PciAddress }}
+{{- range .Vfs }}
+{{- if .Vfio }}
+  bind_vfio {{ $pf }} {{ .ID }}
+{{- else }}
+  set_vf_mtu {{ $pf }} {{ .ID }} {{ .Mtu }}
+{{- end }}
+{{- end }}
+fi
+{{- end }}
+{{ end -}}
+`))
@@ -0,0 +1,179 @@
+package firstboot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+const policies = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: sriovnetwork.openshift.io/v1
+  kind: SriovNetworkNodePolicy
+  metadata:
+    name: netdevice
+  spec:
+    resourceName: netdevice
+    nodeSelector:
+      node-role.kubernetes.io/worker: ""
+    numVfs: 4
+    mtu: 9000
+    pfNamePrefix: sriov
+    nicSelector:
+      pfNames: ["ens1f0#0-1"]
+- apiVersion: sriovnetwork.openshift.io/v1
+  kind: SriovNetworkNodePolicy
+  metadata:
+    name: dpdk
+  spec:
+    resourceName: dpdk
+    nodeSelector:
+      node-role.kubernetes.io/worker: ""
+    numVfs: 4
+    deviceType: vfio-pci
+    nicSelector:
+      pfNames: ["ens1f0#2-3"]
+---
+apiVersion: sriovnetwork.openshift.io/v1
+kind: SriovNetworkNodePolicy
+metadata:
+  name: other-nodes
+spec:
+  resourceName: other
+  nodeSelector:
+    node-role.kubernetes.io/edge: ""
+  numVfs: 8
+  nicSelector:
+    pfNames: ["ens1f1"]
+`
+
+func testProfile() *Profile {
+	return &Profile{
+		Labels: map[string]string{"node-role.kubernetes.io/worker": ""},
+		Interfaces: sriovnetworkv1.InterfaceExts{
+			{Name: "ens1f0", PciAddress: "0000:3b:00.0", Vendor: "8086", DeviceID: "158b", TotalVfs: 64, Driver: "i40e"},
+			{Name: "ens1f1", PciAddress: "0000:3b:00.1", Vendor: "8086", DeviceID: "158b", TotalVfs: 64, Driver: "i40e"},
+		},
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	g := NewGomegaWithT(t)
+	loaded, err := LoadPolicies(strings.NewReader(policies))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(loaded).To(HaveLen(3))
+	g.Expect(loaded[0].Name).To(Equal("netdevice"))
+	g.Expect(loaded[2].Name).To(Equal("other-nodes"))
+
+	_, err = LoadPolicies(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRender(t *testing.T) {
+	g := NewGomegaWithT(t)
+	loaded, err := LoadPolicies(strings.NewReader(policies))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := Render(loaded, testProfile())
+	g.Expect(err).ToNot(HaveOccurred())
+	files := map[string]File{}
+	for _, f := range cfg.Files {
+		files[f.Path] = f
+	}
+	g.Expect(files).To(HaveKey(ScriptPath))
+	script := files[ScriptPath].Contents
+	// the PF of the policies of the other nodes is left out
+	g.Expect(script).To(ContainSubstring("configure_pf 0000:3b:00.0 4 9000 1\n"))
+	g.Expect(script).ToNot(ContainSubstring("0000:3b:00.1"))
+	g.Expect(script).To(ContainSubstring("  set_vf_mtu 0000:3b:00.0 1 9000\n  bind_vfio 0000:3b:00.0 2\n  bind_vfio 0000:3b:00.0 3\n"))
+
+	g.Expect(files["/etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules"].Contents).To(ContainSubstring(`NAME="sriov3b00f0"`))
+	g.Expect(files["/etc/sriov-operator/pf-renames.json"].Contents).To(MatchJSON(
+		`{"0000:3b:00.0": {"originalName": "ens1f0", "name": "sriov3b00f0"}}`))
+	// the options of vfio_pci are only managed when a policy requests one
+	g.Expect(files).ToNot(HaveKey("/etc/modprobe.d/sriov-network-operator-vfio_pci.conf"))
+	g.Expect(files).To(HaveKey("/etc/modules-load.d/sriov-network-operator-vfio_pci.conf"))
+	g.Expect(cfg.KernelArgs).To(Equal([]string{"intel_iommu=on", "iommu=pt"}))
+	g.Expect(cfg.Units).To(HaveLen(1))
+	g.Expect(cfg.Units[0].Contents).To(ContainSubstring("ConditionPathExists=!/etc/sriov-operator/pci\n"))
+}
+
+func TestRenderSkipsSwitchdevAndExternallyManagedPfs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	loaded, err := LoadPolicies(strings.NewReader(policies))
+	g.Expect(err).ToNot(HaveOccurred())
+	loaded[0].Spec.EswitchMode = sriovnetworkv1.ESwithModeSwitchDev
+	loaded[1].Spec.ExternallyManaged = true
+
+	cfg, err := Render(loaded, testProfile())
+	g.Expect(err).ToNot(HaveOccurred())
+	for _, f := range cfg.Files {
+		g.Expect(f.Contents).ToNot(ContainSubstring("0000:3b:00.0"), f.Path)
+	}
+	g.Expect(cfg.KernelArgs).To(BeEmpty())
+}
+
+func TestIgnition(t *testing.T) {
+	g := NewGomegaWithT(t)
+	loaded, err := LoadPolicies(strings.NewReader(policies))
+	g.Expect(err).ToNot(HaveOccurred())
+	cfg, err := Render(loaded, testProfile())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	out, err := cfg.Ignition()
+	g.Expect(err).ToNot(HaveOccurred())
+	ign := struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+		Storage struct {
+			Files []struct {
+				Path string `json:"path"`
+				Mode int    `json:"mode"`
+			} `json:"files"`
+		} `json:"storage"`
+		Systemd struct {
+			Units []struct {
+				Name    string `json:"name"`
+				Enabled bool   `json:"enabled"`
+			} `json:"units"`
+		} `json:"systemd"`
+	}{}
+	g.Expect(json.Unmarshal(out, &ign)).To(Succeed())
+	g.Expect(ign.Ignition.Version).To(Equal("3.2.0"))
+	g.Expect(ign.Storage.Files).To(HaveLen(len(cfg.Files)))
+	g.Expect(ign.Systemd.Units).To(HaveLen(1))
+	g.Expect(ign.Systemd.Units[0].Name).To(Equal(UnitName))
+	g.Expect(ign.Systemd.Units[0].Enabled).To(BeTrue())
+}
+
+func TestWriteTree(t *testing.T) {
+	g := NewGomegaWithT(t)
+	loaded, err := LoadPolicies(strings.NewReader(policies))
+	g.Expect(err).ToNot(HaveOccurred())
+	cfg, err := Render(loaded, testProfile())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	root := t.TempDir()
+	g.Expect(cfg.WriteTree(root)).To(Succeed())
+	info, err := os.Stat(filepath.Join(root, ScriptPath))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+	link, err := os.Readlink(filepath.Join(root, "etc/systemd/system/multi-user.target.wants", UnitName))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(link).To(Equal("../" + UnitName))
+	kargs, err := os.ReadFile(filepath.Join(root, kargsPath))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(kargs)).To(ContainSubstring(`kargs = ["intel_iommu=on", "iommu=pt"]`))
+
+	// the tree is written again over the previous one
+	g.Expect(cfg.WriteTree(root)).To(Succeed())
+}
@@ -34,8 +34,22 @@ func NewHostHelpers(utilsHelper utils.CmdInterface,
 
 func NewDefaultHostHelpers() (HostHelpersInterface, error) {
 	utilsHelper := utils.New()
+	return newHostHelpers(utilsHelper, host.NewHostManager(utilsHelper))
+}
+
+// NewHostHelpersInNamespace returns the host helpers running the network operations in the network namespace, the
+// name of a namespace of the host or its path
+func NewHostHelpersInNamespace(netns string) (HostHelpersInterface, error) {
+	utilsHelper := utils.NewInNetNs(utils.New(), netns)
+	hostManager, err := host.NewHostManagerInNamespace(utils.New(), netns)
+	if err != nil {
+		return nil, err
+	}
+	return newHostHelpers(utilsHelper, hostManager)
+}
+
+func newHostHelpers(utilsHelper utils.CmdInterface, hostManager host.HostManagerInterface) (HostHelpersInterface, error) {
 	mlxHelper := mlx.New(utilsHelper)
-	hostManager := host.NewHostManager(utilsHelper)
 	storeManager, err := store.NewManager()
 	if err != nil {
 		log.Log.Error(err, "failed to create store manager")
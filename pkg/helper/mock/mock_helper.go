@@ -40,6 +40,20 @@ func (m *MockHostHelpersInterface) EXPECT() *MockHostHelpersInterfaceMockRecorde
 	return m.recorder
 }
 
+// AddPfRenameUdevRule mocks base method.
+func (m *MockHostHelpersInterface) AddPfRenameUdevRule(pfPciAddress, portName, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPfRenameUdevRule", pfPciAddress, portName, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPfRenameUdevRule indicates an expected call of AddPfRenameUdevRule.
+func (mr *MockHostHelpersInterfaceMockRecorder) AddPfRenameUdevRule(pfPciAddress, portName, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPfRenameUdevRule", reflect.TypeOf((*MockHostHelpersInterface)(nil).AddPfRenameUdevRule), pfPciAddress, portName, name)
+}
+
 // AddUdevRule mocks base method.
 func (m *MockHostHelpersInterface) AddUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -253,6 +267,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) DiscoverVDPAType(pciAddr interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverVDPAType", reflect.TypeOf((*MockHostHelpersInterface)(nil).DiscoverVDPAType), pciAddr)
 }
 
+// EnableNetDevHwTimestamping mocks base method.
+func (m *MockHostHelpersInterface) EnableNetDevHwTimestamping(ifaceName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableNetDevHwTimestamping", ifaceName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableNetDevHwTimestamping indicates an expected call of EnableNetDevHwTimestamping.
+func (mr *MockHostHelpersInterfaceMockRecorder) EnableNetDevHwTimestamping(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableNetDevHwTimestamping", reflect.TypeOf((*MockHostHelpersInterface)(nil).EnableNetDevHwTimestamping), ifaceName)
+}
+
 // EnableRDMA mocks base method.
 func (m *MockHostHelpersInterface) EnableRDMA(conditionFilePath, serviceName, packageManager string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -297,6 +325,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) EnableService(service interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockHostHelpersInterface)(nil).EnableService), service)
 }
 
+// GetBindError mocks base method.
+func (m *MockHostHelpersInterface) GetBindError(pciAddr string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBindError", pciAddr)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetBindError indicates an expected call of GetBindError.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetBindError(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBindError", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetBindError), pciAddr)
+}
+
 // GetCheckPointNodeState mocks base method.
 func (m *MockHostHelpersInterface) GetCheckPointNodeState() (*v1.SriovNetworkNodeState, error) {
 	m.ctrl.T.Helper()
@@ -312,6 +354,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetCheckPointNodeState() *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckPointNodeState", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetCheckPointNodeState))
 }
 
+// GetCongestionControl mocks base method.
+func (m *MockHostHelpersInterface) GetCongestionControl(pciAddr, pfName string) *v1.CongestionControlConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionControl", pciAddr, pfName)
+	ret0, _ := ret[0].(*v1.CongestionControlConfig)
+	return ret0
+}
+
+// GetCongestionControl indicates an expected call of GetCongestionControl.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetCongestionControl(pciAddr, pfName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionControl", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetCongestionControl), pciAddr, pfName)
+}
+
 // GetCurrentKernelArgs mocks base method.
 func (m *MockHostHelpersInterface) GetCurrentKernelArgs() (string, error) {
 	m.ctrl.T.Helper()
@@ -327,6 +383,65 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetCurrentKernelArgs() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentKernelArgs", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetCurrentKernelArgs))
 }
 
+// GetFirmwareFaults mocks base method.
+func (m *MockHostHelpersInterface) GetFirmwareFaults(pciAddr string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirmwareFaults", pciAddr)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirmwareFaults indicates an expected call of GetFirmwareFaults.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetFirmwareFaults(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirmwareFaults", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetFirmwareFaults), pciAddr)
+}
+
+// GetHugepageSizes mocks base method.
+func (m *MockHostHelpersInterface) GetHugepageSizes() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHugepageSizes")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHugepageSizes indicates an expected call of GetHugepageSizes.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetHugepageSizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHugepageSizes", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetHugepageSizes))
+}
+
+// GetIommuKernelArgs mocks base method.
+func (m *MockHostHelpersInterface) GetIommuKernelArgs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIommuKernelArgs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetIommuKernelArgs indicates an expected call of GetIommuKernelArgs.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetIommuKernelArgs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIommuKernelArgs", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetIommuKernelArgs))
+}
+
+// GetIsolatedCpus mocks base method.
+func (m *MockHostHelpersInterface) GetIsolatedCpus() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIsolatedCpus")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIsolatedCpus indicates an expected call of GetIsolatedCpus.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetIsolatedCpus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIsolatedCpus", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetIsolatedCpus))
+}
+
 // GetLinkType mocks base method.
 func (m *MockHostHelpersInterface) GetLinkType(ifaceStatus v1.InterfaceExt) string {
 	m.ctrl.T.Helper()
@@ -372,6 +487,63 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetMlxNicFwData(pciAddress inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMlxNicFwData", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetMlxNicFwData), pciAddress)
 }
 
+// GetNICs mocks base method.
+func (m *MockHostHelpersInterface) GetNICs() ([]*ghw.NIC, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNICs")
+	ret0, _ := ret[0].([]*ghw.NIC)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNICs indicates an expected call of GetNICs.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNICs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNICs", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNICs))
+}
+
+// GetNetDevDriverVersion mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevDriverVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevDriverVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevDriverVersion indicates an expected call of GetNetDevDriverVersion.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevDriverVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevDriverVersion", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevDriverVersion), name)
+}
+
+// GetNetDevFirmwareVersion mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevFirmwareVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevFirmwareVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevFirmwareVersion indicates an expected call of GetNetDevFirmwareVersion.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevFirmwareVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevFirmwareVersion", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevFirmwareVersion), name)
+}
+
+// GetNetDevHwTimestamping mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevHwTimestamping(ifaceName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevHwTimestamping", ifaceName)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevHwTimestamping indicates an expected call of GetNetDevHwTimestamping.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevHwTimestamping(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevHwTimestamping", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevHwTimestamping), ifaceName)
+}
+
 // GetNetDevLinkSpeed mocks base method.
 func (m *MockHostHelpersInterface) GetNetDevLinkSpeed(name string) string {
 	m.ctrl.T.Helper()
@@ -386,6 +558,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevLinkSpeed(name interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevLinkSpeed", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevLinkSpeed), name)
 }
 
+// GetNetDevLinkState mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevLinkState(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevLinkState", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevLinkState indicates an expected call of GetNetDevLinkState.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevLinkState(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevLinkState", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevLinkState), name)
+}
+
 // GetNetDevMac mocks base method.
 func (m *MockHostHelpersInterface) GetNetDevMac(name string) string {
 	m.ctrl.T.Helper()
@@ -400,6 +586,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevMac(name interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevMac", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevMac), name)
 }
 
+// GetNetDevQos mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevQos(name string) *v1.QosConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevQos", name)
+	ret0, _ := ret[0].(*v1.QosConfig)
+	return ret0
+}
+
+// GetNetDevQos indicates an expected call of GetNetDevQos.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevQos(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevQos", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevQos), name)
+}
+
 // GetNetdevMTU mocks base method.
 func (m *MockHostHelpersInterface) GetNetdevMTU(pciAddr string) int {
 	m.ctrl.T.Helper()
@@ -429,6 +629,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetNicSriovMode(pciAddr interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNicSriovMode", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNicSriovMode), pciAddr)
 }
 
+// GetNumHugepages mocks base method.
+func (m *MockHostHelpersInterface) GetNumHugepages() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNumHugepages")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNumHugepages indicates an expected call of GetNumHugepages.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNumHugepages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNumHugepages", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNumHugepages))
+}
+
 // GetOSPrettyName mocks base method.
 func (m *MockHostHelpersInterface) GetOSPrettyName() (string, error) {
 	m.ctrl.T.Helper()
@@ -444,6 +659,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetOSPrettyName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOSPrettyName", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetOSPrettyName))
 }
 
+// GetPCIDevices mocks base method.
+func (m *MockHostHelpersInterface) GetPCIDevices() ([]*ghw.PCIDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPCIDevices")
+	ret0, _ := ret[0].([]*ghw.PCIDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPCIDevices indicates an expected call of GetPCIDevices.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetPCIDevices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPCIDevices", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPCIDevices))
+}
+
 // GetPhysPortName mocks base method.
 func (m *MockHostHelpersInterface) GetPhysPortName(name string) (string, error) {
 	m.ctrl.T.Helper()
@@ -474,6 +704,51 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetPhysSwitchID(name interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysSwitchID", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPhysSwitchID), name)
 }
 
+// GetPtpInfo mocks base method.
+func (m *MockHostHelpersInterface) GetPtpInfo(name string) *v1.PtpInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPtpInfo", name)
+	ret0, _ := ret[0].(*v1.PtpInfo)
+	return ret0
+}
+
+// GetPtpInfo indicates an expected call of GetPtpInfo.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetPtpInfo(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPtpInfo", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPtpInfo), name)
+}
+
+// GetSplitPorts mocks base method.
+func (m *MockHostHelpersInterface) GetSplitPorts(pciAddr string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSplitPorts", pciAddr)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSplitPorts indicates an expected call of GetSplitPorts.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetSplitPorts(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSplitPorts", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetSplitPorts), pciAddr)
+}
+
+// GetTransceiver mocks base method.
+func (m *MockHostHelpersInterface) GetTransceiver(name string) (*v1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransceiver", name)
+	ret0, _ := ret[0].(*v1.TransceiverInfo)
+	ret1, _ := ret[1].(*types.TransceiverDiagnostics)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTransceiver indicates an expected call of GetTransceiver.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetTransceiver(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransceiver", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetTransceiver), name)
+}
+
 // GetVfInfo mocks base method.
 func (m *MockHostHelpersInterface) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) v1.VirtualFunction {
 	m.ctrl.T.Helper()
@@ -503,6 +778,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) HasDriver(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDriver", reflect.TypeOf((*MockHostHelpersInterface)(nil).HasDriver), pciAddr)
 }
 
+// HasSriovCapablePfs mocks base method.
+func (m *MockHostHelpersInterface) HasSriovCapablePfs() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSriovCapablePfs")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSriovCapablePfs indicates an expected call of HasSriovCapablePfs.
+func (mr *MockHostHelpersInterfaceMockRecorder) HasSriovCapablePfs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSriovCapablePfs", reflect.TypeOf((*MockHostHelpersInterface)(nil).HasSriovCapablePfs))
+}
+
 // InstallRDMA mocks base method.
 func (m *MockHostHelpersInterface) InstallRDMA(packageManager string) error {
 	m.ctrl.T.Helper()
@@ -517,6 +807,18 @@ func (mr *MockHostHelpersInterfaceMockRecorder) InstallRDMA(packageManager inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallRDMA", reflect.TypeOf((*MockHostHelpersInterface)(nil).InstallRDMA), packageManager)
 }
 
+// InvalidateInventory mocks base method.
+func (m *MockHostHelpersInterface) InvalidateInventory() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateInventory")
+}
+
+// InvalidateInventory indicates an expected call of InvalidateInventory.
+func (mr *MockHostHelpersInterfaceMockRecorder) InvalidateInventory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateInventory", reflect.TypeOf((*MockHostHelpersInterface)(nil).InvalidateInventory))
+}
+
 // IsCoreOS mocks base method.
 func (m *MockHostHelpersInterface) IsCoreOS() (bool, error) {
 	m.ctrl.T.Helper()
@@ -634,6 +936,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) IsSwitchdev(name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSwitchdev", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsSwitchdev), name)
 }
 
+// IsTransceiverPresent mocks base method.
+func (m *MockHostHelpersInterface) IsTransceiverPresent(name string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTransceiverPresent", name)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsTransceiverPresent indicates an expected call of IsTransceiverPresent.
+func (mr *MockHostHelpersInterfaceMockRecorder) IsTransceiverPresent(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTransceiverPresent", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsTransceiverPresent), name)
+}
+
 // IsUbuntuSystem mocks base method.
 func (m *MockHostHelpersInterface) IsUbuntuSystem() (bool, error) {
 	m.ctrl.T.Helper()
@@ -649,6 +965,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) IsUbuntuSystem() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUbuntuSystem", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsUbuntuSystem))
 }
 
+// KernelModuleOptionsApplied mocks base method.
+func (m *MockHostHelpersInterface) KernelModuleOptionsApplied(module string, options map[string]string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KernelModuleOptionsApplied", module, options)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KernelModuleOptionsApplied indicates an expected call of KernelModuleOptionsApplied.
+func (mr *MockHostHelpersInterfaceMockRecorder) KernelModuleOptionsApplied(module, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KernelModuleOptionsApplied", reflect.TypeOf((*MockHostHelpersInterface)(nil).KernelModuleOptionsApplied), module, options)
+}
+
 // LoadKernelModule mocks base method.
 func (m *MockHostHelpersInterface) LoadKernelModule(name string, args ...string) error {
 	m.ctrl.T.Helper()
@@ -831,6 +1162,25 @@ func (mr *MockHostHelpersInterfaceMockRecorder) ReloadDriver(driver interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadDriver", reflect.TypeOf((*MockHostHelpersInterface)(nil).ReloadDriver), driver)
 }
 
+// ReloadKernelModules mocks base method.
+func (m *MockHostHelpersInterface) ReloadKernelModules(modules ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range modules {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadKernelModules", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadKernelModules indicates an expected call of ReloadKernelModules.
+func (mr *MockHostHelpersInterfaceMockRecorder) ReloadKernelModules(modules ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{}, modules...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadKernelModules", reflect.TypeOf((*MockHostHelpersInterface)(nil).ReloadKernelModules), varargs...)
+}
+
 // RemoveFromService mocks base method.
 func (m *MockHostHelpersInterface) RemoveFromService(service *types.Service, options ...*unit.UnitOption) (*types.Service, error) {
 	m.ctrl.T.Helper()
@@ -851,6 +1201,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) RemoveFromService(service interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromService", reflect.TypeOf((*MockHostHelpersInterface)(nil).RemoveFromService), varargs...)
 }
 
+// RemovePfRenameUdevRule mocks base method.
+func (m *MockHostHelpersInterface) RemovePfRenameUdevRule(pfPciAddress string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePfRenameUdevRule", pfPciAddress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePfRenameUdevRule indicates an expected call of RemovePfRenameUdevRule.
+func (mr *MockHostHelpersInterfaceMockRecorder) RemovePfRenameUdevRule(pfPciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePfRenameUdevRule", reflect.TypeOf((*MockHostHelpersInterface)(nil).RemovePfRenameUdevRule), pfPciAddress)
+}
+
 // RemoveUdevRule mocks base method.
 func (m *MockHostHelpersInterface) RemoveUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -879,6 +1243,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) RemoveVfRepresentorUdevRule(pfPc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveVfRepresentorUdevRule", reflect.TypeOf((*MockHostHelpersInterface)(nil).RemoveVfRepresentorUdevRule), pfPciAddress)
 }
 
+// RenameNetDev mocks base method.
+func (m *MockHostHelpersInterface) RenameNetDev(name, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameNetDev", name, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameNetDev indicates an expected call of RenameNetDev.
+func (mr *MockHostHelpersInterfaceMockRecorder) RenameNetDev(name, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameNetDev", reflect.TypeOf((*MockHostHelpersInterface)(nil).RenameNetDev), name, newName)
+}
+
 // ResetSriovDevice mocks base method.
 func (m *MockHostHelpersInterface) ResetSriovDevice(ifaceStatus v1.InterfaceExt) error {
 	m.ctrl.T.Helper()
@@ -928,6 +1306,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) SaveLastPfAppliedStatus(PfInfo i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLastPfAppliedStatus", reflect.TypeOf((*MockHostHelpersInterface)(nil).SaveLastPfAppliedStatus), PfInfo)
 }
 
+// SetCongestionControl mocks base method.
+func (m *MockHostHelpersInterface) SetCongestionControl(pciAddr, pfName string, cc *v1.CongestionControlConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCongestionControl", pciAddr, pfName, cc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCongestionControl indicates an expected call of SetCongestionControl.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetCongestionControl(pciAddr, pfName, cc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCongestionControl", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetCongestionControl), pciAddr, pfName, cc)
+}
+
+// SetNetDevQos mocks base method.
+func (m *MockHostHelpersInterface) SetNetDevQos(name string, qos *v1.QosConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNetDevQos", name, qos)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNetDevQos indicates an expected call of SetNetDevQos.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetNetDevQos(name, qos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNetDevQos", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetNetDevQos), name, qos)
+}
+
 // SetNetdevMTU mocks base method.
 func (m *MockHostHelpersInterface) SetNetdevMTU(pciAddr string, mtu int) error {
 	m.ctrl.T.Helper()
@@ -956,6 +1362,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) SetNicSriovMode(pciAddr, mode in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNicSriovMode", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetNicSriovMode), pciAddr, mode)
 }
 
+// SetPortSplit mocks base method.
+func (m *MockHostHelpersInterface) SetPortSplit(pciAddr string, count int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPortSplit", pciAddr, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPortSplit indicates an expected call of SetPortSplit.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetPortSplit(pciAddr, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPortSplit", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetPortSplit), pciAddr, count)
+}
+
 // SetSriovNumVfs mocks base method.
 func (m *MockHostHelpersInterface) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	m.ctrl.T.Helper()
@@ -998,6 +1418,62 @@ func (mr *MockHostHelpersInterfaceMockRecorder) SetVfGUID(vfAddr, pfLink interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVfGUID", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetVfGUID), vfAddr, pfLink)
 }
 
+// SyncFlowRules mocks base method.
+func (m *MockHostHelpersInterface) SyncFlowRules(pfName string, rules []v1.FlowRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncFlowRules", pfName, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncFlowRules indicates an expected call of SyncFlowRules.
+func (mr *MockHostHelpersInterfaceMockRecorder) SyncFlowRules(pfName, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncFlowRules", reflect.TypeOf((*MockHostHelpersInterface)(nil).SyncFlowRules), pfName, rules)
+}
+
+// SyncPolicing mocks base method.
+func (m *MockHostHelpersInterface) SyncPolicing(pfName string, policing map[int]*v1.PolicingConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncPolicing", pfName, policing)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncPolicing indicates an expected call of SyncPolicing.
+func (mr *MockHostHelpersInterfaceMockRecorder) SyncPolicing(pfName, policing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPolicing", reflect.TypeOf((*MockHostHelpersInterface)(nil).SyncPolicing), pfName, policing)
+}
+
+// SyncUnicastMacs mocks base method.
+func (m *MockHostHelpersInterface) SyncUnicastMacs(iface *v1.Interface) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncUnicastMacs", iface)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncUnicastMacs indicates an expected call of SyncUnicastMacs.
+func (mr *MockHostHelpersInterfaceMockRecorder) SyncUnicastMacs(iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncUnicastMacs", reflect.TypeOf((*MockHostHelpersInterface)(nil).SyncUnicastMacs), iface)
+}
+
+// SyncVfUnicastMacs mocks base method.
+func (m *MockHostHelpersInterface) SyncVfUnicastMacs(vfAddr string, macs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncVfUnicastMacs", vfAddr, macs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncVfUnicastMacs indicates an expected call of SyncVfUnicastMacs.
+func (mr *MockHostHelpersInterfaceMockRecorder) SyncVfUnicastMacs(vfAddr interface{}, macs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncVfUnicastMacs", reflect.TypeOf((*MockHostHelpersInterface)(nil).SyncVfUnicastMacs), vfAddr, macs)
+}
+
 // TriggerUdevEvent mocks base method.
 func (m *MockHostHelpersInterface) TriggerUdevEvent() error {
 	m.ctrl.T.Helper()
@@ -1164,6 +1640,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) WriteCheckpointFile(arg0 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteCheckpointFile", reflect.TypeOf((*MockHostHelpersInterface)(nil).WriteCheckpointFile), arg0)
 }
 
+// WriteModprobeConf mocks base method.
+func (m *MockHostHelpersInterface) WriteModprobeConf(module string, options map[string]string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteModprobeConf", module, options)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteModprobeConf indicates an expected call of WriteModprobeConf.
+func (mr *MockHostHelpersInterfaceMockRecorder) WriteModprobeConf(module, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteModprobeConf", reflect.TypeOf((*MockHostHelpersInterface)(nil).WriteModprobeConf), module, options)
+}
+
 // WriteSwitchdevConfFile mocks base method.
 func (m *MockHostHelpersInterface) WriteSwitchdevConfFile(newState *v1.SriovNetworkNodeState, pfsToSkip map[string]bool) (bool, error) {
 	m.ctrl.T.Helper()
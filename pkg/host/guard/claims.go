@@ -0,0 +1,118 @@
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+var (
+	claimsMu sync.RWMutex
+	// claims are the interfaces claimed by the other host network managers of the node, by manager
+	claims map[string][]string
+)
+
+// ParseClaims returns the interface names and the PCI addresses claimed by the other host network managers in the
+// annotations of the node, by manager. The claim of the SR-IOV operator is left out.
+func ParseClaims(annotations map[string]string) map[string][]string {
+	parsed := map[string][]string{}
+	for key, value := range annotations {
+		owner, ok := strings.CutPrefix(key, consts.InterfaceClaimAnnotationPrefix)
+		if !ok || owner == consts.InterfaceClaimOwner {
+			continue
+		}
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				parsed[owner] = append(parsed[owner], entry)
+			}
+		}
+	}
+	return parsed
+}
+
+// SetClaims sets the interfaces claimed by the other host network managers of the node, Check refuses to change them
+func SetClaims(c map[string][]string) {
+	claimsMu.Lock()
+	defer claimsMu.Unlock()
+	claims = c
+}
+
+// claimOwner returns the manager claiming any of the names or PCI addresses, empty when they are not claimed
+func claimOwner(entries ...string) string {
+	claimsMu.RLock()
+	defer claimsMu.RUnlock()
+	owners := make([]string, 0, len(claims))
+	for owner := range claims {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		for _, entry := range entries {
+			if entry != "" && slices.Contains(claims[owner], entry) {
+				return owner
+			}
+		}
+	}
+	return ""
+}
+
+// checkClaims returns an ErrInterfaceClaimed error when the device, or the PF of a VF, is claimed by its PCI address
+// or by the name of its netdevice
+func checkClaims(pciAddr string) error {
+	claimsMu.RLock()
+	claimed := len(claims) > 0
+	claimsMu.RUnlock()
+	if !claimed {
+		return nil
+	}
+	entries := []string{pciAddr}
+	if pf := physfn(pciAddr); pf != "" {
+		entries = append(entries, pf)
+	}
+	for _, addr := range entries {
+		netdevs, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, addr, "net"))
+		if err != nil {
+			continue
+		}
+		for _, netdev := range netdevs {
+			entries = append(entries, netdev.Name())
+		}
+	}
+	if owner := claimOwner(entries...); owner != "" {
+		return fmt.Errorf("%s is claimed by %s", pciAddr, owner)
+	}
+	return nil
+}
+
+// Conflicts returns the interfaces of the spec claimed by another host network manager, e.g. "ens1f0 is claimed by
+// nmstate"
+func Conflicts(interfaces sriovnetworkv1.Interfaces) []string {
+	conflicts := []string{}
+	for _, iface := range interfaces {
+		if owner := claimOwner(iface.Name, iface.PciAddress); owner != "" {
+			conflicts = append(conflicts, fmt.Sprintf("%s is claimed by %s", iface.Name, owner))
+		}
+	}
+	return conflicts
+}
+
+// Claim returns the claim of the SR-IOV operator, the names of the interfaces of the spec not claimed by another
+// host network manager
+func Claim(interfaces sriovnetworkv1.Interfaces) string {
+	names := []string{}
+	for _, iface := range interfaces {
+		if iface.Name != "" && claimOwner(iface.Name, iface.PciAddress) == "" && !slices.Contains(names, iface.Name) {
+			names = append(names, iface.Name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
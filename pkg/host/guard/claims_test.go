@@ -0,0 +1,55 @@
+package guard
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func TestClaims(t *testing.T) {
+	g := NewGomegaWithT(t)
+	useFakeFilesystem(t, &fakefilesystem.FS{
+		Dirs: []string{
+			"sys/bus/pci/devices/0000:3b:00.0/net/ens1f0",
+			"sys/bus/pci/devices/0000:3b:00.1/net/ens1f1",
+			"sys/bus/pci/devices/0000:3b:02.0",
+			"sys/bus/pci/devices/0000:d8:00.0/net/ens2f0",
+		},
+		Symlinks: map[string]string{
+			"sys/bus/pci/devices/0000:3b:02.0/physfn": "../0000:3b:00.0",
+		},
+	})
+	t.Cleanup(func() { SetClaims(nil) })
+
+	claims := ParseClaims(map[string]string{
+		consts.InterfaceClaimAnnotationPrefix + "nmstate":                  "ens1f0, ",
+		consts.InterfaceClaimAnnotationPrefix + "metallb":                  "0000:3b:00.1",
+		consts.InterfaceClaimAnnotationPrefix + consts.InterfaceClaimOwner: "ens2f0",
+		"sriovnetwork.openshift.io/drain-mode":                             "drain",
+	})
+	g.Expect(claims).To(Equal(map[string][]string{"nmstate": {"ens1f0"}, "metallb": {"0000:3b:00.1"}}))
+	SetClaims(claims)
+
+	// the devices are matched by name and by PCI address, a VF by its PF
+	g.Expect(Check("0000:3b:00.0")).To(MatchError(ContainSubstring("claimed by nmstate")))
+	g.Expect(snerrors.ReasonOf(Check("0000:3b:02.0"))).To(Equal(snerrors.ReasonInterfaceClaimed))
+	g.Expect(Check("0000:3b:00.1")).To(MatchError(ContainSubstring("claimed by metallb")))
+	g.Expect(Check("0000:d8:00.0")).To(Succeed())
+
+	interfaces := sriovnetworkv1.Interfaces{
+		{PciAddress: "0000:3b:00.0", Name: "ens1f0"},
+		{PciAddress: "0000:3b:00.1", Name: "ens1f1"},
+		{PciAddress: "0000:d8:00.0", Name: "ens2f0"},
+	}
+	g.Expect(Conflicts(interfaces)).To(Equal([]string{"ens1f0 is claimed by nmstate", "ens1f1 is claimed by metallb"}))
+	g.Expect(Claim(interfaces)).To(Equal("ens2f0"))
+
+	SetClaims(nil)
+	g.Expect(Conflicts(interfaces)).To(BeEmpty())
+	g.Expect(Claim(interfaces)).To(Equal("ens1f0,ens1f1,ens2f0"))
+}
@@ -0,0 +1,143 @@
+// Package guard restricts the PCI devices the config daemon may change on its node to the allowlist and the denylist
+// of the node, a guard rail against the policies selecting by mistake the NICs which must never be touched, e.g. the
+// storage or the out-of-band management NICs. The lists are read from a file of the host, so they can't be changed
+// through the Kubernetes API. The interfaces claimed by the other host network managers of the node in its
+// annotations are not changed either.
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// Config is the content of the guard file, the entries are PCI addresses or shell patterns of PCI addresses, e.g.
+// 0000:3b:00.* for all the functions of a device. A VF is matched by its address and by the address of its PF.
+type Config struct {
+	// Allow lists the devices the daemon may change, all of them when empty
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny lists the devices the daemon must never change, it takes precedence over the allowlist
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	// loaded is the guard file read last, it is read again when its modification time changes
+	loaded     *Config
+	loadedFile string
+	loadedTime time.Time
+	loadErr    error
+)
+
+// Load parses the guard file, the patterns are validated
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the PCI address guard %s: %v", file, err)
+	}
+	for _, pattern := range append(append([]string{}, cfg.Allow...), cfg.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in the PCI address guard %s: %v", pattern, file, err)
+		}
+	}
+	return cfg, nil
+}
+
+// current returns the guard of the node, nil when the node has no guard file
+func current() (*Config, error) {
+	file := utils.GetHostExtensionPath(consts.PciGuardFile)
+	info, err := os.Stat(file)
+	mu.Lock()
+	defer mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		loaded, loadedFile, loadedTime, loadErr = nil, "", time.Time{}, nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if loadedFile == file && loadedTime.Equal(info.ModTime()) {
+		return loaded, loadErr
+	}
+	loaded, loadErr = Load(file)
+	loadedFile, loadedTime = file, info.ModTime()
+	if loadErr == nil {
+		log.Log.Info("guard: PCI address guard loaded", "allow", loaded.Allow, "deny", loaded.Deny)
+	}
+	return loaded, loadErr
+}
+
+// Allowed returns whether the device, or the PF of a VF, may be changed
+func (c *Config) Allowed(pciAddr string) bool {
+	addresses := []string{pciAddr}
+	if pf := physfn(pciAddr); pf != "" {
+		addresses = append(addresses, pf)
+	}
+	if matchAny(c.Deny, addresses) {
+		return false
+	}
+	return len(c.Allow) == 0 || matchAny(c.Allow, addresses)
+}
+
+// Check returns an ErrPciAddressGuarded error when the guard of the node doesn't allow the device to be changed, and
+// an ErrInterfaceClaimed error when the device is claimed by another host network manager. An invalid guard file
+// denies all the devices.
+func Check(pciAddr string) error {
+	cfg, err := current()
+	if err != nil {
+		return snerrors.Wrap(snerrors.ErrPciAddressGuarded, fmt.Errorf("%s is not changed, the PCI address guard of the node can't be read: %v", pciAddr, err))
+	}
+	if cfg != nil && !cfg.Allowed(pciAddr) {
+		return snerrors.Wrap(snerrors.ErrPciAddressGuarded, fmt.Errorf("%s is not allowed to be changed by the PCI address guard of the node", pciAddr))
+	}
+	if err := checkClaims(pciAddr); err != nil {
+		return snerrors.Wrap(snerrors.ErrInterfaceClaimed, err)
+	}
+	return nil
+}
+
+// CheckNetDev checks the PCI device of the netdev as Check does, e.g. the PF of a representor. The netdevs without
+// PCI device are not guarded.
+func CheckNetDev(name string) error {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "device"))
+	if err != nil {
+		return nil
+	}
+	return Check(filepath.Base(link))
+}
+
+// physfn returns the address of the PF of a VF, empty for a PF
+func physfn(pciAddr string) string {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "physfn"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
+
+func matchAny(patterns, addresses []string) bool {
+	for _, pattern := range patterns {
+		for _, addr := range addresses {
+			if ok, _ := path.Match(pattern, addr); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func useFakeFilesystem(t *testing.T, fs *fakefilesystem.FS) string {
+	root, clean, err := fs.Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+	return root
+}
+
+func writeGuard(t *testing.T, root, content string, mtime time.Time) {
+	file := filepath.Join(root, consts.PciGuardFile)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := useFakeFilesystem(t, &fakefilesystem.FS{
+		Dirs: []string{
+			consts.SriovConfBasePath,
+			"sys/bus/pci/devices/0000:3b:00.0",
+			"sys/bus/pci/devices/0000:3b:02.0",
+			"sys/bus/pci/devices/0000:d8:00.0",
+			"sys/class/net/ens1f0_0",
+			"sys/class/net/ens2f0",
+			"sys/class/net/bond0",
+		},
+		Symlinks: map[string]string{
+			"sys/bus/pci/devices/0000:3b:02.0/physfn": "../0000:3b:00.0",
+			"sys/class/net/ens1f0_0/device":           "../../../0000:3b:00.0",
+			"sys/class/net/ens2f0/device":             "../../../0000:d8:00.0",
+		},
+	})
+
+	// no guard file, all the devices are allowed
+	g.Expect(Check("0000:d8:00.0")).To(Succeed())
+
+	writeGuard(t, root, "allow:\n- 0000:3b:00.*\n", time.Unix(1, 0))
+	g.Expect(Check("0000:3b:00.0")).To(Succeed())
+	// a VF is allowed through its PF
+	g.Expect(Check("0000:3b:02.0")).To(Succeed())
+	err := Check("0000:d8:00.0")
+	g.Expect(err).To(MatchError(snerrors.ErrPciAddressGuarded))
+	g.Expect(snerrors.ReasonOf(err)).To(Equal(snerrors.ReasonPciAddressGuarded))
+
+	// the denylist takes precedence and is read again once the file changes
+	writeGuard(t, root, "allow:\n- 0000:3b:00.*\ndeny:\n- 0000:3b:00.0\n", time.Unix(2, 0))
+	g.Expect(Check("0000:3b:00.0")).ToNot(Succeed())
+	g.Expect(Check("0000:3b:02.0")).ToNot(Succeed())
+	g.Expect(Check("0000:3b:00.1")).To(Succeed())
+	// a netdev is checked through its PCI device, the virtual netdevs are not guarded
+	g.Expect(CheckNetDev("ens1f0_0")).To(MatchError(snerrors.ErrPciAddressGuarded))
+	g.Expect(CheckNetDev("ens2f0")).To(MatchError(snerrors.ErrPciAddressGuarded))
+	g.Expect(CheckNetDev("bond0")).To(Succeed())
+
+	// an invalid guard denies all the devices
+	writeGuard(t, root, "allow: [\n", time.Unix(3, 0))
+	g.Expect(Check("0000:3b:00.1")).To(MatchError(snerrors.ErrPciAddressGuarded))
+	writeGuard(t, root, "deny:\n- '0000:3b:[00.0'\n", time.Unix(4, 0))
+	g.Expect(Check("0000:d8:00.0")).To(MatchError(ContainSubstring("invalid pattern")))
+
+	g.Expect(os.Remove(filepath.Join(root, consts.PciGuardFile))).To(Succeed())
+	g.Expect(Check("0000:d8:00.0")).To(Succeed())
+}
@@ -0,0 +1,209 @@
+package congestion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const maxPriority = 7
+
+// param is a DCQCN parameter, exposed by the mlx5 driver in the ecn directory of the netdev in sysfs and, on
+// the drivers without it, in the cc_params directory of the device in debugfs
+type param struct {
+	sysfs   string
+	debugfs string
+}
+
+var (
+	cnpDscp                 = param{"roce_np/cnp_dscp", "np_cnp_dscp"}
+	cnpPriority             = param{"roce_np/cnp_802p_prio", "np_cnp_prio"}
+	minRate                 = param{"roce_rp/rpg_min_rate", "rp_min_rate"}
+	aiRate                  = param{"roce_rp/rpg_ai_rate", "rp_ai_rate"}
+	haiRate                 = param{"roce_rp/rpg_hai_rate", "rp_hai_rate"}
+	timeReset               = param{"roce_rp/rpg_time_reset", "rp_time_reset"}
+	byteReset               = param{"roce_rp/rpg_byte_reset", "rp_byte_reset"}
+	rateReduceMonitorPeriod = param{"roce_rp/rate_reduce_monitor_period", "rp_rate_reduce_monitor_period"}
+
+	params = []param{cnpDscp, cnpPriority, minRate, aiRate, haiRate, timeReset, byteReset, rateReduceMonitorPeriod}
+)
+
+// requestedParams returns the values of the parameters set in the configuration
+func requestedParams(cc *sriovnetworkv1.CongestionControlConfig) map[param]int {
+	values := map[param]int{}
+	if cc.CnpDscp != nil {
+		values[cnpDscp] = *cc.CnpDscp
+	}
+	if cc.CnpPriority != nil {
+		values[cnpPriority] = *cc.CnpPriority
+	}
+	for p, value := range map[param]int{
+		minRate:                 cc.MinRate,
+		aiRate:                  cc.AiRate,
+		haiRate:                 cc.HaiRate,
+		timeReset:               cc.TimeReset,
+		byteReset:               cc.ByteReset,
+		rateReduceMonitorPeriod: cc.RateReduceMonitorPeriod,
+	} {
+		if value > 0 {
+			values[p] = value
+		}
+	}
+	return values
+}
+
+// setParam sets the value of the parameter in the configuration
+func setParam(cc *sriovnetworkv1.CongestionControlConfig, p param, value int) {
+	switch p {
+	case cnpDscp:
+		cc.CnpDscp = &value
+	case cnpPriority:
+		cc.CnpPriority = &value
+	case minRate:
+		cc.MinRate = value
+	case aiRate:
+		cc.AiRate = value
+	case haiRate:
+		cc.HaiRate = value
+	case timeReset:
+		cc.TimeReset = value
+	case byteReset:
+		cc.ByteReset = value
+	case rateReduceMonitorPeriod:
+		cc.RateReduceMonitorPeriod = value
+	}
+}
+
+type congestion struct{}
+
+func New() types.CongestionControlInterface {
+	return &congestion{}
+}
+
+// ecnDir returns the ecn directory of the netdev
+func ecnDir(pfName string) string {
+	return filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName, "ecn")
+}
+
+// ccParamsDir returns the cc_params directory of the mlx5 device in debugfs
+func ccParamsDir(pciAddr string) string {
+	return filepath.Join(vars.FilesystemRoot, consts.SysKernelDebug, "mlx5", pciAddr, "cc_params")
+}
+
+// paramPath returns the file of the parameter, or an empty string if the driver doesn't expose it
+func paramPath(pciAddr, pfName string, p param) string {
+	for _, path := range []string{filepath.Join(ecnDir(pfName), p.sysfs), filepath.Join(ccParamsDir(pciAddr), p.debugfs)} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// enablePath returns the file enabling the notification point ("roce_np") or the reaction point ("roce_rp")
+// on the priority
+func enablePath(pfName, point string, prio int) string {
+	return filepath.Join(ecnDir(pfName), point, "enable", strconv.Itoa(prio))
+}
+
+func readInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+func writeInt(path string, value int) error {
+	return utils.WriteHostFile(path, []byte(strconv.Itoa(value)), os.ModeAppend)
+}
+
+// GetCongestionControl returns the ECN and DCQCN settings of the PF. The ECN priorities are only reported by the
+// drivers exposing the ecn directory in sysfs, they report the priorities with both the notification point and
+// the reaction point enabled.
+func (c *congestion) GetCongestionControl(pciAddr, pfName string) *sriovnetworkv1.CongestionControlConfig {
+	cc := &sriovnetworkv1.CongestionControlConfig{}
+	found := false
+	if _, err := os.Stat(ecnDir(pfName)); err == nil {
+		found = true
+		cc.EcnPriorities = []int{}
+		for prio := 0; prio <= maxPriority; prio++ {
+			np, errNp := readInt(enablePath(pfName, "roce_np", prio))
+			rp, errRp := readInt(enablePath(pfName, "roce_rp", prio))
+			if errNp == nil && errRp == nil && np == 1 && rp == 1 {
+				cc.EcnPriorities = append(cc.EcnPriorities, prio)
+			}
+		}
+	}
+	for _, p := range params {
+		path := paramPath(pciAddr, pfName, p)
+		if path == "" {
+			continue
+		}
+		value, err := readInt(path)
+		if err != nil {
+			log.Log.V(2).Info("GetCongestionControl(): failed to read parameter", "path", path, "error", err)
+			continue
+		}
+		found = true
+		setParam(cc, p, value)
+	}
+	if !found {
+		return nil
+	}
+	return cc
+}
+
+// SetCongestionControl enables ECN on the requested priorities, disables it on the others, and sets the DCQCN
+// parameters requested
+func (c *congestion) SetCongestionControl(pciAddr, pfName string, cc *sriovnetworkv1.CongestionControlConfig) error {
+	log.Log.V(2).Info("SetCongestionControl(): set congestion control", "name", pfName, "config", cc)
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
+	if cc.EcnPriorities != nil {
+		if _, err := os.Stat(ecnDir(pfName)); err != nil {
+			return fmt.Errorf("the driver of %s doesn't support the configuration of the ECN priorities", pfName)
+		}
+		enabled := append([]int{}, cc.EcnPriorities...)
+		sort.Ints(enabled)
+		for prio := 0; prio <= maxPriority; prio++ {
+			value := 0
+			if idx := sort.SearchInts(enabled, prio); idx < len(enabled) && enabled[idx] == prio {
+				value = 1
+			}
+			for _, point := range []string{"roce_np", "roce_rp"} {
+				if err := writeInt(enablePath(pfName, point, prio), value); err != nil {
+					return fmt.Errorf("failed to set ECN on priority %d of %s: %v", prio, pfName, err)
+				}
+			}
+		}
+	}
+	requested := requestedParams(cc)
+	for _, p := range params {
+		value, ok := requested[p]
+		if !ok {
+			continue
+		}
+		path := paramPath(pciAddr, pfName, p)
+		if path == "" {
+			return fmt.Errorf("the driver of %s doesn't support the %s parameter", pfName, filepath.Base(p.sysfs))
+		}
+		if err := writeInt(path, value); err != nil {
+			return fmt.Errorf("failed to set the %s parameter of %s: %v", filepath.Base(p.sysfs), pfName, err)
+		}
+	}
+	return nil
+}
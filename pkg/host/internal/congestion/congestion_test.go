@@ -0,0 +1,119 @@
+package congestion
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+const (
+	testPciAddr = "0000:d8:00.0"
+	testPfName  = "enp216s0f0np0"
+)
+
+// ecnFS returns a fake filesystem with the ecn directory of the mlx5 driver, ECN is enabled on the priorities
+func ecnFS(priorities ...int) *fakefilesystem.FS {
+	ecn := "/sys/class/net/" + testPfName + "/ecn"
+	fs := &fakefilesystem.FS{
+		Dirs: []string{ecn + "/roce_np/enable", ecn + "/roce_rp/enable"},
+		Files: map[string][]byte{
+			ecn + "/roce_np/cnp_dscp":       []byte("48\n"),
+			ecn + "/roce_np/cnp_802p_prio":  []byte("6\n"),
+			ecn + "/roce_rp/rpg_min_rate":   []byte("1\n"),
+			ecn + "/roce_rp/rpg_ai_rate":    []byte("5\n"),
+			ecn + "/roce_rp/rpg_time_reset": []byte("300\n"),
+		},
+	}
+	for prio := 0; prio <= maxPriority; prio++ {
+		enabled := "0"
+		for _, p := range priorities {
+			if p == prio {
+				enabled = "1"
+			}
+		}
+		fs.Files[fmt.Sprintf("%s/roce_np/enable/%d", ecn, prio)] = []byte(enabled)
+		fs.Files[fmt.Sprintf("%s/roce_rp/enable/%d", ecn, prio)] = []byte(enabled)
+	}
+	return fs
+}
+
+var _ = Describe("Congestion control", func() {
+	var c types.CongestionControlInterface
+	BeforeEach(func() {
+		c = New()
+	})
+
+	Context("GetCongestionControl", func() {
+		It("reads the ecn directory", func() {
+			helpers.GinkgoConfigureFakeFS(ecnFS(3, 4))
+			cnpDscp, cnpPriority := 48, 6
+			Expect(c.GetCongestionControl(testPciAddr, testPfName)).To(Equal(&sriovnetworkv1.CongestionControlConfig{
+				EcnPriorities: []int{3, 4},
+				CnpDscp:       &cnpDscp,
+				CnpPriority:   &cnpPriority,
+				MinRate:       1,
+				AiRate:        5,
+				TimeReset:     300,
+			}))
+		})
+		It("reads the cc_params of debugfs", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/kernel/debug/mlx5/" + testPciAddr + "/cc_params"},
+				Files: map[string][]byte{
+					"/sys/kernel/debug/mlx5/" + testPciAddr + "/cc_params/np_cnp_dscp":   []byte("26\n"),
+					"/sys/kernel/debug/mlx5/" + testPciAddr + "/cc_params/rp_hai_rate":   []byte("50\n"),
+					"/sys/kernel/debug/mlx5/" + testPciAddr + "/cc_params/rp_byte_reset": []byte("32767\n"),
+				},
+			})
+			cnpDscp := 26
+			Expect(c.GetCongestionControl(testPciAddr, testPfName)).To(Equal(&sriovnetworkv1.CongestionControlConfig{
+				CnpDscp:   &cnpDscp,
+				HaiRate:   50,
+				ByteReset: 32767,
+			}))
+		})
+		It("returns nil when the driver doesn't expose the congestion control", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/sys/class/net/" + testPfName}})
+			Expect(c.GetCongestionControl(testPciAddr, testPfName)).To(BeNil())
+		})
+	})
+
+	Context("SetCongestionControl", func() {
+		It("enables ECN on the requested priorities and sets the parameters", func() {
+			helpers.GinkgoConfigureFakeFS(ecnFS(0))
+			cnpDscp := 26
+			Expect(c.SetCongestionControl(testPciAddr, testPfName, &sriovnetworkv1.CongestionControlConfig{
+				EcnPriorities: []int{3},
+				CnpDscp:       &cnpDscp,
+				TimeReset:     100,
+			})).To(Succeed())
+			ecn := "/sys/class/net/" + testPfName + "/ecn"
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_np/enable/0", "0")
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_rp/enable/0", "0")
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_np/enable/3", "1")
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_rp/enable/3", "1")
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_np/cnp_dscp", "26")
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_rp/rpg_time_reset", "100")
+			// the parameters not requested are left untouched
+			helpers.GinkgoAssertFileContentsEquals(ecn+"/roce_rp/rpg_ai_rate", "5\n")
+		})
+		It("fails on the ECN priorities without the ecn directory", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/sys/kernel/debug/mlx5/" + testPciAddr + "/cc_params"}})
+			Expect(c.SetCongestionControl(testPciAddr, testPfName, &sriovnetworkv1.CongestionControlConfig{
+				EcnPriorities: []int{3},
+			})).To(MatchError(ContainSubstring("ECN priorities")))
+		})
+		It("fails on a parameter the driver doesn't expose", func() {
+			helpers.GinkgoConfigureFakeFS(ecnFS())
+			Expect(c.SetCongestionControl(testPciAddr, testPfName, &sriovnetworkv1.CongestionControlConfig{
+				HaiRate: 50,
+			})).To(MatchError(ContainSubstring("rpg_hai_rate")))
+		})
+	})
+})
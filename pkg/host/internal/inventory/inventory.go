@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaypipes/ghw"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ghwPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+// maxAge bounds how long a cached scan is served, as a safety net for
+// hardware changes that are not announced through a uevent
+const maxAge = 5 * time.Minute
+
+type inventory struct {
+	ghwLib ghwPkg.GHWLib
+
+	lock       sync.Mutex
+	pciDevices []*ghw.PCIDevice
+	pciScanned time.Time
+	nics       []*ghw.NIC
+	nicScanned time.Time
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+func New(ghwLib ghwPkg.GHWLib) types.InventoryInterface {
+	return &inventory{ghwLib: ghwLib, now: time.Now}
+}
+
+func (i *inventory) isFresh(scanned time.Time) bool {
+	return !scanned.IsZero() && i.now().Sub(scanned) < maxAge
+}
+
+func (i *inventory) GetPCIDevices() ([]*ghw.PCIDevice, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.isFresh(i.pciScanned) {
+		return i.pciDevices, nil
+	}
+	log.Log.V(2).Info("GetPCIDevices(): scanning PCI devices")
+	pci, err := i.ghwLib.PCI()
+	if err != nil {
+		return nil, fmt.Errorf("GetPCIDevices(): error getting PCI info: %v", err)
+	}
+	i.pciDevices = pci.Devices
+	i.pciScanned = i.now()
+	return i.pciDevices, nil
+}
+
+func (i *inventory) GetNICs() ([]*ghw.NIC, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.isFresh(i.nicScanned) {
+		return i.nics, nil
+	}
+	log.Log.V(2).Info("GetNICs(): scanning network interfaces")
+	network, err := i.ghwLib.Network()
+	if err != nil {
+		return nil, fmt.Errorf("GetNICs(): error getting network info: %v", err)
+	}
+	i.nics = network.NICs
+	i.nicScanned = i.now()
+	return i.nics, nil
+}
+
+func (i *inventory) InvalidateInventory() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	log.Log.V(2).Info("InvalidateInventory(): dropping cached hardware inventory")
+	i.pciDevices = nil
+	i.pciScanned = time.Time{}
+	i.nics = nil
+	i.nicScanned = time.Time{}
+}
@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jaypipes/ghw"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ghwMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw/mock"
+)
+
+var _ = Describe("Inventory", func() {
+	var (
+		i          *inventory
+		ghwLibMock *ghwMockPkg.MockGHWLib
+		testCtrl   *gomock.Controller
+		now        time.Time
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		ghwLibMock = ghwMockPkg.NewMockGHWLib(testCtrl)
+		now = time.Now()
+		i = New(ghwLibMock).(*inventory)
+		i.now = func() time.Time { return now }
+	})
+
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	Context("GetPCIDevices", func() {
+		It("serves repeated calls from the cache", func() {
+			ghwLibMock.EXPECT().PCI().Return(&ghw.PCIInfo{Devices: []*ghw.PCIDevice{{Address: "0000:d8:00.0"}}}, nil).Times(1)
+			for n := 0; n < 3; n++ {
+				devices, err := i.GetPCIDevices()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(devices).To(HaveLen(1))
+			}
+		})
+		It("rescans after invalidation", func() {
+			ghwLibMock.EXPECT().PCI().Return(&ghw.PCIInfo{}, nil).Times(2)
+			_, err := i.GetPCIDevices()
+			Expect(err).NotTo(HaveOccurred())
+			i.InvalidateInventory()
+			_, err = i.GetPCIDevices()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("rescans once the cache expired", func() {
+			ghwLibMock.EXPECT().PCI().Return(&ghw.PCIInfo{}, nil).Times(2)
+			_, err := i.GetPCIDevices()
+			Expect(err).NotTo(HaveOccurred())
+			now = now.Add(maxAge)
+			_, err = i.GetPCIDevices()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("does not cache errors", func() {
+			ghwLibMock.EXPECT().PCI().Return(nil, fmt.Errorf("test"))
+			ghwLibMock.EXPECT().PCI().Return(&ghw.PCIInfo{}, nil)
+			_, err := i.GetPCIDevices()
+			Expect(err).To(HaveOccurred())
+			_, err = i.GetPCIDevices()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("GetNICs", func() {
+		It("serves repeated calls from the cache", func() {
+			ghwLibMock.EXPECT().Network().Return(&ghw.NetworkInfo{NICs: []*ghw.NIC{{Name: "eth0"}}}, nil).Times(1)
+			for n := 0; n < 2; n++ {
+				nics, err := i.GetNICs()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(nics).To(HaveLen(1))
+			}
+		})
+	})
+})
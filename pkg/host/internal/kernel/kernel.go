@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
@@ -19,10 +25,12 @@ import (
 
 type kernel struct {
 	utilsHelper utils.CmdInterface
+	// inventory is invalidated when the driver of a device changes, the cached PCI devices report their driver
+	inventory types.InventoryInterface
 }
 
-func New(utilsHelper utils.CmdInterface) types.KernelInterface {
-	return &kernel{utilsHelper: utilsHelper}
+func New(utilsHelper utils.CmdInterface, inventory types.InventoryInterface) types.KernelInterface {
+	return &kernel{utilsHelper: utilsHelper, inventory: inventory}
 }
 
 func (k *kernel) LoadKernelModule(name string, args ...string) error {
@@ -122,7 +130,9 @@ func (k *kernel) Unbind(pciAddr string) error {
 func (k *kernel) BindDpdkDriver(pciAddr, driver string) error {
 	log.Log.V(2).Info("BindDpdkDriver(): bind device to driver",
 		"device", pciAddr, "driver", driver)
-	if err := k.BindDriverByBusAndDevice(consts.BusPci, pciAddr, driver); err != nil {
+	err := k.BindDriverByBusAndDevice(consts.BusPci, pciAddr, driver)
+	k.recordBindError(pciAddr, err)
+	if err != nil {
 		_, innerErr := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "iommu_group"))
 		if innerErr != nil {
 			log.Log.Error(err, "Could not read IOMMU group for device", "device", pciAddr)
@@ -138,6 +148,12 @@ func (k *kernel) BindDpdkDriver(pciAddr, driver string) error {
 // Bind the device given by "pciAddr" to the default driver
 func (k *kernel) BindDefaultDriver(pciAddr string) error {
 	log.Log.V(2).Info("BindDefaultDriver(): bind device to default driver", "device", pciAddr)
+	err := k.bindDefaultDriver(pciAddr)
+	k.recordBindError(pciAddr, err)
+	return err
+}
+
+func (k *kernel) bindDefaultDriver(pciAddr string) error {
 
 	curDriver, err := getDriverByBusAndDevice(consts.BusPci, pciAddr)
 	if err != nil {
@@ -156,6 +172,7 @@ func (k *kernel) BindDefaultDriver(pciAddr string) error {
 	if err := setDriverOverride(consts.BusPci, pciAddr, ""); err != nil {
 		return err
 	}
+	defer k.inventory.InvalidateInventory()
 	if err := probeDriver(consts.BusPci, pciAddr); err != nil {
 		return err
 	}
@@ -187,6 +204,7 @@ func (k *kernel) BindDriverByBusAndDevice(bus, device, driver string) error {
 	if err := setDriverOverride(bus, device, driver); err != nil {
 		return err
 	}
+	defer k.inventory.InvalidateInventory()
 	if err := bindDriver(bus, device, driver); err != nil {
 		return err
 	}
@@ -234,6 +252,7 @@ func (k *kernel) UnbindDriverByBusAndDevice(bus, device string) error {
 		log.Log.V(2).Info("UnbindDriverByBusAndDevice(): device has no driver", "bus", bus, "device", device)
 		return nil
 	}
+	defer k.inventory.InvalidateInventory()
 	return unbindDriver(bus, device, driver)
 }
 
@@ -543,6 +562,129 @@ func (k *kernel) ReloadDriver(driverName string) error {
 	return nil
 }
 
+// modprobeConfPath returns the path of the modprobe.d file of the operator for the kernel module
+func modprobeConfPath(module string) string {
+	path := filepath.Join(consts.ModprobeConfDir, "sriov-network-operator-"+module+".conf")
+	if !vars.UsingSystemdMode {
+		path = filepath.Join(consts.Host, path)
+	}
+	return filepath.Join(vars.FilesystemRoot, path)
+}
+
+// WriteModprobeConf writes the options of the kernel module to its modprobe.d file of the host, so they apply
+// whenever the module is loaded, or removes the file when there is no option. It returns true if the file changed.
+func (k *kernel) WriteModprobeConf(module string, options map[string]string) (bool, error) {
+	path := modprobeConfPath(module)
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read the modprobe configuration of %s: %v", module, err)
+	}
+	exists := err == nil
+
+	if len(options) == 0 {
+		if !exists {
+			return false, nil
+		}
+		log.Log.Info("WriteModprobeConf(): remove the module options", "module", module)
+		if err := utils.RemoveHostFile(path); err != nil {
+			return false, fmt.Errorf("failed to remove the modprobe configuration of %s: %v", module, err)
+		}
+		return true, nil
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	line := "options " + module
+	for _, name := range names {
+		line += " " + name + "=" + options[name]
+	}
+	desired := "# managed by the sriov-network-operator\n" + line + "\n"
+	if exists && string(current) == desired {
+		return false, nil
+	}
+
+	log.Log.Info("WriteModprobeConf(): write the module options", "module", module, "options", options)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create the modprobe configuration directory: %v", err)
+	}
+	if err := utils.WriteHostFile(path, []byte(desired), 0644); err != nil {
+		return false, fmt.Errorf("failed to write the modprobe configuration of %s: %v", module, err)
+	}
+	return true, nil
+}
+
+// KernelModuleOptionsApplied returns false if the kernel module is loaded with parameters that differ from the
+// options, the module must then be reloaded for them to apply. The boolean parameters read Y or N.
+func (k *kernel) KernelModuleOptionsApplied(module string, options map[string]string) (bool, error) {
+	moduleDir := filepath.Join(vars.FilesystemRoot, consts.SysModule, module)
+	if _, err := os.Stat(moduleDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to check if the kernel module %s is loaded: %v", module, err)
+	}
+	for name, value := range options {
+		data, err := os.ReadFile(filepath.Join(moduleDir, "parameters", name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// the module of this kernel doesn't have the parameter, reloading it wouldn't help
+				log.Log.Info("KernelModuleOptionsApplied(): unknown module parameter", "module", module, "parameter", name)
+				continue
+			}
+			return false, fmt.Errorf("failed to read the parameter %s of the kernel module %s: %v", name, module, err)
+		}
+		if normalizeModuleParameter(string(data)) != normalizeModuleParameter(value) {
+			log.Log.V(2).Info("KernelModuleOptionsApplied(): parameter differs", "module", module,
+				"parameter", name, "current", strings.TrimSpace(string(data)), "desired", value)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ReloadKernelModules unloads the loaded kernel modules, given from the dependents to their dependencies, and
+// loads them again so they apply the options of modprobe.d. The modules built in the kernel are not reloaded.
+func (k *kernel) ReloadKernelModules(modules ...string) error {
+	for _, module := range modules {
+		// unloading a module also unloads its unused dependencies, so the next ones may be gone already
+		if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysModule, module, "initstate")); err != nil {
+			continue
+		}
+		log.Log.Info("ReloadKernelModules(): unload kernel module", "name", module)
+		program, args := utils.GetChrootCommand("modprobe", "-r", module)
+		_, stderr, err := k.utilsHelper.RunCommand(program, args...)
+		if err != nil {
+			return fmt.Errorf("failed to unload the kernel module %s: %v %s", module, err, stderr)
+		}
+	}
+	loadOrder := make([]string, 0, len(modules))
+	for i := len(modules) - 1; i >= 0; i-- {
+		loadOrder = append(loadOrder, modules[i])
+	}
+	log.Log.Info("ReloadKernelModules(): load kernel modules", "names", loadOrder)
+	program, args := utils.GetChrootCommand("modprobe", append([]string{"-a"}, loadOrder...)...)
+	_, stderr, err := k.utilsHelper.RunCommand(program, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load the kernel modules %s: %v %s", strings.Join(loadOrder, ", "), err, stderr)
+	}
+	return nil
+}
+
+// normalizeModuleParameter maps the values of the boolean module parameters to Y or N
+func normalizeModuleParameter(value string) string {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "1", "y", "yes", "on", "true":
+		return "Y"
+	case "0", "n", "no", "off", "false":
+		return "N"
+	}
+	return value
+}
+
 func (k *kernel) GetOSPrettyName() (string, error) {
 	path := internal.GenericOSReleaseFile
 	if !vars.UsingSystemdMode {
@@ -564,6 +706,134 @@ func (k *kernel) GetOSPrettyName() (string, error) {
 	return "", fmt.Errorf("failed to find pretty operating system name")
 }
 
+// GetIommuKernelArgs returns the kernel arguments enabling the IOMMU in passthrough mode on the architecture
+// of the node. The arm64 SMMU is enabled by the firmware (ACPI IORT or device tree) and has no equivalent
+// of intel_iommu=on.
+func (k *kernel) GetIommuKernelArgs() []string {
+	if vars.Architecture == consts.ArchARM64 {
+		return []string{consts.KernelArgIommuPassthrough}
+	}
+	return []string{consts.KernelArgIntelIommu, consts.KernelArgIommuPt}
+}
+
+// GetHugepageSizes returns the hugepage sizes supported by the kernel, from the smallest. They depend on the
+// architecture and on the page size of the kernel, e.g. 2048kB and 1048576kB on x86_64 but 2048kB, 32768kB and
+// 1048576kB on arm64 with 4K pages and 2048kB, 524288kB and 16777216kB on arm64 with 64K pages.
+func (k *kernel) GetHugepageSizes() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysKernelMmHugepages))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the hugepage sizes: %v", err)
+	}
+	sizes := []string{}
+	for _, entry := range entries {
+		size, found := strings.CutPrefix(entry.Name(), "hugepages-")
+		if found {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimSuffix(sizes[i], "kB"))
+		b, _ := strconv.Atoi(strings.TrimSuffix(sizes[j], "kB"))
+		return a < b
+	})
+	return sizes, nil
+}
+
+// GetNumHugepages returns the number of hugepages allocated by the kernel, of all the sizes. The hugepages
+// are allocated at boot by the hugepages kernel argument or later through nr_hugepages.
+func (k *kernel) GetNumHugepages() (int, error) {
+	sizes, err := k.GetHugepageSizes()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, size := range sizes {
+		path := filepath.Join(vars.FilesystemRoot, consts.SysKernelMmHugepages, "hugepages-"+size, "nr_hugepages")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the number of %s hugepages: %v", size, err)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse the number of %s hugepages: %v", size, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// GetIsolatedCpus returns the CPUs isolated from the kernel scheduler by the isolcpus kernel argument, e.g.
+// 2-15, empty when none is
+func (k *kernel) GetIsolatedCpus() (string, error) {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysCpuIsolated))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read the isolated CPUs: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// probeErrorRegexps match the failed probes of the kernel log, "<driver> <address>: probe with driver <driver> failed
+// with error <errno>" of the recent kernels and "<driver>: probe of <address> failed with error <errno>" of the older ones
+var probeErrorRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`\S+ ([0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]): (probe with driver \S+ failed with error -?\d+)`),
+	regexp.MustCompile(`(\S+): probe of ([0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]) failed with error (-?\d+)`),
+}
+
+var (
+	bindErrorsMu sync.Mutex
+	// bindErrors are the errors of the last failed binds of the PCI devices by address, shared by all the host managers
+	// of the process
+	bindErrors = map[string]string{}
+)
+
+// GetBindError returns the error of the last failed bind of the PCI device to a driver, the error of its probe when
+// the kernel logged it, empty when the last bind succeeded
+func (k *kernel) GetBindError(pciAddr string) string {
+	bindErrorsMu.Lock()
+	defer bindErrorsMu.Unlock()
+	return bindErrors[pciAddr]
+}
+
+// recordBindError records the result of the bind of the PCI device, the kernel log is only read when the bind fails
+func (k *kernel) recordBindError(pciAddr string, bindErr error) {
+	bindErrorsMu.Lock()
+	defer bindErrorsMu.Unlock()
+	if bindErr == nil {
+		delete(bindErrors, pciAddr)
+		return
+	}
+	if errors.Is(bindErr, snerrors.ErrPciAddressGuarded) {
+		return
+	}
+	bindErrors[pciAddr] = bindErr.Error()
+	stdout, stderr, err := k.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s dmesg --notime", utils.GetChrootExtension()))
+	if err != nil {
+		log.Log.V(2).Info("recordBindError(): unable to read the kernel log", "error", err, "stderr", strings.TrimSpace(stderr))
+		return
+	}
+	if probeErr, found := parseProbeErrors(stdout)[pciAddr]; found {
+		bindErrors[pciAddr] = probeErr
+	}
+}
+
+func parseProbeErrors(kernelLog string) map[string]string {
+	errs := map[string]string{}
+	for _, line := range strings.Split(kernelLog, "\n") {
+		if m := probeErrorRegexps[0].FindStringSubmatch(line); m != nil {
+			errs[m[1]] = m[2]
+		} else if m := probeErrorRegexps[1].FindStringSubmatch(line); m != nil {
+			errs[m[2]] = fmt.Sprintf("probe with driver %s failed with error %s", m[1], m[3])
+		}
+	}
+	return errs
+}
+
 // IsKernelLockdownMode returns true when kernel lockdown mode is enabled
 // TODO: change this to return error
 func (k *kernel) IsKernelLockdownMode() bool {
@@ -598,8 +868,11 @@ func getDriverByBusAndDevice(bus, device string) (string, error) {
 // binds device to the provide driver
 func bindDriver(bus, device, driver string) error {
 	log.Log.V(2).Info("bindDriver(): bind to driver", "bus", bus, "device", device, "driver", driver)
+	if err := checkGuard(bus, device); err != nil {
+		return err
+	}
 	bindPath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers", driver, "bind")
-	err := os.WriteFile(bindPath, []byte(device), os.ModeAppend)
+	err := utils.WriteHostFile(bindPath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "bindDriver(): failed to bind driver", "bus", bus, "device", device, "driver", driver)
 		return err
@@ -607,11 +880,22 @@ func bindDriver(bus, device, driver string) error {
 	return nil
 }
 
+// checkGuard returns an error when the PCI address guard of the node doesn't allow the device to be changed
+func checkGuard(bus, device string) error {
+	if bus != consts.BusPci {
+		return nil
+	}
+	return guard.Check(device)
+}
+
 // unbind device from the driver
 func unbindDriver(bus, device, driver string) error {
 	log.Log.V(2).Info("unbindDriver(): unbind from driver", "bus", bus, "device", device, "driver", driver)
+	if err := checkGuard(bus, device); err != nil {
+		return err
+	}
 	unbindPath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers", driver, "unbind")
-	err := os.WriteFile(unbindPath, []byte(device), os.ModeAppend)
+	err := utils.WriteHostFile(unbindPath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "unbindDriver(): failed to unbind driver", "bus", bus, "device", device, "driver", driver)
 		return err
@@ -622,8 +906,11 @@ func unbindDriver(bus, device, driver string) error {
 // probes driver for device on the bus
 func probeDriver(bus, device string) error {
 	log.Log.V(2).Info("probeDriver(): drivers probe", "bus", bus, "device", device)
+	if err := checkGuard(bus, device); err != nil {
+		return err
+	}
 	probePath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers_probe")
-	err := os.WriteFile(probePath, []byte(device), os.ModeAppend)
+	err := utils.WriteHostFile(probePath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "probeDriver(): failed to trigger driver probe", "bus", bus, "device", device)
 		return err
@@ -635,6 +922,9 @@ func probeDriver(bus, device string) error {
 // resets override if override arg is "",
 // if device doesn't support overriding (has no driver_override path), does nothing
 func setDriverOverride(bus, device, override string) error {
+	if err := checkGuard(bus, device); err != nil {
+		return err
+	}
 	driverOverridePath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices", device, "driver_override")
 	if _, err := os.Stat(driverOverridePath); err != nil {
 		if os.IsNotExist(err) {
@@ -651,7 +941,7 @@ func setDriverOverride(bus, device, override string) error {
 		log.Log.V(2).Info("setDriverOverride(): reset driver override for device", "bus", bus, "device", device)
 		overrideData = []byte("\x00")
 	}
-	err := os.WriteFile(driverOverridePath, overrideData, os.ModeAppend)
+	err := utils.WriteHostFile(driverOverridePath, overrideData, os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "setDriverOverride(): fail to write driver_override for device",
 			"bus", bus, "device", device, "driver", override)
@@ -1,22 +1,38 @@
 package kernel
 
 import (
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
 
 var _ = Describe("Kernel", func() {
+	var inventoryMock *hostMockPkg.MockHostManagerInterface
+	BeforeEach(func() {
+		inventoryMock = hostMockPkg.NewMockHostManagerInterface(gomock.NewController(GinkgoT()))
+		inventoryMock.EXPECT().InvalidateInventory().AnyTimes()
+	})
 	Context("Drivers", func() {
 		var (
-			k types.KernelInterface
+			k         types.KernelInterface
+			utilsMock *utilsMockPkg.MockCmdInterface
 		)
 		BeforeEach(func() {
-			k = New(nil)
+			utilsMock = utilsMockPkg.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			// the kernel log is read when a bind fails
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", nil).AnyTimes()
+			k = New(utilsMock, inventoryMock)
 		})
 		Context("Unbind, UnbindDriverByBusAndDevice", func() {
 			It("unknown device", func() {
@@ -140,6 +156,9 @@ var _ = Describe("Kernel", func() {
 					Symlinks: map[string]string{
 						"/sys/bus/pci/devices/0000:d8:00.0/driver": "../../../../bus/pci/drivers/vfio-pci"},
 				})
+				// the driver doesn't change, the cached inventory is kept
+				inventoryMock = hostMockPkg.NewMockHostManagerInterface(gomock.NewController(GinkgoT()))
+				k = New(utilsMock, inventoryMock)
 				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).NotTo(HaveOccurred())
 			})
 			It("bind to wrong driver", func() {
@@ -155,6 +174,10 @@ var _ = Describe("Kernel", func() {
 						"/sys/bus/pci/drivers/vfio-pci/bind":                {},
 						"/sys/bus/pci/devices/0000:d8:00.0/driver_override": {}},
 				})
+				// the cached PCI devices report the driver of the device, they are dropped on the unbind and the bind
+				inventoryMock = hostMockPkg.NewMockHostManagerInterface(gomock.NewController(GinkgoT()))
+				inventoryMock.EXPECT().InvalidateInventory().Times(2)
+				k = New(utilsMock, inventoryMock)
 				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).NotTo(HaveOccurred())
 				// should unbind from driver1
 				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/drivers/test-driver/unbind", "0000:d8:00.0")
@@ -174,6 +197,25 @@ var _ = Describe("Kernel", func() {
 				})
 				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).To(HaveOccurred())
 			})
+			It("records the probe error of a failed bind", func() {
+				utilsMock = utilsMockPkg.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+				k = New(utilsMock, inventoryMock)
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{
+						"/sys/bus/pci/devices/0000:d8:00.0"},
+					Files: map[string][]byte{
+						"/sys/bus/pci/devices/0000:d8:00.0/driver_override": {}},
+				})
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return(
+					"vfio-pci 0000:d8:00.0: probe with driver vfio-pci failed with error -22\n", "", nil)
+				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).To(HaveOccurred())
+				Expect(k.GetBindError("0000:d8:00.0")).To(Equal("probe with driver vfio-pci failed with error -22"))
+
+				// the kernel log isn't read when the bind succeeds
+				Expect(os.MkdirAll(filepath.Join(vars.FilesystemRoot, "/sys/bus/pci/drivers/vfio-pci"), 0755)).To(Succeed())
+				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).To(Succeed())
+				Expect(k.GetBindError("0000:d8:00.0")).To(BeEmpty())
+			})
 		})
 		Context("BindDriverByBusAndDevice", func() {
 			It("device doesn't support driver_override", func() {
@@ -196,4 +238,120 @@ var _ = Describe("Kernel", func() {
 			})
 		})
 	})
+	Context("Architecture", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(nil, nil)
+			arch := vars.Architecture
+			DeferCleanup(func() { vars.Architecture = arch })
+		})
+		It("enables the IOMMU on amd64", func() {
+			vars.Architecture = consts.ArchAMD64
+			Expect(k.GetIommuKernelArgs()).To(Equal([]string{consts.KernelArgIntelIommu, consts.KernelArgIommuPt}))
+		})
+		It("sets the SMMU passthrough mode on arm64", func() {
+			vars.Architecture = consts.ArchARM64
+			Expect(k.GetIommuKernelArgs()).To(Equal([]string{consts.KernelArgIommuPassthrough}))
+		})
+		It("returns the hugepage sizes from the smallest", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{
+				"/sys/kernel/mm/hugepages/hugepages-16777216kB",
+				"/sys/kernel/mm/hugepages/hugepages-2048kB",
+				"/sys/kernel/mm/hugepages/hugepages-524288kB"}})
+			Expect(k.GetHugepageSizes()).To(Equal([]string{"2048kB", "524288kB", "16777216kB"}))
+		})
+		It("returns no hugepage size if the kernel doesn't support them", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/sys/kernel"}})
+			Expect(k.GetHugepageSizes()).To(BeEmpty())
+		})
+		It("sums the hugepages of all the sizes", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{
+					"/sys/kernel/mm/hugepages/hugepages-2048kB",
+					"/sys/kernel/mm/hugepages/hugepages-1048576kB"},
+				Files: map[string][]byte{
+					"/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages":    []byte("512\n"),
+					"/sys/kernel/mm/hugepages/hugepages-1048576kB/nr_hugepages": []byte("4\n")},
+			})
+			Expect(k.GetNumHugepages()).To(Equal(516))
+		})
+		It("returns the isolated CPUs", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/devices/system/cpu"},
+				Files: map[string][]byte{"/sys/devices/system/cpu/isolated": []byte("2-15\n")},
+			})
+			Expect(k.GetIsolatedCpus()).To(Equal("2-15"))
+		})
+		It("returns no isolated CPU if the kernel doesn't report them", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/sys/devices"}})
+			Expect(k.GetIsolatedCpus()).To(BeEmpty())
+		})
+	})
+	Context("Probe errors", func() {
+		It("parses the failed probes of the kernel log", func() {
+			Expect(parseProbeErrors(`vfio-pci 0000:3b:02.0: probe with driver vfio-pci failed with error -22
+iavf 0000:3b:02.1: Invalid MAC address 00:00:00:00:00:00, using random
+iavf: probe of 0000:3b:02.1 failed with error -5
+vfio-pci 0000:3b:02.0: probe with driver vfio-pci failed with error -16
+`)).To(Equal(map[string]string{
+				"0000:3b:02.0": "probe with driver vfio-pci failed with error -16",
+				"0000:3b:02.1": "probe with driver iavf failed with error -5",
+			}))
+		})
+	})
+	Context("Module options", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(nil, nil)
+		})
+		It("writes and removes the options of the module", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc"}})
+			changed, err := k.WriteModprobeConf("vfio_pci", map[string]string{"ids": "8086:154c", "disable_idle_d3": "1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			helpers.GinkgoAssertFileContentsEquals("/host/etc/modprobe.d/sriov-network-operator-vfio_pci.conf",
+				"# managed by the sriov-network-operator\noptions vfio_pci disable_idle_d3=1 ids=8086:154c\n")
+
+			changed, err = k.WriteModprobeConf("vfio_pci", map[string]string{"disable_idle_d3": "1", "ids": "8086:154c"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+
+			changed, err = k.WriteModprobeConf("vfio_pci", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			_, err = os.Stat(filepath.Join(vars.FilesystemRoot, "/host/etc/modprobe.d/sriov-network-operator-vfio_pci.conf"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("compares the parameters of the loaded module", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/module/vfio/parameters"},
+				Files: map[string][]byte{"/sys/module/vfio/parameters/enable_unsafe_noiommu_mode": []byte("N\n")},
+			})
+			Expect(k.KernelModuleOptionsApplied("vfio", map[string]string{"enable_unsafe_noiommu_mode": "1"})).To(BeFalse())
+			Expect(k.KernelModuleOptionsApplied("vfio", map[string]string{"enable_unsafe_noiommu_mode": "0"})).To(BeTrue())
+			// the options apply when the module loads
+			Expect(k.KernelModuleOptionsApplied("vfio_pci", map[string]string{"disable_idle_d3": "1"})).To(BeTrue())
+		})
+		It("reloads the modules without a shell", func() {
+			testCtrl := gomock.NewController(GinkgoT())
+			defer testCtrl.Finish()
+			utilsMock := utilsMockPkg.NewMockCmdInterface(testCtrl)
+			k = New(utilsMock, inventoryMock)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/module/vfio_pci", "/sys/module/vfio"},
+				Files: map[string][]byte{"/sys/module/vfio_pci/initstate": []byte("live\n"), "/sys/module/vfio/initstate": []byte("live\n")},
+			})
+			host := vars.FilesystemRoot + consts.Host
+			gomock.InOrder(
+				utilsMock.EXPECT().RunCommand("chroot", host, "modprobe", "-r", "vfio_pci").Return("", "", nil),
+				utilsMock.EXPECT().RunCommand("chroot", host, "modprobe", "-r", "vfio").Return("", "", nil),
+				utilsMock.EXPECT().RunCommand("chroot", host, "modprobe", "-a", "vfio", "vfio_iommu_type1", "vfio_pci").Return("", "", nil),
+			)
+			Expect(k.ReloadKernelModules("vfio_pci", "vfio_iommu_type1", "vfio")).To(Succeed())
+		})
+	})
 })
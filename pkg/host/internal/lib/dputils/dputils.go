@@ -2,12 +2,21 @@ package dputils
 
 import (
 	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
 )
 
 func New() DPUtilsLib {
 	return &libWrapper{}
 }
 
+// NewInNamespace returns the DPUtilsLib finding the netdevs of the PCI devices in the network namespace of the netlink
+// library, the sysfs of the process only lists the netdevs of its own namespace. The other functions read the PCI
+// devices from the sysfs of the process.
+func NewInNamespace(netlinkLib netlink.NetlinkLib) DPUtilsLib {
+	return &netNsWrapper{DPUtilsLib: New(), netlinkLib: netlinkLib}
+}
+
 //go:generate ../../../../../bin/mockgen -destination mock/mock_dputils.go -source dputils.go
 type DPUtilsLib interface {
 	// GetNetNames returns host net interface names as string for a PCI device from its pci address
@@ -76,3 +85,27 @@ func (w *libWrapper) SriovConfigured(addr string) bool {
 func (w *libWrapper) GetVFList(pf string) (vfList []string, err error) {
 	return dputils.GetVFList(pf)
 }
+
+type netNsWrapper struct {
+	DPUtilsLib
+	netlinkLib netlink.NetlinkLib
+}
+
+// GetNetNames returns the names of the netdevs of the network namespace whose device is the PCI device
+func (w *netNsWrapper) GetNetNames(pciAddr string) ([]string, error) {
+	links, err := w.netlinkLib.LinkList()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, link := range links {
+		name := link.Attrs().Name
+		// the virtual netdevs have no bus address
+		busInfo, err := w.netlinkLib.LinkBusInfo(name)
+		if err != nil || busInfo != pciAddr {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,31 @@
+package dputils
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
+)
+
+func TestGetNetNamesInNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+	netlinkLibMock := netlinkMockPkg.NewMockNetlinkLib(gomock.NewController(t))
+	netlinkLibMock.EXPECT().LinkList().Return([]netlinkPkg.Link{
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "lo"}},
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "pf0hpf"}},
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "pf0vf0"}},
+		&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "p1"}},
+	}, nil)
+	netlinkLibMock.EXPECT().LinkBusInfo("lo").Return("", syscall.EOPNOTSUPP)
+	netlinkLibMock.EXPECT().LinkBusInfo("pf0hpf").Return("0000:03:00.0", nil)
+	netlinkLibMock.EXPECT().LinkBusInfo("pf0vf0").Return("0000:03:00.0", nil)
+	netlinkLibMock.EXPECT().LinkBusInfo("p1").Return("0000:03:00.1", nil)
+
+	// the representors of a PF report its PCI address
+	g.Expect(NewInNamespace(netlinkLibMock).GetNetNames("0000:03:00.0")).To(Equal([]string{"pf0hpf", "pf0vf0"}))
+}
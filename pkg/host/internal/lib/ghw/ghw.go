@@ -0,0 +1,29 @@
+package ghw
+
+import (
+	"github.com/jaypipes/ghw"
+)
+
+func New() GHWLib {
+	return &libWrapper{}
+}
+
+//go:generate ../../../../../bin/mockgen -destination mock/mock_ghw.go -source ghw.go
+type GHWLib interface {
+	// PCI returns the PCI devices of the host
+	PCI() (*ghw.PCIInfo, error)
+	// Network returns the network interfaces of the host
+	Network() (*ghw.NetworkInfo, error)
+}
+
+type libWrapper struct{}
+
+// PCI returns the PCI devices of the host
+func (w *libWrapper) PCI() (*ghw.PCIInfo, error) {
+	return ghw.PCI()
+}
+
+// Network returns the network interfaces of the host
+func (w *libWrapper) Network() (*ghw.NetworkInfo, error) {
+	return ghw.Network()
+}
@@ -2,12 +2,54 @@ package netlink
 
 import (
 	"net"
+	"runtime"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 )
 
 func New() NetlinkLib {
-	return &libWrapper{}
+	return &libWrapper{handle: &netlink.Handle{}, ethtoolFd: -1}
+}
+
+// NewInNamespace returns the NetlinkLib sending its requests in the network namespace of the path, e.g.
+// /var/run/netns/dpu or /proc/1234/ns/net
+func NewInNamespace(path string) (NetlinkLib, error) {
+	ns, err := netns.GetFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	// the sockets of the handle are opened in the namespace, it isn't needed afterwards
+	defer ns.Close()
+	handle, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return nil, err
+	}
+	ethtoolFd, err := socketAt(ns)
+	if err != nil {
+		handle.Delete()
+		return nil, err
+	}
+	return &libWrapper{handle: handle, ethtoolFd: ethtoolFd}, nil
+}
+
+// socketAt opens the socket of the ethtool requests in the network namespace, the requests of a socket apply to the
+// netdevs of the namespace it was opened in
+func socketAt(ns netns.NsHandle) (int, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origNs, err := netns.Get()
+	if err != nil {
+		return -1, err
+	}
+	defer origNs.Close()
+	if err := netns.Set(ns); err != nil {
+		return -1, err
+	}
+	defer netns.Set(origNs)
+	return unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
 }
 
 type Link interface {
@@ -24,9 +66,22 @@ type NetlinkLib interface {
 	LinkSetVfPortGUID(link Link, vf int, portguid net.HardwareAddr) error
 	// LinkByName finds a link by name and returns a pointer to the object.
 	LinkByName(name string) (Link, error)
+	// LinkList gets a list of link devices.
+	// Equivalent to: `ip link show`
+	LinkList() ([]Link, error)
+	// LinkBusInfo returns the bus address of the device of the link, e.g. the PCI address of a PF, a VF or of the PF
+	// of a representor.
+	// Equivalent to: `ethtool -i $name | grep bus-info`
+	LinkBusInfo(name string) (string, error)
 	// LinkSetVfHardwareAddr sets the hardware address of a vf for the link.
 	// Equivalent to: `ip link set $link vf $vf mac $hwaddr`
 	LinkSetVfHardwareAddr(link Link, vf int, hwaddr net.HardwareAddr) error
+	// LinkSetVfSpoofchk enables or disables the spoof check of a vf for the link.
+	// Equivalent to: `ip link set $link vf $vf spoofchk $check`
+	LinkSetVfSpoofchk(link Link, vf int, check bool) error
+	// LinkSetVfTrust enables or disables the trust mode of a vf for the link.
+	// Equivalent to: `ip link set $link vf $vf trust $state`
+	LinkSetVfTrust(link Link, vf int, state bool) error
 	// LinkSetUp enables the link device.
 	// Equivalent to: `ip link set $link up`
 	LinkSetUp(link Link) error
@@ -40,41 +95,92 @@ type NetlinkLib interface {
 	DevLinkSetEswitchMode(dev *netlink.DevlinkDevice, newMode string) error
 }
 
-type libWrapper struct{}
+type libWrapper struct {
+	// handle sends the requests in the network namespace of the library
+	handle *netlink.Handle
+	// ethtoolFd is the socket of the ethtool requests in the network namespace of the library, -1 for the network
+	// namespace of the process
+	ethtoolFd int
+}
 
 // LinkSetVfNodeGUID sets the node GUID of a vf for the link.
 // Equivalent to: `ip link set dev $link vf $vf node_guid $nodeguid`
 func (w *libWrapper) LinkSetVfNodeGUID(link Link, vf int, nodeguid net.HardwareAddr) error {
-	return netlink.LinkSetVfNodeGUID(link, vf, nodeguid)
+	return w.handle.LinkSetVfGUID(link, vf, nodeguid, nl.IFLA_VF_IB_NODE_GUID)
 }
 
 // LinkSetVfPortGUID sets the port GUID of a vf for the link.
 // Equivalent to: `ip link set dev $link vf $vf port_guid $portguid`
 func (w *libWrapper) LinkSetVfPortGUID(link Link, vf int, portguid net.HardwareAddr) error {
-	return netlink.LinkSetVfPortGUID(link, vf, portguid)
+	return w.handle.LinkSetVfGUID(link, vf, portguid, nl.IFLA_VF_IB_PORT_GUID)
 }
 
-// LinkByName finds a link by name and returns a pointer to the object.// LinkByName finds a link by name and returns a pointer to the object.
+// LinkByName finds a link by name and returns a pointer to the object.
 func (w *libWrapper) LinkByName(name string) (Link, error) {
-	return netlink.LinkByName(name)
+	return w.handle.LinkByName(name)
+}
+
+// LinkList gets a list of link devices.
+// Equivalent to: `ip link show`
+func (w *libWrapper) LinkList() ([]Link, error) {
+	links, err := w.handle.LinkList()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Link, 0, len(links))
+	for _, link := range links {
+		result = append(result, link)
+	}
+	return result, nil
+}
+
+// LinkBusInfo returns the bus address of the device of the link, e.g. the PCI address of a PF, a VF or of the PF
+// of a representor.
+// Equivalent to: `ethtool -i $name | grep bus-info`
+func (w *libWrapper) LinkBusInfo(name string) (string, error) {
+	fd := w.ethtoolFd
+	if fd < 0 {
+		var err error
+		if fd, err = unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0); err != nil {
+			return "", err
+		}
+		defer unix.Close(fd)
+	}
+	info, err := unix.IoctlGetEthtoolDrvinfo(fd, name)
+	if err != nil {
+		return "", err
+	}
+	return unix.ByteSliceToString(info.Bus_info[:]), nil
 }
 
 // LinkSetVfHardwareAddr sets the hardware address of a vf for the link.
 // Equivalent to: `ip link set $link vf $vf mac $hwaddr`
 func (w *libWrapper) LinkSetVfHardwareAddr(link Link, vf int, hwaddr net.HardwareAddr) error {
-	return netlink.LinkSetVfHardwareAddr(link, vf, hwaddr)
+	return w.handle.LinkSetVfHardwareAddr(link, vf, hwaddr)
+}
+
+// LinkSetVfSpoofchk enables or disables the spoof check of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf spoofchk $check`
+func (w *libWrapper) LinkSetVfSpoofchk(link Link, vf int, check bool) error {
+	return w.handle.LinkSetVfSpoofchk(link, vf, check)
+}
+
+// LinkSetVfTrust enables or disables the trust mode of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf trust $state`
+func (w *libWrapper) LinkSetVfTrust(link Link, vf int, state bool) error {
+	return w.handle.LinkSetVfTrust(link, vf, state)
 }
 
 // LinkSetUp enables the link device.
 // Equivalent to: `ip link set $link up`
 func (w *libWrapper) LinkSetUp(link Link) error {
-	return netlink.LinkSetUp(link)
+	return w.handle.LinkSetUp(link)
 }
 
 // DevlinkGetDeviceByName provides a pointer to devlink device and nil error,
 // otherwise returns an error code.
 func (w *libWrapper) DevLinkGetDeviceByName(bus string, device string) (*netlink.DevlinkDevice, error) {
-	return netlink.DevLinkGetDeviceByName(bus, device)
+	return w.handle.DevLinkGetDeviceByName(bus, device)
 }
 
 // DevLinkSetEswitchMode sets eswitch mode if able to set successfully or
@@ -82,5 +188,5 @@ func (w *libWrapper) DevLinkGetDeviceByName(bus string, device string) (*netlink
 // Equivalent to: `devlink dev eswitch set $dev mode switchdev`
 // Equivalent to: `devlink dev eswitch set $dev mode legacy`
 func (w *libWrapper) DevLinkSetEswitchMode(dev *netlink.DevlinkDevice, newMode string) error {
-	return netlink.DevLinkSetEswitchMode(dev, newMode)
+	return w.handle.DevLinkSetEswitchMode(dev, newMode)
 }
@@ -0,0 +1,71 @@
+package netlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// newTestNamespace creates a network namespace with an ifb link and returns the path of the namespace, the test is
+// skipped without the privileges to create it
+func newTestNamespace(t *testing.T) string {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origNs, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer origNs.Close()
+	ns, err := netns.New()
+	if err != nil {
+		t.Skipf("failed to create a network namespace: %v", err)
+	}
+	defer netns.Set(origNs)
+	// the namespace is kept by a bind mount of its path once the test thread leaves it
+	path := filepath.Join(t.TempDir(), "netns")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Mount(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()), path, "", unix.MS_BIND, ""); err != nil {
+		ns.Close()
+		t.Skipf("failed to keep the network namespace: %v", err)
+	}
+	ns.Close()
+	t.Cleanup(func() { unix.Unmount(path, unix.MNT_DETACH) })
+	if err := netlink.LinkAdd(&netlink.Ifb{LinkAttrs: netlink.LinkAttrs{Name: "ifb-ns"}}); err != nil {
+		t.Skipf("failed to create a link in the network namespace: %v", err)
+	}
+	return path
+}
+
+func TestNewInNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := newTestNamespace(t)
+
+	lib, err := NewInNamespace(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	links, err := lib.LinkList()
+	g.Expect(err).ToNot(HaveOccurred())
+	names := []string{}
+	for _, link := range links {
+		names = append(names, link.Attrs().Name)
+	}
+	g.Expect(names).To(ConsistOf("lo", "ifb-ns"))
+	link, err := lib.LinkByName("ifb-ns")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lib.LinkSetUp(link)).To(Succeed())
+	// the link is only in the namespace
+	_, err = New().LinkByName("ifb-ns")
+	g.Expect(err).To(HaveOccurred())
+	// the ifb driver has no bus, the ethtool request still finds the link in the namespace
+	g.Expect(lib.LinkBusInfo("ifb-ns")).To(BeEmpty())
+	_, err = New().LinkBusInfo("ifb-ns")
+	g.Expect(err).To(MatchError(unix.ENODEV))
+}
@@ -0,0 +1,324 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+)
+
+// DCB netlink commands and attributes from linux/dcbnl.h
+const (
+	dcbCmdIeeeSet = 20
+	dcbCmdIeeeGet = 21
+	dcbCmdIeeeDel = 27
+
+	dcbAttrIfname = 1
+	dcbAttrIeee   = 13
+
+	dcbAttrIeeePfc      = 2
+	dcbAttrIeeeAppTable = 3
+	dcbAttrDcbBuffer    = 10
+
+	dcbAttrIeeeApp = 1
+
+	// ieeeAppSelDscp is the selector of the applications mapping a DSCP to a priority
+	ieeeAppSelDscp = 5
+
+	ieeeMaxPrio = 8
+	dscpCount   = 64
+	// sizeofIeeePfc and sizeofDcbBuffer are the sizes of struct ieee_pfc and struct dcbnl_buffer
+	sizeofIeeePfc   = 136
+	sizeofDcbBuffer = 44
+
+	qosTrustPcp  = "pcp"
+	qosTrustDscp = "dscp"
+)
+
+// dcbMsg mirrors struct dcbmsg
+type dcbMsg struct {
+	cmd uint8
+}
+
+func (m *dcbMsg) Len() int {
+	return 4
+}
+
+func (m *dcbMsg) Serialize() []byte {
+	return []byte{unix.AF_UNSPEC, m.cmd, 0, 0}
+}
+
+// dcbApp mirrors struct dcb_app
+type dcbApp struct {
+	selector uint8
+	priority uint8
+	protocol uint16
+}
+
+func (a dcbApp) serialize() []byte {
+	b := make([]byte, 4)
+	b[0] = a.selector
+	b[1] = a.priority
+	binary.NativeEndian.PutUint16(b[2:], a.protocol)
+	return b
+}
+
+// dcbIeee holds the IEEE DCB configuration of a PF, pfc and buffer are the raw struct ieee_pfc and struct
+// dcbnl_buffer, they are nil if the driver doesn't report them
+type dcbIeee struct {
+	pfc    []byte
+	buffer []byte
+	apps   []dcbApp
+}
+
+// parseDcbIeee parses the attributes of the DCB_ATTR_IEEE nest of a DCB_CMD_IEEE_GET reply
+func parseDcbIeee(data []byte) (*dcbIeee, error) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return nil, err
+	}
+	ieee := &dcbIeee{}
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+		case dcbAttrIeeePfc:
+			if len(attr.Value) < sizeofIeeePfc {
+				return nil, fmt.Errorf("truncated PFC attribute")
+			}
+			ieee.pfc = attr.Value[:sizeofIeeePfc]
+		case dcbAttrDcbBuffer:
+			if len(attr.Value) < sizeofDcbBuffer {
+				return nil, fmt.Errorf("truncated buffer attribute")
+			}
+			ieee.buffer = attr.Value[:sizeofDcbBuffer]
+		case dcbAttrIeeeAppTable:
+			apps, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, app := range apps {
+				if app.Attr.Type&nl.NLA_TYPE_MASK != dcbAttrIeeeApp || len(app.Value) < 4 {
+					continue
+				}
+				ieee.apps = append(ieee.apps, dcbApp{
+					selector: app.Value[0],
+					priority: app.Value[1],
+					protocol: binary.NativeEndian.Uint16(app.Value[2:]),
+				})
+			}
+		}
+	}
+	return ieee, nil
+}
+
+// qos returns the QoS settings of the DCB configuration
+func (d *dcbIeee) qos() *sriovnetworkv1.QosConfig {
+	qos := &sriovnetworkv1.QosConfig{Trust: qosTrustPcp}
+	for _, app := range d.apps {
+		if app.selector == ieeeAppSelDscp {
+			qos.Trust = qosTrustDscp
+			break
+		}
+	}
+	if d.pfc != nil {
+		qos.PfcPriorities = []int{}
+		for prio := 0; prio < ieeeMaxPrio; prio++ {
+			if d.pfc[1]&(1<<prio) != 0 {
+				qos.PfcPriorities = append(qos.PfcPriorities, prio)
+			}
+		}
+	}
+	if d.buffer != nil {
+		for prio := 0; prio < ieeeMaxPrio; prio++ {
+			qos.PrioToBuffer = append(qos.PrioToBuffer, int(d.buffer[prio]))
+		}
+		for buffer := 0; buffer < ieeeMaxPrio; buffer++ {
+			qos.BufferSizes = append(qos.BufferSizes, int(binary.NativeEndian.Uint32(d.buffer[ieeeMaxPrio+4*buffer:])))
+		}
+	}
+	return qos
+}
+
+// dscpApps returns the applications mapping every DSCP to the priority of its class selector, the default
+// mapping of the drivers trusting the DSCP
+func dscpApps() []dcbApp {
+	apps := make([]dcbApp, 0, dscpCount)
+	for dscp := 0; dscp < dscpCount; dscp++ {
+		apps = append(apps, dcbApp{selector: ieeeAppSelDscp, priority: uint8(dscp >> 3), protocol: uint16(dscp)})
+	}
+	return apps
+}
+
+// qosChanges returns the IEEE DCB configuration to set and the applications to delete to apply the desired QoS
+// on top of the current configuration, the settings left unset in the desired QoS are not changed
+func qosChanges(current *dcbIeee, desired *sriovnetworkv1.QosConfig) (set *dcbIeee, del []dcbApp, err error) {
+	set = &dcbIeee{}
+	if desired.PfcPriorities != nil {
+		if current.pfc == nil {
+			return nil, nil, fmt.Errorf("the driver doesn't support priority flow control")
+		}
+		var enabled uint8
+		for _, prio := range desired.PfcPriorities {
+			enabled |= 1 << prio
+		}
+		if current.pfc[1] != enabled {
+			set.pfc = append([]byte{}, current.pfc...)
+			set.pfc[1] = enabled
+		}
+	}
+
+	if desired.PrioToBuffer != nil || desired.BufferSizes != nil {
+		if current.buffer == nil {
+			return nil, nil, fmt.Errorf("the driver doesn't support the configuration of the receive buffers")
+		}
+		buffer := append([]byte{}, current.buffer...)
+		for prio, b := range desired.PrioToBuffer {
+			buffer[prio] = uint8(b)
+		}
+		for b, size := range desired.BufferSizes {
+			binary.NativeEndian.PutUint32(buffer[ieeeMaxPrio+4*b:], uint32(size))
+		}
+		if string(buffer) != string(current.buffer) {
+			set.buffer = buffer
+		}
+	}
+
+	present := map[dcbApp]bool{}
+	for _, app := range current.apps {
+		if app.selector == ieeeAppSelDscp {
+			present[app] = true
+		}
+	}
+	switch desired.Trust {
+	case qosTrustDscp:
+		for _, app := range dscpApps() {
+			if !present[app] {
+				set.apps = append(set.apps, app)
+			}
+		}
+	case qosTrustPcp:
+		for app := range present {
+			del = append(del, app)
+		}
+		sort.Slice(del, func(i, j int) bool { return del[i].protocol < del[j].protocol })
+	}
+	return set, del, nil
+}
+
+// ieeeRequest returns a DCB request for the command with the IEEE configuration, the nil parts are not sent
+func ieeeRequest(name string, cmd uint8, ieee *dcbIeee) *nl.NetlinkRequest {
+	msgType := unix.RTM_SETDCB
+	if cmd == dcbCmdIeeeGet {
+		msgType = unix.RTM_GETDCB
+	}
+	req := nl.NewNetlinkRequest(msgType, 0)
+	req.AddData(&dcbMsg{cmd: cmd})
+	req.AddData(nl.NewRtAttr(dcbAttrIfname, nl.ZeroTerminated(name)))
+	if ieee == nil {
+		return req
+	}
+	nest := nl.NewRtAttr(dcbAttrIeee, nil)
+	if ieee.pfc != nil {
+		nest.AddRtAttr(dcbAttrIeeePfc, ieee.pfc)
+	}
+	if ieee.buffer != nil {
+		nest.AddRtAttr(dcbAttrDcbBuffer, ieee.buffer)
+	}
+	if len(ieee.apps) > 0 {
+		table := nest.AddRtAttr(dcbAttrIeeeAppTable, nil)
+		for _, app := range ieee.apps {
+			table.AddRtAttr(dcbAttrIeeeApp, app.serialize())
+		}
+	}
+	req.AddData(nest)
+	return req
+}
+
+// getDcbIeee returns the IEEE DCB configuration of the interface
+func getDcbIeee(name string) (*dcbIeee, error) {
+	msgs, err := ieeeRequest(name, dcbCmdIeeeGet, nil).Execute(unix.NETLINK_ROUTE, unix.RTM_GETDCB)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || len(msgs[0]) < 4 {
+		return nil, fmt.Errorf("empty DCB reply")
+	}
+	attrs, err := nl.ParseRouteAttr(msgs[0][4:])
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		if attr.Attr.Type&nl.NLA_TYPE_MASK == dcbAttrIeee {
+			return parseDcbIeee(attr.Value)
+		}
+	}
+	return nil, fmt.Errorf("no IEEE DCB configuration in the reply")
+}
+
+// execDcbIeee sends a DCB_CMD_IEEE_SET or DCB_CMD_IEEE_DEL request, the driver status is returned in the
+// DCB_ATTR_IEEE attribute of the reply
+func execDcbIeee(name string, cmd uint8, ieee *dcbIeee) error {
+	msgs, err := ieeeRequest(name, cmd, ieee).Execute(unix.NETLINK_ROUTE, unix.RTM_SETDCB)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if len(msg) < 4 {
+			continue
+		}
+		attrs, err := nl.ParseRouteAttr(msg[4:])
+		if err != nil {
+			return err
+		}
+		for _, attr := range attrs {
+			if attr.Attr.Type&nl.NLA_TYPE_MASK == dcbAttrIeee && len(attr.Value) > 0 && attr.Value[0] != 0 {
+				return fmt.Errorf("the driver rejected the DCB configuration: %v", unix.Errno(attr.Value[0]))
+			}
+		}
+	}
+	return nil
+}
+
+// GetNetDevQos returns the trust mode, the priority flow control and the receive buffers of the interface, or
+// nil if its driver doesn't support DCB
+func (n *network) GetNetDevQos(name string) *sriovnetworkv1.QosConfig {
+	ieee, err := getDcbIeee(name)
+	if err != nil {
+		log.Log.V(2).Info("GetNetDevQos(): failed to get the DCB configuration", "name", name, "error", err)
+		return nil
+	}
+	return ieee.qos()
+}
+
+// SetNetDevQos applies the trust mode, the priority flow control and the receive buffers of the QoS settings to
+// the interface through the DCB netlink interface
+func (n *network) SetNetDevQos(name string, qos *sriovnetworkv1.QosConfig) error {
+	log.Log.V(2).Info("SetNetDevQos(): set QoS", "name", name, "qos", qos)
+	if err := guard.CheckNetDev(name); err != nil {
+		return err
+	}
+	current, err := getDcbIeee(name)
+	if err != nil {
+		return fmt.Errorf("failed to get the DCB configuration of %s: %v", name, err)
+	}
+	set, del, err := qosChanges(current, qos)
+	if err != nil {
+		return fmt.Errorf("failed to configure the QoS of %s: %v", name, err)
+	}
+	if set.pfc != nil || set.buffer != nil || len(set.apps) > 0 {
+		if err := execDcbIeee(name, dcbCmdIeeeSet, set); err != nil {
+			return fmt.Errorf("failed to set the DCB configuration of %s: %v", name, err)
+		}
+	}
+	if len(del) > 0 {
+		if err := execDcbIeee(name, dcbCmdIeeeDel, &dcbIeee{apps: del}); err != nil {
+			return fmt.Errorf("failed to remove the DSCP mapping of %s: %v", name, err)
+		}
+	}
+	return nil
+}
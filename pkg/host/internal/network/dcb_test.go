@@ -0,0 +1,109 @@
+package network
+
+import (
+	"encoding/binary"
+
+	"github.com/vishvananda/netlink/nl"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// ieeeAttrs returns the serialized DCB_ATTR_IEEE attributes of the configuration
+func ieeeAttrs(ieee *dcbIeee) []byte {
+	nest := nl.NewRtAttr(dcbAttrIeee, nil)
+	if ieee.pfc != nil {
+		nest.AddRtAttr(dcbAttrIeeePfc, ieee.pfc)
+	}
+	if ieee.buffer != nil {
+		nest.AddRtAttr(dcbAttrDcbBuffer, ieee.buffer)
+	}
+	table := nest.AddRtAttr(dcbAttrIeeeAppTable, nil)
+	for _, app := range ieee.apps {
+		table.AddRtAttr(dcbAttrIeeeApp, app.serialize())
+	}
+	// skip the header of the nest
+	return nest.Serialize()[4:]
+}
+
+var _ = Describe("DCB", func() {
+	var current *dcbIeee
+	BeforeEach(func() {
+		current = &dcbIeee{
+			pfc:    make([]byte, sizeofIeeePfc),
+			buffer: make([]byte, sizeofDcbBuffer),
+			apps:   []dcbApp{{selector: 1, priority: 3, protocol: 0x8915}},
+		}
+		current.pfc[1] = 1 << 3
+		binary.NativeEndian.PutUint32(current.buffer[ieeeMaxPrio:], 262016)
+	})
+
+	It("parses the IEEE configuration", func() {
+		ieee, err := parseDcbIeee(ieeeAttrs(current))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ieee).To(Equal(current))
+		Expect(ieee.qos()).To(Equal(&sriovnetworkv1.QosConfig{
+			Trust:         "pcp",
+			PfcPriorities: []int{3},
+			PrioToBuffer:  []int{0, 0, 0, 0, 0, 0, 0, 0},
+			BufferSizes:   []int{262016, 0, 0, 0, 0, 0, 0, 0},
+		}))
+
+		current.apps = append(current.apps, dscpApps()...)
+		ieee, err = parseDcbIeee(ieeeAttrs(current))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ieee.qos().Trust).To(Equal("dscp"))
+	})
+
+	It("reports no PFC and buffers when the driver doesn't support them", func() {
+		ieee, err := parseDcbIeee(ieeeAttrs(&dcbIeee{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ieee.qos()).To(Equal(&sriovnetworkv1.QosConfig{Trust: "pcp"}))
+	})
+
+	It("fails on a truncated PFC attribute", func() {
+		_, err := parseDcbIeee(ieeeAttrs(&dcbIeee{pfc: make([]byte, 8)}))
+		Expect(err).To(MatchError(ContainSubstring("truncated")))
+	})
+
+	It("computes the changes to apply", func() {
+		set, del, err := qosChanges(current, &sriovnetworkv1.QosConfig{
+			Trust:         "dscp",
+			PfcPriorities: []int{3, 4},
+			PrioToBuffer:  []int{0, 0, 0, 1, 1},
+			BufferSizes:   []int{131072, 131072},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(del).To(BeEmpty())
+		Expect(set.pfc[1]).To(Equal(uint8(1<<3 | 1<<4)))
+		Expect(set.buffer[:ieeeMaxPrio]).To(Equal([]byte{0, 0, 0, 1, 1, 0, 0, 0}))
+		Expect(binary.NativeEndian.Uint32(set.buffer[ieeeMaxPrio+4:])).To(Equal(uint32(131072)))
+		Expect(set.apps).To(HaveLen(dscpCount))
+		Expect(set.apps[46]).To(Equal(dcbApp{selector: ieeeAppSelDscp, priority: 5, protocol: 46}))
+		// the current configuration is not modified
+		Expect(current.pfc[1]).To(Equal(uint8(1 << 3)))
+	})
+
+	It("leaves the configuration already applied untouched", func() {
+		current.apps = append(current.apps, dscpApps()...)
+		set, del, err := qosChanges(current, &sriovnetworkv1.QosConfig{Trust: "dscp", PfcPriorities: []int{3}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(del).To(BeEmpty())
+		Expect(set).To(Equal(&dcbIeee{}))
+	})
+
+	It("removes the DSCP mapping to trust the PCP", func() {
+		current.apps = append(current.apps, dscpApps()[:2]...)
+		set, del, err := qosChanges(current, &sriovnetworkv1.QosConfig{Trust: "pcp"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(set).To(Equal(&dcbIeee{}))
+		Expect(del).To(Equal(dscpApps()[:2]))
+	})
+
+	It("fails when the driver doesn't support PFC", func() {
+		_, _, err := qosChanges(&dcbIeee{}, &sriovnetworkv1.QosConfig{PfcPriorities: []int{3}})
+		Expect(err).To(MatchError(ContainSubstring("priority flow control")))
+	})
+})
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ethtoolModInfo mirrors struct ethtool_modinfo from linux/ethtool.h
+type ethtoolModInfo struct {
+	cmd       uint32
+	modType   uint32
+	eepromLen uint32
+	reserved  [8]uint32
+}
+
+// ethtoolIfreq mirrors struct ifreq with the ifr_data member of the union set
+type ethtoolIfreq struct {
+	name [unix.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [24 - unsafe.Sizeof(unsafe.Pointer(nil))]byte
+}
+
+// ethtoolIoctl issues a SIOCETHTOOL ioctl for the given interface, data must point
+// to a struct that starts with the ethtool command
+func ethtoolIoctl(ifaceName string, data unsafe.Pointer) error {
+	return ifreqIoctl(ifaceName, unix.SIOCETHTOOL, data)
+}
+
+// ifreqIoctl issues an ioctl taking a struct ifreq with the ifr_data member pointing to data
+func ifreqIoctl(ifaceName string, request uintptr, data unsafe.Pointer) error {
+	if len(ifaceName) >= unix.IFNAMSIZ {
+		return fmt.Errorf("interface name %s is too long", ifaceName)
+	}
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	req := ethtoolIfreq{data: data}
+	copy(req.name[:], ifaceName)
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(&req)))
+	runtime.KeepAlive(&req)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// getModuleInfo returns the type and EEPROM length of the module plugged into the interface
+func getModuleInfo(ifaceName string) (*ethtoolModInfo, error) {
+	info := &ethtoolModInfo{cmd: unix.ETHTOOL_GMODULEINFO}
+	if err := ethtoolIoctl(ifaceName, unsafe.Pointer(info)); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// getModuleEeprom reads length bytes of the module EEPROM starting at offset
+func getModuleEeprom(ifaceName string, offset, length uint32) ([]byte, error) {
+	// buffer mirrors struct ethtool_eeprom followed by the data
+	buf := make([]byte, 16+length)
+	binary.NativeEndian.PutUint32(buf[0:], unix.ETHTOOL_GMODULEEEPROM)
+	binary.NativeEndian.PutUint32(buf[8:], offset)
+	binary.NativeEndian.PutUint32(buf[12:], length)
+	if err := ethtoolIoctl(ifaceName, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+	return buf[16:], nil
+}
+
+// ethtoolDrvInfo mirrors struct ethtool_drvinfo from linux/ethtool.h
+type ethtoolDrvInfo struct {
+	cmd         uint32
+	driver      [32]byte
+	version     [32]byte
+	fwVersion   [32]byte
+	busInfo     [32]byte
+	eromVersion [32]byte
+	reserved2   [12]byte
+	nPrivFlags  uint32
+	nStats      uint32
+	testinfoLen uint32
+	eedumpLen   uint32
+	regdumpLen  uint32
+}
+
+// getDriverInfo returns the driver and firmware information of the interface
+func getDriverInfo(ifaceName string) (*ethtoolDrvInfo, error) {
+	info := &ethtoolDrvInfo{cmd: unix.ETHTOOL_GDRVINFO}
+	if err := ethtoolIoctl(ifaceName, unsafe.Pointer(info)); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
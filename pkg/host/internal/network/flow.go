@@ -0,0 +1,288 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// tcFilter is an entry of the output of "tc -j filter show"
+type tcFilter struct {
+	Pref    int `json:"pref"`
+	Options *struct {
+		Actions []struct {
+			Cookie string `json:"cookie"`
+		} `json:"actions"`
+	} `json:"options"`
+}
+
+// tcQdisc is an entry of the output of "tc -j qdisc show"
+type tcQdisc struct {
+	Kind string `json:"kind"`
+}
+
+// flowRuleFilter is a tc filter rendered from a flow rule
+type flowRuleFilter struct {
+	pref   int
+	args   []string
+	cookie string
+}
+
+// representorName returns the name the switchdev udev rule gives to the representor of the VF
+func representorName(pfName string, vfID int) string {
+	return fmt.Sprintf("%s_%d", pfName, vfID)
+}
+
+// SyncFlowRules installs the flow rules on the ingress of the PF and of the representors of its VFs. The rules
+// are installed as tc flower filters with a preference in the range reserved to the operator, and the action
+// cookie holds the hash of the filter, so the filters already installed are left untouched and the ones no
+// longer requested are removed.
+func (n *network) SyncFlowRules(pfName string, rules []sriovnetworkv1.FlowRule) error {
+	log.Log.V(2).Info("SyncFlowRules(): sync flow rules", "name", pfName, "rules", len(rules))
+	if len(rules) > consts.MaxFlowRules {
+		return fmt.Errorf("too many flow rules for %s, %d requested, at most %d are supported", pfName, len(rules), consts.MaxFlowRules)
+	}
+
+	// the preferences follow the order of the names, so they are stable when the policies are reordered
+	sorted := make([]sriovnetworkv1.FlowRule, len(rules))
+	copy(sorted, rules)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	desired := map[string][]flowRuleFilter{}
+	for i, rule := range sorted {
+		dev := pfName
+		if rule.Vf != nil {
+			dev = representorName(pfName, *rule.Vf)
+		}
+		args, err := renderFlowRule(pfName, rule)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256([]byte(strings.Join(args, " ")))
+		desired[dev] = append(desired[dev], flowRuleFilter{
+			pref:   consts.FlowRulePrefBase + i,
+			args:   args,
+			cookie: hex.EncodeToString(sum[:16]),
+		})
+	}
+
+	devices, err := n.getPfAndRepresentors(pfName)
+	if err != nil {
+		return err
+	}
+	for dev := range desired {
+		if !sriovnetworkv1.StringInArray(dev, devices) {
+			return fmt.Errorf("failed to install the flow rules on %s: interface not found", dev)
+		}
+	}
+	for _, dev := range devices {
+		if err := n.syncDeviceFlowRules(dev, desired[dev]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDeviceFlowRules reconciles the filters in the operator range of the device ingress with the desired ones
+func (n *network) syncDeviceFlowRules(dev string, desired []flowRuleFilter) error {
+	hasIngress, err := n.hasIngressQdisc(dev)
+	if err != nil {
+		return err
+	}
+	if !hasIngress {
+		if len(desired) == 0 {
+			return nil
+		}
+		if err := n.runTc("qdisc", "add", "dev", dev, "ingress"); err != nil {
+			return fmt.Errorf("failed to add the ingress qdisc to %s: %w", dev, err)
+		}
+	}
+
+	installed, err := n.getFilterCookies(dev, "ingress", consts.FlowRulePrefBase, consts.FlowRulePrefBase+consts.MaxFlowRules)
+	if err != nil {
+		return err
+	}
+	for _, filter := range desired {
+		cookie, exist := installed[filter.pref]
+		delete(installed, filter.pref)
+		if exist && cookie == filter.cookie {
+			continue
+		}
+		if exist {
+			if err := n.runTc("filter", "del", "dev", dev, "ingress", "pref", strconv.Itoa(filter.pref)); err != nil {
+				return fmt.Errorf("failed to remove the flow rule with pref %d from %s: %w", filter.pref, dev, err)
+			}
+		}
+		log.Log.V(2).Info("syncDeviceFlowRules(): install flow rule", "device", dev, "pref", filter.pref, "rule", strings.Join(filter.args, " "))
+		args := append([]string{"filter", "add", "dev", dev, "ingress", "pref", strconv.Itoa(filter.pref), "handle", "1"},
+			filter.args...)
+		if err := n.runTc(append(args, "cookie", filter.cookie)...); err != nil {
+			return fmt.Errorf("failed to install the flow rule with pref %d on %s: %w", filter.pref, dev, err)
+		}
+	}
+	for pref := range installed {
+		log.Log.V(2).Info("syncDeviceFlowRules(): remove stale flow rule", "device", dev, "pref", pref)
+		if err := n.runTc("filter", "del", "dev", dev, "ingress", "pref", strconv.Itoa(pref)); err != nil {
+			return fmt.Errorf("failed to remove the flow rule with pref %d from %s: %w", pref, dev, err)
+		}
+	}
+	return nil
+}
+
+// getPfAndRepresentors returns the PF and the representors of its VFs renamed by the switchdev udev rule
+func (n *network) getPfAndRepresentors(pfName string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName+"_*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the representors of %s: %v", pfName, err)
+	}
+	representor := regexp.MustCompile(fmt.Sprintf(`^%s_[0-9]+$`, regexp.QuoteMeta(pfName)))
+	devices := []string{pfName}
+	for _, path := range paths {
+		if name := filepath.Base(path); representor.MatchString(name) {
+			devices = append(devices, name)
+		}
+	}
+	return devices, nil
+}
+
+// hasIngressQdisc returns true if the device has an ingress or a clsact qdisc, the ovs offload adds the former
+func (n *network) hasIngressQdisc(dev string) (bool, error) {
+	kinds, err := n.getQdiscKinds(dev)
+	if err != nil {
+		return false, err
+	}
+	return kinds["ingress"] || kinds["clsact"], nil
+}
+
+// getQdiscKinds returns the kinds of the qdiscs of the device
+func (n *network) getQdiscKinds(dev string) (map[string]bool, error) {
+	stdout, err := n.runTcOutput("-j", "qdisc", "show", "dev", dev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the qdiscs of %s: %v", dev, err)
+	}
+	qdiscs := []tcQdisc{}
+	if strings.TrimSpace(stdout) != "" {
+		if err := json.Unmarshal([]byte(stdout), &qdiscs); err != nil {
+			return nil, fmt.Errorf("failed to parse the qdiscs of %s: %v", dev, err)
+		}
+	}
+	kinds := map[string]bool{}
+	for _, qdisc := range qdiscs {
+		kinds[qdisc.Kind] = true
+	}
+	return kinds, nil
+}
+
+// getFilterCookies returns the action cookies of the filters of the hook of the device with a preference in
+// [minPref, maxPref), indexed by preference
+func (n *network) getFilterCookies(dev, hook string, minPref, maxPref int) (map[int]string, error) {
+	stdout, err := n.runTcOutput("-j", "filter", "show", "dev", dev, hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the filters of %s: %v", dev, err)
+	}
+	filters := []tcFilter{}
+	if strings.TrimSpace(stdout) != "" {
+		if err := json.Unmarshal([]byte(stdout), &filters); err != nil {
+			return nil, fmt.Errorf("failed to parse the filters of %s: %v", dev, err)
+		}
+	}
+	cookies := map[int]string{}
+	for _, filter := range filters {
+		if filter.Pref < minPref || filter.Pref >= maxPref {
+			continue
+		}
+		cookie := cookies[filter.Pref]
+		if filter.Options != nil && len(filter.Options.Actions) > 0 {
+			cookie = filter.Options.Actions[0].Cookie
+		}
+		cookies[filter.Pref] = cookie
+	}
+	return cookies, nil
+}
+
+// runTc runs a tc command changing the device of its dev argument, once the PCI address guard of the node allows it
+func (n *network) runTc(args ...string) error {
+	if i := slices.Index(args, "dev"); i >= 0 && i+1 < len(args) {
+		if err := guard.CheckNetDev(args[i+1]); err != nil {
+			return err
+		}
+	}
+	_, err := n.runTcOutput(args...)
+	return err
+}
+
+// runTcOutput runs tc without a shell, the arguments carry values of the custom resources
+func (n *network) runTcOutput(args ...string) (string, error) {
+	program, argv := utils.GetChrootCommand("tc", args...)
+	stdout, stderr, err := n.utilsHelper.RunCommand(program, argv...)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
+
+// renderFlowRule returns the tc filter arguments of the rule, from the protocol to the action
+func renderFlowRule(pfName string, rule sriovnetworkv1.FlowRule) ([]string, error) {
+	m := rule.Match
+	args := []string{}
+	protocol := "all"
+	if m.EthType != "" {
+		protocol = m.EthType
+	}
+	if m.VlanID > 0 {
+		protocol = "802.1Q"
+	}
+	args = append(args, "protocol", protocol, "flower")
+	if m.VlanID > 0 {
+		args = append(args, "vlan_id", fmt.Sprint(m.VlanID))
+		if m.EthType != "" {
+			args = append(args, "vlan_ethtype", m.EthType)
+		}
+	}
+	if m.DstMac != "" {
+		args = append(args, "dst_mac", m.DstMac)
+	}
+	if m.SrcMac != "" {
+		args = append(args, "src_mac", m.SrcMac)
+	}
+	if m.IPProto != "" {
+		args = append(args, "ip_proto", m.IPProto)
+	}
+	if m.SrcIP != "" {
+		args = append(args, "src_ip", m.SrcIP)
+	}
+	if m.DstIP != "" {
+		args = append(args, "dst_ip", m.DstIP)
+	}
+	if m.DstPort > 0 {
+		args = append(args, "dst_port", fmt.Sprint(m.DstPort))
+	}
+
+	switch rule.Action {
+	case sriovnetworkv1.FlowActionTrap, sriovnetworkv1.FlowActionDrop:
+		args = append(args, "action", rule.Action)
+	case sriovnetworkv1.FlowActionMirror, sriovnetworkv1.FlowActionRedirect:
+		if rule.TargetVf == nil {
+			return nil, fmt.Errorf("flow rule %s: the %s action requires a targetVf", rule.Name, rule.Action)
+		}
+		args = append(args, "action", "mirred", "egress", rule.Action, "dev", representorName(pfName, *rule.TargetVf))
+	default:
+		return nil, fmt.Errorf("flow rule %s: unsupported action %q", rule.Name, rule.Action)
+	}
+	return args, nil
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("Flow rules", func() {
+	var (
+		n         types.NetworkInterface
+		testCtrl  *gomock.Controller
+		utilsMock *utilsMockPkg.MockCmdInterface
+		// tcOutput holds the output of the tc commands, the commands run are recorded in tcCommands
+		tcOutput   map[string]string
+		tcCommands []string
+		tcArgs     [][]string
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		utilsMock = utilsMockPkg.NewMockCmdInterface(testCtrl)
+		n = New(utilsMock, nil)
+		tcOutput = map[string]string{}
+		tcCommands = nil
+		tcArgs = nil
+		utilsMock.EXPECT().RunCommand("chroot", gomock.Any()).DoAndReturn(
+			func(_ string, args ...string) (string, string, error) {
+				cmd := strings.Join(args[1:], " ")
+				tcCommands = append(tcCommands, cmd)
+				tcArgs = append(tcArgs, args[2:])
+				return tcOutput[cmd], "", nil
+			}).AnyTimes()
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/enp216s0f0np0_0",
+				"/sys/class/net/enp216s0f0np0_1", "/sys/class/net/enp216s0f0np1"},
+		})
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	It("installs the rules on the PF and the representors", func() {
+		vf := 0
+		target := 1
+		Expect(n.SyncFlowRules("enp216s0f0np0", []sriovnetworkv1.FlowRule{
+			{Name: "trap-lacp", Match: sriovnetworkv1.FlowMatch{EthType: "0x8809", DstMac: "01:80:c2:00:00:02"}, Action: "trap"},
+			{Name: "mirror-vf0", Vf: &vf, Match: sriovnetworkv1.FlowMatch{VlanID: 100, EthType: "ip", IPProto: "udp", DstPort: 4789},
+				Action: "mirror", TargetVf: &target},
+		})).To(Succeed())
+		Expect(tcCommands).To(ContainElements(
+			"tc qdisc add dev enp216s0f0np0 ingress",
+			"tc filter add dev enp216s0f0np0 ingress pref 40001 handle 1 protocol 0x8809 flower dst_mac 01:80:c2:00:00:02 "+
+				"action trap cookie 0a03f10534b0193f1f799e45c7ac0cb2",
+			"tc qdisc add dev enp216s0f0np0_0 ingress",
+			"tc filter add dev enp216s0f0np0_0 ingress pref 40000 handle 1 protocol 802.1Q flower vlan_id 100 vlan_ethtype ip "+
+				"ip_proto udp dst_port 4789 action mirred egress mirror dev enp216s0f0np0_1 cookie ffa024992dbbce104469df3dcc0b3863",
+		))
+		// the representors without rules and the other PFs are left untouched
+		Expect(tcCommands).To(ContainElement("tc -j qdisc show dev enp216s0f0np0_1"))
+		Expect(tcCommands).ToNot(ContainElement(ContainSubstring("dev enp216s0f0np0_1 ingress")))
+		Expect(tcCommands).ToNot(ContainElement(ContainSubstring("enp216s0f0np1")))
+	})
+
+	It("passes each value as a single argument of tc", func() {
+		Expect(n.SyncFlowRules("enp216s0f0np0", []sriovnetworkv1.FlowRule{
+			{Name: "trap", Match: sriovnetworkv1.FlowMatch{DstMac: "01:80:c2:00:00:02; reboot"}, Action: "trap"},
+		})).To(Succeed())
+		Expect(tcArgs).To(ContainElement(ContainElement("01:80:c2:00:00:02; reboot")))
+	})
+
+	It("keeps the installed rules and removes the stale ones", func() {
+		tcOutput["tc -j qdisc show dev enp216s0f0np0"] = `[{"kind":"ingress","handle":"ffff:"}]`
+		tcOutput["tc -j filter show dev enp216s0f0np0 ingress"] = `[` +
+			`{"protocol":"ip","pref":2,"kind":"flower","chain":0},` +
+			`{"protocol":"0x8809","pref":40000,"kind":"flower","chain":0},` +
+			`{"protocol":"0x8809","pref":40000,"kind":"flower","chain":0,"options":{"handle":1,"actions":[{"kind":"gact","cookie":"f4c8e606fce3f5e14670bb1cf64a6358"}]}},` +
+			`{"protocol":"all","pref":40001,"kind":"flower","chain":0,"options":{"handle":1,"actions":[{"kind":"gact","cookie":"0123"}]}}]`
+		Expect(n.SyncFlowRules("enp216s0f0np0", []sriovnetworkv1.FlowRule{
+			{Name: "trap-lacp", Match: sriovnetworkv1.FlowMatch{EthType: "0x8809"}, Action: "trap"},
+		})).To(Succeed())
+		Expect(tcCommands).To(Equal([]string{
+			"tc -j qdisc show dev enp216s0f0np0",
+			"tc -j filter show dev enp216s0f0np0 ingress",
+			"tc filter del dev enp216s0f0np0 ingress pref 40001",
+			"tc -j qdisc show dev enp216s0f0np0_0",
+			"tc -j qdisc show dev enp216s0f0np0_1",
+		}))
+	})
+
+	It("doesn't change the rules of a PF outside of the PCI address guard of the node", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/host" + consts.SriovConfBasePath, "/sys/class/net/enp216s0f0np0"},
+			Files: map[string][]byte{
+				"/host" + consts.PciGuardFile: []byte("deny:\n- 0000:d8:00.*\n"),
+			},
+			Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/device": "../../../0000:d8:00.0"},
+		})
+		tcOutput["tc -j qdisc show dev enp216s0f0np0"] = `[{"kind":"ingress","handle":"ffff:"}]`
+		tcOutput["tc -j filter show dev enp216s0f0np0 ingress"] = `[` +
+			`{"protocol":"all","pref":40001,"kind":"flower","chain":0,"options":{"handle":1,"actions":[{"kind":"gact","cookie":"0123"}]}}]`
+		Expect(n.SyncFlowRules("enp216s0f0np0", nil)).To(MatchError(snerrors.ErrPciAddressGuarded))
+		Expect(tcCommands).ToNot(ContainElement(HavePrefix("tc filter del")))
+	})
+
+	It("fails when the representor doesn't exist", func() {
+		vf := 5
+		Expect(n.SyncFlowRules("enp216s0f0np0", []sriovnetworkv1.FlowRule{
+			{Name: "drop", Vf: &vf, Action: "drop"},
+		})).To(MatchError(ContainSubstring("enp216s0f0np0_5")))
+	})
+
+	It("fails without the target of a mirror rule", func() {
+		Expect(n.SyncFlowRules("enp216s0f0np0", []sriovnetworkv1.FlowRule{
+			{Name: "mirror", Action: "mirror"},
+		})).To(MatchError(ContainSubstring("requires a targetVf")))
+	})
+})
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// devlinkHealthReporter is a reporter of the output of devlink -j health show, the older iproute2 report its name
+// in the name field
+type devlinkHealthReporter struct {
+	Reporter string `json:"reporter"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Error    int    `json:"error"`
+}
+
+// isFirmwareReporter returns true for the reporters of the firmware health, e.g. fw and fw_fatal
+func isFirmwareReporter(name string) bool {
+	return name == "fw" || strings.HasPrefix(name, "fw_")
+}
+
+// GetFirmwareFaults returns the firmware health reporters of the devlink device of the PCI device in error state,
+// with their error count
+func (n *network) GetFirmwareFaults(pciAddr string) ([]string, error) {
+	log.Log.V(2).Info("GetFirmwareFaults(): get the firmware health", "device", pciAddr)
+	out, err := n.runDevlinkOutput("-j health show pci/" + pciAddr)
+	if err != nil {
+		return nil, err
+	}
+	show := struct {
+		Health map[string][]devlinkHealthReporter `json:"health"`
+	}{}
+	if err := json.Unmarshal([]byte(out), &show); err != nil {
+		return nil, fmt.Errorf("failed to parse the devlink health reporters: %v", err)
+	}
+	faults := []string{}
+	for handle, reporters := range show.Health {
+		if handle != "pci/"+pciAddr && !strings.HasPrefix(handle, "pci/"+pciAddr+"/") {
+			continue
+		}
+		for _, reporter := range reporters {
+			name := reporter.Reporter
+			if name == "" {
+				name = reporter.Name
+			}
+			if isFirmwareReporter(name) && reporter.State == "error" {
+				faults = append(faults, fmt.Sprintf("%s (%d errors)", name, reporter.Error))
+			}
+		}
+	}
+	sort.Strings(faults)
+	return faults, nil
+}
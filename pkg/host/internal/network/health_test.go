@@ -0,0 +1,45 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+)
+
+var _ = Describe("Firmware health", func() {
+	var (
+		n         types.NetworkInterface
+		utilsMock *utilsMockPkg.MockCmdInterface
+	)
+	BeforeEach(func() {
+		utilsMock = utilsMockPkg.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+		n = New(utilsMock, nil)
+	})
+
+	It("reports the firmware reporters in error state", func() {
+		utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return(`{"health":{
+			"pci/0000:3b:00.0":[
+				{"reporter":"fw","state":"healthy","error":0,"recover":0},
+				{"reporter":"fw_fatal","state":"error","error":3,"recover":2},
+				{"reporter":"tx","state":"error","error":1,"recover":0}],
+			"pci/0000:3b:00.0/65535":[{"reporter":"fw","state":"error","error":1,"recover":0}]}}`, "", nil)
+		Expect(n.GetFirmwareFaults("0000:3b:00.0")).To(Equal([]string{"fw (1 errors)", "fw_fatal (3 errors)"}))
+	})
+
+	It("reads the reporters of the older iproute2", func() {
+		utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return(
+			`{"health":{"pci/0000:3b:00.0":[{"name":"fw","state":"healthy","error":0}]}}`, "", nil)
+		Expect(n.GetFirmwareFaults("0000:3b:00.0")).To(BeEmpty())
+	})
+
+	It("returns the errors of devlink", func() {
+		utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "devlink answers: Operation not supported", fmt.Errorf("exit status 1"))
+		_, err := n.GetFirmwareFaults("0000:3b:00.0")
+		Expect(err).To(MatchError(ContainSubstring("Operation not supported")))
+	})
+})
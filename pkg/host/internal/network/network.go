@@ -1,6 +1,7 @@
 package network
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	dputilsPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
@@ -149,6 +151,9 @@ func (n *network) GetNetdevMTU(pciAddr string) int {
 
 func (n *network) SetNetdevMTU(pciAddr string, mtu int) error {
 	log.Log.V(2).Info("SetNetdevMTU(): set MTU", "device", pciAddr, "mtu", mtu)
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
 	if mtu <= 0 {
 		log.Log.V(2).Info("SetNetdevMTU(): refusing to set MTU", "mtu", mtu)
 		return nil
@@ -164,7 +169,7 @@ func (n *network) SetNetdevMTU(pciAddr string, mtu int) error {
 			return fmt.Errorf("SetNetdevMTU(): interface name is empty")
 		}
 		mtuFilePath := mtuFilePath(ifaceName[0], pciAddr)
-		return os.WriteFile(mtuFilePath, []byte(strconv.Itoa(mtu)), os.ModeAppend)
+		return utils.WriteHostFile(mtuFilePath, []byte(strconv.Itoa(mtu)), os.ModeAppend)
 	}, backoff.WithMaxRetries(b, 10))
 	if err != nil {
 		log.Log.Error(err, "SetNetdevMTU(): fail to write mtu file after retrying")
@@ -196,3 +201,53 @@ func (n *network) GetNetDevLinkSpeed(ifaceName string) string {
 
 	return fmt.Sprintf("%s Mb/s", strings.TrimSpace(string(data)))
 }
+
+func (n *network) GetNetDevLinkState(ifaceName string) string {
+	log.Log.V(2).Info("GetNetDevLinkState(): get LinkState", "device", ifaceName)
+	carrierFilePath := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, ifaceName, "carrier")
+	data, err := os.ReadFile(carrierFilePath)
+	if err != nil {
+		// reading the carrier file fails with EINVAL when the interface is administratively down
+		log.Log.V(2).Info("GetNetDevLinkState(): fail to read carrier file", "path", carrierFilePath, "error", err)
+		return consts.LinkStateDown
+	}
+
+	if strings.TrimSpace(string(data)) == "1" {
+		return consts.LinkStateUp
+	}
+	return consts.LinkStateDown
+}
+
+func (n *network) IsTransceiverPresent(ifaceName string) bool {
+	log.Log.V(2).Info("IsTransceiverPresent(): check transceiver module", "device", ifaceName)
+	info, err := getModuleInfo(ifaceName)
+	if err != nil {
+		// drivers return an error when the cage is empty or module EEPROM access is not supported
+		log.Log.V(2).Info("IsTransceiverPresent(): fail to get module info", "device", ifaceName, "error", err)
+		return false
+	}
+
+	return info.eepromLen > 0
+}
+
+func (n *network) GetNetDevFirmwareVersion(ifaceName string) string {
+	log.Log.V(2).Info("GetNetDevFirmwareVersion(): get firmware version", "device", ifaceName)
+	info, err := getDriverInfo(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetNetDevFirmwareVersion(): fail to get driver info", "device", ifaceName)
+		return ""
+	}
+
+	return strings.TrimSpace(string(bytes.TrimRight(info.fwVersion[:], "\x00")))
+}
+
+func (n *network) GetNetDevDriverVersion(ifaceName string) string {
+	log.Log.V(2).Info("GetNetDevDriverVersion(): get driver version", "device", ifaceName)
+	info, err := getDriverInfo(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetNetDevDriverVersion(): fail to get driver info", "device", ifaceName)
+		return ""
+	}
+
+	return strings.TrimSpace(string(bytes.TrimRight(info.version[:], "\x00")))
+}
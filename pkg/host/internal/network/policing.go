@@ -0,0 +1,110 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// SyncPolicing installs the policing of the VFs of the PF on their representors, as matchall filters with a police
+// action offloaded to the eswitch. The traffic sent by a VF is policed on the ingress of its representor and the
+// traffic received on its egress, which requires a clsact qdisc. The policing of the other representors is removed.
+func (n *network) SyncPolicing(pfName string, policing map[int]*sriovnetworkv1.PolicingConfig) error {
+	log.Log.V(2).Info("SyncPolicing(): sync policing", "name", pfName, "vfs", len(policing))
+	devices, err := n.getPfAndRepresentors(pfName)
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]map[string][]string{}
+	for vfID, cfg := range policing {
+		dev := representorName(pfName, vfID)
+		if !sriovnetworkv1.StringInArray(dev, devices) {
+			return fmt.Errorf("failed to police VF %d of %s: representor %s not found", vfID, pfName, dev)
+		}
+		hooks := map[string][]string{}
+		if cfg.Egress != nil {
+			hooks["ingress"] = renderPolicing(cfg.Egress)
+		}
+		if cfg.Ingress != nil {
+			hooks["egress"] = renderPolicing(cfg.Ingress)
+		}
+		desired[dev] = hooks
+	}
+	// the first device is the PF, the policing only applies to the representors
+	for _, dev := range devices[1:] {
+		if err := n.syncDevicePolicing(dev, desired[dev]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDevicePolicing reconciles the policing filters of the hooks of the representor with the desired ones
+func (n *network) syncDevicePolicing(dev string, desired map[string][]string) error {
+	kinds, err := n.getQdiscKinds(dev)
+	if err != nil {
+		return err
+	}
+	clsact := kinds["clsact"]
+	ingress := clsact || kinds["ingress"]
+	for _, hook := range []string{"ingress", "egress"} {
+		args, wanted := desired[hook]
+		available := ingress
+		if hook == "egress" {
+			available = clsact
+		}
+		if !available {
+			if !wanted {
+				continue
+			}
+			if ingress {
+				return fmt.Errorf("failed to police the traffic received through %s: the ingress qdisc of the representor "+
+					"has no egress hook, a clsact qdisc is required", dev)
+			}
+			if err := n.runTc("qdisc", "add", "dev", dev, "clsact"); err != nil {
+				return fmt.Errorf("failed to add the clsact qdisc to %s: %w", dev, err)
+			}
+			clsact, ingress = true, true
+		}
+
+		installed, err := n.getFilterCookies(dev, hook, consts.PolicingPref, consts.PolicingPref+1)
+		if err != nil {
+			return err
+		}
+		cookie, exist := installed[consts.PolicingPref]
+		sum := sha256.Sum256([]byte(strings.Join(args, " ")))
+		if wanted && exist && cookie == hex.EncodeToString(sum[:16]) {
+			continue
+		}
+		if exist {
+			if err := n.runTc("filter", "del", "dev", dev, hook, "pref", strconv.Itoa(consts.PolicingPref)); err != nil {
+				return fmt.Errorf("failed to remove the policing of the %s of %s: %w", hook, dev, err)
+			}
+		}
+		if !wanted {
+			continue
+		}
+		log.Log.V(2).Info("syncDevicePolicing(): install policing", "device", dev, "hook", hook, "policing", strings.Join(args, " "))
+		args = append([]string{"filter", "add", "dev", dev, hook, "pref", strconv.Itoa(consts.PolicingPref), "handle", "1"},
+			args...)
+		if err := n.runTc(append(args, "cookie", hex.EncodeToString(sum[:16]))...); err != nil {
+			return fmt.Errorf("failed to install the policing of the %s of %s: %w", hook, dev, err)
+		}
+	}
+	return nil
+}
+
+// renderPolicing returns the tc filter arguments of the policing, skip_sw makes the hardware enforce it or the
+// installation fail
+func renderPolicing(rate *sriovnetworkv1.PolicingRate) []string {
+	return []string{"protocol", "all", "matchall", "skip_sw", "action", "police", "rate", fmt.Sprintf("%dmbit", rate.Rate),
+		"burst", fmt.Sprintf("%dkb", rate.Burst), "conform-exceed", "drop/pipe"}
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("Policing", func() {
+	var (
+		n         types.NetworkInterface
+		testCtrl  *gomock.Controller
+		utilsMock *utilsMockPkg.MockCmdInterface
+		// tcOutput holds the output of the tc commands, the commands run are recorded in tcCommands
+		tcOutput   map[string]string
+		tcCommands []string
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		utilsMock = utilsMockPkg.NewMockCmdInterface(testCtrl)
+		n = New(utilsMock, nil)
+		tcOutput = map[string]string{}
+		tcCommands = nil
+		utilsMock.EXPECT().RunCommand("chroot", gomock.Any()).DoAndReturn(
+			func(_ string, args ...string) (string, string, error) {
+				cmd := strings.Join(args[1:], " ")
+				tcCommands = append(tcCommands, cmd)
+				return tcOutput[cmd], "", nil
+			}).AnyTimes()
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/enp216s0f0np0_0",
+				"/sys/class/net/enp216s0f0np0_1"},
+		})
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	It("polices both directions of the VFs on their representors", func() {
+		Expect(n.SyncPolicing("enp216s0f0np0", map[int]*sriovnetworkv1.PolicingConfig{
+			0: {
+				Egress:  &sriovnetworkv1.PolicingRate{Rate: 1000, Burst: 64},
+				Ingress: &sriovnetworkv1.PolicingRate{Rate: 500, Burst: 32},
+			},
+		})).To(Succeed())
+		Expect(tcCommands).To(Equal([]string{
+			"tc -j qdisc show dev enp216s0f0np0_0",
+			"tc qdisc add dev enp216s0f0np0_0 clsact",
+			"tc -j filter show dev enp216s0f0np0_0 ingress",
+			"tc filter add dev enp216s0f0np0_0 ingress pref 41000 handle 1 protocol all matchall skip_sw action police " +
+				"rate 1000mbit burst 64kb conform-exceed drop/pipe cookie 6f7c5ec0add34a7f3f384ac6d4cb4a72",
+			"tc -j filter show dev enp216s0f0np0_0 egress",
+			"tc filter add dev enp216s0f0np0_0 egress pref 41000 handle 1 protocol all matchall skip_sw action police " +
+				"rate 500mbit burst 32kb conform-exceed drop/pipe cookie de61bad07e84a0e5784599d4d37d15b6",
+			// the representors without policing are left untouched
+			"tc -j qdisc show dev enp216s0f0np0_1",
+		}))
+	})
+
+	It("keeps the installed policing and removes the stale one", func() {
+		tcOutput["tc -j qdisc show dev enp216s0f0np0_0"] = `[{"kind":"clsact","handle":"ffff:"}]`
+		tcOutput["tc -j filter show dev enp216s0f0np0_0 ingress"] = `[` +
+			`{"protocol":"all","pref":41000,"kind":"matchall","chain":0,"options":{"handle":1,"actions":[{"kind":"police","cookie":"6f7c5ec0add34a7f3f384ac6d4cb4a72"}]}}]`
+		tcOutput["tc -j filter show dev enp216s0f0np0_0 egress"] = `[` +
+			`{"protocol":"all","pref":41000,"kind":"matchall","chain":0,"options":{"handle":1,"actions":[{"kind":"police","cookie":"0123"}]}}]`
+		Expect(n.SyncPolicing("enp216s0f0np0", map[int]*sriovnetworkv1.PolicingConfig{
+			0: {Egress: &sriovnetworkv1.PolicingRate{Rate: 1000, Burst: 64}},
+		})).To(Succeed())
+		Expect(tcCommands).To(Equal([]string{
+			"tc -j qdisc show dev enp216s0f0np0_0",
+			"tc -j filter show dev enp216s0f0np0_0 ingress",
+			"tc -j filter show dev enp216s0f0np0_0 egress",
+			"tc filter del dev enp216s0f0np0_0 egress pref 41000",
+			"tc -j qdisc show dev enp216s0f0np0_1",
+		}))
+	})
+
+	It("fails to police the traffic received through a representor with an ingress qdisc", func() {
+		tcOutput["tc -j qdisc show dev enp216s0f0np0_1"] = `[{"kind":"ingress","handle":"ffff:"}]`
+		Expect(n.SyncPolicing("enp216s0f0np0", map[int]*sriovnetworkv1.PolicingConfig{
+			1: {Ingress: &sriovnetworkv1.PolicingRate{Rate: 500, Burst: 32}},
+		})).To(MatchError(ContainSubstring("a clsact qdisc is required")))
+	})
+
+	It("fails when the representor doesn't exist", func() {
+		Expect(n.SyncPolicing("enp216s0f0np0", map[int]*sriovnetworkv1.PolicingConfig{
+			5: {Egress: &sriovnetworkv1.PolicingRate{Rate: 1000, Burst: 64}},
+		})).To(MatchError(ContainSubstring("representor enp216s0f0np0_5 not found")))
+	})
+})
@@ -0,0 +1,188 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// ptpServices are the linuxptp programs bound to network interfaces
+var ptpServices = []string{"ptp4l", "phc2sys", "ts2phc"}
+
+// ptpConfigSections are the sections of the linuxptp configuration files that are not network interfaces
+var ptpConfigSections = []string{"global", "unicast_master_table", "nmea"}
+
+// ptpProcess is a running linuxptp program
+type ptpProcess struct {
+	service string
+	args    []string
+	// root is the root directory of the process, its configuration files are read from it
+	root string
+}
+
+// flagValues returns the values of the short option in the arguments, either "-i ens1f0" or "-iens1f0"
+func flagValues(args []string, flag string) []string {
+	values := []string{}
+	for i, arg := range args {
+		switch {
+		case arg == flag && i+1 < len(args):
+			values = append(values, args[i+1])
+		case strings.HasPrefix(arg, flag) && len(arg) > len(flag):
+			values = append(values, arg[len(flag):])
+		}
+	}
+	return values
+}
+
+// hasFlag returns true if the short option is in the arguments, alone or grouped with others, e.g. "-ar"
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// configInterfaces returns the interfaces of the sections of the linuxptp configuration file
+func configInterfaces(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Log.V(2).Info("configInterfaces(): failed to read linuxptp configuration", "path", path, "error", err)
+		return nil
+	}
+	interfaces := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		section := strings.TrimSpace(line[1 : len(line)-1])
+		if !sriovnetworkv1.StringInArray(section, ptpConfigSections) {
+			interfaces = append(interfaces, section)
+		}
+	}
+	return interfaces
+}
+
+// interfaces returns the network interfaces and the clock devices, e.g. /dev/ptp0, the process is bound to
+func (p *ptpProcess) interfaces() []string {
+	interfaces := []string{}
+	switch p.service {
+	case "ptp4l":
+		interfaces = append(interfaces, flagValues(p.args, "-i")...)
+	case "phc2sys":
+		interfaces = append(interfaces, flagValues(p.args, "-s")...)
+		interfaces = append(interfaces, flagValues(p.args, "-i")...)
+		for _, sink := range flagValues(p.args, "-c") {
+			if sink != "CLOCK_REALTIME" {
+				interfaces = append(interfaces, sink)
+			}
+		}
+	case "ts2phc":
+		interfaces = append(interfaces, flagValues(p.args, "-c")...)
+	}
+	for _, config := range flagValues(p.args, "-f") {
+		interfaces = append(interfaces, configInterfaces(filepath.Join(p.root, config))...)
+	}
+	return interfaces
+}
+
+// listPtpProcesses returns the linuxptp programs running on the host, the daemon shares the PID namespace of
+// the host
+func listPtpProcesses() []ptpProcess {
+	procDir := filepath.Join(vars.FilesystemRoot, consts.Proc)
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		log.Log.Error(err, "listPtpProcesses(): failed to list the processes")
+		return nil
+	}
+	processes := []ptpProcess{}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		service := filepath.Base(args[0])
+		if !sriovnetworkv1.StringInArray(service, ptpServices) {
+			continue
+		}
+		processes = append(processes, ptpProcess{
+			service: service,
+			args:    args[1:],
+			root:    filepath.Join(procDir, entry.Name(), "root"),
+		})
+	}
+	return processes
+}
+
+// getPhc returns the PTP hardware clock of the interface, e.g. "ptp0", or an empty string if it has none
+func getPhc(name string) string {
+	entries, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "device", "ptp"))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "ptp") {
+			return entry.Name()
+		}
+	}
+	return ""
+}
+
+// GetPtpInfo returns the PTP hardware clock of the interface and the linuxptp services using it, or nil if the
+// interface has no PTP hardware clock. The interface is the time source of the node when phc2sys synchronizes
+// the system clock from it, or from the ptp4l instance bound to it in automatic mode.
+func (n *network) GetPtpInfo(name string) *sriovnetworkv1.PtpInfo {
+	clock := getPhc(name)
+	if clock == "" {
+		return nil
+	}
+	info := &sriovnetworkv1.PtpInfo{Clock: clock}
+	bound := func(refs []string) bool {
+		for _, ref := range refs {
+			if ref == name || ref == filepath.Join("/dev", clock) {
+				return true
+			}
+		}
+		return false
+	}
+
+	processes := listPtpProcesses()
+	ptp4lBound := false
+	for i := range processes {
+		p := &processes[i]
+		if !bound(p.interfaces()) {
+			continue
+		}
+		if p.service == "ptp4l" {
+			ptp4lBound = true
+		}
+		if !sriovnetworkv1.StringInArray(p.service, info.Services) {
+			info.Services = append(info.Services, p.service)
+		}
+	}
+	for _, p := range processes {
+		if p.service != "phc2sys" {
+			continue
+		}
+		if bound(flagValues(p.args, "-s")) || (hasFlag(p.args, "-a") && ptp4lBound) {
+			info.TimeSource = true
+		}
+	}
+	return info
+}
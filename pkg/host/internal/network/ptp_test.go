@@ -0,0 +1,77 @@
+package network
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+// cmdline returns the content of the cmdline file of a process
+func cmdline(args ...string) []byte {
+	return []byte(strings.Join(args, "\x00") + "\x00")
+}
+
+var _ = Describe("PTP", func() {
+	var (
+		n  types.NetworkInterface
+		fs *fakefilesystem.FS
+	)
+	BeforeEach(func() {
+		n = New(nil, nil)
+		fs = &fakefilesystem.FS{
+			Dirs: []string{
+				"/sys/class/net/ens1f0/device/ptp/ptp0",
+				"/sys/class/net/ens1f1/device/ptp/ptp1",
+				"/sys/class/net/ens2f0/device",
+				"/proc/1", "/proc/100", "/proc/200", "/proc/300/root/var/run",
+			},
+			Files: map[string][]byte{
+				"/proc/1/cmdline":   cmdline("/usr/lib/systemd/systemd", "--switched-root"),
+				"/proc/100/cmdline": cmdline("/usr/sbin/ptp4l", "-2", "-s", "-i", "ens1f0", "-m"),
+			},
+		}
+	})
+
+	It("reports the clock and the services of the PF", func() {
+		helpers.GinkgoConfigureFakeFS(fs)
+		Expect(n.GetPtpInfo("ens1f0")).To(Equal(&sriovnetworkv1.PtpInfo{Clock: "ptp0", Services: []string{"ptp4l"}}))
+		Expect(n.GetPtpInfo("ens1f1")).To(Equal(&sriovnetworkv1.PtpInfo{Clock: "ptp1"}))
+	})
+
+	It("reports no clock for the PF without PHC", func() {
+		helpers.GinkgoConfigureFakeFS(fs)
+		Expect(n.GetPtpInfo("ens2f0")).To(BeNil())
+	})
+
+	It("detects the time source synchronized by phc2sys", func() {
+		fs.Files["/proc/200/cmdline"] = cmdline("phc2sys", "-s", "/dev/ptp0", "-c", "CLOCK_REALTIME", "-O", "-37")
+		helpers.GinkgoConfigureFakeFS(fs)
+		Expect(n.GetPtpInfo("ens1f0")).To(Equal(&sriovnetworkv1.PtpInfo{
+			Clock: "ptp0", Services: []string{"ptp4l", "phc2sys"}, TimeSource: true,
+		}))
+		Expect(n.GetPtpInfo("ens1f1").TimeSource).To(BeFalse())
+	})
+
+	It("detects the time source of phc2sys in automatic mode", func() {
+		fs.Files["/proc/200/cmdline"] = cmdline("/usr/sbin/phc2sys", "-ar", "-u", "60")
+		helpers.GinkgoConfigureFakeFS(fs)
+		Expect(n.GetPtpInfo("ens1f0").TimeSource).To(BeTrue())
+		Expect(n.GetPtpInfo("ens1f1").TimeSource).To(BeFalse())
+	})
+
+	It("reads the interfaces of the configuration files", func() {
+		fs.Files["/proc/100/cmdline"] = cmdline("/usr/sbin/ptp4l", "-f", "/var/run/ptp4l.0.config")
+		fs.Files["/proc/300/cmdline"] = cmdline("/usr/sbin/ts2phc", "-f", "/var/run/ptp4l.0.config")
+		fs.Files["/proc/300/root/var/run/ptp4l.0.config"] = []byte("[global]\nslaveOnly 1\n[nmea]\n[ens1f1]\nmasterOnly 0\n")
+		fs.Symlinks = map[string]string{"/proc/100/root": "../300/root"}
+		helpers.GinkgoConfigureFakeFS(fs)
+		Expect(n.GetPtpInfo("ens1f1")).To(Equal(&sriovnetworkv1.PtpInfo{Clock: "ptp1", Services: []string{"ptp4l", "ts2phc"}}))
+		Expect(n.GetPtpInfo("ens1f0").Services).To(BeEmpty())
+	})
+})
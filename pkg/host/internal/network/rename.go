@@ -0,0 +1,58 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// iffUp is the IFF_UP flag of the netdevs, set when they are administratively up
+const iffUp = 0x1
+
+// RenameNetDev renames the netdev, the kernel only renames the links that are down: the link is set down for the
+// rename and up again when it was up
+func (n *network) RenameNetDev(name, newName string) error {
+	log.Log.V(2).Info("RenameNetDev(): rename netdev", "name", name, "newName", newName)
+	if err := guard.CheckNetDev(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, newName)); err == nil {
+		return fmt.Errorf("failed to rename %s to %s: the name is used by another netdev", name, newName)
+	}
+	commands := []string{
+		fmt.Sprintf("ip link set dev %s down", name),
+		fmt.Sprintf("ip link set dev %s name %s", name, newName),
+	}
+	if n.isAdminUp(name) {
+		commands = append(commands, fmt.Sprintf("ip link set dev %s up", newName))
+	}
+	for _, command := range commands {
+		_, stderr, err := n.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s %s", utils.GetChrootExtension(), command))
+		if err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %v: %s", name, newName, err, strings.TrimSpace(stderr))
+		}
+	}
+	return nil
+}
+
+// isAdminUp returns whether the netdev is administratively up
+func (n *network) isAdminUp(name string) bool {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "flags"))
+	if err != nil {
+		return false
+	}
+	flags, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"), 16, 32)
+	if err != nil {
+		return false
+	}
+	return flags&iffUp != 0
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("RenameNetDev", func() {
+	var (
+		n         types.NetworkInterface
+		testCtrl  *gomock.Controller
+		utilsMock *utilsMockPkg.MockCmdInterface
+		commands  []string
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		utilsMock = utilsMockPkg.NewMockCmdInterface(testCtrl)
+		n = New(utilsMock, nil)
+		commands = nil
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	recordCommands := func(err error) {
+		utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).DoAndReturn(
+			func(_ string, args ...string) (string, string, error) {
+				commands = append(commands, args[1][strings.Index(args[1], "ip "):])
+				return "", "", err
+			}).AnyTimes()
+	}
+
+	It("sets the link up again when it was up", func() {
+		recordCommands(nil)
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:  []string{"/sys/class/net/ens1f0"},
+			Files: map[string][]byte{"/sys/class/net/ens1f0/flags": []byte("0x1003\n")},
+		})
+		Expect(n.RenameNetDev("ens1f0", "sriov0")).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"ip link set dev ens1f0 down",
+			"ip link set dev ens1f0 name sriov0",
+			"ip link set dev sriov0 up",
+		}))
+	})
+
+	It("leaves the link down when it was down", func() {
+		recordCommands(nil)
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:  []string{"/sys/class/net/ens1f0"},
+			Files: map[string][]byte{"/sys/class/net/ens1f0/flags": []byte("0x1002\n")},
+		})
+		Expect(n.RenameNetDev("ens1f0", "sriov0")).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"ip link set dev ens1f0 down",
+			"ip link set dev ens1f0 name sriov0",
+		}))
+	})
+
+	It("fails when the name is used by another netdev", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/ens1f0", "/sys/class/net/sriov0"},
+		})
+		Expect(n.RenameNetDev("ens1f0", "sriov0")).To(MatchError(ContainSubstring("used by another netdev")))
+	})
+
+	It("reports the failures of ip", func() {
+		recordCommands(fmt.Errorf("exit status 2"))
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+		Expect(n.RenameNetDev("ens1f0", "sriov0")).To(MatchError(ContainSubstring("failed to rename ens1f0 to sriov0")))
+		Expect(commands).To(HaveLen(1))
+	})
+})
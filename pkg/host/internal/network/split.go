@@ -0,0 +1,139 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// devlinkPort is a port of the output of devlink -j port show
+type devlinkPort struct {
+	Netdev     string `json:"netdev"`
+	Flavour    string `json:"flavour"`
+	SplitGroup *int   `json:"split_group"`
+	Splittable bool   `json:"splittable"`
+	Lanes      int    `json:"lanes"`
+}
+
+// physicalPorts returns the physical ports of the devlink device of the PCI device by their handle, e.g.
+// pci/0000:3b:00.0/1, in the order of their handles
+func (n *network) physicalPorts(pciAddr string) ([]string, map[string]devlinkPort, error) {
+	out, err := n.runDevlinkOutput("-j port show")
+	if err != nil {
+		return nil, nil, err
+	}
+	show := struct {
+		Port map[string]devlinkPort `json:"port"`
+	}{}
+	if err := json.Unmarshal([]byte(out), &show); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the devlink ports: %v", err)
+	}
+	handles := []string{}
+	ports := map[string]devlinkPort{}
+	for handle, port := range show.Port {
+		if !strings.HasPrefix(handle, "pci/"+pciAddr+"/") || port.Flavour != "physical" {
+			continue
+		}
+		handles = append(handles, handle)
+		ports[handle] = port
+	}
+	sort.Strings(handles)
+	return handles, ports, nil
+}
+
+// portSplit returns the number of ports of the split group of the first split port, and the handle of that port,
+// 0 when the ports are not split
+func portSplit(handles []string, ports map[string]devlinkPort) (int, string) {
+	count, first := 0, ""
+	for _, handle := range handles {
+		group := ports[handle].SplitGroup
+		if group == nil {
+			continue
+		}
+		if first == "" {
+			first = handle
+		}
+		if *group == *ports[first].SplitGroup {
+			count++
+		}
+	}
+	return count, first
+}
+
+// GetSplitPorts returns the netdevs of the ports the physical port of the PCI device is split into, in the order of
+// their devlink ports, empty when it isn't split
+func (n *network) GetSplitPorts(pciAddr string) ([]string, error) {
+	handles, ports, err := n.physicalPorts(pciAddr)
+	if err != nil {
+		return nil, err
+	}
+	count, first := portSplit(handles, ports)
+	netdevs := make([]string, 0, count)
+	for _, handle := range handles {
+		if group := ports[handle].SplitGroup; group != nil && count > 0 && *group == *ports[first].SplitGroup {
+			netdevs = append(netdevs, ports[handle].Netdev)
+		}
+	}
+	return netdevs, nil
+}
+
+// SetPortSplit splits the physical port of the PCI device into count ports, a split port is unsplit first. A count
+// of 1 unsplits the port. The VFs of the PF must be removed before.
+func (n *network) SetPortSplit(pciAddr string, count int) error {
+	log.Log.V(2).Info("SetPortSplit(): split the port", "device", pciAddr, "count", count)
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
+	handles, ports, err := n.physicalPorts(pciAddr)
+	if err != nil {
+		return err
+	}
+	current, splitHandle := portSplit(handles, ports)
+	if current == count || (current == 0 && count <= 1) {
+		return nil
+	}
+	if current > 0 {
+		if err := n.runDevlink("port unsplit " + splitHandle); err != nil {
+			return fmt.Errorf("failed to unsplit the port %s: %v", splitHandle, err)
+		}
+		if count <= 1 {
+			return nil
+		}
+		if handles, ports, err = n.physicalPorts(pciAddr); err != nil {
+			return err
+		}
+	}
+	for _, handle := range handles {
+		port := ports[handle]
+		if !port.Splittable {
+			continue
+		}
+		if port.Lanes > 0 && count > port.Lanes {
+			return fmt.Errorf("the port %s of %d lanes can't be split into %d ports", handle, port.Lanes, count)
+		}
+		if err := n.runDevlink(fmt.Sprintf("port split %s count %d", handle, count)); err != nil {
+			return fmt.Errorf("failed to split the port %s into %d ports: %v", handle, count, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("the port of %s can't be split", pciAddr)
+}
+
+func (n *network) runDevlink(args string) error {
+	_, err := n.runDevlinkOutput(args)
+	return err
+}
+
+func (n *network) runDevlinkOutput(args string) (string, error) {
+	stdout, stderr, err := n.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s devlink %s", utils.GetChrootExtension(), args))
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+)
+
+const (
+	unsplitPorts = `{"port":{
+		"pci/0000:3b:00.0/0":{"type":"eth","netdev":"ens1f0np0","flavour":"physical","port":0,"splittable":true,"lanes":4},
+		"pci/0000:3b:00.0/1":{"type":"eth","netdev":"eth0","flavour":"pcivf","pfnum":0,"vfnum":0},
+		"pci/0000:3b:00.1/0":{"type":"eth","netdev":"ens1f1np1","flavour":"physical","port":1,"splittable":true,"lanes":4}}}`
+	splitPorts = `{"port":{
+		"pci/0000:3b:00.0/0":{"type":"eth","netdev":"ens1f0np0s0","flavour":"physical","port":0,"split_group":0,"splittable":false,"lanes":1},
+		"pci/0000:3b:00.0/2":{"type":"eth","netdev":"ens1f0np0s1","flavour":"physical","port":0,"split_group":0,"splittable":false,"lanes":1},
+		"pci/0000:3b:00.1/0":{"type":"eth","netdev":"ens1f1np1","flavour":"physical","port":1,"splittable":true,"lanes":4}}}`
+)
+
+var _ = Describe("Port split", func() {
+	var (
+		n         types.NetworkInterface
+		testCtrl  *gomock.Controller
+		utilsMock *utilsMockPkg.MockCmdInterface
+		// ports is the output of devlink port show, the commands run are recorded in devlinkCommands
+		ports           string
+		devlinkCommands []string
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		utilsMock = utilsMockPkg.NewMockCmdInterface(testCtrl)
+		n = New(utilsMock, nil)
+		devlinkCommands = nil
+		utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).DoAndReturn(
+			func(_ string, args ...string) (string, string, error) {
+				cmd := args[1][strings.Index(args[1], "devlink "):]
+				devlinkCommands = append(devlinkCommands, cmd)
+				if cmd == "devlink -j port show" {
+					return ports, "", nil
+				}
+				// the split ports are reported once unsplit
+				if strings.HasPrefix(cmd, "devlink port unsplit") {
+					ports = unsplitPorts
+				}
+				return "", "", nil
+			}).AnyTimes()
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	It("reports the split of the port", func() {
+		ports = unsplitPorts
+		Expect(n.GetSplitPorts("0000:3b:00.0")).To(BeEmpty())
+		ports = splitPorts
+		Expect(n.GetSplitPorts("0000:3b:00.0")).To(Equal([]string{"ens1f0np0s0", "ens1f0np0s1"}))
+		Expect(n.GetSplitPorts("0000:3b:00.1")).To(BeEmpty())
+		Expect(n.GetSplitPorts("0000:5e:00.0")).To(BeEmpty())
+	})
+
+	It("splits the splittable port of the PF", func() {
+		ports = unsplitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 4)).To(Succeed())
+		Expect(devlinkCommands).To(Equal([]string{"devlink -j port show", "devlink port split pci/0000:3b:00.0/0 count 4"}))
+	})
+
+	It("unsplits the split port before splitting it again", func() {
+		ports = splitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 4)).To(Succeed())
+		Expect(devlinkCommands).To(Equal([]string{"devlink -j port show", "devlink port unsplit pci/0000:3b:00.0/0",
+			"devlink -j port show", "devlink port split pci/0000:3b:00.0/0 count 4"}))
+	})
+
+	It("unsplits the port", func() {
+		ports = splitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 1)).To(Succeed())
+		Expect(devlinkCommands).To(Equal([]string{"devlink -j port show", "devlink port unsplit pci/0000:3b:00.0/0"}))
+	})
+
+	It("leaves the port split as requested", func() {
+		ports = splitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 2)).To(Succeed())
+		ports = unsplitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 1)).To(Succeed())
+		Expect(devlinkCommands).To(Equal([]string{"devlink -j port show", "devlink -j port show"}))
+	})
+
+	It("doesn't split the port into more ports than its lanes", func() {
+		ports = unsplitPorts
+		Expect(n.SetPortSplit("0000:3b:00.0", 8)).To(MatchError(ContainSubstring("of 4 lanes can't be split into 8 ports")))
+	})
+})
@@ -0,0 +1,87 @@
+package network
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+)
+
+// hardware timestamping modes, see linux/net_tstamp.h
+const (
+	hwtstampTxOff      = 0
+	hwtstampTxOn       = 1
+	hwtstampFilterNone = 0
+	hwtstampFilterAll  = 1
+
+	// sofTimestampingHardware are the SO_TIMESTAMPING capabilities needed to timestamp the packets in hardware
+	sofTimestampingHardware = unix.SOF_TIMESTAMPING_TX_HARDWARE | unix.SOF_TIMESTAMPING_RX_HARDWARE |
+		unix.SOF_TIMESTAMPING_RAW_HARDWARE
+)
+
+// ethtoolTsInfo mirrors struct ethtool_ts_info from linux/ethtool.h
+type ethtoolTsInfo struct {
+	cmd            uint32
+	soTimestamping uint32
+	phcIndex       int32
+	txTypes        uint32
+	txReserved     [3]uint32
+	rxFilters      uint32
+	rxReserved     [3]uint32
+}
+
+// hwtstampConfig mirrors struct hwtstamp_config from linux/net_tstamp.h
+type hwtstampConfig struct {
+	flags    int32
+	txType   int32
+	rxFilter int32
+}
+
+// hwTimestampingState returns consts.HwTimestampingEnabled if the device timestamps the packets in hardware,
+// consts.HwTimestampingSupported if it can, or an empty string if it can't
+func hwTimestampingState(info *ethtoolTsInfo, config *hwtstampConfig) string {
+	if info.soTimestamping&sofTimestampingHardware != sofTimestampingHardware || info.phcIndex < 0 ||
+		info.txTypes&(1<<hwtstampTxOn) == 0 {
+		return ""
+	}
+	if config != nil && config.txType != hwtstampTxOff && config.rxFilter != hwtstampFilterNone {
+		return consts.HwTimestampingEnabled
+	}
+	return consts.HwTimestampingSupported
+}
+
+// GetNetDevHwTimestamping returns the hardware timestamping state of the interface, see hwTimestampingState
+func (n *network) GetNetDevHwTimestamping(ifaceName string) string {
+	info := &ethtoolTsInfo{cmd: unix.ETHTOOL_GET_TS_INFO}
+	if err := ethtoolIoctl(ifaceName, unsafe.Pointer(info)); err != nil {
+		log.Log.V(2).Info("GetNetDevHwTimestamping(): failed to get the timestamping capabilities",
+			"device", ifaceName, "error", err)
+		return ""
+	}
+	config := &hwtstampConfig{}
+	if err := ifreqIoctl(ifaceName, unix.SIOCGHWTSTAMP, unsafe.Pointer(config)); err != nil {
+		log.Log.V(2).Info("GetNetDevHwTimestamping(): failed to get the timestamping configuration",
+			"device", ifaceName, "error", err)
+		config = nil
+	}
+	return hwTimestampingState(info, config)
+}
+
+// EnableNetDevHwTimestamping enables the hardware timestamping of the packets sent and received by the interface,
+// so the workloads without the NET_ADMIN capability can read the hardware timestamps. The driver can narrow the
+// received packets timestamped, e.g. to the PTP ones.
+func (n *network) EnableNetDevHwTimestamping(ifaceName string) error {
+	log.Log.V(2).Info("EnableNetDevHwTimestamping(): enable hardware timestamping", "device", ifaceName)
+	if err := guard.CheckNetDev(ifaceName); err != nil {
+		return err
+	}
+	config := &hwtstampConfig{txType: hwtstampTxOn, rxFilter: hwtstampFilterAll}
+	if err := ifreqIoctl(ifaceName, unix.SIOCSHWTSTAMP, unsafe.Pointer(config)); err != nil {
+		return fmt.Errorf("failed to enable the hardware timestamping of %s: %v", ifaceName, err)
+	}
+	return nil
+}
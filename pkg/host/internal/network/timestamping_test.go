@@ -0,0 +1,40 @@
+package network
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+var _ = Describe("Timestamping", func() {
+	var info *ethtoolTsInfo
+	BeforeEach(func() {
+		info = &ethtoolTsInfo{
+			soTimestamping: sofTimestampingHardware,
+			phcIndex:       1,
+			txTypes:        1<<hwtstampTxOff | 1<<hwtstampTxOn,
+		}
+	})
+
+	It("reports the hardware timestamping supported", func() {
+		Expect(hwTimestampingState(info, &hwtstampConfig{})).To(Equal(consts.HwTimestampingSupported))
+		Expect(hwTimestampingState(info, nil)).To(Equal(consts.HwTimestampingSupported))
+	})
+
+	It("reports the hardware timestamping enabled", func() {
+		Expect(hwTimestampingState(info, &hwtstampConfig{txType: hwtstampTxOn, rxFilter: 12})).
+			To(Equal(consts.HwTimestampingEnabled))
+	})
+
+	It("reports no hardware timestamping for the software timestamping only", func() {
+		info.soTimestamping = 0x2 | 0x8 | 0x10
+		info.phcIndex = -1
+		Expect(hwTimestampingState(info, &hwtstampConfig{})).To(BeEmpty())
+	})
+
+	It("reports no hardware timestamping without a PTP hardware clock", func() {
+		info.phcIndex = -1
+		Expect(hwTimestampingState(info, &hwtstampConfig{})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,131 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+// module types reported by ETHTOOL_GMODULEINFO, see linux/ethtool.h
+const (
+	ethModuleSff8079 = 0x1
+	ethModuleSff8472 = 0x2
+	ethModuleSff8636 = 0x3
+	ethModuleSff8436 = 0x4
+)
+
+// SFF-8472 (SFP/SFP+/SFP28) layout, the diagnostic page A2h is mapped right after the 256 bytes of A0h
+const (
+	sff8472VendorNameOffset = 20
+	sff8472VendorPNOffset   = 40
+	sff8472VendorSNOffset   = 68
+	sff8472DiagTypeOffset   = 92
+	sff8472DiagImplemented  = 0x40
+	sff8472DiagInternalCal  = 0x20
+	sff8472A2Offset         = 256
+	sff8472TempOffset       = sff8472A2Offset + 96
+	sff8472TxPowerOffset    = sff8472A2Offset + 102
+	sff8472RxPowerOffset    = sff8472A2Offset + 104
+)
+
+// SFF-8636/SFF-8436 (QSFP+/QSFP28) layout, lower page followed by upper page 00h
+const (
+	sff8636TempOffset       = 22
+	sff8636RxPowerOffset    = 34
+	sff8636TxPowerOffset    = 50
+	sff8636VendorNameOffset = 148
+	sff8636VendorPNOffset   = 168
+	sff8636VendorSNOffset   = 196
+	sff8636Lanes            = 4
+)
+
+// GetTransceiver reads the module EEPROM of the interface once and decodes the identification of the module and its
+// digital diagnostic monitoring values, the diagnostics are nil when the module doesn't report them
+func (n *network) GetTransceiver(ifaceName string) (*sriovnetworkv1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	info, err := getModuleInfo(ifaceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get module info for %s: %v", ifaceName, err)
+	}
+	if info.eepromLen == 0 {
+		return nil, nil, fmt.Errorf("no module EEPROM available for %s", ifaceName)
+	}
+
+	eeprom, err := getModuleEeprom(ifaceName, 0, info.eepromLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read module EEPROM for %s: %v", ifaceName, err)
+	}
+
+	return parseModuleEeprom(info.modType, eeprom)
+}
+
+// parseModuleEeprom decodes the module EEPROM based on the module type
+func parseModuleEeprom(modType uint32, eeprom []byte) (*sriovnetworkv1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	switch modType {
+	case ethModuleSff8079, ethModuleSff8472:
+		return parseSff8472(eeprom)
+	case ethModuleSff8636, ethModuleSff8436:
+		return parseSff8636(eeprom)
+	default:
+		return nil, nil, fmt.Errorf("unsupported module type 0x%x", modType)
+	}
+}
+
+func parseSff8472(eeprom []byte) (*sriovnetworkv1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	if len(eeprom) < sff8472A2Offset {
+		return nil, nil, fmt.Errorf("module EEPROM is too short: %d bytes", len(eeprom))
+	}
+	info := &sriovnetworkv1.TransceiverInfo{
+		Vendor:       eepromString(eeprom, sff8472VendorNameOffset, 16),
+		PartNumber:   eepromString(eeprom, sff8472VendorPNOffset, 16),
+		SerialNumber: eepromString(eeprom, sff8472VendorSNOffset, 16),
+	}
+
+	// diagnostics are only reported for internally calibrated modules exposing page A2h
+	diagType := eeprom[sff8472DiagTypeOffset]
+	if diagType&sff8472DiagImplemented == 0 || diagType&sff8472DiagInternalCal == 0 ||
+		len(eeprom) < sff8472RxPowerOffset+2 {
+		return info, nil, nil
+	}
+	return info, &types.TransceiverDiagnostics{
+		Temperature: eepromTemperature(eeprom, sff8472TempOffset),
+		TxPower:     []float64{eepromPower(eeprom, sff8472TxPowerOffset)},
+		RxPower:     []float64{eepromPower(eeprom, sff8472RxPowerOffset)},
+	}, nil
+}
+
+func parseSff8636(eeprom []byte) (*sriovnetworkv1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	if len(eeprom) < sff8636VendorSNOffset+16 {
+		return nil, nil, fmt.Errorf("module EEPROM is too short: %d bytes", len(eeprom))
+	}
+	info := &sriovnetworkv1.TransceiverInfo{
+		Vendor:       eepromString(eeprom, sff8636VendorNameOffset, 16),
+		PartNumber:   eepromString(eeprom, sff8636VendorPNOffset, 16),
+		SerialNumber: eepromString(eeprom, sff8636VendorSNOffset, 16),
+	}
+	diagnostics := &types.TransceiverDiagnostics{Temperature: eepromTemperature(eeprom, sff8636TempOffset)}
+	for lane := 0; lane < sff8636Lanes; lane++ {
+		diagnostics.RxPower = append(diagnostics.RxPower, eepromPower(eeprom, sff8636RxPowerOffset+2*lane))
+		diagnostics.TxPower = append(diagnostics.TxPower, eepromPower(eeprom, sff8636TxPowerOffset+2*lane))
+	}
+	return info, diagnostics, nil
+}
+
+// eepromString returns the space padded ASCII field of the EEPROM
+func eepromString(eeprom []byte, offset, length int) string {
+	return strings.TrimSpace(strings.Trim(string(eeprom[offset:offset+length]), "\x00"))
+}
+
+// eepromTemperature decodes a signed 16 bit value in 1/256 degrees Celsius
+func eepromTemperature(eeprom []byte, offset int) float64 {
+	raw := int16(binary.BigEndian.Uint16(eeprom[offset:]))
+	return float64(raw) / 256
+}
+
+// eepromPower decodes an unsigned 16 bit value in 0.1 uW to mW
+func eepromPower(eeprom []byte, offset int) float64 {
+	raw := binary.BigEndian.Uint16(eeprom[offset:])
+	return float64(raw) / 10000
+}
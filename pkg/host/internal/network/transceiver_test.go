@@ -0,0 +1,84 @@
+package network
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+func putEepromString(eeprom []byte, offset int, value string) {
+	copy(eeprom[offset:offset+16], []byte(value+"                "))
+}
+
+var _ = Describe("Transceiver", func() {
+	Context("parseModuleEeprom", func() {
+		It("SFP with internal calibration", func() {
+			eeprom := make([]byte, 512)
+			putEepromString(eeprom, sff8472VendorNameOffset, "FINISAR CORP.")
+			putEepromString(eeprom, sff8472VendorPNOffset, "FTLX8574D3BCL")
+			putEepromString(eeprom, sff8472VendorSNOffset, "ALJ1234")
+			eeprom[sff8472DiagTypeOffset] = sff8472DiagImplemented | sff8472DiagInternalCal
+			binary.BigEndian.PutUint16(eeprom[sff8472TempOffset:], 0x2380)
+			binary.BigEndian.PutUint16(eeprom[sff8472TxPowerOffset:], 5000)
+			binary.BigEndian.PutUint16(eeprom[sff8472RxPowerOffset:], 4321)
+
+			info, diagnostics, err := parseModuleEeprom(ethModuleSff8472, eeprom)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(Equal(&sriovnetworkv1.TransceiverInfo{
+				Vendor:       "FINISAR CORP.",
+				PartNumber:   "FTLX8574D3BCL",
+				SerialNumber: "ALJ1234",
+			}))
+			Expect(diagnostics).To(Equal(&types.TransceiverDiagnostics{
+				Temperature: 35.5,
+				TxPower:     []float64{0.5},
+				RxPower:     []float64{0.4321},
+			}))
+		})
+		It("SFP without diagnostics", func() {
+			eeprom := make([]byte, 256)
+			putEepromString(eeprom, sff8472VendorNameOffset, "Mellanox")
+
+			info, diagnostics, err := parseModuleEeprom(ethModuleSff8079, eeprom)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Vendor).To(Equal("Mellanox"))
+			Expect(diagnostics).To(BeNil())
+		})
+		It("QSFP", func() {
+			eeprom := make([]byte, 256)
+			putEepromString(eeprom, sff8636VendorNameOffset, "Mellanox")
+			putEepromString(eeprom, sff8636VendorPNOffset, "MMA1B00-C100D")
+			putEepromString(eeprom, sff8636VendorSNOffset, "MT1234")
+			binary.BigEndian.PutUint16(eeprom[sff8636TempOffset:], 0xFF00)
+			for lane := 0; lane < sff8636Lanes; lane++ {
+				binary.BigEndian.PutUint16(eeprom[sff8636RxPowerOffset+2*lane:], uint16(10000*(lane+1)))
+				binary.BigEndian.PutUint16(eeprom[sff8636TxPowerOffset+2*lane:], 10000)
+			}
+
+			info, diagnostics, err := parseModuleEeprom(ethModuleSff8636, eeprom)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(Equal(&sriovnetworkv1.TransceiverInfo{
+				Vendor:       "Mellanox",
+				PartNumber:   "MMA1B00-C100D",
+				SerialNumber: "MT1234",
+			}))
+			Expect(diagnostics).To(Equal(&types.TransceiverDiagnostics{
+				Temperature: -1,
+				TxPower:     []float64{1, 1, 1, 1},
+				RxPower:     []float64{1, 2, 3, 4},
+			}))
+		})
+		It("unsupported module", func() {
+			_, _, err := parseModuleEeprom(0xff, make([]byte, 256))
+			Expect(err).To(HaveOccurred())
+		})
+		It("truncated EEPROM", func() {
+			_, _, err := parseModuleEeprom(ethModuleSff8636, make([]byte, 128))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// bridgeFdbEntry is an entry of the output of bridge -j fdb show
+type bridgeFdbEntry struct {
+	Mac   string   `json:"mac"`
+	Flags []string `json:"flags"`
+}
+
+// ipLink is a link of the output of ip -j -d link show
+type ipLink struct {
+	Ifname    string `json:"ifname"`
+	ParentBus string `json:"parentbus"`
+	ParentDev string `json:"parentdev"`
+}
+
+// SyncVfUnicastMacs adds the MACs to the unicast filter of the netdev of the VF with bridge fdb, the VF drivers
+// program them on the VF as the ndo_set_rx_mode of the netdev does for the secondary MACs. The kernel flushes the
+// filter when the netdev moves to another network namespace, the MACs are programmed in the namespace the netdev is
+// in, e.g. the one of the pod using the VF, and programmed again at each sync. Only the MACs the operator added are
+// removed once no longer requested, they are recorded by VF in the unicast MACs file of the node. The MAC of the VF
+// is not part of the filter list.
+func (n *network) SyncVfUnicastMacs(vfAddr string, macs []string) error {
+	requested := []string{}
+	for _, mac := range macs {
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			return fmt.Errorf("invalid unicast MAC %q: %v", mac, err)
+		}
+		requested = append(requested, hw.String())
+	}
+	added, err := loadUnicastMacs()
+	if err != nil {
+		return err
+	}
+	if len(requested) == 0 && len(added[vfAddr]) == 0 {
+		return nil
+	}
+
+	netns, name, err := n.findVfNetdev(vfAddr)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		log.Log.V(2).Info("SyncVfUnicastMacs(): the VF has no netdev, skipping", "device", vfAddr)
+		return nil
+	}
+	log.Log.V(2).Info("SyncVfUnicastMacs(): sync the unicast MACs", "device", vfAddr, "name", name, "netns", netns, "macs", requested)
+	out, err := n.runInNamespace(netns, "bridge", "-j", "fdb", "show", "dev", name)
+	if err != nil {
+		return fmt.Errorf("failed to list the unicast MACs of %s: %v", name, err)
+	}
+	entries := []bridgeFdbEntry{}
+	if strings.TrimSpace(out) != "" {
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			return fmt.Errorf("failed to parse the unicast MACs of %s: %v", name, err)
+		}
+	}
+	existing := map[string]bool{}
+	for _, entry := range entries {
+		hw, err := net.ParseMAC(entry.Mac)
+		if err != nil || hw[0]&1 != 0 || !entry.isSelf() {
+			// the multicast and the bridge entries are not managed
+			continue
+		}
+		existing[hw.String()] = true
+	}
+
+	if err := guard.Check(vfAddr); err != nil {
+		return err
+	}
+	for _, mac := range added[vfAddr] {
+		if slices.Contains(requested, mac) || !existing[mac] {
+			continue
+		}
+		log.Log.Info("SyncVfUnicastMacs(): remove unicast MAC", "device", vfAddr, "name", name, "mac", mac)
+		if _, err := n.runInNamespace(netns, "bridge", "fdb", "del", mac, "dev", name, "self"); err != nil {
+			return fmt.Errorf("failed to remove the unicast MAC %s of %s: %v", mac, name, err)
+		}
+	}
+	// the MACs are recorded before they are added, a MAC added by a failed sync is removed by the next one
+	added[vfAddr] = requested
+	if err := saveUnicastMacs(added); err != nil {
+		return err
+	}
+	for _, mac := range requested {
+		if existing[mac] {
+			continue
+		}
+		log.Log.Info("SyncVfUnicastMacs(): add unicast MAC", "device", vfAddr, "name", name, "mac", mac)
+		if _, err := n.runInNamespace(netns, "bridge", "fdb", "add", mac, "dev", name, "self", "permanent"); err != nil {
+			return fmt.Errorf("failed to add the unicast MAC %s to %s: %v", mac, name, err)
+		}
+		existing[mac] = true
+	}
+	return nil
+}
+
+// isSelf returns true for the entries of the netdev itself, the other entries are the entries of a bridge
+func (e *bridgeFdbEntry) isSelf() bool {
+	for _, f := range e.Flags {
+		if f == "self" {
+			return true
+		}
+	}
+	return false
+}
+
+// findVfNetdev returns the network namespace and the name of the netdev of the VF, the namespace is the path of the
+// namespace of a process of the node, empty for the host namespace. The name is empty when the VF has no netdev.
+func (n *network) findVfNetdev(vfAddr string) (string, string, error) {
+	if name := n.TryGetInterfaceName(vfAddr); name != "" {
+		return "", name, nil
+	}
+	namespaces, err := netNamespaces()
+	if err != nil {
+		return "", "", err
+	}
+	for _, netns := range namespaces {
+		out, err := n.runInNamespace(netns, "ip", "-j", "-d", "link", "show")
+		if err != nil {
+			// the processes of the namespace may have exited meanwhile
+			log.Log.V(2).Info("findVfNetdev(): failed to list the links of the namespace", "netns", netns, "error", err)
+			continue
+		}
+		links := []ipLink{}
+		if err := json.Unmarshal([]byte(out), &links); err != nil {
+			return "", "", fmt.Errorf("failed to parse the links of the namespace %s: %v", netns, err)
+		}
+		for _, link := range links {
+			if link.ParentBus == "pci" && link.ParentDev == vfAddr {
+				return netns, link.Ifname, nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// netNamespaces returns a path for each network namespace of the processes of the node other than the host one, the
+// daemon shares the PID namespace of the node
+func netNamespaces() ([]string, error) {
+	procDir := filepath.Join(vars.FilesystemRoot, "/proc")
+	hostNetns, err := os.Readlink(filepath.Join(procDir, "1/ns/net"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the network namespace of the host: %v", err)
+	}
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the processes: %v", err)
+	}
+	seen := map[string]bool{hostNetns: true}
+	namespaces := []string{}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		path := filepath.Join("/proc", entry.Name(), "ns/net")
+		netns, err := os.Readlink(filepath.Join(vars.FilesystemRoot, path))
+		if err != nil || seen[netns] {
+			continue
+		}
+		seen[netns] = true
+		namespaces = append(namespaces, path)
+	}
+	return namespaces, nil
+}
+
+// loadUnicastMacs returns the unicast MACs the operator added by VF PCI address
+func loadUnicastMacs() (map[string][]string, error) {
+	added := map[string][]string{}
+	data, err := os.ReadFile(utils.GetHostExtensionPath(consts.UnicastMacsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return added, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the unicast MACs file: %v", err)
+	}
+	if err := json.Unmarshal(data, &added); err != nil {
+		return nil, fmt.Errorf("failed to parse the unicast MACs file: %v", err)
+	}
+	return added, nil
+}
+
+// saveUnicastMacs writes the unicast MACs the operator added, the VFs without MAC are left out
+func saveUnicastMacs(added map[string][]string) error {
+	for vfAddr, macs := range added {
+		if len(macs) == 0 {
+			delete(added, vfAddr)
+			continue
+		}
+		sort.Strings(macs)
+	}
+	path := utils.GetHostExtensionPath(consts.UnicastMacsFile)
+	if len(added) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the unicast MACs file: %v", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(added, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the unicast MACs file: %v", err)
+	}
+	return nil
+}
+
+// runInNamespace runs the program of the host in the network namespace, the host namespace when it is empty, and
+// returns its output
+func (n *network) runInNamespace(netns, program string, args ...string) (string, error) {
+	if netns != "" {
+		args = append([]string{"--net=" + netns, program}, args...)
+		program = "nsenter"
+	}
+	cmd, cmdArgs := utils.GetChrootCommand(program, args...)
+	stdout, stderr, err := n.utilsHelper.RunCommand(cmd, cmdArgs...)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
@@ -0,0 +1,131 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	dputilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	utilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("VF unicast MACs", func() {
+	var (
+		n           types.NetworkInterface
+		utilsMock   *utilsMockPkg.MockCmdInterface
+		dputilsMock *dputilsMockPkg.MockDPUtilsLib
+		// output holds the output of the commands, the commands run are recorded in commands
+		output   map[string]string
+		commands []string
+	)
+	BeforeEach(func() {
+		testCtrl := gomock.NewController(GinkgoT())
+		utilsMock = utilsMockPkg.NewMockCmdInterface(testCtrl)
+		dputilsMock = dputilsMockPkg.NewMockDPUtilsLib(testCtrl)
+		n = New(utilsMock, dputilsMock)
+		output = map[string]string{}
+		commands = nil
+		utilsMock.EXPECT().RunCommand("chroot", gomock.Any()).DoAndReturn(
+			func(_ string, args ...string) (string, string, error) {
+				cmd := strings.Join(args[1:], " ")
+				commands = append(commands, cmd)
+				return output[cmd], "", nil
+			}).AnyTimes()
+		origInChroot := vars.InChroot
+		DeferCleanup(func() { vars.InChroot = origInChroot })
+		vars.InChroot = false
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"host/etc/sriov-operator", "proc/1/ns", "proc/123/ns", "proc/124/ns"},
+			Symlinks: map[string]string{
+				"proc/1/ns/net":   "net:[1]",
+				"proc/123/ns/net": "net:[2]",
+				"proc/124/ns/net": "net:[2]",
+			},
+		})
+	})
+
+	trackedMacs := func() string {
+		data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, "host/etc/sriov-operator/unicast-macs.json"))
+		if os.IsNotExist(err) {
+			return ""
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return strings.Join(strings.Fields(string(data)), "")
+	}
+
+	It("adds the missing MACs and only removes the MACs it added", func() {
+		dputilsMock.EXPECT().GetNetNames("0000:3b:02.1").Return([]string{"enp59s0f0v1"}, nil).Times(2)
+		output["bridge -j fdb show dev enp59s0f0v1"] = `[
+			{"mac":"33:33:00:00:00:01","flags":["self"],"state":"permanent"},
+			{"mac":"02:00:00:00:00:01","flags":["self"],"state":"permanent"},
+			{"mac":"02:00:00:00:00:02","flags":["self"],"state":"permanent"},
+			{"mac":"02:00:00:00:00:09","master":"br0","state":""}]`
+		Expect(n.SyncVfUnicastMacs("0000:3b:02.1", []string{"02:00:00:00:00:02", "02:00:00:00:00:03"})).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"bridge -j fdb show dev enp59s0f0v1",
+			"bridge fdb add 02:00:00:00:00:03 dev enp59s0f0v1 self permanent",
+		}))
+		Expect(trackedMacs()).To(Equal(`{"0000:3b:02.1":["02:00:00:00:00:02","02:00:00:00:00:03"]}`))
+
+		// the MAC added by other means is kept
+		commands = nil
+		Expect(n.SyncVfUnicastMacs("0000:3b:02.1", []string{"02:00:00:00:00:03"})).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"bridge -j fdb show dev enp59s0f0v1",
+			"bridge fdb del 02:00:00:00:00:02 dev enp59s0f0v1 self",
+			"bridge fdb add 02:00:00:00:00:03 dev enp59s0f0v1 self permanent",
+		}))
+		Expect(trackedMacs()).To(Equal(`{"0000:3b:02.1":["02:00:00:00:00:03"]}`))
+	})
+
+	It("programs the MACs of a VF in the network namespace of a pod", func() {
+		dputilsMock.EXPECT().GetNetNames("0000:3b:02.1").Return(nil, fmt.Errorf("no net directory"))
+		output["nsenter --net=/proc/123/ns/net ip -j -d link show"] = `[
+			{"ifname":"lo"},
+			{"ifname":"net1","parentbus":"pci","parentdev":"0000:3b:02.1"}]`
+		output["nsenter --net=/proc/123/ns/net bridge -j fdb show dev net1"] = "[]"
+		Expect(n.SyncVfUnicastMacs("0000:3b:02.1", []string{"02:00:00:00:00:01"})).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"nsenter --net=/proc/123/ns/net ip -j -d link show",
+			"nsenter --net=/proc/123/ns/net bridge -j fdb show dev net1",
+			"nsenter --net=/proc/123/ns/net bridge fdb add 02:00:00:00:00:01 dev net1 self permanent",
+		}))
+	})
+
+	It("removes the MACs it added once no MAC is requested", func() {
+		Expect(os.WriteFile(filepath.Join(vars.FilesystemRoot, "host/etc/sriov-operator/unicast-macs.json"),
+			[]byte(`{"0000:3b:02.1":["02:00:00:00:00:01"]}`), 0644)).To(Succeed())
+		dputilsMock.EXPECT().GetNetNames("0000:3b:02.1").Return([]string{"enp59s0f0v1"}, nil)
+		output["bridge -j fdb show dev enp59s0f0v1"] = `[{"mac":"02:00:00:00:00:01","flags":["self"],"state":"permanent"}]`
+		Expect(n.SyncVfUnicastMacs("0000:3b:02.1", nil)).To(Succeed())
+		Expect(commands).To(Equal([]string{
+			"bridge -j fdb show dev enp59s0f0v1",
+			"bridge fdb del 02:00:00:00:00:01 dev enp59s0f0v1 self",
+		}))
+		Expect(trackedMacs()).To(BeEmpty())
+
+		// nothing is run for the VFs without MAC
+		commands = nil
+		Expect(n.SyncVfUnicastMacs("0000:3b:02.1", nil)).To(Succeed())
+		Expect(commands).To(BeEmpty())
+	})
+
+	It("returns the errors of bridge", func() {
+		utilsMock = utilsMockPkg.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+		n = New(utilsMock, dputilsMock)
+		dputilsMock.EXPECT().GetNetNames("0000:3b:02.1").Return([]string{"enp59s0f0v1"}, nil)
+		utilsMock.EXPECT().RunCommand("chroot", gomock.Any()).Return("[]", "", nil)
+		utilsMock.EXPECT().RunCommand("chroot", gomock.Any()).Return("", "RTNETLINK answers: Operation not supported", fmt.Errorf("exit status 2"))
+		err := n.SyncVfUnicastMacs("0000:3b:02.1", []string{"02:00:00:00:00:01"})
+		Expect(err).To(MatchError(ContainSubstring("Operation not supported")))
+	})
+})
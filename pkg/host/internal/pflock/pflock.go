@@ -0,0 +1,49 @@
+// Package pflock serializes the operations on the sysfs and the netlink attributes of a PF and of its VFs. The locks
+// are shared by all the host managers of the process, so the configuration of a PF, the discovery of the devices by
+// the status writer and the host drift audit never interleave on the same PF, while the PFs are still changed and
+// read concurrently.
+package pflock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+var (
+	mu sync.Mutex
+	// locks are the locks of the PFs by PCI address, a lock is created the first time its PF is locked
+	locks = map[string]*sync.Mutex{}
+)
+
+// Lock locks the PF of the PCI address, blocking until the PF is unlocked, and returns the function unlocking it.
+// The locks are not reentrant, a function holding the lock of a PF must not call another function locking it.
+func Lock(pciAddr string) func() {
+	mu.Lock()
+	l, found := locks[pciAddr]
+	if !found {
+		l = &sync.Mutex{}
+		locks[pciAddr] = l
+	}
+	mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// LockNetDev locks the PF of the netdev, the PF itself or the PF of a VF or of a representor, and returns the function
+// unlocking it. Nothing is locked for the netdevs without PCI device.
+func LockNetDev(name string) func() {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "device"))
+	if err != nil {
+		return func() {}
+	}
+	pciAddr := filepath.Base(link)
+	if physfn, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "physfn")); err == nil {
+		pciAddr = filepath.Base(physfn)
+	}
+	return Lock(pciAddr)
+}
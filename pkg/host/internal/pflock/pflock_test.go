@@ -0,0 +1,63 @@
+package pflock
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("PF lock", func() {
+	It("serializes the operations on the same PF", func() {
+		unlock := Lock("0000:3b:00.0")
+		locked := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			unlockAgain := Lock("0000:3b:00.0")
+			close(locked)
+			unlockAgain()
+		}()
+		Consistently(locked, 100*time.Millisecond).ShouldNot(BeClosed())
+		unlock()
+		Eventually(locked).Should(BeClosed())
+	})
+
+	It("doesn't serialize the operations on different PFs", func() {
+		unlock := Lock("0000:3b:00.0")
+		defer unlock()
+		locked := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer Lock("0000:3b:00.1")()
+			close(locked)
+		}()
+		Eventually(locked).Should(BeClosed())
+	})
+
+	It("locks the PF of the netdevs of its VFs", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"sys/class/net/enp59s0f0v1", "sys/bus/pci/devices/0000:3b:02.1"},
+			Symlinks: map[string]string{
+				"sys/class/net/enp59s0f0v1/device":        "../../../0000:3b:02.1",
+				"sys/bus/pci/devices/0000:3b:02.1/physfn": "../0000:3b:00.0",
+			},
+		})
+		unlock := Lock("0000:3b:00.0")
+		locked := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer LockNetDev("enp59s0f0v1")()
+			close(locked)
+		}()
+		Consistently(locked, 100*time.Millisecond).ShouldNot(BeClosed())
+		unlock()
+		Eventually(locked).Should(BeClosed())
+
+		// nothing is locked for the netdevs without PCI device
+		defer Lock("0000:3b:00.0")()
+		LockNetDev("lo")()
+	})
+})
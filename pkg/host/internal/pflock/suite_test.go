@@ -0,0 +1,13 @@
+package pflock
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPfLock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package PF Lock Suite")
+}
@@ -0,0 +1,36 @@
+package sriov
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// getDriverOverride returns the driver_override of the device, empty when it isn't set
+func getDriverOverride(pciAddr string) string {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "driver_override"))
+	if err != nil {
+		return ""
+	}
+	override := strings.TrimSpace(string(data))
+	if override == "(null)" {
+		return ""
+	}
+	return override
+}
+
+// setBindErrors reports the error of the last failed bind of the unbound VFs
+func (s *sriov) setBindErrors(pfList []sriovnetworkv1.InterfaceExt) {
+	for i := range pfList {
+		for j := range pfList[i].VFs {
+			vf := &pfList[i].VFs[j]
+			if vf.Driver == "" {
+				vf.BindError = s.kernelHelper.GetBindError(vf.PciAddress)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sriov
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// nmstateMtu is the wait of a PF for kubernetes-nmstate to raise its MTU to the MTU of its policies
+type nmstateMtu struct {
+	PendingSince time.Time `json:"pendingSince,omitempty"`
+	// Fallback is true once the config daemon set the MTU of the PF itself, the MTU is reset with the PF
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// waitNMStateMtu returns ErrNMStatePending while the MTU of the PF is below the MTU of its policies for less than
+// NMStateMtuTimeout, e.g. while the node boots and kubernetes-nmstate hasn't applied its policies yet. It returns nil
+// once the wait timed out, e.g. when no NodeNetworkConfigurationPolicy sets the MTU of the PF, the MTU is then set by
+// the config daemon.
+func waitNMStateMtu(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	mtus, err := loadNMStateMtus()
+	if err != nil {
+		return err
+	}
+	mtu := mtus[iface.PciAddress]
+	if mtu.Fallback {
+		return nil
+	}
+	if mtu.PendingSince.IsZero() {
+		mtu.PendingSince = time.Now()
+	}
+	if time.Since(mtu.PendingSince) >= consts.NMStateMtuTimeout {
+		log.Log.Info("ConfigSriovDevice(): kubernetes-nmstate didn't set the MTU of the PF in time, setting it",
+			"device", iface.PciAddress, "mtu", iface.Mtu, "timeout", consts.NMStateMtuTimeout)
+		mtus[iface.PciAddress] = nmstateMtu{Fallback: true}
+		return saveNMStateMtus(mtus)
+	}
+	mtus[iface.PciAddress] = mtu
+	if err := saveNMStateMtus(mtus); err != nil {
+		return err
+	}
+	err = snerrors.Wrap(snerrors.ErrNMStatePending, fmt.Errorf(
+		"ConfigSriovDevice(): requested MTU(%d) is greater than the MTU(%d) set by kubernetes-nmstate for device %s",
+		iface.Mtu, ifaceStatus.Mtu, iface.PciAddress))
+	log.Log.Info(err.Error())
+	return err
+}
+
+// nmstateMtuSet ends the wait of the PF once kubernetes-nmstate set its MTU
+func nmstateMtuSet(pciAddress string) error {
+	mtus, err := loadNMStateMtus()
+	if err != nil {
+		return err
+	}
+	if mtu, found := mtus[pciAddress]; !found || mtu.Fallback {
+		return nil
+	}
+	delete(mtus, pciAddress)
+	return saveNMStateMtus(mtus)
+}
+
+// releaseNMStateMtu forgets the PF and returns true if the config daemon set its MTU itself
+func releaseNMStateMtu(pciAddress string) (bool, error) {
+	mtus, err := loadNMStateMtus()
+	if err != nil {
+		return false, err
+	}
+	mtu, found := mtus[pciAddress]
+	if !found {
+		return false, nil
+	}
+	delete(mtus, pciAddress)
+	return mtu.Fallback, saveNMStateMtus(mtus)
+}
+
+// loadNMStateMtus returns the waits of the PFs for kubernetes-nmstate by PCI address
+func loadNMStateMtus() (map[string]nmstateMtu, error) {
+	mtus := map[string]nmstateMtu{}
+	data, err := os.ReadFile(utils.GetHostExtensionPath(consts.NMStateMtusFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return mtus, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the kubernetes-nmstate MTUs file: %v", err)
+	}
+	if err := json.Unmarshal(data, &mtus); err != nil {
+		return nil, fmt.Errorf("failed to parse the kubernetes-nmstate MTUs file: %v", err)
+	}
+	return mtus, nil
+}
+
+// saveNMStateMtus writes the waits of the PFs for kubernetes-nmstate, and removes the file when no PF waits
+func saveNMStateMtus(mtus map[string]nmstateMtu) error {
+	path := utils.GetHostExtensionPath(consts.NMStateMtusFile)
+	if len(mtus) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the kubernetes-nmstate MTUs file: %v", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(mtus, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the kubernetes-nmstate MTUs file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sriov
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// readPcieAttr returns the content of the PCIe link attribute of the device, or an empty string if the kernel
+// doesn't report it, e.g. "Unknown" for the speed of a link that is down
+func readPcieAttr(pciAddr, attr string) string {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, attr))
+	if err != nil {
+		return ""
+	}
+	value := strings.TrimSpace(string(data))
+	if strings.HasPrefix(value, "Unknown") {
+		return ""
+	}
+	return value
+}
+
+// pcieSpeedGTs returns the speed in GT/s of a link speed reported by the kernel, e.g. 16 for "16.0 GT/s PCIe"
+func pcieSpeedGTs(speed string) float64 {
+	fields := strings.Fields(speed)
+	if len(fields) == 0 {
+		return 0
+	}
+	gts, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return gts
+}
+
+// getPcieLink returns the current and the maximum speed and width of the PCIe link of the device, or nil if the
+// kernel doesn't report them, e.g. for the devices of the virtual platforms
+func getPcieLink(pciAddr string) *sriovnetworkv1.PcieLinkInfo {
+	width, _ := strconv.Atoi(readPcieAttr(pciAddr, "current_link_width"))
+	maxWidth, _ := strconv.Atoi(readPcieAttr(pciAddr, "max_link_width"))
+	link := &sriovnetworkv1.PcieLinkInfo{
+		Speed:    readPcieAttr(pciAddr, "current_link_speed"),
+		MaxSpeed: readPcieAttr(pciAddr, "max_link_speed"),
+		Width:    width,
+		MaxWidth: maxWidth,
+	}
+	if link.Width <= 0 || link.MaxWidth <= 0 || link.Speed == "" || link.MaxSpeed == "" {
+		return nil
+	}
+	link.Degraded = link.Width < link.MaxWidth || pcieSpeedGTs(link.Speed) < pcieSpeedGTs(link.MaxSpeed)
+	return link
+}
@@ -0,0 +1,77 @@
+package sriov
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// setPortLayout reports the position of the PFs on their card with the link type, the speed and the capabilities of
+// each port, the PFs sharing the PCI domain, bus and device of a card are its ports, indexed in the order of their PCI
+// functions. The zPCI functions have no card.
+func setPortLayout(pfList []sriovnetworkv1.InterfaceExt) {
+	cards := map[string][]int{}
+	for i := range pfList {
+		if pfList[i].ZpciUID != "" {
+			continue
+		}
+		card, _, found := cutPciFunction(pfList[i].PciAddress)
+		if !found {
+			continue
+		}
+		cards[card] = append(cards[card], i)
+	}
+	for card, ports := range cards {
+		sort.Slice(ports, func(i, j int) bool {
+			_, fi, _ := cutPciFunction(pfList[ports[i]].PciAddress)
+			_, fj, _ := cutPciFunction(pfList[ports[j]].PciAddress)
+			return fi < fj
+		})
+		for index, i := range ports {
+			pfList[i].Port = &sriovnetworkv1.NicPortInfo{Card: card, Index: index, Ports: len(ports),
+				LinkType: pfList[i].LinkType, Speed: pfList[i].LinkSpeed, Capabilities: portCapabilities(&pfList[i])}
+		}
+	}
+}
+
+// portCapabilities returns the SR-IOV features the PF supports from its discovered status, and the RDMA device of the
+// PF
+func portCapabilities(pf *sriovnetworkv1.InterfaceExt) []sriovnetworkv1.PortCapability {
+	var capabilities []sriovnetworkv1.PortCapability
+	if pf.TotalVfs > 0 {
+		capabilities = append(capabilities, sriovnetworkv1.PortCapabilitySriov)
+	}
+	// the eswitch mode is only reported by the PFs supporting devlink
+	if pf.EswitchMode != "" {
+		capabilities = append(capabilities, sriovnetworkv1.PortCapabilitySwitchdev)
+	}
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pf.PciAddress, "infiniband")); err == nil {
+		capabilities = append(capabilities, sriovnetworkv1.PortCapabilityRdma)
+	}
+	if pf.Ptp != nil && pf.Ptp.Clock != "" {
+		capabilities = append(capabilities, sriovnetworkv1.PortCapabilityPtp)
+	}
+	if pf.VfTotalMsix > 0 {
+		capabilities = append(capabilities, sriovnetworkv1.PortCapabilityVfMsix)
+	}
+	return capabilities
+}
+
+// cutPciFunction splits the PCI address into the domain, bus and device, and the function
+func cutPciFunction(pciAddr string) (string, int, bool) {
+	i := strings.LastIndex(pciAddr, ".")
+	if i < 0 {
+		return "", 0, false
+	}
+	function, err := strconv.Atoi(pciAddr[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return pciAddr[:i], function, true
+}
@@ -1,8 +1,11 @@
 package sriov
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,69 +15,146 @@ import (
 
 	"github.com/jaypipes/ghw"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	dputilsPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
 	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/pflock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/pfrename"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/portsplit"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/quirks"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
 )
 
 type sriov struct {
-	utilsHelper   utils.CmdInterface
-	kernelHelper  types.KernelInterface
-	networkHelper types.NetworkInterface
-	udevHelper    types.UdevInterface
-	netlinkLib    netlinkPkg.NetlinkLib
-	dputilsLib    dputilsPkg.DPUtilsLib
+	utilsHelper      utils.CmdInterface
+	kernelHelper     types.KernelInterface
+	networkHelper    types.NetworkInterface
+	udevHelper       types.UdevInterface
+	inventory        types.InventoryInterface
+	congestionHelper types.CongestionControlInterface
+	netlinkLib       netlinkPkg.NetlinkLib
+	dputilsLib       dputilsPkg.DPUtilsLib
 }
 
 func New(utilsHelper utils.CmdInterface,
 	kernelHelper types.KernelInterface,
 	networkHelper types.NetworkInterface,
 	udevHelper types.UdevInterface,
+	inventory types.InventoryInterface,
+	congestionHelper types.CongestionControlInterface,
 	netlinkLib netlinkPkg.NetlinkLib,
 	dputilsLib dputilsPkg.DPUtilsLib) types.SriovInterface {
 	return &sriov{utilsHelper: utilsHelper,
-		kernelHelper:  kernelHelper,
-		networkHelper: networkHelper,
-		udevHelper:    udevHelper,
-		netlinkLib:    netlinkLib,
-		dputilsLib:    dputilsLib,
+		kernelHelper:     kernelHelper,
+		networkHelper:    networkHelper,
+		udevHelper:       udevHelper,
+		inventory:        inventory,
+		congestionHelper: congestionHelper,
+		netlinkLib:       netlinkLib,
+		dputilsLib:       dputilsLib,
 	}
 }
 
 func (s *sriov) SetSriovNumVfs(pciAddr string, numVfs int) error {
+	defer pflock.Lock(pciAddr)()
+	return s.setSriovNumVfs(pciAddr, numVfs)
+}
+
+// setSriovNumVfs sets the number of VFs of the PF, the caller holds the lock of the PF
+func (s *sriov) setSriovNumVfs(pciAddr string, numVfs int) error {
 	log.Log.V(2).Info("SetSriovNumVfs(): set NumVfs", "device", pciAddr, "numVfs", numVfs)
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
 	numVfsFilePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.NumVfsFile)
 	bs := []byte(strconv.Itoa(numVfs))
-	err := os.WriteFile(numVfsFilePath, []byte("0"), os.ModeAppend)
+	// VFs are added or removed, the cached PCI devices are no longer accurate
+	defer s.inventory.InvalidateInventory()
+	err := utils.WriteHostFile(numVfsFilePath, []byte("0"), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "SetSriovNumVfs(): fail to reset NumVfs file", "path", numVfsFilePath)
-		return err
+		return numVfsError(err)
 	}
 	if numVfs == 0 {
 		return nil
 	}
-	err = os.WriteFile(numVfsFilePath, bs, os.ModeAppend)
+	err = utils.WriteHostFile(numVfsFilePath, bs, os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "SetSriovNumVfs(): fail to set NumVfs file", "path", numVfsFilePath)
-		return err
+		return numVfsError(err)
 	}
 	return nil
 }
 
+// setDriversAutoprobe enables or disables the probing of the VFs of the PF by the kernel drivers when they are
+// created, a PF whose kernel doesn't expose the setting always probes its VFs
+func setDriversAutoprobe(pciAddr string, enabled bool) error {
+	path := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.DriversAutoprobeFile)
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if enabled && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read the drivers autoprobe of %s: %v", pciAddr, err)
+	}
+	if strings.TrimSpace(string(current)) == value {
+		return nil
+	}
+	log.Log.V(2).Info("setDriversAutoprobe(): set drivers autoprobe", "device", pciAddr, "enabled", enabled)
+	if err := utils.WriteHostFile(path, []byte(value), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to set the drivers autoprobe of %s: %v", pciAddr, err)
+	}
+	return nil
+}
+
+// driversAutoprobeDisabled returns true when the kernel drivers don't probe the VFs of the PF when they are created
+func driversAutoprobeDisabled(pciAddr string) bool {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.DriversAutoprobeFile))
+	return err == nil && strings.TrimSpace(string(data)) == "0"
+}
+
+// numVfsError classifies the errors of the writes to sriov_numvfs, the kernel returns EBUSY while
+// the VFs are in use, e.g. bound to a userspace driver
+func numVfsError(err error) error {
+	if errors.Is(err, syscall.EBUSY) {
+		return snerrors.Wrap(snerrors.ErrVfInUse, err)
+	}
+	return err
+}
+
 func (s *sriov) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error {
 	log.Log.V(2).Info("ResetSriovDevice(): reset SRIOV device", "address", ifaceStatus.PciAddress)
-	if err := s.SetSriovNumVfs(ifaceStatus.PciAddress, 0); err != nil {
+	defer pflock.Lock(ifaceStatus.PciAddress)()
+	if err := s.setSriovNumVfs(ifaceStatus.PciAddress, 0); err != nil {
+		return err
+	}
+	if err := setDriversAutoprobe(ifaceStatus.PciAddress, true); err != nil {
 		return err
 	}
+	if vars.NMStateIntegration {
+		// the MTU of the PF belongs to kubernetes-nmstate, unless the config daemon set it once the wait for
+		// kubernetes-nmstate timed out
+		fallback, err := releaseNMStateMtu(ifaceStatus.PciAddress)
+		if err != nil || !fallback {
+			return err
+		}
+	}
 	if ifaceStatus.LinkType == consts.LinkTypeETH {
 		var mtu int
 		is := sriovnetworkv1.InitialState.GetInterfaceStateByPciAddress(ifaceStatus.PciAddress)
@@ -108,7 +188,9 @@ func (s *sriov) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) sriovnetwork
 		PciAddress: pciAddr,
 		Driver:     driver,
 		VfID:       id,
+		MsixCount:  getVfMsixCount(pciAddr),
 	}
+	vf.DriverOverride = getDriverOverride(pciAddr)
 
 	if mtu := s.networkHelper.GetNetdevMTU(pciAddr); mtu > 0 {
 		vf.Mtu = mtu
@@ -116,6 +198,7 @@ func (s *sriov) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) sriovnetwork
 	if name := s.networkHelper.TryGetInterfaceName(pciAddr); name != "" {
 		vf.Name = name
 		vf.Mac = s.networkHelper.GetNetDevMac(name)
+		vf.HwTimestamping = s.networkHelper.GetNetDevHwTimestamping(name)
 	}
 
 	for _, device := range devices {
@@ -184,20 +267,64 @@ func (s *sriov) SetVfAdminMac(vfAddr string, pfLink, vfLink netlink.Link) error
 	return nil
 }
 
+// setUnprobedVfAdminMac sets a random admin MAC address to the VF the kernel drivers didn't probe, the VFs whose admin
+// MAC address is already set keep it
+func (s *sriov) setUnprobedVfAdminMac(vfAddr string, pfLink netlink.Link) error {
+	vfID, err := s.dputilsLib.GetVFID(vfAddr)
+	if err != nil {
+		log.Log.Error(err, "setUnprobedVfAdminMac(): unable to get VF id", "address", vfAddr)
+		return err
+	}
+	for _, vf := range pfLink.Attrs().Vfs {
+		if vf.ID == vfID && len(vf.Mac) > 0 && !bytes.Equal(vf.Mac, make(net.HardwareAddr, len(vf.Mac))) {
+			return nil
+		}
+	}
+	log.Log.Info("setUnprobedVfAdminMac()", "vf", vfAddr)
+	return s.netlinkLib.LinkSetVfHardwareAddr(pfLink, vfID, utils.GenerateRandomMAC())
+}
+
+// HasSriovCapablePfs returns true if a supported network device of the system is an SR-IOV capable PF, e.g. in a
+// virtual machine with a PF passed through or emulated by the hypervisor
+func (s *sriov) HasSriovCapablePfs() (bool, error) {
+	devices, err := s.inventory.GetPCIDevices()
+	if err != nil {
+		return false, fmt.Errorf("HasSriovCapablePfs(): error getting PCI info: %v", err)
+	}
+	for _, device := range devices {
+		devClass, err := strconv.ParseInt(device.Class.ID, 16, 64)
+		if err != nil || devClass != consts.NetClass {
+			continue
+		}
+		if !vars.DevMode && !sriovnetworkv1.IsSupportedModel(device.Vendor.ID, device.Product.ID) {
+			continue
+		}
+		if s.dputilsLib.IsSriovPF(device.Address) && s.dputilsLib.GetSriovVFcapacity(device.Address) > 0 {
+			log.Log.V(2).Info("HasSriovCapablePfs(): SR-IOV capable PF", "device", device.Address)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error) {
 	log.Log.V(2).Info("DiscoverSriovDevices")
 	pfList := []sriovnetworkv1.InterfaceExt{}
 
-	pci, err := ghw.PCI()
+	devices, err := s.inventory.GetPCIDevices()
 	if err != nil {
 		return nil, fmt.Errorf("DiscoverSriovDevices(): error getting PCI info: %v", err)
 	}
 
-	devices := pci.ListDevices()
 	if len(devices) == 0 {
 		return nil, fmt.Errorf("DiscoverSriovDevices(): could not retrieve PCI devices")
 	}
 
+	// the PFs renamed by their policies are reported with their original names
+	originalNames := pfrename.OriginalNames()
+	// the diagnostic readings of the transceivers are read with their identification and only exported in the metrics
+	diagnostics := map[string]*types.TransceiverDiagnostics{}
+
 	for _, device := range devices {
 		devClass, err := strconv.ParseInt(device.Class.ID, 16, 64)
 		if err != nil {
@@ -216,97 +343,165 @@ func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sri
 			continue
 		}
 
-		driver, err := s.dputilsLib.GetDriverName(device.Address)
-		if err != nil {
-			log.Log.Error(err, "DiscoverSriovDevices(): unable to parse device driver for device, skipping", "device", device)
-			continue
+		if iface := s.discoverPf(storeManager, device, devices, originalNames, diagnostics); iface != nil {
+			pfList = append(pfList, *iface)
 		}
+	}
+	setPortLayout(pfList)
+	s.setBindErrors(pfList)
+	metrics.SetTransceiverDiagnostics(vars.NodeName, pfList, diagnostics)
 
-		deviceNames, err := s.dputilsLib.GetNetNames(device.Address)
-		if err != nil {
-			log.Log.Error(err, "DiscoverSriovDevices(): unable to get device names for device, skipping", "device", device)
-			continue
-		}
+	return pfList, nil
+}
 
-		if len(deviceNames) == 0 {
-			// no network devices found, skipping device
-			continue
-		}
+// discoverPf returns the SR-IOV interface of the network device, nil when the device is left out, and adds the
+// diagnostic readings of its transceiver to diagnostics. The PF is locked while its attributes and its VFs are read,
+// so it is never reported in the middle of its configuration.
+func (s *sriov) discoverPf(storeManager store.ManagerInterface, device *ghw.PCIDevice, devices []*ghw.PCIDevice,
+	originalNames map[string]string, diagnostics map[string]*types.TransceiverDiagnostics) *sriovnetworkv1.InterfaceExt {
+	defer pflock.Lock(device.Address)()
 
-		if !vars.DevMode {
-			if !sriovnetworkv1.IsSupportedModel(device.Vendor.ID, device.Product.ID) {
-				log.Log.Info("DiscoverSriovDevices(): unsupported device", "device", device)
-				continue
-			}
+	// on IBM Z, the RoCE Express functions are VFs provided by the firmware without their PF
+	zpciUID := ""
+	if vars.Architecture == consts.ArchS390X && !s.dputilsLib.IsSriovPF(device.Address) {
+		zpciUID = getZpciUID(device.Address)
+	}
+
+	driver, err := s.dputilsLib.GetDriverName(device.Address)
+	if err != nil {
+		log.Log.Error(err, "DiscoverSriovDevices(): unable to parse device driver for device, skipping", "device", device)
+		return nil
+	}
+
+	deviceNames, err := s.dputilsLib.GetNetNames(device.Address)
+	if err != nil {
+		log.Log.Error(err, "DiscoverSriovDevices(): unable to get device names for device, skipping", "device", device)
+		return nil
+	}
+
+	if len(deviceNames) == 0 && (zpciUID == "" || !sriovnetworkv1.StringInArray(driver, vars.DpdkDrivers)) {
+		// no network devices found, skipping device
+		return nil
+	}
+
+	if !vars.DevMode {
+		supported := sriovnetworkv1.IsSupportedModel(device.Vendor.ID, device.Product.ID)
+		if zpciUID != "" {
+			supported = sriovnetworkv1.IsVfSupportedModel(device.Vendor.ID, device.Product.ID)
 		}
+		if !supported {
+			log.Log.Info("DiscoverSriovDevices(): unsupported device", "device", device)
+			return nil
+		}
+	}
 
-		iface := sriovnetworkv1.InterfaceExt{
-			PciAddress: device.Address,
-			Driver:     driver,
-			Vendor:     device.Vendor.ID,
-			DeviceID:   device.Product.ID,
+	iface := sriovnetworkv1.InterfaceExt{
+		PciAddress: device.Address,
+		Driver:     driver,
+		Vendor:     device.Vendor.ID,
+		DeviceID:   device.Product.ID,
+	}
+	if mtu := s.networkHelper.GetNetdevMTU(device.Address); mtu > 0 {
+		iface.Mtu = mtu
+	}
+	name := s.networkHelper.TryGetInterfaceName(device.Address)
+	if zpciUID == "" && s.dputilsLib.IsSriovPF(device.Address) {
+		if iface.SplitPorts, err = s.networkHelper.GetSplitPorts(device.Address); err != nil {
+			log.Log.V(2).Info("DiscoverSriovDevices(): unable to get the split ports", "device", device.Address, "error", err)
 		}
-		if mtu := s.networkHelper.GetNetdevMTU(device.Address); mtu > 0 {
-			iface.Mtu = mtu
+		iface.PortSplit = len(iface.SplitPorts)
+		// the PF of a split port has a netdev per port, it is configured through the netdev of its first port
+		if iface.PortSplit > 0 && iface.SplitPorts[0] != "" {
+			name = iface.SplitPorts[0]
 		}
-		if name := s.networkHelper.TryGetInterfaceName(device.Address); name != "" {
-			iface.Name = name
-			iface.Mac = s.networkHelper.GetNetDevMac(name)
-			iface.LinkSpeed = s.networkHelper.GetNetDevLinkSpeed(name)
+	}
+	if name != "" {
+		iface.Name = name
+		iface.Mac = s.networkHelper.GetNetDevMac(name)
+		iface.LinkSpeed = s.networkHelper.GetNetDevLinkSpeed(name)
+		iface.LinkState = s.networkHelper.GetNetDevLinkState(name)
+		iface.DriverVersion = s.networkHelper.GetNetDevDriverVersion(name)
+		iface.FirmwareVersion = s.networkHelper.GetNetDevFirmwareVersion(name)
+		iface.Qos = s.networkHelper.GetNetDevQos(name)
+		iface.CongestionControl = s.congestionHelper.GetCongestionControl(device.Address, name)
+		iface.Ptp = s.networkHelper.GetPtpInfo(name)
+		iface.TransceiverPresent = s.networkHelper.IsTransceiverPresent(name)
+		if iface.TransceiverPresent {
+			if iface.Transceiver, diagnostics[device.Address], err = s.networkHelper.GetTransceiver(name); err != nil {
+				log.Log.V(2).Info("DiscoverSriovDevices(): unable to get transceiver info", "device", device.Address, "error", err)
+			}
 		}
-		iface.LinkType = s.GetLinkType(iface)
+	}
+	iface.OriginalName = originalNames[device.Address]
+	iface.LinkType = s.GetLinkType(iface)
+	iface.PcieLink = getPcieLink(device.Address)
 
-		pfStatus, exist, err := storeManager.LoadPfsStatus(iface.PciAddress)
-		if err != nil {
-			log.Log.Error(err, "DiscoverSriovDevices(): failed to load PF status from disk")
-		} else {
-			if exist {
-				iface.ExternallyManaged = pfStatus.ExternallyManaged
-			}
+	pfStatus, exist, err := storeManager.LoadPfsStatus(iface.PciAddress)
+	if err != nil {
+		log.Log.Error(err, "DiscoverSriovDevices(): failed to load PF status from disk")
+	} else {
+		if exist {
+			iface.ExternallyManaged = pfStatus.ExternallyManaged
 		}
+	}
 
-		if s.dputilsLib.IsSriovPF(device.Address) {
-			iface.TotalVfs = s.dputilsLib.GetSriovVFcapacity(device.Address)
-			iface.NumVfs = s.dputilsLib.GetVFconfigured(device.Address)
-			if iface.EswitchMode, err = s.GetNicSriovMode(device.Address); err != nil {
-				log.Log.Error(err, "DiscoverSriovDevices(): warning, unable to get device eswitch mode",
-					"device", device.Address)
+	if zpciUID != "" {
+		// the function is reported as a PF with a single VF, itself, like the VFs of the virtual platforms
+		iface.ZpciUID = zpciUID
+		iface.TotalVfs = 1
+		iface.NumVfs = 1
+		vf := s.GetVfInfo(device.Address, devices)
+		vf.VfID = 0
+		iface.VFs = append(iface.VFs, vf)
+	} else if s.dputilsLib.IsSriovPF(device.Address) {
+		iface.TotalVfs = s.dputilsLib.GetSriovVFcapacity(device.Address)
+		iface.NumVfs = s.dputilsLib.GetVFconfigured(device.Address)
+		iface.VfTotalMsix = getVfTotalMsix(device.Address)
+		iface.DriversAutoprobeDisabled = driversAutoprobeDisabled(device.Address)
+		if iface.EswitchMode, err = s.GetNicSriovMode(device.Address); err != nil {
+			log.Log.Error(err, "DiscoverSriovDevices(): warning, unable to get device eswitch mode",
+				"device", device.Address)
+		}
+		if s.dputilsLib.SriovConfigured(device.Address) {
+			vfs, err := s.dputilsLib.GetVFList(device.Address)
+			if err != nil {
+				log.Log.Error(err, "DiscoverSriovDevices(): unable to parse VFs for device, skipping",
+					"device", device)
+				return nil
 			}
-			if s.dputilsLib.SriovConfigured(device.Address) {
-				vfs, err := s.dputilsLib.GetVFList(device.Address)
-				if err != nil {
-					log.Log.Error(err, "DiscoverSriovDevices(): unable to parse VFs for device, skipping",
-						"device", device)
-					continue
-				}
-				for _, vf := range vfs {
-					instance := s.GetVfInfo(vf, devices)
-					iface.VFs = append(iface.VFs, instance)
-				}
+			for _, vf := range vfs {
+				instance := s.GetVfInfo(vf, devices)
+				iface.VFs = append(iface.VFs, instance)
 			}
 		}
-		pfList = append(pfList, iface)
 	}
-
-	return pfList, nil
+	return &iface
 }
 
 func (s *sriov) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
 	log.Log.V(2).Info("configSriovDevice(): configure sriov device",
 		"device", iface.PciAddress, "config", iface)
+	if err := guard.Check(iface.PciAddress); err != nil {
+		return err
+	}
+	defer pflock.Lock(iface.PciAddress)()
+	defer metrics.ObservePfConfig(time.Now())
 	var err error
 	if iface.NumVfs > ifaceStatus.TotalVfs {
-		err := fmt.Errorf("cannot config SRIOV device: NumVfs (%d) is larger than TotalVfs (%d)", iface.NumVfs, ifaceStatus.TotalVfs)
+		err := snerrors.Wrap(snerrors.ErrUnsupportedNic,
+			fmt.Errorf("cannot config SRIOV device: NumVfs (%d) is larger than TotalVfs (%d)", iface.NumVfs, ifaceStatus.TotalVfs))
 		log.Log.Error(err, "configSriovDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
 		return err
 	}
-	// set numVFs
-	if iface.NumVfs != ifaceStatus.NumVfs {
+	// set numVFs, the VFs are created again when the drivers autoprobe of the PF changes as the kernel drivers only
+	// probe the VFs when they are created
+	autoprobeChanged := iface.NumVfs > 0 && iface.DisableDriversAutoprobe != ifaceStatus.DriversAutoprobeDisabled
+	if iface.NumVfs != ifaceStatus.NumVfs || (autoprobeChanged && !iface.ExternallyManaged) {
 		if iface.ExternallyManaged {
 			if iface.NumVfs > ifaceStatus.NumVfs {
 				errMsg := fmt.Sprintf("configSriovDevice(): number of request virtual functions %d is not equal to configured virtual functions %d but the policy is configured as ExternallyManaged for device %s", iface.NumVfs, ifaceStatus.NumVfs, iface.PciAddress)
 				log.Log.Error(nil, errMsg)
-				return fmt.Errorf(errMsg)
+				return snerrors.Wrap(snerrors.ErrExternallyManagedMismatch, fmt.Errorf(errMsg))
 			}
 		} else {
 			// create the udev rule to disable all the vfs from network manager as this vfs are managed by the operator
@@ -315,7 +510,12 @@ func (s *sriov) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *
 				return err
 			}
 
-			err = s.SetSriovNumVfs(iface.PciAddress, iface.NumVfs)
+			// the kernel drivers probe the VFs when they are created, unless the PF disables it
+			if err := setDriversAutoprobe(iface.PciAddress, !iface.DisableDriversAutoprobe); err != nil {
+				return err
+			}
+
+			err = s.setSriovNumVfs(iface.PciAddress, iface.NumVfs)
 			if err != nil {
 				log.Log.Error(err, "configSriovDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
 				errRemove := s.udevHelper.RemoveUdevRule(iface.PciAddress)
@@ -328,9 +528,16 @@ func (s *sriov) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *
 	}
 	// set PF mtu
 	if iface.Mtu > 0 && iface.Mtu > ifaceStatus.Mtu {
+		if vars.NMStateIntegration {
+			// the MTU of the PF is set by kubernetes-nmstate, the VFs are configured once it is applied
+			if err := waitNMStateMtu(iface, ifaceStatus); err != nil {
+				return err
+			}
+		}
 		if iface.ExternallyManaged {
-			err := fmt.Errorf("ConfigSriovDevice(): requested MTU(%d) is greater than configured MTU(%d) for device %s. cannot change MTU as policy is configured as ExternallyManaged",
-				iface.Mtu, ifaceStatus.Mtu, iface.PciAddress)
+			err := snerrors.Wrap(snerrors.ErrExternallyManagedMismatch, fmt.Errorf(
+				"ConfigSriovDevice(): requested MTU(%d) is greater than configured MTU(%d) for device %s. cannot change MTU as policy is configured as ExternallyManaged",
+				iface.Mtu, ifaceStatus.Mtu, iface.PciAddress))
 			log.Log.Error(nil, err.Error())
 			return err
 		}
@@ -339,6 +546,10 @@ func (s *sriov) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *
 			log.Log.Error(err, "configSriovDevice(): fail to set mtu for PF", "device", iface.PciAddress)
 			return err
 		}
+	} else if vars.NMStateIntegration {
+		if err := nmstateMtuSet(iface.PciAddress); err != nil {
+			return err
+		}
 	}
 	// Config VFs
 	if iface.NumVfs > 0 {
@@ -352,131 +563,262 @@ func (s *sriov) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *
 			return err
 		}
 
+		// the quirks of the driver of the PF skip steps of the configuration of its VFs
+		skipped := quirks.For(ifaceStatus.Driver, func() string {
+			return s.networkHelper.GetNetDevFirmwareVersion(ifaceStatus.Name)
+		})
+		if len(skipped) > 0 {
+			log.Log.Info("configSriovDevice(): driver quirks skip steps of the configuration of the VFs",
+				"device", iface.PciAddress, "driver", ifaceStatus.Driver, "skipped", skipped)
+		}
+
+		// the VFs are independent from each other, configure them concurrently to keep
+		// the reconfiguration of PFs with hundreds of VFs short
+		g := errgroup.Group{}
+		g.SetLimit(vars.VfConfigConcurrency)
 		for _, addr := range vfAddrs {
-			var group *sriovnetworkv1.VfGroup
+			addr := addr
+			g.Go(func() error {
+				return s.configSriovVF(addr, iface, ifaceStatus, pfLink, skipped)
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+	// Set PF link up
+	pfLink, err := s.netlinkLib.LinkByName(ifaceStatus.Name)
+	if err != nil {
+		return err
+	}
+	if pfLink.Attrs().OperState != netlink.OperUp {
+		err = s.netlinkLib.LinkSetUp(pfLink)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			vfID, err := s.dputilsLib.GetVFID(addr)
-			if err != nil {
-				log.Log.Error(err, "configSriovDevice(): unable to get VF id", "device", iface.PciAddress)
-				return err
-			}
+// configSriovVF configures a single VF of the PF according to the VF group it belongs to, the skipped steps are left
+// out
+func (s *sriov) configSriovVF(addr string, iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt,
+	pfLink netlink.Link, skipped quirks.Skipped) error {
+	var group *sriovnetworkv1.VfGroup
 
-			for i := range iface.VfGroups {
-				if sriovnetworkv1.IndexInRange(vfID, iface.VfGroups[i].VfRange) {
-					group = &iface.VfGroups[i]
-					break
-				}
-			}
+	vfID, err := s.dputilsLib.GetVFID(addr)
+	if err != nil {
+		log.Log.Error(err, "configSriovVF(): unable to get VF id", "device", iface.PciAddress)
+		return err
+	}
 
-			// VF group not found.
-			if group == nil {
-				continue
-			}
+	for i := range iface.VfGroups {
+		if sriovnetworkv1.IndexInRange(vfID, iface.VfGroups[i].VfRange) {
+			group = &iface.VfGroups[i]
+			break
+		}
+	}
 
-			// only set GUID and MAC for VF with default driver
-			// for userspace drivers like vfio we configure the vf mac using the kernel nic mac address
-			// before we switch to the userspace driver
-			if yes, d := s.kernelHelper.HasDriver(addr); yes && !sriovnetworkv1.StringInArray(d, vars.DpdkDrivers) {
-				// LinkType is an optional field. Let's fallback to current link type
-				// if nothing is specified in the SriovNodePolicy
-				linkType := iface.LinkType
-				if linkType == "" {
-					linkType = ifaceStatus.LinkType
-				}
-				if strings.EqualFold(linkType, consts.LinkTypeIB) {
-					if err = s.SetVfGUID(addr, pfLink); err != nil {
-						return err
-					}
-				} else {
-					vfLink, err := s.VFIsReady(addr)
-					if err != nil {
-						log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
-						err = s.kernelHelper.RebindVfToDefaultDriver(addr)
-						if err != nil {
-							log.Log.Error(err, "configSriovDevice(): failed to rebind VF", "address", addr)
-							return err
-						}
+	// VF group not found.
+	if group == nil {
+		return nil
+	}
 
-						// Try to check the VF status again
-						vfLink, err = s.VFIsReady(addr)
-						if err != nil {
-							log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
-							return err
-						}
-					}
-					if err = s.SetVfAdminMac(addr, pfLink, vfLink); err != nil {
-						log.Log.Error(err, "configSriovDevice(): fail to configure VF admin mac", "device", addr)
-						return err
-					}
+	// LinkType is an optional field. Let's fallback to current link type
+	// if nothing is specified in the SriovNodePolicy
+	linkType := iface.LinkType
+	if linkType == "" {
+		linkType = ifaceStatus.LinkType
+	}
+	// only set GUID and MAC for VF with default driver
+	// for userspace drivers like vfio we configure the vf mac using the kernel nic mac address
+	// before we switch to the userspace driver
+	yes, d := s.kernelHelper.HasDriver(addr)
+	if !yes && iface.DisableDriversAutoprobe {
+		// the kernel drivers didn't probe the VF, it has no kernel netdev to take the MAC address from
+		if strings.EqualFold(linkType, consts.LinkTypeIB) {
+			if !skipped.Has(sriovnetworkv1.VfConfigStepGUID) {
+				if err = s.SetVfGUID(addr, pfLink); err != nil {
+					return err
 				}
 			}
-
-			if err = s.kernelHelper.UnbindDriverIfNeeded(addr, group.IsRdma); err != nil {
+		} else if !skipped.Has(sriovnetworkv1.VfConfigStepAdminMac) {
+			if err = s.setUnprobedVfAdminMac(addr, pfLink); err != nil {
+				log.Log.Error(err, "configSriovVF(): fail to configure VF admin mac", "device", addr)
 				return err
 			}
-
-			if !sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) {
-				if err := s.kernelHelper.BindDefaultDriver(addr); err != nil {
-					log.Log.Error(err, "configSriovDevice(): fail to bind default driver for device", "device", addr)
+		}
+	} else if yes && !sriovnetworkv1.StringInArray(d, vars.DpdkDrivers) {
+		if strings.EqualFold(linkType, consts.LinkTypeIB) {
+			if !skipped.Has(sriovnetworkv1.VfConfigStepGUID) {
+				if err = s.SetVfGUID(addr, pfLink); err != nil {
 					return err
 				}
-				// only set MTU for VF with default driver
-				if group.Mtu > 0 {
-					if err := s.networkHelper.SetNetdevMTU(addr, group.Mtu); err != nil {
-						log.Log.Error(err, "configSriovDevice(): fail to set mtu for VF", "address", addr)
-						return err
-					}
+			}
+		} else if !skipped.Has(sriovnetworkv1.VfConfigStepAdminMac) {
+			vfLink, err := s.VFIsReady(addr)
+			if err != nil {
+				log.Log.Error(err, "configSriovVF(): VF link is not ready", "address", addr)
+				if skipped.Has(sriovnetworkv1.VfConfigStepRebind) {
+					return err
 				}
-			} else {
-				if err := s.kernelHelper.BindDpdkDriver(addr, group.DeviceType); err != nil {
-					log.Log.Error(err, "configSriovDevice(): fail to bind driver for device",
-						"driver", group.DeviceType, "device", addr)
+				err = s.kernelHelper.RebindVfToDefaultDriver(addr)
+				if err != nil {
+					log.Log.Error(err, "configSriovVF(): failed to rebind VF", "address", addr)
+					return err
+				}
+
+				// Try to check the VF status again
+				vfLink, err = s.VFIsReady(addr)
+				if err != nil {
+					log.Log.Error(err, "configSriovVF(): VF link is not ready", "address", addr)
 					return err
 				}
 			}
+			if err = s.SetVfAdminMac(addr, pfLink, vfLink); err != nil {
+				log.Log.Error(err, "configSriovVF(): fail to configure VF admin mac", "device", addr)
+				return err
+			}
 		}
 	}
-	// Set PF link up
-	pfLink, err := s.netlinkLib.LinkByName(ifaceStatus.Name)
-	if err != nil {
+
+	if err = s.kernelHelper.UnbindDriverIfNeeded(addr, group.IsRdma); err != nil {
 		return err
 	}
-	if pfLink.Attrs().OperState != netlink.OperUp {
-		err = s.netlinkLib.LinkSetUp(pfLink)
-		if err != nil {
+	if group.MsixCount > 0 && !skipped.Has(sriovnetworkv1.VfConfigStepMsixCount) {
+		if err = s.setVfMsixCount(addr, group.MsixCount); err != nil {
+			log.Log.Error(err, "configSriovVF(): fail to set the MSI-X vectors of VF", "address", addr)
+			return err
+		}
+	}
+
+	if !sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) {
+		if err := s.kernelHelper.BindDefaultDriver(addr); err != nil {
+			log.Log.Error(err, "configSriovVF(): fail to bind default driver for device", "device", addr)
+			return err
+		}
+		// only set MTU for VF with default driver
+		if group.Mtu > 0 && !skipped.Has(sriovnetworkv1.VfConfigStepMtu) {
+			if err := s.networkHelper.SetNetdevMTU(addr, group.Mtu); err != nil {
+				log.Log.Error(err, "configSriovVF(): fail to set mtu for VF", "address", addr)
+				return err
+			}
+		}
+		if group.HwTimestamping && !skipped.Has(sriovnetworkv1.VfConfigStepHwTimestamping) {
+			if err := s.enableVfHwTimestamping(addr); err != nil {
+				log.Log.Error(err, "configSriovVF(): fail to enable hardware timestamping for VF", "address", addr)
+				return err
+			}
+		}
+	} else {
+		if err := s.kernelHelper.BindDpdkDriver(addr, group.DeviceType); err != nil {
+			log.Log.Error(err, "configSriovVF(): fail to bind driver for device",
+				"driver", group.DeviceType, "device", addr)
 			return err
 		}
 	}
 	return nil
 }
 
+// enableVfHwTimestamping enables the hardware timestamping of the VF, the VFs whose driver doesn't support it are
+// skipped and reported without hwTimestamping in the node state
+func (s *sriov) enableVfHwTimestamping(addr string) error {
+	name := s.networkHelper.TryGetInterfaceName(addr)
+	if name == "" {
+		return fmt.Errorf("failed to get the interface name of VF %s", addr)
+	}
+	switch s.networkHelper.GetNetDevHwTimestamping(name) {
+	case consts.HwTimestampingEnabled:
+		return nil
+	case consts.HwTimestampingSupported:
+		return s.networkHelper.EnableNetDevHwTimestamping(name)
+	default:
+		log.Log.Info("enableVfHwTimestamping(): the VF doesn't support hardware timestamping, skipping", "address", addr)
+		return nil
+	}
+}
+
 func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 	interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, pfsToConfig map[string]bool) error {
 	if s.kernelHelper.IsKernelLockdownMode() && mlx.HasMellanoxInterfacesInSpec(ifaceStatuses, interfaces) {
 		log.Log.Error(nil, "cannot use mellanox devices when in kernel lockdown mode")
-		return fmt.Errorf("cannot use mellanox devices when in kernel lockdown mode")
+		return snerrors.Wrap(snerrors.ErrKernelLockdown, fmt.Errorf("cannot use mellanox devices when in kernel lockdown mode"))
+	}
+
+	// the PFs that are the PTP time source of the node are left as they are before any change, their port split
+	// included, the error is returned once the other PFs are configured
+	skipped, ptpErr := ptpSourcesRefused(interfaces, ifaceStatuses)
+	for pciAddress, skip := range pfsToConfig {
+		skipped[pciAddress] = skipped[pciAddress] || skip
+	}
+
+	// the ports are split before the VFs are created, the PFs are discovered again once split as their netdevs changed
+	split, err := s.splitPorts(interfaces, ifaceStatuses, skipped)
+	if err != nil {
+		return err
+	}
+	if len(split) > 0 {
+		if ifaceStatuses, err = s.DiscoverSriovDevices(storeManager); err != nil {
+			return err
+		}
+		interfaces = splitPortNames(interfaces, ifaceStatuses)
+	}
+	if err := syncPendingSplits(split, interfaces, ifaceStatuses); err != nil {
+		return err
 	}
 
+	// the PFs that the PCI address guard of the node doesn't allow to change are skipped, the error is returned once
+	// the other PFs are configured. The guard is only checked for the PFs that change. The PFs claimed by another host
+	// network manager are skipped without error, the conflict is reported in the conditions of the node state.
+	var guardErr error
+	skipGuarded := func(address string, err error) bool {
+		if errors.Is(err, snerrors.ErrInterfaceClaimed) {
+			log.Log.Info("SyncNodeState(): skipping the configuration of the interface", "address", address, "reason", err.Error())
+			return true
+		}
+		if errors.Is(err, snerrors.ErrPciAddressGuarded) {
+			guardErr = err
+			log.Log.Error(err, "SyncNodeState(): skipping the configuration of the interface", "address", address)
+			return true
+		}
+		return false
+	}
 	for _, ifaceStatus := range ifaceStatuses {
 		configured := false
 		for _, iface := range interfaces {
 			if iface.PciAddress == ifaceStatus.PciAddress {
 				configured = true
 
-				if skip := pfsToConfig[iface.PciAddress]; skip {
+				if skip := skipped[iface.PciAddress]; skip {
 					break
 				}
 
 				if !sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) {
 					log.Log.V(2).Info("syncNodeState(): no need update interface", "address", iface.PciAddress)
 
-					// Save the PF status to the host
-					err := storeManager.SaveLastPfAppliedStatus(&iface)
-					if err != nil {
+					// the features of the PF check the guard once they change it
+					if err := s.savePfAppliedStatus(storeManager, &iface, &ifaceStatus); err != nil {
+						if skipGuarded(iface.PciAddress, err) {
+							break
+						}
+						return err
+					}
+					break
+				}
+				if err := guard.Check(iface.PciAddress); err != nil {
+					skipGuarded(iface.PciAddress, err)
+					break
+				}
+				if ifaceStatus.ZpciUID != "" {
+					// only the driver of a zPCI function can be configured
+					if err := s.ConfigSriovDeviceVirtual(&iface); err != nil {
+						log.Log.Error(err, "SyncNodeState(): fail to configure zPCI function", "address", iface.PciAddress)
+						return err
+					}
+					if err := storeManager.SaveLastPfAppliedStatus(&iface); err != nil {
 						log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
 						return err
 					}
-
 					break
 				}
 				if err := s.ConfigSriovDevice(&iface, &ifaceStatus); err != nil {
@@ -491,17 +833,28 @@ func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 					return err
 				}
 
-				// Save the PF status to the host
-				err := storeManager.SaveLastPfAppliedStatus(&iface)
-				if err != nil {
-					log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
+				if err := s.savePfAppliedStatus(storeManager, &iface, &ifaceStatus); err != nil {
 					return err
 				}
 				break
 			}
 		}
 		if !configured && ifaceStatus.NumVfs > 0 {
-			if skip := pfsToConfig[ifaceStatus.PciAddress]; skip {
+			if skip := skipped[ifaceStatus.PciAddress]; skip {
+				continue
+			}
+			if err := guard.Check(ifaceStatus.PciAddress); err != nil {
+				log.Log.Info("SyncNodeState(): skipping the device reset", "address", ifaceStatus.PciAddress, "reason", err.Error())
+				continue
+			}
+
+			if ifaceStatus.ZpciUID != "" {
+				// a zPCI function can't be reset, it is only given back to its default driver
+				if sriovnetworkv1.StringInArray(ifaceStatus.Driver, vars.DpdkDrivers) {
+					if err := s.kernelHelper.BindDefaultDriver(ifaceStatus.PciAddress); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 
@@ -525,11 +878,25 @@ func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 					"pf-name", ifaceStatus.Name,
 					"address", ifaceStatus.PciAddress)
 				continue
-			} else {
-				err = s.udevHelper.RemoveUdevRule(ifaceStatus.PciAddress)
-				if err != nil {
-					return err
+			}
+			// the reset removes the VFs of the PF, no policy is left to allow the disruption of the time source
+			if ifaceStatus.Ptp != nil && ifaceStatus.Ptp.TimeSource {
+				ptpErr = snerrors.Wrap(snerrors.ErrPtpTimeSource, fmt.Errorf("%s is the PTP time source of the node, "+
+					"its VFs are kept until it no longer is", ifaceStatus.Name))
+				log.Log.Error(ptpErr, "SyncNodeState(): skipping the device reset", "address", ifaceStatus.PciAddress)
+				continue
+			}
+			if err = s.udevHelper.RemoveUdevRule(ifaceStatus.PciAddress); err != nil {
+				return err
+			}
+
+			if len(pfStatus.FlowRules) > 0 {
+				// the representors go away with the VFs, only the rules of the PF are left behind
+				unlock := pflock.Lock(ifaceStatus.PciAddress)
+				if err := s.networkHelper.SyncFlowRules(ifaceStatus.Name, nil); err != nil {
+					log.Log.Error(err, "SyncNodeState(): failed to remove the flow rules", "name", ifaceStatus.Name)
 				}
+				unlock()
 			}
 
 			if err = s.ResetSriovDevice(ifaceStatus); err != nil {
@@ -537,11 +904,395 @@ func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 			}
 		}
 	}
+	if guardErr != nil {
+		return guardErr
+	}
+	return ptpErr
+}
+
+// ptpSourcesRefused returns the PFs of the spec that are the PTP time source of the node and whose change no policy
+// allows, with the error reporting them
+func ptpSourcesRefused(interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt) (map[string]bool, error) {
+	refused := map[string]bool{}
+	var ptpErr error
+	for i := range interfaces {
+		iface := &interfaces[i]
+		ifaceStatus := findInterfaceStatus(ifaceStatuses, iface.PciAddress)
+		if ifaceStatus == nil || !sriovnetworkv1.PtpSourceRefused(iface, ifaceStatus) {
+			continue
+		}
+		refused[iface.PciAddress] = true
+		ptpErr = snerrors.Wrap(snerrors.ErrPtpTimeSource, fmt.Errorf("%s is the PTP time source of the node, set "+
+			"allowPtpSourceDisruption in its policies to allow the change of its numVfs, eSwitchMode, linkType or portSplit", iface.Name))
+		log.Log.Error(ptpErr, "SyncNodeState(): skipping the configuration of the interface", "address", iface.PciAddress)
+	}
+	return refused, ptpErr
+}
+
+// splitPorts splits the physical ports of the PFs whose port split differs from the spec, the VFs of the PFs are
+// removed first. It returns the PCI addresses of the PFs whose port was split or unsplit.
+func (s *sriov) splitPorts(interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt,
+	pfsToConfig map[string]bool) ([]string, error) {
+	split := []string{}
+	for _, iface := range interfaces {
+		if iface.PortSplit == 0 || pfsToConfig[iface.PciAddress] {
+			continue
+		}
+		for _, ifaceStatus := range ifaceStatuses {
+			if ifaceStatus.PciAddress != iface.PciAddress || iface.PortSplit == max(ifaceStatus.PortSplit, 1) {
+				continue
+			}
+			// the guarded PFs are reported by their configuration
+			if err := guard.Check(iface.PciAddress); err != nil {
+				break
+			}
+			log.Log.Info("splitPorts(): split the port of the PF", "address", iface.PciAddress,
+				"count", iface.PortSplit, "current", ifaceStatus.PortSplit)
+			if err := s.splitPort(iface.PciAddress, iface.PortSplit, ifaceStatus.NumVfs); err != nil {
+				log.Log.Error(err, "splitPorts(): failed to split the port of the PF", "address", iface.PciAddress)
+				return split, err
+			}
+			split = append(split, iface.PciAddress)
+			break
+		}
+	}
+	return split, nil
+}
+
+// syncPendingSplits records the splits of the PFs the NIC doesn't report once split, the NIC applies them at the
+// next boot, and drops the splits reported since or no longer in the spec
+func syncPendingSplits(split []string, interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt) error {
+	pending, err := portsplit.Load()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for pciAddress, p := range pending {
+		iface := findInterface(interfaces, pciAddress)
+		ifaceStatus := findInterfaceStatus(ifaceStatuses, pciAddress)
+		if iface == nil || iface.PortSplit != p.PortSplit || (ifaceStatus != nil && p.PortSplit == max(ifaceStatus.PortSplit, 1)) {
+			delete(pending, pciAddress)
+			changed = true
+		}
+	}
+	for _, pciAddress := range split {
+		iface := findInterface(interfaces, pciAddress)
+		ifaceStatus := findInterfaceStatus(ifaceStatuses, pciAddress)
+		if iface == nil || ifaceStatus == nil || iface.PortSplit == max(ifaceStatus.PortSplit, 1) {
+			continue
+		}
+		if _, found := pending[pciAddress]; found {
+			continue
+		}
+		bootID, err := portsplit.BootID()
+		if err != nil {
+			return err
+		}
+		log.Log.Info("syncPendingSplits(): the split of the port isn't reported, the NIC applies it at the next boot",
+			"address", pciAddress, "count", iface.PortSplit)
+		pending[pciAddress] = portsplit.Pending{PortSplit: iface.PortSplit, BootID: bootID}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return portsplit.Save(pending)
+}
+
+func findInterface(interfaces []sriovnetworkv1.Interface, pciAddress string) *sriovnetworkv1.Interface {
+	for i := range interfaces {
+		if interfaces[i].PciAddress == pciAddress {
+			return &interfaces[i]
+		}
+	}
+	return nil
+}
+
+func findInterfaceStatus(ifaceStatuses []sriovnetworkv1.InterfaceExt, pciAddress string) *sriovnetworkv1.InterfaceExt {
+	for i := range ifaceStatuses {
+		if ifaceStatuses[i].PciAddress == pciAddress {
+			return &ifaceStatuses[i]
+		}
+	}
+	return nil
+}
+
+// splitPort removes the VFs of the PF and splits its physical port into count ports
+func (s *sriov) splitPort(pciAddr string, count, numVfs int) error {
+	defer pflock.Lock(pciAddr)()
+	if numVfs > 0 {
+		if err := s.setSriovNumVfs(pciAddr, 0); err != nil {
+			return err
+		}
+	}
+	return s.networkHelper.SetPortSplit(pciAddr, count)
+}
+
+// splitPortNames returns the spec with the names the split PFs were discovered with, the netdevs of the split ports
+// are created with new names
+func splitPortNames(interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt) []sriovnetworkv1.Interface {
+	renamed := make([]sriovnetworkv1.Interface, len(interfaces))
+	copy(renamed, interfaces)
+	for i := range renamed {
+		if renamed[i].PortSplit == 0 {
+			continue
+		}
+		for _, ifaceStatus := range ifaceStatuses {
+			if ifaceStatus.PciAddress != renamed[i].PciAddress {
+				continue
+			}
+			if ifaceStatus.Name != "" {
+				renamed[i].Name = ifaceStatus.Name
+			}
+			break
+		}
+	}
+	return renamed
+}
+
+// savePfAppliedStatus saves the spec applied to the PF to the host and installs the features of the PF, the spec
+// applied before tells the address sharing exceptions to revert
+func (s *sriov) savePfAppliedStatus(storeManager store.ManagerInterface,
+	iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	applied, _, err := storeManager.LoadPfsStatus(iface.PciAddress)
+	if err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to load the PF applied config from host", "address", iface.PciAddress)
+		return err
+	}
+	// Save the PF status to the host
+	if err := storeManager.SaveLastPfAppliedStatus(iface); err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
+		return err
+	}
+	return s.syncPfFeatures(iface, ifaceStatus, applied)
+}
+
+// syncPfFeatures installs the flow rules, the address sharing exceptions of the VFs, the QoS and the congestion
+// control of the PF
+func (s *sriov) syncPfFeatures(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt,
+	applied *sriovnetworkv1.Interface) error {
+	defer pflock.Lock(iface.PciAddress)()
+	if err := s.syncFlowRules(iface); err != nil {
+		return err
+	}
+	if err := s.syncAddressSharing(iface, applied); err != nil {
+		return err
+	}
+	if err := s.syncUnicastMacs(iface); err != nil {
+		return err
+	}
+	if err := s.syncQos(iface, ifaceStatus); err != nil {
+		return err
+	}
+	return s.syncCongestionControl(iface, ifaceStatus)
+}
+
+// syncFlowRules installs the flow rules of the PF and of its representors and the policing of the VFs on their
+// representors, the rules and the policing require the switchdev mode
+func (s *sriov) syncFlowRules(iface *sriovnetworkv1.Interface) error {
+	if iface.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return nil
+	}
+	if err := s.networkHelper.SyncFlowRules(iface.Name, iface.FlowRules); err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to sync the flow rules", "name", iface.Name)
+		return err
+	}
+	if err := s.networkHelper.SyncPolicing(iface.Name, iface.VfPolicing()); err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to sync the policing of the VFs", "name", iface.Name)
+		return err
+	}
+	return nil
+}
+
+// syncAddressSharing disables the spoof check and enables the trust mode of the VFs of the resources with address
+// sharing exceptions. They are applied at each sync as the CNI resets them when the pods release the VFs, the VFs
+// whose driver doesn't support a setting are skipped. The exceptions of the spec applied before and no longer
+// requested are reverted.
+func (s *sriov) syncAddressSharing(iface *sriovnetworkv1.Interface, applied *sriovnetworkv1.Interface) error {
+	sharing := iface.VfAddressSharing()
+	previous := map[int]*sriovnetworkv1.AddressSharing{}
+	if applied != nil {
+		previous = applied.VfAddressSharing()
+	}
+	if len(sharing) == 0 && len(previous) == 0 {
+		return nil
+	}
+	if err := guard.Check(iface.PciAddress); err != nil {
+		return err
+	}
+	pfLink, err := s.netlinkLib.LinkByName(iface.Name)
+	if err != nil {
+		log.Log.Error(err, "syncAddressSharing(): unable to get PF link for device", "name", iface.Name)
+		return err
+	}
+	for vfID := 0; vfID < iface.NumVfs; vfID++ {
+		exceptions, was := sharing[vfID], previous[vfID]
+		if exceptions == nil {
+			exceptions = &sriovnetworkv1.AddressSharing{}
+		}
+		if was == nil {
+			was = &sriovnetworkv1.AddressSharing{}
+		}
+		if exceptions.AllowSpoofing || was.AllowSpoofing {
+			if err := s.netlinkLib.LinkSetVfSpoofchk(pfLink, vfID, !exceptions.AllowSpoofing); err != nil {
+				if !errors.Is(err, syscall.EOPNOTSUPP) {
+					log.Log.Error(err, "syncAddressSharing(): failed to set the spoof check of VF", "name", iface.Name, "vf", vfID)
+					return err
+				}
+				log.Log.Info("syncAddressSharing(): the driver doesn't support the spoof check of VF, skipping",
+					"name", iface.Name, "vf", vfID)
+			}
+		}
+		if exceptions.MultipleUnicastMacs || was.MultipleUnicastMacs {
+			if err := s.netlinkLib.LinkSetVfTrust(pfLink, vfID, exceptions.MultipleUnicastMacs); err != nil {
+				if !errors.Is(err, syscall.EOPNOTSUPP) {
+					log.Log.Error(err, "syncAddressSharing(): failed to set the trust mode of VF", "name", iface.Name, "vf", vfID)
+					return err
+				}
+				log.Log.Info("syncAddressSharing(): the driver doesn't support the trust mode of VF, skipping",
+					"name", iface.Name, "vf", vfID)
+			}
+		}
+	}
+	return nil
+}
+
+// SyncUnicastMacs programs the additional unicast MACs of the VFs of the PF, the kernel flushes them when a VF
+// moves to the network namespace of a pod and back, the daemon programs them again periodically
+func (s *sriov) SyncUnicastMacs(iface *sriovnetworkv1.Interface) error {
+	defer pflock.Lock(iface.PciAddress)()
+	return s.syncUnicastMacs(iface)
+}
+
+// syncUnicastMacs programs the additional unicast MACs of the VFs on their netdevs, in the network namespace each
+// netdev is in, and removes the MACs the operator added to the VFs no longer listed
+func (s *sriov) syncUnicastMacs(iface *sriovnetworkv1.Interface) error {
+	macs := iface.UnicastMacsByVf()
+	vfAddrs, err := s.dputilsLib.GetVFList(iface.PciAddress)
+	if err != nil {
+		log.Log.Error(err, "syncUnicastMacs(): failed to get the VFs", "device", iface.PciAddress)
+		return err
+	}
+	for _, addr := range vfAddrs {
+		vfID, err := s.dputilsLib.GetVFID(addr)
+		if err != nil {
+			log.Log.Error(err, "syncUnicastMacs(): failed to get VF ID", "device", addr)
+			return err
+		}
+		if err := s.networkHelper.SyncVfUnicastMacs(addr, macs[vfID]); err != nil {
+			log.Log.Error(err, "syncUnicastMacs(): failed to sync the unicast MACs of VF", "device", addr)
+			return err
+		}
+	}
+	return nil
+}
+
+// syncQos applies the trust mode, the priority flow control and the receive buffers requested for the PF when
+// they differ from the ones reported by its driver
+func (s *sriov) syncQos(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	if iface.Qos == nil || !sriovnetworkv1.QosNeedsUpdate(iface.Qos, ifaceStatus.Qos) {
+		return nil
+	}
+	if err := s.networkHelper.SetNetDevQos(iface.Name, iface.Qos); err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to configure the QoS", "name", iface.Name)
+		return err
+	}
+	return nil
+}
+
+// syncCongestionControl applies the ECN and DCQCN settings requested for the PF when they differ from the ones
+// exposed by its driver
+func (s *sriov) syncCongestionControl(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	if iface.CongestionControl == nil ||
+		!sriovnetworkv1.CongestionControlNeedsUpdate(iface.CongestionControl, ifaceStatus.CongestionControl) {
+		return nil
+	}
+	if err := s.congestionHelper.SetCongestionControl(iface.PciAddress, iface.Name, iface.CongestionControl); err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to configure the congestion control", "name", iface.Name)
+		return err
+	}
+	return nil
+}
+
+// getZpciUID returns the UID of the zPCI function, e.g. 0x1f, or an empty string if the device is not a zPCI function
+func getZpciUID(pciAddr string) string {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "uid"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// PCI configuration space offsets of the capability list and of the MSI-X capability
+const (
+	pciStatus          = 0x06
+	pciStatusCapList   = 0x10
+	pciCapabilityList  = 0x34
+	pciCapIDMsix       = 0x11
+	pciMsixFlags       = 2
+	pciMsixFlagsQsize  = 0x07ff
+	pciConfigHeaderLen = 0x40
+)
+
+// getVfTotalMsix returns the number of MSI-X vectors the PF can distribute among its VFs, or 0 if the driver
+// doesn't support the configuration of the MSI-X vectors of the VFs
+func getVfTotalMsix(pfAddr string) int {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pfAddr, "sriov_vf_total_msix"))
+	if err != nil {
+		return 0
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		log.Log.V(2).Info("getVfTotalMsix(): invalid sriov_vf_total_msix", "device", pfAddr, "error", err)
+		return 0
+	}
+	return total
+}
+
+// getVfMsixCount returns the size of the MSI-X table of the VF read from its PCI configuration space, the
+// sriov_vf_msix_count file being write only, or 0 if the VF has no MSI-X capability
+func getVfMsixCount(vfAddr string) int {
+	config, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, vfAddr, "config"))
+	if err != nil || len(config) < pciConfigHeaderLen || config[pciStatus]&pciStatusCapList == 0 {
+		return 0
+	}
+	// the capabilities are dword aligned, the loop is bounded in case of a corrupted list
+	pos := int(config[pciCapabilityList]) &^ 3
+	for i := 0; i < 48 && pos >= pciConfigHeaderLen && pos+pciMsixFlags+2 <= len(config); i++ {
+		if config[pos] == pciCapIDMsix {
+			return int(binary.LittleEndian.Uint16(config[pos+pciMsixFlags:])&pciMsixFlagsQsize) + 1
+		}
+		pos = int(config[pos+1]) &^ 3
+	}
+	return 0
+}
+
+// setVfMsixCount sets the number of MSI-X vectors of the VF, the kernel only allows it while the VF is not bound
+// to a driver so the VF is unbound and must be bound again by the caller
+func (s *sriov) setVfMsixCount(vfAddr string, count int) error {
+	if current := getVfMsixCount(vfAddr); current == count {
+		return nil
+	}
+	log.Log.V(2).Info("setVfMsixCount(): set the MSI-X vectors of the VF", "device", vfAddr, "count", count)
+	if err := guard.Check(vfAddr); err != nil {
+		return err
+	}
+	if err := s.kernelHelper.Unbind(vfAddr); err != nil {
+		return err
+	}
+	path := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, vfAddr, "sriov_vf_msix_count")
+	if err := utils.WriteHostFile(path, []byte(strconv.Itoa(count)), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to set the MSI-X vectors of VF %s: %v", vfAddr, err)
+	}
 	return nil
 }
 
 func (s *sriov) ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error {
 	log.Log.V(2).Info("ConfigSriovDeviceVirtual(): config interface", "address", iface.PciAddress, "config", iface)
+	if err := guard.Check(iface.PciAddress); err != nil {
+		return err
+	}
+	defer pflock.Lock(iface.PciAddress)()
 	// Config VFs
 	if iface.NumVfs > 0 {
 		if iface.NumVfs > 1 {
@@ -603,6 +1354,10 @@ func (s *sriov) GetNicSriovMode(pciAddress string) (string, error) {
 
 func (s *sriov) SetNicSriovMode(pciAddress string, mode string) error {
 	log.Log.V(2).Info("SetNicSriovMode()", "device", pciAddress, "mode", mode)
+	if err := guard.Check(pciAddress); err != nil {
+		return err
+	}
+	defer pflock.Lock(pciAddress)()
 
 	dev, err := s.netlinkLib.DevLinkGetDeviceByName("pci", pciAddress)
 	if err != nil {
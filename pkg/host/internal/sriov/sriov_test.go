@@ -1,24 +1,49 @@
 package sriov
 
 import (
+	"encoding/binary"
 	"fmt"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/pcidb"
 	"github.com/vishvananda/netlink"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	dputilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils/mock"
 	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/pflock"
 	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
+	storeMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/portsplit"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/quirks"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
 
+// pciConfig returns a PCI configuration space with an MSI capability followed by an MSI-X capability
+// of tableSize vectors
+func pciConfig(tableSize int) []byte {
+	config := make([]byte, 256)
+	config[pciStatus] = pciStatusCapList
+	config[pciCapabilityList] = 0x40
+	config[0x40], config[0x41] = 0x05, 0x50
+	config[0x50] = pciCapIDMsix
+	binary.LittleEndian.PutUint16(config[0x50+pciMsixFlags:], uint16(tableSize-1)|0x8000)
+	return config
+}
+
 var _ = Describe("SRIOV", func() {
 	var (
 		s              types.SriovInterface
@@ -35,7 +60,7 @@ var _ = Describe("SRIOV", func() {
 		netlinkLibMock = netlinkMockPkg.NewMockNetlinkLib(testCtrl)
 		dputilsLibMock = dputilsMockPkg.NewMockDPUtilsLib(testCtrl)
 		hostMock = hostMockPkg.NewMockHostManagerInterface(testCtrl)
-		s = New(nil, hostMock, hostMock, hostMock, netlinkLibMock, dputilsLibMock)
+		s = New(nil, hostMock, hostMock, hostMock, hostMock, hostMock, netlinkLibMock, dputilsLibMock)
 	})
 
 	AfterEach(func() {
@@ -48,12 +73,31 @@ var _ = Describe("SRIOV", func() {
 				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
 				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
 			})
+			hostMock.EXPECT().InvalidateInventory()
 			Expect(s.SetSriovNumVfs("0000:d8:00.0", 5)).NotTo(HaveOccurred())
 			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", strconv.Itoa(5))
 		})
 		It("fail - no such device", func() {
+			hostMock.EXPECT().InvalidateInventory()
 			Expect(s.SetSriovNumVfs("0000:d8:00.0", 5)).To(HaveOccurred())
 		})
+		It("waits for the other operations on the PF", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+			hostMock.EXPECT().InvalidateInventory()
+			unlock := pflock.Lock("0000:d8:00.0")
+			done := make(chan error)
+			go func() {
+				done <- s.SetSriovNumVfs("0000:d8:00.0", 5)
+			}()
+			Consistently(done, 100*time.Millisecond).ShouldNot(Receive())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "")
+			unlock()
+			Eventually(done).Should(Receive(BeNil()))
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", strconv.Itoa(5))
+		})
 	})
 
 	Context("GetNicSriovMode", func() {
@@ -96,4 +140,801 @@ var _ = Describe("SRIOV", func() {
 			Expect(s.SetNicSriovMode("0000:d8:00.0", "legacy")).To(MatchError(testError))
 		})
 	})
+
+	Context("ConfigSriovDevice", func() {
+		It("configures the VFs concurrently", func() {
+			vars.VfConfigConcurrency = 2
+			DeferCleanup(func() {
+				vars.VfConfigConcurrency = consts.DefaultVfConfigConcurrency
+			})
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}
+			vfAddrs := []string{"0000:d8:00.2", "0000:d8:00.3", "0000:d8:00.4", "0000:d8:00.5"}
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return(vfAddrs, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil).Times(2)
+			for i, addr := range vfAddrs {
+				dputilsLibMock.EXPECT().GetVFID(addr).Return(i, nil)
+				hostMock.EXPECT().HasDriver(addr).Return(true, "vfio-pci")
+				hostMock.EXPECT().UnbindDriverIfNeeded(addr, false).Return(nil)
+				hostMock.EXPECT().BindDpdkDriver(addr, "vfio-pci").Return(nil)
+			}
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     4,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-3", DeviceType: "vfio-pci"}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     4,
+				TotalVfs:   8,
+			})).NotTo(HaveOccurred())
+		})
+		It("creates the VFs without probing their kernel driver", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs":            {},
+					"/sys/bus/pci/devices/0000:d8:00.0/sriov_drivers_autoprobe": []byte("1\n"),
+				},
+			})
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}
+			hostMock.EXPECT().AddUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().InvalidateInventory()
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil).Times(2)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(2)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(false, "")
+			// the VF never gets a kernel netdev, its admin MAC address is generated
+			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(pfLink, 0, gomock.Any()).Return(nil)
+			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", false).Return(nil)
+			hostMock.EXPECT().BindDpdkDriver("0000:d8:00.2", "vfio-pci").Return(nil)
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress:              "0000:d8:00.0",
+				Name:                    "enp216s0f0np0",
+				NumVfs:                  1,
+				DisableDriversAutoprobe: true,
+				VfGroups:                []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "vfio-pci"}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				TotalVfs:   8,
+			})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_drivers_autoprobe", "0")
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "1")
+		})
+		It("enables the hardware timestamping of the VFs supporting it", func() {
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil).Times(2)
+			for i, hwTimestamping := range []string{consts.HwTimestampingSupported, ""} {
+				addr, name := fmt.Sprintf("0000:d8:00.%d", i+2), fmt.Sprintf("enp216s0f0v%d", i)
+				vfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: name}}
+				dputilsLibMock.EXPECT().GetVFID(addr).Return(i, nil).Times(2)
+				hostMock.EXPECT().HasDriver(addr).Return(true, "mlx5_core")
+				hostMock.EXPECT().TryGetInterfaceName(addr).Return(name).Times(2)
+				netlinkLibMock.EXPECT().LinkByName(name).Return(vfLink, nil)
+				netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(pfLink, i, gomock.Any()).Return(nil)
+				hostMock.EXPECT().UnbindDriverIfNeeded(addr, false).Return(nil)
+				hostMock.EXPECT().BindDefaultDriver(addr).Return(nil)
+				hostMock.EXPECT().GetNetDevHwTimestamping(name).Return(hwTimestamping)
+			}
+			hostMock.EXPECT().EnableNetDevHwTimestamping("enp216s0f0v0").Return(nil)
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", HwTimestamping: true}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				TotalVfs:   8,
+			})).NotTo(HaveOccurred())
+		})
+		It("skips the steps of the configuration of the VFs of the driver quirks", func() {
+			quirks.Set([]sriovnetworkv1.DriverQuirk{{Name: "ice-rdma-aux", Driver: "ice", MinFirmwareVersion: "4.20",
+				Skip: []sriovnetworkv1.VfConfigStep{sriovnetworkv1.VfConfigStepAdminMac, sriovnetworkv1.VfConfigStepMtu}}})
+			DeferCleanup(func() { quirks.Set(nil) })
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil).Times(2)
+			hostMock.EXPECT().GetNetDevFirmwareVersion("enp216s0f0np0").Return("4.40 0x8001b6ee 1.3429.0")
+			for i, addr := range []string{"0000:d8:00.2", "0000:d8:00.3"} {
+				dputilsLibMock.EXPECT().GetVFID(addr).Return(i, nil)
+				hostMock.EXPECT().HasDriver(addr).Return(true, "iavf")
+				hostMock.EXPECT().UnbindDriverIfNeeded(addr, true).Return(nil)
+				hostMock.EXPECT().BindDefaultDriver(addr).Return(nil)
+			}
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", IsRdma: true, Mtu: 9000}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     2,
+				TotalVfs:   8,
+			})).NotTo(HaveOccurred())
+		})
+		It("sets the MSI-X vectors of the VFs", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.2", "/sys/bus/pci/devices/0000:d8:00.3"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.2/config":              pciConfig(8),
+					"/sys/bus/pci/devices/0000:d8:00.2/sriov_vf_msix_count": []byte("0"),
+					"/sys/bus/pci/devices/0000:d8:00.3/config":              pciConfig(16),
+					"/sys/bus/pci/devices/0000:d8:00.3/sriov_vf_msix_count": []byte("0"),
+				},
+			})
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil).Times(2)
+			for i, addr := range []string{"0000:d8:00.2", "0000:d8:00.3"} {
+				dputilsLibMock.EXPECT().GetVFID(addr).Return(i, nil)
+				hostMock.EXPECT().HasDriver(addr).Return(true, "vfio-pci")
+				hostMock.EXPECT().UnbindDriverIfNeeded(addr, false).Return(nil)
+				hostMock.EXPECT().BindDpdkDriver(addr, "vfio-pci").Return(nil)
+			}
+			// only the VF with a different number of vectors is unbound
+			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "vfio-pci", MsixCount: 16}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress:  "0000:d8:00.0",
+				Name:        "enp216s0f0np0",
+				NumVfs:      2,
+				TotalVfs:    8,
+				VfTotalMsix: 64,
+			})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.2/sriov_vf_msix_count", "16")
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.3/sriov_vf_msix_count", "0")
+		})
+		It("reads the PCIe link of the PF", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0", "/sys/bus/pci/devices/0000:d8:00.1"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/current_link_speed": []byte("16.0 GT/s PCIe\n"),
+					"/sys/bus/pci/devices/0000:d8:00.0/max_link_speed":     []byte("16.0 GT/s PCIe\n"),
+					"/sys/bus/pci/devices/0000:d8:00.0/current_link_width": []byte("8\n"),
+					"/sys/bus/pci/devices/0000:d8:00.0/max_link_width":     []byte("16\n"),
+					"/sys/bus/pci/devices/0000:d8:00.1/current_link_speed": []byte("Unknown\n"),
+					"/sys/bus/pci/devices/0000:d8:00.1/max_link_speed":     []byte("16.0 GT/s PCIe\n"),
+					"/sys/bus/pci/devices/0000:d8:00.1/current_link_width": []byte("0\n"),
+					"/sys/bus/pci/devices/0000:d8:00.1/max_link_width":     []byte("16\n"),
+				},
+			})
+			Expect(getPcieLink("0000:d8:00.0")).To(Equal(&sriovnetworkv1.PcieLinkInfo{
+				Speed: "16.0 GT/s PCIe", MaxSpeed: "16.0 GT/s PCIe", Width: 8, MaxWidth: 16, Degraded: true,
+			}))
+			Expect(getPcieLink("0000:d8:00.1")).To(BeNil())
+			Expect(getPcieLink("0000:d8:00.2")).To(BeNil())
+		})
+		It("detects a PCIe link trained below its speed", func() {
+			Expect(pcieSpeedGTs("8.0 GT/s PCIe")).To(BeNumerically("<", pcieSpeedGTs("16.0 GT/s PCIe")))
+			Expect(pcieSpeedGTs("2.5 GT/s")).To(Equal(2.5))
+			Expect(pcieSpeedGTs("")).To(BeZero())
+		})
+		It("reports the ports of the cards", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.1/infiniband/mlx5_1"},
+			})
+			pfList := []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:d8:00.1", LinkType: "IB", TotalVfs: 8},
+				{PciAddress: "0000:d8:00.0", LinkType: "ETH", LinkSpeed: "100000 Mb/s", TotalVfs: 8, EswitchMode: "legacy",
+					Ptp: &sriovnetworkv1.PtpInfo{Clock: "ptp0"}, VfTotalMsix: 1024},
+				{PciAddress: "0000:3b:00.0"},
+				{PciAddress: "0000:00:00.0", ZpciUID: "0x0001"},
+			}
+			setPortLayout(pfList)
+			Expect(pfList[0].Port).To(Equal(&sriovnetworkv1.NicPortInfo{Card: "0000:d8:00", Index: 1, Ports: 2, LinkType: "IB",
+				Capabilities: []sriovnetworkv1.PortCapability{sriovnetworkv1.PortCapabilitySriov, sriovnetworkv1.PortCapabilityRdma}}))
+			Expect(pfList[1].Port).To(Equal(&sriovnetworkv1.NicPortInfo{Card: "0000:d8:00", Index: 0, Ports: 2, LinkType: "ETH",
+				Speed: "100000 Mb/s", Capabilities: []sriovnetworkv1.PortCapability{sriovnetworkv1.PortCapabilitySriov,
+					sriovnetworkv1.PortCapabilitySwitchdev, sriovnetworkv1.PortCapabilityPtp, sriovnetworkv1.PortCapabilityVfMsix}}))
+			Expect(pfList[2].Port).To(Equal(&sriovnetworkv1.NicPortInfo{Card: "0000:3b:00", Index: 0, Ports: 1}))
+			Expect(pfList[3].Port).To(BeNil())
+		})
+		It("reads the MSI-X vectors of the VF and of the PF", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0", "/sys/bus/pci/devices/0000:d8:00.2"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/sriov_vf_total_msix": []byte("2048\n"),
+					"/sys/bus/pci/devices/0000:d8:00.2/config":              pciConfig(12),
+				},
+			})
+			Expect(getVfTotalMsix("0000:d8:00.0")).To(Equal(2048))
+			Expect(getVfMsixCount("0000:d8:00.2")).To(Equal(12))
+			Expect(getVfMsixCount("0000:d8:00.3")).To(BeZero())
+		})
+		It("returns the error of a failed VF", func() {
+			pfLink := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0"}}
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLink, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, testError)
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     1,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "vfio-pci"}},
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     1,
+				TotalVfs:   8,
+			})).To(MatchError(testError))
+		})
+		It("waits for kubernetes-nmstate to set the MTU of the PF", func() {
+			vars.NMStateIntegration = true
+			DeferCleanup(func() {
+				vars.NMStateIntegration = false
+			})
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host" + consts.SriovConfBasePath},
+			})
+
+			err := s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     4,
+				Mtu:        9000,
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     4,
+				TotalVfs:   8,
+				Mtu:        1500,
+			})
+			Expect(err).To(MatchError(snerrors.ErrNMStatePending))
+			mtus, err := loadNMStateMtus()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mtus["0000:d8:00.0"].PendingSince).ToNot(BeZero())
+		})
+		It("sets the MTU of the PF once the wait for kubernetes-nmstate timed out", func() {
+			vars.NMStateIntegration = true
+			DeferCleanup(func() {
+				vars.NMStateIntegration = false
+			})
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host" + consts.SriovConfBasePath},
+			})
+			Expect(saveNMStateMtus(map[string]nmstateMtu{
+				"0000:d8:00.0": {PendingSince: time.Now().Add(-consts.NMStateMtuTimeout)},
+			})).To(Succeed())
+			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.0", 9000).Return(nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(
+				&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", OperState: netlink.OperUp}}, nil)
+
+			Expect(s.ConfigSriovDevice(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Mtu:        9000,
+			}, &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				TotalVfs:   8,
+				Mtu:        1500,
+			})).To(Succeed())
+			mtus, err := loadNMStateMtus()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mtus["0000:d8:00.0"].Fallback).To(BeTrue())
+		})
+	})
+
+	Context("ResetSriovDevice", func() {
+		It("enables the drivers autoprobe of the PF again", func() {
+			vars.NMStateIntegration = true
+			DeferCleanup(func() {
+				vars.NMStateIntegration = false
+			})
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs":            {},
+					"/sys/bus/pci/devices/0000:d8:00.0/sriov_drivers_autoprobe": []byte("0\n"),
+				},
+			})
+			hostMock.EXPECT().InvalidateInventory()
+
+			Expect(s.ResetSriovDevice(sriovnetworkv1.InterfaceExt{PciAddress: "0000:d8:00.0"})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_drivers_autoprobe", "1")
+		})
+		It("leaves the MTU of the PF to kubernetes-nmstate", func() {
+			vars.NMStateIntegration = true
+			DeferCleanup(func() {
+				vars.NMStateIntegration = false
+			})
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+			hostMock.EXPECT().InvalidateInventory()
+
+			Expect(s.ResetSriovDevice(sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				LinkType:   consts.LinkTypeETH,
+				Mtu:        9000,
+			})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "0")
+		})
+		It("resets the MTU of the PF it set once the wait for kubernetes-nmstate timed out", func() {
+			vars.NMStateIntegration = true
+			DeferCleanup(func() {
+				vars.NMStateIntegration = false
+			})
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0", "/host" + consts.SriovConfBasePath},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+			Expect(saveNMStateMtus(map[string]nmstateMtu{"0000:d8:00.0": {Fallback: true}})).To(Succeed())
+			hostMock.EXPECT().InvalidateInventory()
+			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.0", 1500).Return(nil)
+
+			Expect(s.ResetSriovDevice(sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				LinkType:   consts.LinkTypeETH,
+				Mtu:        9000,
+			})).NotTo(HaveOccurred())
+			Expect(loadNMStateMtus()).To(BeEmpty())
+		})
+	})
+
+	Context("ConfigSriovInterfaces", func() {
+		It("binds the driver of a zPCI function", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "001f:00:00.0",
+				NumVfs:     1,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "vfio-pci", ResourceName: "roce"}},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			hostMock.EXPECT().BindDpdkDriver("001f:00:00.0", "vfio-pci").Return(nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "001f:00:00.0",
+				Driver:     "mlx5_core",
+				ZpciUID:    "0x1f",
+				NumVfs:     1,
+				TotalVfs:   1,
+				VFs:        []sriovnetworkv1.VirtualFunction{{PciAddress: "001f:00:00.0", Driver: "mlx5_core"}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("syncs the flow rules and the policing of a switchdev PF that doesn't need an update", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			policing := &sriovnetworkv1.PolicingConfig{Egress: &sriovnetworkv1.PolicingRate{Rate: 1000, Burst: 64}}
+			iface := sriovnetworkv1.Interface{
+				PciAddress:  "0000:d8:00.0",
+				Name:        "enp216s0f0np0",
+				NumVfs:      1,
+				EswitchMode: "switchdev",
+				VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "netdevice", ResourceName: "switchdev",
+					Policing: policing}},
+				FlowRules: []sriovnetworkv1.FlowRule{{Name: "trap-lacp", Match: sriovnetworkv1.FlowMatch{EthType: "0x8809"}, Action: "trap"}},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+			hostMock.EXPECT().SyncFlowRules("enp216s0f0np0", iface.FlowRules).Return(nil)
+			hostMock.EXPECT().SyncPolicing("enp216s0f0np0", map[int]*sriovnetworkv1.PolicingConfig{0: policing}).Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:00.2", nil).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress:  "0000:d8:00.0",
+				Name:        "enp216s0f0np0",
+				Driver:      "mlx5_core",
+				EswitchMode: "switchdev",
+				NumVfs:      1,
+				TotalVfs:    8,
+				VFs:         []sriovnetworkv1.VirtualFunction{{PciAddress: "0000:d8:00.2", Driver: "mlx5_core", VfID: 0}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("applies the address sharing exceptions to the VFs of their resource and reverts the removed ones", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups: []sriovnetworkv1.VfGroup{
+					{VfRange: "0-0", DeviceType: "netdevice", ResourceName: "vrrp",
+						AddressSharing: &sriovnetworkv1.AddressSharing{AllowSpoofing: true, MultipleUnicastMacs: true}},
+					{VfRange: "1-1", DeviceType: "netdevice", ResourceName: "data"}},
+			}
+			applied := iface.DeepCopy()
+			applied.VfGroups[1].AddressSharing = &sriovnetworkv1.AddressSharing{MultipleUnicastMacs: true}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(applied, true, nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, false).Return(nil)
+			// the trust mode not supported by the driver is skipped
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, true).Return(syscall.EOPNOTSUPP)
+			// the exception no longer requested is reverted
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 1, false).Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:01.0", "0000:d8:01.1"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.0").Return(0, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.1").Return(1, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.0", nil).Return(nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.1", nil).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     2,
+				TotalVfs:   8,
+				VFs: []sriovnetworkv1.VirtualFunction{
+					{PciAddress: "0000:d8:01.0", Driver: "iavf", VfID: 0},
+					{PciAddress: "0000:d8:01.1", Driver: "iavf", VfID: 1}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("programs the unicast MACs of the VFs and clears the ones of the other VFs", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     3,
+				VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-2", DeviceType: "netdevice", ResourceName: "bond",
+					UnicastMacs: []sriovnetworkv1.VfUnicastMacs{
+						{Vf: 0, Macs: []string{"02:00:00:00:00:01"}},
+						{Vf: 1, Macs: []string{"02:00:00:00:00:02"}}}}},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:01.0", "0000:d8:01.1", "0000:d8:01.2"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.0").Return(0, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.1").Return(1, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.2").Return(2, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.0", []string{"02:00:00:00:00:01"}).Return(nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.1", []string{"02:00:00:00:00:02"}).Return(nil)
+			// the MACs the operator added to the VF no longer listed are removed
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.2", nil).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     3,
+				TotalVfs:   8,
+				VFs: []sriovnetworkv1.VirtualFunction{
+					{PciAddress: "0000:d8:01.0", Driver: "iavf", VfID: 0},
+					{PciAddress: "0000:d8:01.1", Driver: "iavf", VfID: 1},
+					{PciAddress: "0000:d8:01.2", Driver: "iavf", VfID: 2}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("configures the QoS of a PF reporting a different configuration", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     1,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "netdevice", ResourceName: "roce", IsRdma: true}},
+				Qos:        &sriovnetworkv1.QosConfig{Trust: "dscp", PfcPriorities: []int{3}},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+			hostMock.EXPECT().SetNetDevQos("enp216s0f0np0", iface.Qos).Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:00.2", nil).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "mlx5_core",
+				NumVfs:     1,
+				TotalVfs:   8,
+				Qos:        &sriovnetworkv1.QosConfig{Trust: "pcp", PfcPriorities: []int{}},
+				VFs:        []sriovnetworkv1.VirtualFunction{{PciAddress: "0000:d8:00.2", Driver: "mlx5_core", VfID: 0}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("configures the congestion control of a PF reporting different settings", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			cnpDscp := 48
+			iface := sriovnetworkv1.Interface{
+				PciAddress:        "0000:d8:00.0",
+				Name:              "enp216s0f0np0",
+				NumVfs:            1,
+				VfGroups:          []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "netdevice", ResourceName: "roce", IsRdma: true}},
+				CongestionControl: &sriovnetworkv1.CongestionControlConfig{EcnPriorities: []int{3}, CnpDscp: &cnpDscp},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(&iface).Return(nil)
+			hostMock.EXPECT().SetCongestionControl("0000:d8:00.0", "enp216s0f0np0", iface.CongestionControl).Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:00.2", nil).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress:        "0000:d8:00.0",
+				Name:              "enp216s0f0np0",
+				Driver:            "mlx5_core",
+				NumVfs:            1,
+				TotalVfs:          8,
+				CongestionControl: &sriovnetworkv1.CongestionControlConfig{EcnPriorities: []int{3}},
+				VFs:               []sriovnetworkv1.VirtualFunction{{PciAddress: "0000:d8:00.2", Driver: "mlx5_core", VfID: 0}},
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("doesn't change the VFs of the PTP time source without the permission of the policy", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", ResourceName: "tsn"}},
+			}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+
+			err := s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     0,
+				TotalVfs:   8,
+				Ptp:        &sriovnetworkv1.PtpInfo{Clock: "ptp0", Services: []string{"ptp4l", "phc2sys"}, TimeSource: true},
+			}}, map[string]bool{})
+			Expect(err).To(MatchError(ContainSubstring("enp216s0f0np0 is the PTP time source of the node")))
+			Expect(snerrors.ReasonOf(err)).To(Equal(snerrors.ReasonPtpTimeSource))
+		})
+		It("doesn't split the port nor reset the VFs of the PTP time source", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false).Times(2)
+			ptp := &sriovnetworkv1.PtpInfo{Clock: "ptp0", Services: []string{"ptp4l", "phc2sys"}, TimeSource: true}
+			status := sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     2,
+				TotalVfs:   8,
+				Ptp:        ptp,
+			}
+
+			// the port isn't split, the VFs of the PF are left as they are
+			err := s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				PortSplit:  2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", ResourceName: "tsn"}},
+			}}, []sriovnetworkv1.InterfaceExt{status}, map[string]bool{})
+			Expect(err).To(MatchError(ContainSubstring("enp216s0f0np0 is the PTP time source of the node")))
+
+			// no policy selects the PF anymore, its VFs are kept
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(&sriovnetworkv1.Interface{}, true, nil)
+			err = s.ConfigSriovInterfaces(storeMock, nil, []sriovnetworkv1.InterfaceExt{status}, map[string]bool{})
+			Expect(err).To(MatchError(ContainSubstring("its VFs are kept until it no longer is")))
+			Expect(snerrors.ReasonOf(err)).To(Equal(snerrors.ReasonPtpTimeSource))
+		})
+		It("doesn't change the PFs outside of the PCI address guard of the node", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host" + consts.SriovConfBasePath},
+				Files: map[string][]byte{
+					"/host" + consts.PciGuardFile: []byte("deny:\n- 0000:d8:00.*\n"),
+				},
+			})
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+
+			err := s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", ResourceName: "storage"}},
+			}}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     0,
+				TotalVfs:   8,
+			}, {
+				// the VFs of a guarded PF left out of the policies are not reset either
+				PciAddress: "0000:d8:00.1",
+				Name:       "enp216s0f1np1",
+				Driver:     "ice",
+				NumVfs:     4,
+				TotalVfs:   8,
+			}}, map[string]bool{})
+			Expect(err).To(MatchError(snerrors.ErrPciAddressGuarded))
+			Expect(snerrors.ReasonOf(err)).To(Equal(snerrors.ReasonPciAddressGuarded))
+		})
+		It("only checks the PCI address guard of the node for the PFs that change", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host" + consts.SriovConfBasePath},
+				Files: map[string][]byte{
+					"/host" + consts.PciGuardFile: []byte("deny:\n- 0000:d8:00.*\n"),
+				},
+			})
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			iface := sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     1,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: "netdevice", ResourceName: "storage"}},
+			}
+			status := []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     1,
+				TotalVfs:   8,
+				VFs:        []sriovnetworkv1.VirtualFunction{{PciAddress: "0000:d8:01.0", Driver: "iavf", VfID: 0}},
+			}}
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false).Times(2)
+			storeMock.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil).Times(2)
+			storeMock.EXPECT().SaveLastPfAppliedStatus(gomock.Any()).Return(nil).Times(2)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:01.0"}, nil)
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:01.0").Return(0, nil)
+			hostMock.EXPECT().SyncVfUnicastMacs("0000:d8:01.0", nil).Return(nil)
+
+			// the guarded PF matching the spec is left untouched
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, status, map[string]bool{})).
+				NotTo(HaveOccurred())
+
+			// the features of the PF are not applied
+			iface.VfGroups[0].AddressSharing = &sriovnetworkv1.AddressSharing{AllowSpoofing: true}
+			err := s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{iface}, status, map[string]bool{})
+			Expect(err).To(MatchError(snerrors.ErrPciAddressGuarded))
+		})
+		It("skips the PFs claimed by another host network manager", func() {
+			guard.SetClaims(map[string][]string{"nmstate": {"0000:d8:00.0"}})
+			DeferCleanup(func() { guard.SetClaims(nil) })
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, []sriovnetworkv1.Interface{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				NumVfs:     2,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", ResourceName: "tenant"}},
+			}}, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "0000:d8:00.0",
+				Name:       "enp216s0f0np0",
+				Driver:     "ice",
+				NumVfs:     0,
+				TotalVfs:   8,
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+		It("gives a zPCI function back to its default driver", func() {
+			storeMock := storeMockPkg.NewMockManagerInterface(testCtrl)
+			hostMock.EXPECT().IsKernelLockdownMode().Return(false)
+			hostMock.EXPECT().BindDefaultDriver("001f:00:00.0").Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeMock, nil, []sriovnetworkv1.InterfaceExt{{
+				PciAddress: "001f:00:00.0",
+				Driver:     "vfio-pci",
+				ZpciUID:    "0x1f",
+				NumVfs:     1,
+				TotalVfs:   1,
+			}}, map[string]bool{})).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("binding state", func() {
+		It("reports the driver_override of the VF", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:3b:02.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:3b:02.0/driver_override": []byte("vfio-pci\n")},
+			})
+			dputilsLibMock.EXPECT().GetDriverName("0000:3b:02.0").Return("", fmt.Errorf("no driver"))
+			dputilsLibMock.EXPECT().GetVFID("0000:3b:02.0").Return(0, nil)
+			hostMock.EXPECT().GetNetdevMTU("0000:3b:02.0").Return(0)
+			hostMock.EXPECT().TryGetInterfaceName("0000:3b:02.0").Return("")
+			vf := s.GetVfInfo("0000:3b:02.0", nil)
+			Expect(vf.Driver).To(BeEmpty())
+			Expect(vf.DriverOverride).To(Equal("vfio-pci"))
+		})
+		It("reports the bind errors of the unbound VFs", func() {
+			hostMock.EXPECT().GetBindError("0000:3b:02.1").Return("probe with driver vfio-pci failed with error -22")
+			pfList := []sriovnetworkv1.InterfaceExt{{PciAddress: "0000:3b:00.0", VFs: []sriovnetworkv1.VirtualFunction{
+				{PciAddress: "0000:3b:02.0", Driver: "iavf"},
+				{PciAddress: "0000:3b:02.1"},
+			}}}
+			s.(*sriov).setBindErrors(pfList)
+			Expect(pfList[0].VFs[0].BindError).To(BeEmpty())
+			Expect(pfList[0].VFs[1].BindError).To(Equal("probe with driver vfio-pci failed with error -22"))
+		})
+	})
+
+	Context("splitPorts", func() {
+		It("removes the VFs before splitting the port", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:3b:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:3b:00.0/sriov_numvfs": []byte("8")},
+			})
+			gomock.InOrder(
+				hostMock.EXPECT().InvalidateInventory(),
+				hostMock.EXPECT().SetPortSplit("0000:3b:00.0", 4).Return(nil),
+			)
+			interfaces := []sriovnetworkv1.Interface{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0np0", NumVfs: 8, PortSplit: 4},
+				{PciAddress: "0000:3b:00.1", Name: "ens1f1np1", NumVfs: 8},
+				{PciAddress: "0000:5e:00.0", Name: "ens2f0np0", NumVfs: 8, PortSplit: 2},
+			}
+			split, err := s.(*sriov).splitPorts(interfaces, []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0np0", NumVfs: 8},
+				{PciAddress: "0000:3b:00.1", Name: "ens1f1np1", NumVfs: 8},
+				{PciAddress: "0000:5e:00.0", Name: "ens2f0np0s0", NumVfs: 8, PortSplit: 2},
+			}, map[string]bool{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(split).To(Equal([]string{"0000:3b:00.0"}))
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:3b:00.0/sriov_numvfs", "0")
+
+			// the split PFs are configured with the names of their split ports
+			renamed := splitPortNames(interfaces, []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0np0s0", PortSplit: 4},
+				{PciAddress: "0000:3b:00.1", Name: "ens1f1np1"},
+			})
+			Expect(renamed[0].Name).To(Equal("ens1f0np0s0"))
+			Expect(renamed[1].Name).To(Equal("ens1f1np1"))
+			Expect(interfaces[0].Name).To(Equal("ens1f0np0"))
+		})
+
+		It("records the splits the NIC applies at the next boot", func() {
+			origInChroot := vars.InChroot
+			DeferCleanup(func() { vars.InChroot = origInChroot })
+			vars.InChroot = false
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"host/etc/sriov-operator", "proc/sys/kernel/random"},
+				Files: map[string][]byte{"/proc/sys/kernel/random/boot_id": []byte("boot-1\n")},
+			})
+			interfaces := []sriovnetworkv1.Interface{
+				{PciAddress: "0000:3b:00.0", NumVfs: 8, PortSplit: 4},
+				{PciAddress: "0000:5e:00.0", NumVfs: 8, PortSplit: 2},
+			}
+			Expect(syncPendingSplits([]string{"0000:3b:00.0", "0000:5e:00.0"}, interfaces, []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0np0"},
+				{PciAddress: "0000:5e:00.0", Name: "ens2f0np0s0", PortSplit: 2},
+			})).To(Succeed())
+			Expect(portsplit.Load()).To(Equal(map[string]portsplit.Pending{"0000:3b:00.0": {PortSplit: 4, BootID: "boot-1"}}))
+			Expect(portsplit.NeedReboot("0000:3b:00.0", 4)).To(BeTrue())
+
+			// the split is dropped once reported
+			Expect(syncPendingSplits(nil, interfaces, []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:3b:00.0", Name: "ens1f0np0s0", PortSplit: 4},
+			})).To(Succeed())
+			Expect(portsplit.Load()).To(BeEmpty())
+		})
+	})
+
+	Context("HasSriovCapablePfs", func() {
+		BeforeEach(func() {
+			devMode := vars.DevMode
+			vars.DevMode = true
+			DeferCleanup(func() { vars.DevMode = devMode })
+		})
+		netDevice := func(address string) *ghw.PCIDevice {
+			return &ghw.PCIDevice{Address: address, Class: &pcidb.Class{ID: "02"},
+				Vendor: &pcidb.Vendor{ID: "15b3"}, Product: &pcidb.Product{ID: "101d"}}
+		}
+		It("detects the SR-IOV capable PF of a virtual machine", func() {
+			hostMock.EXPECT().GetPCIDevices().Return([]*ghw.PCIDevice{
+				{Address: "0000:00:01.0", Class: &pcidb.Class{ID: "01"}},
+				netDevice("0000:00:03.0"), netDevice("0000:00:04.0")}, nil)
+			dputilsLibMock.EXPECT().IsSriovPF("0000:00:03.0").Return(false)
+			dputilsLibMock.EXPECT().IsSriovPF("0000:00:04.0").Return(true)
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:00:04.0").Return(8)
+			Expect(s.HasSriovCapablePfs()).To(BeTrue())
+		})
+		It("reports the virtual machines with VFs only", func() {
+			hostMock.EXPECT().GetPCIDevices().Return([]*ghw.PCIDevice{netDevice("0000:00:03.0")}, nil)
+			dputilsLibMock.EXPECT().IsSriovPF("0000:00:03.0").Return(false)
+			Expect(s.HasSriovCapablePfs()).To(BeFalse())
+		})
+	})
 })
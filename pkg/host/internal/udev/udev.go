@@ -13,6 +13,7 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
@@ -36,7 +37,7 @@ func (u *udev) PrepareNMUdevRule(supportedVfIds []string) error {
 
 	// remove the old unmanaged rules file
 	if _, err := os.Stat(filePath); err == nil {
-		err = os.Remove(filePath)
+		err = utils.RemoveHostFile(filePath)
 		if err != nil {
 			log.Log.Error(err, "failed to remove the network manager global unmanaged rule",
 				"path", filePath)
@@ -173,8 +174,28 @@ func (u *udev) RemoveVfRepresentorUdevRule(pfPciAddress string) error {
 	return u.removeUdevRule(pfPciAddress, "20-switchdev")
 }
 
+// AddPfRenameUdevRule adds a udev rule that renames the concrete PF, its representors keep their names. The rule
+// only matches the port when portName is set, the other ports of a split PF keep their names.
+func (u *udev) AddPfRenameUdevRule(pfPciAddress, portName, name string) error {
+	log.Log.V(2).Info("AddPfRenameUdevRule()", "device", pfPciAddress, "port", portName, "name", name)
+	udevRuleContent := fmt.Sprintf(consts.PfRenameUdevRule, pfPciAddress, name)
+	if portName != "" {
+		udevRuleContent = fmt.Sprintf(consts.PfPortRenameUdevRule, pfPciAddress, portName, name)
+	}
+	return u.addUdevRule(pfPciAddress, "30-pf-rename", udevRuleContent)
+}
+
+// RemovePfRenameUdevRule removes the udev rule that renames the concrete PF
+func (u *udev) RemovePfRenameUdevRule(pfPciAddress string) error {
+	log.Log.V(2).Info("RemovePfRenameUdevRule()", "device", pfPciAddress)
+	return u.removeUdevRule(pfPciAddress, "30-pf-rename")
+}
+
 func (u *udev) addUdevRule(pfPciAddress, ruleName, ruleContent string) error {
 	log.Log.V(2).Info("addUdevRule()", "device", pfPciAddress, "rule", ruleName)
+	if err := guard.Check(pfPciAddress); err != nil {
+		return err
+	}
 	rulePath := u.getRuleFolderPath()
 	err := os.MkdirAll(rulePath, os.ModePerm)
 	if err != nil && !os.IsExist(err) {
@@ -182,7 +203,7 @@ func (u *udev) addUdevRule(pfPciAddress, ruleName, ruleContent string) error {
 		return err
 	}
 	filePath := u.getRulePathForPF(ruleName, pfPciAddress)
-	if err := os.WriteFile(filePath, []byte(ruleContent), 0666); err != nil {
+	if err := utils.WriteHostFile(filePath, []byte(ruleContent), 0666); err != nil {
 		log.Log.Error(err, "addUdevRule(): fail to write file", "path", filePath)
 		return err
 	}
@@ -192,8 +213,8 @@ func (u *udev) addUdevRule(pfPciAddress, ruleName, ruleContent string) error {
 func (u *udev) removeUdevRule(pfPciAddress, ruleName string) error {
 	log.Log.V(2).Info("removeUdevRule()", "device", pfPciAddress, "rule", ruleName)
 	rulePath := u.getRulePathForPF(ruleName, pfPciAddress)
-	err := os.Remove(rulePath)
-	if err != nil && !os.IsNotExist(err) {
+	err := utils.RemoveHostFile(rulePath)
+	if err != nil {
 		log.Log.Error(err, "removeUdevRule(): fail to remove rule file", "path", rulePath)
 		return err
 	}
@@ -21,6 +21,10 @@ const (
 		`ATTRS{phys_switch_id}=="7cfe90ff2cc0", ` +
 		`ATTR{phys_port_name}=="pf0vf*", IMPORT{program}="/etc/udev/switchdev-vf-link-name.sh $attr{phys_port_name}", ` +
 		`NAME="enp216s0f0np0_$env{NUMBER}"`
+	testExpectedPfRenameUdevRule = `SUBSYSTEM=="net", ACTION=="add", KERNELS=="0000:d8:00.0", ` +
+		`ATTR{phys_port_name}!="pf*", NAME="sriovd800f0"`
+	testExpectedPfPortRenameUdevRule = `SUBSYSTEM=="net", ACTION=="add", KERNELS=="0000:d8:00.0", ` +
+		`ATTR{phys_port_name}=="p0s1", NAME="sriovd800f0"`
 )
 
 var _ = Describe("UDEV", func() {
@@ -114,4 +118,34 @@ var _ = Describe("UDEV", func() {
 			Expect(s.RemoveVfRepresentorUdevRule("0000:d8:00.0")).To(BeNil())
 		})
 	})
+	Context("AddPfRenameUdevRule", func() {
+		It("Created", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+			Expect(s.AddPfRenameUdevRule("0000:d8:00.0", "", "sriovd800f0")).To(BeNil())
+			helpers.GinkgoAssertFileContentsEquals(
+				"/etc/udev/rules.d/30-pf-rename-0000:d8:00.0.rules",
+				testExpectedPfRenameUdevRule)
+		})
+		It("Created for a split port", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+			Expect(s.AddPfRenameUdevRule("0000:d8:00.0", "p0s1", "sriovd800f0")).To(BeNil())
+			helpers.GinkgoAssertFileContentsEquals(
+				"/etc/udev/rules.d/30-pf-rename-0000:d8:00.0.rules",
+				testExpectedPfPortRenameUdevRule)
+		})
+	})
+	Context("RemovePfRenameUdevRule", func() {
+		It("Exist", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/etc/udev/rules.d"},
+				Files: map[string][]byte{
+					"/etc/udev/rules.d/30-pf-rename-0000:d8:00.0.rules": []byte(testExpectedPfRenameUdevRule),
+				},
+			})
+			Expect(s.RemovePfRenameUdevRule("0000:d8:00.0")).To(BeNil())
+			_, err := os.Stat(filepath.Join(vars.FilesystemRoot,
+				"/etc/udev/rules.d/30-pf-rename-0000:d8:00.0.rules"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
 })
@@ -9,6 +9,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/guard"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/govdpa"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 )
@@ -32,6 +33,9 @@ func (v *vdpa) CreateVDPADevice(pciAddr, vdpaType string) error {
 	if expectedDriver == "" {
 		return fmt.Errorf("unknown VDPA device type: %s", vdpaType)
 	}
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
 	expectedVDPAName := generateVDPADevName(pciAddr)
 	_, err := v.vdpaLib.GetVdpaDevice(expectedVDPAName)
 	if err != nil {
@@ -60,6 +64,9 @@ func (v *vdpa) CreateVDPADevice(pciAddr, vdpaType string) error {
 func (v *vdpa) DeleteVDPADevice(pciAddr string) error {
 	log.Log.V(2).Info("DeleteVDPADevice(): delete VDPA device for VF",
 		"device", pciAddr)
+	if err := guard.Check(pciAddr); err != nil {
+		return err
+	}
 	expectedVDPAName := generateVDPADevName(pciAddr)
 	if err := v.vdpaLib.DeleteVdpaDevice(expectedVDPAName); err != nil {
 		if errors.Is(err, syscall.ENODEV) {
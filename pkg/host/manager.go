@@ -1,11 +1,20 @@
 package host
 
 import (
+	"fmt"
+	"sync"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/congestion"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/inventory"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/kernel"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/govdpa"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/network"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/pflock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/service"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/sriov"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/udev"
@@ -24,6 +33,22 @@ type HostManagerInterface interface {
 	types.UdevInterface
 	types.SriovInterface
 	types.VdpaInterface
+	types.InventoryInterface
+	types.CongestionControlInterface
+}
+
+var (
+	inventoryOnce   sync.Once
+	sharedInventory types.InventoryInterface
+)
+
+// getInventory returns the hardware inventory shared by all the host managers of the process,
+// so a single uevent watcher keeps every cached copy accurate
+func getInventory() types.InventoryInterface {
+	inventoryOnce.Do(func() {
+		sharedInventory = inventory.New(ghw.New())
+	})
+	return sharedInventory
 }
 
 type hostManager struct {
@@ -34,15 +59,34 @@ type hostManager struct {
 	types.UdevInterface
 	types.SriovInterface
 	types.VdpaInterface
+	types.InventoryInterface
+	types.CongestionControlInterface
 }
 
 func NewHostManager(utilsInterface utils.CmdInterface) HostManagerInterface {
-	dpUtils := dputils.New()
-	k := kernel.New(utilsInterface)
+	return newHostManager(utilsInterface, netlink.New(), dputils.New())
+}
+
+// NewHostManagerInNamespace returns the host manager running the netlink requests and the commands of the network
+// operations, e.g. ip, tc or devlink, in the network namespace, the name of a namespace of the host or its path. The
+// PCI devices are still discovered from the sysfs of the process, their netdevs are listed with netlink in the
+// namespace and matched by the bus address ethtool reports for them.
+func NewHostManagerInNamespace(utilsInterface utils.CmdInterface, netns string) (HostManagerInterface, error) {
+	netlinkLib, err := netlink.NewInNamespace(utils.NetNsPath(netns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the network namespace %s: %v", netns, err)
+	}
+	return newHostManager(utils.NewInNetNs(utilsInterface, netns), netlinkLib, dputils.NewInNamespace(netlinkLib)), nil
+}
+
+func newHostManager(utilsInterface utils.CmdInterface, netlinkLib netlink.NetlinkLib, dpUtils dputils.DPUtilsLib) HostManagerInterface {
+	i := getInventory()
+	k := kernel.New(utilsInterface, i)
 	n := network.New(utilsInterface, dpUtils)
 	sv := service.New(utilsInterface)
 	u := udev.New(utilsInterface)
-	sr := sriov.New(utilsInterface, k, n, u, netlink.New(), dpUtils)
+	cc := congestion.New()
+	sr := sriov.New(utilsInterface, k, n, u, i, cc, netlinkLib, dpUtils)
 	v := vdpa.New(k, govdpa.New())
 
 	return &hostManager{
@@ -53,5 +97,27 @@ func NewHostManager(utilsInterface utils.CmdInterface) HostManagerInterface {
 		u,
 		sr,
 		v,
+		i,
+		cc,
 	}
 }
+
+// SyncFlowRules syncs the flow rules of the PF holding the lock of the PF, the managers of the host call the network
+// manager directly while they already hold it
+func (h *hostManager) SyncFlowRules(pfName string, rules []sriovnetworkv1.FlowRule) error {
+	defer pflock.LockNetDev(pfName)()
+	return h.NetworkInterface.SyncFlowRules(pfName, rules)
+}
+
+// RenameNetDev renames the netdev holding the lock of its PF
+func (h *hostManager) RenameNetDev(name, newName string) error {
+	defer pflock.LockNetDev(name)()
+	return h.NetworkInterface.RenameNetDev(name, newName)
+}
+
+// LockPf locks the PF of the PCI address for the operations of the config daemon reading or changing the PF outside
+// of the host managers, and returns the function unlocking it. The function must not call the host managers locking
+// the PF meanwhile.
+func LockPf(pciAddr string) func() {
+	return pflock.Lock(pciAddr)
+}
@@ -39,6 +39,20 @@ func (m *MockHostManagerInterface) EXPECT() *MockHostManagerInterfaceMockRecorde
 	return m.recorder
 }
 
+// AddPfRenameUdevRule mocks base method.
+func (m *MockHostManagerInterface) AddPfRenameUdevRule(pfPciAddress, portName, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPfRenameUdevRule", pfPciAddress, portName, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPfRenameUdevRule indicates an expected call of AddPfRenameUdevRule.
+func (mr *MockHostManagerInterfaceMockRecorder) AddPfRenameUdevRule(pfPciAddress, portName, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPfRenameUdevRule", reflect.TypeOf((*MockHostManagerInterface)(nil).AddPfRenameUdevRule), pfPciAddress, portName, name)
+}
+
 // AddUdevRule mocks base method.
 func (m *MockHostManagerInterface) AddUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -223,6 +237,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) DiscoverVDPAType(pciAddr interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverVDPAType", reflect.TypeOf((*MockHostManagerInterface)(nil).DiscoverVDPAType), pciAddr)
 }
 
+// EnableNetDevHwTimestamping mocks base method.
+func (m *MockHostManagerInterface) EnableNetDevHwTimestamping(ifaceName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableNetDevHwTimestamping", ifaceName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableNetDevHwTimestamping indicates an expected call of EnableNetDevHwTimestamping.
+func (mr *MockHostManagerInterfaceMockRecorder) EnableNetDevHwTimestamping(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableNetDevHwTimestamping", reflect.TypeOf((*MockHostManagerInterface)(nil).EnableNetDevHwTimestamping), ifaceName)
+}
+
 // EnableRDMA mocks base method.
 func (m *MockHostManagerInterface) EnableRDMA(conditionFilePath, serviceName, packageManager string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -267,6 +295,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) EnableService(service interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockHostManagerInterface)(nil).EnableService), service)
 }
 
+// GetBindError mocks base method.
+func (m *MockHostManagerInterface) GetBindError(pciAddr string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBindError", pciAddr)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetBindError indicates an expected call of GetBindError.
+func (mr *MockHostManagerInterfaceMockRecorder) GetBindError(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBindError", reflect.TypeOf((*MockHostManagerInterface)(nil).GetBindError), pciAddr)
+}
+
+// GetCongestionControl mocks base method.
+func (m *MockHostManagerInterface) GetCongestionControl(pciAddr, pfName string) *v1.CongestionControlConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionControl", pciAddr, pfName)
+	ret0, _ := ret[0].(*v1.CongestionControlConfig)
+	return ret0
+}
+
+// GetCongestionControl indicates an expected call of GetCongestionControl.
+func (mr *MockHostManagerInterfaceMockRecorder) GetCongestionControl(pciAddr, pfName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionControl", reflect.TypeOf((*MockHostManagerInterface)(nil).GetCongestionControl), pciAddr, pfName)
+}
+
 // GetCurrentKernelArgs mocks base method.
 func (m *MockHostManagerInterface) GetCurrentKernelArgs() (string, error) {
 	m.ctrl.T.Helper()
@@ -282,6 +338,65 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetCurrentKernelArgs() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentKernelArgs", reflect.TypeOf((*MockHostManagerInterface)(nil).GetCurrentKernelArgs))
 }
 
+// GetFirmwareFaults mocks base method.
+func (m *MockHostManagerInterface) GetFirmwareFaults(pciAddr string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirmwareFaults", pciAddr)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirmwareFaults indicates an expected call of GetFirmwareFaults.
+func (mr *MockHostManagerInterfaceMockRecorder) GetFirmwareFaults(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirmwareFaults", reflect.TypeOf((*MockHostManagerInterface)(nil).GetFirmwareFaults), pciAddr)
+}
+
+// GetHugepageSizes mocks base method.
+func (m *MockHostManagerInterface) GetHugepageSizes() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHugepageSizes")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHugepageSizes indicates an expected call of GetHugepageSizes.
+func (mr *MockHostManagerInterfaceMockRecorder) GetHugepageSizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHugepageSizes", reflect.TypeOf((*MockHostManagerInterface)(nil).GetHugepageSizes))
+}
+
+// GetIommuKernelArgs mocks base method.
+func (m *MockHostManagerInterface) GetIommuKernelArgs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIommuKernelArgs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetIommuKernelArgs indicates an expected call of GetIommuKernelArgs.
+func (mr *MockHostManagerInterfaceMockRecorder) GetIommuKernelArgs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIommuKernelArgs", reflect.TypeOf((*MockHostManagerInterface)(nil).GetIommuKernelArgs))
+}
+
+// GetIsolatedCpus mocks base method.
+func (m *MockHostManagerInterface) GetIsolatedCpus() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIsolatedCpus")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIsolatedCpus indicates an expected call of GetIsolatedCpus.
+func (mr *MockHostManagerInterfaceMockRecorder) GetIsolatedCpus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIsolatedCpus", reflect.TypeOf((*MockHostManagerInterface)(nil).GetIsolatedCpus))
+}
+
 // GetLinkType mocks base method.
 func (m *MockHostManagerInterface) GetLinkType(ifaceStatus v1.InterfaceExt) string {
 	m.ctrl.T.Helper()
@@ -296,6 +411,63 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetLinkType(ifaceStatus interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkType", reflect.TypeOf((*MockHostManagerInterface)(nil).GetLinkType), ifaceStatus)
 }
 
+// GetNICs mocks base method.
+func (m *MockHostManagerInterface) GetNICs() ([]*ghw.NIC, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNICs")
+	ret0, _ := ret[0].([]*ghw.NIC)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNICs indicates an expected call of GetNICs.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNICs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNICs", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNICs))
+}
+
+// GetNetDevDriverVersion mocks base method.
+func (m *MockHostManagerInterface) GetNetDevDriverVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevDriverVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevDriverVersion indicates an expected call of GetNetDevDriverVersion.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevDriverVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevDriverVersion", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevDriverVersion), name)
+}
+
+// GetNetDevFirmwareVersion mocks base method.
+func (m *MockHostManagerInterface) GetNetDevFirmwareVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevFirmwareVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevFirmwareVersion indicates an expected call of GetNetDevFirmwareVersion.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevFirmwareVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevFirmwareVersion", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevFirmwareVersion), name)
+}
+
+// GetNetDevHwTimestamping mocks base method.
+func (m *MockHostManagerInterface) GetNetDevHwTimestamping(ifaceName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevHwTimestamping", ifaceName)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevHwTimestamping indicates an expected call of GetNetDevHwTimestamping.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevHwTimestamping(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevHwTimestamping", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevHwTimestamping), ifaceName)
+}
+
 // GetNetDevLinkSpeed mocks base method.
 func (m *MockHostManagerInterface) GetNetDevLinkSpeed(name string) string {
 	m.ctrl.T.Helper()
@@ -310,6 +482,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevLinkSpeed(name interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevLinkSpeed", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevLinkSpeed), name)
 }
 
+// GetNetDevLinkState mocks base method.
+func (m *MockHostManagerInterface) GetNetDevLinkState(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevLinkState", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevLinkState indicates an expected call of GetNetDevLinkState.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevLinkState(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevLinkState", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevLinkState), name)
+}
+
 // GetNetDevMac mocks base method.
 func (m *MockHostManagerInterface) GetNetDevMac(name string) string {
 	m.ctrl.T.Helper()
@@ -324,6 +510,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevMac(name interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevMac", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevMac), name)
 }
 
+// GetNetDevQos mocks base method.
+func (m *MockHostManagerInterface) GetNetDevQos(name string) *v1.QosConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevQos", name)
+	ret0, _ := ret[0].(*v1.QosConfig)
+	return ret0
+}
+
+// GetNetDevQos indicates an expected call of GetNetDevQos.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevQos(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevQos", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevQos), name)
+}
+
 // GetNetdevMTU mocks base method.
 func (m *MockHostManagerInterface) GetNetdevMTU(pciAddr string) int {
 	m.ctrl.T.Helper()
@@ -353,6 +553,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetNicSriovMode(pciAddr interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNicSriovMode", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNicSriovMode), pciAddr)
 }
 
+// GetNumHugepages mocks base method.
+func (m *MockHostManagerInterface) GetNumHugepages() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNumHugepages")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNumHugepages indicates an expected call of GetNumHugepages.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNumHugepages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNumHugepages", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNumHugepages))
+}
+
 // GetOSPrettyName mocks base method.
 func (m *MockHostManagerInterface) GetOSPrettyName() (string, error) {
 	m.ctrl.T.Helper()
@@ -368,6 +583,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetOSPrettyName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOSPrettyName", reflect.TypeOf((*MockHostManagerInterface)(nil).GetOSPrettyName))
 }
 
+// GetPCIDevices mocks base method.
+func (m *MockHostManagerInterface) GetPCIDevices() ([]*ghw.PCIDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPCIDevices")
+	ret0, _ := ret[0].([]*ghw.PCIDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPCIDevices indicates an expected call of GetPCIDevices.
+func (mr *MockHostManagerInterfaceMockRecorder) GetPCIDevices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPCIDevices", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPCIDevices))
+}
+
 // GetPhysPortName mocks base method.
 func (m *MockHostManagerInterface) GetPhysPortName(name string) (string, error) {
 	m.ctrl.T.Helper()
@@ -398,6 +628,51 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetPhysSwitchID(name interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysSwitchID", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPhysSwitchID), name)
 }
 
+// GetPtpInfo mocks base method.
+func (m *MockHostManagerInterface) GetPtpInfo(name string) *v1.PtpInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPtpInfo", name)
+	ret0, _ := ret[0].(*v1.PtpInfo)
+	return ret0
+}
+
+// GetPtpInfo indicates an expected call of GetPtpInfo.
+func (mr *MockHostManagerInterfaceMockRecorder) GetPtpInfo(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPtpInfo", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPtpInfo), name)
+}
+
+// GetSplitPorts mocks base method.
+func (m *MockHostManagerInterface) GetSplitPorts(pciAddr string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSplitPorts", pciAddr)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSplitPorts indicates an expected call of GetSplitPorts.
+func (mr *MockHostManagerInterfaceMockRecorder) GetSplitPorts(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSplitPorts", reflect.TypeOf((*MockHostManagerInterface)(nil).GetSplitPorts), pciAddr)
+}
+
+// GetTransceiver mocks base method.
+func (m *MockHostManagerInterface) GetTransceiver(name string) (*v1.TransceiverInfo, *types.TransceiverDiagnostics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransceiver", name)
+	ret0, _ := ret[0].(*v1.TransceiverInfo)
+	ret1, _ := ret[1].(*types.TransceiverDiagnostics)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTransceiver indicates an expected call of GetTransceiver.
+func (mr *MockHostManagerInterfaceMockRecorder) GetTransceiver(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransceiver", reflect.TypeOf((*MockHostManagerInterface)(nil).GetTransceiver), name)
+}
+
 // GetVfInfo mocks base method.
 func (m *MockHostManagerInterface) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) v1.VirtualFunction {
 	m.ctrl.T.Helper()
@@ -427,6 +702,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) HasDriver(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDriver", reflect.TypeOf((*MockHostManagerInterface)(nil).HasDriver), pciAddr)
 }
 
+// HasSriovCapablePfs mocks base method.
+func (m *MockHostManagerInterface) HasSriovCapablePfs() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSriovCapablePfs")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSriovCapablePfs indicates an expected call of HasSriovCapablePfs.
+func (mr *MockHostManagerInterfaceMockRecorder) HasSriovCapablePfs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSriovCapablePfs", reflect.TypeOf((*MockHostManagerInterface)(nil).HasSriovCapablePfs))
+}
+
 // InstallRDMA mocks base method.
 func (m *MockHostManagerInterface) InstallRDMA(packageManager string) error {
 	m.ctrl.T.Helper()
@@ -441,6 +731,18 @@ func (mr *MockHostManagerInterfaceMockRecorder) InstallRDMA(packageManager inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallRDMA", reflect.TypeOf((*MockHostManagerInterface)(nil).InstallRDMA), packageManager)
 }
 
+// InvalidateInventory mocks base method.
+func (m *MockHostManagerInterface) InvalidateInventory() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateInventory")
+}
+
+// InvalidateInventory indicates an expected call of InvalidateInventory.
+func (mr *MockHostManagerInterfaceMockRecorder) InvalidateInventory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateInventory", reflect.TypeOf((*MockHostManagerInterface)(nil).InvalidateInventory))
+}
+
 // IsCoreOS mocks base method.
 func (m *MockHostManagerInterface) IsCoreOS() (bool, error) {
 	m.ctrl.T.Helper()
@@ -558,6 +860,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) IsSwitchdev(name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSwitchdev", reflect.TypeOf((*MockHostManagerInterface)(nil).IsSwitchdev), name)
 }
 
+// IsTransceiverPresent mocks base method.
+func (m *MockHostManagerInterface) IsTransceiverPresent(name string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTransceiverPresent", name)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsTransceiverPresent indicates an expected call of IsTransceiverPresent.
+func (mr *MockHostManagerInterfaceMockRecorder) IsTransceiverPresent(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTransceiverPresent", reflect.TypeOf((*MockHostManagerInterface)(nil).IsTransceiverPresent), name)
+}
+
 // IsUbuntuSystem mocks base method.
 func (m *MockHostManagerInterface) IsUbuntuSystem() (bool, error) {
 	m.ctrl.T.Helper()
@@ -573,6 +889,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) IsUbuntuSystem() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUbuntuSystem", reflect.TypeOf((*MockHostManagerInterface)(nil).IsUbuntuSystem))
 }
 
+// KernelModuleOptionsApplied mocks base method.
+func (m *MockHostManagerInterface) KernelModuleOptionsApplied(module string, options map[string]string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KernelModuleOptionsApplied", module, options)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KernelModuleOptionsApplied indicates an expected call of KernelModuleOptionsApplied.
+func (mr *MockHostManagerInterfaceMockRecorder) KernelModuleOptionsApplied(module, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KernelModuleOptionsApplied", reflect.TypeOf((*MockHostManagerInterface)(nil).KernelModuleOptionsApplied), module, options)
+}
+
 // LoadKernelModule mocks base method.
 func (m *MockHostManagerInterface) LoadKernelModule(name string, args ...string) error {
 	m.ctrl.T.Helper()
@@ -709,6 +1040,25 @@ func (mr *MockHostManagerInterfaceMockRecorder) ReloadDriver(driver interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadDriver", reflect.TypeOf((*MockHostManagerInterface)(nil).ReloadDriver), driver)
 }
 
+// ReloadKernelModules mocks base method.
+func (m *MockHostManagerInterface) ReloadKernelModules(modules ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range modules {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadKernelModules", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadKernelModules indicates an expected call of ReloadKernelModules.
+func (mr *MockHostManagerInterfaceMockRecorder) ReloadKernelModules(modules ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{}, modules...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadKernelModules", reflect.TypeOf((*MockHostManagerInterface)(nil).ReloadKernelModules), varargs...)
+}
+
 // RemoveFromService mocks base method.
 func (m *MockHostManagerInterface) RemoveFromService(service *types.Service, options ...*unit.UnitOption) (*types.Service, error) {
 	m.ctrl.T.Helper()
@@ -729,6 +1079,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) RemoveFromService(service interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromService", reflect.TypeOf((*MockHostManagerInterface)(nil).RemoveFromService), varargs...)
 }
 
+// RemovePfRenameUdevRule mocks base method.
+func (m *MockHostManagerInterface) RemovePfRenameUdevRule(pfPciAddress string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePfRenameUdevRule", pfPciAddress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePfRenameUdevRule indicates an expected call of RemovePfRenameUdevRule.
+func (mr *MockHostManagerInterfaceMockRecorder) RemovePfRenameUdevRule(pfPciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePfRenameUdevRule", reflect.TypeOf((*MockHostManagerInterface)(nil).RemovePfRenameUdevRule), pfPciAddress)
+}
+
 // RemoveUdevRule mocks base method.
 func (m *MockHostManagerInterface) RemoveUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -757,6 +1121,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) RemoveVfRepresentorUdevRule(pfPc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveVfRepresentorUdevRule", reflect.TypeOf((*MockHostManagerInterface)(nil).RemoveVfRepresentorUdevRule), pfPciAddress)
 }
 
+// RenameNetDev mocks base method.
+func (m *MockHostManagerInterface) RenameNetDev(name, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameNetDev", name, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameNetDev indicates an expected call of RenameNetDev.
+func (mr *MockHostManagerInterfaceMockRecorder) RenameNetDev(name, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameNetDev", reflect.TypeOf((*MockHostManagerInterface)(nil).RenameNetDev), name, newName)
+}
+
 // ResetSriovDevice mocks base method.
 func (m *MockHostManagerInterface) ResetSriovDevice(ifaceStatus v1.InterfaceExt) error {
 	m.ctrl.T.Helper()
@@ -771,6 +1149,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) ResetSriovDevice(ifaceStatus int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSriovDevice", reflect.TypeOf((*MockHostManagerInterface)(nil).ResetSriovDevice), ifaceStatus)
 }
 
+// SetCongestionControl mocks base method.
+func (m *MockHostManagerInterface) SetCongestionControl(pciAddr, pfName string, cc *v1.CongestionControlConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCongestionControl", pciAddr, pfName, cc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCongestionControl indicates an expected call of SetCongestionControl.
+func (mr *MockHostManagerInterfaceMockRecorder) SetCongestionControl(pciAddr, pfName, cc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCongestionControl", reflect.TypeOf((*MockHostManagerInterface)(nil).SetCongestionControl), pciAddr, pfName, cc)
+}
+
+// SetNetDevQos mocks base method.
+func (m *MockHostManagerInterface) SetNetDevQos(name string, qos *v1.QosConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNetDevQos", name, qos)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNetDevQos indicates an expected call of SetNetDevQos.
+func (mr *MockHostManagerInterfaceMockRecorder) SetNetDevQos(name, qos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNetDevQos", reflect.TypeOf((*MockHostManagerInterface)(nil).SetNetDevQos), name, qos)
+}
+
 // SetNetdevMTU mocks base method.
 func (m *MockHostManagerInterface) SetNetdevMTU(pciAddr string, mtu int) error {
 	m.ctrl.T.Helper()
@@ -799,6 +1205,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) SetNicSriovMode(pciAddr, mode in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNicSriovMode", reflect.TypeOf((*MockHostManagerInterface)(nil).SetNicSriovMode), pciAddr, mode)
 }
 
+// SetPortSplit mocks base method.
+func (m *MockHostManagerInterface) SetPortSplit(pciAddr string, count int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPortSplit", pciAddr, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPortSplit indicates an expected call of SetPortSplit.
+func (mr *MockHostManagerInterfaceMockRecorder) SetPortSplit(pciAddr, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPortSplit", reflect.TypeOf((*MockHostManagerInterface)(nil).SetPortSplit), pciAddr, count)
+}
+
 // SetSriovNumVfs mocks base method.
 func (m *MockHostManagerInterface) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	m.ctrl.T.Helper()
@@ -841,6 +1261,62 @@ func (mr *MockHostManagerInterfaceMockRecorder) SetVfGUID(vfAddr, pfLink interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVfGUID", reflect.TypeOf((*MockHostManagerInterface)(nil).SetVfGUID), vfAddr, pfLink)
 }
 
+// SyncFlowRules mocks base method.
+func (m *MockHostManagerInterface) SyncFlowRules(pfName string, rules []v1.FlowRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncFlowRules", pfName, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncFlowRules indicates an expected call of SyncFlowRules.
+func (mr *MockHostManagerInterfaceMockRecorder) SyncFlowRules(pfName, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncFlowRules", reflect.TypeOf((*MockHostManagerInterface)(nil).SyncFlowRules), pfName, rules)
+}
+
+// SyncPolicing mocks base method.
+func (m *MockHostManagerInterface) SyncPolicing(pfName string, policing map[int]*v1.PolicingConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncPolicing", pfName, policing)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncPolicing indicates an expected call of SyncPolicing.
+func (mr *MockHostManagerInterfaceMockRecorder) SyncPolicing(pfName, policing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPolicing", reflect.TypeOf((*MockHostManagerInterface)(nil).SyncPolicing), pfName, policing)
+}
+
+// SyncUnicastMacs mocks base method.
+func (m *MockHostManagerInterface) SyncUnicastMacs(iface *v1.Interface) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncUnicastMacs", iface)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncUnicastMacs indicates an expected call of SyncUnicastMacs.
+func (mr *MockHostManagerInterfaceMockRecorder) SyncUnicastMacs(iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncUnicastMacs", reflect.TypeOf((*MockHostManagerInterface)(nil).SyncUnicastMacs), iface)
+}
+
+// SyncVfUnicastMacs mocks base method.
+func (m *MockHostManagerInterface) SyncVfUnicastMacs(vfAddr string, macs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncVfUnicastMacs", vfAddr, macs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncVfUnicastMacs indicates an expected call of SyncVfUnicastMacs.
+func (mr *MockHostManagerInterfaceMockRecorder) SyncVfUnicastMacs(vfAddr interface{}, macs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncVfUnicastMacs", reflect.TypeOf((*MockHostManagerInterface)(nil).SyncVfUnicastMacs), vfAddr, macs)
+}
+
 // TriggerUdevEvent mocks base method.
 func (m *MockHostManagerInterface) TriggerUdevEvent() error {
 	m.ctrl.T.Helper()
@@ -993,6 +1469,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) VFIsReady(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VFIsReady", reflect.TypeOf((*MockHostManagerInterface)(nil).VFIsReady), pciAddr)
 }
 
+// WriteModprobeConf mocks base method.
+func (m *MockHostManagerInterface) WriteModprobeConf(module string, options map[string]string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteModprobeConf", module, options)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteModprobeConf indicates an expected call of WriteModprobeConf.
+func (mr *MockHostManagerInterfaceMockRecorder) WriteModprobeConf(module, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteModprobeConf", reflect.TypeOf((*MockHostManagerInterface)(nil).WriteModprobeConf), module, options)
+}
+
 // WriteSwitchdevConfFile mocks base method.
 func (m *MockHostManagerInterface) WriteSwitchdevConfFile(newState *v1.SriovNetworkNodeState, pfsToSkip map[string]bool) (bool, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +51,15 @@ type KernelInterface interface {
 	IsKernelModuleLoaded(name string) (bool, error)
 	// ReloadDriver reloads a requested driver
 	ReloadDriver(driver string) error
+	// WriteModprobeConf writes the options of the kernel module to its modprobe.d file of the host, or removes the
+	// file when there is no option, returns true if the file changed
+	WriteModprobeConf(module string, options map[string]string) (bool, error)
+	// ReloadKernelModules unloads the loaded kernel modules, given from the dependents to their dependencies, and
+	// loads them again with the options of modprobe.d
+	ReloadKernelModules(modules ...string) error
+	// KernelModuleOptionsApplied returns false if the kernel module is loaded with parameters that differ from
+	// the options
+	KernelModuleOptionsApplied(module string, options map[string]string) (bool, error)
 	// IsKernelLockdownMode returns true if the kernel is in lockdown mode
 	IsKernelLockdownMode() bool
 	// IsRHELSystem returns try if the system is a RHEL base
@@ -69,6 +78,18 @@ type KernelInterface interface {
 	EnableRDMAOnRHELMachine() (bool, error)
 	// GetOSPrettyName returns OS name
 	GetOSPrettyName() (string, error)
+	// GetIommuKernelArgs returns the kernel arguments enabling the IOMMU in passthrough mode on the architecture
+	// of the node
+	GetIommuKernelArgs() []string
+	// GetHugepageSizes returns the hugepage sizes supported by the kernel, e.g. 2048kB, from the smallest
+	GetHugepageSizes() ([]string, error)
+	// GetNumHugepages returns the number of hugepages allocated by the kernel, of all the sizes
+	GetNumHugepages() (int, error)
+	// GetIsolatedCpus returns the CPUs isolated from the kernel scheduler, e.g. 2-15, empty when none is
+	GetIsolatedCpus() (string, error)
+	// GetBindError returns the error of the last failed bind of the PCI device to a driver, the error of its probe when
+	// the kernel logged it, empty when the last bind succeeded
+	GetBindError(pciAddr string) string
 }
 
 type NetworkInterface interface {
@@ -92,6 +113,49 @@ type NetworkInterface interface {
 	GetNetDevMac(name string) string
 	// GetNetDevLinkSpeed returns the network interface link speed
 	GetNetDevLinkSpeed(name string) string
+	// GetNetDevLinkState returns "up" if the network interface reports carrier, "down" otherwise
+	GetNetDevLinkState(name string) string
+	// IsTransceiverPresent returns true if a transceiver module is plugged into the network interface
+	IsTransceiverPresent(name string) bool
+	// GetTransceiver returns the identification and the diagnostic readings read at once from the transceiver module
+	// EEPROM, the diagnostics are nil if the module doesn't report them
+	GetTransceiver(name string) (*sriovnetworkv1.TransceiverInfo, *TransceiverDiagnostics, error)
+	// GetNetDevFirmwareVersion returns the firmware version reported by the driver of the network interface
+	GetNetDevFirmwareVersion(name string) string
+	// GetNetDevDriverVersion returns the version reported by the driver of the network interface, the kernel release
+	// for the in-tree drivers
+	GetNetDevDriverVersion(name string) string
+	// SyncFlowRules installs the tc flower rules on the ingress of the PF and of the representors of its VFs,
+	// and removes the rules installed by the operator that are no longer requested
+	SyncFlowRules(pfName string, rules []sriovnetworkv1.FlowRule) error
+	// SyncPolicing installs the policing of the VFs of the PF, by VF index, on their representors and removes the
+	// policing of the other representors
+	SyncPolicing(pfName string, policing map[int]*sriovnetworkv1.PolicingConfig) error
+	// RenameNetDev renames the netdev, its link is set down for the rename and up again when it was up
+	RenameNetDev(name, newName string) error
+	// GetNetDevQos returns the trust mode, the priority flow control and the receive buffers of the interface,
+	// or nil if its driver doesn't support DCB
+	GetNetDevQos(name string) *sriovnetworkv1.QosConfig
+	// SetNetDevQos configures the trust mode, the priority flow control and the receive buffers of the interface
+	SetNetDevQos(name string, qos *sriovnetworkv1.QosConfig) error
+	// GetPtpInfo returns the PTP hardware clock of the interface and the linuxptp services using it, or nil if
+	// the interface has no PTP hardware clock
+	GetPtpInfo(name string) *sriovnetworkv1.PtpInfo
+	// GetNetDevHwTimestamping returns "enabled" if the interface timestamps the packets in hardware, "supported"
+	// if it can, or an empty string if it can't
+	GetNetDevHwTimestamping(ifaceName string) string
+	// EnableNetDevHwTimestamping enables the hardware timestamping of the packets sent and received by the interface
+	EnableNetDevHwTimestamping(ifaceName string) error
+	// GetSplitPorts returns the netdevs of the ports the physical port of the PCI device is split into, empty when it
+	// isn't split
+	GetSplitPorts(pciAddr string) ([]string, error)
+	// SetPortSplit splits the physical port of the PCI device into count ports with devlink, 1 unsplits it
+	SetPortSplit(pciAddr string, count int) error
+	// GetFirmwareFaults returns the firmware health reporters of the devlink device of the PCI device in error state
+	GetFirmwareFaults(pciAddr string) ([]string, error)
+	// SyncVfUnicastMacs adds the MACs to the unicast filter of the netdev of the VF, in the network namespace it is
+	// in, and removes the MACs previously added by the operator and no longer requested
+	SyncVfUnicastMacs(vfAddr string, macs []string) error
 }
 
 type ServiceInterface interface {
@@ -140,6 +204,8 @@ type SriovInterface interface {
 	GetLinkType(ifaceStatus sriovnetworkv1.InterfaceExt) string
 	// ResetSriovDevice resets the number of virtual function for the specific physical function to zero
 	ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error
+	// HasSriovCapablePfs returns true if a supported network device of the system is an SR-IOV capable PF
+	HasSriovCapablePfs() (bool, error)
 	// DiscoverSriovDevices returns a list of all the available SR-IOV capable network interfaces on the system
 	DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error)
 	// ConfigSriovDevice configure the request SR-IOV device with the desired configuration
@@ -149,6 +215,9 @@ type SriovInterface interface {
 		ifaceStatuses []sriovnetworkv1.InterfaceExt, pfsToConfig map[string]bool) error
 	// ConfigSriovInterfaces configure virtual functions for virtual environments with the desired configuration
 	ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error
+	// SyncUnicastMacs programs the additional unicast MACs of the VFs of the PF, the kernel flushes them when the VFs
+	// move to another network namespace
+	SyncUnicastMacs(iface *sriovnetworkv1.Interface) error
 }
 
 type UdevInterface interface {
@@ -165,6 +234,10 @@ type UdevInterface interface {
 	AddVfRepresentorUdevRule(pfPciAddress, pfName, pfSwitchID, pfSwitchPort string) error
 	// RemoveVfRepresentorUdevRule removes udev rule that renames VF representors on the concrete PF
 	RemoveVfRepresentorUdevRule(pfPciAddress string) error
+	// AddPfRenameUdevRule adds a udev rule that renames the concrete PF, or its port when portName is set
+	AddPfRenameUdevRule(pfPciAddress, portName, name string) error
+	// RemovePfRenameUdevRule removes the udev rule that renames the concrete PF
+	RemovePfRenameUdevRule(pfPciAddress string) error
 }
 
 type VdpaInterface interface {
@@ -176,3 +249,19 @@ type VdpaInterface interface {
 	// returns empty string if VDPA device not found or unknown driver is in use
 	DiscoverVDPAType(pciAddr string) string
 }
+
+type CongestionControlInterface interface {
+	// GetCongestionControl returns the ECN and DCQCN settings of the PF, or nil if its driver doesn't expose them
+	GetCongestionControl(pciAddr, pfName string) *sriovnetworkv1.CongestionControlConfig
+	// SetCongestionControl applies the ECN and DCQCN settings to the PF
+	SetCongestionControl(pciAddr, pfName string, cc *sriovnetworkv1.CongestionControlConfig) error
+}
+
+type InventoryInterface interface {
+	// GetPCIDevices returns the PCI devices of the host, served from a cache when possible
+	GetPCIDevices() ([]*ghw.PCIDevice, error)
+	// GetNICs returns the network interfaces of the host, served from a cache when possible
+	GetNICs() ([]*ghw.NIC, error)
+	// InvalidateInventory drops the cached hardware inventory so the next call rescans the host
+	InvalidateInventory()
+}
@@ -41,3 +41,13 @@ var (
 		Value:   "!/etc/ignition-machine-config-encapsulated.json",
 	}
 )
+
+// TransceiverDiagnostics are the digital diagnostic monitoring (DDM) readings of the module plugged into a PF
+type TransceiverDiagnostics struct {
+	// Temperature is the temperature of the module in degrees Celsius
+	Temperature float64
+	// TxPower is the transmit optical power of each lane in mW
+	TxPower []float64
+	// RxPower is the receive optical power of each lane in mW
+	RxPower []float64
+}
@@ -0,0 +1,247 @@
+// Package watch turns the kernel uevents and the netlink link notifications of the host
+// into typed events, so the config daemon can react to hardware changes instead of
+// polling sysfs.
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// resubscribeDelay is the delay before subscribing again to the link notifications once the subscription ended
+const resubscribeDelay = time.Second
+
+type EventType string
+
+const (
+	// DeviceAdded a PCI device, e.g. a VF, was added
+	DeviceAdded EventType = "DeviceAdded"
+	// DeviceRemoved a PCI device was removed
+	DeviceRemoved EventType = "DeviceRemoved"
+	// DeviceChanged the attributes of a PCI device changed
+	DeviceChanged EventType = "DeviceChanged"
+	// DriverBound a driver was bound to a PCI device
+	DriverBound EventType = "DriverBound"
+	// DriverUnbound a driver was unbound from a PCI device
+	DriverUnbound EventType = "DriverUnbound"
+	// NetdevAdded a network interface was added
+	NetdevAdded EventType = "NetdevAdded"
+	// NetdevRemoved a network interface was removed
+	NetdevRemoved EventType = "NetdevRemoved"
+	// NetdevRenamed a network interface was renamed
+	NetdevRenamed EventType = "NetdevRenamed"
+	// LinkChanged the state of a network interface changed, e.g. its operational state or MTU
+	LinkChanged EventType = "LinkChanged"
+	// Resync events were lost, the kernel dropped them when the socket buffer overflowed, the whole host is rescanned
+	Resync EventType = "Resync"
+)
+
+// Event is a change of a device or network interface of the host
+type Event struct {
+	Type EventType
+	// PciAddress of the device, set for the PCI events
+	PciAddress string
+	// Interface name of the network interface, set for the netdev and link events
+	Interface string
+}
+
+func (e Event) String() string {
+	if e.Interface != "" {
+		return fmt.Sprintf("%s %s", e.Type, e.Interface)
+	}
+	if e.PciAddress == "" {
+		return string(e.Type)
+	}
+	return fmt.Sprintf("%s %s", e.Type, e.PciAddress)
+}
+
+// Watcher publishes the host events to its subscribers
+type Watcher struct {
+	lock        sync.Mutex
+	subscribers []chan Event
+}
+
+func New() *Watcher {
+	return &Watcher{}
+}
+
+// Subscribe returns a channel receiving the events of the host. The events are dropped
+// when the channel buffer is full, subscribers are expected to coalesce them and
+// rescan the host instead of relying on each single event.
+func (w *Watcher) Subscribe(buffer int) <-chan Event {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	ch := make(chan Event, buffer)
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+func (w *Watcher) publish(e Event) {
+	log.Log.V(2).Info("publish(): host event", "event", e.String())
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (w *Watcher) closeSubscribers() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, ch := range w.subscribers {
+		close(ch)
+	}
+	w.subscribers = nil
+}
+
+// Run listens for the kernel uevents and the netlink link notifications until the stop
+// channel is closed. The subscriber channels are closed when Run returns, so the
+// subscribers can fall back to polling if the host can't be watched.
+func (w *Watcher) Run(stop <-chan struct{}) error {
+	defer w.closeSubscribers()
+	fd, err := openUeventSocket()
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-stop
+		// unblocks the pending Recvfrom
+		unix.Close(fd)
+	}()
+
+	links, err := subscribeLinks(stop)
+	if err != nil {
+		return fmt.Errorf("Run(): failed to subscribe to link updates: %v", err)
+	}
+	go w.watchLinks(links, stop)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+			}
+			if err == unix.EINTR {
+				continue
+			}
+			if err == unix.ENOBUFS {
+				// the kernel dropped uevents, the state of the devices they reported is unknown
+				log.Log.Info("Run(): uevents lost, the host is rescanned")
+				w.publish(Event{Type: Resync})
+				continue
+			}
+			return fmt.Errorf("Run(): failed to read uevent: %v", err)
+		}
+		if e, ok := parseUevent(buf[:n]); ok {
+			w.publish(e)
+		}
+	}
+}
+
+// subscribeLinks subscribes to the netlink link notifications until the stop channel is closed, the channel is closed
+// when the subscription ends
+func subscribeLinks(stop <-chan struct{}) (chan netlink.LinkUpdate, error) {
+	links := make(chan netlink.LinkUpdate, 100)
+	err := netlink.LinkSubscribeWithOptions(links, stop, netlink.LinkSubscribeOptions{
+		ErrorCallback: func(err error) {
+			log.Log.V(2).Info("subscribeLinks(): link notifications error", "error", err)
+		},
+	})
+	return links, err
+}
+
+// watchLinks publishes the link notifications until the stop channel is closed. The subscription ends when the
+// kernel drops notifications as the socket buffer overflowed, a resync is published and the links are subscribed to
+// again.
+func (w *Watcher) watchLinks(links chan netlink.LinkUpdate, stop <-chan struct{}) {
+	for {
+		for update := range links {
+			w.publish(Event{Type: LinkChanged, Interface: update.Attrs().Name})
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(resubscribeDelay):
+			}
+			var err error
+			if links, err = subscribeLinks(stop); err == nil {
+				// the changes of the links are caught by the rescan once subscribed again
+				log.Log.Info("watchLinks(): link notifications lost, the host is rescanned")
+				w.publish(Event{Type: Resync})
+				break
+			}
+			log.Log.Error(err, "watchLinks(): failed to subscribe to link updates again")
+		}
+	}
+}
+
+func openUeventSocket() (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return -1, fmt.Errorf("openUeventSocket(): failed to open uevent socket: %v", err)
+	}
+	// the kernel multicast group
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1}); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("openUeventSocket(): failed to bind uevent socket: %v", err)
+	}
+	return fd, nil
+}
+
+// parseUevent converts a raw kernel uevent of the pci or net subsystem to an event
+func parseUevent(msg []byte) (Event, bool) {
+	env := map[string]string{}
+	for _, field := range bytes.Split(msg, []byte{0}) {
+		if k, v, found := strings.Cut(string(field), "="); found {
+			env[k] = v
+		}
+	}
+
+	switch env["SUBSYSTEM"] {
+	case "pci":
+		e := Event{PciAddress: env["PCI_SLOT_NAME"]}
+		switch env["ACTION"] {
+		case "add":
+			e.Type = DeviceAdded
+		case "remove":
+			e.Type = DeviceRemoved
+		case "change":
+			e.Type = DeviceChanged
+		case "bind":
+			e.Type = DriverBound
+		case "unbind":
+			e.Type = DriverUnbound
+		default:
+			return Event{}, false
+		}
+		return e, true
+	case "net":
+		e := Event{Interface: env["INTERFACE"]}
+		switch env["ACTION"] {
+		case "add":
+			e.Type = NetdevAdded
+		case "remove":
+			e.Type = NetdevRemoved
+		case "move":
+			e.Type = NetdevRenamed
+		default:
+			return Event{}, false
+		}
+		return e, true
+	}
+	return Event{}, false
+}
@@ -0,0 +1,51 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func uevent(fields ...string) []byte {
+	return []byte(strings.Join(fields, "\x00") + "\x00")
+}
+
+func TestParseUevent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	e, ok := parseUevent(uevent("add@/devices/pci0000:d7/0000:d8:02.0", "ACTION=add", "SUBSYSTEM=pci", "PCI_SLOT_NAME=0000:d8:02.0"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(e).To(Equal(Event{Type: DeviceAdded, PciAddress: "0000:d8:02.0"}))
+
+	e, ok = parseUevent(uevent("unbind@/devices/pci0000:d7/0000:d8:02.0", "ACTION=unbind", "SUBSYSTEM=pci", "PCI_SLOT_NAME=0000:d8:02.0"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(e).To(Equal(Event{Type: DriverUnbound, PciAddress: "0000:d8:02.0"}))
+
+	e, ok = parseUevent(uevent("move@/devices/pci0000:d7/0000:d8:02.0/net/ens1f0v0", "ACTION=move", "SUBSYSTEM=net", "INTERFACE=ens1f0v0"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(e).To(Equal(Event{Type: NetdevRenamed, Interface: "ens1f0v0"}))
+
+	_, ok = parseUevent(uevent("add@/devices/virtual/block/loop0", "ACTION=add", "SUBSYSTEM=block"))
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = parseUevent(uevent("online@/devices/pci0000:d7/0000:d8:02.0", "ACTION=online", "SUBSYSTEM=pci"))
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestPublish(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	w := New()
+	ch := w.Subscribe(1)
+	w.publish(Event{Type: DeviceAdded, PciAddress: "0000:d8:02.0"})
+	// the buffer is full, the event is dropped instead of blocking the watcher
+	w.publish(Event{Type: DeviceAdded, PciAddress: "0000:d8:02.1"})
+	g.Expect(<-ch).To(Equal(Event{Type: DeviceAdded, PciAddress: "0000:d8:02.0"}))
+
+	w.closeSubscribers()
+	_, ok := <-ch
+	g.Expect(ok).To(BeFalse())
+	// publishing after the watcher stopped is a no-op
+	w.publish(Event{Type: DeviceRemoved, PciAddress: "0000:d8:02.0"})
+}
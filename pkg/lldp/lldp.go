@@ -0,0 +1,247 @@
+package lldp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+const (
+	ethTypeLldp   = 0x88cc
+	ethHeaderLen  = 14
+	maxFrameSize  = 1518
+	readTimeout   = time.Second
+	retryInterval = 30 * time.Second
+)
+
+// nearest bridge multicast address used by LLDP agents
+var lldpMulticastAddr = [8]byte{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+
+type neighborEntry struct {
+	neighbor *sriovnetworkv1.LldpNeighbor
+	expires  time.Time
+}
+
+// Listener receives LLDP frames on the PFs of the node and keeps the
+// last neighbor advertised on each of them
+type Listener struct {
+	mu        sync.Mutex
+	enabled   bool
+	neighbors map[string]neighborEntry
+	stopChs   map[string]chan struct{}
+}
+
+func NewListener() *Listener {
+	return &Listener{
+		neighbors: map[string]neighborEntry{},
+		stopChs:   map[string]chan struct{}{},
+	}
+}
+
+// SetEnabled starts or stops the LLDP listening, when disabled all the
+// listeners are stopped and the known neighbors are discarded
+func (l *Listener) SetEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enabled == enabled {
+		return
+	}
+	log.Log.Info("LLDP listener", "enabled", enabled)
+	l.enabled = enabled
+	if !enabled {
+		for name, stopCh := range l.stopChs {
+			close(stopCh)
+			delete(l.stopChs, name)
+		}
+		l.neighbors = map[string]neighborEntry{}
+	}
+}
+
+// UpdateInterfaces makes sure a listener is running for each of the named interfaces,
+// stops the listeners of interfaces that are gone and sets the LLDP neighbor of the
+// interfaces from the last received frame
+func (l *Listener) UpdateInterfaces(ifaces []sriovnetworkv1.InterfaceExt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled {
+		return
+	}
+
+	current := map[string]bool{}
+	for i := range ifaces {
+		name := ifaces[i].Name
+		if name == "" {
+			continue
+		}
+		current[name] = true
+		if _, ok := l.stopChs[name]; !ok {
+			stopCh := make(chan struct{})
+			l.stopChs[name] = stopCh
+			go l.listen(name, stopCh)
+		}
+		if entry, ok := l.neighbors[name]; ok {
+			if time.Now().After(entry.expires) {
+				delete(l.neighbors, name)
+				continue
+			}
+			ifaces[i].LldpNeighbor = entry.neighbor.DeepCopy()
+		}
+	}
+
+	for name, stopCh := range l.stopChs {
+		if !current[name] {
+			close(stopCh)
+			delete(l.stopChs, name)
+			delete(l.neighbors, name)
+		}
+	}
+}
+
+func (l *Listener) setNeighbor(ifaceName string, neighbor *sriovnetworkv1.LldpNeighbor, ttl time.Duration, stopCh chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// the listener may have been stopped while the frame was processed
+	if l.stopChs[ifaceName] != stopCh {
+		return
+	}
+	l.neighbors[ifaceName] = neighborEntry{neighbor: neighbor, expires: time.Now().Add(ttl)}
+}
+
+func (l *Listener) listen(ifaceName string, stopCh chan struct{}) {
+	log.Log.V(2).Info("listen(): start LLDP listener", "device", ifaceName)
+	for {
+		err := l.receive(ifaceName, stopCh)
+		if err == nil {
+			log.Log.V(2).Info("listen(): stop LLDP listener", "device", ifaceName)
+			return
+		}
+		log.Log.V(2).Info("listen(): LLDP listener failed, retrying", "device", ifaceName, "error", err)
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// receive reads LLDP frames from the interface until stopCh is closed
+func (l *Listener) receive(ifaceName string, stopCh chan struct{}) error {
+	netIface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return err
+	}
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(htons(ethTypeLldp)))
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: htons(ethTypeLldp), Ifindex: netIface.Index}); err != nil {
+		return err
+	}
+	mreq := &unix.PacketMreq{
+		Ifindex: int32(netIface.Index),
+		Type:    unix.PACKET_MR_MULTICAST,
+		Alen:    6,
+		Address: lldpMulticastAddr,
+	}
+	if err := unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, mreq); err != nil {
+		return err
+	}
+	tv := unix.NsecToTimeval(readTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return err
+	}
+
+	buf := make([]byte, maxFrameSize)
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		default:
+		}
+
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				continue
+			}
+			return err
+		}
+		if n <= ethHeaderLen {
+			continue
+		}
+		neighbor, ttl, err := ParseFrame(buf[ethHeaderLen:n])
+		if err != nil {
+			log.Log.V(2).Info("receive(): failed to parse LLDP frame", "device", ifaceName, "error", err)
+			continue
+		}
+		l.setNeighbor(ifaceName, neighbor, ttl, stopCh)
+	}
+}
+
+// ParseFrame decodes the LLDPDU (the ethernet payload) and returns the neighbor and its time to live
+func ParseFrame(data []byte) (*sriovnetworkv1.LldpNeighbor, time.Duration, error) {
+	neighbor := &sriovnetworkv1.LldpNeighbor{}
+	var ttl time.Duration
+	for len(data) >= 2 {
+		header := binary.BigEndian.Uint16(data)
+		tlvType := header >> 9
+		tlvLen := int(header & 0x1ff)
+		data = data[2:]
+		if tlvLen > len(data) {
+			return nil, 0, fmt.Errorf("truncated TLV type %d", tlvType)
+		}
+		value := data[:tlvLen]
+		data = data[tlvLen:]
+
+		switch tlvType {
+		case 0:
+			// end of LLDPDU
+			data = nil
+		case 1:
+			neighbor.ChassisID = decodeID(value, 4)
+		case 2:
+			neighbor.PortID = decodeID(value, 3)
+		case 3:
+			if tlvLen >= 2 {
+				ttl = time.Duration(binary.BigEndian.Uint16(value)) * time.Second
+			}
+		case 4:
+			neighbor.PortDescription = string(value)
+		case 5:
+			neighbor.SystemName = string(value)
+		}
+	}
+	if neighbor.ChassisID == "" || neighbor.PortID == "" {
+		return nil, 0, fmt.Errorf("mandatory chassis ID or port ID TLV is missing")
+	}
+	return neighbor, ttl, nil
+}
+
+// decodeID decodes a chassis or port ID TLV value, macSubtype is the subtype
+// identifying a MAC address
+func decodeID(value []byte, macSubtype byte) string {
+	if len(value) < 2 {
+		return ""
+	}
+	if value[0] == macSubtype && len(value) == 7 {
+		return net.HardwareAddr(value[1:]).String()
+	}
+	return string(value[1:])
+}
+
+// htons converts a short from host to network byte order
+func htons(v uint16) uint16 {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return binary.NativeEndian.Uint16(b)
+}
@@ -0,0 +1,59 @@
+package lldp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func tlv(tlvType int, value ...byte) []byte {
+	header := tlvType<<9 | len(value)
+	return append([]byte{byte(header >> 8), byte(header)}, value...)
+}
+
+func TestParseFrame(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var frame []byte
+	frame = append(frame, tlv(1, 4, 0x00, 0x1c, 0x73, 0x01, 0x02, 0x03)...)
+	frame = append(frame, tlv(2, append([]byte{5}, []byte("Ethernet12")...)...)...)
+	frame = append(frame, tlv(3, 0x00, 0x78)...)
+	frame = append(frame, tlv(4, []byte("server-42 port 1")...)...)
+	frame = append(frame, tlv(5, []byte("tor-a1")...)...)
+	frame = append(frame, tlv(0)...)
+
+	neighbor, ttl, err := ParseFrame(frame)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ttl).To(Equal(120 * time.Second))
+	g.Expect(neighbor).To(Equal(&sriovnetworkv1.LldpNeighbor{
+		ChassisID:       "00:1c:73:01:02:03",
+		PortID:          "Ethernet12",
+		PortDescription: "server-42 port 1",
+		SystemName:      "tor-a1",
+	}))
+}
+
+func TestParseFrameErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// missing port ID
+	_, _, err := ParseFrame(append(tlv(1, 7, 'a'), tlv(0)...))
+	g.Expect(err).To(HaveOccurred())
+
+	// TLV length exceeds the frame
+	_, _, err = ParseFrame([]byte{0x02, 0x10, 0x01})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestListenerDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	l := NewListener()
+	ifaces := []sriovnetworkv1.InterfaceExt{{Name: "eth0"}}
+	l.UpdateInterfaces(ifaces)
+	g.Expect(l.stopChs).To(BeEmpty())
+	g.Expect(ifaces[0].LldpNeighbor).To(BeNil())
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DaemonPhaseDiscovery is the discovery of the SR-IOV devices of the node
+	DaemonPhaseDiscovery = "discovery"
+	// DaemonPhaseOnNodeStateChange is the evaluation of the new node state by the plugins
+	DaemonPhaseOnNodeStateChange = "on_node_state_change"
+	// DaemonPhaseDrain is the drain of the node
+	DaemonPhaseDrain = "drain"
+	// DaemonPhaseApply is the application of the configuration by the plugins
+	DaemonPhaseApply = "apply"
+)
+
+var (
+	daemonRegistry = prometheus.NewRegistry()
+
+	daemonPhaseDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_config_daemon_phase_duration_seconds",
+		Help: "Duration of the last run of each config daemon phase",
+	}, []string{"phase"})
+	daemonStartupDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sriov_config_daemon_startup_duration_seconds",
+		Help: "Time between the config daemon start and its first successful sync",
+	})
+	pfConfigDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sriov_config_daemon_pf_config_duration_seconds",
+		Help:    "Duration of the configuration of a PF and its VFs",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	})
+	vfBindRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_config_daemon_vf_bind_retries_total",
+		Help: "Number of attempts to bind again the VFs left without driver, by result",
+	}, []string{"result"})
+	nodeStateStatusUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_config_daemon_node_state_status_updates_total",
+		Help: "Number of refreshes of the SriovNetworkNodeState status, written or skipped without change",
+	}, []string{"result"})
+)
+
+func init() {
+	daemonRegistry.MustRegister(daemonPhaseDuration, daemonStartupDuration, pfConfigDuration, vfBindRetries,
+		nodeStateStatusUpdates, virtualDevices, virtualMetadataFetchFailures, transceiverTemperature, transceiverTxPower,
+		transceiverRxPower)
+}
+
+// ObserveDaemonPhase records the duration of a config daemon phase started at start
+func ObserveDaemonPhase(phase string, start time.Time) {
+	daemonPhaseDuration.WithLabelValues(phase).Set(time.Since(start).Seconds())
+}
+
+// ObserveDaemonStartup records the time the config daemon started at start needed to complete its first sync
+func ObserveDaemonStartup(start time.Time) {
+	daemonStartupDuration.Set(time.Since(start).Seconds())
+}
+
+// ObservePfConfig records the duration of the configuration of a PF started at start
+func ObservePfConfig(start time.Time) {
+	pfConfigDuration.Observe(time.Since(start).Seconds())
+}
+
+// IncVfBindRetries counts an attempt to bind again a VF left without driver, failed when err is not nil
+func IncVfBindRetries(err error) {
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	vfBindRetries.WithLabelValues(result).Inc()
+}
+
+// IncNodeStateStatusUpdates counts a refresh of the node state status, skipped when it changed nothing
+func IncNodeStateStatusUpdates(skipped bool) {
+	result := "written"
+	if skipped {
+		result = "skipped"
+	}
+	nodeStateStatusUpdates.WithLabelValues(result).Inc()
+}
+
+// ServeDaemonMetrics exposes the config daemon metrics on addr until the stop channel is closed
+func ServeDaemonMetrics(addr string, stop <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(daemonRegistry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-stop
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Log.Error(err, "ServeDaemonMetrics(): failed to stop the metrics server")
+		}
+	}()
+
+	log.Log.V(0).Info("ServeDaemonMetrics(): serving metrics", "address", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Log.Error(err, "ServeDaemonMetrics(): metrics server failed")
+	}
+}
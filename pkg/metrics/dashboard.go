@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ResourcePool is a resource of the device plugin shown in the dashboard and watched by the alert rules
+type ResourcePool struct {
+	// Name is the resourceName of the policies
+	Name string
+	// Resource is the value of the resource label of the kube-state-metrics series of the extended resource
+	Resource string
+}
+
+var invalidLabelValueChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// NewResourcePool returns the pool of the extended resource, kube-state-metrics exports the extended resources
+// with the characters other than letters, digits and underscores replaced by underscores
+func NewResourcePool(name string, extendedResourceName corev1.ResourceName) ResourcePool {
+	return ResourcePool{Name: name, Resource: invalidLabelValueChars.ReplaceAllString(string(extendedResourceName), "_")}
+}
+
+// PoolUtilizationExpr returns the PromQL expression of the ratio of the VFs of the pool requested by the pods
+func (p ResourcePool) PoolUtilizationExpr() string {
+	return fmt.Sprintf(`sum(kube_pod_container_resource_requests{resource="%s"}) / sum(kube_node_status_allocatable{resource="%s"})`,
+		p.Resource, p.Resource)
+}
+
+type dashboardTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+type dashboardGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type dashboardPanel struct {
+	ID         int               `json:"id"`
+	Title      string            `json:"title"`
+	Type       string            `json:"type"`
+	Datasource string            `json:"datasource"`
+	GridPos    dashboardGridPos  `json:"gridPos"`
+	Targets    []dashboardTarget `json:"targets"`
+}
+
+// GrafanaDashboard returns the Grafana dashboard of the operator: the utilization of each resource pool, the sync
+// status of the nodes and the health of the PFs, built from the metrics of the operator and of kube-state-metrics
+func GrafanaDashboard(pools []ResourcePool) (string, error) {
+	panels := []dashboardPanel{}
+	addPanel := func(title, panelType string, width int, targets ...dashboardTarget) {
+		x, y := 0, 0
+		if len(panels) > 0 {
+			last := panels[len(panels)-1].GridPos
+			x, y = last.X+last.W, last.Y
+			if x+width > 24 {
+				x, y = 0, last.Y+last.H
+			}
+		}
+		panels = append(panels, dashboardPanel{
+			ID:         len(panels) + 1,
+			Title:      title,
+			Type:       panelType,
+			Datasource: "${datasource}",
+			GridPos:    dashboardGridPos{H: 8, W: width, X: x, Y: y},
+			Targets:    targets,
+		})
+	}
+
+	addPanel("Nodes by sync status", "stat", 12,
+		dashboardTarget{Expr: `sum by (status) (sriov_node_state_sync_status)`, LegendFormat: "{{status}}"})
+	addPanel("Nodes not in sync", "table", 12,
+		dashboardTarget{Expr: `sriov_node_state_sync_status{status!="Succeeded"} == 1`, LegendFormat: "{{node}}"})
+	for _, pool := range pools {
+		addPanel(fmt.Sprintf("Utilization of the %s pool", pool.Name), "timeseries", 12,
+			dashboardTarget{Expr: pool.PoolUtilizationExpr(), LegendFormat: pool.Name},
+			dashboardTarget{Expr: fmt.Sprintf(`sum by (node) (kube_node_status_allocatable{resource="%s"})`, pool.Resource),
+				LegendFormat: "allocatable {{node}}"})
+	}
+	addPanel("Degraded PCIe links", "table", 12,
+		dashboardTarget{Expr: `sriov_pf_pcie_link_degraded == 1`, LegendFormat: "{{node}} {{pf}}"})
+	addPanel("Drifted objects", "timeseries", 12,
+		dashboardTarget{Expr: `sum by (kind) (sriov_operator_drifted_objects)`, LegendFormat: "{{kind}}"})
+
+	dashboard := map[string]interface{}{
+		"uid":           "sriov-network-operator",
+		"title":         "SR-IOV Network Operator",
+		"schemaVersion": 38,
+		"refresh":       "1m",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{{"name": "datasource", "type": "datasource", "query": "prometheus"}},
+		},
+		"panels": panels,
+	}
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var driftedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "sriov_operator_drifted_objects",
+	Help: "Number of objects rendered by the operator which were modified out of band",
+}, []string{"kind"})
+
+// SetDriftedObjects records the number of rendered objects of the kind which drifted from their recorded content
+func SetDriftedObjects(kind string, count int) {
+	driftedObjects.WithLabelValues(kind).Set(float64(count))
+}
@@ -0,0 +1,13 @@
+package metrics
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// RegisterOperatorMetrics registers the operator collectors in the controller-runtime
+// registry, they are served by the manager metrics endpoint
+func RegisterOperatorMetrics(c client.Reader, namespace string) {
+	crmetrics.Registry.MustRegister(newNodeStateCollector(c, namespace))
+	crmetrics.Registry.MustRegister(soakTestStepDuration, soakTestFailures, driftedObjects, webhookCertificateExpiration)
+}
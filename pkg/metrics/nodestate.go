@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+var (
+	pfLabels = []string{"node", "pf", "pci_address"}
+
+	transceiverInfoDesc = prometheus.NewDesc(
+		"sriov_pf_transceiver_info",
+		"Identification of the transceiver module plugged into the PF",
+		append(pfLabels, "vendor", "part_number", "serial_number"), nil)
+	pcieLinkWidthDesc = prometheus.NewDesc(
+		"sriov_pf_pcie_link_width_lanes",
+		"Number of lanes of the PCIe link of the PF",
+		pfLabels, nil)
+	pcieLinkMaxWidthDesc = prometheus.NewDesc(
+		"sriov_pf_pcie_link_max_width_lanes",
+		"Number of lanes the PF supports on its PCIe link",
+		pfLabels, nil)
+	pcieLinkDegradedDesc = prometheus.NewDesc(
+		"sriov_pf_pcie_link_degraded",
+		"Whether the PCIe link of the PF trained below the speed or the width the PF supports",
+		append(pfLabels, "speed", "max_speed"), nil)
+	vfsUnboundDesc = prometheus.NewDesc(
+		"sriov_pf_vfs_unbound",
+		"Number of VFs of the PF bound to no driver",
+		pfLabels, nil)
+	syncStatusDesc = prometheus.NewDesc(
+		"sriov_node_state_sync_status",
+		"Sync status of the SriovNetworkNodeState of the node, 1 for its current status and 0 for the others",
+		[]string{"node", "status"}, nil)
+
+	syncStatuses = []string{consts.SyncStatusSucceeded, consts.SyncStatusInProgress, consts.SyncStatusFailed}
+)
+
+// nodeStateCollector exports the PF telemetry reported by the config daemons
+// in the SriovNetworkNodeState objects, the live transceiver readings are exported
+// by the config daemons, see SetTransceiverDiagnostics
+type nodeStateCollector struct {
+	client    client.Reader
+	namespace string
+}
+
+func newNodeStateCollector(c client.Reader, namespace string) *nodeStateCollector {
+	return &nodeStateCollector{client: c, namespace: namespace}
+}
+
+func (c *nodeStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- transceiverInfoDesc
+	ch <- pcieLinkWidthDesc
+	ch <- pcieLinkMaxWidthDesc
+	ch <- pcieLinkDegradedDesc
+	ch <- vfsUnboundDesc
+	ch <- syncStatusDesc
+}
+
+func (c *nodeStateCollector) Collect(ch chan<- prometheus.Metric) {
+	nodeStates := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := c.client.List(context.Background(), nodeStates, client.InNamespace(c.namespace)); err != nil {
+		log.Log.Error(err, "nodeStateCollector: failed to list SriovNetworkNodeStates")
+		return
+	}
+
+	for _, ns := range nodeStates.Items {
+		c.collectSyncStatus(ch, &ns)
+		for _, iface := range ns.Status.Interfaces {
+			if iface.PcieLink != nil {
+				c.collectPcieLink(ch, ns.Name, &iface)
+			}
+			if iface.NumVfs > 0 {
+				c.collectVfsUnbound(ch, ns.Name, &iface)
+			}
+			if iface.Transceiver == nil {
+				continue
+			}
+			c.collectTransceiver(ch, ns.Name, &iface)
+		}
+	}
+}
+
+func (c *nodeStateCollector) collectSyncStatus(ch chan<- prometheus.Metric, ns *sriovnetworkv1.SriovNetworkNodeState) {
+	if ns.Status.SyncStatus == "" {
+		return
+	}
+	for _, status := range syncStatuses {
+		value := 0.0
+		if ns.Status.SyncStatus == status {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(syncStatusDesc, prometheus.GaugeValue, value, ns.Name, status)
+	}
+}
+
+func (c *nodeStateCollector) collectTransceiver(ch chan<- prometheus.Metric, node string, iface *sriovnetworkv1.InterfaceExt) {
+	t := iface.Transceiver
+	ch <- prometheus.MustNewConstMetric(transceiverInfoDesc, prometheus.GaugeValue, 1,
+		node, iface.Name, iface.PciAddress, t.Vendor, t.PartNumber, t.SerialNumber)
+}
+
+func (c *nodeStateCollector) collectPcieLink(ch chan<- prometheus.Metric, node string, iface *sriovnetworkv1.InterfaceExt) {
+	l := iface.PcieLink
+	ch <- prometheus.MustNewConstMetric(pcieLinkWidthDesc, prometheus.GaugeValue, float64(l.Width),
+		node, iface.Name, iface.PciAddress)
+	ch <- prometheus.MustNewConstMetric(pcieLinkMaxWidthDesc, prometheus.GaugeValue, float64(l.MaxWidth),
+		node, iface.Name, iface.PciAddress)
+	degraded := 0.0
+	if l.Degraded {
+		degraded = 1
+	}
+	ch <- prometheus.MustNewConstMetric(pcieLinkDegradedDesc, prometheus.GaugeValue, degraded,
+		node, iface.Name, iface.PciAddress, l.Speed, l.MaxSpeed)
+}
+
+func (c *nodeStateCollector) collectVfsUnbound(ch chan<- prometheus.Metric, node string, iface *sriovnetworkv1.InterfaceExt) {
+	unbound := 0
+	for _, vf := range iface.GetVirtualFunctions() {
+		if vf.Driver == "" {
+			unbound++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(vfsUnboundDesc, prometheus.GaugeValue, float64(unbound),
+		node, iface.Name, iface.PciAddress)
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// VirtualPlatformOpenstack is the platform label of the OpenStack virtual machines
+	VirtualPlatformOpenstack = "openstack"
+
+	// VirtualDevicesMetadata are the devices found in the metadata of the platform
+	VirtualDevicesMetadata = "metadata"
+	// VirtualDevicesMacFallback are the devices of the metadata whose PCI address was resolved from their MAC
+	// address because the PCI address of the metadata didn't match the one seen by the virtual machine
+	VirtualDevicesMacFallback = "mac_fallback"
+	// VirtualDevicesSkipped are the network devices missing from the metadata of the platform
+	VirtualDevicesSkipped = "skipped"
+	// VirtualDevicesUnmanaged are the network devices missing from the metadata reported as unmanaged
+	VirtualDevicesUnmanaged = "unmanaged"
+)
+
+var (
+	virtualDevices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_config_daemon_virtual_devices",
+		Help: "Number of devices of the virtual platform by result of the last discovery",
+	}, []string{"platform", "result"})
+	virtualMetadataFetchFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_config_daemon_virtual_metadata_fetch_failures_total",
+		Help: "Number of failed reads of the metadata of the virtual platform by source",
+	}, []string{"platform", "source"})
+)
+
+// SetVirtualDevices records the number of devices of the virtual platform with the result of the last discovery
+func SetVirtualDevices(platform, result string, count int) {
+	virtualDevices.WithLabelValues(platform, result).Set(float64(count))
+}
+
+// IncVirtualMetadataFetchFailures counts a failed read of the metadata of the virtual platform from source
+func IncVirtualMetadataFetchFailures(platform, source string) {
+	virtualMetadataFetchFailures.WithLabelValues(platform, source).Inc()
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	soakTestStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sriov_soak_test_step_duration_seconds",
+		Help:    "Duration of the apply and remove steps of the soak mode scratch policy",
+		Buckets: []float64{30, 60, 120, 300, 600, 900, 1200, 1800, 3600},
+	}, []string{"step"})
+	soakTestFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_soak_test_failures_total",
+		Help: "Number of failed steps of the soak mode scratch policy",
+	}, []string{"step"})
+)
+
+// ObserveSoakTestStep records the duration of a completed soak mode step
+func ObserveSoakTestStep(step string, d time.Duration) {
+	soakTestStepDuration.WithLabelValues(step).Observe(d.Seconds())
+}
+
+// IncSoakTestFailures counts a failed soak mode step
+func IncSoakTestFailures(step string) {
+	soakTestFailures.WithLabelValues(step).Inc()
+}
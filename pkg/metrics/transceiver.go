@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+var (
+	transceiverTemperature = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_pf_transceiver_temperature_celsius",
+		Help: "Temperature of the transceiver module plugged into the PF",
+	}, []string{"node", "pf", "pci_address"})
+	transceiverTxPower = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_pf_transceiver_tx_power_milliwatts",
+		Help: "Transmit optical power of the transceiver module lane",
+	}, []string{"node", "pf", "pci_address", "lane"})
+	transceiverRxPower = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_pf_transceiver_rx_power_milliwatts",
+		Help: "Receive optical power of the transceiver module lane",
+	}, []string{"node", "pf", "pci_address", "lane"})
+)
+
+// SetTransceiverDiagnostics replaces the readings of the transceiver modules of the PFs of the node with the
+// diagnostics read at the last discovery, by PCI address. They change at each discovery and are exported by the
+// config daemon instead of being reported in the SriovNetworkNodeState status.
+func SetTransceiverDiagnostics(node string, pfs []sriovnetworkv1.InterfaceExt, diagnostics map[string]*types.TransceiverDiagnostics) {
+	transceiverTemperature.Reset()
+	transceiverTxPower.Reset()
+	transceiverRxPower.Reset()
+	for _, pf := range pfs {
+		d, ok := diagnostics[pf.PciAddress]
+		if !ok || d == nil {
+			continue
+		}
+		transceiverTemperature.WithLabelValues(node, pf.Name, pf.PciAddress).Set(d.Temperature)
+		for lane, power := range d.TxPower {
+			transceiverTxPower.WithLabelValues(node, pf.Name, pf.PciAddress, strconv.Itoa(lane)).Set(power)
+		}
+		for lane, power := range d.RxPower {
+			transceiverRxPower.WithLabelValues(node, pf.Name, pf.PciAddress, strconv.Itoa(lane)).Set(power)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var webhookCertificateExpiration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "sriov_webhook_certificate_expiration_timestamp_seconds",
+	Help: "Expiration time of the serving certificate and of the CA of the webhooks, in seconds since the epoch",
+}, []string{"webhook", "certificate"})
+
+// SetWebhookCertificateExpiration records the expiration time of a certificate of a webhook
+func SetWebhookCertificateExpiration(webhook, certificate string, notAfter time.Time) {
+	webhookCertificateExpiration.WithLabelValues(webhook, certificate).Set(float64(notAfter.Unix()))
+}
+
+// DeleteWebhookCertificateExpiration forgets the certificate of a webhook no longer found
+func DeleteWebhookCertificateExpiration(webhook, certificate string) {
+	webhookCertificateExpiration.DeleteLabelValues(webhook, certificate)
+}
@@ -0,0 +1,119 @@
+package nmstate
+
+import (
+	"strconv"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// NetworkState is the network state of a node in the nmstate schema, restricted to the SR-IOV devices of the node
+type NetworkState struct {
+	Interfaces []InterfaceState `json:"interfaces"`
+}
+
+// InterfaceState is an interface of the nmstate schema
+type InterfaceState struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// State is up when the link of the interface is up, down when it is down and unknown when it isn't reported
+	State      string         `json:"state"`
+	MacAddress string         `json:"mac-address,omitempty"`
+	Mtu        int            `json:"mtu,omitempty"`
+	Ethernet   *EthernetState `json:"ethernet,omitempty"`
+}
+
+// EthernetState is the ethernet section of an interface of the nmstate schema
+type EthernetState struct {
+	// Speed in Mb/s
+	Speed int         `json:"speed,omitempty"`
+	SrIov *SrIovState `json:"sr-iov,omitempty"`
+}
+
+// SrIovState is the sr-iov section of a PF of the nmstate schema
+type SrIovState struct {
+	TotalVfs int       `json:"total-vfs"`
+	Vfs      []VfState `json:"vfs,omitempty"`
+}
+
+// VfState is a VF of the sr-iov section of a PF of the nmstate schema
+type VfState struct {
+	ID         int    `json:"id"`
+	MacAddress string `json:"mac-address,omitempty"`
+	VlanID     int    `json:"vlan-id,omitempty"`
+}
+
+// Export translates the status of the node state to the nmstate schema. The PFs are reported as ethernet interfaces
+// with their VFs in their sr-iov section, and the VFs bound to a kernel driver as ethernet interfaces of their own.
+// The devices without netdev, e.g. bound to vfio-pci, have no name in the nmstate schema, they are left out. The VFs
+// of a compact node state are not reported individually, only their number is exported.
+func Export(status *sriovnetworkv1.SriovNetworkNodeStateStatus) *NetworkState {
+	state := &NetworkState{Interfaces: []InterfaceState{}}
+	vfInterfaces := []InterfaceState{}
+	for _, iface := range status.Interfaces {
+		if iface.Name == "" {
+			continue
+		}
+		pf := InterfaceState{
+			Name:       iface.Name,
+			Type:       "ethernet",
+			State:      linkState(iface.LinkState),
+			MacAddress: strings.ToUpper(iface.Mac),
+			Mtu:        iface.Mtu,
+			Ethernet:   &EthernetState{Speed: linkSpeed(iface.LinkSpeed)},
+		}
+		if iface.TotalVfs > 0 {
+			pf.Ethernet.SrIov = &SrIovState{TotalVfs: iface.NumVfs}
+		}
+		for _, vf := range iface.VFs {
+			if pf.Ethernet.SrIov != nil {
+				pf.Ethernet.SrIov.Vfs = append(pf.Ethernet.SrIov.Vfs, VfState{
+					ID:         vf.VfID,
+					MacAddress: strings.ToUpper(vf.Mac),
+					VlanID:     vf.Vlan,
+				})
+			}
+			if vf.Name == "" {
+				continue
+			}
+			vfInterfaces = append(vfInterfaces, InterfaceState{
+				Name:       vf.Name,
+				Type:       "ethernet",
+				State:      "unknown",
+				MacAddress: strings.ToUpper(vf.Mac),
+				Mtu:        vf.Mtu,
+			})
+		}
+		if pf.Ethernet.Speed == 0 && pf.Ethernet.SrIov == nil {
+			pf.Ethernet = nil
+		}
+		state.Interfaces = append(state.Interfaces, pf)
+	}
+	state.Interfaces = append(state.Interfaces, vfInterfaces...)
+	return state
+}
+
+// linkState returns the nmstate state of the link state of the node state
+func linkState(state string) string {
+	switch state {
+	case consts.LinkStateUp:
+		return "up"
+	case consts.LinkStateDown:
+		return "down"
+	}
+	return "unknown"
+}
+
+// linkSpeed returns the speed in Mb/s of the link speed of the node state, e.g. "25000 Mb/s", 0 when unknown
+func linkSpeed(speed string) int {
+	value, found := strings.CutSuffix(strings.TrimSpace(speed), "Mb/s")
+	if !found {
+		return 0
+	}
+	mbps, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || mbps < 0 {
+		return 0
+	}
+	return mbps
+}
@@ -0,0 +1,71 @@
+package nmstate
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func TestExport(t *testing.T) {
+	g := NewGomegaWithT(t)
+	status := &sriovnetworkv1.SriovNetworkNodeStateStatus{
+		Interfaces: sriovnetworkv1.InterfaceExts{
+			{
+				Name: "ens1f0", PciAddress: "0000:3b:00.0", Mac: "b8:59:9f:01:02:03", Mtu: 9000, LinkState: "up",
+				LinkSpeed: "25000 Mb/s", TotalVfs: 64, NumVfs: 2,
+				VFs: []sriovnetworkv1.VirtualFunction{
+					{VfID: 0, PciAddress: "0000:3b:00.2", Name: "ens1f0v0", Mac: "aa:bb:cc:dd:ee:00", Mtu: 9000, Vlan: 100},
+					{VfID: 1, PciAddress: "0000:3b:00.3", Mac: "aa:bb:cc:dd:ee:01", Driver: "vfio-pci"},
+				},
+			},
+			{Name: "ens1f1", PciAddress: "0000:3b:00.1", Mac: "b8:59:9f:01:02:04", Mtu: 1500, LinkState: "down"},
+			{PciAddress: "0000:d8:00.0", Driver: "vfio-pci"},
+		},
+	}
+
+	state := Export(status)
+	out, err := yaml.Marshal(state)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(out)).To(MatchYAML(`
+interfaces:
+- name: ens1f0
+  type: ethernet
+  state: up
+  mac-address: B8:59:9F:01:02:03
+  mtu: 9000
+  ethernet:
+    speed: 25000
+    sr-iov:
+      total-vfs: 2
+      vfs:
+      - id: 0
+        mac-address: AA:BB:CC:DD:EE:00
+        vlan-id: 100
+      - id: 1
+        mac-address: AA:BB:CC:DD:EE:01
+- name: ens1f1
+  type: ethernet
+  state: down
+  mac-address: B8:59:9F:01:02:04
+  mtu: 1500
+- name: ens1f0v0
+  type: ethernet
+  state: unknown
+  mac-address: AA:BB:CC:DD:EE:00
+  mtu: 9000
+`))
+
+	// the exported interfaces are read back as the interfaces of a desired state
+	obj := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal(out, &obj)).To(Succeed())
+	nncp := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"desiredState": obj}}}
+	interfaces, err := Interfaces(nncp)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(interfaces).To(HaveLen(3))
+	g.Expect(interfaces[0].Mtu).To(Equal(9000))
+	g.Expect(interfaces[0].TotalVfs).To(HaveValue(Equal(2)))
+}
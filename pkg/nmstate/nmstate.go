@@ -0,0 +1,156 @@
+// Package nmstate cross-validates the SriovNetworkNodePolicies with the NodeNetworkConfigurationPolicies of
+// kubernetes-nmstate when the PF level settings are delegated to it, see the nmstateIntegration feature gate. The
+// SR-IOV operator then only manages the VFs. The package also exports the SR-IOV network state of a node in the
+// nmstate schema.
+package nmstate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+var (
+	// PolicyGVR is the resource of the NodeNetworkConfigurationPolicies
+	PolicyGVR = schema.GroupVersionResource{Group: "nmstate.io", Version: "v1", Resource: "nodenetworkconfigurationpolicies"}
+	// PolicyGVK is the kind of the NodeNetworkConfigurationPolicies
+	PolicyGVK = schema.GroupVersionKind{Group: "nmstate.io", Version: "v1", Kind: "NodeNetworkConfigurationPolicy"}
+)
+
+// Interface is the part of an interface of the desired state of a NodeNetworkConfigurationPolicy the SR-IOV
+// operator depends on
+type Interface struct {
+	Name string
+	Type string
+	Mtu  int
+	// TotalVfs is set when the NodeNetworkConfigurationPolicy configures the VFs of the interface
+	TotalVfs *int
+	// Ports are the ports of a bond
+	Ports []string
+}
+
+// Interfaces returns the interfaces of the desired state of the NodeNetworkConfigurationPolicy
+func Interfaces(nncp *unstructured.Unstructured) ([]Interface, error) {
+	items, _, err := unstructured.NestedSlice(nncp.Object, "spec", "desiredState", "interfaces")
+	if err != nil {
+		return nil, fmt.Errorf("invalid interfaces in NodeNetworkConfigurationPolicy %s: %v", nncp.GetName(), err)
+	}
+	interfaces := []Interface{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		iface := Interface{}
+		iface.Name, _, _ = unstructured.NestedString(obj, "name")
+		iface.Type, _, _ = unstructured.NestedString(obj, "type")
+		if mtu, found := nestedInt(obj, "mtu"); found {
+			iface.Mtu = mtu
+		}
+		if totalVfs, found := nestedInt(obj, "ethernet", "sr-iov", "total-vfs"); found {
+			iface.TotalVfs = &totalVfs
+		}
+		iface.Ports, _, _ = unstructured.NestedStringSlice(obj, "link-aggregation", "port")
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces, nil
+}
+
+// nestedInt returns the integer field of the object, decoded from JSON as an int64 or a float64
+func nestedInt(obj map[string]interface{}, fields ...string) (int, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil || !found {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// ValidatePolicy checks that the NodeNetworkConfigurationPolicies selecting the nodes of the policy configure its PFs
+// as the policy expects. It returns an error when a NodeNetworkConfigurationPolicy configures the VFs of a PF of the
+// policy, or sets the MTU of the PF, directly or through its bond, below the MTU of the policy, and warnings for the
+// MTU of the policy no NodeNetworkConfigurationPolicy sets. Only the PFs selected by name are checked.
+func ValidatePolicy(policy *sriovnetworkv1.SriovNetworkNodePolicy, nncps []unstructured.Unstructured) ([]string, error) {
+	pfNames := map[string]bool{}
+	for _, name := range policy.Spec.NicSelector.PfNames {
+		pfNames[strings.Split(name, "#")[0]] = true
+	}
+	if len(pfNames) == 0 {
+		return nil, nil
+	}
+
+	mtuSet := map[string]bool{}
+	for i := range nncps {
+		nncp := &nncps[i]
+		nodeSelector, _, _ := unstructured.NestedStringMap(nncp.Object, "spec", "nodeSelector")
+		if !selectorsOverlap(nodeSelector, policy.Spec.NodeSelector) {
+			continue
+		}
+		interfaces, err := Interfaces(nncp)
+		if err != nil {
+			return nil, err
+		}
+		for _, iface := range interfaces {
+			pfs := []string{}
+			if pfNames[iface.Name] {
+				pfs = append(pfs, iface.Name)
+				if iface.TotalVfs != nil {
+					return nil, fmt.Errorf("the VFs of %s are configured by NodeNetworkConfigurationPolicy %s, "+
+						"they can't be configured by SriovNetworkNodePolicy %s too", iface.Name, nncp.GetName(), policy.Name)
+				}
+			}
+			// the ports of a bond take its MTU
+			for _, port := range iface.Ports {
+				if pfNames[port] {
+					pfs = append(pfs, port)
+				}
+			}
+			if iface.Mtu == 0 {
+				continue
+			}
+			for _, pf := range pfs {
+				mtuSet[pf] = true
+				if policy.Spec.Mtu > iface.Mtu {
+					return nil, fmt.Errorf("the MTU %d of SriovNetworkNodePolicy %s is above the MTU %d set on %s by "+
+						"NodeNetworkConfigurationPolicy %s", policy.Spec.Mtu, policy.Name, iface.Mtu, pf, nncp.GetName())
+				}
+			}
+		}
+	}
+
+	if policy.Spec.Mtu == 0 {
+		return nil, nil
+	}
+	unset := []string{}
+	for pf := range pfNames {
+		if !mtuSet[pf] {
+			unset = append(unset, pf)
+		}
+	}
+	if len(unset) == 0 {
+		return nil, nil
+	}
+	sort.Strings(unset)
+	return []string{fmt.Sprintf("the MTU of the PFs is set by kubernetes-nmstate, no NodeNetworkConfigurationPolicy "+
+		"sets the MTU %d of SriovNetworkNodePolicy %s on %s", policy.Spec.Mtu, policy.Name, strings.Join(unset, ", "))}, nil
+}
+
+// selectorsOverlap returns whether a node may be selected by both node selectors
+func selectorsOverlap(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, ok := b[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
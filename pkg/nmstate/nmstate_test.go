@@ -0,0 +1,120 @@
+package nmstate
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func newNNCP(t *testing.T, manifest string) unstructured.Unstructured {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil {
+		t.Fatal(err)
+	}
+	return unstructured.Unstructured{Object: obj}
+}
+
+func TestInterfaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+	nncp := newNNCP(t, `
+metadata:
+  name: bond0
+spec:
+  desiredState:
+    interfaces:
+    - name: ens1f0
+      type: ethernet
+      mtu: 9000
+      ethernet:
+        sr-iov:
+          total-vfs: 4
+    - name: bond0
+      type: bond
+      mtu: 1500
+      link-aggregation:
+        mode: active-backup
+        port: [ens1f1, ens2f1]
+`)
+	interfaces, err := Interfaces(&nncp)
+	g.Expect(err).ToNot(HaveOccurred())
+	vfs := 4
+	g.Expect(interfaces).To(Equal([]Interface{
+		{Name: "ens1f0", Type: "ethernet", Mtu: 9000, TotalVfs: &vfs},
+		{Name: "bond0", Type: "bond", Mtu: 1500, Ports: []string{"ens1f1", "ens2f1"}},
+	}))
+}
+
+func TestValidatePolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	nncps := []unstructured.Unstructured{
+		newNNCP(t, `
+metadata:
+  name: jumbo
+spec:
+  nodeSelector:
+    pool: tenant
+  desiredState:
+    interfaces:
+    - name: ens1f0
+      type: ethernet
+      mtu: 9000
+    - name: bond0
+      type: bond
+      mtu: 1500
+      link-aggregation:
+        port: [ens1f1]
+`),
+		newNNCP(t, `
+metadata:
+  name: infra-vfs
+spec:
+  nodeSelector:
+    pool: infra
+  desiredState:
+    interfaces:
+    - name: ens2f0
+      type: ethernet
+      ethernet:
+        sr-iov:
+          total-vfs: 8
+`),
+	}
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{}
+	policy.Name = "tenant"
+	policy.Spec.NodeSelector = map[string]string{"pool": "tenant"}
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0#0-3"}
+	policy.Spec.Mtu = 9000
+
+	warnings, err := ValidatePolicy(policy, nncps)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(BeEmpty())
+
+	// the ports of a bond take its MTU
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0", "ens1f1"}
+	_, err = ValidatePolicy(policy, nncps)
+	g.Expect(err).To(MatchError(ContainSubstring("above the MTU 1500 set on ens1f1 by NodeNetworkConfigurationPolicy jumbo")))
+
+	// the VFs can't be configured by both operators, on the nodes selected by both policies only
+	policy.Spec.NicSelector.PfNames = []string{"ens2f0"}
+	policy.Spec.Mtu = 0
+	g.Expect(ValidatePolicy(policy, nncps)).To(BeEmpty())
+	policy.Spec.NodeSelector = map[string]string{"pool": "infra"}
+	_, err = ValidatePolicy(policy, nncps)
+	g.Expect(err).To(MatchError(ContainSubstring("the VFs of ens2f0 are configured by NodeNetworkConfigurationPolicy infra-vfs")))
+
+	// the MTU of the policy is expected to be set by kubernetes-nmstate
+	policy.Spec.NodeSelector = map[string]string{"pool": "tenant"}
+	policy.Spec.NicSelector.PfNames = []string{"ens3f0"}
+	policy.Spec.Mtu = 9000
+	warnings, err = ValidatePolicy(policy, nncps)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(ConsistOf(ContainSubstring("no NodeNetworkConfigurationPolicy sets the MTU 9000 of SriovNetworkNodePolicy tenant on ens3f0")))
+
+	// the PFs selected by vendor and device are not checked
+	policy.Spec.NicSelector.PfNames = nil
+	g.Expect(ValidatePolicy(policy, nncps)).To(BeEmpty())
+}
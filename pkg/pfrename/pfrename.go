@@ -0,0 +1,70 @@
+// Package pfrename maintains the rename map of the node, keeping the original names of the PFs renamed by the
+// pfNamePrefix of their policies. A renamed PF gets its new name from a udev rule when the node boots, the original
+// name is only known from the map: it is reported in the node state for the pfNames of the nicSelectors, and given
+// back to the PF when no policy renames it anymore.
+package pfrename
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// Rename is a renamed PF
+type Rename struct {
+	OriginalName string `json:"originalName"`
+	Name         string `json:"name"`
+}
+
+// Load returns the renamed PFs of the node by PCI address
+func Load() (map[string]Rename, error) {
+	renames := map[string]Rename{}
+	data, err := os.ReadFile(utils.GetHostExtensionPath(consts.PfRenamesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return renames, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the PF rename map: %v", err)
+	}
+	if err := json.Unmarshal(data, &renames); err != nil {
+		return nil, fmt.Errorf("failed to parse the PF rename map: %v", err)
+	}
+	return renames, nil
+}
+
+// Save writes the renamed PFs of the node, and removes the rename map when no PF is renamed
+func Save(renames map[string]Rename) error {
+	path := utils.GetHostExtensionPath(consts.PfRenamesFile)
+	if len(renames) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the PF rename map: %v", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(renames, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the PF rename map: %v", err)
+	}
+	return nil
+}
+
+// OriginalNames returns the original names of the renamed PFs of the node by PCI address, empty when the map can't
+// be read
+func OriginalNames() map[string]string {
+	names := map[string]string{}
+	renames, err := Load()
+	if err != nil {
+		return names
+	}
+	for pciAddress, rename := range renames {
+		names[pciAddress] = rename.OriginalName
+	}
+	return names
+}
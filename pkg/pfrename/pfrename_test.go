@@ -0,0 +1,38 @@
+package pfrename
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func TestSaveLoad(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root, clean, err := (&fakefilesystem.FS{Dirs: []string{consts.SriovConfBasePath}}).Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+
+	renames, err := Load()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(renames).To(BeEmpty())
+
+	renames["0000:3b:00.0"] = Rename{OriginalName: "ens1f0", Name: "sriov3b00f0"}
+	g.Expect(Save(renames)).To(Succeed())
+	g.Expect(Load()).To(Equal(renames))
+	g.Expect(OriginalNames()).To(Equal(map[string]string{"0000:3b:00.0": "ens1f0"}))
+
+	g.Expect(Save(map[string]Rename{})).To(Succeed())
+	g.Expect(filepath.Join(root, consts.PfRenamesFile)).ToNot(BeAnExistingFile())
+}
@@ -50,6 +50,21 @@ func (mr *MockInterfaceMockRecorder) CreateOpenstackDevicesInfo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOpenstackDevicesInfo", reflect.TypeOf((*MockInterface)(nil).CreateOpenstackDevicesInfo))
 }
 
+// CreateOpenstackDevicesInfoFromCheckpoint mocks base method.
+func (m *MockInterface) CreateOpenstackDevicesInfoFromCheckpoint() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOpenstackDevicesInfoFromCheckpoint")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOpenstackDevicesInfoFromCheckpoint indicates an expected call of CreateOpenstackDevicesInfoFromCheckpoint.
+func (mr *MockInterfaceMockRecorder) CreateOpenstackDevicesInfoFromCheckpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOpenstackDevicesInfoFromCheckpoint", reflect.TypeOf((*MockInterface)(nil).CreateOpenstackDevicesInfoFromCheckpoint))
+}
+
 // CreateOpenstackDevicesInfoFromNodeStatus mocks base method.
 func (m *MockInterface) CreateOpenstackDevicesInfoFromNodeStatus(arg0 *v1.SriovNetworkNodeState) {
 	m.ctrl.T.Helper()
@@ -77,6 +92,62 @@ func (mr *MockInterfaceMockRecorder) DiscoverSriovDevicesVirtual() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverSriovDevicesVirtual", reflect.TypeOf((*MockInterface)(nil).DiscoverSriovDevicesVirtual))
 }
 
+// GetOpenstackMetadataErrors mocks base method.
+func (m *MockInterface) GetOpenstackMetadataErrors() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMetadataErrors")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackMetadataErrors indicates an expected call of GetOpenstackMetadataErrors.
+func (mr *MockInterfaceMockRecorder) GetOpenstackMetadataErrors() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMetadataErrors", reflect.TypeOf((*MockInterface)(nil).GetOpenstackMetadataErrors))
+}
+
+// GetOpenstackMetadataProvenance mocks base method.
+func (m *MockInterface) GetOpenstackMetadataProvenance() *v1.MetadataProvenance {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMetadataProvenance")
+	ret0, _ := ret[0].(*v1.MetadataProvenance)
+	return ret0
+}
+
+// GetOpenstackMetadataProvenance indicates an expected call of GetOpenstackMetadataProvenance.
+func (mr *MockInterfaceMockRecorder) GetOpenstackMetadataProvenance() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMetadataProvenance", reflect.TypeOf((*MockInterface)(nil).GetOpenstackMetadataProvenance))
+}
+
+// GetOpenstackMtuMismatches mocks base method.
+func (m *MockInterface) GetOpenstackMtuMismatches() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMtuMismatches")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackMtuMismatches indicates an expected call of GetOpenstackMtuMismatches.
+func (mr *MockInterfaceMockRecorder) GetOpenstackMtuMismatches() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMtuMismatches", reflect.TypeOf((*MockInterface)(nil).GetOpenstackMtuMismatches))
+}
+
+// GetOpenstackUnresolvedDevices mocks base method.
+func (m *MockInterface) GetOpenstackUnresolvedDevices() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackUnresolvedDevices")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackUnresolvedDevices indicates an expected call of GetOpenstackUnresolvedDevices.
+func (mr *MockInterfaceMockRecorder) GetOpenstackUnresolvedDevices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackUnresolvedDevices", reflect.TypeOf((*MockInterface)(nil).GetOpenstackUnresolvedDevices))
+}
+
 // GetFlavor mocks base method.
 func (m *MockInterface) GetFlavor() openshift.OpenshiftFlavor {
 	m.ctrl.T.Helper()
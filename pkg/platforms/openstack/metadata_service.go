@@ -0,0 +1,67 @@
+package openstack
+
+import (
+	"fmt"
+	"net"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ospMetaDataIPv6Address is the link-local IPv6 address of the metadata service on the IPv6-only networks
+const ospMetaDataIPv6Address = "fe80::a9fe:a9fe"
+
+// hostInterface is a network interface of the host and its addresses
+type hostInterface struct {
+	name  string
+	addrs []net.IP
+}
+
+// listHostInterfaces returns the interfaces of the host that are up, except the loopback
+func listHostInterfaces() []hostInterface {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		log.Log.Error(err, "listHostInterfaces(): failed to list the network interfaces")
+		return nil
+	}
+	hostInterfaces := []hostInterface{}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			log.Log.Error(err, "listHostInterfaces(): failed to list the addresses", "interface", iface.Name)
+			continue
+		}
+		hostInterface := hostInterface{name: iface.Name}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				hostInterface.addrs = append(hostInterface.addrs, ipNet.IP)
+			}
+		}
+		hostInterfaces = append(hostInterfaces, hostInterface)
+	}
+	return hostInterfaces
+}
+
+// metadataServiceBaseURL returns the base URL of the metadata service reachable from the host: its IPv4 link-local
+// address, or on the IPv6-only hosts its IPv6 link-local address scoped to the first interface with an IPv6
+// link-local address
+func metadataServiceBaseURL(interfaces []hostInterface) string {
+	zone := ""
+	for _, iface := range interfaces {
+		for _, ip := range iface.addrs {
+			if ip.To4() != nil && !ip.IsLinkLocalUnicast() {
+				return ospMetaDataBaseURL
+			}
+			if zone == "" && ip.To4() == nil && ip.IsLinkLocalUnicast() {
+				zone = iface.name
+			}
+		}
+	}
+	if zone == "" {
+		return ospMetaDataBaseURL
+	}
+	log.Log.V(2).Info("metadataServiceBaseURL(): no IPv4 address on the host, using the IPv6 metadata service", "interface", zone)
+	return fmt.Sprintf("http://[%s%%25%s]%s", ospMetaDataIPv6Address, zone, ospMetaDataPath)
+}
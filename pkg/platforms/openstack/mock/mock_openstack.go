@@ -48,6 +48,21 @@ func (mr *MockOpenstackInterfaceMockRecorder) CreateOpenstackDevicesInfo() *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOpenstackDevicesInfo", reflect.TypeOf((*MockOpenstackInterface)(nil).CreateOpenstackDevicesInfo))
 }
 
+// CreateOpenstackDevicesInfoFromCheckpoint mocks base method.
+func (m *MockOpenstackInterface) CreateOpenstackDevicesInfoFromCheckpoint() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOpenstackDevicesInfoFromCheckpoint")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOpenstackDevicesInfoFromCheckpoint indicates an expected call of CreateOpenstackDevicesInfoFromCheckpoint.
+func (mr *MockOpenstackInterfaceMockRecorder) CreateOpenstackDevicesInfoFromCheckpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOpenstackDevicesInfoFromCheckpoint", reflect.TypeOf((*MockOpenstackInterface)(nil).CreateOpenstackDevicesInfoFromCheckpoint))
+}
+
 // CreateOpenstackDevicesInfoFromNodeStatus mocks base method.
 func (m *MockOpenstackInterface) CreateOpenstackDevicesInfoFromNodeStatus(arg0 *v1.SriovNetworkNodeState) {
 	m.ctrl.T.Helper()
@@ -74,3 +89,59 @@ func (mr *MockOpenstackInterfaceMockRecorder) DiscoverSriovDevicesVirtual() *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverSriovDevicesVirtual", reflect.TypeOf((*MockOpenstackInterface)(nil).DiscoverSriovDevicesVirtual))
 }
+
+// GetOpenstackMetadataErrors mocks base method.
+func (m *MockOpenstackInterface) GetOpenstackMetadataErrors() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMetadataErrors")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackMetadataErrors indicates an expected call of GetOpenstackMetadataErrors.
+func (mr *MockOpenstackInterfaceMockRecorder) GetOpenstackMetadataErrors() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMetadataErrors", reflect.TypeOf((*MockOpenstackInterface)(nil).GetOpenstackMetadataErrors))
+}
+
+// GetOpenstackMetadataProvenance mocks base method.
+func (m *MockOpenstackInterface) GetOpenstackMetadataProvenance() *v1.MetadataProvenance {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMetadataProvenance")
+	ret0, _ := ret[0].(*v1.MetadataProvenance)
+	return ret0
+}
+
+// GetOpenstackMetadataProvenance indicates an expected call of GetOpenstackMetadataProvenance.
+func (mr *MockOpenstackInterfaceMockRecorder) GetOpenstackMetadataProvenance() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMetadataProvenance", reflect.TypeOf((*MockOpenstackInterface)(nil).GetOpenstackMetadataProvenance))
+}
+
+// GetOpenstackMtuMismatches mocks base method.
+func (m *MockOpenstackInterface) GetOpenstackMtuMismatches() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackMtuMismatches")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackMtuMismatches indicates an expected call of GetOpenstackMtuMismatches.
+func (mr *MockOpenstackInterfaceMockRecorder) GetOpenstackMtuMismatches() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackMtuMismatches", reflect.TypeOf((*MockOpenstackInterface)(nil).GetOpenstackMtuMismatches))
+}
+
+// GetOpenstackUnresolvedDevices mocks base method.
+func (m *MockOpenstackInterface) GetOpenstackUnresolvedDevices() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenstackUnresolvedDevices")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetOpenstackUnresolvedDevices indicates an expected call of GetOpenstackUnresolvedDevices.
+func (mr *MockOpenstackInterfaceMockRecorder) GetOpenstackUnresolvedDevices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenstackUnresolvedDevices", reflect.TypeOf((*MockOpenstackInterface)(nil).GetOpenstackUnresolvedDevices))
+}
@@ -1,52 +1,74 @@
 package openstack
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/jaypipes/ghw"
 	"github.com/jaypipes/ghw/pkg/net"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/metrics"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
 const (
-	ospHostMetaDataDir     = "/host/var/config/openstack/2018-08-27"
-	ospMetaDataDir         = "/var/config/openstack/2018-08-27"
-	ospMetaDataBaseURL     = "http://169.254.169.254/openstack/2018-08-27"
-	ospNetworkDataJSON     = "network_data.json"
-	ospMetaDataJSON        = "meta_data.json"
-	ospHostNetworkDataFile = ospHostMetaDataDir + "/" + ospNetworkDataJSON
-	ospHostMetaDataFile    = ospHostMetaDataDir + "/" + ospMetaDataJSON
-	ospNetworkDataURL      = ospMetaDataBaseURL + "/" + ospNetworkDataJSON
-	ospMetaDataURL         = ospMetaDataBaseURL + "/" + ospMetaDataJSON
-)
-
-var (
-	ospNetworkDataFile = ospMetaDataDir + "/" + ospNetworkDataJSON
-	ospMetaDataFile    = ospMetaDataDir + "/" + ospMetaDataJSON
+	ospHostMetaDataDir = "/host/var/config/openstack/2018-08-27"
+	ospMetaDataPath    = "/openstack/2018-08-27"
+	ospMetaDataBaseURL = "http://169.254.169.254" + ospMetaDataPath
+	ospNetworkDataJSON = "network_data.json"
+	ospMetaDataJSON    = "meta_data.json"
 )
 
 //go:generate ../../../bin/mockgen -destination mock/mock_openstack.go -source openstack.go
 type OpenstackInterface interface {
 	CreateOpenstackDevicesInfo() error
 	CreateOpenstackDevicesInfoFromNodeStatus(*sriovnetworkv1.SriovNetworkNodeState)
+	CreateOpenstackDevicesInfoFromCheckpoint() (bool, error)
 	DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.InterfaceExt, error)
+	GetOpenstackMetadataErrors() []string
+	GetOpenstackUnresolvedDevices() []string
+	GetOpenstackMetadataProvenance() *sriovnetworkv1.MetadataProvenance
+	GetOpenstackMtuMismatches() []string
 }
 
 type openstackContext struct {
 	hostManager          host.HostManagerInterface
 	openStackDevicesInfo OSPDevicesInfo
+	// configDrive is the directory of the config drive mounted from the host
+	configDrive fs.FS
+	// metadataService is the endpoint of the metadata service
+	metadataService metadataService
+	// metadataErrors are the errors of the malformed entries of the metadata skipped by CreateOpenstackDevicesInfo
+	metadataErrors []string
+	// unresolvedDevices are the errors of the devices whose PCI address wasn't found by MAC address by
+	// CreateOpenstackDevicesInfo, they keep the PCI address of the metadata
+	unresolvedDevices []string
+	// provenance is where the device information was read from
+	provenance *sriovnetworkv1.MetadataProvenance
+	// mtuMismatches are the devices whose MTU differs from the MTU declared in the metadata, found by the last
+	// DiscoverSriovDevicesVirtual
+	mtuMismatches []string
 }
 
 // OSPMetaDataDevice -- Device structure within meta_data.json
@@ -78,6 +100,11 @@ type OSPNetworkLink struct {
 	Type        string `json:"type"`
 	Mtu         int    `json:"mtu,omitempty"`
 	EthernetMac string `json:"ethernet_mac_address"`
+	// VlanLink is the ID of the parent link of a vlan link, the vlan links of a link are the subports of its
+	// trunk port
+	VlanLink string `json:"vlan_link,omitempty"`
+	VlanID   int    `json:"vlan_id,omitempty"`
+	VlanMac  string `json:"vlan_mac_address,omitempty"`
 }
 
 // OSPNetwork OSP Network metadata
@@ -95,28 +122,154 @@ type OSPNetworkData struct {
 	// Omit Services
 }
 
+// ospInt is an integer of the OpenStack metadata, some clouds emit the integers as strings, e.g. "mtu": "1500"
+type ospInt int
+
+// UnmarshalJSON accepts a JSON number or a string holding an integer
+func (i *ospInt) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = strings.TrimSpace(unquoted)
+	}
+	if value == "" || value == "null" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = ospInt(n)
+	return nil
+}
+
+// UnmarshalJSON decodes a device of meta_data.json, the VLAN may be a string
+func (d *OSPMetaDataDevice) UnmarshalJSON(data []byte) error {
+	type device OSPMetaDataDevice
+	aux := struct {
+		*device
+		Vlan ospInt `json:"vlan,omitempty"`
+	}{device: (*device)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Vlan = int(aux.Vlan)
+	return nil
+}
+
+// UnmarshalJSON decodes a link of network_data.json, the MTU and the VLAN ID may be strings
+func (l *OSPNetworkLink) UnmarshalJSON(data []byte) error {
+	type link OSPNetworkLink
+	aux := struct {
+		*link
+		Mtu    ospInt `json:"mtu,omitempty"`
+		VlanID ospInt `json:"vlan_id,omitempty"`
+	}{link: (*link)(l)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.Mtu = int(aux.Mtu)
+	l.VlanID = int(aux.VlanID)
+	return nil
+}
+
 type OSPDevicesInfo map[string]*OSPDeviceInfo
 
 type OSPDeviceInfo struct {
 	MacAddress string
 	NetworkID  string
+	Subports   []sriovnetworkv1.OpenstackSubport
+	// Mtu of the network of the device declared in network_data.json
+	Mtu int
 }
 
 func New(hostManager host.HostManagerInterface) OpenstackInterface {
 	return &openstackContext{
-		hostManager: hostManager,
+		hostManager:     hostManager,
+		configDrive:     os.DirFS(ospHostMetaDataDir),
+		metadataService: metadataService{},
 	}
 }
 
-// GetOpenstackData gets the metadata and network_data
-func getOpenstackData(useHostPath bool) (metaData *OSPMetaData, networkData *OSPNetworkData, err error) {
-	metaData, networkData, err = getOpenstackDataFromConfigDrive(useHostPath)
-	if err != nil {
-		metaData, networkData, err = getOpenstackDataFromMetadataService()
-		if err != nil {
-			return metaData, networkData, fmt.Errorf("GetOpenStackData(): error getting OpenStack data: %w", err)
+// metadataService is the endpoint the meta_data.json and network_data.json documents are fetched from
+type metadataService struct {
+	// baseURL the documents are relative to, when empty it is picked from the addresses of the host on each fetch
+	baseURL string
+	// transport of the requests, http.DefaultTransport when nil
+	transport http.RoundTripper
+}
+
+// ospDataOrigin describes where getOpenstackData read the metadata from
+type ospDataOrigin struct {
+	// sources the metadata was read from, e.g. ConfigDrive
+	sources []string
+	// malformed are the errors of the malformed devices, links and networks skipped while decoding the metadata
+	malformed []string
+	// unresolved are the errors of the devices whose PCI address wasn't found by MAC address
+	unresolved []string
+	// macFallbacks is the number of devices whose PCI address of the metadata was replaced by the PCI address
+	// of the NIC with their MAC address
+	macFallbacks int
+}
+
+// GetOpenstackData gets the metadata and network_data of the config drive and of the metadata service, merged
+// together since the ports hot-plugged after the boot are only known by the metadata service
+func getOpenstackData(configDrive fs.FS, service metadataService, getNICs func() ([]*ghw.NIC, error)) (metaData *OSPMetaData, networkData *OSPNetworkData, origin ospDataOrigin, err error) {
+	// the metadata service is slow to answer, it is queried while the config drive is read
+	var (
+		serviceMetaData    *OSPMetaData
+		serviceNetworkData *OSPNetworkData
+		serviceMalformed   []string
+		serviceErr         error
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serviceDone := make(chan struct{})
+	go func() {
+		defer close(serviceDone)
+		serviceMetaData, serviceNetworkData, serviceMalformed, serviceErr = getOpenstackDataFromMetadataService(ctx, service)
+	}()
+	metaData, networkData, driveMalformed, driveErr := getOpenstackDataFromConfigDrive(configDrive)
+	if driveErr != nil {
+		log.Log.V(2).Info("GetOpenstackData(): config drive not available", "error", driveErr)
+		metrics.IncVirtualMetadataFetchFailures(metrics.VirtualPlatformOpenstack, sriovnetworkv1.MetadataSourceConfigDrive)
+		<-serviceDone
+	} else {
+		// the config drive answered, the metadata service only adds the ports hot-plugged after the boot and is
+		// waited for one attempt at most rather than its whole deadline
+		select {
+		case <-serviceDone:
+		case <-time.After(vars.OpenstackMetadataTimeout):
+			cancel()
+			<-serviceDone
+			serviceErr = fmt.Errorf("no answer within %s of the config drive: %w", vars.OpenstackMetadataTimeout, serviceErr)
+		}
+	}
+	if serviceErr != nil {
+		log.Log.V(2).Info("GetOpenstackData(): metadata service not available", "error", serviceErr)
+		metrics.IncVirtualMetadataFetchFailures(metrics.VirtualPlatformOpenstack, sriovnetworkv1.MetadataSourceMetadataService)
+	}
+	switch {
+	case driveErr != nil && serviceErr != nil:
+		return metaData, networkData, origin, snerrors.Wrap(snerrors.ErrMetadataUnavailable,
+			fmt.Errorf("GetOpenStackData(): error getting OpenStack data: %w", errors.Join(driveErr, serviceErr)))
+	case driveErr != nil:
+		metaData, networkData = serviceMetaData, serviceNetworkData
+		origin = ospDataOrigin{sources: []string{sriovnetworkv1.MetadataSourceMetadataService}, malformed: serviceMalformed}
+	case serviceErr != nil:
+		origin = ospDataOrigin{sources: []string{sriovnetworkv1.MetadataSourceConfigDrive}, malformed: driveMalformed}
+	default:
+		metaData, networkData = mergeOpenstackData(metaData, networkData, serviceMetaData, serviceNetworkData)
+		malformed := append(driveMalformed, serviceMalformed...)
+		slices.Sort(malformed)
+		origin = ospDataOrigin{
+			sources:   []string{sriovnetworkv1.MetadataSourceConfigDrive, sriovnetworkv1.MetadataSourceMetadataService},
+			malformed: slices.Compact(malformed),
 		}
 	}
+	for _, m := range origin.malformed {
+		log.Log.Error(nil, "GetOpenstackData(): skipping malformed OpenStack metadata entry", "error", m)
+	}
 
 	// We can't rely on the PCI address from the metadata so we will lookup the real PCI address
 	// for the NIC that matches the MAC address.
@@ -127,19 +280,21 @@ func getOpenstackData(useHostPath bool) (metaData *OSPMetaData, networkData *OSP
 	//
 	// With that said, the PCI value in Nova Metadata is a best effort hint due to the limitations mentioned above. Therefore
 	// we will lookup the real PCI address for the NIC that matches the MAC address.
-	netInfo, err := ghw.Network()
+	netNICs, err := getNICs()
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("GetOpenStackData(): error getting network info: %w", err)
+		return metaData, networkData, origin, fmt.Errorf("GetOpenStackData(): error getting network info: %w", err)
 	}
+	nics := newNICIndex(netNICs)
+	var unresolved []error
 	for i, device := range metaData.Devices {
-		realPCIAddr, err := getPCIAddressFromMACAddress(device.Mac, netInfo.NICs)
+		realPCIAddr, err := nics.pciAddress(device.Mac)
 		if err != nil {
-			// If we can't find the PCI address, we will just print a warning, return the data as is with no error.
-			// In the future, we'll want to drain the node if sno-initial-node-state.json doesn't exist when daemon is restarted and when we have SR-IOV
-			// allocated devices already.
+			// If we can't find the PCI address, the device keeps the PCI address of the metadata and the other
+			// devices are still resolved, the error is reported in the MetadataIncomplete condition.
 			log.Log.Error(err, "Warning GetOpenstackData(): error getting PCI address for device",
 				"device-mac", device.Mac)
-			return metaData, networkData, nil
+			unresolved = append(unresolved, fmt.Errorf("device %s: %w", device.Mac, err))
+			continue
 		}
 		if realPCIAddr != device.Address {
 			log.Log.V(2).Info("GetOpenstackData(): PCI address for device does not match Nova metadata value, it'll be overwritten",
@@ -147,59 +302,108 @@ func getOpenstackData(useHostPath bool) (metaData *OSPMetaData, networkData *OSP
 				"current-address", device.Address,
 				"overwrite-address", realPCIAddr)
 			metaData.Devices[i].Address = realPCIAddr
+			origin.macFallbacks++
 		}
 	}
+	if len(unresolved) > 0 && vars.StrictOpenstackPciLookup {
+		return metaData, networkData, origin, fmt.Errorf("GetOpenStackData(): error getting the PCI address of %d devices: %w",
+			len(unresolved), errors.Join(unresolved...))
+	}
+	for _, err := range unresolved {
+		origin.unresolved = append(origin.unresolved, err.Error())
+	}
 
-	return metaData, networkData, err
+	return metaData, networkData, origin, nil
 }
 
-// getOpenstackDataFromConfigDrive reads the meta_data and network_data files
-func getOpenstackDataFromConfigDrive(useHostPath bool) (metaData *OSPMetaData, networkData *OSPNetworkData, err error) {
+// mergeOpenstackData returns the union of the devices, keyed by MAC address, and of the links and networks, keyed by
+// ID, of the config drive and of the metadata service. The entries of the metadata service, which is up to date with
+// the hot-plugged ports, replace those of the config drive.
+func mergeOpenstackData(driveMetaData *OSPMetaData, driveNetworkData *OSPNetworkData,
+	serviceMetaData *OSPMetaData, serviceNetworkData *OSPNetworkData) (*OSPMetaData, *OSPNetworkData) {
+	metaData := *driveMetaData
+	metaData.Devices = mergeByKey(driveMetaData.Devices, serviceMetaData.Devices,
+		func(d OSPMetaDataDevice) string { return strings.ToLower(d.Mac) })
+	if metaData.UUID == "" {
+		metaData.UUID = serviceMetaData.UUID
+	}
+
+	networkData := &OSPNetworkData{
+		Links: mergeByKey(driveNetworkData.Links, serviceNetworkData.Links,
+			func(l OSPNetworkLink) string { return l.ID }),
+		Networks: mergeByKey(driveNetworkData.Networks, serviceNetworkData.Networks,
+			func(n OSPNetwork) string { return n.ID }),
+	}
+	return &metaData, networkData
+}
+
+// mergeByKey returns the items of base, replaced by the items of override with the same key, followed by the
+// other items of override
+func mergeByKey[T any](base, override []T, key func(T) string) []T {
+	merged := make([]T, 0, len(base)+len(override))
+	index := map[string]int{}
+	for _, item := range base {
+		index[key(item)] = len(merged)
+		merged = append(merged, item)
+	}
+	for _, item := range override {
+		if i, exists := index[key(item)]; exists {
+			merged[i] = item
+			continue
+		}
+		index[key(item)] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// getOpenstackDataFromConfigDrive reads the meta_data and network_data files of the config drive
+func getOpenstackDataFromConfigDrive(configDrive fs.FS) (metaData *OSPMetaData, networkData *OSPNetworkData, malformed []string, err error) {
 	metaData = &OSPMetaData{}
 	networkData = &OSPNetworkData{}
 	log.Log.Info("reading OpenStack meta_data from config-drive")
-	var metadataf *os.File
-	ospMetaDataFilePath := ospMetaDataFile
-	if useHostPath {
-		ospMetaDataFilePath = ospHostMetaDataFile
-	}
-	metadataf, err = os.Open(ospMetaDataFilePath)
+	metaDataRawBytes, err := fs.ReadFile(configDrive, ospMetaDataJSON)
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error opening file %s: %w", ospHostMetaDataFile, err)
+		return metaData, networkData, nil, fmt.Errorf("error opening file %s: %w", ospMetaDataJSON, err)
 	}
-	defer func() {
-		if e := metadataf.Close(); err == nil && e != nil {
-			err = fmt.Errorf("error closing file %s: %w", ospHostMetaDataFile, e)
-		}
-	}()
-	if err = json.NewDecoder(metadataf).Decode(&metaData); err != nil {
-		return metaData, networkData, fmt.Errorf("error unmarshalling metadata from file %s: %w", ospHostMetaDataFile, err)
+	metaData, metaDataMalformed, err := decodeMetaData(metaDataRawBytes)
+	if err != nil {
+		return metaData, networkData, nil, fmt.Errorf("error unmarshalling metadata from file %s: %w", ospMetaDataJSON, err)
 	}
 
 	log.Log.Info("reading OpenStack network_data from config-drive")
-	var networkDataf *os.File
-	ospNetworkDataFilePath := ospNetworkDataFile
-	if useHostPath {
-		ospNetworkDataFilePath = ospHostNetworkDataFile
+	networkDataRawBytes, err := fs.ReadFile(configDrive, ospNetworkDataJSON)
+	if err != nil {
+		return metaData, networkData, nil, fmt.Errorf("error opening file %s: %w", ospNetworkDataJSON, err)
 	}
-	networkDataf, err = os.Open(ospNetworkDataFilePath)
+	networkData, networkDataMalformed, err := decodeNetworkData(networkDataRawBytes)
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error opening file %s: %w", ospHostNetworkDataFile, err)
+		return metaData, networkData, nil, fmt.Errorf("error unmarshalling network_data from file %s: %w", ospNetworkDataJSON, err)
 	}
-	defer func() {
-		if e := networkDataf.Close(); err == nil && e != nil {
-			err = fmt.Errorf("error closing file %s: %w", ospHostNetworkDataFile, e)
-		}
-	}()
-	if err = json.NewDecoder(networkDataf).Decode(&networkData); err != nil {
-		return metaData, networkData, fmt.Errorf("error unmarshalling metadata from file %s: %w", ospHostNetworkDataFile, err)
+	return metaData, networkData, append(metaDataMalformed, networkDataMalformed...), nil
+}
+
+// newMetadataServiceClient returns the client of the metadata service, with the timeout and the retries
+// configured in the environment of the daemon
+func newMetadataServiceClient(transport http.RoundTripper) *retryablehttp.Client {
+	client := retryablehttp.NewClient()
+	if transport != nil {
+		client.HTTPClient.Transport = transport
 	}
-	return metaData, networkData, err
+	client.HTTPClient.Timeout = vars.OpenstackMetadataTimeout
+	client.RetryMax = vars.OpenstackMetadataRetries
+	client.RetryWaitMin = vars.OpenstackMetadataBackoffMin
+	client.RetryWaitMax = vars.OpenstackMetadataBackoffMax
+	return client
 }
 
-func getBodyFromURL(url string) ([]byte, error) {
+func getBodyFromURL(ctx context.Context, client *retryablehttp.Client, url string) ([]byte, error) {
 	log.Log.V(2).Info("Getting body from", "url", url)
-	resp, err := retryablehttp.Get(url)
+	req, err := retryablehttp.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -212,49 +416,146 @@ func getBodyFromURL(url string) ([]byte, error) {
 }
 
 // getOpenstackDataFromMetadataService fetchs the metadata and network_data from the metadata service
-func getOpenstackDataFromMetadataService() (metaData *OSPMetaData, networkData *OSPNetworkData, err error) {
+func getOpenstackDataFromMetadataService(ctx context.Context, service metadataService) (metaData *OSPMetaData, networkData *OSPNetworkData, malformed []string, err error) {
 	metaData = &OSPMetaData{}
 	networkData = &OSPNetworkData{}
-	log.Log.Info("getting OpenStack meta_data from metadata server")
-	metaDataRawBytes, err := getBodyFromURL(ospMetaDataURL)
+	log.Log.Info("getting OpenStack meta_data and network_data from metadata server")
+	// the deadline bounds the retries of both requests
+	ctx, cancel := context.WithTimeout(ctx, vars.OpenstackMetadataDeadline)
+	defer cancel()
+	client := newMetadataServiceClient(service.transport)
+	baseURL := service.baseURL
+	if baseURL == "" {
+		baseURL = metadataServiceBaseURL(listHostInterfaces())
+	}
+	metaDataURL := baseURL + "/" + ospMetaDataJSON
+	networkDataURL := baseURL + "/" + ospNetworkDataJSON
+	var (
+		networkDataRawBytes []byte
+		networkDataErr      error
+		wg                  sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		networkDataRawBytes, networkDataErr = getBodyFromURL(ctx, client, networkDataURL)
+	}()
+	metaDataRawBytes, err := getBodyFromURL(ctx, client, metaDataURL)
+	wg.Wait()
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error getting OpenStack meta_data from %s: %v", ospMetaDataURL, err)
+		return metaData, networkData, nil, fmt.Errorf("error getting OpenStack meta_data from %s: %v", metaDataURL, err)
 	}
-	err = json.Unmarshal(metaDataRawBytes, metaData)
+	metaData, metaDataMalformed, err := decodeMetaData(metaDataRawBytes)
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error unmarshalling raw bytes %v from %s", err, ospMetaDataURL)
+		return metaData, networkData, nil, fmt.Errorf("error unmarshalling raw bytes %v from %s", err, metaDataURL)
 	}
 
-	log.Log.Info("getting OpenStack network_data from metadata server")
-	networkDataRawBytes, err := getBodyFromURL(ospNetworkDataURL)
+	if networkDataErr != nil {
+		return metaData, networkData, nil, fmt.Errorf("error getting OpenStack network_data from %s: %v", networkDataURL, networkDataErr)
+	}
+	networkData, networkDataMalformed, err := decodeNetworkData(networkDataRawBytes)
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error getting OpenStack network_data from %s: %v", ospNetworkDataURL, err)
+		return metaData, networkData, nil, fmt.Errorf("error unmarshalling raw bytes %v from %s", err, networkDataURL)
+	}
+	return metaData, networkData, append(metaDataMalformed, networkDataMalformed...), nil
+}
+
+// decodeMetaData decodes a meta_data.json document, each device is decoded on its own so that a malformed one
+// is skipped and reported in malformed instead of failing the whole document
+func decodeMetaData(data []byte) (*OSPMetaData, []string, error) {
+	raw := struct {
+		OSPMetaData
+		Devices []json.RawMessage `json:"devices,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &OSPMetaData{}, nil, err
 	}
-	err = json.Unmarshal(networkDataRawBytes, networkData)
+	metaData := raw.OSPMetaData
+	devices, malformed, err := decodeEntries[OSPMetaDataDevice](raw.Devices, "device", "mac")
 	if err != nil {
-		return metaData, networkData, fmt.Errorf("error unmarshalling raw bytes %v from %s", err, ospNetworkDataURL)
+		return &metaData, nil, err
 	}
-	return metaData, networkData, nil
+	metaData.Devices = devices
+	return &metaData, malformed, nil
 }
 
-// getPCIAddressFromMACAddress returns the PCI address of a device given its MAC address
-func getPCIAddressFromMACAddress(macAddress string, nics []*net.NIC) (string, error) {
-	var pciAddress string
-	for _, nic := range nics {
-		if strings.EqualFold(nic.MacAddress, macAddress) {
-			if pciAddress == "" {
-				pciAddress = *nic.PCIAddress
-			} else {
-				return "", fmt.Errorf("more than one device found with MAC address %s is unsupported", macAddress)
+// decodeNetworkData decodes a network_data.json document, each link and network is decoded on its own so that a
+// malformed one is skipped and reported in malformed instead of failing the whole document
+func decodeNetworkData(data []byte) (*OSPNetworkData, []string, error) {
+	raw := struct {
+		Links    []json.RawMessage `json:"links,omitempty"`
+		Networks []json.RawMessage `json:"networks,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &OSPNetworkData{}, nil, err
+	}
+	links, linksMalformed, err := decodeEntries[OSPNetworkLink](raw.Links, "link", "id")
+	if err != nil {
+		return &OSPNetworkData{}, nil, err
+	}
+	networks, networksMalformed, err := decodeEntries[OSPNetwork](raw.Networks, "network", "id")
+	if err != nil {
+		return &OSPNetworkData{}, nil, err
+	}
+	return &OSPNetworkData{Links: links, Networks: networks}, append(linksMalformed, networksMalformed...), nil
+}
+
+// decodeEntries decodes the entries of a list of the metadata. The errors of the malformed entries are returned
+// in malformed, named after the keyField of the entry or its index, unless the metadata is decoded strictly
+// where the first malformed entry fails the decoding.
+func decodeEntries[T any](raw []json.RawMessage, kind, keyField string) (entries []T, malformed []string, err error) {
+	for i, r := range raw {
+		var entry T
+		if err := json.Unmarshal(r, &entry); err != nil {
+			name := fmt.Sprintf("%s #%d", kind, i)
+			fields := map[string]interface{}{}
+			if json.Unmarshal(r, &fields) == nil {
+				if key, ok := fields[keyField].(string); ok && key != "" {
+					name = kind + " " + key
+				}
 			}
+			if vars.StrictOpenstackMetadata {
+				return nil, nil, fmt.Errorf("malformed %s: %w", name, err)
+			}
+			malformed = append(malformed, fmt.Sprintf("%s: %v", name, err))
+			continue
 		}
+		entries = append(entries, entry)
 	}
+	return entries, malformed, nil
+}
+
+// nicIndex maps the lower case MAC addresses of the NICs to their PCI addresses, the MAC addresses shared by
+// several NICs map to an empty PCI address
+type nicIndex map[string]string
 
-	if pciAddress != "" {
-		return pciAddress, nil
+// newNICIndex indexes the NICs by MAC address, once for all the devices of the metadata
+func newNICIndex(nics []*net.NIC) nicIndex {
+	index := nicIndex{}
+	for _, nic := range nics {
+		if nic.PCIAddress == nil {
+			continue
+		}
+		mac := strings.ToLower(nic.MacAddress)
+		if _, exists := index[mac]; exists {
+			index[mac] = ""
+			continue
+		}
+		index[mac] = *nic.PCIAddress
 	}
+	return index
+}
 
-	return "", fmt.Errorf("no device found with MAC address %s", macAddress)
+// pciAddress returns the PCI address of a device given its MAC address
+func (n nicIndex) pciAddress(macAddress string) (string, error) {
+	pciAddress, exists := n[strings.ToLower(macAddress)]
+	if !exists {
+		return "", fmt.Errorf("no device found with MAC address %s", macAddress)
+	}
+	if pciAddress == "" {
+		return "", fmt.Errorf("more than one device found with MAC address %s is unsupported", macAddress)
+	}
+	return pciAddress, nil
 }
 
 // CreateOpenstackDevicesInfo create the openstack device info map
@@ -262,11 +563,15 @@ func (o *openstackContext) CreateOpenstackDevicesInfo() error {
 	log.Log.Info("CreateOpenstackDevicesInfo()")
 	devicesInfo := make(OSPDevicesInfo)
 
-	metaData, networkData, err := getOpenstackData(true)
+	metaData, networkData, origin, err := getOpenstackData(o.configDrive, o.metadataService, o.hostManager.GetNICs)
 	if err != nil {
 		log.Log.Error(err, "failed to read OpenStack data")
 		return err
 	}
+	o.metadataErrors = origin.malformed
+	o.unresolvedDevices = origin.unresolved
+	metrics.SetVirtualDevices(metrics.VirtualPlatformOpenstack, metrics.VirtualDevicesMacFallback, origin.macFallbacks)
+	o.provenance = &sriovnetworkv1.MetadataProvenance{Sources: origin.sources, ReadTime: metav1.Now()}
 
 	if metaData == nil || networkData == nil {
 		o.openStackDevicesInfo = make(OSPDevicesInfo)
@@ -279,8 +584,9 @@ func (o *openstackContext) CreateOpenstackDevicesInfo() error {
 			if device.Mac == link.EthernetMac {
 				for _, network := range networkData.Networks {
 					if network.Link == link.ID {
-						networkID := sriovnetworkv1.OpenstackNetworkID.String() + ":" + network.NetworkID
-						devicesInfo[device.Address] = &OSPDeviceInfo{MacAddress: device.Mac, NetworkID: networkID}
+						networkID := sriovnetworkv1.NewNetFilter(sriovnetworkv1.OpenstackNetworkID.String(), network.NetworkID)
+						devicesInfo[device.Address] = &OSPDeviceInfo{MacAddress: device.Mac, NetworkID: networkID,
+							Subports: subportsOfLink(networkData, link.ID), Mtu: link.Mtu}
 					}
 				}
 			}
@@ -288,12 +594,11 @@ func (o *openstackContext) CreateOpenstackDevicesInfo() error {
 	}
 
 	// for vhostuser interface type we check the interfaces on the node
-	pci, err := ghw.PCI()
+	devices, err := o.hostManager.GetPCIDevices()
 	if err != nil {
 		return fmt.Errorf("CreateOpenstackDevicesInfo(): error getting PCI info: %v", err)
 	}
 
-	devices := pci.ListDevices()
 	if len(devices) == 0 {
 		return fmt.Errorf("CreateOpenstackDevicesInfo(): could not retrieve PCI devices")
 	}
@@ -330,8 +635,9 @@ func (o *openstackContext) CreateOpenstackDevicesInfo() error {
 			if macAddress == link.EthernetMac {
 				for _, network := range networkData.Networks {
 					if network.Link == link.ID {
-						networkID := sriovnetworkv1.OpenstackNetworkID.String() + ":" + network.NetworkID
-						devicesInfo[device.Address] = &OSPDeviceInfo{MacAddress: macAddress, NetworkID: networkID}
+						networkID := sriovnetworkv1.NewNetFilter(sriovnetworkv1.OpenstackNetworkID.String(), network.NetworkID)
+						devicesInfo[device.Address] = &OSPDeviceInfo{MacAddress: macAddress, NetworkID: networkID,
+							Subports: subportsOfLink(networkData, link.ID), Mtu: link.Mtu}
 					}
 				}
 			}
@@ -339,20 +645,105 @@ func (o *openstackContext) CreateOpenstackDevicesInfo() error {
 	}
 
 	o.openStackDevicesInfo = devicesInfo
+	o.writeDevicesInfoCheckpoint()
 	return nil
 }
 
+// ospDevicesInfoCheckpoint is the device information persisted by the first successful CreateOpenstackDevicesInfo
+// since the boot of the node, it is used when the metadata is unavailable after a restart of the daemon
+type ospDevicesInfoCheckpoint struct {
+	Provenance sriovnetworkv1.MetadataProvenance `json:"provenance"`
+	Devices    OSPDevicesInfo                    `json:"devices"`
+}
+
+func devicesInfoCheckpointPath() string {
+	return filepath.Join(vars.Destdir, consts.OpenstackDevicesInfoFileName)
+}
+
+// writeDevicesInfoCheckpoint persists the device information unless it was already persisted since the boot of the
+// node, the errors are only logged as the checkpoint is only needed when the metadata becomes unavailable
+func (o *openstackContext) writeDevicesInfoCheckpoint() {
+	file, err := os.OpenFile(devicesInfoCheckpointPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if !os.IsExist(err) {
+			log.Log.Error(err, "writeDevicesInfoCheckpoint(): failed to create the devices info checkpoint")
+		}
+		return
+	}
+	defer file.Close()
+	checkpoint := ospDevicesInfoCheckpoint{Provenance: *o.provenance, Devices: o.openStackDevicesInfo}
+	if err := json.NewEncoder(file).Encode(checkpoint); err != nil {
+		log.Log.Error(err, "writeDevicesInfoCheckpoint(): failed to write the devices info checkpoint")
+		return
+	}
+	log.Log.Info("writeDevicesInfoCheckpoint(): devices info persisted", "path", devicesInfoCheckpointPath())
+}
+
+// CreateOpenstackDevicesInfoFromCheckpoint restores the device information persisted by CreateOpenstackDevicesInfo,
+// it returns false if no device information was persisted since the boot of the node
+func (o *openstackContext) CreateOpenstackDevicesInfoFromCheckpoint() (bool, error) {
+	data, err := os.ReadFile(devicesInfoCheckpointPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("CreateOpenstackDevicesInfoFromCheckpoint(): failed to read the devices info checkpoint: %w", err)
+	}
+	checkpoint := ospDevicesInfoCheckpoint{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return false, fmt.Errorf("CreateOpenstackDevicesInfoFromCheckpoint(): failed to decode the devices info checkpoint: %w", err)
+	}
+	o.openStackDevicesInfo = checkpoint.Devices
+	if o.openStackDevicesInfo == nil {
+		o.openStackDevicesInfo = make(OSPDevicesInfo)
+	}
+	// the read time tells how stale the restored network IDs are
+	o.provenance = &sriovnetworkv1.MetadataProvenance{
+		Sources:  []string{sriovnetworkv1.MetadataSourceCheckpoint},
+		ReadTime: checkpoint.Provenance.ReadTime,
+	}
+	o.metadataErrors = nil
+	o.unresolvedDevices = nil
+	return true, nil
+}
+
+// subportsOfLink returns the subports of the trunk port of a link, the networks of the vlan links whose parent
+// is the link
+func subportsOfLink(networkData *OSPNetworkData, linkID string) []sriovnetworkv1.OpenstackSubport {
+	var subports []sriovnetworkv1.OpenstackSubport
+	for _, link := range networkData.Links {
+		if link.VlanLink == "" || link.VlanLink != linkID {
+			continue
+		}
+		for _, network := range networkData.Networks {
+			if network.Link == link.ID {
+				subports = append(subports, sriovnetworkv1.OpenstackSubport{
+					NetFilter: sriovnetworkv1.NewNetFilter(sriovnetworkv1.OpenstackNetworkID.String(), network.NetworkID),
+					VlanID:    link.VlanID,
+				})
+			}
+		}
+	}
+	return subports
+}
+
 // DiscoverSriovDevicesVirtual discovers VFs on a virtual platform
 func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.InterfaceExt, error) {
 	log.Log.V(2).Info("DiscoverSriovDevicesVirtual()")
 	pfList := []sriovnetworkv1.InterfaceExt{}
+	mtuMismatches := []string{}
+	// number of network devices by result of the discovery, exported as metrics
+	results := map[string]int{
+		metrics.VirtualDevicesMetadata:  0,
+		metrics.VirtualDevicesSkipped:   0,
+		metrics.VirtualDevicesUnmanaged: 0,
+	}
 
-	pci, err := ghw.PCI()
+	devices, err := o.hostManager.GetPCIDevices()
 	if err != nil {
 		return nil, fmt.Errorf("DiscoverSriovDevicesVirtual(): error getting PCI info: %v", err)
 	}
 
-	devices := pci.ListDevices()
 	if len(devices) == 0 {
 		return nil, fmt.Errorf("DiscoverSriovDevicesVirtual(): could not retrieve PCI devices")
 	}
@@ -371,9 +762,15 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 
 		deviceInfo, exist := o.openStackDevicesInfo[device.Address]
 		if !exist {
-			log.Log.Error(nil, "DiscoverSriovDevicesVirtual(): unable to find device in devicesInfo list, skipping",
+			if !vars.ReportUnmanagedVirtualDevices {
+				log.Log.Error(nil, "DiscoverSriovDevicesVirtual(): unable to find device in devicesInfo list, skipping",
+					"device", device.Address)
+				results[metrics.VirtualDevicesSkipped]++
+				continue
+			}
+			log.Log.Info("DiscoverSriovDevicesVirtual(): unable to find device in devicesInfo list, reporting it as unmanaged",
 				"device", device.Address)
-			continue
+			deviceInfo = &OSPDeviceInfo{}
 		}
 		netFilter := deviceInfo.NetworkID
 		metaMac := deviceInfo.MacAddress
@@ -382,14 +779,18 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 		if err != nil {
 			log.Log.Error(err, "DiscoverSriovDevicesVirtual(): unable to parse device driver for device, skipping",
 				"device", device)
+			results[metrics.VirtualDevicesSkipped]++
 			continue
 		}
 		iface := sriovnetworkv1.InterfaceExt{
-			PciAddress: device.Address,
-			Driver:     driver,
-			Vendor:     device.Vendor.ID,
-			DeviceID:   device.Product.ID,
-			NetFilter:  netFilter,
+			PciAddress:  device.Address,
+			Driver:      driver,
+			Vendor:      device.Vendor.ID,
+			DeviceID:    device.Product.ID,
+			NetFilter:   netFilter,
+			Subports:    deviceInfo.Subports,
+			PlatformMtu: deviceInfo.Mtu,
+			Unmanaged:   !exist,
 		}
 		if mtu := o.hostManager.GetNetdevMTU(device.Address); mtu > 0 {
 			iface.Mtu = mtu
@@ -401,7 +802,20 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 			}
 			iface.LinkSpeed = o.hostManager.GetNetDevLinkSpeed(name)
 		}
+		if iface.PlatformMtu > 0 && iface.Mtu != iface.PlatformMtu {
+			// a device with a MTU above the one of its network fragments the tunnels of the network
+			log.Log.Info("DiscoverSriovDevicesVirtual(): device MTU differs from the MTU of its network",
+				"device", device.Address, "mtu", iface.Mtu, "network-mtu", iface.PlatformMtu)
+			mtuMismatches = append(mtuMismatches,
+				fmt.Sprintf("%s(%s) %d, declared %d", iface.Name, iface.PciAddress, iface.Mtu, iface.PlatformMtu))
+		}
 		iface.LinkType = o.hostManager.GetLinkType(iface)
+		if iface.Unmanaged {
+			// the device is only reported, it has no VF usable by the policies
+			pfList = append(pfList, iface)
+			results[metrics.VirtualDevicesUnmanaged]++
+			continue
+		}
 
 		iface.TotalVfs = 1
 		iface.NumVfs = 1
@@ -418,6 +832,11 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 		iface.VFs = append(iface.VFs, vf)
 
 		pfList = append(pfList, iface)
+		results[metrics.VirtualDevicesMetadata]++
+	}
+	o.mtuMismatches = mtuMismatches
+	for result, count := range results {
+		metrics.SetVirtualDevices(metrics.VirtualPlatformOpenstack, result, count)
 	}
 	return pfList, nil
 }
@@ -425,8 +844,44 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 func (o *openstackContext) CreateOpenstackDevicesInfoFromNodeStatus(networkState *sriovnetworkv1.SriovNetworkNodeState) {
 	devicesInfo := make(OSPDevicesInfo)
 	for _, iface := range networkState.Status.Interfaces {
-		devicesInfo[iface.PciAddress] = &OSPDeviceInfo{MacAddress: iface.Mac, NetworkID: iface.NetFilter}
+		if iface.Unmanaged {
+			continue
+		}
+		devicesInfo[iface.PciAddress] = &OSPDeviceInfo{MacAddress: iface.Mac, NetworkID: iface.NetFilter,
+			Subports: iface.Subports, Mtu: iface.PlatformMtu}
 	}
 
 	o.openStackDevicesInfo = devicesInfo
+	// the node status keeps the time its device information was read from the platform, which tells how stale
+	// the network IDs are
+	o.provenance = &sriovnetworkv1.MetadataProvenance{
+		Sources:  []string{sriovnetworkv1.MetadataSourceNodeStatus},
+		ReadTime: metav1.Now(),
+	}
+	if networkState.Status.Metadata != nil {
+		o.provenance.ReadTime = networkState.Status.Metadata.ReadTime
+	}
+}
+
+// GetOpenstackMetadataErrors returns the errors of the malformed devices, links and networks of the metadata
+// skipped by the last CreateOpenstackDevicesInfo
+func (o *openstackContext) GetOpenstackMetadataErrors() []string {
+	return o.metadataErrors
+}
+
+// GetOpenstackUnresolvedDevices returns the errors of the devices whose PCI address wasn't found by MAC address
+// by the last CreateOpenstackDevicesInfo
+func (o *openstackContext) GetOpenstackUnresolvedDevices() []string {
+	return o.unresolvedDevices
+}
+
+// GetOpenstackMetadataProvenance returns where the device information was read from, nil before it is created
+func (o *openstackContext) GetOpenstackMetadataProvenance() *sriovnetworkv1.MetadataProvenance {
+	return o.provenance
+}
+
+// GetOpenstackMtuMismatches returns the devices whose MTU differs from the MTU of their network declared in the
+// metadata, found by the last DiscoverSriovDevicesVirtual
+func (o *openstackContext) GetOpenstackMtuMismatches() []string {
+	return o.mtuMismatches
 }
@@ -1,16 +1,30 @@
 package openstack
 
 import (
+	"context"
+	"io"
+	stdnet "net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
 	"github.com/jaypipes/ghw"
 	"github.com/jaypipes/ghw/pkg/net"
-	"github.com/jaypipes/ghw/pkg/option"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakemetadata"
 )
 
 func TestUtilsVirtual(t *testing.T) {
@@ -18,35 +32,44 @@ func TestUtilsVirtual(t *testing.T) {
 	RunSpecs(t, "Utils")
 }
 
+// serveMetadataService starts a fake metadata service returning the given meta_data and network_data documents
+func serveMetadataService(metaData, networkData string) (metadataService, *fakemetadata.Server) {
+	server := fakemetadata.New(map[string]string{
+		"/" + ospMetaDataJSON:    metaData,
+		"/" + ospNetworkDataJSON: networkData,
+	})
+	DeferCleanup(server.Close)
+	return metadataService{baseURL: server.URL, transport: server.Client().Transport}, server
+}
+
+// roundTripperFunc is an http.RoundTripper answering the requests without network
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 var _ = Describe("Virtual", func() {
 
 	Context("GetOpenstackData", func() {
+		configDrive := os.DirFS("./testdata")
+
 		It("PCI address replacement based on MAC address", func() {
-			ospNetworkDataFile = "./testdata/network_data.json"
-			ospMetaDataFile = "./testdata/meta_data.json"
-			DeferCleanup(func() {
-				ospNetworkDataFile = ospMetaDataDir + "/network_data.json"
-				ospMetaDataFile = ospMetaDataDir + "/meta_data.json"
-			})
+			service, _ := serveMetadataService(`{"devices": []}`, `{"links": [], "networks": []}`)
 
-			ghw.Network = func(opts ...*option.Option) (*net.Info, error) {
-				return &net.Info{
-					NICs: []*net.NIC{{
-						MacAddress: "fa:16:3e:00:00:00",
-						PCIAddress: pointer.String("0000:04:00.0"),
-					}, {
-						MacAddress: "fa:16:3e:11:11:11",
-						PCIAddress: pointer.String("0000:99:99.9"),
-					}},
-				}, nil
+			nics := func() ([]*ghw.NIC, error) {
+				return []*ghw.NIC{{
+					MacAddress: "fa:16:3e:00:00:00",
+					PCIAddress: pointer.String("0000:04:00.0"),
+				}, {
+					MacAddress: "fa:16:3e:11:11:11",
+					PCIAddress: pointer.String("0000:99:99.9"),
+				}}, nil
 			}
 
-			DeferCleanup(func() {
-				ghw.Network = net.New
-			})
-
-			metaData, _, err := getOpenstackData(false)
+			metaData, _, origin, err := getOpenstackData(configDrive, service, nics)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(origin.macFallbacks).To(Equal(1))
 
 			Expect(metaData.Devices).To(HaveLen(2))
 			Expect(metaData.Devices[0].Mac).To(Equal("fa:16:3e:00:00:00"))
@@ -55,5 +78,357 @@ var _ = Describe("Virtual", func() {
 			Expect(metaData.Devices[1].Address).To(Equal("0000:99:99.9"))
 
 		})
+
+		It("resolves the PCI address of the other devices when a MAC address isn't found", func() {
+			service, _ := serveMetadataService(`{"devices": []}`, `{"links": [], "networks": []}`)
+
+			nics := func() ([]*ghw.NIC, error) {
+				return []*ghw.NIC{{
+					MacAddress: "fa:16:3e:11:11:11",
+					PCIAddress: pointer.String("0000:99:99.9"),
+				}}, nil
+			}
+
+			metaData, _, origin, err := getOpenstackData(configDrive, service, nics)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metaData.Devices[0].Address).To(Equal("0000:04:00.0"))
+			Expect(metaData.Devices[1].Address).To(Equal("0000:99:99.9"))
+			Expect(origin.unresolved).To(ConsistOf(HavePrefix("device fa:16:3e:00:00:00: ")))
+			Expect(origin.malformed).To(BeEmpty())
+
+			vars.StrictOpenstackPciLookup = true
+			DeferCleanup(func() {
+				vars.StrictOpenstackPciLookup = false
+			})
+			_, _, _, err = getOpenstackData(configDrive, service, nics)
+			Expect(err).To(MatchError(ContainSubstring("no device found with MAC address fa:16:3e:00:00:00")))
+		})
+
+		It("merges the devices hot-plugged in the metadata service with the config drive ones", func() {
+			service, _ := serveMetadataService(`{
+  "uuid": "7114a452-2e00-4ade-856a-42d4dc7c894f",
+  "devices": [
+    {"type": "nic", "mac": "FA:16:3E:11:11:11", "bus": "pci", "address": "0000:05:00.0", "vlan": 200},
+    {"type": "nic", "mac": "fa:16:3e:22:22:22", "bus": "pci", "address": "0000:06:00.0"}
+  ]
+}`, `{
+  "links": [{"id": "tap22222222-22", "type": "phy", "ethernet_mac_address": "fa:16:3e:22:22:22", "mtu": 1500}],
+  "networks": []
+}`)
+
+			nics := func() ([]*ghw.NIC, error) {
+				return []*ghw.NIC{{
+					MacAddress: "fa:16:3e:00:00:00",
+					PCIAddress: pointer.String("0000:04:00.0"),
+				}, {
+					MacAddress: "fa:16:3e:11:11:11",
+					PCIAddress: pointer.String("0000:05:00.0"),
+				}, {
+					MacAddress: "fa:16:3e:22:22:22",
+					PCIAddress: pointer.String("0000:06:00.0"),
+				}}, nil
+			}
+
+			metaData, networkData, origin, err := getOpenstackData(configDrive, service, nics)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(origin.sources).To(Equal([]string{sriovnetworkv1.MetadataSourceConfigDrive, sriovnetworkv1.MetadataSourceMetadataService}))
+
+			Expect(metaData.Devices).To(HaveLen(3))
+			Expect(metaData.Devices[0].Mac).To(Equal("fa:16:3e:00:00:00"))
+			Expect(metaData.Devices[1].Mac).To(Equal("FA:16:3E:11:11:11"))
+			Expect(metaData.Devices[1].Vlan).To(Equal(200))
+			Expect(metaData.Devices[2].Mac).To(Equal("fa:16:3e:22:22:22"))
+			Expect(metaData.Devices[2].Address).To(Equal("0000:06:00.0"))
+			Expect(networkData.Links).To(ContainElement(HaveField("ID", "tap22222222-22")))
+		})
+
+		It("falls back to the metadata service without config drive", func() {
+			service, _ := serveMetadataService(`{"devices": [{"type": "nic", "mac": "fa:16:3e:22:22:22", "address": "0000:06:00.0"}]}`,
+				`{"links": [], "networks": []}`)
+
+			nics := func() ([]*ghw.NIC, error) {
+				return []*ghw.NIC{{
+					MacAddress: "fa:16:3e:22:22:22",
+					PCIAddress: pointer.String("0000:06:00.0"),
+				}}, nil
+			}
+
+			metaData, _, origin, err := getOpenstackData(fstest.MapFS{}, service, nics)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(origin.sources).To(Equal([]string{sriovnetworkv1.MetadataSourceMetadataService}))
+			Expect(metaData.Devices).To(HaveLen(1))
+			Expect(metaData.Devices[0].Mac).To(Equal("fa:16:3e:22:22:22"))
+		})
+		It("waits for the metadata service one attempt at most once the config drive answered", func() {
+			service := metadataService{
+				baseURL: ospMetaDataBaseURL,
+				transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					<-req.Context().Done()
+					return nil, req.Context().Err()
+				}),
+			}
+			vars.OpenstackMetadataTimeout = 100 * time.Millisecond
+			DeferCleanup(func() {
+				vars.OpenstackMetadataTimeout = consts.DefaultOpenstackMetadataTimeout
+			})
+
+			nics := func() ([]*ghw.NIC, error) {
+				return []*ghw.NIC{{
+					MacAddress: "fa:16:3e:00:00:00",
+					PCIAddress: pointer.String("0000:04:00.0"),
+				}}, nil
+			}
+
+			start := time.Now()
+			metaData, _, origin, err := getOpenstackData(configDrive, service, nics)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(origin.sources).To(Equal([]string{sriovnetworkv1.MetadataSourceConfigDrive}))
+			Expect(metaData.Devices).To(HaveLen(2))
+		})
+	})
+
+	Context("decodeNetworkData", func() {
+		networkData := []byte(`{
+  "links": [
+    {"id": "tap1", "type": "phy", "ethernet_mac_address": "fa:16:3e:00:00:00", "mtu": "9000", "vendor_extra": {"a": 1}},
+    {"id": "tap2", "type": "phy", "ethernet_mac_address": "fa:16:3e:11:11:11", "mtu": "jumbo"},
+    {"id": "tap3", "type": "phy", "ethernet_mac_address": "fa:16:3e:22:22:22", "mtu": null}
+  ],
+  "networks": [
+    {"id": "network0", "type": "ipv4_dhcp", "link": "tap1", "network_id": "da5bb487-5193-4a65-a3df-4a0055a8c0d7"},
+    {"id": 1, "type": "ipv4_dhcp", "link": "tap2"}
+  ],
+  "services": [{"type": "dns", "address": "8.8.8.8"}]
+}`)
+
+		It("decodes the string integers and skips the malformed entries", func() {
+			data, malformed, err := decodeNetworkData(networkData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data.Links).To(HaveLen(2))
+			Expect(data.Links[0].Mtu).To(Equal(9000))
+			Expect(data.Links[1].ID).To(Equal("tap3"))
+			Expect(data.Links[1].Mtu).To(Equal(0))
+			Expect(data.Networks).To(HaveLen(1))
+			Expect(malformed).To(HaveLen(2))
+			Expect(malformed[0]).To(HavePrefix("link tap2: "))
+			Expect(malformed[1]).To(HavePrefix("network #1: "))
+		})
+
+		It("fails on the first malformed entry in strict mode", func() {
+			vars.StrictOpenstackMetadata = true
+			DeferCleanup(func() {
+				vars.StrictOpenstackMetadata = false
+			})
+
+			_, _, err := decodeNetworkData(networkData)
+			Expect(err).To(MatchError(HavePrefix("malformed link tap2: ")))
+		})
+
+		It("decodes the string VLANs of the devices", func() {
+			metaData, malformed, err := decodeMetaData([]byte(`{"uuid": "7114a452", "devices": [
+  {"type": "nic", "mac": "fa:16:3e:00:00:00", "vlan": "177"},
+  {"type": "nic", "mac": "fa:16:3e:11:11:11", "vf_trusted": "yes"}
+]}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metaData.UUID).To(Equal("7114a452"))
+			Expect(metaData.Devices).To(HaveLen(1))
+			Expect(metaData.Devices[0].Vlan).To(Equal(177))
+			Expect(malformed).To(ConsistOf(HavePrefix("device fa:16:3e:11:11:11: ")))
+		})
+	})
+
+	Context("CreateOpenstackDevicesInfoFromNodeStatus", func() {
+		It("keeps the time the node status was read from the platform", func() {
+			readTime := metav1.NewTime(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+			o := &openstackContext{}
+			o.CreateOpenstackDevicesInfoFromNodeStatus(&sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{PciAddress: "0000:04:00.0", Mac: "fa:16:3e:00:00:00",
+						NetFilter: "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7"}},
+					Metadata: &sriovnetworkv1.MetadataProvenance{
+						Sources:  []string{sriovnetworkv1.MetadataSourceConfigDrive},
+						ReadTime: readTime,
+					},
+				},
+			})
+
+			provenance := o.GetOpenstackMetadataProvenance()
+			Expect(provenance.Sources).To(Equal([]string{sriovnetworkv1.MetadataSourceNodeStatus}))
+			Expect(provenance.ReadTime.Equal(&readTime)).To(BeTrue())
+			Expect(o.openStackDevicesInfo).To(HaveKey("0000:04:00.0"))
+		})
+
+		It("skips the unmanaged devices", func() {
+			o := &openstackContext{}
+			o.CreateOpenstackDevicesInfoFromNodeStatus(&sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{
+						{PciAddress: "0000:04:00.0", Mac: "fa:16:3e:00:00:00",
+							NetFilter: "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7"},
+						{PciAddress: "0000:05:00.0", Mac: "52:54:00:12:34:56", Unmanaged: true},
+					},
+				},
+			})
+			Expect(o.openStackDevicesInfo).To(HaveKey("0000:04:00.0"))
+			Expect(o.openStackDevicesInfo).ToNot(HaveKey("0000:05:00.0"))
+		})
+	})
+
+	Context("CreateOpenstackDevicesInfoFromCheckpoint", func() {
+		BeforeEach(func() {
+			destdir := vars.Destdir
+			vars.Destdir = GinkgoT().TempDir()
+			DeferCleanup(func() {
+				vars.Destdir = destdir
+			})
+		})
+
+		It("restores the devices info persisted since the boot", func() {
+			readTime := metav1.NewTime(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+			o := &openstackContext{
+				openStackDevicesInfo: OSPDevicesInfo{"0000:04:00.0": {MacAddress: "fa:16:3e:00:00:00",
+					NetworkID: "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7", Mtu: 8942}},
+				provenance: &sriovnetworkv1.MetadataProvenance{
+					Sources: []string{sriovnetworkv1.MetadataSourceConfigDrive}, ReadTime: readTime},
+			}
+			o.writeDevicesInfoCheckpoint()
+			// the devices info of the first successful read is kept
+			o.openStackDevicesInfo = OSPDevicesInfo{}
+			o.writeDevicesInfoCheckpoint()
+
+			restored := &openstackContext{}
+			ok, err := restored.CreateOpenstackDevicesInfoFromCheckpoint()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(restored.openStackDevicesInfo).To(HaveKeyWithValue("0000:04:00.0", &OSPDeviceInfo{MacAddress: "fa:16:3e:00:00:00",
+				NetworkID: "openstack/NetworkID:da5bb487-5193-4a65-a3df-4a0055a8c0d7", Mtu: 8942}))
+			provenance := restored.GetOpenstackMetadataProvenance()
+			Expect(provenance.Sources).To(Equal([]string{sriovnetworkv1.MetadataSourceCheckpoint}))
+			Expect(provenance.ReadTime.Equal(&readTime)).To(BeTrue())
+		})
+
+		It("reports the missing checkpoint", func() {
+			o := &openstackContext{}
+			ok, err := o.CreateOpenstackDevicesInfoFromCheckpoint()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("subportsOfLink", func() {
+		It("returns the networks and the VLANs of the subports of the trunk port", func() {
+			networkData, _, err := decodeNetworkData([]byte(`{
+  "links": [
+    {"id": "tapparent", "type": "phy", "ethernet_mac_address": "fa:16:3e:00:00:00"},
+    {"id": "tapsub101", "type": "vlan", "vlan_link": "tapparent", "vlan_id": 101, "vlan_mac_address": "fa:16:3e:00:00:00"},
+    {"id": "tapsub102", "type": "vlan", "vlan_link": "tapparent", "vlan_id": "102", "vlan_mac_address": "fa:16:3e:00:00:00"},
+    {"id": "tapother", "type": "phy", "ethernet_mac_address": "fa:16:3e:11:11:11"}
+  ],
+  "networks": [
+    {"id": "network0", "type": "ipv4_dhcp", "link": "tapparent", "network_id": "da5bb487-5193-4a65-a3df-4a0055a8c0d7"},
+    {"id": "network1", "type": "ipv4_dhcp", "link": "tapsub101", "network_id": "4dff9e7c-05e4-4a2a-9a5b-5bcd7e3c1b07"},
+    {"id": "network2", "type": "ipv4_dhcp", "link": "tapsub102", "network_id": "0b3e7d4f-6f4c-4a8e-9a54-7c3c2b1e0f11"},
+    {"id": "network3", "type": "ipv4_dhcp", "link": "tapother", "network_id": "9c1f0a52-4f1e-4d3c-8e0a-2f5b7d6c3a19"}
+  ]
+}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(subportsOfLink(networkData, "tapparent")).To(Equal([]sriovnetworkv1.OpenstackSubport{
+				{NetFilter: "openstack/NetworkID:4dff9e7c-05e4-4a2a-9a5b-5bcd7e3c1b07", VlanID: 101},
+				{NetFilter: "openstack/NetworkID:0b3e7d4f-6f4c-4a8e-9a54-7c3c2b1e0f11", VlanID: 102},
+			}))
+			Expect(subportsOfLink(networkData, "tapother")).To(BeEmpty())
+		})
+	})
+
+	Context("nicIndex", func() {
+		It("resolves the PCI addresses of the MAC addresses", func() {
+			nics := newNICIndex([]*net.NIC{{
+				MacAddress: "FA:16:3E:00:00:00",
+				PCIAddress: pointer.String("0000:04:00.0"),
+			}, {
+				MacAddress: "fa:16:3e:11:11:11",
+				PCIAddress: pointer.String("0000:05:00.0"),
+			}, {
+				MacAddress: "fa:16:3e:11:11:11",
+				PCIAddress: pointer.String("0000:06:00.0"),
+			}, {
+				// a virtual NIC without PCI address
+				MacAddress: "fa:16:3e:22:22:22",
+			}})
+
+			Expect(nics.pciAddress("fa:16:3e:00:00:00")).To(Equal("0000:04:00.0"))
+			_, err := nics.pciAddress("fa:16:3e:11:11:11")
+			Expect(err).To(MatchError(ContainSubstring("more than one device")))
+			_, err = nics.pciAddress("fa:16:3e:22:22:22")
+			Expect(err).To(MatchError(ContainSubstring("no device found")))
+		})
+	})
+
+	Context("getOpenstackDataFromMetadataService", func() {
+		It("reads the documents through the injected transport", func() {
+			var requested []string
+			service := metadataService{
+				baseURL: ospMetaDataBaseURL,
+				transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					requested = append(requested, req.URL.String())
+					body := `{"links": [], "networks": []}`
+					if strings.HasSuffix(req.URL.Path, ospMetaDataJSON) {
+						body = `{"uuid": "7114a452-2e00-4ade-856a-42d4dc7c894f", "devices": []}`
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+				}),
+			}
+
+			metaData, _, _, err := getOpenstackDataFromMetadataService(context.Background(), service)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metaData.UUID).To(Equal("7114a452-2e00-4ade-856a-42d4dc7c894f"))
+			Expect(requested).To(ConsistOf(ospMetaDataBaseURL+"/"+ospMetaDataJSON, ospMetaDataBaseURL+"/"+ospNetworkDataJSON))
+		})
+
+		It("gives up on a failing metadata service at the deadline", func() {
+			service, server := serveMetadataService(`{"devices": []}`, `{"links": [], "networks": []}`)
+			server.SetStatus("/"+ospMetaDataJSON, http.StatusServiceUnavailable)
+			server.SetStatus("/"+ospNetworkDataJSON, http.StatusServiceUnavailable)
+			vars.OpenstackMetadataRetries = 1000
+			vars.OpenstackMetadataBackoffMin = 10 * time.Millisecond
+			vars.OpenstackMetadataBackoffMax = 50 * time.Millisecond
+			vars.OpenstackMetadataDeadline = 300 * time.Millisecond
+			DeferCleanup(func() {
+				vars.OpenstackMetadataRetries = consts.DefaultOpenstackMetadataRetries
+				vars.OpenstackMetadataBackoffMin = consts.DefaultOpenstackMetadataBackoffMin
+				vars.OpenstackMetadataBackoffMax = consts.DefaultOpenstackMetadataBackoffMax
+				vars.OpenstackMetadataDeadline = consts.DefaultOpenstackMetadataDeadline
+			})
+
+			start := time.Now()
+			_, _, _, err := getOpenstackDataFromMetadataService(context.Background(), service)
+			Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(server.Requests("/" + ospMetaDataJSON)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Context("metadataServiceBaseURL", func() {
+		It("uses the IPv4 metadata service on the hosts with an IPv4 address", func() {
+			Expect(metadataServiceBaseURL([]hostInterface{
+				{name: "eth0", addrs: []stdnet.IP{stdnet.ParseIP("fe80::f816:3eff:fe00:1")}},
+				{name: "eth1", addrs: []stdnet.IP{stdnet.ParseIP("fe80::f816:3eff:fe00:2"), stdnet.ParseIP("10.0.0.5")}},
+			})).To(Equal(ospMetaDataBaseURL))
+			Expect(metadataServiceBaseURL(nil)).To(Equal(ospMetaDataBaseURL))
+		})
+
+		It("uses the IPv6 metadata service through the first link-local interface on the IPv6-only hosts", func() {
+			baseURL := metadataServiceBaseURL([]hostInterface{
+				{name: "eth0", addrs: []stdnet.IP{stdnet.ParseIP("2001:db8::5")}},
+				{name: "eth1", addrs: []stdnet.IP{stdnet.ParseIP("2001:db8:1::5"), stdnet.ParseIP("fe80::f816:3eff:fe00:2")}},
+				{name: "eth2", addrs: []stdnet.IP{stdnet.ParseIP("fe80::f816:3eff:fe00:3"), stdnet.ParseIP("169.254.10.1")}},
+			})
+			Expect(baseURL).To(Equal("http://[fe80::a9fe:a9fe%25eth1]/openstack/2018-08-27"))
+			parsed, err := url.Parse(baseURL + "/" + ospMetaDataJSON)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Hostname()).To(Equal("fe80::a9fe:a9fe%eth1"))
+		})
 	})
 })
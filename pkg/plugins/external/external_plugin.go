@@ -0,0 +1,200 @@
+// Package external runs the out-of-tree vendor plugins installed in the plugins directory of
+// the host. A plugin is an executable speaking the protocol below, so vendors can support new
+// NICs without changes to the config daemon.
+//
+// For every call the daemon runs the executable with a Request encoded as JSON on its stdin
+// and expects a Response encoded as JSON on its stdout:
+//
+//   - Info returns the name and spec version of the plugin, and the PCI vendor IDs it handles.
+//     The plugin is loaded for every node whose NICs match one of the vendors, or for all the
+//     nodes if it returns no vendor.
+//   - OnNodeStateChange receives the desired SriovNetworkNodeState and returns whether the node
+//     must be drained and/or rebooted to apply it.
+//   - Apply receives the same SriovNetworkNodeState and applies it.
+//
+// A non-zero exit code or a non-empty Response.Error fails the call.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// APIVersion is the version of the protocol spoken with the plugins
+const APIVersion = "sriovnetwork.openshift.io/plugin/v1"
+
+const (
+	CommandInfo              = "Info"
+	CommandOnNodeStateChange = "OnNodeStateChange"
+	CommandApply             = "Apply"
+)
+
+var (
+	// callTimeout bounds the Info and OnNodeStateChange calls
+	callTimeout = time.Minute
+	// applyTimeout bounds the Apply calls, configuring the NICs can be slow
+	applyTimeout = 10 * time.Minute
+)
+
+// Request is sent to the plugin on its stdin
+type Request struct {
+	APIVersion string                                `json:"apiVersion"`
+	Command    string                                `json:"command"`
+	NodeState  *sriovnetworkv1.SriovNetworkNodeState `json:"nodeState,omitempty"`
+}
+
+// Response is read from the plugin stdout
+type Response struct {
+	// Name and SpecVersion are returned by Info
+	Name        string `json:"name,omitempty"`
+	SpecVersion string `json:"specVersion,omitempty"`
+	// Vendors are the PCI vendor IDs handled by the plugin, returned by Info
+	Vendors []string `json:"vendors,omitempty"`
+	// NeedDrain and NeedReboot are returned by OnNodeStateChange
+	NeedDrain  bool `json:"needDrain,omitempty"`
+	NeedReboot bool `json:"needReboot,omitempty"`
+	// Error fails the call
+	Error string `json:"error,omitempty"`
+}
+
+// ExternalPlugin is a vendor plugin implemented by an executable
+type ExternalPlugin struct {
+	path        string
+	name        string
+	specVersion string
+	vendors     []string
+	desireState *sriovnetworkv1.SriovNetworkNodeState
+}
+
+// ErrConfinedDaemon is returned by Discover when plugins are installed while the daemon is confined, the
+// privileged helper doesn't run the plugins
+var ErrConfinedDaemon = errors.New("external plugins are not supported by the confined config daemon")
+
+// Discover loads the plugins installed in the plugins directory of the host, the plugins
+// failing to answer the Info call are skipped
+func Discover() ([]*ExternalPlugin, error) {
+	dir := filepath.Join(utils.GetHostExtension(), consts.ExternalPluginsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Discover(): failed to read plugins directory %s: %v", dir, err)
+	}
+
+	plugins := []*ExternalPlugin{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		p := &ExternalPlugin{path: filepath.Join(consts.ExternalPluginsDir, entry.Name())}
+		if utils.HostExecutorSet() {
+			return nil, fmt.Errorf("%w: %s is installed", ErrConfinedDaemon, p.path)
+		}
+		resp, err := p.call(CommandInfo, nil, callTimeout)
+		if err != nil {
+			log.Log.Error(err, "Discover(): failed to load external plugin", "path", p.path)
+			continue
+		}
+		if resp.Name == "" {
+			log.Log.Error(nil, "Discover(): external plugin returned no name", "path", p.path)
+			continue
+		}
+		p.name, p.specVersion, p.vendors = resp.Name, resp.SpecVersion, resp.Vendors
+		log.Log.Info("Discover(): found external plugin", "name", p.name, "path", p.path, "vendors", p.vendors)
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// Name returns the name of the plugin
+func (p *ExternalPlugin) Name() string {
+	return p.name
+}
+
+// Spec returns the version of the spec expected by the plugin
+func (p *ExternalPlugin) Spec() string {
+	return p.specVersion
+}
+
+// HandlesVendor returns true if the plugin must be loaded for a NIC of the vendor
+func (p *ExternalPlugin) HandlesVendor(vendor string) bool {
+	if len(p.vendors) == 0 {
+		return true
+	}
+	for _, v := range p.vendors {
+		if v == vendor {
+			return true
+		}
+	}
+	return false
+}
+
+// OnNodeStateChange Invoked when SriovNetworkNodeState CR is created or updated, return if need dain and/or reboot node
+func (p *ExternalPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState) (bool, bool, error) {
+	log.Log.Info("external plugin OnNodeStateChange()", "name", p.name)
+	p.desireState = new
+	resp, err := p.call(CommandOnNodeStateChange, new, callTimeout)
+	if err != nil {
+		return false, false, err
+	}
+	return resp.NeedDrain, resp.NeedReboot, nil
+}
+
+// Apply config change
+func (p *ExternalPlugin) Apply() error {
+	log.Log.Info("external plugin Apply()", "name", p.name)
+	_, err := p.call(CommandApply, p.desireState, applyTimeout)
+	return err
+}
+
+// call runs the plugin executable, chrooted to the host file system when the daemon runs in a container
+func (p *ExternalPlugin) call(command string, nodeState *sriovnetworkv1.SriovNetworkNodeState, timeout time.Duration) (*Response, error) {
+	req, err := json.Marshal(&Request{APIVersion: APIVersion, Command: command, NodeState: nodeState})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if vars.InChroot {
+		cmd = exec.CommandContext(ctx, filepath.Join(vars.FilesystemRoot, p.path))
+	} else {
+		cmd = exec.CommandContext(ctx, "chroot", utils.GetHostExtension(), p.path)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("external plugin %s %s failed: %v: %s", p.path, command, err, stderr.String())
+	}
+
+	resp := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("failed to decode the %s response of external plugin %s: %v", command, p.path, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("external plugin %s %s failed: %s", p.path, command, resp.Error)
+	}
+	return resp, nil
+}
+
+var _ plugin.VendorPlugin = &ExternalPlugin{}
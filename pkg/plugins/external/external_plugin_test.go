@@ -0,0 +1,133 @@
+package external
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// testPlugin answers each command with a canned response and logs the requests next to itself
+const testPlugin = `#!/bin/sh
+req=$(cat)
+echo "$req" >> "$(dirname "$0")/requests.log"
+case "$req" in
+  *'"command":"Info"'*) echo '{"name": "acme", "specVersion": "1.0", "vendors": ["1d0f"]}' ;;
+  *'"command":"OnNodeStateChange"'*) echo '{"needDrain": true}' ;;
+  *'"command":"Apply"'*) echo '{"error": "firmware update failed"}' ;;
+esac
+`
+
+func installPlugins(t *testing.T, plugins map[string]string) string {
+	root := t.TempDir()
+	dir := filepath.Join(root, consts.ExternalPluginsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, script := range plugins {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	savedRoot, savedInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = savedRoot, savedInChroot
+	})
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := installPlugins(t, map[string]string{
+		"acme":   testPlugin,
+		"broken": "#!/bin/sh\nexit 1\n",
+	})
+	g.Expect(os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644)).To(Succeed())
+
+	plugins, err := Discover()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plugins).To(HaveLen(1))
+	g.Expect(plugins[0].Name()).To(Equal("acme"))
+	g.Expect(plugins[0].Spec()).To(Equal("1.0"))
+	g.Expect(plugins[0].HandlesVendor("1d0f")).To(BeTrue())
+	g.Expect(plugins[0].HandlesVendor("8086")).To(BeFalse())
+}
+
+// deniedExecutor stands for the privileged helper of the confined daemon
+type deniedExecutor struct{}
+
+func (deniedExecutor) RunCommand(root, command string, args ...string) (string, string, error) {
+	return "", "", errors.New("not allowed")
+}
+
+func (deniedExecutor) WriteFile(root, path string, data []byte, perm os.FileMode) error {
+	return errors.New("not allowed")
+}
+
+func (deniedExecutor) RemoveFile(root, path string) error {
+	return errors.New("not allowed")
+}
+
+func TestDiscoverConfinedDaemon(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := installPlugins(t, map[string]string{})
+	utils.SetHostExecutor(deniedExecutor{})
+	t.Cleanup(func() { utils.SetHostExecutor(nil) })
+
+	// the daemon runs without plugins
+	plugins, err := Discover()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plugins).To(BeEmpty())
+
+	// the plugin isn't run by the confined daemon
+	g.Expect(os.WriteFile(filepath.Join(dir, "acme"), []byte(testPlugin), 0755)).To(Succeed())
+	_, err = Discover()
+	g.Expect(err).To(MatchError(ErrConfinedDaemon))
+	g.Expect(filepath.Join(dir, "requests.log")).ToNot(BeAnExistingFile())
+}
+
+func TestDiscoverWithoutPluginsDir(t *testing.T) {
+	g := NewGomegaWithT(t)
+	vars.FilesystemRoot, vars.InChroot = t.TempDir(), true
+	t.Cleanup(func() { vars.FilesystemRoot, vars.InChroot = "", false })
+
+	plugins, err := Discover()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plugins).To(BeEmpty())
+}
+
+func TestCalls(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := installPlugins(t, map[string]string{"acme": testPlugin})
+
+	plugins, err := Discover()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plugins).To(HaveLen(1))
+	p := plugins[0]
+
+	needDrain, needReboot, err := p.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{
+		Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:00:06.0", NumVfs: 4}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(needDrain).To(BeTrue())
+	g.Expect(needReboot).To(BeFalse())
+
+	err = p.Apply()
+	g.Expect(err).To(MatchError(ContainSubstring("firmware update failed")))
+
+	requests, err := os.ReadFile(filepath.Join(dir, "requests.log"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(requests)).To(ContainSubstring(`"apiVersion":"` + APIVersion + `"`))
+	g.Expect(string(requests)).To(ContainSubstring(`"command":"Apply","nodeState":{`))
+	g.Expect(string(requests)).To(ContainSubstring(`"pciAddress":"0000:00:06.0"`))
+}
@@ -1,10 +1,8 @@
 package generic
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"os/exec"
 	"reflect"
 	"strconv"
 	"strings"
@@ -16,6 +14,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/portsplit"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
@@ -59,6 +58,8 @@ type GenericPlugin struct {
 	DesiredKernelArgs map[string]bool
 	pfsToSkip         map[string]bool
 	helpers           helper.HostHelpersInterface
+	// reloadVfioPci is true when the vfio_pci module is loaded with other options than the desired ones
+	reloadVfioPci bool
 }
 
 const scriptsPath = "bindata/scripts/enable-kargs.sh"
@@ -110,6 +111,11 @@ func (p *GenericPlugin) Spec() string {
 // OnNodeStateChange Invoked when SriovNetworkNodeState CR is created or updated, return if need drain and/or reboot node
 func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState) (needDrain bool, needReboot bool, err error) {
 	log.Log.Info("generic plugin OnNodeStateChange()")
+	if vars.PlatformType != consts.Baremetal {
+		// in a virtual machine the plugin provisions the VFs of the SR-IOV capable PFs, the devices of the
+		// platform are left to the virtual plugin
+		new = new.SelectPlatformDevices(false)
+	}
 	p.DesireState = new
 
 	needDrain = p.needDrainNode(new.Spec.Interfaces, new.Status.Interfaces)
@@ -121,9 +127,41 @@ func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 	if needReboot {
 		needDrain = true
 	}
+
+	reloadVfioPci, err := p.syncVfioModuleOptions(new)
+	if err != nil {
+		return needDrain, needReboot, err
+	}
+	if reloadVfioPci {
+		// reloading the module unbinds the VFs of the DPDK workloads
+		needDrain = true
+	}
 	return
 }
 
+// syncVfioModuleOptions writes the options of the vfio_pci module requested by the policies to modprobe.d and
+// returns true if the loaded module must be reloaded for them to apply
+func (p *GenericPlugin) syncVfioModuleOptions(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	options := sriovnetworkv1.VfioPciModuleOptions(state.Spec.Interfaces)
+	if _, err := p.helpers.WriteModprobeConf(consts.VfioPciModule, options); err != nil {
+		log.Log.Error(err, "generic plugin syncVfioModuleOptions(): failed to write the vfio_pci options")
+		return false, err
+	}
+	p.reloadVfioPci = false
+	if options == nil {
+		return false, nil
+	}
+	applied, err := p.helpers.KernelModuleOptionsApplied(consts.VfioPciModule, options)
+	if err != nil {
+		return false, err
+	}
+	if !applied {
+		log.Log.Info("generic plugin syncVfioModuleOptions(): vfio_pci must be reloaded to apply its options", "options", options)
+		p.reloadVfioPci = true
+	}
+	return p.reloadVfioPci, nil
+}
+
 func (p *GenericPlugin) syncDriverState() error {
 	for _, driverState := range p.DriverStateMap {
 		if !driverState.DriverLoaded && driverState.NeedDriverFunc(p.DesireState, driverState) {
@@ -142,6 +180,14 @@ func (p *GenericPlugin) syncDriverState() error {
 func (p *GenericPlugin) Apply() error {
 	log.Log.Info("generic plugin Apply()", "desiredState", p.DesireState.Spec)
 
+	if p.reloadVfioPci {
+		if err := p.helpers.ReloadKernelModules(consts.VfioPciModule); err != nil {
+			log.Log.Error(err, "generic plugin Apply(): failed to reload the vfio_pci module")
+			return err
+		}
+		p.reloadVfioPci = false
+	}
+
 	if p.LastState != nil {
 		log.Log.Info("generic plugin Apply()", "lastState", p.LastState.Spec)
 		if reflect.DeepEqual(p.LastState.Spec.Interfaces, p.DesireState.Spec.Interfaces) {
@@ -200,12 +246,9 @@ func needDriverCheckVdpaType(state *sriovnetworkv1.SriovNetworkNodeState, driver
 // setKernelArg Tries to add the kernel args via ostree or grubby.
 func setKernelArg(karg string) (bool, error) {
 	log.Log.Info("generic plugin setKernelArg()")
-	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("/bin/sh", scriptsPath, karg)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
+	// the script is run by the privileged helper when the config daemon runs confined
+	stdout, _, err := utils.New().RunCommand("/bin/sh", scriptsPath, karg)
+	if err != nil {
 		// if grubby is not there log and assume kernel args are set correctly.
 		if utils.IsCommandNotFound(err) {
 			log.Log.Error(err, "generic plugin setKernelArg(): grubby or ostree command not found. Please ensure that kernel arg are set",
@@ -216,7 +259,7 @@ func setKernelArg(karg string) (bool, error) {
 		return false, err
 	}
 
-	i, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	i, err := strconv.Atoi(strings.TrimSpace(stdout))
 	if err == nil {
 		if i > 0 {
 			log.Log.Info("generic plugin setKernelArg(): need to reboot node for kernel arg", "karg", karg)
@@ -283,7 +326,13 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 						"address", iface.PciAddress)
 					break
 				}
-				if sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) {
+				// the change of the PTP time source of the node is refused, the PF is left as it is
+				if sriovnetworkv1.PtpSourceRefused(&iface, &ifaceStatus) {
+					log.Log.V(2).Info("generic plugin needDrainNode(): no need drain, the PF is the PTP time source of the node",
+						"address", iface.PciAddress)
+					break
+				}
+				if sriovnetworkv1.NeedToDrainForSriov(&iface, &ifaceStatus) {
 					log.Log.V(2).Info("generic plugin needDrainNode(): need drain, for PCI address request update",
 						"address", iface.PciAddress)
 					needDrain = true
@@ -316,6 +365,13 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 				continue
 			}
 
+			if ifaceStatus.Ptp != nil && ifaceStatus.Ptp.TimeSource {
+				log.Log.Info("generic plugin needDrainNode(): PF is the PTP time source of the node, its reset is refused. Skipping drain",
+					"name", ifaceStatus.Name,
+					"address", ifaceStatus.PciAddress)
+				continue
+			}
+
 			log.Log.V(2).Info("generic plugin needDrainNode(): need drain since interface needs to be reset",
 				"interface", ifaceStatus)
 			needDrain = true
@@ -328,8 +384,9 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 func (p *GenericPlugin) addVfioDesiredKernelArg(state *sriovnetworkv1.SriovNetworkNodeState) {
 	driverState := p.DriverStateMap[Vfio]
 	if !driverState.DriverLoaded && driverState.NeedDriverFunc(state, driverState) {
-		p.addToDesiredKernelArgs(consts.KernelArgIntelIommu)
-		p.addToDesiredKernelArgs(consts.KernelArgIommuPt)
+		for _, karg := range p.helpers.GetIommuKernelArgs() {
+			p.addToDesiredKernelArgs(karg)
+		}
 	}
 }
 
@@ -366,9 +423,41 @@ func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeSta
 		needReboot = true
 	}
 
+	updateNode, err = portSplitPending(state)
+	if err != nil {
+		log.Log.Error(err, "generic plugin needRebootNode(): failed to check the pending port splits")
+		return false, err
+	}
+	if updateNode {
+		log.Log.V(2).Info("generic plugin needRebootNode(): need reboot for applying the port split")
+		needReboot = true
+	}
+
 	return needReboot, nil
 }
 
+// portSplitPending returns true when the split of a port requested in the current boot isn't reported by the NIC,
+// the NIC applies it at the next boot
+func portSplitPending(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	pending := false
+	for _, iface := range state.Spec.Interfaces {
+		if iface.PortSplit == 0 {
+			continue
+		}
+		for _, ifaceStatus := range state.Status.Interfaces {
+			if ifaceStatus.PciAddress != iface.PciAddress || iface.PortSplit == max(ifaceStatus.PortSplit, 1) {
+				continue
+			}
+			needReboot, err := portsplit.NeedReboot(iface.PciAddress, iface.PortSplit)
+			if err != nil {
+				return false, err
+			}
+			pending = pending || needReboot
+		}
+	}
+	return pending, nil
+}
+
 // getPfsToSkip return a map of devices pci addresses to should be configured via systemd instead if the legacy mode
 // we skip devices in switchdev mode and Bluefield card in ConnectX mode
 func getPfsToSkip(ns *sriovnetworkv1.SriovNetworkNodeState, mlxHelper mlx.MellanoxInterface) (map[string]bool, error) {
@@ -10,6 +10,9 @@ import (
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	mock_helper "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
 
 func TestGenericPlugin(t *testing.T) {
@@ -79,6 +82,7 @@ var _ = Describe("Generic plugin", func() {
 			}
 
 			hostHelper.EXPECT().WriteSwitchdevConfFile(networkNodeState, map[string]bool{"0000:00:00.0": false}).Return(false, nil)
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", nil).Return(false, nil)
 			needDrain, needReboot, err := genericPlugin.OnNodeStateChange(networkNodeState)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(needReboot).To(BeFalse())
@@ -135,12 +139,81 @@ var _ = Describe("Generic plugin", func() {
 			}
 
 			hostHelper.EXPECT().WriteSwitchdevConfFile(networkNodeState, map[string]bool{"0000:00:00.0": false}).Return(false, nil)
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", nil).Return(false, nil)
 			needDrain, needReboot, err := genericPlugin.OnNodeStateChange(networkNodeState)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(needReboot).To(BeFalse())
 			Expect(needDrain).To(BeTrue())
 		})
 
+		It("should not drain for the refused change of the PTP time source", func() {
+			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     4,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:   "netdevice",
+							PolicyName:   "policy-1",
+							ResourceName: "resource-1",
+							VfRange:      "0-3",
+						}}}},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     2,
+						TotalVfs:   8,
+						Name:       "sriovif1",
+						Driver:     "ice",
+						Ptp:        &sriovnetworkv1.PtpInfo{Clock: "ptp0", TimeSource: true},
+					}},
+				},
+			}
+
+			hostHelper.EXPECT().WriteSwitchdevConfFile(networkNodeState, map[string]bool{"0000:00:00.0": false}).Return(false, nil)
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", nil).Return(false, nil)
+			needDrain, _, err := genericPlugin.OnNodeStateChange(networkNodeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needDrain).To(BeFalse())
+
+			// the policy allows the disruption of the time source
+			networkNodeState.Spec.Interfaces[0].AllowPtpSourceDisruption = true
+			hostHelper.EXPECT().WriteSwitchdevConfFile(networkNodeState, map[string]bool{"0000:00:00.0": false}).Return(false, nil)
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", nil).Return(false, nil)
+			needDrain, _, err = genericPlugin.OnNodeStateChange(networkNodeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needDrain).To(BeTrue())
+		})
+
+		It("should reboot for the port split the NIC applies at the next boot", func() {
+			origInChroot := vars.InChroot
+			DeferCleanup(func() { vars.InChroot = origInChroot })
+			vars.InChroot = false
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"host/etc/sriov-operator", "proc/sys/kernel/random"},
+				Files: map[string][]byte{
+					"/proc/sys/kernel/random/boot_id":           []byte("boot-1\n"),
+					"/host/etc/sriov-operator/port-splits.json": []byte(`{"0000:00:00.0": {"portSplit": 4, "bootID": "boot-1"}}`),
+				},
+			})
+			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:00:00.0", PortSplit: 4}},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{PciAddress: "0000:00:00.0", Name: "ens1f0np0", TotalVfs: 8}},
+				},
+			}
+
+			hostHelper.EXPECT().WriteSwitchdevConfFile(networkNodeState, map[string]bool{"0000:00:00.0": false}).Return(false, nil)
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", nil).Return(false, nil)
+			needDrain, needReboot, err := genericPlugin.OnNodeStateChange(networkNodeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+			Expect(needDrain).To(BeTrue())
+		})
+
 		It("should load vfio_pci driver", func() {
 			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
 				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
@@ -310,6 +383,38 @@ var _ = Describe("Generic plugin", func() {
 			concretePlugin.loadDriverForTests(networkNodeState)
 			Expect(driverState.DriverLoaded).To(BeTrue())
 		})
+
+		It("should reload vfio_pci to disable the idle D3 state", func() {
+			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     2,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:    "vfio-pci",
+							PolicyName:    "policy-1",
+							ResourceName:  "resource-1",
+							VfRange:       "0-1",
+							DisableIdleD3: true,
+						}}}},
+				},
+			}
+			options := map[string]string{"disable_idle_d3": "1"}
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", options).Return(true, nil)
+			hostHelper.EXPECT().KernelModuleOptionsApplied("vfio_pci", options).Return(false, nil)
+
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			reload, err := concretePlugin.syncVfioModuleOptions(networkNodeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reload).To(BeTrue())
+
+			// the options already apply to the loaded module
+			hostHelper.EXPECT().WriteModprobeConf("vfio_pci", options).Return(false, nil)
+			hostHelper.EXPECT().KernelModuleOptionsApplied("vfio_pci", options).Return(true, nil)
+			reload, err = concretePlugin.syncVfioModuleOptions(networkNodeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reload).To(BeFalse())
+		})
 	})
 
 })
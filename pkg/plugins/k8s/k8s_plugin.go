@@ -13,6 +13,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	plugins "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -180,6 +181,11 @@ func (p *K8sPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState)
 // Apply config change
 func (p *K8sPlugin) Apply() error {
 	log.Log.Info("k8s plugin Apply()")
+	if utils.HostExecutorSet() && p.updateTarget.needUpdate() {
+		// the units run programs on the host, the privileged helper doesn't write them
+		return fmt.Errorf("k8s plugin Apply(): the systemd units %s are not installed by the confined config daemon, disable the %s feature gate",
+			p.updateTarget, consts.ConfinedDaemonFeatureGate)
+	}
 	if err := p.updateSwitchdevService(); err != nil {
 		return err
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
 	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -191,4 +192,17 @@ var _ = Describe("K8s plugin", func() {
 		Expect(needDrain).To(BeTrue())
 		Expect(k8sPlugin.Apply()).NotTo(HaveOccurred())
 	})
+	It("refuses the units in the confined daemon", func() {
+		setIsSystemdMode(true)
+		// the executor of the confined daemon isn't called, the units aren't written
+		utils.SetHostExecutor(struct{ utils.HostExecutor }{})
+		DeferCleanup(func() { utils.SetHostExecutor(nil) })
+
+		hostHelper.EXPECT().IsServiceEnabled("/etc/systemd/system/sriov-config.service").Return(false, nil)
+		hostHelper.EXPECT().IsServiceEnabled("/etc/systemd/system/sriov-config-post-network.service").Return(false, nil)
+
+		_, _, err := k8sPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sPlugin.Apply()).To(MatchError(ContainSubstring("not installed by the confined config daemon")))
+	})
 })
@@ -6,6 +6,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
@@ -58,7 +59,8 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 
 	// fill mellanoxNicsStatus
 	for _, iface := range new.Status.Interfaces {
-		if iface.Vendor != mlx.MellanoxVendorID {
+		// the firmware of the RoCE Express adapters is managed by IBM Z
+		if iface.Vendor != mlx.MellanoxVendorID || iface.ZpciUID != "" {
 			continue
 		}
 
@@ -116,6 +118,14 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 		}
 		needReboot = needReboot || needLinkChange
 
+		// the firmware change reboots the node, it is refused before the node is drained when a port of the card is
+		// the PTP time source of the node
+		if (totalVfsNeedReboot || sriovEnNeedReboot || needLinkChange) && ptpSourceRefused(mellanoxNicsStatus[pciPrefix]) {
+			return false, false, snerrors.Wrap(snerrors.ErrPtpTimeSource, fmt.Errorf("a port of the card %s is the PTP "+
+				"time source of the node, set allowPtpSourceDisruption in its policies to allow the firmware change of the "+
+				"card that reboots the node", pciPrefix))
+		}
+
 		// no FW changes allowed when NIC is externally managed
 		if ifaceSpec.ExternallyManaged {
 			if totalVfsNeedReboot || totalVfsChangeWithoutReboot {
@@ -181,6 +191,20 @@ func (p *MellanoxPlugin) Apply() error {
 	return p.helpers.MlxConfigFW(attributesToChange)
 }
 
+// ptpSourceRefused returns true if a port of the NIC is the PTP time source of the node and its policies don't allow
+// its disruption
+func ptpSourceRefused(nicPortsMap map[string]sriovnetworkv1.InterfaceExt) bool {
+	for _, iface := range nicPortsMap {
+		if iface.Ptp == nil || !iface.Ptp.TimeSource {
+			continue
+		}
+		if spec, found := mellanoxNicsSpec[iface.PciAddress]; !found || !spec.AllowPtpSourceDisruption {
+			return true
+		}
+	}
+	return false
+}
+
 // nicHasExternallyManagedPFs returns true if one of the ports(interface) of the NIC is marked as externally managed
 // in StoreManagerInterface.
 func (p *MellanoxPlugin) nicHasExternallyManagedPFs(nicPortsMap map[string]sriovnetworkv1.InterfaceExt) bool {
@@ -22,6 +22,8 @@ type VirtualPlugin struct {
 	LastState      *sriovnetworkv1.SriovNetworkNodeState
 	LoadVfioDriver uint
 	helpers        helper.HostHelpersInterface
+	// reloadVfio is set when the loaded vfio modules must be reloaded to apply their options, after the drain
+	reloadVfio bool
 }
 
 const (
@@ -56,12 +58,20 @@ func (p *VirtualPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 	needDrain = false
 	needReboot = false
 	err = nil
+	// the SR-IOV capable PFs of the virtual machine are provisioned by the generic plugin
+	new = new.SelectPlatformDevices(true)
 	p.DesireState = new
 
-	if p.LoadVfioDriver != loaded {
-		if needVfioDriver(new) {
+	if needVfioDriver(new) {
+		if p.LoadVfioDriver != loaded {
 			p.LoadVfioDriver = loading
 		}
+		p.reloadVfio, err = p.syncVfioModuleOptions(new)
+		if err != nil {
+			return
+		}
+		// reloading the modules unbinds the VFs of the DPDK workloads
+		needDrain = p.reloadVfio
 	}
 
 	return
@@ -71,19 +81,17 @@ func (p *VirtualPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 func (p *VirtualPlugin) Apply() error {
 	log.Log.Info("virtual plugin Apply()", "desired-state", p.DesireState.Spec)
 
-	if p.LoadVfioDriver == loading {
-		// In virtual deployments of Kubernetes where the underlying virtualization platform does not support a virtualized iommu
-		// the VFIO PCI driver needs to be loaded with a special flag.
-		// This is the case for OpenStack deployments where the underlying virtualization platform is KVM.
-		// NOTE: if VFIO was already loaded for some reason, we will not try to load it again with the new options.
-		kernelArgs := "enable_unsafe_noiommu_mode=1"
-		if err := p.helpers.LoadKernelModule("vfio", kernelArgs); err != nil {
-			log.Log.Error(err, "virtual plugin Apply(): fail to load vfio kmod")
+	if p.reloadVfio {
+		log.Log.Info("virtual plugin Apply(): reload the vfio modules to apply their options")
+		if err := p.helpers.ReloadKernelModules(consts.VfioPciModule, consts.VfioIommuType1Module, consts.VfioModule); err != nil {
+			log.Log.Error(err, "virtual plugin Apply(): fail to reload the vfio kmods")
 			return err
 		}
-
-		if err := p.helpers.LoadKernelModule("vfio_pci"); err != nil {
-			log.Log.Error(err, "virtual plugin Apply(): fail to load vfio_pci kmod")
+		p.reloadVfio = false
+		p.LoadVfioDriver = loaded
+	}
+	if p.LoadVfioDriver == loading {
+		if err := p.loadVfioModules(); err != nil {
 			return err
 		}
 		p.LoadVfioDriver = loaded
@@ -110,6 +118,51 @@ func (p *VirtualPlugin) Apply() error {
 	return nil
 }
 
+// vfioModuleOptions returns the options of the vfio modules. In virtual deployments of Kubernetes where the
+// underlying virtualization platform does not support a virtualized iommu, e.g. OpenStack on KVM, vfio needs the
+// unsafe no-IOMMU mode. The options of vfio_pci are requested by the policies.
+func vfioModuleOptions(state *sriovnetworkv1.SriovNetworkNodeState) map[string]map[string]string {
+	return map[string]map[string]string{
+		consts.VfioModule:    {consts.VfioOptionUnsafeNoIommuMode: "1"},
+		consts.VfioPciModule: sriovnetworkv1.VfioPciModuleOptions(state.Spec.Interfaces),
+	}
+}
+
+// syncVfioModuleOptions writes the options of the vfio modules to modprobe.d, so they also apply when the modules
+// are loaded by something else, and returns true if the loaded modules must be reloaded for them to apply: the
+// vfio-pci VFs are not usable without the options anyway
+func (p *VirtualPlugin) syncVfioModuleOptions(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	reload := false
+	for module, options := range vfioModuleOptions(state) {
+		if _, err := p.helpers.WriteModprobeConf(module, options); err != nil {
+			log.Log.Error(err, "virtual plugin syncVfioModuleOptions(): fail to write the module options", "module", module)
+			return false, err
+		}
+		applied, err := p.helpers.KernelModuleOptionsApplied(module, options)
+		if err != nil {
+			return false, err
+		}
+		if !applied {
+			log.Log.Info("virtual plugin syncVfioModuleOptions(): the module must be reloaded to apply its options", "module", module)
+			reload = true
+		}
+	}
+	return reload, nil
+}
+
+// loadVfioModules loads the vfio modules, their options are read from modprobe.d
+func (p *VirtualPlugin) loadVfioModules() error {
+	if err := p.helpers.LoadKernelModule(consts.VfioModule); err != nil {
+		log.Log.Error(err, "virtual plugin loadVfioModules(): fail to load vfio kmod")
+		return err
+	}
+	if err := p.helpers.LoadKernelModule(consts.VfioPciModule); err != nil {
+		log.Log.Error(err, "virtual plugin loadVfioModules(): fail to load vfio_pci kmod")
+		return err
+	}
+	return nil
+}
+
 func (p *VirtualPlugin) SetSystemdFlag() {
 }
 
@@ -153,7 +206,7 @@ func needUpdateVirtual(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetwor
 	// The device MTU is set by the platform
 	// The NumVfs is always 1
 	if iface.NumVfs > 0 {
-		for _, vf := range ifaceStatus.VFs {
+		for _, vf := range ifaceStatus.GetVirtualFunctions() {
 			ingroup := false
 			for _, group := range iface.VfGroups {
 				if sriovnetworkv1.IndexInRange(vf.VfID, group.VfRange) {
@@ -0,0 +1,90 @@
+// Package portsplit records the port splits the NICs apply at the next boot of the node. The config daemon splits
+// the port of a PF with devlink, a NIC that doesn't report the split once done applies it when the node reboots. The
+// split is recorded with the boot of the node it was requested in, the node is rebooted once for it, and a split the
+// NIC still doesn't report after the reboot fails instead of rebooting the node again.
+package portsplit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// Pending is a port split requested but not reported yet by the NIC
+type Pending struct {
+	PortSplit int    `json:"portSplit"`
+	BootID    string `json:"bootID"`
+}
+
+// Load returns the pending port splits of the node by PCI address
+func Load() (map[string]Pending, error) {
+	pending := map[string]Pending{}
+	data, err := os.ReadFile(utils.GetHostExtensionPath(consts.PortSplitsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return pending, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pending port splits: %v", err)
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse the pending port splits: %v", err)
+	}
+	return pending, nil
+}
+
+// Save writes the pending port splits of the node, and removes the file when no split is pending
+func Save(pending map[string]Pending) error {
+	path := utils.GetHostExtensionPath(consts.PortSplitsFile)
+	if len(pending) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the pending port splits: %v", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the pending port splits: %v", err)
+	}
+	return nil
+}
+
+// BootID returns the ID of the current boot of the node
+func BootID() (string, error) {
+	bootID, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcBootID))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the boot ID of the node: %v", err)
+	}
+	return strings.TrimSpace(string(bootID)), nil
+}
+
+// NeedReboot returns true when the split of the port of the PF into portSplit ports was requested in the current
+// boot of the node, the NIC applies it at the next boot. It returns an error when the split was requested before the
+// last reboot and the NIC still doesn't report it.
+func NeedReboot(pciAddress string, portSplit int) (bool, error) {
+	pending, err := Load()
+	if err != nil {
+		return false, err
+	}
+	split, found := pending[pciAddress]
+	if !found || split.PortSplit != portSplit {
+		return false, nil
+	}
+	bootID, err := BootID()
+	if err != nil {
+		return false, err
+	}
+	if split.BootID != bootID {
+		return false, fmt.Errorf("the port of %s isn't split into %d ports after the reboot of the node", pciAddress, portSplit)
+	}
+	return true, nil
+}
@@ -0,0 +1,44 @@
+package portsplit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func TestNeedReboot(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root, clean, err := (&fakefilesystem.FS{
+		Dirs:  []string{consts.SriovConfBasePath, "proc/sys/kernel/random"},
+		Files: map[string][]byte{consts.ProcBootID: []byte("boot-1\n")},
+	}).Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+
+	g.Expect(NeedReboot("0000:3b:00.0", 4)).To(BeFalse())
+	g.Expect(Save(map[string]Pending{"0000:3b:00.0": {PortSplit: 4, BootID: "boot-1"}})).To(Succeed())
+	g.Expect(NeedReboot("0000:3b:00.0", 4)).To(BeTrue())
+	// another split of the port isn't requested yet
+	g.Expect(NeedReboot("0000:3b:00.0", 2)).To(BeFalse())
+
+	// the node is rebooted once, the split the NIC still doesn't report fails
+	g.Expect(os.WriteFile(filepath.Join(root, consts.ProcBootID), []byte("boot-2\n"), 0644)).To(Succeed())
+	_, err = NeedReboot("0000:3b:00.0", 4)
+	g.Expect(err).To(MatchError(ContainSubstring("isn't split into 4 ports after the reboot")))
+
+	g.Expect(Save(map[string]Pending{})).To(Succeed())
+	g.Expect(filepath.Join(root, consts.PortSplitsFile)).ToNot(BeAnExistingFile())
+}
@@ -0,0 +1,231 @@
+// Package preflight validates that a node is able to run SR-IOV workloads before any
+// policy is applied to it. The checks only read the host state, they never change it.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// CheckSriovEnabled verifies that SR-IOV is enabled in the BIOS for every supported NIC
+	CheckSriovEnabled = "SriovEnabled"
+	// CheckIommuEnabled verifies that the IOMMU is enabled on the host
+	CheckIommuEnabled = "IommuEnabled"
+	// CheckFirmwareVersion verifies that the NICs run at least the configured firmware version
+	CheckFirmwareVersion = "FirmwareVersion"
+	// CheckKernelModules verifies that the configured kernel modules are available on the host
+	CheckKernelModules = "KernelModules"
+)
+
+// Options of the pre-flight checks
+type Options struct {
+	// MinFirmwareVersions maps a driver name to the minimum firmware version of the NICs using it
+	MinFirmwareVersions map[string]string
+	// KernelModules that must be loaded or loadable on the host
+	KernelModules []string
+}
+
+// CheckResult is the outcome of a single pre-flight check
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Reason is the machine-readable reason of a failed check
+	Reason  snerrors.Reason `json:"reason,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+// fail marks the check failed with the error, the reason of the check is the reason of the error in the taxonomy
+func (r *CheckResult) fail(err error) {
+	r.Passed = false
+	r.Reason = snerrors.ReasonOf(err)
+	r.Message = err.Error()
+}
+
+// Report is the outcome of all the pre-flight checks of a node, it is stored as JSON in
+// the preflight annotation of the node
+type Report struct {
+	Passed bool          `json:"passed"`
+	Time   metav1.Time   `json:"time"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Failed returns the names of the failed checks
+func (r *Report) Failed() []string {
+	failed := []string{}
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed
+}
+
+// Run executes all the pre-flight checks against the host
+func Run(hostHelpers helper.HostHelpersInterface, opts Options) (*Report, error) {
+	ifaces, err := hostHelpers.DiscoverSriovDevices(hostHelpers)
+	if err != nil {
+		return nil, fmt.Errorf("Run(): failed to discover the SR-IOV devices: %v", err)
+	}
+
+	report := &Report{
+		Time: metav1.Now(),
+		Checks: []CheckResult{
+			checkSriovEnabled(ifaces),
+			checkIommuEnabled(),
+			checkFirmwareVersion(hostHelpers, ifaces, opts.MinFirmwareVersions),
+			checkKernelModules(hostHelpers, opts.KernelModules),
+		},
+	}
+	report.Passed = len(report.Failed()) == 0
+	log.Log.V(0).Info("Run(): pre-flight checks completed", "passed", report.Passed, "failed", report.Failed())
+	return report, nil
+}
+
+// Annotate stores the report in the preflight annotation of the node
+func Annotate(ctx context.Context, kubeClient kubernetes.Interface, nodeName string, report *Report) error {
+	value, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("Annotate(): failed to marshal the pre-flight report: %v", err)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{consts.PreflightAnnotation: string(value)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Annotate(): failed to marshal the node patch: %v", err)
+	}
+	_, err = kubeClient.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("Annotate(): failed to annotate node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+// GetReport returns the report stored in the preflight annotation of the node, or nil if
+// the node was never checked
+func GetReport(node *corev1.Node) (*Report, error) {
+	value, ok := node.Annotations[consts.PreflightAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	report := &Report{}
+	if err := json.Unmarshal([]byte(value), report); err != nil {
+		return nil, fmt.Errorf("GetReport(): failed to unmarshal the pre-flight report of node %s: %v", node.Name, err)
+	}
+	return report, nil
+}
+
+// checkSriovEnabled reports the supported NICs exposing no VF, which means SR-IOV is
+// disabled in the BIOS or in the NIC firmware
+func checkSriovEnabled(ifaces []sriovnetworkv1.InterfaceExt) CheckResult {
+	result := CheckResult{Name: CheckSriovEnabled, Passed: true}
+	disabled := []string{}
+	for _, iface := range ifaces {
+		if iface.TotalVfs == 0 {
+			disabled = append(disabled, iface.PciAddress)
+		}
+	}
+	if len(disabled) > 0 {
+		result.fail(snerrors.Wrap(snerrors.ErrSriovDisabled,
+			fmt.Errorf("SR-IOV is disabled in the BIOS or NIC firmware for %s", strings.Join(disabled, ", "))))
+	}
+	return result
+}
+
+// checkIommuEnabled reports an error if the kernel created no IOMMU group
+func checkIommuEnabled() CheckResult {
+	result := CheckResult{Name: CheckIommuEnabled, Passed: true}
+	groups, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysKernelIommuGroups))
+	if err != nil || len(groups) == 0 {
+		message := "no IOMMU group found, enable VT-d/AMD-Vi in the BIOS and the IOMMU in the kernel arguments"
+		if vars.Architecture == consts.ArchARM64 {
+			message = "no IOMMU group found, enable the SMMU in the firmware and check that the kernel doesn't disable it"
+		}
+		result.fail(snerrors.Wrap(snerrors.ErrIommuDisabled, errors.New(message)))
+	}
+	return result
+}
+
+// checkFirmwareVersion compares the firmware version of the NICs with the minimum
+// version configured for their driver
+func checkFirmwareVersion(hostHelpers helper.HostHelpersInterface, ifaces []sriovnetworkv1.InterfaceExt, minVersions map[string]string) CheckResult {
+	result := CheckResult{Name: CheckFirmwareVersion, Passed: true}
+	problems := []string{}
+	tooOld := false
+	for _, iface := range ifaces {
+		minVersion, ok := minVersions[iface.Driver]
+		if !ok || iface.Name == "" {
+			continue
+		}
+		version := hostHelpers.GetNetDevFirmwareVersion(iface.Name)
+		older, err := isOlderVersion(version, minVersion)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", iface.Name, err))
+		} else if older {
+			tooOld = true
+			problems = append(problems, fmt.Sprintf("%s: firmware %s is older than %s", iface.Name, version, minVersion))
+		}
+	}
+	if len(problems) > 0 {
+		err := errors.New(strings.Join(problems, "; "))
+		if tooOld {
+			err = snerrors.Wrap(snerrors.ErrFirmwareTooOld, err)
+		}
+		result.fail(err)
+	}
+	return result
+}
+
+// checkKernelModules reports the kernel modules neither loaded nor known to modinfo
+func checkKernelModules(hostHelpers helper.HostHelpersInterface, modules []string) CheckResult {
+	result := CheckResult{Name: CheckKernelModules, Passed: true}
+	missing := []string{}
+	for _, module := range modules {
+		if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysModule, module)); err == nil {
+			continue
+		}
+		_, _, err := hostHelpers.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s modinfo -n %s", utils.GetChrootExtension(), module))
+		if err != nil {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) > 0 {
+		result.fail(snerrors.Wrap(snerrors.ErrKernelModuleMissing,
+			fmt.Errorf("kernel modules not available: %s", strings.Join(missing, ", "))))
+	}
+	return result
+}
+
+// isOlderVersion compares the leading dotted numeric part of the firmware versions,
+// drivers append build identifiers after it, e.g. "4.20 0x80017785 1.3346.0"
+func isOlderVersion(version, minVersion string) (bool, error) {
+	current, err := utils.ParseFirmwareVersion(version)
+	if err != nil {
+		return false, err
+	}
+	minimum, err := utils.ParseFirmwareVersion(minVersion)
+	if err != nil {
+		return false, err
+	}
+	return utils.CompareVersions(current, minimum) < 0, nil
+}
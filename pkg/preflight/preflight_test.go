@@ -0,0 +1,146 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	snerrors "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/errors"
+	mock_helper "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func useFakeFS(t *testing.T, fs *fakefilesystem.FS) {
+	root, clean, err := fs.Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedRoot := vars.FilesystemRoot
+	vars.FilesystemRoot = root
+	t.Cleanup(func() {
+		vars.FilesystemRoot = savedRoot
+		clean()
+	})
+}
+
+func TestRun(t *testing.T) {
+	g := NewGomegaWithT(t)
+	useFakeFS(t, &fakefilesystem.FS{
+		Dirs: []string{"/sys/kernel/iommu_groups/0", "/sys/module/vfio_pci"},
+	})
+
+	mockCtrl := gomock.NewController(t)
+	hostHelper := mock_helper.NewMockHostHelpersInterface(mockCtrl)
+	hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return([]sriovnetworkv1.InterfaceExt{
+		{PciAddress: "0000:d8:00.0", Name: "ens1f0", Driver: "ice", TotalVfs: 64},
+		{PciAddress: "0000:d8:00.1", Name: "ens1f1", Driver: "ice", TotalVfs: 0},
+		{PciAddress: "0000:3b:00.0", Name: "ens2f0", Driver: "mlx5_core", TotalVfs: 8},
+	}, nil)
+	hostHelper.EXPECT().GetNetDevFirmwareVersion("ens1f0").Return("4.20 0x80017785 1.3346.0")
+	hostHelper.EXPECT().GetNetDevFirmwareVersion("ens1f1").Return("3.0 0x80001234 1.2000.0")
+	hostHelper.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", fmt.Errorf("not found"))
+
+	report, err := Run(hostHelper, Options{
+		MinFirmwareVersions: map[string]string{"ice": "4.1"},
+		KernelModules:       []string{"vfio_pci", "vhost_net"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(report.Passed).To(BeFalse())
+	g.Expect(report.Failed()).To(Equal([]string{CheckSriovEnabled, CheckFirmwareVersion, CheckKernelModules}))
+	g.Expect(report.Checks[0].Message).To(ContainSubstring("0000:d8:00.1"))
+	g.Expect(report.Checks[0].Message).ToNot(ContainSubstring("0000:d8:00.0"))
+	g.Expect(report.Checks[2].Message).To(Equal("ens1f1: firmware 3.0 0x80001234 1.2000.0 is older than 4.1"))
+	g.Expect(report.Checks[3].Message).To(Equal("kernel modules not available: vhost_net"))
+	g.Expect(report.Checks[0].Reason).To(Equal(snerrors.ReasonSriovDisabled))
+	g.Expect(report.Checks[2].Reason).To(Equal(snerrors.ReasonFirmwareTooOld))
+	g.Expect(report.Checks[3].Reason).To(Equal(snerrors.ReasonKernelModuleMissing))
+}
+
+func TestRunPassed(t *testing.T) {
+	g := NewGomegaWithT(t)
+	useFakeFS(t, &fakefilesystem.FS{
+		Dirs: []string{"/sys/kernel/iommu_groups/0", "/sys/module/vfio_pci"},
+	})
+
+	mockCtrl := gomock.NewController(t)
+	hostHelper := mock_helper.NewMockHostHelpersInterface(mockCtrl)
+	hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return([]sriovnetworkv1.InterfaceExt{
+		{PciAddress: "0000:d8:00.0", Name: "ens1f0", Driver: "ice", TotalVfs: 64},
+	}, nil)
+
+	report, err := Run(hostHelper, Options{KernelModules: []string{"vfio_pci"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(report.Passed).To(BeTrue())
+	g.Expect(report.Failed()).To(BeEmpty())
+}
+
+func TestCheckIommuEnabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	useFakeFS(t, &fakefilesystem.FS{
+		Dirs: []string{"/sys/kernel/iommu_groups"},
+	})
+
+	result := checkIommuEnabled()
+	g.Expect(result.Passed).To(BeFalse())
+	g.Expect(result.Message).To(ContainSubstring("no IOMMU group found"))
+	g.Expect(result.Reason).To(Equal(snerrors.ReasonIommuDisabled))
+}
+
+func TestIsOlderVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		version, minVersion string
+		older               bool
+	}{
+		{"16.35.2000 (MT_0000000012)", "16.35", false},
+		{"16.34.1002 (MT_0000000012)", "16.35", true},
+		{"4.20 0x80017785 1.3346.0", "4.20", false},
+		{"4.2", "4.20", true},
+		{"8", "8.1", true},
+		{"9", "8.1", false},
+	} {
+		older, err := isOlderVersion(tc.version, tc.minVersion)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(older).To(Equal(tc.older), "%s < %s", tc.version, tc.minVersion)
+	}
+
+	_, err := isOlderVersion("", "1.0")
+	g.Expect(err).To(HaveOccurred())
+	_, err = isOlderVersion("N/A", "1.0")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestAnnotate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	kubeClient := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}})
+	report := &Report{
+		Passed: false,
+		Time:   metav1.Now(),
+		Checks: []CheckResult{{Name: CheckIommuEnabled, Passed: false, Message: "no IOMMU group found"}},
+	}
+	g.Expect(Annotate(context.Background(), kubeClient, "worker-0", report)).To(Succeed())
+
+	node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), "worker-0", metav1.GetOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(node.Annotations).To(HaveKey(consts.PreflightAnnotation))
+
+	stored, err := GetReport(node)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stored.Passed).To(BeFalse())
+	g.Expect(stored.Failed()).To(Equal([]string{CheckIommuEnabled}))
+
+	stored, err = GetReport(&corev1.Node{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stored).To(BeNil())
+}
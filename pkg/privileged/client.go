@@ -0,0 +1,92 @@
+package privileged
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// callTimeout bounds a call to the helper, the commands installing packages are the slowest
+const callTimeout = 10 * time.Minute
+
+// ExitError is the error of a command run by the helper exiting with a non-zero code
+type ExitError struct {
+	Command string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s: exit status %d", e.Command, e.Code)
+}
+
+// ExitCode returns the exit code of the command, like the exec.ExitError of the commands run by the daemon
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Client runs the commands and writes the files of the host through the privileged helper, it implements the
+// utils.HostExecutor of the confined config daemon
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the privileged helper serving on the unix socket
+func Dial(socket string) (*Client, error) {
+	conn, err := grpc.Dial("unix://"+socket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the privileged helper on %s: %v", socket, err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the connection to the helper
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// RunCommand runs the command in the root directory, a non-zero exit code is returned as an *ExitError along
+// with the output of the command
+func (c *Client) RunCommand(root, command string, args ...string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp := &RunCommandResponse{}
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/RunCommand", &RunCommandRequest{Root: root, Command: command, Args: args}, resp,
+		grpc.WaitForReady(true))
+	if err != nil {
+		return "", "", fmt.Errorf("privileged helper failed to run %s: %w", command, err)
+	}
+	if resp.ExitCode != 0 {
+		return resp.Stdout, resp.Stderr, &ExitError{Command: command, Code: resp.ExitCode}
+	}
+	return resp.Stdout, resp.Stderr, nil
+}
+
+// WriteFile writes the file relative to the root directory
+func (c *Client) WriteFile(root, path string, data []byte, perm os.FileMode) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/WriteFile", &WriteFileRequest{Root: root, Path: path, Data: data, Perm: uint32(perm)},
+		&WriteFileResponse{}, grpc.WaitForReady(true))
+	if err != nil {
+		return fmt.Errorf("privileged helper failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// RemoveFile removes the file relative to the root directory
+func (c *Client) RemoveFile(root, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/RemoveFile", &RemoveFileRequest{Root: root, Path: path},
+		&RemoveFileResponse{}, grpc.WaitForReady(true))
+	if err != nil {
+		return fmt.Errorf("privileged helper failed to remove %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package privileged
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// allowedShells are the programs run by their absolute path, the other programs are looked up in defaultPath
+var allowedShells = map[string]bool{"/bin/sh": true, "/bin/bash": true}
+
+// allowedRoots are the directories the commands are run in and the files are relative to
+var allowedRoots = map[string]bool{"": true, "/": true, consts.Host: true}
+
+// allowedPrograms are the argument patterns of the programs run for the config daemon, directly or in a shell
+// script. The words of a pattern match the same argument, except the placeholders, and a placeholder ending with ...
+// matches all the remaining arguments.
+var allowedPrograms = map[string][]string{
+	"apt-get":   {"install -y rdma-core"},
+	"bridge":    {"-j fdb show dev <name>", "fdb del <name> dev <name> self", "fdb add <name> dev <name> self permanent"},
+	"cat":       {"", "<file>"},
+	"cut":       {"-c 13-"},
+	"devlink":   {"-j port show", "-j health show <port>", "port split <port> count <number>", "port unsplit <port>"},
+	"dmesg":     {"--notime"},
+	"dnf":       {"install -y rdma-core"},
+	"grep":      {"<regexp>", "--quiet <regexp>", "-i --quiet <regexp> <file>"},
+	"ip":        {"link set dev <name> down", "link set dev <name> up", "link set dev <name> name <name>"},
+	"lsmod":     {""},
+	"modinfo":   {"-n <name>"},
+	"modprobe":  {"<name> <param>...", "-r <name>", "-a <name>..."},
+	"mstconfig": {"-e -d <name> q", "-d <name> q", "-d <name> -y set <param>..."},
+	"ovs-vsctl": {
+		"--timeout=10 --if-exists get Open_vSwitch . other_config:hw-offload",
+		"--timeout=10 set Open_vSwitch . other_config:hw-offload=true",
+	},
+	"reboot":    {""},
+	"systemctl": {"is-enabled <unit>", "enable <unit>", "try-restart ovs-vswitchd.service", "stop kubelet.service"},
+	"tc": {
+		"qdisc add dev <name> ingress", "qdisc add dev <name> clsact", "-j qdisc show dev <name>",
+		"-j filter show dev <name> <name>", "filter del dev <name> <name> pref <number>",
+		"filter add dev <name> <name> pref <number> <arg>...",
+	},
+	"yum": {"install -y rdma-core"},
+}
+
+// placeholders are the arguments matched by the placeholders of the patterns other than <file>
+var placeholders = map[string]*regexp.Regexp{
+	// a device, a module, a unit or a MAC address, never a path or an option
+	"<name>": regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:@-]*$`),
+	// a unit installed by the daemon
+	"<unit>": regexp.MustCompile(`^(sriov-config|sriov-config-post-network|switchdev-configuration-before-nm|switchdev-configuration-after-nm|NetworkManager|ovs-vswitchd)\.service$`),
+	// a devlink handle, e.g. pci/0000:3b:00.0/1
+	"<port>":   regexp.MustCompile(`^[a-z]+/[0-9A-Fa-f:.]+(/[0-9]+)?$`),
+	"<number>": regexp.MustCompile(`^[0-9]+$`),
+	// a module or a firmware parameter
+	"<param>": regexp.MustCompile(`^[A-Za-z0-9_]+=[A-Za-z0-9_.,:-]*$`),
+	// a word of the match or the action of a tc filter, e.g. an address or a rate
+	"<arg>": regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/+-]*$`),
+	// a pattern of grep, never an option
+	"<regexp>": regexp.MustCompile(`^[^-]`),
+}
+
+// allowedReads are the files the config daemon reads with a program, in the container or in the host
+var allowedReads = []string{
+	"/etc/os-release",
+	"/host/etc/os-release",
+	"/sys/kernel/security/lockdown",
+	"/host/sys/kernel/security/lockdown",
+}
+
+// allowedScripts are the scripts of the image run for the config daemon
+var allowedScripts = map[string]bool{
+	"/bindata/scripts/enable-kargs.sh":       true,
+	"/bindata/scripts/load-udev.sh":          true,
+	"/bindata/scripts/udev-find-sriov-pf.sh": true,
+}
+
+// systemdRunOptions are the options of systemd-run taking a value
+var systemdRunOptions = map[string]bool{"--unit": true, "--description": true}
+
+// allowedWrites are the patterns of the sysfs attributes written for the config daemon
+var allowedWrites = []string{
+	"/sys/bus/*/drivers/*/bind",
+	"/sys/bus/*/drivers/*/unbind",
+	"/sys/bus/*/drivers_probe",
+	"/sys/bus/*/devices/*/driver_override",
+	"/sys/bus/pci/devices/*/sriov_numvfs",
+	"/sys/bus/pci/devices/*/sriov_vf_msix_count",
+	"/sys/bus/pci/devices/*/net/*/mtu",
+	"/sys/class/net/*/ecn/*/*",
+	"/sys/class/net/*/ecn/*/enable/*",
+	"/sys/kernel/debug/mlx5/*/cc_params/*",
+	"/etc/udev/rules.d/10-nm-disable-*.rules",
+	"/etc/udev/rules.d/20-switchdev-*.rules",
+	"/etc/udev/rules.d/30-pf-rename-*.rules",
+	"/etc/modprobe.d/sriov-network-operator-*.conf",
+	"/host/etc/udev/rules.d/10-nm-disable-*.rules",
+	"/host/etc/udev/rules.d/20-switchdev-*.rules",
+	"/host/etc/udev/rules.d/30-pf-rename-*.rules",
+	"/host/etc/modprobe.d/sriov-network-operator-*.conf",
+}
+
+// allowedRemovals are the patterns of the configuration files of the host removed for the config daemon
+var allowedRemovals = []string{
+	"/etc/udev/rules.d/10-nm-unmanaged.rules",
+	"/etc/udev/rules.d/10-nm-disable-*.rules",
+	"/etc/udev/rules.d/20-switchdev-*.rules",
+	"/etc/udev/rules.d/30-pf-rename-*.rules",
+	"/etc/modprobe.d/sriov-network-operator-*.conf",
+	"/host/etc/udev/rules.d/10-nm-unmanaged.rules",
+	"/host/etc/udev/rules.d/10-nm-disable-*.rules",
+	"/host/etc/udev/rules.d/20-switchdev-*.rules",
+	"/host/etc/udev/rules.d/30-pf-rename-*.rules",
+	"/host/etc/modprobe.d/sriov-network-operator-*.conf",
+}
+
+// udevRules are the rules the daemon writes, a udev rule runs programs so the rules written must be one of them
+var udevRules = udevRulePatterns(consts.NMUdevRule, consts.SwitchdevUdevRule, consts.PfRenameUdevRule,
+	consts.PfPortRenameUdevRule)
+
+// udevRulePatterns returns the patterns of the rules of the formats, the values are names, PCI addresses or IDs
+func udevRulePatterns(formats ...string) []*regexp.Regexp {
+	patterns := []*regexp.Regexp{}
+	for _, format := range formats {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(format), "%s", `[A-Za-z0-9_.:|-]*`)
+		patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+	}
+	return patterns
+}
+
+// checkRoot returns an error when the root directory isn't allowed
+func checkRoot(root string) error {
+	if !allowedRoots[root] {
+		return fmt.Errorf("root directory %q is not allowed", root)
+	}
+	return nil
+}
+
+// checkWrite returns an error when the file isn't allowed to be written with the data
+func checkWrite(file string, data []byte) error {
+	if !matchFile(allowedWrites, file) {
+		return fmt.Errorf("writing %s is not allowed", file)
+	}
+	switch filepath.Dir(strings.TrimPrefix(file, consts.Host)) {
+	case consts.UdevRulesFolder:
+		return checkUdevRule(string(data))
+	case consts.ModprobeConfDir:
+		return checkModprobeConf(string(data))
+	}
+	return nil
+}
+
+// checkRemove returns an error when the file isn't allowed to be removed
+func checkRemove(file string) error {
+	if !matchFile(allowedRemovals, file) {
+		return fmt.Errorf("removing %s is not allowed", file)
+	}
+	return nil
+}
+
+// matchFile returns true when the absolute and clean path matches one of the patterns
+func matchFile(patterns []string, file string) bool {
+	if !filepath.IsAbs(file) || filepath.Clean(file) != file {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUdevRule returns an error when the rule isn't one of the rules of the daemon
+func checkUdevRule(rule string) error {
+	for _, pattern := range udevRules {
+		if pattern.MatchString(rule) {
+			return nil
+		}
+	}
+	return fmt.Errorf("udev rule %q is not allowed", rule)
+}
+
+// checkModprobeConf returns an error when the configuration has other lines than comments and module options,
+// the install and remove commands run programs
+func checkModprobeConf(conf string) error {
+	for _, line := range strings.Split(conf, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+			continue
+		}
+		if words[0] != "options" || !matchArgs([]string{"<name>", "<param>..."}, words[1:]) {
+			return fmt.Errorf("modprobe configuration line %q is not allowed", line)
+		}
+	}
+	return nil
+}
+
+// checkCommand returns an error when the command runs a program or a script that isn't allowed. The shell
+// scripts are split in simple commands, each of them must run an allowed program, and the redirections to files
+// and the shell expansions are rejected.
+func checkCommand(command string, args []string) error {
+	switch command {
+	case "/bin/sh", "/bin/bash", "sh", "bash":
+		// the arguments following the script are only its positional parameters
+		if len(args) >= 2 && args[0] == "-c" {
+			return checkScript(args[1])
+		}
+		if len(args) >= 1 && allowedScripts[path.Join("/", args[0])] {
+			return nil
+		}
+		return fmt.Errorf("running %s %s is not allowed", command, strings.Join(args, " "))
+	}
+	return checkProgram(append([]string{command}, args...))
+}
+
+// checkProgram checks the words of a simple command, a chroot to the host runs the rest of the words
+func checkProgram(words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+	if words[0] == "chroot" {
+		if len(words) < 3 || words[1] != consts.Host {
+			return fmt.Errorf("chroot is only allowed to %s", consts.Host)
+		}
+		return checkProgram(words[2:])
+	}
+	if words[0] == "systemd-run" {
+		// the transient unit runs the command following the options
+		for i := 1; i < len(words); i++ {
+			option, _, hasValue := strings.Cut(words[i], "=")
+			switch {
+			case !strings.HasPrefix(option, "-"):
+				return checkCommand(words[i], words[i+1:])
+			case !systemdRunOptions[option]:
+				return fmt.Errorf("systemd-run option %s is not allowed", option)
+			case !hasValue:
+				i++
+			}
+		}
+		return fmt.Errorf("systemd-run without a command is not allowed")
+	}
+	if strings.Contains(words[0], "/") {
+		// the programs are looked up in the directories the daemon can't write to
+		return fmt.Errorf("running %s by its path is not allowed", words[0])
+	}
+	if words[0] == "nsenter" {
+		// the network namespace utils run the command following the namespace
+		if len(words) < 4 || !strings.HasPrefix(words[1], "--net=/") || words[2] != "--" {
+			return fmt.Errorf("nsenter is only allowed to a network namespace")
+		}
+		return checkCommand(words[3], words[4:])
+	}
+	patterns, ok := allowedPrograms[words[0]]
+	if !ok {
+		return fmt.Errorf("running %s is not allowed", words[0])
+	}
+	for _, pattern := range patterns {
+		if matchArgs(strings.Fields(pattern), words[1:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("running %s with the arguments %q is not allowed", words[0], strings.Join(words[1:], " "))
+}
+
+// matchArgs returns true when the arguments match the words of a pattern
+func matchArgs(pattern, args []string) bool {
+	for i, word := range pattern {
+		if placeholder, ok := strings.CutSuffix(word, "..."); ok && i == len(pattern)-1 {
+			if len(args) < i {
+				return false
+			}
+			for _, arg := range args[i:] {
+				if !matchWord(placeholder, arg) {
+					return false
+				}
+			}
+			return true
+		}
+		if i >= len(args) || !matchWord(word, args[i]) {
+			return false
+		}
+	}
+	return len(args) == len(pattern)
+}
+
+// matchWord returns true when the argument matches a word of a pattern
+func matchWord(word, arg string) bool {
+	if word == "<file>" {
+		return slices.Contains(allowedReads, arg)
+	}
+	if placeholder, ok := placeholders[word]; ok {
+		return placeholder.MatchString(arg)
+	}
+	return word == arg
+}
+
+// checkScript splits a shell script in simple commands, honoring the quotes, and checks each of them
+func checkScript(script string) error {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		invalid error
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if err := checkProgram(words); err != nil && invalid == nil {
+			invalid = err
+		}
+		words = nil
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				if quote == '"' && (r == '`' || r == '$') {
+					return fmt.Errorf("shell expansions are not allowed")
+				}
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '>':
+			return fmt.Errorf("redirections are not allowed")
+		case strings.ContainsRune("`$*?[]{}~", r):
+			// the parameters, the command substitutions, the globs and the tilde and brace expansions
+			return fmt.Errorf("shell expansions are not allowed")
+		case r == '|' || r == '&' || r == ';' || r == '(' || r == ')' || r == '<' || r == '\n':
+			endCommand()
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return fmt.Errorf("unterminated quote")
+	}
+	endCommand()
+	return invalid
+}
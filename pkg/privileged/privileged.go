@@ -0,0 +1,134 @@
+// Package privileged splits the config daemon in a confined daemon and a privileged helper. The helper runs the
+// commands and writes the sysfs attributes of the host on behalf of the daemon, through a narrow gRPC API served
+// on a unix socket shared by their containers, and only the commands and the files allowed by its policy.
+package privileged
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// the messages are encoded in JSON, there is no protobuf definition of the API
+const codecName = "json"
+
+const serviceName = "sriovnetwork.PrivilegedHelper"
+
+// RunCommandRequest runs a command of the host
+type RunCommandRequest struct {
+	// Root is the directory the command is run in, empty to run it in the root of the helper
+	Root    string   `json:"root,omitempty"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// RunCommandResponse is the output of a command, a command failing to run is a gRPC error
+type RunCommandResponse struct {
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	// ExitCode is the exit code of the command
+	ExitCode int `json:"exitCode,omitempty"`
+}
+
+// WriteFileRequest writes a file of the host
+type WriteFileRequest struct {
+	// Root is the directory the path is relative to, empty for the root of the helper
+	Root string `json:"root,omitempty"`
+	Path string `json:"path"`
+	Data []byte `json:"data"`
+	Perm uint32 `json:"perm"`
+}
+
+// WriteFileResponse acknowledges a write
+type WriteFileResponse struct{}
+
+// RemoveFileRequest removes a file of the host, a missing file isn't an error
+type RemoveFileRequest struct {
+	// Root is the directory the path is relative to, empty for the root of the helper
+	Root string `json:"root,omitempty"`
+	Path string `json:"path"`
+}
+
+// RemoveFileResponse acknowledges a removal
+type RemoveFileResponse struct{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// helperServer is the API of the privileged helper
+type helperServer interface {
+	RunCommand(context.Context, *RunCommandRequest) (*RunCommandResponse, error)
+	WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error)
+	RemoveFile(context.Context, *RemoveFileRequest) (*RemoveFileResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*helperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "RunCommand", Handler: runCommandHandler},
+		{MethodName: "WriteFile", Handler: writeFileHandler},
+		{MethodName: "RemoveFile", Handler: removeFileHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "privileged.go",
+}
+
+func runCommandHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &RunCommandRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(helperServer).RunCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/RunCommand"}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(helperServer).RunCommand(ctx, req.(*RunCommandRequest))
+	})
+}
+
+func writeFileHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &WriteFileRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(helperServer).WriteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/WriteFile"}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(helperServer).WriteFile(ctx, req.(*WriteFileRequest))
+	})
+}
+
+func removeFileHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &RemoveFileRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(helperServer).RemoveFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/RemoveFile"}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(helperServer).RemoveFile(ctx, req.(*RemoveFileRequest))
+	})
+}
@@ -0,0 +1,264 @@
+package privileged
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+)
+
+func TestCheckCommand(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		args    []string
+		allowed bool
+	}{
+		{"modprobe in the host", "/bin/sh", []string{"-c", "chroot /host modprobe vfio_pci"}, true},
+		{"lsmod piped to grep", "/bin/sh", []string{"-c", `chroot /host lsmod | grep "^mlx5_core"`}, true},
+		{"process substitution", "/bin/sh", []string{"-c", `grep --quiet '\(^ib\|^rdma\)' <(chroot /host lsmod)`}, true},
+		{"reload a driver", "/bin/sh", []string{"-c", "chroot /host modprobe -r mlx5_core && chroot /host modprobe mlx5_core"}, true},
+		{"positional parameters", "/bin/sh", []string{"-c", "cat", "/host/etc/os-release"}, true},
+		{"reboot", "systemd-run", []string{"--unit", "sriov-network-config-daemon-reboot", "--description", "sriov-network-config-daemon reboot node", "/bin/sh", "-c", "systemctl stop kubelet.service; reboot"}, true},
+		{"kernel arguments script", "/bin/sh", []string{"bindata/scripts/enable-kargs.sh", "intel_iommu=on"}, true},
+		{"udev script", "/bin/bash", []string{"/bindata/scripts/load-udev.sh"}, true},
+		{"mstconfig", "mstconfig", []string{"-d", "0000:3b:00.0", "q"}, true},
+		{"firmware settings", "mstconfig", []string{"-d", "0000:3b:00.0", "-y", "set", "SRIOV_EN=True", "NUM_OF_VFS=8"}, true},
+		{"service enabled", "systemctl", []string{"is-enabled", "sriov-config.service"}, true},
+		{"module with parameters", "/bin/sh", []string{"-c", "chroot /host modprobe mlx4_core num_vfs=4 probe_vf=4"}, true},
+		{"modules loaded", "/bin/sh", []string{"-c", "chroot /host modprobe -a ib_core mlx5_ib"}, true},
+		{"rdma-core installed", "/bin/sh", []string{"-c", "chroot /host dnf install -y rdma-core"}, true},
+		{"os name", "/bin/sh", []string{"-c", "cat /host/etc/os-release | grep PRETTY_NAME | cut -c 13-"}, true},
+		{"flow rule", "/bin/sh", []string{"-c", "chroot /host tc filter add dev ens1f0 ingress pref 1000 handle 1 protocol ip flower dst_ip 10.0.0.0/24 action drop cookie 0a1b"}, true},
+		{"port split", "/bin/sh", []string{"-c", "chroot /host devlink port split pci/0000:3b:00.0/1 count 2"}, true},
+		{"unicast MAC", "/bin/sh", []string{"-c", "chroot /host bridge fdb add 02:00:00:00:00:01 dev ens1f0v0 self permanent"}, true},
+		{"command in a network namespace", "nsenter", []string{"--net=/var/run/netns/ns1", "--", "/bin/sh", "-c", "chroot /host ip link set dev ens1f0 up"}, true},
+		{"hardware offload of Open vSwitch", "/bin/sh", []string{"-c", "chroot /host ovs-vsctl --timeout=10 set Open_vSwitch . other_config:hw-offload=true"}, true},
+		{"package other than rdma-core", "/bin/sh", []string{"-c", "chroot /host yum install -y nmap"}, false},
+		{"package removed", "/bin/sh", []string{"-c", "chroot /host apt-get remove -y rdma-core"}, false},
+		{"module removed by its path", "/bin/sh", []string{"-c", "chroot /host modprobe -r /lib/modules/x.ko"}, false},
+		{"module option", "/bin/sh", []string{"-c", "chroot /host modprobe --force mlx5_core"}, false},
+		{"service stopped", "systemctl", []string{"stop", "sshd.service"}, false},
+		{"unit file linked", "systemctl", []string{"enable", "/tmp/evil.service"}, false},
+		{"file read", "/bin/sh", []string{"-c", "cat /host/etc/shadow"}, false},
+		{"file searched", "/bin/sh", []string{"-c", "grep -i --quiet root /host/etc/shadow"}, false},
+		{"grep option as the pattern", "/bin/sh", []string{"-c", "grep --quiet -r /host"}, false},
+		{"firmware reset", "mstconfig", []string{"-d", "0000:3b:00.0", "-y", "reset"}, false},
+		{"tc filter of a file", "/bin/sh", []string{"-c", "chroot /host tc filter add dev ens1f0 ingress pref 1 bpf obj /tmp/x.o"}, false},
+		{"nsenter of the mount namespace", "nsenter", []string{"--mount=/proc/1/ns/mnt", "--", "lsmod"}, false},
+		{"nsenter of a denied program", "nsenter", []string{"--net=/var/run/netns/ns1", "--", "rm", "-rf", "/"}, false},
+		{"program by its path", "/var/run/sriov-network-operator/ip", []string{"link", "set", "dev", "ens1f0", "up"}, false},
+		{"program by its path in a script", "/bin/sh", []string{"-c", "chroot /host /tmp/modprobe vfio_pci"}, false},
+		{"program by a relative path", "/bin/sh", []string{"-c", "./lsmod"}, false},
+		{"parameter expansion", "/bin/sh", []string{"-c", "chroot /host modprobe $MODULE"}, false},
+		{"parameter expansion in double quotes", "/bin/sh", []string{"-c", `grep "${PATTERN}"`}, false},
+		{"glob", "/bin/sh", []string{"-c", "cat /host/etc/*"}, false},
+		{"tilde expansion", "/bin/sh", []string{"-c", "grep --quiet x ~"}, false},
+		{"brace expansion", "/bin/bash", []string{"-c", "chroot /host modprobe {a,b}"}, false},
+		{"input redirection", "/bin/sh", []string{"-c", "grep --quiet root < /host/etc/shadow"}, false},
+		{"unit not installed by the daemon", "systemctl", []string{"enable", "debug-shell.service"}, false},
+		{"removing files", "/bin/sh", []string{"-c", "chroot /host rm -rf /etc"}, false},
+		{"program after an allowed one", "/bin/sh", []string{"-c", "lsmod; curl http://example.com"}, false},
+		{"redirection", "/bin/sh", []string{"-c", "cat /etc/hostname > /etc/passwd"}, false},
+		{"command substitution", "/bin/sh", []string{"-c", "grep $(rm -rf /) file"}, false},
+		{"backticks in double quotes", "/bin/sh", []string{"-c", "grep \"`id`\" file"}, false},
+		{"chroot outside of the host", "/bin/sh", []string{"-c", "chroot /tmp modprobe vfio_pci"}, false},
+		{"unknown systemd-run option", "systemd-run", []string{"--wait", "rm", "-rf", "/"}, false},
+		{"systemd-run of a denied program", "systemd-run", []string{"--unit", "x", "rm", "-rf", "/"}, false},
+		{"unknown script", "/bin/bash", []string{"/tmp/script.sh"}, false},
+		{"unterminated quote", "/bin/sh", []string{"-c", "grep 'x"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			err := checkCommand(tc.command, tc.args)
+			if tc.allowed {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestLookPath(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(root, "usr/sbin"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(root, "usr/sbin/modprobe"), []byte("#!/bin/sh\n"), 0755)).To(Succeed())
+	g.Expect(os.MkdirAll(filepath.Join(root, "var/run/sriov-network-operator"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(root, "var/run/sriov-network-operator/ip"), []byte("#!/bin/sh\n"), 0755)).To(Succeed())
+
+	g.Expect(lookPath(root, "modprobe")).To(Equal("/usr/sbin/modprobe"))
+	g.Expect(lookPath(root, "/bin/sh")).To(Equal("/bin/sh"))
+	// the programs are only found in the directories of the PATH of the helper
+	_, err := lookPath(root, "/var/run/sriov-network-operator/ip")
+	g.Expect(err).To(HaveOccurred())
+	_, err = lookPath(root, "ip")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCheckWrite(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(checkWrite("/sys/bus/pci/devices/0000:3b:00.0/sriov_numvfs", []byte("8"))).To(Succeed())
+	g.Expect(checkWrite("/sys/bus/pci/drivers/vfio-pci/bind", []byte("0000:3b:00.2"))).To(Succeed())
+	g.Expect(checkWrite("/sys/bus/pci/devices/0000:3b:00.2/driver_override", []byte("vfio-pci"))).To(Succeed())
+	g.Expect(checkWrite("/sys/bus/pci/devices/0000:3b:00.0/net/ens1f0/mtu", []byte("9000"))).To(Succeed())
+	g.Expect(checkWrite("/etc/passwd", nil)).ToNot(Succeed())
+	g.Expect(checkWrite("/sys/bus/pci/devices/0000:3b:00.0/../../../../etc/passwd", nil)).ToNot(Succeed())
+	g.Expect(checkWrite("sys/bus/pci/drivers_probe", nil)).ToNot(Succeed())
+
+	// the udev rules and the modprobe configurations run programs, only the ones of the daemon are written
+	rule := fmt.Sprintf(consts.PfRenameUdevRule, "0000:3b:00.0", "sriov3b00f0")
+	g.Expect(checkWrite("/etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules", []byte(rule))).To(Succeed())
+	rule = fmt.Sprintf(consts.PfPortRenameUdevRule, "0000:3b:00.0", "p0s1", "sriov3b00f0")
+	g.Expect(checkWrite("/etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules", []byte(rule))).To(Succeed())
+	rule = fmt.Sprintf(consts.NMUdevRule, "0x1018|0x101e", "0000:3b:00.0")
+	g.Expect(checkWrite("/host/etc/udev/rules.d/10-nm-disable-0000:3b:00.0.rules", []byte(rule))).To(Succeed())
+	g.Expect(checkWrite("/etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules", []byte(`SUBSYSTEM=="net", RUN+="/tmp/x"`))).ToNot(Succeed())
+	g.Expect(checkWrite("/etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules", []byte(rule+"\n"+`RUN+="/tmp/x"`))).ToNot(Succeed())
+	g.Expect(checkWrite("/etc/udev/rules.d/99-other.rules", []byte(rule))).ToNot(Succeed())
+	conf := "# managed by the sriov-network-operator\noptions vfio_pci disable_idle_d3=1\n"
+	g.Expect(checkWrite("/host/etc/modprobe.d/sriov-network-operator-vfio_pci.conf", []byte(conf))).To(Succeed())
+	g.Expect(checkWrite("/host/etc/modprobe.d/sriov-network-operator-vfio_pci.conf", []byte("install vfio_pci /tmp/x\n"))).ToNot(Succeed())
+	g.Expect(checkWrite("/etc/systemd/system/sriov-config.service", []byte("[Service]"))).ToNot(Succeed())
+
+	g.Expect(checkRemove("/etc/udev/rules.d/10-nm-unmanaged.rules")).To(Succeed())
+	g.Expect(checkRemove("/host/etc/modprobe.d/sriov-network-operator-vfio_pci.conf")).To(Succeed())
+	g.Expect(checkRemove("/host/etc/modprobe.d/blacklist.conf")).ToNot(Succeed())
+	g.Expect(checkRemove("/sys/bus/pci/devices/0000:3b:00.0/sriov_numvfs")).ToNot(Succeed())
+	g.Expect(checkRoot(consts.Host)).To(Succeed())
+	g.Expect(checkRoot("/tmp")).ToNot(Succeed())
+}
+
+func startHelper(t *testing.T, server *Server) *Client {
+	// the path of a unix socket is limited to 108 characters, the test temp directories are too long
+	dir, err := os.MkdirTemp("", "ph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "helper.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.Serve(ctx, socket) }()
+	client, err := Dial(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		cancel()
+		<-done
+		os.RemoveAll(dir)
+	})
+	return client
+}
+
+func TestHelper(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := t.TempDir()
+	device := filepath.Join(root, "sys/bus/pci/devices/0000:3b:00.0")
+	g.Expect(os.MkdirAll(device, 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(device, "sriov_numvfs"), []byte("0"), 0644)).To(Succeed())
+
+	client := startHelper(t, &Server{filesystemRoot: root})
+	// the test file isn't one of the files the daemon reads
+	reads := allowedReads
+	allowedReads = append(slices.Clone(reads), filepath.Join(device, "sriov_numvfs"))
+	t.Cleanup(func() { allowedReads = reads })
+
+	stdout, _, err := client.RunCommand("", "cat", filepath.Join(device, "sriov_numvfs"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stdout).To(Equal("0"))
+
+	_, _, err = client.RunCommand("", "/bin/sh", "-c", "grep -i --quiet 8 "+filepath.Join(device, "sriov_numvfs"))
+	var exitErr *ExitError
+	g.Expect(errors.As(err, &exitErr)).To(BeTrue())
+	g.Expect(exitErr.ExitCode()).To(Equal(1))
+	g.Expect(utils.IsCommandNotFound(err)).To(BeFalse())
+
+	_, _, err = client.RunCommand("", "rm", filepath.Join(device, "sriov_numvfs"))
+	g.Expect(err).To(MatchError(ContainSubstring("running rm is not allowed")))
+	_, _, err = client.RunCommand("", "cat", "/etc/hostname")
+	g.Expect(err).To(MatchError(ContainSubstring("running cat with the arguments \"/etc/hostname\" is not allowed")))
+	_, _, err = client.RunCommand("/tmp", "cat", "/etc/os-release")
+	g.Expect(err).To(MatchError(ContainSubstring("not allowed")))
+
+	// the daemon writes the sysfs attributes through the helper once it is set as the host executor
+	utils.SetHostExecutor(client)
+	t.Cleanup(func() { utils.SetHostExecutor(nil) })
+	g.Expect(utils.WriteHostFile(filepath.Join(device, "sriov_numvfs"), []byte("8"), 0644)).To(Succeed())
+	g.Expect(os.ReadFile(filepath.Join(device, "sriov_numvfs"))).To(Equal([]byte("8")))
+	g.Expect(utils.WriteHostFile(filepath.Join(root, "etc/passwd"), []byte("x"), 0644)).ToNot(Succeed())
+	g.Expect(filepath.Join(root, "etc/passwd")).ToNot(BeAnExistingFile())
+
+	stdout, _, err = utils.New().RunCommand("/bin/sh", "-c", "cat "+filepath.Join(device, "sriov_numvfs"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stdout).To(Equal("8"))
+
+	// the configuration of the host is mounted read-only in the daemon, the helper writes and removes its files
+	rule := filepath.Join(root, "etc/udev/rules.d/30-pf-rename-0000:3b:00.0.rules")
+	g.Expect(os.MkdirAll(filepath.Dir(rule), 0755)).To(Succeed())
+	g.Expect(utils.WriteHostFile(rule, []byte(fmt.Sprintf(consts.PfRenameUdevRule, "0000:3b:00.0", "sriov0")), 0644)).To(Succeed())
+	g.Expect(rule).To(BeAnExistingFile())
+	g.Expect(utils.RemoveHostFile(rule)).To(Succeed())
+	g.Expect(rule).ToNot(BeAnExistingFile())
+	g.Expect(utils.RemoveHostFile(rule)).To(Succeed())
+	g.Expect(utils.RemoveHostFile(filepath.Join(root, "etc/passwd"))).ToNot(Succeed())
+}
+
+func TestSeccompProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	data, err := SeccompProfile()
+	g.Expect(err).ToNot(HaveOccurred())
+	profile := seccompProfile{}
+	g.Expect(json.Unmarshal(data, &profile)).To(Succeed())
+	g.Expect(profile.DefaultAction).To(Equal("SCMP_ACT_ALLOW"))
+	g.Expect(profile.Syscalls).To(HaveLen(1))
+	g.Expect(profile.Syscalls[0].Action).To(Equal("SCMP_ACT_ERRNO"))
+	g.Expect(profile.Syscalls[0].Names).To(ContainElements("mount", "init_module", "kexec_load", "reboot"))
+	// the daemon chroots to the host and configures the devices through netlink
+	g.Expect(profile.Syscalls[0].Names).ToNot(ContainElements("chroot", "socket", "bind"))
+}
+
+func TestSELinuxPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	policy := SELinuxPolicy()
+	g.Expect(policy).To(ContainSubstring("(type sriov_config_daemon_t)"))
+	g.Expect(policy).To(ContainSubstring("(typeattributeset container_domain (sriov_config_daemon_t))"))
+	g.Expect(policy).To(ContainSubstring("(allow sriov_config_daemon_t sriov_config_daemon_t (capability (chown dac_override fowner net_admin net_raw sys_chroot)))"))
+	g.Expect(policy).To(ContainSubstring("(allow sriov_config_daemon_t spc_t (unix_stream_socket (connectto)))"))
+}
+
+func TestInstallProfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+	mockCtrl := gomock.NewController(t)
+	runner := mock_utils.NewMockCmdInterface(mockCtrl)
+
+	// SELinux disabled, only the seccomp profile is installed
+	root := t.TempDir()
+	g.Expect(InstallProfiles(root, runner)).To(Succeed())
+	profile, err := SeccompProfile()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.ReadFile(filepath.Join(root, consts.KubeletSeccompDir, consts.ConfinedDaemonSeccompProfile))).To(Equal(profile))
+	g.Expect(filepath.Join(root, consts.ConfinedDaemonSELinuxModule)).ToNot(BeAnExistingFile())
+
+	// SELinux enabled, the module is installed once
+	g.Expect(os.MkdirAll(filepath.Join(root, "sys/fs/selinux"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(root, "sys/fs/selinux/enforce"), []byte("1"), 0644)).To(Succeed())
+	runner.EXPECT().RunCommand("chroot", root, "semodule", "-i", consts.ConfinedDaemonSELinuxModule).Return("", "", nil).Times(1)
+	g.Expect(InstallProfiles(root, runner)).To(Succeed())
+	g.Expect(os.ReadFile(filepath.Join(root, consts.ConfinedDaemonSELinuxModule))).To(Equal([]byte(SELinuxPolicy())))
+	g.Expect(InstallProfiles(root, runner)).To(Succeed())
+}
@@ -0,0 +1,164 @@
+package privileged
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// deniedSyscalls are the system calls the confined config daemon never makes: the host changes it can't make
+// through netlink are made by the privileged helper
+var deniedSyscalls = []string{
+	"acct", "add_key", "bpf", "clock_adjtime", "clock_settime", "create_module", "delete_module", "finit_module",
+	"fsconfig", "fsmount", "fsopen", "fspick", "get_kernel_syms", "init_module", "ioperm", "iopl", "kcmp",
+	"kexec_file_load", "kexec_load", "keyctl", "lookup_dcookie", "mount", "mount_setattr", "move_mount",
+	"name_to_handle_at", "nfsservctl", "open_by_handle_at", "open_tree", "perf_event_open", "pivot_root",
+	"process_vm_readv", "process_vm_writev", "ptrace", "query_module", "quotactl", "reboot", "request_key", "setns",
+	"settimeofday", "swapoff", "swapon", "sysfs", "_sysctl", "umount", "umount2", "unshare", "uselib",
+	"userfaultfd", "ustat", "vm86", "vm86old",
+}
+
+type seccompProfile struct {
+	DefaultAction string           `json:"defaultAction"`
+	Architectures []string         `json:"architectures"`
+	Syscalls      []seccompSyscall `json:"syscalls"`
+}
+
+type seccompSyscall struct {
+	Names    []string `json:"names"`
+	Action   string   `json:"action"`
+	ErrnoRet int      `json:"errnoRet"`
+}
+
+// SeccompProfile returns the seccomp profile of the confined config daemon, in the format of the container
+// runtimes. The system calls of the daemon are allowed except the ones changing the host outside of the devices.
+func SeccompProfile() ([]byte, error) {
+	return json.MarshalIndent(seccompProfile{
+		DefaultAction: "SCMP_ACT_ALLOW",
+		Architectures: []string{"SCMP_ARCH_X86_64", "SCMP_ARCH_X86", "SCMP_ARCH_AARCH64", "SCMP_ARCH_PPC64LE", "SCMP_ARCH_S390X"},
+		Syscalls: []seccompSyscall{{
+			Names:  deniedSyscalls,
+			Action: "SCMP_ACT_ERRNO",
+			// EPERM
+			ErrnoRet: 1,
+		}},
+	}, "", "  ")
+}
+
+// selinuxRule allows the confined config daemon to access the objects of a type
+type selinuxRule struct {
+	target string
+	class  string
+	perms  string
+}
+
+const (
+	readDirPerms   = "getattr open read search ioctl lock"
+	readFilePerms  = "getattr open read ioctl lock"
+	writeDirPerms  = "getattr open read search ioctl lock write add_name remove_name"
+	writeFilePerms = "getattr open read ioctl lock create write append rename unlink setattr"
+	netlinkPerms   = "create bind getattr setattr read write"
+)
+
+// selinuxRules are the accesses of the confined config daemon besides the ones of the containers
+var selinuxRules = []selinuxRule{
+	// discover the devices in sysfs and read the host filesystem mounted on /host
+	{"sysfs_t", "dir", readDirPerms},
+	{"sysfs_t", "file", readFilePerms},
+	{"sysfs_t", "lnk_file", "getattr read"},
+	{"file_type", "dir", readDirPerms},
+	{"file_type", "file", readFilePerms},
+	{"file_type", "lnk_file", "getattr read"},
+	// write the configuration of the operator, the udev rules, the module options and the systemd units
+	{"etc_t", "dir", writeDirPerms},
+	{"etc_t", "file", writeFilePerms},
+	{"udev_rules_t", "dir", writeDirPerms},
+	{"udev_rules_t", "file", writeFilePerms},
+	{"modules_conf_t", "dir", writeDirPerms},
+	{"modules_conf_t", "file", writeFilePerms},
+	{"systemd_unit_file_t", "dir", writeDirPerms},
+	{"systemd_unit_file_t", "file", writeFilePerms},
+	// chroot to the host, configure the devices through netlink and listen to the uevents and LLDP
+	{"self", "capability", "chown dac_override fowner net_admin net_raw sys_chroot"},
+	{"self", "netlink_route_socket", netlinkPerms + " nlmsg_read nlmsg_write"},
+	{"self", "netlink_generic_socket", netlinkPerms},
+	{"self", "netlink_rdma_socket", netlinkPerms},
+	{"self", "netlink_kobject_uevent_socket", "create bind getattr setattr read"},
+	{"self", "packet_socket", netlinkPerms + " ioctl"},
+	// delegate the privileged changes to the helper
+	{"spc_t", "unix_stream_socket", "connectto"},
+}
+
+// SELinuxPolicy returns the SELinux policy module of the confined config daemon, in the Common Intermediate
+// Language. Its type is a container type extended with the accesses of the daemon.
+func SELinuxPolicy() string {
+	t := consts.ConfinedDaemonSELinuxType
+	var b strings.Builder
+	fmt.Fprintf(&b, "; SELinux policy of the confined sriov-network-config-daemon, generated by the sriov-network-operator\n")
+	fmt.Fprintf(&b, "(type %s)\n", t)
+	fmt.Fprintf(&b, "(roletype system_r %s)\n", t)
+	for _, attribute := range []string{"domain", "container_domain", "svirt_sandbox_domain", "mcs_constrained_type", "sandbox_net_domain"} {
+		fmt.Fprintf(&b, "(typeattributeset %s (%s))\n", attribute, t)
+	}
+	fmt.Fprintf(&b, "(allow container_runtime_t %s (key (create link read search setattr view write)))\n", t)
+	for _, rule := range selinuxRules {
+		target := rule.target
+		if target == "self" {
+			target = t
+		}
+		fmt.Fprintf(&b, "(allow %s %s (%s (%s)))\n", t, target, rule.class, rule.perms)
+	}
+	return b.String()
+}
+
+// InstallProfiles installs the seccomp profile of the confined config daemon in the seccomp directory of the
+// kubelet and, on the hosts with SELinux enabled, its SELinux policy module. hostRoot is the root of the host
+// filesystem, the policy module is installed by the semodule command of the host.
+func InstallProfiles(hostRoot string, runner utils.CmdInterface) error {
+	profile, err := SeccompProfile()
+	if err != nil {
+		return err
+	}
+	profilePath := filepath.Join(hostRoot, consts.KubeletSeccompDir, consts.ConfinedDaemonSeccompProfile)
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the seccomp profiles directory: %v", err)
+	}
+	if err := os.WriteFile(profilePath, profile, 0644); err != nil {
+		return fmt.Errorf("failed to write the seccomp profile: %v", err)
+	}
+	log.Log.Info("InstallProfiles(): seccomp profile installed", "path", profilePath)
+
+	if _, err := os.Stat(filepath.Join(hostRoot, "/sys/fs/selinux/enforce")); errors.Is(err, os.ErrNotExist) {
+		log.Log.Info("InstallProfiles(): SELinux is disabled on the host, skipping the SELinux policy")
+		return nil
+	}
+	policy := []byte(SELinuxPolicy())
+	modulePath := filepath.Join(hostRoot, consts.ConfinedDaemonSELinuxModule)
+	// the module is only installed again when it changed, semodule takes a while
+	if current, err := os.ReadFile(modulePath); err == nil && bytes.Equal(current, policy) {
+		log.Log.Info("InstallProfiles(): SELinux policy already installed")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(modulePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the SELinux module directory: %v", err)
+	}
+	if err := os.WriteFile(modulePath, policy, 0644); err != nil {
+		return fmt.Errorf("failed to write the SELinux policy: %v", err)
+	}
+	if _, stderr, err := runner.RunCommand("chroot", hostRoot, "semodule", "-i", consts.ConfinedDaemonSELinuxModule); err != nil {
+		// the module is written again by the next attempt
+		os.Remove(modulePath)
+		return fmt.Errorf("failed to install the SELinux policy: %v: %s", err, stderr)
+	}
+	log.Log.Info("InstallProfiles(): SELinux policy installed", "type", consts.ConfinedDaemonSELinuxType)
+	return nil
+}
@@ -0,0 +1,152 @@
+package privileged
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultPath is the PATH the programs are looked up in when they are run in a root directory
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// Server is the privileged helper, it runs the commands and writes the files allowed by its policy
+type Server struct {
+	// filesystemRoot prefixes the files written, for the tests
+	filesystemRoot string
+}
+
+// NewServer returns the privileged helper
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Serve serves the API of the helper on the unix socket until the context is done
+func (s *Server) Serve(ctx context.Context, socket string) error {
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return fmt.Errorf("failed to create the directory of the socket: %v", err)
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the stale socket: %v", err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", socket, err)
+	}
+	// only the processes running as root, i.e. the config daemon, connect to the socket
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict the access to the socket: %v", err)
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&serviceDesc, s)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	log.Log.Info("Serve(): serving the privileged helper", "socket", socket)
+	return server.Serve(listener)
+}
+
+// RunCommand runs a command allowed by the policy
+func (s *Server) RunCommand(ctx context.Context, req *RunCommandRequest) (*RunCommandResponse, error) {
+	if err := checkRoot(req.Root); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err := checkCommand(req.Command, req.Args); err != nil {
+		log.Log.Info("RunCommand(): command denied", "command", req.Command, "args", req.Args, "reason", err.Error())
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	log.Log.V(2).Info("RunCommand()", "root", req.Root, "command", req.Command, "args", req.Args)
+
+	program, err := lookPath(req.Root, req.Command)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, program, req.Args...)
+	cmd.Dir = "/"
+	// the shell scripts look the programs up in the same directories
+	cmd.Env = []string{"PATH=" + defaultPath}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if req.Root != "" && req.Root != "/" {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Chroot: req.Root}
+	}
+	err = cmd.Run()
+	resp := &RunCommandResponse{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		resp.ExitCode = exitErr.ExitCode()
+		return resp, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
+
+// WriteFile writes a file allowed by the policy
+func (s *Server) WriteFile(ctx context.Context, req *WriteFileRequest) (*WriteFileResponse, error) {
+	if err := checkRoot(req.Root); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	file := strings.TrimPrefix(req.Path, s.filesystemRoot)
+	if err := checkWrite(file, req.Data); err != nil {
+		log.Log.Info("WriteFile(): write denied", "path", req.Path, "reason", err.Error())
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	log.Log.V(2).Info("WriteFile()", "root", req.Root, "path", req.Path)
+	if err := os.WriteFile(filepath.Join(req.Root, s.filesystemRoot, file), req.Data, os.FileMode(req.Perm)); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &WriteFileResponse{}, nil
+}
+
+// RemoveFile removes a file allowed by the policy
+func (s *Server) RemoveFile(ctx context.Context, req *RemoveFileRequest) (*RemoveFileResponse, error) {
+	if err := checkRoot(req.Root); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	file := strings.TrimPrefix(req.Path, s.filesystemRoot)
+	if err := checkRemove(file); err != nil {
+		log.Log.Info("RemoveFile(): removal denied", "path", req.Path, "reason", err.Error())
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	log.Log.V(2).Info("RemoveFile()", "root", req.Root, "path", req.Path)
+	if err := os.Remove(filepath.Join(req.Root, s.filesystemRoot, file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &RemoveFileResponse{}, nil
+}
+
+// lookPath returns the path of the program in the root directory, the program is found after the chroot of the
+// command so it is looked up relative to the root. The programs are only looked up in the directories of
+// defaultPath, never in the directories the daemon can write to, and the shells are the only absolute paths.
+func lookPath(root, command string) (string, error) {
+	if strings.Contains(command, "/") {
+		if !allowedShells[command] {
+			return "", fmt.Errorf("running %s by its path is not allowed", command)
+		}
+		return command, nil
+	}
+	for _, dir := range filepath.SplitList(defaultPath) {
+		candidate := filepath.Join(dir, command)
+		if info, err := os.Stat(filepath.Join(root, candidate)); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s of %s", command, defaultPath, root)
+}
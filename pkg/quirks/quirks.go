@@ -0,0 +1,108 @@
+// Package quirks holds the driver quirks of the SriovOperatorConfig, the steps of the configuration of the VFs
+// skipped to work around the bugs of the drivers and of the firmwares of the NICs
+package quirks
+
+import (
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var (
+	mu     sync.RWMutex
+	quirks []sriovnetworkv1.DriverQuirk
+)
+
+// Set replaces the driver quirks applied by the next configurations of the VFs
+func Set(driverQuirks []sriovnetworkv1.DriverQuirk) {
+	mu.Lock()
+	defer mu.Unlock()
+	quirks = make([]sriovnetworkv1.DriverQuirk, len(driverQuirks))
+	for i := range driverQuirks {
+		driverQuirks[i].DeepCopyInto(&quirks[i])
+	}
+}
+
+// Get returns the driver quirks, e.g. to persist them for the sriov-config service
+func Get() []sriovnetworkv1.DriverQuirk {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(quirks) == 0 {
+		return nil
+	}
+	driverQuirks := make([]sriovnetworkv1.DriverQuirk, len(quirks))
+	for i := range quirks {
+		quirks[i].DeepCopyInto(&driverQuirks[i])
+	}
+	return driverQuirks
+}
+
+// Skipped is the set of the steps skipped on a PF, by the name of the quirk skipping them
+type Skipped map[sriovnetworkv1.VfConfigStep]string
+
+// Has returns true if the step is skipped
+func (s Skipped) Has(step sriovnetworkv1.VfConfigStep) bool {
+	_, ok := s[step]
+	return ok
+}
+
+// For returns the steps skipped on the PFs of the driver, the firmware version of the PF is only read for the quirks
+// of the driver restricted to a range of versions
+func For(driver string, firmwareVersion func() string) Skipped {
+	mu.RLock()
+	defer mu.RUnlock()
+	skipped := Skipped{}
+	version, read := "", false
+	for _, quirk := range quirks {
+		if quirk.Driver != driver {
+			continue
+		}
+		if !read && (quirk.MinFirmwareVersion != "" || quirk.MaxFirmwareVersion != "") {
+			version, read = firmwareVersion(), true
+		}
+		if !affects(quirk, version) {
+			continue
+		}
+		for _, step := range quirk.Skip {
+			skipped[step] = quirk.Name
+		}
+	}
+	return skipped
+}
+
+// affects returns true if the firmware version is in the range of the quirk, the quirks restricted to a range are
+// not applied when the version can't be parsed
+func affects(quirk sriovnetworkv1.DriverQuirk, firmwareVersion string) bool {
+	if quirk.MinFirmwareVersion == "" && quirk.MaxFirmwareVersion == "" {
+		return true
+	}
+	version, err := utils.ParseFirmwareVersion(firmwareVersion)
+	if err != nil {
+		log.Log.Error(err, "affects(): unable to match the firmware version, the quirk is not applied", "quirk", quirk.Name)
+		return false
+	}
+	if quirk.MinFirmwareVersion != "" {
+		minimum, err := utils.ParseFirmwareVersion(quirk.MinFirmwareVersion)
+		if err != nil {
+			log.Log.Error(err, "affects(): invalid minimum firmware version, the quirk is not applied", "quirk", quirk.Name)
+			return false
+		}
+		if utils.CompareVersions(version, minimum) < 0 {
+			return false
+		}
+	}
+	if quirk.MaxFirmwareVersion != "" {
+		maximum, err := utils.ParseFirmwareVersion(quirk.MaxFirmwareVersion)
+		if err != nil {
+			log.Log.Error(err, "affects(): invalid maximum firmware version, the quirk is not applied", "quirk", quirk.Name)
+			return false
+		}
+		if utils.CompareVersions(version, maximum) > 0 {
+			return false
+		}
+	}
+	return true
+}
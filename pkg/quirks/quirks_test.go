@@ -0,0 +1,41 @@
+package quirks
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func TestFor(t *testing.T) {
+	g := NewGomegaWithT(t)
+	t.Cleanup(func() { Set(nil) })
+
+	Set([]sriovnetworkv1.DriverQuirk{
+		{Name: "ice-rdma-aux", Driver: "ice", MinFirmwareVersion: "4.20", MaxFirmwareVersion: "4.30",
+			Skip: []sriovnetworkv1.VfConfigStep{sriovnetworkv1.VfConfigStepAdminMac}},
+		{Name: "i40e-rebind", Driver: "i40e",
+			Skip: []sriovnetworkv1.VfConfigStep{sriovnetworkv1.VfConfigStepRebind, sriovnetworkv1.VfConfigStepMtu}},
+	})
+
+	testCases := []struct {
+		driver   string
+		version  string
+		expected Skipped
+	}{
+		{"ice", "4.20 0x80017785 1.3346.0", Skipped{sriovnetworkv1.VfConfigStepAdminMac: "ice-rdma-aux"}},
+		{"ice", "4.30.2", Skipped{sriovnetworkv1.VfConfigStepAdminMac: "ice-rdma-aux"}},
+		{"ice", "4.10", Skipped{}},
+		{"ice", "4.40", Skipped{}},
+		{"ice", "N/A", Skipped{}},
+		{"i40e", "", Skipped{sriovnetworkv1.VfConfigStepRebind: "i40e-rebind", sriovnetworkv1.VfConfigStepMtu: "i40e-rebind"}},
+		{"mlx5_core", "22.36.1010", Skipped{}},
+	}
+	for _, tc := range testCases {
+		g.Expect(For(tc.driver, func() string { return tc.version })).To(Equal(tc.expected), "%s %s", tc.driver, tc.version)
+	}
+	g.Expect(For("ice", func() string { return "4.25" }).Has(sriovnetworkv1.VfConfigStepAdminMac)).To(BeTrue())
+	g.Expect(For("ice", func() string { return "4.25" }).Has(sriovnetworkv1.VfConfigStepMtu)).To(BeFalse())
+	g.Expect(Get()).To(HaveLen(2))
+}
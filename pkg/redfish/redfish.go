@@ -0,0 +1,220 @@
+// Package redfish reads, and optionally fixes, the BIOS settings preventing SR-IOV on a
+// bare-metal node through the Redfish API of its BMC.
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Setting is a platform setting required by SR-IOV, with the BIOS attributes exposing it on
+// the different server vendors and the value enabling it
+type Setting struct {
+	Name       string
+	Attributes map[string]string
+}
+
+// Settings are the platform settings checked on the BMCs
+var Settings = []Setting{
+	{
+		Name: "SR-IOV",
+		Attributes: map[string]string{
+			// Dell iDRAC
+			"SriovGlobalEnable": "Enabled",
+			// HPE iLO
+			"Sriov": "Enabled",
+		},
+	},
+	{
+		Name: "VT-d",
+		Attributes: map[string]string{
+			// Dell iDRAC, covers both VT-x and VT-d
+			"ProcVirtualization": "Enabled",
+			// HPE iLO
+			"IntelProcVtd": "Enabled",
+			"ProcAmdIoVt":  "Enabled",
+		},
+	},
+}
+
+// Client talks to the Redfish API of a BMC
+type Client struct {
+	address  string
+	username string
+	password string
+	http     *http.Client
+}
+
+// NewClient returns a client for the BMC at address, e.g. https://10.0.0.5. The certificate of the BMC is always
+// verified, against caBundle when it's set, a PEM bundle of the CAs signing the BMC certificates, or against the
+// system CAs otherwise.
+func NewClient(address, username, password string, caBundle []byte) (*Client, error) {
+	if !strings.HasPrefix(address, "https://") {
+		return nil, fmt.Errorf("the BMC address %q must be an https URL", address)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle of the BMCs")
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	return &Client{
+		address:  strings.TrimSuffix(address, "/"),
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: defaultTimeout, Transport: transport},
+	}, nil
+}
+
+// BiosState is the state of the required platform settings of a system
+type BiosState struct {
+	// SystemPath is the Redfish path of the system, e.g. /redfish/v1/Systems/1
+	SystemPath string
+	// Disabled are the names of the settings found disabled
+	Disabled []string
+	// Unknown are the names of the settings not exposed by the BIOS of the system
+	Unknown []string
+	// Fixes are the attributes enabling the disabled settings
+	Fixes map[string]string
+	// settingsPath is the path the pending BIOS attributes are written to
+	settingsPath string
+}
+
+// GetBiosState reads the BIOS attributes of the first system of the BMC
+func (c *Client) GetBiosState(ctx context.Context) (*BiosState, error) {
+	systems := struct {
+		Members []struct {
+			ID string `json:"@odata.id"`
+		} `json:"Members"`
+	}{}
+	if err := c.get(ctx, "/redfish/v1/Systems", &systems); err != nil {
+		return nil, err
+	}
+	if len(systems.Members) == 0 {
+		return nil, fmt.Errorf("no system found on BMC %s", c.address)
+	}
+
+	state := &BiosState{SystemPath: systems.Members[0].ID, Fixes: map[string]string{}}
+	bios := struct {
+		Attributes map[string]interface{} `json:"Attributes"`
+		Settings   struct {
+			SettingsObject struct {
+				ID string `json:"@odata.id"`
+			} `json:"SettingsObject"`
+		} `json:"@Redfish.Settings"`
+	}{}
+	if err := c.get(ctx, state.SystemPath+"/Bios", &bios); err != nil {
+		return nil, err
+	}
+	state.settingsPath = bios.Settings.SettingsObject.ID
+	if state.settingsPath == "" {
+		state.settingsPath = state.SystemPath + "/Bios/Settings"
+	}
+
+	for _, setting := range Settings {
+		found := false
+		enabled := true
+		for attr, want := range setting.Attributes {
+			value, ok := bios.Attributes[attr]
+			if !ok {
+				continue
+			}
+			found = true
+			if fmt.Sprint(value) != want {
+				enabled = false
+				state.Fixes[attr] = want
+			}
+		}
+		switch {
+		case !found:
+			state.Unknown = append(state.Unknown, setting.Name)
+		case !enabled:
+			state.Disabled = append(state.Disabled, setting.Name)
+		}
+	}
+	return state, nil
+}
+
+// EnableSettings writes the attributes enabling the disabled settings, the BIOS applies them
+// at the next reboot of the system
+func (c *Client) EnableSettings(ctx context.Context, state *BiosState) error {
+	if len(state.Fixes) == 0 {
+		return nil
+	}
+	body := map[string]interface{}{
+		"Attributes": state.Fixes,
+		"@Redfish.SettingsApplyTime": map[string]string{
+			"ApplyTime": "OnReset",
+		},
+	}
+	return c.do(ctx, http.MethodPatch, state.settingsPath, body, nil)
+}
+
+// FixedAttributes returns the sorted names of the attributes set by EnableSettings
+func (s *BiosState) FixedAttributes() []string {
+	attrs := make([]string, 0, len(s.Fixes))
+	for attr := range s.Fixes {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call BMC %s: %v", c.address, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, string(data))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode the response of %s %s: %v", method, path, err)
+	}
+	return nil
+}
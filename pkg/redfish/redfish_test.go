@@ -0,0 +1,116 @@
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeBMC serves the Redfish resources of a single system and records the patched BIOS attributes
+type fakeBMC struct {
+	attributes map[string]interface{}
+	patched    map[string]interface{}
+}
+
+func (b *fakeBMC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Systems":
+		_, _ = w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Systems/System.Embedded.1/Bios":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Attributes": b.attributes,
+			"@Redfish.Settings": map[string]interface{}{
+				"SettingsObject": map[string]string{"@odata.id": "/redfish/v1/Systems/System.Embedded.1/Bios/Settings"},
+			},
+		})
+	case r.Method == http.MethodPatch && r.URL.Path == "/redfish/v1/Systems/System.Embedded.1/Bios/Settings":
+		data, _ := io.ReadAll(r.Body)
+		body := map[string]interface{}{}
+		_ = json.Unmarshal(data, &body)
+		b.patched = body["Attributes"].(map[string]interface{})
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newClient returns a client trusting the certificate of the fake BMC
+func newClient(g *WithT, server *httptest.Server, address, password string) *Client {
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	c, err := NewClient(address, "admin", password, ca)
+	g.Expect(err).ToNot(HaveOccurred())
+	return c
+}
+
+func TestGetBiosState(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	bmc := &fakeBMC{attributes: map[string]interface{}{
+		"SriovGlobalEnable":  "Disabled",
+		"ProcVirtualization": "Enabled",
+		"LogicalProc":        "Enabled",
+	}}
+	server := httptest.NewTLSServer(bmc)
+	defer server.Close()
+
+	c := newClient(g, server, server.URL+"/", "secret")
+	state, err := c.GetBiosState(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(state.SystemPath).To(Equal("/redfish/v1/Systems/System.Embedded.1"))
+	g.Expect(state.Disabled).To(Equal([]string{"SR-IOV"}))
+	g.Expect(state.Unknown).To(BeEmpty())
+	g.Expect(state.FixedAttributes()).To(Equal([]string{"SriovGlobalEnable"}))
+
+	g.Expect(c.EnableSettings(context.Background(), state)).To(Succeed())
+	g.Expect(bmc.patched).To(Equal(map[string]interface{}{"SriovGlobalEnable": "Enabled"}))
+}
+
+func TestGetBiosStateUnknownSettings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewTLSServer(&fakeBMC{attributes: map[string]interface{}{"Sriov": "Enabled"}})
+	defer server.Close()
+
+	state, err := newClient(g, server, server.URL, "secret").GetBiosState(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(state.Disabled).To(BeEmpty())
+	g.Expect(state.Unknown).To(Equal([]string{"VT-d"}))
+}
+
+func TestGetBiosStateUnauthorized(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewTLSServer(&fakeBMC{})
+	defer server.Close()
+
+	_, err := newClient(g, server, server.URL, "wrong").GetBiosState(context.Background())
+	g.Expect(err).To(MatchError(ContainSubstring("returned 401")))
+}
+
+func TestNewClientVerifiesTheBMC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewTLSServer(&fakeBMC{})
+	defer server.Close()
+
+	_, err := NewClient("http://10.0.0.5", "admin", "secret", nil)
+	g.Expect(err).To(MatchError(ContainSubstring("must be an https URL")))
+	_, err = NewClient(server.URL, "admin", "secret", []byte("not a certificate"))
+	g.Expect(err).To(MatchError(ContainSubstring("no certificate found")))
+
+	// the self-signed certificate of the BMC isn't trusted without the CA bundle
+	c, err := NewClient(server.URL, "admin", "secret", nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = c.GetBiosState(context.Background())
+	g.Expect(err).To(MatchError(ContainSubstring("certificate")))
+}
@@ -0,0 +1,107 @@
+// Package reserved maintains the reserved VFs file of the node, listing the VFs of the systemReserved policies the
+// device plugin doesn't advertise, for the node tooling of the system components using them, e.g. the OVN
+// encapsulation of the host over a VF. The file follows the policies: a reservation is removed with its policy, and the
+// file with the last reservation.
+package reserved
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// File is the content of the reserved VFs file
+type File struct {
+	Reservations []Reservation `json:"reservations"`
+}
+
+// Reservation are the VFs of a PF reserved by a policy
+type Reservation struct {
+	// ResourceName of the policy, naming the reservation
+	ResourceName string `json:"resourceName"`
+	PolicyName   string `json:"policyName"`
+	PfName       string `json:"pfName,omitempty"`
+	PfPciAddress string `json:"pfPciAddress"`
+	Vfs          []Vf   `json:"vfs"`
+}
+
+// Vf is a reserved VF
+type Vf struct {
+	VfID       int    `json:"vfID"`
+	PciAddress string `json:"pciAddress"`
+	// Name of the netdev of the VF, empty when the VF is bound to a userspace driver
+	Name string `json:"name,omitempty"`
+}
+
+// Build returns the reservations of the VF groups of the interfaces, the VFs not created yet are left out
+func Build(interfaces sriovnetworkv1.Interfaces) *File {
+	file := &File{Reservations: []Reservation{}}
+	for _, iface := range interfaces {
+		for _, group := range iface.VfGroups {
+			if !group.SystemReserved {
+				continue
+			}
+			reservation := Reservation{
+				ResourceName: group.ResourceName,
+				PolicyName:   group.PolicyName,
+				PfName:       iface.Name,
+				PfPciAddress: iface.PciAddress,
+				Vfs:          []Vf{},
+			}
+			for vfID := 0; vfID < iface.NumVfs; vfID++ {
+				if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				if vf := lookupVf(iface.PciAddress, vfID); vf != nil {
+					reservation.Vfs = append(reservation.Vfs, *vf)
+				}
+			}
+			file.Reservations = append(file.Reservations, reservation)
+		}
+	}
+	return file
+}
+
+// lookupVf returns the VF of the PF, nil when the VF doesn't exist
+func lookupVf(pfPciAddress string, vfID int) *Vf {
+	pciAddress := utils.GetVfPciAddress(pfPciAddress, vfID)
+	if pciAddress == "" {
+		return nil
+	}
+	vf := &Vf{VfID: vfID, PciAddress: pciAddress}
+	entries, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, vf.PciAddress, "net"))
+	if err == nil && len(entries) > 0 {
+		vf.Name = entries[0].Name()
+	}
+	return vf
+}
+
+// Sync writes the reservations of the interfaces to the reserved VFs file of the host when they changed, and removes
+// the file when there is no reservation
+func Sync(interfaces sriovnetworkv1.Interfaces) error {
+	path := utils.GetHostExtensionPath(consts.ReservedVfsFile)
+	file := Build(interfaces)
+	if len(file.Reservations) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the reserved VFs file: %v", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file is replaced at once, the node tooling never reads a partially written file
+	if _, err := utils.ReplaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write the reserved VFs file: %v", err)
+	}
+	return nil
+}
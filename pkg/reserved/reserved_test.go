@@ -0,0 +1,78 @@
+package reserved
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+)
+
+func useFakeFilesystem(t *testing.T, fs *fakefilesystem.FS) string {
+	root, clean, err := fs.Use()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origRoot, origInChroot := vars.FilesystemRoot, vars.InChroot
+	vars.FilesystemRoot, vars.InChroot = root, true
+	t.Cleanup(func() {
+		vars.FilesystemRoot, vars.InChroot = origRoot, origInChroot
+		clean()
+	})
+	return root
+}
+
+func TestSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	root := useFakeFilesystem(t, &fakefilesystem.FS{
+		Dirs: []string{
+			consts.SriovConfBasePath,
+			"sys/bus/pci/devices/0000:3b:00.0",
+			"sys/bus/pci/devices/0000:3b:02.0/net/ens1f0v0",
+			"sys/bus/pci/devices/0000:3b:02.1",
+		},
+		Symlinks: map[string]string{
+			"sys/bus/pci/devices/0000:3b:00.0/virtfn0": "../0000:3b:02.0",
+			"sys/bus/pci/devices/0000:3b:00.0/virtfn1": "../0000:3b:02.1",
+		},
+	})
+	file := filepath.Join(root, consts.ReservedVfsFile)
+
+	interfaces := sriovnetworkv1.Interfaces{{
+		PciAddress: "0000:3b:00.0",
+		Name:       "ens1f0",
+		NumVfs:     4,
+		VfGroups: []sriovnetworkv1.VfGroup{
+			{ResourceName: "ovn_encap", PolicyName: "policy-ovn", VfRange: "0-2", SystemReserved: true},
+			{ResourceName: "intel", PolicyName: "policy-intel", VfRange: "3-3"},
+		},
+	}}
+	g.Expect(Sync(interfaces)).To(Succeed())
+	data, err := os.ReadFile(file)
+	g.Expect(err).ToNot(HaveOccurred())
+	read := &File{}
+	g.Expect(json.Unmarshal(data, read)).To(Succeed())
+	// the VF 2 is not created yet
+	g.Expect(read.Reservations).To(Equal([]Reservation{{
+		ResourceName: "ovn_encap",
+		PolicyName:   "policy-ovn",
+		PfName:       "ens1f0",
+		PfPciAddress: "0000:3b:00.0",
+		Vfs: []Vf{
+			{VfID: 0, PciAddress: "0000:3b:02.0", Name: "ens1f0v0"},
+			{VfID: 1, PciAddress: "0000:3b:02.1"},
+		},
+	}}))
+
+	// the file is removed with the last reservation
+	interfaces[0].VfGroups = interfaces[0].VfGroups[1:]
+	g.Expect(Sync(interfaces)).To(Succeed())
+	g.Expect(file).ToNot(BeAnExistingFile())
+	g.Expect(Sync(interfaces)).To(Succeed())
+}
@@ -0,0 +1,86 @@
+package signature
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// failedRetryInterval is how long a failed verification is reused before the verifier is called again, e.g. after
+// the verifier was unavailable
+const failedRetryInterval = 5 * time.Minute
+
+// Cache holds the result of the verification of each policy by UID, the verifier is only called again once the
+// policy, its signature or the verifier changes, or once the retry interval of a failure expires
+type Cache struct {
+	mu      sync.Mutex
+	results map[types.UID]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key        string
+	err        error
+	verifiedAt time.Time
+}
+
+// NewCache returns an empty cache
+func NewCache() *Cache {
+	return &Cache{results: map[types.UID]cacheEntry{}, now: time.Now}
+}
+
+// Filter returns the policies whose signature is verified when the SriovOperatorConfig requires signed policies,
+// and the names of the others, which must not be applied to the nodes. The default policy is generated by the
+// operator and kept. The order of the policies is kept.
+func (c *Cache) Filter(ctx context.Context, cfg *sriovnetworkv1.PolicySignatureConfig,
+	policies []sriovnetworkv1.SriovNetworkNodePolicy) ([]sriovnetworkv1.SriovNetworkNodePolicy, []string) {
+	if cfg == nil {
+		return policies, nil
+	}
+	signed := make([]sriovnetworkv1.SriovNetworkNodePolicy, 0, len(policies))
+	rejected := []string{}
+	for i := range policies {
+		p := &policies[i]
+		if p.Name == consts.DefaultPolicyName {
+			signed = append(signed, *p)
+			continue
+		}
+		if err := c.verify(ctx, cfg, p); err != nil {
+			log.FromContext(ctx).Info("Skip the policy, its signature is not verified", "policy", p.Name, "reason", err.Error())
+			rejected = append(rejected, p.Name)
+			continue
+		}
+		signed = append(signed, *p)
+	}
+	return signed, rejected
+}
+
+// Forget drops the result of the policy, e.g. once it is deleted
+func (c *Cache) Forget(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.results, uid)
+}
+
+func (c *Cache) verify(ctx context.Context, cfg *sriovnetworkv1.PolicySignatureConfig, p *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	key := fmt.Sprintf("%d/%s/%+v", p.Generation, p.Annotations[Annotation(cfg)], *cfg)
+	c.mu.Lock()
+	entry, ok := c.results[p.UID]
+	c.mu.Unlock()
+	if ok && entry.key == key && (entry.err == nil || c.now().Sub(entry.verifiedAt) < failedRetryInterval) {
+		return entry.err
+	}
+
+	err := VerifyPolicy(ctx, cfg, p)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[p.UID] = cacheEntry{key: key, err: err, verifiedAt: c.now()}
+	return err
+}
@@ -0,0 +1,81 @@
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func TestCacheFilter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(Response{Allowed: req.Signature == "trusted"})
+	}))
+	defer server.Close()
+	cfg := &sriovnetworkv1.PolicySignatureConfig{URL: server.URL}
+
+	now := time.Now()
+	cache := NewCache()
+	cache.now = func() time.Time { return now }
+
+	defaultPolicy := *newPolicy("")
+	defaultPolicy.Name = consts.DefaultPolicyName
+	trusted := *newPolicy("trusted")
+	trusted.UID = "trusted-uid"
+	forged := *newPolicy("forged")
+	forged.Name = "policy-2"
+	forged.UID = "forged-uid"
+	policies := []sriovnetworkv1.SriovNetworkNodePolicy{defaultPolicy, trusted, forged}
+
+	// all the policies are kept when the signatures are not required
+	signed, rejected := cache.Filter(context.Background(), nil, policies)
+	g.Expect(signed).To(Equal(policies))
+	g.Expect(rejected).To(BeEmpty())
+	g.Expect(calls.Load()).To(BeZero())
+
+	signed, rejected = cache.Filter(context.Background(), cfg, policies)
+	g.Expect(signed).To(Equal(policies[:2]))
+	g.Expect(rejected).To(Equal([]string{"policy-2"}))
+	g.Expect(calls.Load()).To(BeEquivalentTo(2))
+	// the input is left untouched
+	g.Expect(policies[2].Name).To(Equal("policy-2"))
+
+	// both the verified and the rejected signatures are cached
+	signed, rejected = cache.Filter(context.Background(), cfg, policies)
+	g.Expect(signed).To(Equal(policies[:2]))
+	g.Expect(rejected).To(Equal([]string{"policy-2"}))
+	g.Expect(calls.Load()).To(BeEquivalentTo(2))
+
+	// the failures are verified again after the retry interval
+	now = now.Add(failedRetryInterval)
+	cache.Filter(context.Background(), cfg, policies)
+	g.Expect(calls.Load()).To(BeEquivalentTo(3))
+
+	// a new signature is verified again
+	policies[1].Annotations[consts.PolicySignatureAnnotation] = "forged"
+	_, rejected = cache.Filter(context.Background(), cfg, policies)
+	g.Expect(rejected).To(Equal([]string{"policy-1", "policy-2"}))
+	g.Expect(calls.Load()).To(BeEquivalentTo(4))
+
+	// the results of the deleted policies are dropped
+	cache.Forget("trusted-uid")
+	cache.Forget("forged-uid")
+	g.Expect(cache.results).To(BeEmpty())
+}
@@ -0,0 +1,175 @@
+// Package signature verifies the signatures of the SriovNetworkNodePolicies with the external verifier configured in
+// the policySignature of the SriovOperatorConfig, for the clusters where only signed network configurations may
+// reach the nodes.
+package signature
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Request is the payload sent to the verifier
+type Request struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Signature is the value of the signature annotation of the policy
+	Signature string `json:"signature"`
+	// Spec is the spec of the policy the signature is verified against
+	Spec json.RawMessage `json:"spec"`
+}
+
+// Response is the answer expected from the verifier
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Verifier verifies the signature of a policy
+type Verifier interface {
+	Verify(ctx context.Context, req *Request) (*Response, error)
+}
+
+// NewVerifier returns the verifier configured in the SriovOperatorConfig
+func NewVerifier(cfg *sriovnetworkv1.PolicySignatureConfig) (Verifier, error) {
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	switch {
+	case cfg.URL != "" && len(cfg.Command) > 0:
+		return nil, fmt.Errorf("only one of url or command can be set for the policy signature verifier")
+	case cfg.URL != "":
+		return &httpVerifier{url: cfg.URL, client: &http.Client{Timeout: timeout}}, nil
+	case len(cfg.Command) > 0:
+		return &execVerifier{command: cfg.Command, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("one of url or command must be set for the policy signature verifier")
+	}
+}
+
+// Annotation returns the annotation of the policies holding their signature
+func Annotation(cfg *sriovnetworkv1.PolicySignatureConfig) string {
+	if cfg.Annotation != "" {
+		return cfg.Annotation
+	}
+	return consts.PolicySignatureAnnotation
+}
+
+// VerifyPolicy returns an error when the policy has no signature or when the verifier rejects its signature. The
+// errors of the verifier itself are ignored with the Ignore failure policy.
+func VerifyPolicy(ctx context.Context, cfg *sriovnetworkv1.PolicySignatureConfig, policy *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	annotation := Annotation(cfg)
+	signature := policy.Annotations[annotation]
+	if signature == "" {
+		return fmt.Errorf("SriovNetworkNodePolicy %s has no signature, the %s annotation is required", policy.Name, annotation)
+	}
+	spec, err := json.Marshal(policy.Spec)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := NewVerifier(cfg)
+	if err != nil {
+		return err
+	}
+	resp, err := verifier.Verify(ctx, &Request{
+		Kind:      "SriovNetworkNodePolicy",
+		Name:      policy.Name,
+		Namespace: policy.Namespace,
+		Signature: signature,
+		Spec:      spec,
+	})
+	if err != nil {
+		if cfg.FailurePolicy == consts.FailurePolicyIgnore {
+			log.FromContext(ctx).Error(err, "policy signature verifier failed, ignoring", "policy", policy.Name)
+			return nil
+		}
+		return err
+	}
+	if !resp.Allowed {
+		return fmt.Errorf("the signature of SriovNetworkNodePolicy %s is rejected: %s", policy.Name, resp.Message)
+	}
+	return nil
+}
+
+// httpVerifier POSTs the request as JSON to the verifier URL
+type httpVerifier struct {
+	url    string
+	client *http.Client
+}
+
+func (v *httpVerifier) Verify(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call policy signature verifier: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy signature verifier returned %d: %s", resp.StatusCode, string(data))
+	}
+	return decodeResponse(data)
+}
+
+// execVerifier runs the verifier command with the request as JSON on stdin
+// and reads the response from stdout
+type execVerifier struct {
+	command []string
+	timeout time.Duration
+}
+
+func (v *execVerifier) Verify(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, v.command[0], v.command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("policy signature verifier command failed: %v: %s", err, stderr.String())
+	}
+	return decodeResponse(stdout.Bytes())
+}
+
+func decodeResponse(data []byte) (*Response, error) {
+	resp := &Response{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("failed to decode policy signature verifier response: %v", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,101 @@
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func TestNewVerifier(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := NewVerifier(&sriovnetworkv1.PolicySignatureConfig{})
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewVerifier(&sriovnetworkv1.PolicySignatureConfig{URL: "http://verifier", Command: []string{"/bin/true"}})
+	g.Expect(err).To(HaveOccurred())
+
+	v, err := NewVerifier(&sriovnetworkv1.PolicySignatureConfig{URL: "http://verifier"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(BeAssignableToTypeOf(&httpVerifier{}))
+
+	v, err = NewVerifier(&sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/true"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(BeAssignableToTypeOf(&execVerifier{}))
+}
+
+func newPolicy(signature string) *sriovnetworkv1.SriovNetworkNodePolicy {
+	policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-1", Namespace: "sriov-network-operator"},
+		Spec:       sriovnetworkv1.SriovNetworkNodePolicySpec{ResourceName: "intel", NumVfs: 4},
+	}
+	if signature != "" {
+		policy.Annotations = map[string]string{consts.PolicySignatureAnnotation: signature}
+	}
+	return policy
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		spec := &sriovnetworkv1.SriovNetworkNodePolicySpec{}
+		if err := json.Unmarshal(req.Spec, spec); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Signature == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp := Response{Allowed: req.Kind == "SriovNetworkNodePolicy" && req.Signature == "signed-"+spec.ResourceName}
+		if !resp.Allowed {
+			resp.Message = "signature mismatch"
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	cfg := &sriovnetworkv1.PolicySignatureConfig{URL: server.URL}
+
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("signed-intel"))).To(Succeed())
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("signed-mlx"))).To(MatchError(ContainSubstring("signature mismatch")))
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy(""))).To(MatchError(ContainSubstring("has no signature")))
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("broken"))).ToNot(Succeed())
+
+	// the failures of the verifier are ignored with the Ignore failure policy, not the missing signatures
+	cfg.FailurePolicy = consts.FailurePolicyIgnore
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("broken"))).To(Succeed())
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy(""))).ToNot(Succeed())
+
+	// the signature is read from the configured annotation
+	cfg = &sriovnetworkv1.PolicySignatureConfig{URL: server.URL, Annotation: "example.com/attestation"}
+	policy := newPolicy("")
+	policy.Annotations = map[string]string{"example.com/attestation": "signed-intel"}
+	g.Expect(VerifyPolicy(context.Background(), cfg, policy)).To(Succeed())
+}
+
+func TestExecVerifier(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg := &sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/sh", "-c", `cat > /dev/null; echo '{"allowed": true}'`}}
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("signed"))).To(Succeed())
+
+	cfg = &sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/sh", "-c", `cat > /dev/null; echo '{"allowed": false, "message": "unknown key"}'`}}
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("signed"))).To(MatchError(ContainSubstring("unknown key")))
+
+	cfg = &sriovnetworkv1.PolicySignatureConfig{Command: []string{"/bin/false"}}
+	g.Expect(VerifyPolicy(context.Background(), cfg, newPolicy("signed"))).To(MatchError(ContainSubstring("command failed")))
+}
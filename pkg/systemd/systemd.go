@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -26,6 +27,7 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/quirks"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
@@ -35,6 +37,9 @@ const (
 	SriovSystemdResultPath        = consts.SriovConfBasePath + "/sriov-interface-result.yaml"
 	sriovSystemdSupportedNicPath  = consts.SriovConfBasePath + "/sriov-supported-nics-ids.yaml"
 	sriovSystemdServiceBinaryPath = "/var/lib/sriov/sriov-network-config-daemon"
+	// SriovLastAppliedSpecPath is the node spec last applied by the config daemon in daemon mode, applied again when
+	// the daemon starts while the API server is unreachable
+	SriovLastAppliedSpecPath = consts.SriovConfBasePath + "/sriov-last-applied-spec.yaml"
 
 	SriovServicePath            = "/etc/systemd/system/sriov-config.service"
 	SriovPostNetworkServicePath = "/etc/systemd/system/sriov-config-post-network.service"
@@ -46,6 +51,8 @@ type SriovConfig struct {
 	Spec            sriovnetworkv1.SriovNetworkNodeStateSpec `yaml:"spec"`
 	UnsupportedNics bool                                     `yaml:"unsupportedNics"`
 	PlatformType    consts.PlatformTypes                     `yaml:"platformType"`
+	// DriverQuirks are the driver quirks of the SriovOperatorConfig, the sriov-config service can't read it
+	DriverQuirks []sriovnetworkv1.DriverQuirk `yaml:"driverQuirks,omitempty"`
 }
 
 type SriovResult struct {
@@ -70,9 +77,10 @@ func WriteConfFile(newState *sriovnetworkv1.SriovNetworkNodeState) (bool, error)
 	newState.Spec.DpConfigVersion = ""
 
 	sriovConfig := &SriovConfig{
-		newState.Spec,
-		vars.DevMode,
-		vars.PlatformType,
+		Spec:            newState.Spec,
+		UnsupportedNics: vars.DevMode,
+		PlatformType:    vars.PlatformType,
+		DriverQuirks:    quirks.Get(),
 	}
 
 	_, err := os.Stat(utils.GetHostExtensionPath(SriovSystemdConfigPath))
@@ -274,6 +282,51 @@ func ReadSriovSupportedNics() ([]string, error) {
 	return lines, nil
 }
 
+// LastAppliedSpec is the node spec last applied in daemon mode, with the supported NICs it was applied with
+type LastAppliedSpec struct {
+	SriovConfig     `yaml:",inline"`
+	SupportedNicIds []string `yaml:"supportedNicIds"`
+}
+
+// WriteLastAppliedSpec persists the node spec applied in daemon mode, the file is replaced atomically so a crash never
+// leaves a partial spec to apply
+func WriteLastAppliedSpec(state *sriovnetworkv1.SriovNetworkNodeState) error {
+	spec := state.Spec.DeepCopy()
+	spec.DpConfigVersion = ""
+	lastApplied := &LastAppliedSpec{
+		SriovConfig: SriovConfig{Spec: *spec, UnsupportedNics: vars.DevMode, PlatformType: vars.PlatformType,
+			DriverQuirks: quirks.Get()},
+		SupportedNicIds: sriovnetworkv1.NicIDMap,
+	}
+	out, err := yaml.Marshal(lastApplied)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the last applied spec: %v", err)
+	}
+	path := utils.GetHostExtensionPath(SriovLastAppliedSpecPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if _, err := utils.ReplaceFile(path, out); err != nil {
+		return fmt.Errorf("failed to write the last applied spec: %v", err)
+	}
+	log.Log.V(2).Info("WriteLastAppliedSpec(): last applied spec persisted", "path", path)
+	return nil
+}
+
+// ReadLastAppliedSpec returns the node spec last applied in daemon mode, the error satisfies os.IsNotExist when no spec
+// was persisted
+func ReadLastAppliedSpec() (*LastAppliedSpec, error) {
+	raw, err := os.ReadFile(utils.GetHostExtensionPath(SriovLastAppliedSpecPath))
+	if err != nil {
+		return nil, err
+	}
+	lastApplied := &LastAppliedSpec{}
+	if err := yaml.Unmarshal(raw, lastApplied); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the last applied spec: %v", err)
+	}
+	return lastApplied, nil
+}
+
 func CleanSriovFilesFromHost(isOpenShift bool) error {
 	err := os.Remove(utils.GetHostExtensionPath(SriovSystemdConfigPath))
 	if err != nil && !os.IsNotExist(err) {
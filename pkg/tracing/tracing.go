@@ -0,0 +1,179 @@
+// Package tracing records OpenTelemetry spans of the reconciliation pipeline, from the admission of
+// a policy to the steps of the config daemon sync, and exports them to an OTLP/HTTP collector.
+//
+// The trace context is propagated between the components in the W3C traceparent format, through
+// the sriovnetwork.openshift.io/traceparent annotation of the custom resources. Tracing is disabled,
+// and Start returns nil spans, until an endpoint is configured.
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	scopeName       = "github.com/k8snetworkplumbingwg/sriov-network-operator"
+	tracesPath      = "/v1/traces"
+	shutdownTimeout = 10 * time.Second
+
+	traceparentHeader = "traceparent"
+)
+
+var propagator = propagation.TraceContext{}
+
+// ContextWithTraceparent returns a context whose next span is a child of the span identified by
+// the traceparent, the context is returned unchanged if the traceparent is invalid
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return propagator.Extract(ctx, propagation.MapCarrier{traceparentHeader: traceparent})
+}
+
+// Traceparent returns the traceparent of the current span of the context, or an empty string
+func Traceparent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get(traceparentHeader)
+}
+
+// Span is an operation of a trace. The methods of a nil span do nothing, so that the callers
+// don't have to check if tracing is enabled.
+type Span struct {
+	span trace.Span
+}
+
+// Start starts a span, child of the current span of the context, with the attributes given as
+// key/value pairs. The returned context carries the new span.
+func Start(ctx context.Context, name string, attrs ...string) (context.Context, *Span) {
+	mu.Lock()
+	tracer := current.tracer
+	mu.Unlock()
+	if tracer == nil {
+		return ctx, nil
+	}
+	kvs := make([]attribute.KeyValue, 0, len(attrs)/2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		kvs = append(kvs, attribute.String(attrs[i], attrs[i+1]))
+	}
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+	return ctx, &Span{span: span}
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+// RecordError marks the span as failed, a nil error is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End ends the span and queues it for the export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.span.End()
+}
+
+type provider struct {
+	endpoint    string
+	serviceName string
+	provider    *sdktrace.TracerProvider
+	tracer      trace.Tracer
+}
+
+var (
+	// mu protects the current provider
+	mu      sync.Mutex
+	current provider
+)
+
+// Configure exports the spans to the OTLP/HTTP collector at the endpoint, e.g. http://otel-collector:4318,
+// with the service name as resource. An empty endpoint disables tracing, the pending spans of the
+// previous provider are flushed.
+func Configure(endpoint, serviceName string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current.endpoint == endpoint && current.serviceName == serviceName {
+		return
+	}
+	old := current.provider
+	current = provider{endpoint: endpoint, serviceName: serviceName}
+	if endpoint != "" {
+		tp, err := newProvider(endpoint, serviceName)
+		if err != nil {
+			log.Log.Error(err, "Configure(): failed to configure the trace export", "endpoint", endpoint)
+		} else {
+			log.Log.Info("Configure(): export traces", "endpoint", endpoint)
+			current.provider = tp
+			current.tracer = tp.Tracer(scopeName)
+		}
+	}
+	if old != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := old.Shutdown(ctx); err != nil {
+			log.Log.Error(err, "Configure(): failed to flush the pending spans")
+		}
+	}
+}
+
+// Flush exports the finished spans immediately
+func Flush() {
+	mu.Lock()
+	tp := current.provider
+	mu.Unlock()
+	if tp == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := tp.ForceFlush(ctx); err != nil {
+		log.Log.Error(err, "Flush(): failed to export the spans")
+	}
+}
+
+func newProvider(endpoint, serviceName string) (*sdktrace.TracerProvider, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	urlPath := u.Path
+	if !strings.HasSuffix(urlPath, tracesPath) {
+		urlPath = strings.TrimSuffix(urlPath, "/") + tracesPath
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host), otlptracehttp.WithURLPath(urlPath)}
+	if u.Scheme != "https" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	// the exporter connects lazily, so that an unavailable collector doesn't fail the configuration
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	), nil
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans makes the spans started by the test recorded in memory
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	mu.Lock()
+	current = provider{endpoint: "memory", serviceName: "test", provider: tp, tracer: tp.Tracer(scopeName)}
+	mu.Unlock()
+	t.Cleanup(func() { Configure("", "") })
+	return exporter
+}
+
+func TestTraceparent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	remote := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	g.Expect(Traceparent(ContextWithTraceparent(context.Background(), remote))).To(Equal(remote))
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		g.Expect(Traceparent(ContextWithTraceparent(context.Background(), invalid))).To(BeEmpty(), invalid)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	Configure("", "test")
+
+	ctx, span := Start(context.Background(), "noop")
+	g.Expect(span).To(BeNil())
+	g.Expect(Traceparent(ctx)).To(BeEmpty())
+	// the methods of a nil span are no-ops
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	span.End()
+}
+
+func TestSpans(t *testing.T) {
+	g := NewGomegaWithT(t)
+	exporter := recordSpans(t)
+
+	// the trace continues a remote parent, e.g. read from an annotation
+	remote := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx, parent := Start(ContextWithTraceparent(context.Background(), remote), "parent", "node", "worker-0")
+	g.Expect(Traceparent(ctx)).To(HavePrefix("00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+	g.Expect(Traceparent(ctx)).ToNot(Equal(remote))
+
+	// concurrent spans get the parent of their own context
+	_, child := Start(ctx, "child")
+	_, other := Start(context.Background(), "other")
+	child.RecordError(errors.New("command failed"))
+	child.End()
+	other.End()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	g.Expect(spans).To(HaveLen(3))
+	childSpan, otherSpan, parentSpan := spans[0], spans[1], spans[2]
+
+	g.Expect(parentSpan.Name).To(Equal("parent"))
+	g.Expect(parentSpan.SpanContext.TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+	g.Expect(parentSpan.Parent.SpanID().String()).To(Equal("00f067aa0ba902b7"))
+	g.Expect(parentSpan.Attributes).To(Equal([]attribute.KeyValue{attribute.String("node", "worker-0")}))
+	g.Expect(parentSpan.Status.Code).To(Equal(codes.Unset))
+
+	g.Expect(childSpan.Name).To(Equal("child"))
+	g.Expect(childSpan.SpanContext.TraceID()).To(Equal(parentSpan.SpanContext.TraceID()))
+	g.Expect(childSpan.Parent.SpanID()).To(Equal(parentSpan.SpanContext.SpanID()))
+	g.Expect(childSpan.Status.Code).To(Equal(codes.Error))
+	g.Expect(childSpan.Status.Description).To(Equal("command failed"))
+
+	g.Expect(otherSpan.Parent.IsValid()).To(BeFalse())
+	g.Expect(otherSpan.SpanContext.TraceID()).ToNot(Equal(parentSpan.SpanContext.TraceID()))
+}
+
+func TestConfigureFlushesPreviousProvider(t *testing.T) {
+	g := NewGomegaWithT(t)
+	var exports atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == tracesPath {
+			exports.Add(1)
+		}
+	}))
+	defer server.Close()
+
+	Configure(server.URL+"/", "test-service")
+	_, span := Start(context.Background(), "pending")
+	g.Expect(span).ToNot(BeNil())
+	span.End()
+	Configure("", "")
+
+	g.Expect(exports.Load()).To(BeEquivalentTo(1))
+	_, span = Start(context.Background(), "disabled")
+	g.Expect(span).To(BeNil())
+}
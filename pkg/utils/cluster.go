@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -110,3 +111,13 @@ func openshiftControlPlaneTopologyStatus(c client.Client) (configv1.TopologyMode
 	}
 	return infra.Status.ControlPlaneTopology, nil
 }
+
+// ClusterPrimaryIPFamily returns the primary IP family of the service network of the cluster, the family of the
+// cluster IP of the API server injected in the environment of every pod. It defaults to IPv4.
+func ClusterPrimaryIPFamily() corev1.IPFamily {
+	ip := net.ParseIP(os.Getenv("KUBERNETES_SERVICE_HOST"))
+	if ip != nil && ip.To4() == nil {
+		return corev1.IPv6Protocol
+	}
+	return corev1.IPv4Protocol
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"path/filepath"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// NetNsPath returns the path of the network namespace, a name is a namespace of the netns directory of the host while
+// a path, e.g. /proc/1234/ns/net, is returned as is
+func NetNsPath(netns string) string {
+	if filepath.IsAbs(netns) {
+		return netns
+	}
+	return GetHostExtensionPath(filepath.Join(consts.NetNsDir, netns))
+}
+
+type netNsCmd struct {
+	CmdInterface
+	netns string
+}
+
+// NewInNetNs returns the CmdInterface running the commands of cmd in the network namespace, the name of a namespace
+// of the host or its path
+func NewInNetNs(cmd CmdInterface, netns string) CmdInterface {
+	return &netNsCmd{CmdInterface: cmd, netns: netns}
+}
+
+// RunCommand runs the command in the network namespace with nsenter, only the network namespace is changed
+func (c *netNsCmd) RunCommand(command string, args ...string) (string, string, error) {
+	return c.CmdInterface.RunCommand("nsenter", append([]string{"--net=" + NetNsPath(c.netns), "--", command}, args...)...)
+}
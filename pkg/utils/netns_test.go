@@ -0,0 +1,35 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+func TestNetNsPath(t *testing.T) {
+	g := NewGomegaWithT(t)
+	origInChroot := vars.InChroot
+	defer func() { vars.InChroot = origInChroot }()
+	vars.InChroot = false
+	g.Expect(utils.NetNsPath("dpu")).To(Equal("/host/var/run/netns/dpu"))
+	g.Expect(utils.NetNsPath("/proc/1234/ns/net")).To(Equal("/proc/1234/ns/net"))
+}
+
+func TestNewInNetNs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	origInChroot := vars.InChroot
+	defer func() { vars.InChroot = origInChroot }()
+	vars.InChroot = false
+	cmd := mock_utils.NewMockCmdInterface(gomock.NewController(t))
+	cmd.EXPECT().RunCommand("nsenter", "--net=/host/var/run/netns/dpu", "--", "/bin/sh", "-c", "ip link show").
+		Return("out", "", nil)
+
+	stdout, _, err := utils.NewInNetNs(cmd, "dpu").RunCommand("/bin/sh", "-c", "ip link show")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stdout).To(Equal("out"))
+}
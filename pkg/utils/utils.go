@@ -3,6 +3,7 @@ package utils
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"math/rand"
@@ -10,7 +11,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +33,83 @@ type CmdInterface interface {
 type utilsHelper struct {
 }
 
+// HostExecutor runs the commands and writes the files of the host on behalf of a config daemon running confined,
+// without the privileges to do it itself
+type HostExecutor interface {
+	// RunCommand runs a command, in the root directory when it isn't empty
+	RunCommand(root, command string, args ...string) (string, string, error)
+	// WriteFile writes a file of the host, e.g. a sysfs attribute, relative to the root directory when it isn't empty
+	WriteFile(root, path string, data []byte, perm os.FileMode) error
+	// RemoveFile removes a file of the host relative to the root directory when it isn't empty, a missing file isn't
+	// an error
+	RemoveFile(root, path string) error
+}
+
+var (
+	// hostExecutor runs the host operations when set, they are run by the process itself otherwise
+	hostExecutor HostExecutor
+	// chrootPath is the root directory the process is chrooted in, empty when it isn't
+	chrootPath string
+)
+
+// SetHostExecutor routes the commands and the writes of the host files of the process to the executor
+func SetHostExecutor(executor HostExecutor) {
+	hostExecutor = executor
+}
+
+// HostExecutorSet returns true when the host operations are run by the host executor, i.e. the daemon is confined
+func HostExecutorSet() bool {
+	return hostExecutor != nil
+}
+
+// WriteHostFile writes a file of the host, e.g. a sysfs attribute, through the host executor when it is set
+func WriteHostFile(path string, data []byte, perm os.FileMode) error {
+	if hostExecutor != nil {
+		return hostExecutor.WriteFile(chrootPath, path, data, perm)
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// RemoveHostFile removes a file of the host, e.g. a udev rule, through the host executor when it is set. A missing
+// file isn't an error.
+func RemoveHostFile(path string) error {
+	if hostExecutor != nil {
+		return hostExecutor.RemoveFile(chrootPath, path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReplaceFile replaces the content of the file at once with the data, through a temporary file renamed over it, so
+// the readers of the file never read a partially written content. The file is left untouched when it already holds
+// the data, ReplaceFile returns whether it was written.
+func ReplaceFile(path string, data []byte) (bool, error) {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+// GetVfPciAddress returns the PCI address of the VF of the PF from the virtfn link of the PF, empty when the VF
+// doesn't exist
+func GetVfPciAddress(pfPciAddress string, vfID int) string {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pfPciAddress, fmt.Sprintf("virtfn%d", vfID)))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
+
 func New() CmdInterface {
 	return &utilsHelper{}
 }
@@ -44,6 +125,7 @@ func (u *utilsHelper) Chroot(path string) (func() error, error) {
 		return nil, err
 	}
 	vars.InChroot = true
+	chrootPath = path
 
 	return func() error {
 		defer root.Close()
@@ -51,6 +133,7 @@ func (u *utilsHelper) Chroot(path string) (func() error, error) {
 			return err
 		}
 		vars.InChroot = false
+		chrootPath = ""
 		return syscall.Chroot(".")
 	}, nil
 }
@@ -58,6 +141,11 @@ func (u *utilsHelper) Chroot(path string) (func() error, error) {
 // RunCommand runs a command
 func (u *utilsHelper) RunCommand(command string, args ...string) (string, string, error) {
 	log.Log.Info("RunCommand()", "command", command, "args", args)
+	if hostExecutor != nil {
+		stdout, stderr, err := hostExecutor.RunCommand(chrootPath, command, args...)
+		log.Log.V(2).Info("RunCommand()", "output", stdout, "error", err)
+		return stdout, stderr, err
+	}
 	var stdout, stderr bytes.Buffer
 
 	cmd := exec.Command(command, args...)
@@ -82,6 +170,16 @@ func GenerateRandomGUID() net.HardwareAddr {
 	return guid
 }
 
+// GenerateRandomMAC returns a random locally administered unicast MAC address
+func GenerateRandomMAC() net.HardwareAddr {
+	mac := make(net.HardwareAddr, 6)
+	for i := range mac {
+		mac[i] = byte(rand.Intn(0x100))
+	}
+	mac[0] = mac[0]&0xfe | 0x02
+	return mac
+}
+
 func HashConfigMap(cm *corev1.ConfigMap) string {
 	var keys []string
 	for k := range cm.Data {
@@ -98,13 +196,19 @@ func HashConfigMap(cm *corev1.ConfigMap) string {
 	return hex.EncodeToString(hashed)
 }
 
+// HashConfigMapKey hashes a single entry of the ConfigMap, so that the hash only changes with the data of that key
+func HashConfigMapKey(cm *corev1.ConfigMap, key string) string {
+	hash := fnv.New128()
+	hash.Write([]byte(key))
+	hash.Write([]byte(cm.Data[key]))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IsCommandNotFound returns whether the command exited with the 127 code of the shells not finding a command, the
+// error is an *exec.ExitError or the error of a command run by the host executor
 func IsCommandNotFound(err error) bool {
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 127 {
-			return true
-		}
-	}
-	return false
+	var exitErr interface{ ExitCode() int }
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 127
 }
 
 func GetHostExtension() string {
@@ -124,3 +228,49 @@ func GetChrootExtension() string {
 	}
 	return fmt.Sprintf("chroot %s%s", vars.FilesystemRoot, consts.Host)
 }
+
+// GetChrootCommand returns the program and the arguments running the command in the host root filesystem without
+// a shell, for the commands built from user provided values
+func GetChrootCommand(program string, args ...string) (string, []string) {
+	if vars.InChroot {
+		return program, args
+	}
+	return "chroot", append([]string{vars.FilesystemRoot + consts.Host, program}, args...)
+}
+
+// versionRegexp matches the leading dotted numeric part of the versions
+var versionRegexp = regexp.MustCompile(`^\d+(\.\d+)*`)
+
+// ParseFirmwareVersion returns the components of the leading dotted numeric part of the firmware or driver version,
+// drivers append build identifiers after it, e.g. "4.20 0x80017785 1.3346.0", and the in-tree drivers report the
+// kernel release, e.g. "5.14.0-284.el9"
+func ParseFirmwareVersion(version string) ([]int, error) {
+	match := versionRegexp.FindString(strings.TrimSpace(version))
+	if match == "" {
+		return nil, fmt.Errorf("unable to parse firmware version %q", version)
+	}
+	parts := []int{}
+	for _, p := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse firmware version %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// CompareVersions compares the version parsed by ParseFirmwareVersion with the components of the bound only, the
+// patch versions of a bound are equal to it. It returns -1, 0 or 1 when the version is lower than, equal to or
+// higher than the bound.
+func CompareVersions(version, bound []int) int {
+	for i := range bound {
+		if i >= len(version) || version[i] < bound[i] {
+			return -1
+		}
+		if version[i] > bound[i] {
+			return 1
+		}
+	}
+	return 0
+}
@@ -1,6 +1,9 @@
 package utils_test
 
 import (
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -75,3 +78,34 @@ var _ = Describe("HashConfigMap", func() {
 		Expect(hash1).To(Equal(hash2))
 	})
 })
+
+var _ = Describe("HashConfigMapKey", func() {
+	It("should only change with the data of the key", func() {
+		cm1 := &corev1.ConfigMap{Data: map[string]string{"node1": "value1", "node2": "value2"}}
+		cm2 := &corev1.ConfigMap{Data: map[string]string{"node1": "value1", "node2": "changed"}}
+
+		Expect(utils.HashConfigMapKey(cm1, "node1")).To(Equal(utils.HashConfigMapKey(cm2, "node1")))
+		Expect(utils.HashConfigMapKey(cm1, "node2")).ToNot(Equal(utils.HashConfigMapKey(cm2, "node2")))
+		Expect(utils.HashConfigMapKey(cm1, "node1")).ToNot(Equal(utils.HashConfigMapKey(cm1, "node2")))
+	})
+})
+
+var _ = Describe("ReplaceFile", func() {
+	It("only writes the file when its content changed", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "vfs.json")
+		written, err := utils.ReplaceFile(path, []byte("a"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(BeTrue())
+		Expect(os.ReadFile(path)).To(Equal([]byte("a")))
+
+		written, err = utils.ReplaceFile(path, []byte("a"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(BeFalse())
+
+		written, err = utils.ReplaceFile(path, []byte("b"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(BeTrue())
+		Expect(os.ReadFile(path)).To(Equal([]byte("b")))
+		Expect(path + ".tmp").ToNot(BeAnExistingFile())
+	})
+})
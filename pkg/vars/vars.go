@@ -3,6 +3,9 @@ package vars
 import (
 	"os"
 	"regexp"
+	goruntime "runtime"
+	"strconv"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
@@ -51,6 +54,13 @@ var (
 	// FilesystemRoot used by test to mock interactions with filesystem
 	FilesystemRoot = ""
 
+	// Architecture is the CPU architecture of the node, e.g. amd64 or arm64
+	Architecture = goruntime.GOARCH
+
+	// SupportedNodeOperatingSystems are the operating systems of the nodes the operator configures. The Windows
+	// nodes are skipped until the config daemon can run in a Windows HostProcess container.
+	SupportedNodeOperatingSystems = []string{consts.NodeOSLinux}
+
 	//Cluster variables
 	Config *rest.Config    = nil
 	Scheme *runtime.Scheme = nil
@@ -61,6 +71,43 @@ var (
 	// Namespace contains k8s namespace
 	Namespace = ""
 
+	// VfConfigConcurrency is the maximum number of VFs of a PF configured concurrently
+	VfConfigConcurrency = consts.DefaultVfConfigConcurrency
+
+	// StrictOpenstackMetadata fails the discovery on a malformed device, link or network of the OpenStack metadata
+	// instead of skipping it
+	StrictOpenstackMetadata = false
+
+	// StrictOpenstackPciLookup fails the discovery when the PCI address of a device of the OpenStack metadata can't
+	// be found from its MAC address instead of keeping the PCI address of the metadata
+	StrictOpenstackPciLookup = false
+
+	// NestedSriov is true when the virtual machine of the node has SR-IOV capable PFs, the config daemon provisions
+	// their VFs next to the devices of the virtual platform
+	NestedSriov = false
+
+	// ReportUnmanagedVirtualDevices reports the network devices of the virtual platforms missing from the metadata
+	// of the platform as unmanaged interfaces instead of skipping them
+	ReportUnmanagedVirtualDevices = false
+
+	// NMStateIntegration delegates the PF level settings to kubernetes-nmstate, the daemon doesn't set the MTU of
+	// the PFs and waits for kubernetes-nmstate to set it before configuring their VFs
+	NMStateIntegration = false
+
+	// OpenstackMetadataTimeout is the timeout of an attempt of a request to the OpenStack metadata service
+	OpenstackMetadataTimeout = consts.DefaultOpenstackMetadataTimeout
+	// OpenstackMetadataRetries is the number of retries of a failed request to the OpenStack metadata service
+	OpenstackMetadataRetries = consts.DefaultOpenstackMetadataRetries
+	// OpenstackMetadataBackoffMin and OpenstackMetadataBackoffMax bound the exponential backoff between the
+	// attempts of a request to the OpenStack metadata service
+	OpenstackMetadataBackoffMin = consts.DefaultOpenstackMetadataBackoffMin
+	OpenstackMetadataBackoffMax = consts.DefaultOpenstackMetadataBackoffMax
+	// OpenstackMetadataDeadline bounds the time spent reading the OpenStack metadata service, retries included
+	OpenstackMetadataDeadline = consts.DefaultOpenstackMetadataDeadline
+
+	// DaemonProfile is the profile of the config daemon, selected by the pool of the node
+	DaemonProfile = consts.DaemonProfileFull
+
 	// DisableablePlugins contains which plugins can be disabled in sriov config daemon
 	DisableablePlugins = map[string]struct{}{"mellanox": {}}
 )
@@ -87,4 +134,22 @@ func init() {
 	}
 
 	Namespace = os.Getenv("NAMESPACE")
+
+	OpenstackMetadataTimeout = durationFromEnv(consts.OpenstackMetadataTimeoutEnv, OpenstackMetadataTimeout)
+	if retries, err := strconv.Atoi(os.Getenv(consts.OpenstackMetadataRetriesEnv)); err == nil && retries >= 0 {
+		OpenstackMetadataRetries = retries
+	}
+	OpenstackMetadataBackoffMin = durationFromEnv(consts.OpenstackMetadataBackoffMinEnv, OpenstackMetadataBackoffMin)
+	OpenstackMetadataBackoffMax = durationFromEnv(consts.OpenstackMetadataBackoffMaxEnv, OpenstackMetadataBackoffMax)
+	OpenstackMetadataDeadline = durationFromEnv(consts.OpenstackMetadataDeadlineEnv, OpenstackMetadataDeadline)
+}
+
+// durationFromEnv returns the duration of the environment variable, e.g. 30s, or def when the variable is unset
+// or isn't a positive duration
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
 }
@@ -1,18 +1,125 @@
 package webhook
 
 import (
+	"fmt"
 	"os"
+	"sync"
 
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
+	sninformers "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/informers/externalversions"
+	snlisters "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/listers/sriovnetwork/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/compat"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/tracing"
 )
 
 var snclient snclientset.Interface
-var kubeclient *kubernetes.Clientset
+var kubeclient kubernetes.Interface
+
+// the listers read the objects checked on every admission from the caches of the informers started with the
+// clients, instead of the API server
+var (
+	namespaceLister corev1listers.NamespaceLister
+	grantLister     snlisters.SriovNetworkGrantLister
+	networkLister   snlisters.SriovNetworkLister
+	// compatLister reads the ConfigMap of the compatibility database of the operator namespace
+	compatLister corev1listers.ConfigMapNamespaceLister
+	pods         *podCache
+)
+
+// podCache starts the informer of the pods on the first admission counting the VFs attached in a namespace, the
+// pod webhooks are disabled by default
+type podCache struct {
+	once    sync.Once
+	factory informers.SharedInformerFactory
+	stopCh  <-chan struct{}
+	lister  corev1listers.PodLister
+	err     error
+}
+
+// Lister returns the lister of the pods once the cache is synced
+func (c *podCache) Lister() (corev1listers.PodLister, error) {
+	c.once.Do(func() {
+		informer := c.factory.Core().V1().Pods().Informer()
+		// only the fields counting the attachments of the pods are kept in the cache
+		if err := informer.SetTransform(trimPod); err != nil {
+			c.err = err
+			return
+		}
+		c.lister = c.factory.Core().V1().Pods().Lister()
+		c.factory.Start(c.stopCh)
+		for informer, synced := range c.factory.WaitForCacheSync(c.stopCh) {
+			if !synced {
+				c.err = fmt.Errorf("failed to sync the cache of %v", informer)
+			}
+		}
+	})
+	return c.lister, c.err
+}
+
+// trimPod keeps the name, the network attachments and the phase of the pods
+func trimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	trimmed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			DeletionTimestamp: pod.DeletionTimestamp,
+		},
+		Status: corev1.PodStatus{Phase: pod.Status.Phase},
+	}
+	if networks, ok := pod.Annotations[netattdefv1.NetworkAttachmentAnnot]; ok {
+		trimmed.Annotations = map[string]string{netattdefv1.NetworkAttachmentAnnot: networks}
+	}
+	return trimmed, nil
+}
+
+// tracingHandler exports the admission spans to the collector configured in the default SriovOperatorConfig,
+// the tracing is configured when the config changes instead of on every admission
+func tracingHandler() cache.ResourceEventHandler {
+	configure := func(obj interface{}) {
+		if config, ok := obj.(*sriovnetworkv1.SriovOperatorConfig); ok {
+			tracing.Configure(config.TracingEndpoint(), "sriov-network-webhook")
+		}
+	}
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			config, ok := obj.(*sriovnetworkv1.SriovOperatorConfig)
+			return ok && config.Namespace == namespace && config.Name == consts.DefaultConfigName
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    configure,
+			UpdateFunc: func(_, newObj interface{}) { configure(newObj) },
+			DeleteFunc: func(interface{}) { tracing.Configure("", "sriov-network-webhook") },
+		},
+	}
+}
+
+// dynclient reads the NodeNetworkConfigurationPolicies of kubernetes-nmstate
+var dynclient dynamic.Interface
 
 func SetupInClusterClient() error {
 	var err error
@@ -33,6 +140,46 @@ func SetupInClusterClient() error {
 
 	snclient = snclientset.NewForConfigOrDie(config)
 	kubeclient = kubernetes.NewForConfigOrDie(config)
+	dynclient = dynamic.NewForConfigOrDie(config)
+
+	return startInformers(wait.NeverStop)
+}
 
+// startInformers starts the informers of the listers and waits for their caches to sync
+func startInformers(stopCh <-chan struct{}) error {
+	kubeFactory := informers.NewSharedInformerFactory(kubeclient, 0)
+	snFactory := sninformers.NewSharedInformerFactory(snclient, 0)
+	namespaceLister = kubeFactory.Core().V1().Namespaces().Lister()
+	grantLister = snFactory.Sriovnetwork().V1().SriovNetworkGrants().Lister()
+	networkLister = snFactory.Sriovnetwork().V1().SriovNetworks().Lister()
+	pods = &podCache{factory: informers.NewSharedInformerFactory(kubeclient, 0), stopCh: stopCh}
+	// only the ConfigMap of the compatibility database is cached
+	compatFactory := informers.NewSharedInformerFactoryWithOptions(kubeclient, 0, informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", compat.ConfigMapName).String()
+		}))
+	compatLister = compatFactory.Core().V1().ConfigMaps().Lister().ConfigMaps(namespace)
+	if _, err := snFactory.Sriovnetwork().V1().SriovOperatorConfigs().Informer().AddEventHandler(tracingHandler()); err != nil {
+		return err
+	}
+
+	kubeFactory.Start(stopCh)
+	snFactory.Start(stopCh)
+	compatFactory.Start(stopCh)
+	for informer, synced := range kubeFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync the cache of %v", informer)
+		}
+	}
+	for informer, synced := range snFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync the cache of %v", informer)
+		}
+	}
+	for informer, synced := range compatFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync the cache of %v", informer)
+		}
+	}
 	return nil
 }
@@ -1,10 +1,14 @@
 package webhook
 
 import (
+	"bytes"
 	"crypto/tls"
+	"os"
 	"sync"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/certs"
 )
 
 // Config contains the server (the webhook) cert and key.
@@ -18,17 +22,39 @@ type tlsKeypairReloader struct {
 	cert      *tls.Certificate
 	certPath  string
 	keyPath   string
+	// certPEM and keyPEM are the contents of the loaded files, the unchanged files are not loaded again
+	certPEM []byte
+	keyPEM  []byte
 }
 
+// Reload loads the certificate and the key again when their files changed. The current certificate is served until
+// both files are loaded, so a renewed certificate is served with no downtime and a partially written one is ignored.
 func (keyPair *tlsKeypairReloader) Reload() error {
-	newCert, err := tls.LoadX509KeyPair(keyPair.certPath, keyPair.keyPath)
+	certPEM, err := os.ReadFile(keyPair.certPath)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := os.ReadFile(keyPair.keyPath)
+	if err != nil {
+		return err
+	}
+	keyPair.certMutex.RLock()
+	unchanged := bytes.Equal(certPEM, keyPair.certPEM) && bytes.Equal(keyPEM, keyPair.keyPEM)
+	keyPair.certMutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	newCert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
 		return err
 	}
-	log.Log.Info("cetificate reloaded")
 	keyPair.certMutex.Lock()
 	defer keyPair.certMutex.Unlock()
 	keyPair.cert = &newCert
+	keyPair.certPEM = certPEM
+	keyPair.keyPEM = keyPEM
+	logExpiration(certPEM, "certificate reloaded")
 	return nil
 }
 
@@ -45,11 +71,18 @@ func NewTLSKeypairReloader(certPath, keyPath string) (*tlsKeypairReloader, error
 		certPath: certPath,
 		keyPath:  keyPath,
 	}
-	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
-	if err != nil {
+	if err := result.Reload(); err != nil {
 		return nil, err
 	}
-	result.cert = &cert
-
 	return result, nil
 }
+
+// logExpiration logs the expiration time of the certificate, the operator reports the certificates about to expire
+func logExpiration(certPEM []byte, msg string) {
+	notAfter, err := certs.Expiration(certPEM)
+	if err != nil {
+		log.Log.Error(err, "failed to read the expiration time of the certificate")
+		return
+	}
+	log.Log.Info(msg, "notAfter", notAfter)
+}
@@ -17,7 +17,9 @@ var (
 	InfiniBandIsRdmaPatch  = map[string]interface{}{"op": "add", "path": "/spec/isRdma", "value": true}
 )
 
-func mutateSriovNetworkNodePolicy(cr map[string]interface{}) (*v1.AdmissionResponse, error) {
+// mutateSriovNetworkNodePolicy sets the defaults of the policy spec, and the traceparent annotation
+// propagating the trace of the admission to the rendering of the node states when not empty
+func mutateSriovNetworkNodePolicy(cr map[string]interface{}, traceparent string) (*v1.AdmissionResponse, error) {
 	log.Log.V(2).Info("mutateSriovNetworkNodePolicy(): set default value")
 	reviewResponse := v1.AdmissionResponse{}
 	reviewResponse.Allowed = true
@@ -47,6 +49,9 @@ func mutateSriovNetworkNodePolicy(cr map[string]interface{}) (*v1.AdmissionRespo
 		log.Log.V(2).Info("mutateSriovNetworkNodePolicy(): set isRdma to true for policy since ib link type is detected", "policy-name", name)
 		patchs = append(patchs, InfiniBandIsRdmaPatch)
 	}
+	if traceparent != "" {
+		patchs = append(patchs, traceparentPatch(cr, traceparent))
+	}
 	var err error
 	reviewResponse.Patch, err = json.Marshal(patchs)
 	if err != nil {
@@ -57,3 +62,14 @@ func mutateSriovNetworkNodePolicy(cr map[string]interface{}) (*v1.AdmissionRespo
 	reviewResponse.PatchType = &pt
 	return &reviewResponse, nil
 }
+
+// traceparentPatch sets the traceparent annotation, creating the annotations of the object if needed
+func traceparentPatch(cr map[string]interface{}, traceparent string) map[string]interface{} {
+	if _, ok := cr["metadata"].(map[string]interface{})["annotations"].(map[string]interface{}); !ok {
+		return map[string]interface{}{"op": "add", "path": "/metadata/annotations",
+			"value": map[string]interface{}{constants.TraceparentAnnotation: traceparent}}
+	}
+	// "/" is escaped as "~1" in JSON pointers
+	path := "/metadata/annotations/" + strings.ReplaceAll(constants.TraceparentAnnotation, "/", "~1")
+	return map[string]interface{}{"op": "add", "path": path, "value": traceparent}
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func TestMutateSriovNetworkNodePolicyTraceparent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	policy := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "policy1"},
+		"spec":     map[string]interface{}{"priority": 10, "deviceType": "netdevice", "isRdma": false},
+	}
+	resp, err := mutateSriovNetworkNodePolicy(policy, traceparent)
+	g.Expect(err).ToNot(HaveOccurred())
+	patches := []map[string]interface{}{}
+	g.Expect(json.Unmarshal(resp.Patch, &patches)).To(Succeed())
+	g.Expect(patches).To(Equal([]map[string]interface{}{{
+		"op": "add", "path": "/metadata/annotations",
+		"value": map[string]interface{}{constants.TraceparentAnnotation: traceparent},
+	}}))
+
+	policy["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{"foo": "bar"}
+	resp, err = mutateSriovNetworkNodePolicy(policy, traceparent)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(json.Unmarshal(resp.Patch, &patches)).To(Succeed())
+	g.Expect(patches).To(Equal([]map[string]interface{}{{
+		"op": "add", "path": "/metadata/annotations/sriovnetwork.openshift.io~1traceparent", "value": traceparent,
+	}}))
+
+	// no annotation without an admission span
+	resp, err = mutateSriovNetworkNodePolicy(policy, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(resp.Patch)).To(Equal("[]"))
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// podNetworkWarnings returns the mismatches between the SR-IOV resources requested by the pod and its attachments
+// to the SriovNetworks: an attachment without a VF fails when the sandbox of the pod is created, and a VF without an
+// attachment is allocated but never configured. The requests are counted after the network resources injector
+// mutated the pod.
+func podNetworkWarnings(pod *corev1.Pod) ([]string, error) {
+	log.Log.V(2).Info("podNetworkWarnings", "namespace", pod.Namespace, "name", pod.Name)
+	attachments, err := podNetworks(pod)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	networks, err := networkLister.SriovNetworks(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the SriovNetworks: %v", err)
+	}
+	attached := map[corev1.ResourceName]int64{}
+	for _, network := range networks {
+		// the pods attaching the networks without injected resource request their devices themselves
+		name := corev1.ResourceName(network.InjectedResourceName())
+		if name == "" {
+			continue
+		}
+		attached[name] += int64(countAttachments(attachments, network))
+	}
+
+	requested := map[corev1.ResourceName]int64{}
+	for _, c := range pod.Spec.Containers {
+		resources := c.Resources.Limits
+		if len(resources) == 0 {
+			resources = c.Resources.Requests
+		}
+		for name, q := range resources {
+			if _, ok := attached[name]; ok {
+				requested[name] += q.Value()
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range attached {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	warnings := []string{}
+	for _, n := range names {
+		name := corev1.ResourceName(n)
+		switch {
+		case attached[name] > requested[name]:
+			warnings = append(warnings, fmt.Sprintf("%d networks of %s attached but %d VFs requested, the pod will fail to start",
+				attached[name], name, requested[name]))
+		case attached[name] < requested[name]:
+			warnings = append(warnings, fmt.Sprintf("%d VFs of %s requested but %d networks attached, the VFs are left unused",
+				requested[name], name, attached[name]))
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+func newPodRequesting(networks string, vfs int64) *corev1.Pod {
+	pod := newQuotaPod("pod", networks, "")
+	pod.Spec.Containers = []corev1.Container{{Name: "app"}}
+	if vfs > 0 {
+		pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			"openshift.io/nic1": *resource.NewQuantity(vfs, resource.DecimalSI),
+		}
+	}
+	return pod
+}
+
+func TestPodNetworkWarnings(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t)
+
+	warnings, err := podNetworkWarnings(newPodRequesting("net-a, shared/net-b", 2))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(BeEmpty())
+
+	warnings, err = podNetworkWarnings(newPodRequesting("net-a, shared/net-b", 1))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(ConsistOf("2 networks of openshift.io/nic1 attached but 1 VFs requested, the pod will fail to start"))
+
+	warnings, err = podNetworkWarnings(newPodRequesting("", 1))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(ConsistOf("1 VFs of openshift.io/nic1 requested but 0 networks attached, the VFs are left unused"))
+
+	// the other networks and resources are not checked
+	warnings, err = podNetworkWarnings(newPodRequesting("other-net", 0))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(BeEmpty())
+
+	warnings, err = podNetworkWarnings(newPodRequesting(`[{"name": `, 1))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(warnings).To(ConsistOf(ContainSubstring("failed to parse the k8s.v1.cni.cncf.io/networks annotation")))
+}
+
+func TestPodNetworkWarningsWithResourceInjection(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t)
+	network, err := snclient.SriovnetworkV1().SriovNetworks(namespace).Get(context.Background(), "net-a", metav1.GetOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the pods attaching the networks without injected resource request their devices themselves
+	network.Spec.ResourceInjection = &ResourceInjection{Skip: true}
+	_, err = snclient.SriovnetworkV1().SriovNetworks(namespace).Update(context.Background(), network, metav1.UpdateOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	// the networks are read from the cache of the informer
+	g.Eventually(func() ([]string, error) { return podNetworkWarnings(newPodRequesting("net-a", 0)) }).Should(BeEmpty())
+
+	network.Spec.ResourceInjection = &ResourceInjection{ResourceName: "rdma/hca_shared_devices_a"}
+	_, err = snclient.SriovnetworkV1().SriovNetworks(namespace).Update(context.Background(), network, metav1.UpdateOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Eventually(func() ([]string, error) { return podNetworkWarnings(newPodRequesting("net-a", 0)) }).
+		Should(ConsistOf("1 networks of rdma/hca_shared_devices_a attached but 0 VFs requested, the pod will fail to start"))
+}
+
+func TestValidatePodNetworksAllowsThePods(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t)
+
+	raw, err := json.Marshal(newPodRequesting("net-a", 0))
+	g.Expect(err).ToNot(HaveOccurred())
+	response := ValidatePodNetworks(v1.AdmissionReview{Request: &v1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Operation: v1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	g.Expect(response.Allowed).To(BeTrue())
+	g.Expect(response.Warnings).To(HaveLen(1))
+}
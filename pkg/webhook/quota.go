@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// validatePodQuota rejects the pods attaching more VFs of a SriovNetwork than the quota of their namespace
+// allows, or attaching a SriovNetwork whose VLAN is not allowed for their namespace. The VFs are counted
+// from the network attachments of the pods of the namespace which are not terminated, nothing is reserved
+// between the admissions so the pods created concurrently may exceed the quota. The networks and the pods are
+// read from the caches of the webhook.
+func validatePodQuota(pod *corev1.Pod) error {
+	log.Log.V(2).Info("validatePodQuota", "namespace", pod.Namespace, "name", pod.Name)
+	attachments, err := podNetworks(pod)
+	if err != nil {
+		// multus fails the pods with a malformed annotation, they don't attach any VF
+		log.Log.V(2).Info("validatePodQuota(): ignoring the malformed network attachments", "error", err)
+		return nil
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	networks, err := networkLister.SriovNetworks(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworks: %v", err)
+	}
+
+	var namespacePods []*corev1.Pod
+	for _, network := range networks {
+		if network.Spec.Quota == nil {
+			continue
+		}
+		requested := countAttachments(attachments, network)
+		if requested == 0 {
+			continue
+		}
+
+		maxVfs := network.Spec.Quota.MaxVfsPerNamespace
+		if tenant := tenantQuota(network.Spec.Quota, pod.Namespace); tenant != nil {
+			if len(tenant.AllowedVlans) > 0 && !containsVlan(tenant.AllowedVlans, network.Spec.Vlan) {
+				return fmt.Errorf("SriovNetwork %s: VLAN %d is not allowed in namespace %s",
+					network.Name, network.Spec.Vlan, pod.Namespace)
+			}
+			if tenant.MaxVfs != nil {
+				maxVfs = tenant.MaxVfs
+			}
+		}
+		if maxVfs == nil {
+			continue
+		}
+
+		if namespacePods == nil {
+			lister, err := pods.Lister()
+			if err != nil {
+				return fmt.Errorf("failed to read the pods: %v", err)
+			}
+			namespacePods, err = lister.Pods(pod.Namespace).List(labels.Everything())
+			if err != nil {
+				return fmt.Errorf("failed to list the pods of namespace %s: %v", pod.Namespace, err)
+			}
+		}
+		used := 0
+		for _, p := range namespacePods {
+			if p.DeletionTimestamp != nil || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			// the VFs of the pods with an invalid annotation are not allocated
+			existing, err := podNetworks(p)
+			if err != nil {
+				continue
+			}
+			used += countAttachments(existing, network)
+		}
+		if used+requested > *maxVfs {
+			return fmt.Errorf("SriovNetwork %s: quota of namespace %s exceeded, %d VFs attached, %d requested, maximum %d",
+				network.Name, pod.Namespace, used, requested, *maxVfs)
+		}
+	}
+	return nil
+}
+
+// podNetworks returns the network attachments requested by the pod, in either the JSON or the
+// comma separated <namespace>/<network>@<interface> format of the annotation
+func podNetworks(pod *corev1.Pod) ([]netattdefv1.NetworkSelectionElement, error) {
+	value := strings.TrimSpace(pod.Annotations[netattdefv1.NetworkAttachmentAnnot])
+	if value == "" {
+		return nil, nil
+	}
+
+	var attachments []netattdefv1.NetworkSelectionElement
+	if strings.HasPrefix(value, "[") {
+		if err := json.Unmarshal([]byte(value), &attachments); err != nil {
+			return nil, fmt.Errorf("failed to parse the %s annotation: %v", netattdefv1.NetworkAttachmentAnnot, err)
+		}
+	} else {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			attachment := netattdefv1.NetworkSelectionElement{}
+			if i := strings.LastIndex(item, "@"); i >= 0 {
+				attachment.InterfaceRequest = item[i+1:]
+				item = item[:i]
+			}
+			if ns, name, found := strings.Cut(item, "/"); found {
+				attachment.Namespace, attachment.Name = ns, name
+			} else {
+				attachment.Name = item
+			}
+			attachments = append(attachments, attachment)
+		}
+	}
+	for i := range attachments {
+		if attachments[i].Namespace == "" {
+			attachments[i].Namespace = pod.Namespace
+		}
+	}
+	return attachments, nil
+}
+
+// countAttachments returns the number of attachments to the NetworkAttachmentDefinition rendered for the network
+func countAttachments(attachments []netattdefv1.NetworkSelectionElement, network *sriovnetworkv1.SriovNetwork) int {
+	networkNamespace := network.Spec.NetworkNamespace
+	if networkNamespace == "" {
+		networkNamespace = network.Namespace
+	}
+	count := 0
+	for _, a := range attachments {
+		if a.Name == network.Name && a.Namespace == networkNamespace {
+			count++
+		}
+	}
+	return count
+}
+
+func tenantQuota(quota *sriovnetworkv1.SriovNetworkQuota, ns string) *sriovnetworkv1.TenantQuota {
+	for i := range quota.Tenants {
+		if quota.Tenants[i].Namespace == ns {
+			return &quota.Tenants[i]
+		}
+	}
+	return nil
+}
+
+func containsVlan(vlans []int, vlan int) bool {
+	for _, v := range vlans {
+		if v == vlan {
+			return true
+		}
+	}
+	return false
+}
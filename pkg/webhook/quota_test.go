@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+
+	. "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	fakesnclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned/fake"
+)
+
+func newQuotaPod(name, networks string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{"k8s.v1.cni.cncf.io/networks": networks},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+// setupQuota creates the SriovNetworks with a quota and the pods, and starts the informers of the webhook on them
+func setupQuota(t *testing.T, pods ...*corev1.Pod) {
+	maxVfs, tenantMaxVfs := 2, 3
+	snclient = fakesnclientset.NewSimpleClientset(
+		&SriovNetwork{
+			ObjectMeta: metav1.ObjectMeta{Name: "net-a", Namespace: namespace},
+			Spec: SriovNetworkSpec{
+				NetworkNamespace: "tenant-a",
+				ResourceName:     "nic1",
+				Vlan:             100,
+				Quota: &SriovNetworkQuota{
+					MaxVfsPerNamespace: &maxVfs,
+				},
+			},
+		},
+		&SriovNetwork{
+			ObjectMeta: metav1.ObjectMeta{Name: "net-b", Namespace: namespace},
+			Spec: SriovNetworkSpec{
+				NetworkNamespace: "shared",
+				ResourceName:     "nic1",
+				Vlan:             200,
+				Quota: &SriovNetworkQuota{
+					Tenants: []TenantQuota{{Namespace: "tenant-a", MaxVfs: &tenantMaxVfs, AllowedVlans: []int{200}}},
+				},
+			},
+		},
+		&SriovNetwork{
+			ObjectMeta: metav1.ObjectMeta{Name: "net-c", Namespace: namespace},
+			Spec: SriovNetworkSpec{
+				NetworkNamespace: "shared",
+				ResourceName:     "nic1",
+				Vlan:             300,
+				Quota: &SriovNetworkQuota{
+					Tenants: []TenantQuota{{Namespace: "tenant-a", AllowedVlans: []int{200}}},
+				},
+			},
+		},
+	)
+	objs := []runtime.Object{}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	kubeclient = fakek8s.NewSimpleClientset(objs...)
+	startTestInformers(t)
+}
+
+func TestPodNetworks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	networks, err := podNetworks(newQuotaPod("pod", "net-a, shared/net-b@net1", ""))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(networks).To(HaveLen(2))
+	g.Expect(networks[0].Namespace).To(Equal("tenant-a"))
+	g.Expect(networks[0].Name).To(Equal("net-a"))
+	g.Expect(networks[1].Namespace).To(Equal("shared"))
+	g.Expect(networks[1].Name).To(Equal("net-b"))
+	g.Expect(networks[1].InterfaceRequest).To(Equal("net1"))
+
+	networks, err = podNetworks(newQuotaPod("pod", `[{"name": "net-a"}, {"name": "net-b", "namespace": "shared"}]`, ""))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(networks).To(HaveLen(2))
+	g.Expect(networks[0].Namespace).To(Equal("tenant-a"))
+
+	_, err = podNetworks(newQuotaPod("pod", `[{"name": `, ""))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestValidatePodQuotaWithinQuota(t *testing.T) {
+	g := NewGomegaWithT(t)
+	// the VFs of the terminated pods are not counted
+	setupQuota(t, newQuotaPod("running", "net-a", corev1.PodRunning), newQuotaPod("done", "net-a", corev1.PodSucceeded))
+
+	err := validatePodQuota(newQuotaPod("new", "net-a", ""))
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidatePodQuotaExceeded(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t, newQuotaPod("running", "net-a", corev1.PodRunning))
+
+	err := validatePodQuota(newQuotaPod("new", "net-a,net-a", ""))
+	g.Expect(err).To(MatchError(ContainSubstring("quota of namespace tenant-a exceeded, 1 VFs attached, 2 requested, maximum 2")))
+}
+
+func TestValidatePodQuotaTenantOverride(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t, newQuotaPod("running", "shared/net-b,shared/net-b", corev1.PodRunning))
+
+	g.Expect(validatePodQuota(newQuotaPod("new", "shared/net-b", ""))).To(Succeed())
+	g.Expect(validatePodQuota(newQuotaPod("new", "shared/net-b,shared/net-b", ""))).
+		To(MatchError(ContainSubstring("maximum 3")))
+}
+
+func TestValidatePodQuotaVlanNotAllowed(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t)
+
+	err := validatePodQuota(newQuotaPod("new", "shared/net-c", ""))
+	g.Expect(err).To(MatchError(ContainSubstring("VLAN 300 is not allowed in namespace tenant-a")))
+}
+
+func TestValidatePodQuotaWithoutSriovNetwork(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t)
+
+	g.Expect(validatePodQuota(newQuotaPod("new", "", ""))).To(Succeed())
+	g.Expect(validatePodQuota(newQuotaPod("new", "other-net", ""))).To(Succeed())
+}
+
+func TestValidatePodQuotaMalformedAnnotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	setupQuota(t, newQuotaPod("running", "net-a,net-a", corev1.PodRunning))
+
+	// multus fails the pods with a malformed annotation, they are admitted whatever the networks they mention
+	g.Expect(validatePodQuota(newQuotaPod("new", `[{"name": "net-a"`, ""))).To(Succeed())
+	// the names are matched exactly, not as substrings of the annotation
+	g.Expect(validatePodQuota(newQuotaPod("new", "net-a-large", ""))).To(Succeed())
+}
+
+func TestTrimPod(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pod := newQuotaPod("running", "net-a", corev1.PodRunning)
+	pod.Annotations["other"] = "value"
+	pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:latest"}}
+
+	trimmed, err := trimPod(pod)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(trimmed).To(Equal(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "running",
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{"k8s.v1.cni.cncf.io/networks": "net-a"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}))
+}
@@ -9,11 +9,15 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	sriovnetworkv2 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v2"
 )
 
 var scheme = runtime.NewScheme()
 var Codecs = serializer.NewCodecFactory(scheme)
 
+// Scheme contains the API versions the conversion webhook converts between
+var Scheme = scheme
+
 func init() {
 	addToScheme(scheme)
 }
@@ -23,4 +27,5 @@ func addToScheme(scheme *runtime.Scheme) {
 	utilruntime.Must(admissionv1.AddToScheme(scheme))
 	utilruntime.Must(admissionregistrationv1.AddToScheme(scheme))
 	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	utilruntime.Must(sriovnetworkv2.AddToScheme(scheme))
 }
@@ -3,8 +3,11 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -16,7 +19,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/cni"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/compat"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/fabric"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/nmstate"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/signature"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -29,6 +38,9 @@ const (
 var (
 	nodesSelected     bool
 	interfaceSelected bool
+	// flowEthType matches the ethType of the flow rules, the CRD pattern isn't enforced on the updates of objects
+	// stored before it
+	flowEthType = regexp.MustCompile(`^(ip|ipv6|arp|0x[0-9a-fA-F]{4})$`)
 )
 
 func validateSriovOperatorConfig(cr *sriovnetworkv1.SriovOperatorConfig, operation v1.Operation) (bool, []string, error) {
@@ -52,9 +64,93 @@ func validateSriovOperatorConfig(cr *sriovnetworkv1.SriovOperatorConfig, operati
 		return false, warnings, err
 	}
 
+	err = validateSriovOperatorConfigOverlays(cr)
+	if err != nil {
+		return false, warnings, err
+	}
+
+	if err := validateSriovOperatorConfigDriverQuirks(cr); err != nil {
+		return false, warnings, err
+	}
+
+	if err := cr.Spec.DrainEscalation.Validate(); err != nil {
+		return false, warnings, err
+	}
+
+	if err := cr.Spec.ValidateServiceIPFamilies(); err != nil {
+		return false, warnings, err
+	}
+
+	if err := validateSriovOperatorConfigPolicySignature(cr); err != nil {
+		return false, warnings, err
+	}
+
+	if nodeOS, ok := cr.Spec.ConfigDaemonNodeSelector[consts.NodeOSLabel]; ok && !sriovnetworkv1.StringInArray(nodeOS, vars.SupportedNodeOperatingSystems) {
+		return false, warnings, fmt.Errorf("configDaemonNodeSelector selects the %s nodes, the config daemon only runs on %s nodes",
+			nodeOS, strings.Join(vars.SupportedNodeOperatingSystems, ", "))
+	}
+
+	for gate := range cr.Spec.FeatureGates {
+		if !sriovnetworkv1.StringInArray(gate, sriovnetworkv1.FeatureGates) {
+			return false, warnings, fmt.Errorf("unknown feature gate %s, the supported gates are %s", gate, strings.Join(sriovnetworkv1.FeatureGates, ", "))
+		}
+	}
+
 	return true, warnings, nil
 }
 
+// validateSriovOperatorConfigPolicySignature checks the verifier of the policy signatures is configured, the scratch
+// policy of the soak test is generated by the operator and can't be signed
+func validateSriovOperatorConfigPolicySignature(cr *sriovnetworkv1.SriovOperatorConfig) error {
+	if cr.Spec.PolicySignature == nil {
+		return nil
+	}
+	if _, err := signature.NewVerifier(cr.Spec.PolicySignature); err != nil {
+		return err
+	}
+	if cr.Spec.SoakTest != nil {
+		return fmt.Errorf("soakTest can't be enabled with policySignature, the scratch policy of the soak test isn't signed")
+	}
+	return nil
+}
+
+// validateSriovOperatorConfigOverlays checks the overlays have unique names and patches that can be parsed
+func validateSriovOperatorConfigOverlays(cr *sriovnetworkv1.SriovOperatorConfig) error {
+	names := map[string]bool{}
+	for i := range cr.Spec.Overlays {
+		overlay := &cr.Spec.Overlays[i]
+		if names[overlay.Name] {
+			return fmt.Errorf("overlay %s is defined more than once", overlay.Name)
+		}
+		names[overlay.Name] = true
+		if err := overlay.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSriovOperatorConfigDriverQuirks checks the driver quirks have unique names and firmware versions that can
+// be compared with the versions of the PFs
+func validateSriovOperatorConfigDriverQuirks(cr *sriovnetworkv1.SriovOperatorConfig) error {
+	names := map[string]bool{}
+	for _, quirk := range cr.Spec.DriverQuirks {
+		if names[quirk.Name] {
+			return fmt.Errorf("driver quirk %s is defined more than once", quirk.Name)
+		}
+		names[quirk.Name] = true
+		for _, version := range []string{quirk.MinFirmwareVersion, quirk.MaxFirmwareVersion} {
+			if version == "" {
+				continue
+			}
+			if _, err := utils.ParseFirmwareVersion(version); err != nil {
+				return fmt.Errorf("driver quirk %s: %v", quirk.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // validateSriovOperatorConfigDisableDrain checks if the user is setting `.Spec.DisableDrain` from false to true while
 // operator is updating one or more nodes. Disabling the drain at this stage would prevent the operator to uncordon a node at
 // the end of the update operation, keeping nodes un-schedulable until manual intervention.
@@ -123,17 +219,150 @@ func validateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy, o
 		return admit, warnings, err
 	}
 
+	if err := validatePolicySignature(cr); err != nil {
+		return false, warnings, err
+	}
+
+	nmstateWarnings, err := validateNMStatePolicy(cr)
+	if err != nil {
+		return false, warnings, err
+	}
+	warnings = append(warnings, nmstateWarnings...)
+
+	if cr.Spec.DeviceType == consts.DeviceTypeVfioPci {
+		warnings = append(warnings, dpdkPrerequisitesWarnings(cr)...)
+	}
+
 	return admit, warnings, nil
 }
 
+// validatePolicySignature verifies the signature of the policy when the default SriovOperatorConfig requires the
+// policies to be signed. The policies outside of the operator namespace are not applied and not verified.
+func validatePolicySignature(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.GetNamespace() != os.Getenv("NAMESPACE") {
+		return nil
+	}
+	config, err := snclient.SriovnetworkV1().SriovOperatorConfigs(cr.GetNamespace()).Get(context.Background(), consts.DefaultConfigName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the default SriovOperatorConfig: %v", err)
+	}
+	if config.Spec.PolicySignature == nil {
+		return nil
+	}
+	return signature.VerifyPolicy(context.Background(), config.Spec.PolicySignature, cr)
+}
+
+// validateNMStatePolicy cross-validates the policy with the NodeNetworkConfigurationPolicies of kubernetes-nmstate
+// when the default SriovOperatorConfig delegates the PF settings to it with the nmstateIntegration feature gate
+func validateNMStatePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy) ([]string, error) {
+	if cr.GetNamespace() != os.Getenv("NAMESPACE") || dynclient == nil {
+		return nil, nil
+	}
+	config, err := snclient.SriovnetworkV1().SriovOperatorConfigs(cr.GetNamespace()).Get(context.Background(), consts.DefaultConfigName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the default SriovOperatorConfig: %v", err)
+	}
+	if !config.FeatureGateEnabled(consts.NMStateIntegrationFeatureGate) {
+		return nil, nil
+	}
+	nncps, err := dynclient.Resource(nmstate.PolicyGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return []string{"the nmstateIntegration feature gate is enabled but kubernetes-nmstate is not installed"}, nil
+		}
+		return nil, fmt.Errorf("failed to list the NodeNetworkConfigurationPolicies: %v", err)
+	}
+	return nmstate.ValidatePolicy(cr, nncps.Items)
+}
+
+// dpdkPrerequisitesWarnings returns the warnings about the selected nodes that have no hugepages or, when the
+// policy requires them, no isolated CPUs. The DPDK workloads of the vfio-pci VFs fail at runtime without them.
+func dpdkPrerequisitesWarnings(cr *sriovnetworkv1.SriovNetworkNodePolicy) []string {
+	nodeList, err := kubeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.Set(cr.Spec.NodeSelector).String(),
+	})
+	if err != nil {
+		log.Log.Error(err, "dpdkPrerequisitesWarnings(): failed to list the nodes")
+		return nil
+	}
+	noHugepages := []string{}
+	noIsolatedCpus := []string{}
+	for _, node := range nodeList.Items {
+		if !cr.Selected(&node) {
+			continue
+		}
+		hugepages := false
+		for name, quantity := range node.Status.Allocatable {
+			if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) && !quantity.IsZero() {
+				hugepages = true
+				break
+			}
+		}
+		if !hugepages {
+			noHugepages = append(noHugepages, node.Name)
+		}
+		if cr.Spec.RequireIsolatedCpus {
+			ns, err := snclient.SriovnetworkV1().SriovNetworkNodeStates(namespace).Get(context.Background(), node.Name, metav1.GetOptions{})
+			if err != nil {
+				log.Log.Error(err, "dpdkPrerequisitesWarnings(): failed to get the node state", "node", node.Name)
+				continue
+			}
+			if ns.Status.IsolatedCpus == "" {
+				noIsolatedCpus = append(noIsolatedCpus, node.Name)
+			}
+		}
+	}
+	warnings := []string{}
+	if len(noHugepages) > 0 {
+		sort.Strings(noHugepages)
+		warnings = append(warnings, fmt.Sprintf("the nodes %s selected by the vfio-pci policy %s have no hugepages, "+
+			"the DPDK workloads of its VFs will fail to start", strings.Join(noHugepages, ", "), cr.GetName()))
+	}
+	if len(noIsolatedCpus) > 0 {
+		sort.Strings(noIsolatedCpus)
+		warnings = append(warnings, fmt.Sprintf("the nodes %s selected by the vfio-pci policy %s have no isolated CPUs, "+
+			"set the isolcpus kernel argument for the DPDK workloads of its VFs", strings.Join(noIsolatedCpus, ", "), cr.GetName()))
+	}
+	return warnings
+}
+
 func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy) (bool, error) {
 	var validString = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 	if !validString.MatchString(cr.Spec.ResourceName) {
 		return false, fmt.Errorf("resource name \"%s\" contains invalid characters, the accepted syntax of the regular expressions is: \"^[a-zA-Z0-9_]+$\"", cr.Spec.ResourceName)
 	}
+	if err := sriovnetworkv1.ValidateExtendedResourceName(cr.Spec.ResourceName); err != nil {
+		return false, fmt.Errorf("invalid resource name %q in CR %s: %v", cr.Spec.ResourceName, cr.GetName(), err)
+	}
+	// the device plugin only implements the packed allocation of the VFs
+	if cr.Spec.AllocationPolicy == sriovnetworkv1.AllocationPolicySpread {
+		return false, fmt.Errorf("allocationPolicy %q in CR %s is not supported by the device plugin, only %q is",
+			cr.Spec.AllocationPolicy, cr.GetName(), sriovnetworkv1.AllocationPolicyPack)
+	}
 
-	if cr.Spec.NicSelector.Vendor == "" && cr.Spec.NicSelector.DeviceID == "" && len(cr.Spec.NicSelector.PfNames) == 0 && len(cr.Spec.NicSelector.RootDevices) == 0 && cr.Spec.NicSelector.NetFilter == "" {
-		return false, fmt.Errorf("at least one of these parameters (vendor, deviceID, pfNames, rootDevices or netFilter) has to be defined in nicSelector in CR %s", cr.GetName())
+	if cr.Spec.NicSelector.Vendor == "" && cr.Spec.NicSelector.DeviceID == "" && len(cr.Spec.NicSelector.PfNames) == 0 && len(cr.Spec.NicSelector.RootDevices) == 0 && cr.Spec.NicSelector.NetFilter == "" && len(cr.Spec.NicSelector.ZpciUids) == 0 {
+		return false, fmt.Errorf("at least one of these parameters (vendor, deviceID, pfNames, rootDevices, netFilter or zpciUids) has to be defined in nicSelector in CR %s", cr.GetName())
+	}
+	if cr.Spec.NicSelector.NetFilter != "" {
+		prefix, _, err := sriovnetworkv1.ParseNetFilter(cr.Spec.NicSelector.NetFilter)
+		if err != nil {
+			return false, fmt.Errorf("invalid netFilter in nicSelector in CR %s: %v", cr.GetName(), err)
+		}
+		if _, exists := sriovnetworkv1.LookupNetFilterProvider(prefix); !exists {
+			return false, fmt.Errorf("unsupported netFilter %q in nicSelector in CR %s, no platform provides the %s prefix",
+				cr.Spec.NicSelector.NetFilter, cr.GetName(), prefix)
+		}
+	}
+	for _, uid := range cr.Spec.NicSelector.ZpciUids {
+		if _, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(uid), "0x"), 16, 32); err != nil {
+			return false, fmt.Errorf("invalid zPCI UID %q in nicSelector in CR %s, expected a hexadecimal number", uid, cr.GetName())
+		}
 	}
 
 	devMode := false
@@ -194,6 +423,15 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 	if cr.Spec.DeviceType == consts.DeviceTypeVfioPci && cr.Spec.IsRdma {
 		return false, fmt.Errorf("'deviceType: vfio-pci' conflicts with 'isRdma: true'; Set 'deviceType' to (string)'netdevice' Or Set 'isRdma' to (bool)'false'")
 	}
+	if cr.Spec.DeviceType == consts.DeviceTypeVfioPci && cr.Spec.HwTimestamping {
+		return false, fmt.Errorf("'deviceType: vfio-pci' conflicts with 'hwTimestamping: true'; the hardware timestamping is enabled on the VF netdevs")
+	}
+	if cr.Spec.DisableIdleD3 && cr.Spec.DeviceType != consts.DeviceTypeVfioPci {
+		return false, fmt.Errorf("'disableIdleD3: true' requires 'deviceType: vfio-pci'; the option applies to the vfio_pci module")
+	}
+	if cr.Spec.DisableDriversAutoprobe && cr.Spec.DeviceType != consts.DeviceTypeVfioPci {
+		return false, fmt.Errorf("'disableDriversAutoprobe: true' requires 'deviceType: vfio-pci'; the VFs of the other device types are used through their kernel driver")
+	}
 	if strings.EqualFold(cr.Spec.LinkType, consts.LinkTypeIB) && !cr.Spec.IsRdma {
 		return false, fmt.Errorf("'linkType: ib or IB' requires 'isRdma: true'; Set 'isRdma' to (bool)'true'")
 	}
@@ -212,10 +450,340 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 	if cr.Spec.ExternallyManaged && cr.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
 		return false, fmt.Errorf("ExternallyManaged doesn't support the device to be configured in switchdev mode")
 	}
+	// the host network manager configuring an externally managed PF knows it by its name
+	if cr.Spec.ExternallyManaged && cr.Spec.PfNamePrefix != "" {
+		return false, fmt.Errorf("ExternallyManaged doesn't support the renaming of the PFs with pfNamePrefix")
+	}
+	if cr.Spec.ExposePfs {
+		if err := validateExposePfs(cr); err != nil {
+			return false, err
+		}
+	}
+	if cr.Spec.PortSplit > 0 {
+		if err := validatePortSplit(cr); err != nil {
+			return false, err
+		}
+	}
+
+	if err := validateFlowRules(cr); err != nil {
+		return false, err
+	}
+	if err := validateMirror(cr); err != nil {
+		return false, err
+	}
+	if err := validateUnicastMacs(cr); err != nil {
+		return false, err
+	}
+	if err := validateQos(cr); err != nil {
+		return false, err
+	}
+	if err := validateCongestionControl(cr); err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
 
+// validateExposePfs checks the policy advertising its PFs doesn't configure them, the PFs are left as they are
+func validateExposePfs(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.NumVfs != 0 {
+		return fmt.Errorf("'exposePfs: true' requires 'numVfs: 0'; the PFs are advertised instead of their VFs")
+	}
+	if cr.Spec.DeviceType != "" && cr.Spec.DeviceType != consts.DeviceTypeNetDevice {
+		return fmt.Errorf("'exposePfs: true' requires 'deviceType: netdevice'; the PFs are kept on their kernel driver")
+	}
+	switch {
+	case cr.Spec.ExternallyManaged:
+		return fmt.Errorf("'exposePfs: true' conflicts with 'externallyManaged: true'")
+	case cr.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev:
+		return fmt.Errorf("'exposePfs: true' conflicts with 'eSwitchMode: switchdev'")
+	case cr.Spec.VdpaType != "":
+		return fmt.Errorf("'exposePfs: true' conflicts with 'vdpaType: %s'", cr.Spec.VdpaType)
+	case cr.Spec.SystemReserved:
+		return fmt.Errorf("'exposePfs: true' conflicts with 'systemReserved: true'")
+	case cr.Spec.PfNamePrefix != "":
+		return fmt.Errorf("'exposePfs: true' conflicts with 'pfNamePrefix'; the PFs are not configured by the policy")
+	}
+	return nil
+}
+
+// validatePortSplit checks the policy splitting the ports selects whole PFs it configures. The split ports are PFs
+// of the same model as the port they come from, the policy selects the ports to split by their address or name.
+func validatePortSplit(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	switch {
+	case cr.Spec.ExternallyManaged:
+		return fmt.Errorf("'portSplit' conflicts with 'externallyManaged: true'; the ports of the externally managed PFs are left as they are")
+	case cr.Spec.ExposePfs:
+		return fmt.Errorf("'portSplit' conflicts with 'exposePfs: true'; the PFs are not configured by the policy")
+	case len(cr.Spec.NicSelector.RootDevices) == 0 && len(cr.Spec.NicSelector.PfNames) == 0:
+		return fmt.Errorf("'portSplit' requires the nicSelector to select the PFs by rootDevices or pfNames; " +
+			"the split ports are PFs of the same model")
+	}
+	for _, pfName := range cr.Spec.NicSelector.PfNames {
+		if strings.Contains(pfName, "#") {
+			return fmt.Errorf("'portSplit' conflicts with the VF range of pfNames %s; the whole port is split", pfName)
+		}
+	}
+	return nil
+}
+
+// validateFlowRules checks the flow rules of the policy can be rendered as tc flower filters
+func validateFlowRules(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if len(cr.Spec.FlowRules) == 0 {
+		return nil
+	}
+	if cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("flowRules require the device to be configured in switchdev mode")
+	}
+	if len(cr.Spec.FlowRules) > consts.MaxFlowRules {
+		return fmt.Errorf("too many flowRules, at most %d are supported", consts.MaxFlowRules)
+	}
+	names := map[string]bool{}
+	for _, rule := range cr.Spec.FlowRules {
+		if rule.Name == "" {
+			return fmt.Errorf("the flowRules require a name")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("flowRule %s is defined more than once", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Vf != nil && (*rule.Vf < 0 || *rule.Vf >= cr.Spec.NumVfs) {
+			return fmt.Errorf("flowRule %s: vf %d exceeds the maximum VF index", rule.Name, *rule.Vf)
+		}
+
+		switch rule.Action {
+		case sriovnetworkv1.FlowActionTrap, sriovnetworkv1.FlowActionDrop:
+			if rule.TargetVf != nil {
+				return fmt.Errorf("flowRule %s: targetVf is only supported by the mirror and redirect actions", rule.Name)
+			}
+		case sriovnetworkv1.FlowActionMirror, sriovnetworkv1.FlowActionRedirect:
+			if rule.TargetVf == nil {
+				return fmt.Errorf("flowRule %s: the %s action requires a targetVf", rule.Name, rule.Action)
+			}
+			if *rule.TargetVf < 0 || *rule.TargetVf >= cr.Spec.NumVfs {
+				return fmt.Errorf("flowRule %s: targetVf %d exceeds the maximum VF index", rule.Name, *rule.TargetVf)
+			}
+		default:
+			return fmt.Errorf("flowRule %s: unsupported action %q", rule.Name, rule.Action)
+		}
+
+		// every value ends up in the arguments of tc on the host
+		m := rule.Match
+		if m.EthType != "" && !flowEthType.MatchString(m.EthType) {
+			return fmt.Errorf("flowRule %s: invalid ethType %q, ip, ipv6, arp or a hex value are supported", rule.Name, m.EthType)
+		}
+		for _, mac := range []string{m.SrcMac, m.DstMac} {
+			if hw, err := net.ParseMAC(mac); mac != "" && (err != nil || len(hw) != 6) {
+				return fmt.Errorf("flowRule %s: invalid MAC address %q", rule.Name, mac)
+			}
+		}
+		if m.VlanID < 0 || m.VlanID > 4095 {
+			return fmt.Errorf("flowRule %s: invalid vlanId %d", rule.Name, m.VlanID)
+		}
+		switch m.IPProto {
+		case "", "tcp", "udp", "sctp", "icmp", "icmpv6":
+		default:
+			return fmt.Errorf("flowRule %s: unsupported ipProto %q", rule.Name, m.IPProto)
+		}
+		if m.DstPort < 0 || m.DstPort > 65535 {
+			return fmt.Errorf("flowRule %s: invalid dstPort %d", rule.Name, m.DstPort)
+		}
+		isIP := m.EthType == "ip" || m.EthType == "ipv6"
+		if (m.IPProto != "" || m.SrcIP != "" || m.DstIP != "") && !isIP {
+			return fmt.Errorf("flowRule %s: ipProto, srcIp and dstIp require the ip or ipv6 ethType", rule.Name)
+		}
+		for _, ip := range []string{m.SrcIP, m.DstIP} {
+			if ip == "" {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(ip); err != nil && net.ParseIP(ip) == nil {
+				return fmt.Errorf("flowRule %s: invalid IP address %q", rule.Name, ip)
+			}
+		}
+		if m.DstPort > 0 && m.IPProto != "tcp" && m.IPProto != "udp" && m.IPProto != "sctp" {
+			return fmt.Errorf("flowRule %s: dstPort requires the tcp, udp or sctp ipProto", rule.Name)
+		}
+	}
+	return nil
+}
+
+// validateMirror checks the mirror destination of the policy is a VF range and the sources are outside of it
+func validateMirror(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	mirror := cr.Spec.Mirror
+	if mirror == nil {
+		return nil
+	}
+	if cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("mirror requires the device to be configured in switchdev mode")
+	}
+	if !mirror.SourcePf && len(mirror.SourceVfs) == 0 {
+		return fmt.Errorf("mirror requires sourcePf or sourceVfs to be set")
+	}
+	if len(cr.Spec.NicSelector.PfNames) == 0 {
+		return fmt.Errorf("mirror requires the pfNames of the nicSelector to select a range of VFs, e.g. ens1f0#7-7")
+	}
+	for _, pf := range cr.Spec.NicSelector.PfNames {
+		if !strings.Contains(pf, "#") {
+			return fmt.Errorf("mirror requires the pfNames of the nicSelector to select a range of VFs, %s selects all the VFs", pf)
+		}
+	}
+	sources := map[int]bool{}
+	for _, vf := range mirror.SourceVfs {
+		if vf < 0 || vf >= cr.Spec.NumVfs {
+			return fmt.Errorf("mirror source VF %d exceeds the maximum VF index", vf)
+		}
+		if sources[vf] {
+			return fmt.Errorf("mirror source VF %d is defined more than once", vf)
+		}
+		sources[vf] = true
+		for _, pf := range cr.Spec.NicSelector.PfNames {
+			_, rngStart, rngEnd, err := sriovnetworkv1.ParsePFName(pf)
+			if err != nil {
+				return err
+			}
+			if vf >= rngStart && vf <= rngEnd {
+				return fmt.Errorf("mirror source VF %d is in the VF range of %s, the VFs of the policy receive the mirrored traffic", vf, pf)
+			}
+		}
+	}
+	return nil
+}
+
+// validateUnicastMacs checks the unicast MACs of the policy are set once on VFs of the policy with a netdev
+func validateUnicastMacs(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if len(cr.Spec.UnicastMacs) == 0 {
+		return nil
+	}
+	if cr.Spec.DeviceType != "" && cr.Spec.DeviceType != consts.DeviceTypeNetDevice {
+		return fmt.Errorf("unicastMacs requires 'deviceType: netdevice'; the MACs are added to the netdevs of the VFs")
+	}
+	if cr.Spec.VdpaType != "" {
+		return fmt.Errorf("unicastMacs is not supported with the vdpa devices")
+	}
+	vfs := map[int]bool{}
+	for _, entry := range cr.Spec.UnicastMacs {
+		if entry.Vf < 0 || entry.Vf >= cr.Spec.NumVfs {
+			return fmt.Errorf("unicastMacs VF %d exceeds the maximum VF index", entry.Vf)
+		}
+		if vfs[entry.Vf] {
+			return fmt.Errorf("unicastMacs VF %d is defined more than once", entry.Vf)
+		}
+		vfs[entry.Vf] = true
+		if !inPolicyVfRange(cr, entry.Vf) {
+			return fmt.Errorf("unicastMacs VF %d is not in the VF ranges of the pfNames of the nicSelector", entry.Vf)
+		}
+		if len(entry.Macs) == 0 {
+			return fmt.Errorf("unicastMacs VF %d has no MAC", entry.Vf)
+		}
+		macs := map[string]bool{}
+		for _, mac := range entry.Macs {
+			hw, err := net.ParseMAC(mac)
+			if err != nil || len(hw) != 6 {
+				return fmt.Errorf("unicastMacs VF %d: invalid MAC %q", entry.Vf, mac)
+			}
+			if hw[0]&1 != 0 || hw.String() == "00:00:00:00:00:00" {
+				return fmt.Errorf("unicastMacs VF %d: %s is not a unicast MAC", entry.Vf, mac)
+			}
+			if macs[hw.String()] {
+				return fmt.Errorf("unicastMacs VF %d: MAC %s is defined more than once", entry.Vf, mac)
+			}
+			macs[hw.String()] = true
+		}
+	}
+	return nil
+}
+
+// inPolicyVfRange returns true if the VF is selected by the policy, the policy without pfNames or with a pfName
+// without VF range selects all the VFs
+func inPolicyVfRange(cr *sriovnetworkv1.SriovNetworkNodePolicy, vf int) bool {
+	if len(cr.Spec.NicSelector.PfNames) == 0 {
+		return true
+	}
+	for _, pf := range cr.Spec.NicSelector.PfNames {
+		if !strings.Contains(pf, "#") {
+			return true
+		}
+		_, rngStart, rngEnd, err := sriovnetworkv1.ParsePFName(pf)
+		if err == nil && vf >= rngStart && vf <= rngEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// validateQos checks the QoS settings of the policy are set for an RDMA pool and the priorities and buffers exist
+func validateQos(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	qos := cr.Spec.Qos
+	if qos == nil {
+		return nil
+	}
+	if !cr.Spec.IsRdma {
+		return fmt.Errorf("qos requires isRdma to be set")
+	}
+	priorities := map[int]bool{}
+	for _, prio := range qos.PfcPriorities {
+		if prio < 0 || prio > 7 {
+			return fmt.Errorf("qos: invalid PFC priority %d, the priorities are in the range 0-7", prio)
+		}
+		if priorities[prio] {
+			return fmt.Errorf("qos: PFC priority %d is defined more than once", prio)
+		}
+		priorities[prio] = true
+	}
+	for prio, buffer := range qos.PrioToBuffer {
+		if buffer < 0 || buffer > 7 {
+			return fmt.Errorf("qos: invalid buffer %d for priority %d, the buffers are in the range 0-7", buffer, prio)
+		}
+	}
+	for buffer, size := range qos.BufferSizes {
+		if size < 0 {
+			return fmt.Errorf("qos: invalid size %d for buffer %d", size, buffer)
+		}
+	}
+	return nil
+}
+
+// validateCongestionControl checks the congestion control of the policy is set for an RDMA pool and its
+// priorities and notification packets are valid
+func validateCongestionControl(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	cc := cr.Spec.CongestionControl
+	if cc == nil {
+		return nil
+	}
+	if !cr.Spec.IsRdma {
+		return fmt.Errorf("congestionControl requires isRdma to be set")
+	}
+	priorities := map[int]bool{}
+	for _, prio := range cc.EcnPriorities {
+		if prio < 0 || prio > 7 {
+			return fmt.Errorf("congestionControl: invalid ECN priority %d, the priorities are in the range 0-7", prio)
+		}
+		if priorities[prio] {
+			return fmt.Errorf("congestionControl: ECN priority %d is defined more than once", prio)
+		}
+		priorities[prio] = true
+	}
+	if cc.CnpDscp != nil && (*cc.CnpDscp < 0 || *cc.CnpDscp > 63) {
+		return fmt.Errorf("congestionControl: invalid cnpDscp %d, the DSCP is in the range 0-63", *cc.CnpDscp)
+	}
+	if cc.CnpPriority != nil && (*cc.CnpPriority < 0 || *cc.CnpPriority > 7) {
+		return fmt.Errorf("congestionControl: invalid cnpPriority %d, the priorities are in the range 0-7", *cc.CnpPriority)
+	}
+	for name, value := range map[string]int{
+		"minRate":                 cc.MinRate,
+		"aiRate":                  cc.AiRate,
+		"haiRate":                 cc.HaiRate,
+		"timeReset":               cc.TimeReset,
+		"byteReset":               cc.ByteReset,
+		"rateReduceMonitorPeriod": cc.RateReduceMonitorPeriod,
+	} {
+		if value < 0 {
+			return fmt.Errorf("congestionControl: invalid %s %d", name, value)
+		}
+	}
+	return nil
+}
+
 func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy) (bool, error) {
 	nodesSelected = false
 	interfaceSelected = false
@@ -235,10 +803,27 @@ func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePo
 	if err != nil {
 		return false, err
 	}
+	db := compat.Load(compatLister)
+	unsupportedNodes := []string{}
+	for _, node := range nodeList.Items {
+		if cr.Selected(&node) && !sriovnetworkv1.IsSupportedNode(&node) {
+			unsupportedNodes = append(unsupportedNodes, fmt.Sprintf("%s (%s)", node.Name, sriovnetworkv1.NodeOperatingSystem(&node)))
+		}
+	}
+	if len(unsupportedNodes) > 0 {
+		return false, fmt.Errorf("the nodeSelector in CR %s selects the nodes %s whose operating system is not supported, "+
+			"SR-IOV can only be configured on %s nodes: add the %s label to the nodeSelector",
+			cr.GetName(), strings.Join(unsupportedNodes, ", "), strings.Join(vars.SupportedNodeOperatingSystems, ", "), consts.NodeOSLabel)
+	}
+
 	for _, node := range nodeList.Items {
 		if cr.Selected(&node) {
 			nodesSelected = true
-			err = validatePolicyForNodeStateAndPolicy(nsList, npList, &node, cr, nodeInterfaceErrorList)
+			err = validatePolicyForNodeStateAndPolicy(db, nsList, npList, &node, cr, nodeInterfaceErrorList)
+			if err != nil {
+				return false, err
+			}
+			err = validateResourceNameCollision(cr, &node, npList)
 			if err != nil {
 				return false, err
 			}
@@ -260,13 +845,16 @@ func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePo
 	return true, nil
 }
 
-func validatePolicyForNodeStateAndPolicy(nsList *sriovnetworkv1.SriovNetworkNodeStateList, npList *sriovnetworkv1.SriovNetworkNodePolicyList, node *corev1.Node, cr *sriovnetworkv1.SriovNetworkNodePolicy, nodeInterfaceErrorList map[string][]string) error {
+func validatePolicyForNodeStateAndPolicy(db compat.Database, nsList *sriovnetworkv1.SriovNetworkNodeStateList, npList *sriovnetworkv1.SriovNetworkNodePolicyList, node *corev1.Node, cr *sriovnetworkv1.SriovNetworkNodePolicy, nodeInterfaceErrorList map[string][]string) error {
 	for _, ns := range nsList.Items {
 		if ns.GetName() == node.GetName() {
 			interfaceAndErrorList, err := validatePolicyForNodeState(cr, &ns, node)
 			if err != nil {
 				return err
 			}
+			if err := validatePolicyCompatibility(db, cr, &ns, node); err != nil {
+				return err
+			}
 			if interfaceAndErrorList != nil {
 				nodeInterfaceErrorList[ns.GetName()] = interfaceAndErrorList
 			}
@@ -285,6 +873,117 @@ func validatePolicyForNodeStateAndPolicy(nsList *sriovnetworkv1.SriovNetworkNode
 	return nil
 }
 
+// validateResourceNameCollision rejects the policy when a node it selects already advertises its extended resource
+// while no policy defines the resource on the node, the resource then belongs to another device plugin
+func validateResourceNameCollision(cr *sriovnetworkv1.SriovNetworkNodePolicy, node *corev1.Node, npList *sriovnetworkv1.SriovNetworkNodePolicyList) error {
+	name := sriovnetworkv1.ExtendedResourceName(cr.Spec.ResourceName)
+	capacity, exists := node.Status.Capacity[name]
+	if !exists || capacity.IsZero() {
+		return nil
+	}
+	for _, np := range npList.Items {
+		if np.Spec.ResourceName == cr.Spec.ResourceName && np.Selected(node) {
+			return nil
+		}
+	}
+	return fmt.Errorf("resource name %s in CR %s collides with the extended resource %s already advertised on node %s by another device plugin",
+		cr.Spec.ResourceName, cr.GetName(), name, node.GetName())
+}
+
+// validatePolicyCompatibility checks the switchdev mode and the vdpa devices of the policy against the compatibility
+// database on the interfaces it selects on the node
+func validatePolicyCompatibility(db compat.Database, policy *sriovnetworkv1.SriovNetworkNodePolicy,
+	state *sriovnetworkv1.SriovNetworkNodeState, node *corev1.Node) error {
+	features := []compat.Feature{}
+	if policy.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+		features = append(features, compat.FeatureSwitchdev)
+	}
+	if policy.Spec.VdpaType != "" {
+		features = append(features, compat.FeatureVdpa)
+	}
+	for _, iface := range state.Status.Interfaces {
+		if validateNicModel(&policy.Spec.NicSelector, &iface, node) != nil {
+			continue
+		}
+		for _, feature := range features {
+			if err := db.Check(feature, &iface); err != nil {
+				return fmt.Errorf("CR %s can't be applied on node(%s): %v", policy.GetName(), state.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMsixCount checks the driver of the PF allows to set the MSI-X vectors of the VFs and the vectors of the
+// VFs of the policy fit in the vectors the PF distributes among its VFs
+func validateMsixCount(policy *sriovnetworkv1.SriovNetworkNodePolicy, iface *sriovnetworkv1.InterfaceExt, nodeName string) error {
+	if iface.VfTotalMsix == 0 {
+		return fmt.Errorf("msixCount in CR %s is not supported by the driver of interface(%s) on node(%s)", policy.GetName(), iface.Name, nodeName)
+	}
+	numVfs := policy.Spec.NumVfs
+	for _, p := range policy.Spec.NicSelector.PfNames {
+		name, rngSt, rngEnd, err := sriovnetworkv1.ParsePFName(p)
+		if err == nil && name == iface.Name && strings.Contains(p, "#") {
+			numVfs = rngEnd - rngSt + 1
+		}
+	}
+	if policy.Spec.MsixCount*numVfs > iface.VfTotalMsix {
+		return fmt.Errorf("msixCount(%d) of the %d VFs in CR %s exceed the MSI-X vectors(%d) of interface(%s) on node(%s)",
+			policy.Spec.MsixCount, numVfs, policy.GetName(), iface.VfTotalMsix, iface.Name, nodeName)
+	}
+	return nil
+}
+
+// portDescription names the port of the card in the errors of the validation
+func portDescription(iface *sriovnetworkv1.InterfaceExt) string {
+	if iface.Port != nil && iface.Port.Ports > 1 {
+		return fmt.Sprintf("interface(%s), port %d of card %s,", iface.Name, iface.Port.Index, iface.Port.Card)
+	}
+	return fmt.Sprintf("interface(%s)", iface.Name)
+}
+
+// validatePortLinkType checks the linkType of the policy against the port it selects. The ports of a card may
+// differ in link type, a policy selecting the PFs by their device ID can select both an Ethernet and an InfiniBand
+// port of the same card. The policies without linkType keep the link type of the ports.
+func validatePortLinkType(policy *sriovnetworkv1.SriovNetworkNodePolicy, iface *sriovnetworkv1.InterfaceExt, nodeName string) error {
+	if policy.Spec.LinkType == "" {
+		return nil
+	}
+	port := portDescription(iface)
+	if iface.LinkType != "" && !strings.EqualFold(policy.Spec.LinkType, iface.LinkType) && iface.Vendor != MellanoxID {
+		return fmt.Errorf("linkType(%s) in CR %s can't be set on %s on node(%s) whose link type is %s: only the link type "+
+			"of the ports of the Mellanox cards can be changed", policy.Spec.LinkType, policy.GetName(), port, nodeName, iface.LinkType)
+	}
+	if !strings.EqualFold(policy.Spec.LinkType, consts.LinkTypeIB) {
+		return nil
+	}
+	if !policy.Spec.IsRdma {
+		return fmt.Errorf("%s on node(%s) is an InfiniBand port, CR %s requires 'isRdma: true'", port, nodeName, policy.GetName())
+	}
+	if policy.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("%s on node(%s) is an InfiniBand port, the switchdev mode of CR %s is only supported on Ethernet ports",
+			port, nodeName, policy.GetName())
+	}
+	if policy.Spec.Qos != nil || policy.Spec.CongestionControl != nil {
+		return fmt.Errorf("%s on node(%s) is an InfiniBand port, the qos and congestionControl of CR %s are only supported on "+
+			"Ethernet ports", port, nodeName, policy.GetName())
+	}
+	return nil
+}
+
+// validatePortCapabilities checks the policy against the capabilities of the port it selects, the ports reported
+// without capabilities are not checked
+func validatePortCapabilities(policy *sriovnetworkv1.SriovNetworkNodePolicy, iface *sriovnetworkv1.InterfaceExt, nodeName string) error {
+	if iface.Port == nil || len(iface.Port.Capabilities) == 0 {
+		return nil
+	}
+	if policy.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev &&
+		!slices.Contains(iface.Port.Capabilities, sriovnetworkv1.PortCapabilitySwitchdev) {
+		return fmt.Errorf("%s on node(%s) doesn't support the switchdev mode of CR %s", portDescription(iface), nodeName, policy.GetName())
+	}
+	return nil
+}
+
 func validatePolicyForNodeState(policy *sriovnetworkv1.SriovNetworkNodePolicy, state *sriovnetworkv1.SriovNetworkNodeState, node *corev1.Node) ([]string, error) {
 	log.Log.V(2).Info("validatePolicyForNodeState(): validate policy for node", "policy-name",
 		policy.GetName(), "node-name", state.GetName())
@@ -295,7 +994,8 @@ func validatePolicyForNodeState(policy *sriovnetworkv1.SriovNetworkNodePolicy, s
 		if err == nil {
 			interfaceSelected = true
 			interfaceSelectedForNode = true
-			if policy.GetName() != consts.DefaultPolicyName && policy.Spec.NumVfs == 0 {
+			if policy.GetName() != consts.DefaultPolicyName && policy.Spec.NumVfs == 0 && !policy.Spec.ExposePfs &&
+				policy.Spec.PortSplit == 0 {
 				return nil, fmt.Errorf("numVfs(%d) in CR %s is not allowed", policy.Spec.NumVfs, policy.GetName())
 			}
 			if policy.Spec.NumVfs > iface.TotalVfs && iface.Vendor == IntelID {
@@ -319,6 +1019,33 @@ func validatePolicyForNodeState(policy *sriovnetworkv1.SriovNetworkNodePolicy, s
 					return nil, fmt.Errorf("LinkType(%s) in CR %s is not equal to the LinkType for the PF externally value(%s)", policy.Spec.LinkType, policy.GetName(), iface.LinkType)
 				}
 			}
+			if err := validatePortLinkType(policy, &iface, state.GetName()); err != nil {
+				return nil, err
+			}
+			if err := validatePortCapabilities(policy, &iface, state.GetName()); err != nil {
+				return nil, err
+			}
+			if policy.Spec.MsixCount > 0 {
+				if err := validateMsixCount(policy, &iface, state.GetName()); err != nil {
+					return nil, err
+				}
+			}
+			if !policy.Spec.AllowPtpSourceDisruption {
+				// the VFs of the other policies of the PF are kept, so only the growth of numVfs is disruptive
+				numVfs := policy.Spec.NumVfs
+				if numVfs < iface.NumVfs {
+					numVfs = iface.NumVfs
+				}
+				if sriovnetworkv1.PtpSourceDisrupted(&sriovnetworkv1.Interface{
+					NumVfs:      numVfs,
+					EswitchMode: policy.Spec.EswitchMode,
+					LinkType:    policy.Spec.LinkType,
+					PortSplit:   policy.Spec.PortSplit,
+				}, &iface) {
+					return nil, fmt.Errorf("interface(%s) on node(%s) is the PTP time source of the node, CR %s changes its "+
+						"numVfs, eSwitchMode, linkType or portSplit: set allowPtpSourceDisruption to allow it", iface.Name, state.GetName(), policy.GetName())
+				}
+			}
 			// vdpa: only mellanox cards are supported
 			if (policy.Spec.VdpaType == consts.VdpaTypeVirtio || policy.Spec.VdpaType == consts.VdpaTypeVhost) && iface.Vendor != MellanoxID {
 				return nil, fmt.Errorf("vendor(%s) in CR %s not supported for vdpa interface(%s)", iface.Vendor, policy.GetName(), iface.Name)
@@ -353,6 +1080,21 @@ func validatePolicyForNodePolicy(current *sriovnetworkv1.SriovNetworkNodePolicy,
 		return err
 	}
 
+	err = validateAllocationPolicyField(current, previous)
+	if err != nil {
+		return err
+	}
+
+	err = validateSystemReservedField(current, previous)
+	if err != nil {
+		return err
+	}
+
+	err = validateExposePfsField(current, previous)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -382,6 +1124,17 @@ func validatePfNames(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *s
 					return fmt.Errorf("switchdev overlap with externallyManage mode in existing policy %s", previous.GetName())
 				}
 
+				// reject policy advertising the PF if there is a policy creating VFs on the same PF
+				if current.Spec.ExposePfs != previous.Spec.ExposePfs {
+					return fmt.Errorf("exposePfs is inconsistent with existing policy %s on the same PF", previous.GetName())
+				}
+
+				// reject policy with a drainMode different from the one of a policy on the same PF
+				if current.Spec.DrainMode != previous.Spec.DrainMode {
+					return fmt.Errorf("drainMode %q is inconsistent with drainMode %q of existing policy %s on the same PF",
+						current.Spec.DrainMode, previous.Spec.DrainMode, previous.GetName())
+				}
+
 				// Check for overlapping ranges
 				if curRngEnd < preRngSt || curRngSt > preRngEnd {
 					return nil
@@ -407,6 +1160,48 @@ func validateExludeTopologyField(current *sriovnetworkv1.SriovNetworkNodePolicy,
 		current.Spec.ExcludeTopology, previous.GetName(), previous.Spec.ExcludeTopology, current.Spec.ResourceName)
 }
 
+func validateAllocationPolicyField(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if current.Spec.ResourceName != previous.Spec.ResourceName {
+		return nil
+	}
+
+	if current.Spec.AllocationPolicy == previous.Spec.AllocationPolicy {
+		return nil
+	}
+
+	return fmt.Errorf("allocationPolicy[%s] field conflicts with policy [%s].AllocationPolicy[%s] as they target the same resource[%s]",
+		current.Spec.AllocationPolicy, previous.GetName(), previous.Spec.AllocationPolicy, current.Spec.ResourceName)
+}
+
+// validateSystemReservedField rejects a resource shared by reserved and advertised policies, the resource name of a
+// reserved policy names its reservation
+func validateSystemReservedField(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if current.Spec.ResourceName != previous.Spec.ResourceName {
+		return nil
+	}
+
+	if current.Spec.SystemReserved == previous.Spec.SystemReserved {
+		return nil
+	}
+
+	return fmt.Errorf("systemReserved[%t] field conflicts with policy [%s].SystemReserved[%t] as they target the same resource[%s]",
+		current.Spec.SystemReserved, previous.GetName(), previous.Spec.SystemReserved, current.Spec.ResourceName)
+}
+
+// validateExposePfsField rejects a resource shared by policies advertising PFs and policies advertising VFs
+func validateExposePfsField(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if current.Spec.ResourceName != previous.Spec.ResourceName {
+		return nil
+	}
+
+	if current.Spec.ExposePfs == previous.Spec.ExposePfs {
+		return nil
+	}
+
+	return fmt.Errorf("exposePfs[%t] field conflicts with policy [%s].ExposePfs[%t] as they target the same resource[%s]",
+		current.Spec.ExposePfs, previous.GetName(), previous.Spec.ExposePfs, current.Spec.ResourceName)
+}
+
 func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *sriovnetworkv1.InterfaceExt, node *corev1.Node) error {
 	if selector.Vendor != "" && selector.Vendor != iface.Vendor {
 		return fmt.Errorf("selector vendor: %s is not equal to the interface vendor: %s", selector.Vendor, iface.Vendor)
@@ -414,9 +1209,12 @@ func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *s
 	if selector.DeviceID != "" && selector.DeviceID != iface.DeviceID {
 		return fmt.Errorf("selector device ID: %s is not equal to the interface device ID: %s", selector.Vendor, iface.Vendor)
 	}
-	if len(selector.RootDevices) > 0 && !sriovnetworkv1.StringInArray(iface.PciAddress, selector.RootDevices) {
+	if len(selector.RootDevices) > 0 && !sriovnetworkv1.PciAddressInArray(iface.PciAddress, selector.RootDevices) {
 		return fmt.Errorf("interface PCI address: %s not found in root devices", iface.PciAddress)
 	}
+	if len(selector.ZpciUids) > 0 && !sriovnetworkv1.ZpciUIDInArray(iface.ZpciUID, selector.ZpciUids) {
+		return fmt.Errorf("interface zPCI UID: %s not found in zPCI UIDs", iface.ZpciUID)
+	}
 	if len(selector.PfNames) > 0 {
 		var pfNames []string
 		for _, p := range selector.PfNames {
@@ -427,7 +1225,8 @@ func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *s
 				pfNames = append(pfNames, p)
 			}
 		}
-		if !sriovnetworkv1.StringInArray(iface.Name, pfNames) {
+		if !sriovnetworkv1.StringInArray(iface.Name, pfNames) &&
+			(iface.OriginalName == "" || !sriovnetworkv1.StringInArray(iface.OriginalName, pfNames)) {
 			return fmt.Errorf("interface name: %s not found in physical function names", iface.PciAddress)
 		}
 	}
@@ -437,10 +1236,15 @@ func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *s
 		return nil
 	}
 
+	// the zPCI functions of the RoCE Express adapters are VFs
+	if iface.ZpciUID != "" && sriovnetworkv1.IsVfSupportedModel(iface.Vendor, iface.DeviceID) {
+		return nil
+	}
+
 	// Check the vendor and device ID of the VF only if we are on a virtual environment
 	for key := range vars.PlatformsMap {
 		if strings.Contains(strings.ToLower(node.Spec.ProviderID), strings.ToLower(key)) &&
-			selector.NetFilter != "" && selector.NetFilter == iface.NetFilter &&
+			selector.NetFilter != "" && iface.NetFilterMatch(selector.NetFilter) &&
 			sriovnetworkv1.IsVfSupportedModel(iface.Vendor, iface.DeviceID) {
 			return nil
 		}
@@ -448,3 +1252,189 @@ func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *s
 
 	return fmt.Errorf("vendor and device ID is not in supported list")
 }
+
+// validateSriovNetworkPoolConfig checks the drain settings and the hardware offload of OVN-Kubernetes of the pool
+func validateSriovNetworkPoolConfig(pool *sriovnetworkv1.SriovNetworkPoolConfig) error {
+	log.Log.V(2).Info("validateSriovNetworkPoolConfig", "name", pool.Name)
+	if err := pool.ValidateDrainMode(); err != nil {
+		return err
+	}
+	return pool.ValidateOvnHardwareOffload()
+}
+
+// validateSriovNetwork checks the SriovNetworks created by the namespace owners outside of the operator namespace
+// against the SriovNetworkGrants of their namespace
+func validateSriovNetwork(network *sriovnetworkv1.SriovNetwork) error {
+	log.Log.V(2).Info("validateSriovNetwork", "namespace", network.Namespace, "name", network.Name)
+	if err := network.ValidateResourceInjection(); err != nil {
+		return err
+	}
+	if err := validateCniVersion("sriov", network.Spec.CniVersion); err != nil {
+		return err
+	}
+	if err := validatePolicing(network); err != nil {
+		return err
+	}
+	if err := network.ValidateAddressSharing(); err != nil {
+		return err
+	}
+	if err := validateNetworkCompatibility(network); err != nil {
+		return err
+	}
+	if err := validateFabricVlan(network); err != nil {
+		return err
+	}
+	if network.Namespace == namespace {
+		// the NetworkAttachmentDefinition must not already be rendered by a network of the target namespace
+		if network.Spec.NetworkNamespace == "" || network.Spec.NetworkNamespace == namespace {
+			return nil
+		}
+		_, err := networkLister.SriovNetworks(network.Spec.NetworkNamespace).Get(network.Name)
+		if err == nil {
+			return fmt.Errorf("SriovNetwork %s/%s already renders the NetworkAttachmentDefinition %s/%s",
+				network.Spec.NetworkNamespace, network.Name, network.Spec.NetworkNamespace, network.Name)
+		}
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the SriovNetwork %s/%s: %v", network.Spec.NetworkNamespace, network.Name, err)
+		}
+		return nil
+	}
+
+	ns, err := namespaceLister.Get(network.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace %s: %v", network.Namespace, err)
+	}
+	grants, err := grantLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworkGrants: %v", err)
+	}
+	items := make([]sriovnetworkv1.SriovNetworkGrant, 0, len(grants))
+	for _, grant := range grants {
+		items = append(items, *grant)
+	}
+	if err := sriovnetworkv1.ValidateTenantNetwork(network, ns, items); err != nil {
+		return err
+	}
+
+	// the NetworkAttachmentDefinition of the network must not be rendered by a network of the operator namespace
+	networks, err := networkLister.SriovNetworks(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworks: %v", err)
+	}
+	for _, n := range networks {
+		if n.Name == network.Name && n.Spec.NetworkNamespace == network.Namespace {
+			return fmt.Errorf("SriovNetwork %s/%s already renders the NetworkAttachmentDefinition %s/%s",
+				n.Namespace, n.Name, network.Namespace, network.Name)
+		}
+	}
+	return nil
+}
+
+// validateFabricVlan rejects the VLAN of the network when the fabric validation hook of the default
+// SriovOperatorConfig doesn't allow it
+func validateFabricVlan(network *sriovnetworkv1.SriovNetwork) error {
+	if network.Spec.Vlan == 0 {
+		return nil
+	}
+	config, err := snclient.SriovnetworkV1().SriovOperatorConfigs(namespace).Get(context.Background(), consts.DefaultConfigName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the SriovOperatorConfig: %v", err)
+	}
+	return fabric.ValidateNetworkVlan(context.Background(), config.Spec.VlanValidation, network)
+}
+
+// validateNetworkCompatibility checks the minimum transmit rate and the trust mode of the network, also enabled by
+// its multiple unicast MACs, against the compatibility database on the PFs of its resource, as configured in the
+// SriovNetworkNodeStates
+func validateNetworkCompatibility(network *sriovnetworkv1.SriovNetwork) error {
+	features := []compat.Feature{}
+	if network.Spec.MinTxRate != nil && *network.Spec.MinTxRate > 0 {
+		features = append(features, compat.FeatureMinTxRate)
+	}
+	if network.Spec.Trust == sriovnetworkv1.SriovCniStateOn ||
+		(network.Spec.AddressSharing != nil && network.Spec.AddressSharing.MultipleUnicastMacs) {
+		features = append(features, compat.FeatureTrust)
+	}
+	if len(features) == 0 {
+		return nil
+	}
+	db := compat.Load(compatLister)
+	if len(db) == 0 {
+		return nil
+	}
+	states, err := snclient.SriovnetworkV1().SriovNetworkNodeStates(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworkNodeStates: %v", err)
+	}
+	for _, state := range states.Items {
+		for _, iface := range state.Spec.Interfaces {
+			if !hasResource(iface.VfGroups, network.Spec.ResourceName) {
+				continue
+			}
+			for _, ifaceStatus := range state.Status.Interfaces {
+				if ifaceStatus.PciAddress != iface.PciAddress {
+					continue
+				}
+				for _, feature := range features {
+					if err := db.Check(feature, &ifaceStatus); err != nil {
+						return fmt.Errorf("SriovNetwork %s/%s can't be attached to resource %s on node(%s): %v",
+							network.Namespace, network.Name, network.Spec.ResourceName, state.GetName(), err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasResource(groups []sriovnetworkv1.VfGroup, resourceName string) bool {
+	for _, group := range groups {
+		if group.ResourceName == resourceName {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePolicing only lets the SriovNetworks of the operator namespace police a resource, the policing applies to all
+// the VFs of the resource whatever network the pods attach
+func validatePolicing(network *sriovnetworkv1.SriovNetwork) error {
+	if network.Spec.Policing == nil {
+		return nil
+	}
+	if network.Namespace != namespace {
+		return fmt.Errorf("the policing of resource %s can only be set by the SriovNetworks of namespace %s",
+			network.Spec.ResourceName, namespace)
+	}
+	networks, err := snclient.SriovnetworkV1().SriovNetworks(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworks: %v", err)
+	}
+	return network.ValidatePolicing(networks.Items)
+}
+
+// validateSriovIBNetwork checks the CNI specification version of the SriovIBNetworks
+func validateSriovIBNetwork(network *sriovnetworkv1.SriovIBNetwork) error {
+	log.Log.V(2).Info("validateSriovIBNetwork", "namespace", network.Namespace, "name", network.Name)
+	return validateCniVersion("ib-sriov", network.Spec.CniVersion)
+}
+
+// validateCniVersion rejects the CNI specification version of a network when the CNI plugin of a node doesn't
+// support it, as reported in the SriovNetworkNodeStates
+func validateCniVersion(pluginType, version string) error {
+	if version == "" {
+		return nil
+	}
+	states, err := snclient.SriovnetworkV1().SriovNetworkNodeStates(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the SriovNetworkNodeStates: %v", err)
+	}
+	if nodes := cni.UnsupportedNodes(states.Items, pluginType, version); len(nodes) > 0 {
+		return fmt.Errorf("the %s CNI plugin of the nodes %s doesn't support the CNI version %s",
+			pluginType, strings.Join(nodes, ", "), version)
+	}
+	return nil
+}
@@ -9,9 +9,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
 
 	. "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/compat"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 
 	fakesnclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned/fake"
@@ -37,6 +40,7 @@ func TestMain(m *testing.M) {
 		"14e4 16d7 16dc", // BCM57414 2x25G
 		"14e4 1750 1806", // BCM75508 2x100G
 	}
+	os.Setenv("RESOURCE_PREFIX", "openshift.io")
 	os.Exit(m.Run())
 }
 
@@ -260,6 +264,78 @@ func TestValidatePolicyForNodeStateWithValidPolicy(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+func TestValidatePolicyForNodeStateWithPtpTimeSource(t *testing.T) {
+	state := newNodeState()
+	state.Status.Interfaces[0].Ptp = &PtpInfo{Clock: "ptp0", Services: []string{"ptp4l", "phc2sys"}, TimeSource: true}
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames:     []string{"ens803f0"},
+				RootDevices: []string{"0000:86:00.0"},
+				Vendor:      "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       8,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("interface(ens803f0) on node() is the PTP time source of the node")))
+
+	policy.Spec.AllowPtpSourceDisruption = true
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the VFs already created are kept
+	policy.Spec.AllowPtpSourceDisruption = false
+	policy.Spec.NumVfs = 4
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestValidatePolicyForNodeStateWithMsixCount(t *testing.T) {
+	state := newNodeState()
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames:     []string{"ens803f0"},
+				RootDevices: []string{"0000:86:00.0"},
+				Vendor:      "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+			MsixCount:    32,
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("msixCount in CR p1 is not supported by the driver of interface(ens803f0)")))
+
+	state.Status.Interfaces[0].VfTotalMsix = 64
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("msixCount(32) of the 4 VFs in CR p1 exceed the MSI-X vectors(64)")))
+
+	policy.Spec.NicSelector.PfNames = []string{"ens803f0#2-3"}
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
 func TestValidatePolicyForNodeStateWithInvalidNumVfsPolicy(t *testing.T) {
 	state := newNodeState()
 	policy := &SriovNetworkNodePolicy{
@@ -476,6 +552,73 @@ func TestValidatePolicyForNodePolicyWithSwitchDevConflictWithExternallyManage(t
 	g.Expect(err).To(HaveOccurred())
 }
 
+func TestValidatePolicyForNodePolicyWithDrainModeConflict(t *testing.T) {
+	appliedPolicy := newNodePolicy()
+	appliedPolicy.Spec.DrainMode = DrainModeNone
+
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p0",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens803f1#3-4"},
+				Vendor:  "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       63,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	err := validatePolicyForNodePolicy(policy, appliedPolicy)
+	g.Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("drainMode \"\" is inconsistent with drainMode \"None\" of existing policy %s", appliedPolicy.Name))))
+
+	policy.Spec.DrainMode = DrainModeNone
+	err = validatePolicyForNodePolicy(policy, appliedPolicy)
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidateSriovNetworkPoolConfigDrainMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pool := &SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+		Spec:       SriovNetworkPoolConfigSpec{DrainSkip: true, DrainMode: DrainModeCordon},
+	}
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(Succeed())
+
+	pool.Spec.DrainMode = DrainModeDrain
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(MatchError("drainSkip cordons the nodes of the pool and conflicts with the Drain drainMode"))
+
+	pool.Spec.DrainSkip = false
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(Succeed())
+}
+
+func TestValidateSriovNetworkPoolConfigOvnHardwareOffload(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pool := &SriovNetworkPoolConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "offload"},
+		Spec: SriovNetworkPoolConfigSpec{
+			OvnHardwareOffload: &OvnHardwareOffloadConfig{PfNames: []string{"ens1f0"}},
+		},
+	}
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(MatchError("ovnHardwareOffload requires a nodeSelector with matchLabels only"))
+
+	pool.Spec.NodeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"offload": "true"}}
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(Succeed())
+
+	pool.Spec.OvnHardwareOffload.PfNames = []string{"ens1f0#0-3"}
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(MatchError(ContainSubstring("the whole PFs are configured")))
+
+	pool.Spec.OvnHardwareOffload.PfNames = []string{"ens1f0"}
+	pool.Spec.OvsHardwareOffloadConfig.Name = "worker"
+	g.Expect(validateSriovNetworkPoolConfig(pool)).To(MatchError(ContainSubstring("can't be combined with ovsHardwareOffloadConfig")))
+}
+
 func TestValidatePolicyForNodeStateWithExternallyManageAndMTU(t *testing.T) {
 	state := newNodeState()
 	policy := &SriovNetworkNodePolicy{
@@ -598,6 +741,62 @@ func TestValidatePolicyForNodeStateWithExternallyManageAndDifferentLinkType(t *t
 	g.Expect(err).To(HaveOccurred())
 }
 
+func TestValidatePolicyForNodeStateWithHeterogeneousPorts(t *testing.T) {
+	state := newNodeState()
+	state.Name = "worker-1"
+	state.Status.Interfaces = []InterfaceExt{
+		{Name: "ens1f0", PciAddress: "0000:3b:00.0", Vendor: "15b3", DeviceID: "101b", TotalVfs: 8, LinkType: "ETH",
+			Port: &NicPortInfo{Card: "0000:3b:00", Index: 0, Ports: 2, LinkType: "ETH", Speed: "100000 Mb/s",
+				Capabilities: []PortCapability{PortCapabilitySriov, PortCapabilitySwitchdev}}},
+		{Name: "ibs1f1", PciAddress: "0000:3b:00.1", Vendor: "15b3", DeviceID: "101b", TotalVfs: 8, LinkType: "IB",
+			Port: &NicPortInfo{Card: "0000:3b:00", Index: 1, Ports: 2, LinkType: "IB",
+				Capabilities: []PortCapability{PortCapabilitySriov}}},
+	}
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType:   "netdevice",
+			NicSelector:  SriovNetworkNicSelector{Vendor: "15b3", DeviceID: "101b"},
+			NumVfs:       4,
+			ResourceName: "p1",
+		},
+	}
+	g := NewGomegaWithT(t)
+	// the ports keep their link type
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the device ID selects the Ethernet port of the card too
+	policy.Spec.LinkType = "IB"
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("interface(ens1f0), port 0 of card 0000:3b:00, on node(worker-1) is an InfiniBand port")))
+
+	policy.Spec.NicSelector.PfNames = []string{"ibs1f1"}
+	policy.Spec.IsRdma = true
+	policy.Spec.EswitchMode = "switchdev"
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("switchdev mode of CR p1 is only supported on Ethernet ports")))
+
+	// the link type of the port is changed by the policy, the port doesn't support the switchdev mode
+	policy.Spec.LinkType = "ETH"
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("interface(ibs1f1), port 1 of card 0000:3b:00, on node(worker-1) doesn't support the switchdev mode of CR p1")))
+
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0"}
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidatePolicyForNodeStateWithLinkTypeChangeOfIntelPort(t *testing.T) {
+	state := newNodeState()
+	policy := newNodePolicy()
+	policy.Spec.LinkType = "IB"
+	policy.Spec.IsRdma = true
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("only the link type of the ports of the Mellanox cards can be changed")))
+}
+
 func TestValidatePolicyForNodePolicyWithOverlappedVfRange(t *testing.T) {
 	appliedPolicy := newNodePolicy()
 	policy := &SriovNetworkNodePolicy{
@@ -722,6 +921,103 @@ func TestValidatePoliciesWithSameExcludeTopologyForTheSameResource(t *testing.T)
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+func TestValidatePoliciesWithDifferentAllocationPolicyForTheSameResource(t *testing.T) {
+	current := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName:     "resourceX",
+			AllocationPolicy: AllocationPolicyPack,
+		},
+	}
+
+	previous := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "previousPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "resourceX",
+		},
+	}
+
+	err := validatePolicyForNodePolicy(current, previous)
+
+	g := NewGomegaWithT(t)
+	g.Expect(err).To(MatchError("allocationPolicy[pack] field conflicts with policy [previousPolicy].AllocationPolicy[] as they target the same resource[resourceX]"))
+}
+
+func TestValidatePoliciesWithDifferentAllocationPolicyForDifferentResources(t *testing.T) {
+	current := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName:     "resourceX",
+			AllocationPolicy: AllocationPolicyPack,
+		},
+	}
+
+	previous := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "previousPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName:     "resourceY",
+			AllocationPolicy: AllocationPolicySpread,
+		},
+	}
+
+	err := validatePolicyForNodePolicy(current, previous)
+
+	g := NewGomegaWithT(t)
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestValidatePoliciesWithDifferentSystemReservedForTheSameResource(t *testing.T) {
+	current := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName:   "resourceX",
+			SystemReserved: true,
+		},
+	}
+
+	previous := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "previousPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "resourceX",
+		},
+	}
+
+	err := validatePolicyForNodePolicy(current, previous)
+
+	g := NewGomegaWithT(t)
+	g.Expect(err).To(MatchError("systemReserved[true] field conflicts with policy [previousPolicy].SystemReserved[false] as they target the same resource[resourceX]"))
+}
+
+func TestValidatePoliciesWithDifferentExposePfsForTheSamePf(t *testing.T) {
+	current := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "macvlanParents",
+			NicSelector:  SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			ExposePfs:    true,
+		},
+	}
+
+	previous := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "previousPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "resourceX",
+			NicSelector:  SriovNetworkNicSelector{PfNames: []string{"ens1f0#0-3"}},
+			NumVfs:       4,
+		},
+	}
+
+	g := NewGomegaWithT(t)
+	g.Expect(validatePolicyForNodePolicy(current, previous)).To(MatchError("exposePfs is inconsistent with existing policy previousPolicy on the same PF"))
+
+	previous.Spec.NicSelector.PfNames = []string{"ens1f1"}
+	g.Expect(validatePolicyForNodePolicy(current, previous)).To(Succeed())
+
+	previous.Spec.ResourceName = "macvlanParents"
+	g.Expect(validatePolicyForNodePolicy(current, previous)).To(MatchError(
+		"exposePfs[true] field conflicts with policy [previousPolicy].ExposePfs[false] as they target the same resource[macvlanParents]"))
+}
+
 func TestStaticValidateSriovNetworkNodePolicyWithValidVendorDevice(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
@@ -744,93 +1040,358 @@ func TestStaticValidateSriovNetworkNodePolicyWithValidVendorDevice(t *testing.T)
 	g.Expect(ok).To(Equal(true))
 }
 
-func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendor(t *testing.T) {
+func TestStaticValidateSriovNetworkNodePolicyNetFilter(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
 			DeviceType: "netdevice",
 			NicSelector: SriovNetworkNicSelector{
-				Vendor: "8087",
-			},
-			NodeSelector: map[string]string{
-				"feature.node.kubernetes.io/network-sriov.capable": "true",
+				NetFilter: "openstack/NetworkID:ada9ec67-2c97-467c-b674-c47200e2f5da",
 			},
-			NumVfs:       63,
+			NumVfs:       1,
 			Priority:     99,
 			ResourceName: "p0",
 		},
 	}
 	g := NewGomegaWithT(t)
 	ok, err := staticValidateSriovNetworkNodePolicy(policy)
-	g.Expect(err).To(MatchError(ContainSubstring("vendor %s is not supported", policy.Spec.NicSelector.Vendor)))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	policy.Spec.NicSelector.NetFilter = "aws/SubnetID:subnet-0bb1c79de3EXAMPLE"
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("no platform provides the aws/SubnetID prefix")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.NicSelector.NetFilter = "ada9ec67-2c97-467c-b674-c47200e2f5da"
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("expected <prefix>:<value>")))
 	g.Expect(ok).To(Equal(false))
 }
 
-func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendorDevMode(t *testing.T) {
-	t.Setenv("DEV_MODE", "TRUE")
+func TestStaticValidateSriovNetworkNodePolicyAllocationPolicy(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
 		Spec: SriovNetworkNodePolicySpec{
-			DeviceType: "netdevice",
-			NicSelector: SriovNetworkNicSelector{
-				Vendor: "8087",
-			},
-			NodeSelector: map[string]string{
-				"feature.node.kubernetes.io/network-sriov.capable": "true",
-			},
-			NumVfs:       63,
-			Priority:     99,
-			ResourceName: "p0",
+			DeviceType:       "netdevice",
+			NicSelector:      SriovNetworkNicSelector{PfNames: []string{"ens1f0"}},
+			NumVfs:           4,
+			ResourceName:     "p0",
+			AllocationPolicy: AllocationPolicyPack,
 		},
 	}
 	g := NewGomegaWithT(t)
 	ok, err := staticValidateSriovNetworkNodePolicy(policy)
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(ok).To(Equal(true))
+
+	policy.Spec.AllocationPolicy = AllocationPolicySpread
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring(`allocationPolicy "spread" in CR policy is not supported by the device plugin`)))
+	g.Expect(ok).To(Equal(false))
 }
 
-func TestStaticValidateSriovNetworkNodePolicyWithInvalidDevice(t *testing.T) {
+func TestStaticValidateSriovNetworkNodePolicyFlowRules(t *testing.T) {
+	vf := 1
+	target := 7
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
-			DeviceType: "netdevice",
+			DeviceType:  "netdevice",
+			EswitchMode: "switchdev",
 			NicSelector: SriovNetworkNicSelector{
-				DeviceID: "1234",
-			},
-			NodeSelector: map[string]string{
-				"feature.node.kubernetes.io/network-sriov.capable": "true",
+				Vendor:   "15b3",
+				DeviceID: "101d",
 			},
-			NumVfs:       63,
-			Priority:     99,
+			NumVfs:       8,
 			ResourceName: "p0",
+			FlowRules: []FlowRule{
+				{Name: "trap-lacp", Match: FlowMatch{EthType: "0x8809", DstMac: "01:80:c2:00:00:02"}, Action: "trap"},
+				{Name: "mirror", Vf: &vf, Match: FlowMatch{EthType: "ip", IPProto: "tcp", DstIP: "10.0.0.0/8", DstPort: 80},
+					Action: "mirror", TargetVf: &target},
+			},
 		},
 	}
 	g := NewGomegaWithT(t)
 	ok, err := staticValidateSriovNetworkNodePolicy(policy)
-	g.Expect(err).To(MatchError(ContainSubstring("device %s is not supported", policy.Spec.NicSelector.DeviceID)))
-	g.Expect(ok).To(Equal(false))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	for _, tc := range []struct {
+		update func(*SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.EswitchMode = "legacy" }, "switchdev mode"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Name = "trap-lacp" }, "defined more than once"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.NumVfs = 4 }, "targetVf 7 exceeds the maximum VF index"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].TargetVf = nil }, "requires a targetVf"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[0].TargetVf = &target }, "only supported by the mirror and redirect actions"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[0].Match.DstMac = "01:80:c2" }, "invalid MAC address"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[0].Match.DstMac = "00:00:00:00:fe:80:00:00" }, "invalid MAC address"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[0].Match.EthType = "0x8809 action drop" }, "invalid ethType"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.IPProto = "udp;reboot" }, "unsupported ipProto"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.VlanID = 4096 }, "invalid vlanId"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.DstPort = -1 }, "invalid dstPort"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.EthType = "arp" }, "require the ip or ipv6 ethType"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.DstIP = "10.0.0" }, "invalid IP address"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.FlowRules[1].Match.IPProto = "icmp" }, "dstPort requires"},
+	} {
+		invalid := policy.DeepCopy()
+		tc.update(invalid)
+		ok, err := staticValidateSriovNetworkNodePolicy(invalid)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(BeFalse())
+	}
 }
 
-func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendorDevice(t *testing.T) {
+func TestStaticValidateSriovNetworkNodePolicyMirror(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
-			DeviceType: "netdevice",
+			DeviceType:  "netdevice",
+			EswitchMode: "switchdev",
 			NicSelector: SriovNetworkNicSelector{
-				Vendor:   "8086",
-				DeviceID: "1015",
-			},
-			NodeSelector: map[string]string{
-				"feature.node.kubernetes.io/network-sriov.capable": "true",
+				PfNames: []string{"ens1f0#7-7"},
 			},
-			NumVfs:       63,
-			Priority:     99,
-			ResourceName: "p0",
+			NumVfs:       8,
+			ResourceName: "mirror",
+			Mirror:       &MirrorConfig{SourcePf: true, SourceVfs: []int{0, 1}},
 		},
 	}
 	g := NewGomegaWithT(t)
 	ok, err := staticValidateSriovNetworkNodePolicy(policy)
-	g.Expect(err).To(MatchError(ContainSubstring("vendor/device %s/%s is not supported", policy.Spec.NicSelector.Vendor, policy.Spec.NicSelector.DeviceID)))
-	g.Expect(ok).To(Equal(false))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	for _, tc := range []struct {
+		update func(*SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.EswitchMode = "legacy" }, "switchdev mode"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Mirror = &MirrorConfig{} }, "sourcePf or sourceVfs"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.NicSelector.PfNames = []string{"ens1f0"} }, "ens1f0 selects all the VFs"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Mirror.SourceVfs = []int{8} }, "exceeds the maximum VF index"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Mirror.SourceVfs = []int{1, 1} }, "defined more than once"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Mirror.SourceVfs = []int{7} }, "is in the VF range of ens1f0#7-7"},
+	} {
+		invalid := policy.DeepCopy()
+		tc.update(invalid)
+		ok, err := staticValidateSriovNetworkNodePolicy(invalid)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(BeFalse())
+	}
 }
 
-func TestStaticValidateSriovNetworkNodePolicyWithConflictIsRdmaAndDeviceType(t *testing.T) {
+func TestStaticValidateSriovNetworkNodePolicyUnicastMacs(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens1f0#0-3"},
+			},
+			NumVfs:       8,
+			ResourceName: "bond",
+			UnicastMacs: []VfUnicastMacs{
+				{Vf: 0, Macs: []string{"02:00:00:00:00:01", "02:00:00:00:00:02"}},
+				{Vf: 3, Macs: []string{"02:00:00:00:00:03"}},
+			},
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	for _, tc := range []struct {
+		update func(*SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.DeviceType = "vfio-pci" }, "requires 'deviceType: netdevice'"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Vf = 8 }, "exceeds the maximum VF index"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Vf = 0 }, "defined more than once"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Vf = 4 }, "not in the VF ranges"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Macs = nil }, "has no MAC"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Macs = []string{"02:00:00:00:00"} }, "invalid MAC"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Macs = []string{"01:00:5e:00:00:01"} }, "not a unicast MAC"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[1].Macs = []string{"00:00:00:00:00:00"} }, "not a unicast MAC"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.UnicastMacs[0].Macs[1] = "02:00:00:00:00:01" }, "defined more than once"},
+	} {
+		invalid := policy.DeepCopy()
+		tc.update(invalid)
+		ok, err := staticValidateSriovNetworkNodePolicy(invalid)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(BeFalse())
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyQos(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens1f0"},
+			},
+			NumVfs:       8,
+			IsRdma:       true,
+			ResourceName: "roce",
+			Qos: &QosConfig{
+				Trust:         "dscp",
+				PfcPriorities: []int{3},
+				PrioToBuffer:  []int{0, 0, 0, 1, 0, 0, 0, 0},
+				BufferSizes:   []int{130944, 130944},
+			},
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	for _, tc := range []struct {
+		update func(*SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.IsRdma = false }, "requires isRdma"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Qos.PfcPriorities = []int{8} }, "invalid PFC priority 8"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Qos.PfcPriorities = []int{3, 3} }, "defined more than once"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Qos.PrioToBuffer = []int{0, 8} }, "invalid buffer 8 for priority 1"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Qos.BufferSizes = []int{-1} }, "invalid size -1"},
+	} {
+		invalid := policy.DeepCopy()
+		tc.update(invalid)
+		ok, err := staticValidateSriovNetworkNodePolicy(invalid)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(BeFalse())
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyCongestionControl(t *testing.T) {
+	cnpDscp := 48
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens1f0"},
+			},
+			NumVfs:       8,
+			IsRdma:       true,
+			ResourceName: "roce",
+			CongestionControl: &CongestionControlConfig{
+				EcnPriorities: []int{3},
+				CnpDscp:       &cnpDscp,
+				MinRate:       1,
+			},
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	invalidDscp, invalidPriority := 64, 8
+	for _, tc := range []struct {
+		update func(*SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.IsRdma = false }, "requires isRdma"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.CongestionControl.EcnPriorities = []int{8} }, "invalid ECN priority 8"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.CongestionControl.EcnPriorities = []int{3, 3} }, "defined more than once"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.CongestionControl.CnpDscp = &invalidDscp }, "invalid cnpDscp 64"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.CongestionControl.CnpPriority = &invalidPriority }, "invalid cnpPriority 8"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.CongestionControl.AiRate = -1 }, "invalid aiRate -1"},
+	} {
+		invalid := policy.DeepCopy()
+		tc.update(invalid)
+		ok, err := staticValidateSriovNetworkNodePolicy(invalid)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(BeFalse())
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendor(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				Vendor: "8087",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       63,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("vendor %s is not supported", policy.Spec.NicSelector.Vendor)))
+	g.Expect(ok).To(Equal(false))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendorDevMode(t *testing.T) {
+	t.Setenv("DEV_MODE", "TRUE")
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				Vendor: "8087",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       63,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithInvalidDevice(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				DeviceID: "1234",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       63,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("device %s is not supported", policy.Spec.NicSelector.DeviceID)))
+	g.Expect(ok).To(Equal(false))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithInvalidVendorDevice(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "1015",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       63,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("vendor/device %s/%s is not supported", policy.Spec.NicSelector.Vendor, policy.Spec.NicSelector.DeviceID)))
+	g.Expect(ok).To(Equal(false))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithConflictIsRdmaAndDeviceType(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
 			DeviceType: constants.DeviceTypeVfioPci,
@@ -853,6 +1414,194 @@ func TestStaticValidateSriovNetworkNodePolicyWithConflictIsRdmaAndDeviceType(t *
 	g.Expect(ok).To(Equal(false))
 }
 
+func TestStaticValidateSriovNetworkNodePolicyWithConflictHwTimestampingAndDeviceType(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeVfioPci,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "15b3",
+				DeviceID: "101d",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:         1,
+			Priority:       99,
+			ResourceName:   "p0",
+			HwTimestamping: true,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'deviceType: vfio-pci' conflicts with 'hwTimestamping: true'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.DeviceType = constants.DeviceTypeNetDevice
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithDisableIdleD3AndDeviceType(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:        1,
+			Priority:      99,
+			ResourceName:  "p0",
+			DisableIdleD3: true,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'disableIdleD3: true' requires 'deviceType: vfio-pci'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.DeviceType = constants.DeviceTypeVfioPci
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithExternallyManagedAndPfNamePrefix(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:            1,
+			Priority:          99,
+			ResourceName:      "p0",
+			ExternallyManaged: true,
+			PfNamePrefix:      "sriov",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("ExternallyManaged doesn't support the renaming of the PFs")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.ExternallyManaged = false
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithDisableDriversAutoprobeAndDeviceType(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:                  1,
+			Priority:                99,
+			ResourceName:            "p0",
+			DisableDriversAutoprobe: true,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'disableDriversAutoprobe: true' requires 'deviceType: vfio-pci'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.DeviceType = constants.DeviceTypeVfioPci
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithExposePfs(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+			ExposePfs:    true,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'exposePfs: true' requires 'numVfs: 0'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.NumVfs = 0
+	policy.Spec.DeviceType = constants.DeviceTypeVfioPci
+	_, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'exposePfs: true' requires 'deviceType: netdevice'")))
+
+	policy.Spec.DeviceType = constants.DeviceTypeNetDevice
+	policy.Spec.PfNamePrefix = "sriov"
+	_, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'exposePfs: true' conflicts with 'pfNamePrefix'")))
+
+	policy.Spec.PfNamePrefix = ""
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithPortSplit(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "1592",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+			PortSplit:    4,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'portSplit' requires the nicSelector to select the PFs by rootDevices or pfNames")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0#0-3"}
+	_, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'portSplit' conflicts with the VF range of pfNames ens1f0#0-3")))
+
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0"}
+	policy.Spec.ExternallyManaged = true
+	_, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'portSplit' conflicts with 'externallyManaged: true'")))
+
+	policy.Spec.ExternallyManaged = false
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
 func TestStaticValidateSriovNetworkNodePolicyWithConflictDeviceTypeAndVirtioVdpaType(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
@@ -1196,3 +1945,530 @@ func TestValidatePolicyForNodeStateWithValidVFAndNetFilter(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(interfaceSelected).To(Equal(true))
 }
+
+// startTestInformers starts the informers of the webhook listers on the fake clients until the end of the test
+func startTestInformers(t *testing.T) {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	if err := startInformers(stopCh); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateSriovNetworkGrant(t *testing.T) {
+	g := NewGomegaWithT(t)
+	snclient = fakesnclientset.NewSimpleClientset(
+		&SriovNetworkGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec: SriovNetworkGrantSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				ResourceNames:     []string{"nic1"},
+				VlanRanges:        []VlanRange{"100-199"},
+			},
+		},
+		&SriovNetwork{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: namespace},
+			Spec:       SriovNetworkSpec{ResourceName: "nic1", NetworkNamespace: "tenant-a"},
+		},
+	)
+	kubeclient = fakek8s.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"team": "a"}}},
+	)
+	startTestInformers(t)
+
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: "tenant-a"},
+		Spec:       SriovNetworkSpec{ResourceName: "nic1", Vlan: 100},
+	}
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	network.Spec.Vlan = 200
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("VLAN 200 is not granted")))
+
+	// the NetworkAttachmentDefinition is already rendered by a network of the operator namespace
+	network.Name = "shared"
+	network.Spec.Vlan = 100
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("already renders the NetworkAttachmentDefinition tenant-a/shared")))
+
+	// the networks of the operator namespace are not restricted
+	network.Namespace = namespace
+	network.Spec.ResourceName = "nic2"
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	// the NetworkAttachmentDefinition is already rendered by a network of the target namespace
+	network.Name = "tenant-net"
+	network.Spec.NetworkNamespace = "tenant-a"
+	_, err := snclient.SriovnetworkV1().SriovNetworks("tenant-a").Create(context.Background(), &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-net", Namespace: "tenant-a"},
+		Spec:       SriovNetworkSpec{ResourceName: "nic1"},
+	}, metav1.CreateOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Eventually(func() error { return validateSriovNetwork(network) }).
+		Should(MatchError(ContainSubstring("SriovNetwork tenant-a/tenant-net already renders the NetworkAttachmentDefinition tenant-a/tenant-net")))
+	network.Name = "shared"
+	network.Spec.NetworkNamespace = ""
+
+	network.Spec.ResourceInjection = &ResourceInjection{ResourceName: "shared"}
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("must be an extended resource")))
+	network.Spec.ResourceInjection.ResourceName = "rdma/hca_shared_devices_a"
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+}
+
+func TestValidateSriovNetworkPolicing(t *testing.T) {
+	g := NewGomegaWithT(t)
+	snclient = fakesnclientset.NewSimpleClientset(
+		&SriovNetwork{
+			ObjectMeta: metav1.ObjectMeta{Name: "capped", Namespace: namespace},
+			Spec: SriovNetworkSpec{ResourceName: "nic1", Policing: &PolicingConfig{
+				Egress: &PolicingRate{Rate: 1000, Burst: 64},
+			}},
+		},
+	)
+
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: namespace},
+		Spec: SriovNetworkSpec{ResourceName: "nic1", Policing: &PolicingConfig{
+			Egress: &PolicingRate{Rate: 1000, Burst: 64},
+		}},
+	}
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	network.Spec.Policing.Egress.Rate = 2000
+	g.Expect(validateSriovNetwork(network)).To(MatchError("resource nic1 is already policed differently by SriovNetwork capped"))
+
+	network.Spec.ResourceName = "nic2"
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	network.Spec.Policing.Egress = nil
+	g.Expect(validateSriovNetwork(network)).To(MatchError("policing requires an egress or an ingress rate"))
+
+	// the tenants can't cap the VFs shared with the other tenants
+	network.Namespace = "tenant-a"
+	network.Spec.Policing.Ingress = &PolicingRate{Rate: 100, Burst: 16}
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("can only be set by the SriovNetworks of namespace")))
+}
+
+func TestValidateSriovNetworkFabricVlan(t *testing.T) {
+	g := NewGomegaWithT(t)
+	config := &SriovOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: namespace},
+		Spec: SriovOperatorConfigSpec{VlanValidation: &VlanValidationConfig{
+			Command: []string{"/bin/sh", "-c", `echo '{"allowed": false, "message": "not provisioned on the ToR"}'`},
+		}},
+	}
+	snclient = fakesnclientset.NewSimpleClientset(config)
+
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: namespace},
+		Spec:       SriovNetworkSpec{ResourceName: "nic1", Vlan: 100},
+	}
+	g.Expect(validateSriovNetwork(network)).To(MatchError("vlan 100 is rejected by the fabric: not provisioned on the ToR"))
+
+	// the networks without VLAN are not checked
+	network.Spec.Vlan = 0
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	// the errors of the hook only reject the VLAN with the Fail policy
+	network.Spec.Vlan = 100
+	config.Spec.VlanValidation = &VlanValidationConfig{Command: []string{"/bin/false"}}
+	snclient = fakesnclientset.NewSimpleClientset(config)
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("vlan validation hook command failed")))
+
+	config.Spec.VlanValidation.FailurePolicy = constants.FailurePolicyIgnore
+	snclient = fakesnclientset.NewSimpleClientset(config)
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+}
+
+func TestValidateNetworkCniVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+	snclient = fakesnclientset.NewSimpleClientset(
+		&SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: namespace},
+			Status: SriovNetworkNodeStateStatus{CniPlugins: []CniPluginStatus{
+				{Type: "sriov", SupportedVersions: []string{"0.3.1", "0.4.0", "1.0.0"}},
+				{Type: "ib-sriov", SupportedVersions: []string{"0.3.1", "0.4.0", "1.0.0", "1.1.0"}},
+			}},
+		},
+	)
+
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: namespace},
+		Spec:       SriovNetworkSpec{ResourceName: "nic1", CniVersion: "1.0.0"},
+	}
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+	network.Spec.CniVersion = "1.1.0"
+	g.Expect(validateSriovNetwork(network)).To(MatchError("the sriov CNI plugin of the nodes worker-0 doesn't support the CNI version 1.1.0"))
+
+	ibNetwork := &SriovIBNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "ib", Namespace: namespace},
+		Spec:       SriovIBNetworkSpec{ResourceName: "ib1", CniVersion: "1.1.0"},
+	}
+	g.Expect(validateSriovIBNetwork(ibNetwork)).To(Succeed())
+}
+
+func TestValidatePolicyCompatibility(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db, err := compat.Parse(`
+- feature: switchdev
+  driver: i40e
+  reason: the driver has no switchdev mode
+- feature: vdpa
+  vendor: "15b3"
+  minFirmwareVersion: "22.31"
+`)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	state := newNodeState()
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+		Spec: SriovNetworkNodePolicySpec{
+			NicSelector: SriovNetworkNicSelector{PfNames: []string{"ens803f0"}},
+			NumVfs:      4,
+		},
+	}
+	g.Expect(validatePolicyCompatibility(db, policy, state, NewNode())).To(Succeed())
+
+	policy.Spec.EswitchMode = ESwithModeSwitchDev
+	g.Expect(validatePolicyCompatibility(db, policy, state, NewNode())).To(MatchError("CR p1 can't be applied on node(): " +
+		"switchdev on interface(ens803f0), model 8086:158b with driver i40e, is not supported: the driver has no switchdev mode"))
+
+	state.Status.Interfaces[0].Vendor = "15b3"
+	state.Status.Interfaces[0].DeviceID = "101d"
+	state.Status.Interfaces[0].Driver = "mlx5_core"
+	state.Status.Interfaces[0].FirmwareVersion = "22.28.4000 (MT_0000000359)"
+	policy.Spec.NicSelector.Vendor = "15b3"
+	policy.Spec.VdpaType = constants.VdpaTypeVirtio
+	g.Expect(validatePolicyCompatibility(db, policy, state, NewNode())).To(MatchError(ContainSubstring(
+		"vdpa on interface(ens803f0), model 15b3:101d with driver mlx5_core, requires firmware version 22.31 or newer")))
+	state.Status.Interfaces[0].FirmwareVersion = "22.31.1014 (MT_0000000359)"
+	g.Expect(validatePolicyCompatibility(db, policy, state, NewNode())).To(Succeed())
+}
+
+func TestValidateNetworkCompatibility(t *testing.T) {
+	g := NewGomegaWithT(t)
+	// the ConfigMap of the database is cached from the operator namespace
+	origNamespace := namespace
+	namespace = "openshift-sriov-network-operator"
+	t.Cleanup(func() { namespace = origNamespace })
+	state := newNodeState()
+	state.Name = "worker-0"
+	state.Namespace = namespace
+	state.Status.Interfaces[1].FirmwareVersion = "4.20 0x80017785 1.3346.0"
+	snclient = fakesnclientset.NewSimpleClientset(state)
+	kubeclient = fakek8s.NewSimpleClientset()
+	startTestInformers(t)
+
+	minTxRate := 100
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "net", Namespace: namespace},
+		Spec:       SriovNetworkSpec{ResourceName: "nic1", MinTxRate: &minTxRate, Trust: SriovCniStateOn},
+	}
+	// the built-in database has no requirement on the trust
+	g.Expect(validateSriovNetwork(network)).To(MatchError("SriovNetwork " + namespace + "/net can't be attached to resource nic1 " +
+		"on node(worker-0): min_tx_rate on interface(ens803f1), model 8086:158b with driver i40e, is not supported: " +
+		"the driver rejects a minimum rate"))
+	network.Spec.MinTxRate = nil
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+	network.Spec.MinTxRate = &minTxRate
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: compat.ConfigMapName, Namespace: namespace},
+		Data: map[string]string{compat.ConfigMapKey: `
+- feature: min_tx_rate
+  driver: i40e
+  minFirmwareVersion: "4.30"
+`},
+	}
+	_, err := kubeclient.CoreV1().ConfigMaps(namespace).Create(context.Background(), cm, metav1.CreateOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Eventually(func() error { _, err := compatLister.Get(compat.ConfigMapName); return err }).Should(Succeed())
+	g.Expect(validateSriovNetwork(network)).To(MatchError("SriovNetwork " + namespace + "/net can't be attached to resource nic1 " +
+		"on node(worker-0): min_tx_rate on interface(ens803f1), model 8086:158b with driver i40e, requires firmware version " +
+		"4.30 or newer, the firmware version is 4.20 0x80017785 1.3346.0"))
+
+	network.Spec.MinTxRate = nil
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+	network.Spec.MinTxRate = &minTxRate
+	network.Spec.ResourceName = "nic2"
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+}
+
+func TestValidateNetworkAddressSharing(t *testing.T) {
+	g := NewGomegaWithT(t)
+	// the ConfigMap of the database is cached from the operator namespace
+	origNamespace := namespace
+	namespace = "openshift-sriov-network-operator"
+	t.Cleanup(func() { namespace = origNamespace })
+	state := newNodeState()
+	state.Name = "worker-0"
+	state.Namespace = namespace
+	snclient = fakesnclientset.NewSimpleClientset(state)
+	kubeclient = fakek8s.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: compat.ConfigMapName, Namespace: namespace},
+		Data:       map[string]string{compat.ConfigMapKey: "- feature: trust\n  driver: i40e\n"},
+	})
+	startTestInformers(t)
+
+	network := &SriovNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "vrrp", Namespace: namespace},
+		Spec: SriovNetworkSpec{ResourceName: "nic1", SpoofChk: SriovCniStateOn,
+			AddressSharing: &AddressSharing{AllowSpoofing: true, ProxyArp: true}},
+	}
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("requires the spoof check of the VFs off")))
+	network.Spec.SpoofChk = ""
+	g.Expect(validateSriovNetwork(network)).To(Succeed())
+
+	// the multiple unicast MACs trust the VFs
+	network.Spec.AddressSharing.MultipleUnicastMacs = true
+	g.Expect(validateSriovNetwork(network)).To(MatchError(ContainSubstring("trust on interface(ens803f1), model 8086:158b with driver i40e, is not supported")))
+}
+
+func TestValidateSriovOperatorConfigOverlays(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+	target := OverlayTarget{Kind: "DaemonSet", Name: "sriov-device-plugin"}
+
+	config.Spec.Overlays = []ManifestOverlay{
+		{Name: "sidecar", Target: target, Patch: "spec:\n  template:\n    metadata:\n      labels:\n        a: b"},
+		{Name: "image", Target: target, Type: JSON6902Overlay, Patch: `[{"op": "add", "path": "/metadata/labels/c", "value": "d"}]`},
+	}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.Overlays = append(config.Spec.Overlays, ManifestOverlay{Name: "sidecar", Target: target, Patch: "{}"})
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError("overlay sidecar is defined more than once"))
+
+	config.Spec.Overlays = []ManifestOverlay{{Name: "broken", Target: target, Type: JSON6902Overlay, Patch: "spec: {}"}}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("overlay broken: invalid JSON patch")))
+}
+
+func TestValidateSriovOperatorConfigDriverQuirks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.DriverQuirks = []DriverQuirk{
+		{Name: "ice-rdma-aux", Driver: "ice", MinFirmwareVersion: "4.20", MaxFirmwareVersion: "4.30",
+			Skip: []VfConfigStep{VfConfigStepAdminMac}},
+		{Name: "i40e-mtu", Driver: "i40e", Skip: []VfConfigStep{VfConfigStepMtu}},
+	}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.DriverQuirks = append(config.Spec.DriverQuirks, DriverQuirk{Name: "i40e-mtu", Driver: "i40e",
+		Skip: []VfConfigStep{VfConfigStepMtu}})
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError("driver quirk i40e-mtu is defined more than once"))
+
+	config.Spec.DriverQuirks = []DriverQuirk{{Name: "ice-rdma-aux", Driver: "ice", MinFirmwareVersion: "v4",
+		Skip: []VfConfigStep{VfConfigStepAdminMac}}}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring(`driver quirk ice-rdma-aux: unable to parse firmware version "v4"`)))
+}
+
+func TestValidateSriovOperatorConfigDrainEscalation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.DrainEscalation = &DrainEscalationConfig{WarnAfterMinutes: 10, AbortAfterMinutes: 60}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.DrainEscalation.ForceAfterMinutes = 90
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError("drainEscalation abortAfterMinutes (60) must be greater than forceAfterMinutes (90)"))
+}
+
+func TestValidateSriovOperatorConfigServiceIPFamilies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.ServiceIPFamilyPolicy = corev1.IPFamilyPolicyPreferDualStack
+	config.Spec.ServiceIPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.ServiceIPFamilyPolicy = corev1.IPFamilyPolicySingleStack
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("allows a single IP family")))
+
+	config.Spec.ServiceIPFamilyPolicy = corev1.IPFamilyPolicyRequireDualStack
+	config.Spec.ServiceIPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv6Protocol}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("listed more than once")))
+
+	config.Spec.ServiceIPFamilies = []corev1.IPFamily{"IPv5"}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring(`unsupported IP family "IPv5"`)))
+}
+
+func TestValidateSriovOperatorConfigFeatureGates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.FeatureGates = map[string]bool{"metricsExporter": true, "systemdMode": false}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.FeatureGates["foo"] = true
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("unknown feature gate foo")))
+}
+
+func TestDynamicValidateSriovNetworkNodePolicyRejectsWindowsNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	snclient = fakesnclientset.NewSimpleClientset()
+	kubeclient = fakek8s.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "win-0", Labels: map[string]string{
+			"feature.node.kubernetes.io/network-sriov.capable": "true",
+			"kubernetes.io/os": "windows",
+		}}},
+	)
+
+	policy := newNodePolicy()
+	_, err := dynamicValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("selects the nodes win-0 (windows) whose operating system is not supported")))
+
+	policy.Spec.NodeSelector["kubernetes.io/os"] = "linux"
+	_, err = dynamicValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("no matched node is selected")))
+}
+
+func TestValidateResourceNameCollision(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{"feature.node.kubernetes.io/network-sriov.capable": "true"}},
+		Status: corev1.NodeStatus{Capacity: corev1.ResourceList{"openshift.io/p1": resource.MustParse("4"), "openshift.io/gone": resource.MustParse("0")}}}
+	policy := newNodePolicy()
+	npList := &SriovNetworkNodePolicyList{}
+
+	// the resource is advertised by another device plugin
+	g.Expect(validateResourceNameCollision(policy, node, npList)).To(
+		MatchError("resource name p1 in CR p1 collides with the extended resource openshift.io/p1 already advertised on node worker-0 by another device plugin"))
+
+	// the resource is advertised for a policy of the operator
+	npList.Items = append(npList.Items, *newNodePolicy())
+	npList.Items[0].Name = "p2"
+	g.Expect(validateResourceNameCollision(policy, node, npList)).To(Succeed())
+
+	// the resources left behind by a stopped device plugin have no capacity
+	policy.Spec.ResourceName = "gone"
+	g.Expect(validateResourceNameCollision(policy, node, &SriovNetworkNodePolicyList{})).To(Succeed())
+}
+
+func TestValidateSriovOperatorConfigDaemonNodeSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.ConfigDaemonNodeSelector = map[string]string{"kubernetes.io/os": "linux"}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.ConfigDaemonNodeSelector = map[string]string{"kubernetes.io/os": "windows"}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("configDaemonNodeSelector selects the windows nodes")))
+}
+
+func TestDpdkPrerequisitesWarnings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodeLabels := map[string]string{"feature.node.kubernetes.io/network-sriov.capable": "true"}
+	kubeclient = fakek8s.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: nodeLabels},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{"hugepages-1Gi": resource.MustParse("4Gi")}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: nodeLabels},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("0")}}},
+	)
+	snclient = fakesnclientset.NewSimpleClientset(
+		&SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: namespace},
+			Status: SriovNetworkNodeStateStatus{IsolatedCpus: "2-15"}},
+		&SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: namespace}},
+	)
+
+	policy := newNodePolicy()
+	policy.Spec.DeviceType = constants.DeviceTypeVfioPci
+	g.Expect(dpdkPrerequisitesWarnings(policy)).To(Equal([]string{
+		"the nodes worker-1 selected by the vfio-pci policy p1 have no hugepages, the DPDK workloads of its VFs will fail to start"}))
+
+	policy.Spec.RequireIsolatedCpus = true
+	g.Expect(dpdkPrerequisitesWarnings(policy)).To(ConsistOf(
+		ContainSubstring("the nodes worker-1 selected by the vfio-pci policy p1 have no hugepages"),
+		ContainSubstring("the nodes worker-1 selected by the vfio-pci policy p1 have no isolated CPUs")))
+}
+
+func TestValidateSriovOperatorConfigPolicySignature(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.PolicySignature = &PolicySignatureConfig{Command: []string{"/usr/bin/verify-policy"}}
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.PolicySignature = &PolicySignatureConfig{}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("one of url or command must be set")))
+
+	config.Spec.PolicySignature = &PolicySignatureConfig{URL: "http://verifier"}
+	config.Spec.SoakTest = &SoakTestConfig{NodeSelector: map[string]string{"pool": "soak"}, NumVfs: 1}
+	_, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("soakTest can't be enabled with policySignature")))
+}
+
+func TestValidatePolicySignature(t *testing.T) {
+	g := NewGomegaWithT(t)
+	t.Setenv("NAMESPACE", "openshift-sriov-network-operator")
+
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "openshift-sriov-network-operator"},
+		Spec:       SriovNetworkNodePolicySpec{ResourceName: "p1res", NumVfs: 4},
+	}
+	config := &SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultConfigName, Namespace: "openshift-sriov-network-operator"}}
+
+	// the policies are not verified without a policySignature
+	snclient = fakesnclientset.NewSimpleClientset(config)
+	g.Expect(validatePolicySignature(policy)).To(Succeed())
+
+	config.Spec.PolicySignature = &PolicySignatureConfig{Command: []string{"/bin/sh", "-c", `grep -q '"signature":"trusted"' && echo '{"allowed": true}' || echo '{"allowed": false, "message": "untrusted signer"}'`}}
+	snclient = fakesnclientset.NewSimpleClientset(config)
+	g.Expect(validatePolicySignature(policy)).To(MatchError(ContainSubstring("has no signature")))
+
+	policy.Annotations = map[string]string{constants.PolicySignatureAnnotation: "forged"}
+	g.Expect(validatePolicySignature(policy)).To(MatchError(ContainSubstring("untrusted signer")))
+
+	policy.Annotations[constants.PolicySignatureAnnotation] = "trusted"
+	g.Expect(validatePolicySignature(policy)).To(Succeed())
+
+	// the policies outside of the operator namespace are not applied
+	unused := &SriovNetworkNodePolicy{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}}
+	g.Expect(validatePolicySignature(unused)).To(Succeed())
+}
@@ -1,14 +1,18 @@
 package webhook
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 
 	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/tracing"
 )
 
 var namespace = os.Getenv("NAMESPACE")
@@ -31,9 +35,14 @@ func MutateCustomResource(ar v1.AdmissionReview) *v1.AdmissionResponse {
 		log.Log.Error(err, "failed to unmarshal object")
 		return toV1AdmissionResponse(err)
 	}
+	ctx, span := tracing.Start(context.Background(), "admission.mutate",
+		"kind", ar.Request.Kind.Kind, "name", ar.Request.Name, "operation", string(ar.Request.Operation))
+	defer span.End()
+
 	var reviewResp *v1.AdmissionResponse
-	if reviewResp, err = mutateSriovNetworkNodePolicy(cr); err != nil {
+	if reviewResp, err = mutateSriovNetworkNodePolicy(cr, tracing.Traceparent(ctx)); err != nil {
 		log.Log.Error(err, "failed to mutate object")
+		span.RecordError(err)
 		return toV1AdmissionResponse(err)
 	}
 
@@ -63,10 +72,101 @@ func ValidateCustomResource(ar v1.AdmissionReview) *v1.AdmissionResponse {
 			return toV1AdmissionResponse(err)
 		}
 
+		// the mutating webhook set the traceparent of the admission
+		ctx := tracing.ContextWithTraceparent(context.Background(), policy.Annotations[consts.TraceparentAnnotation])
+		_, span := tracing.Start(ctx, "admission.validate",
+			"kind", ar.Request.Kind.Kind, "name", policy.Name, "operation", string(ar.Request.Operation))
 		if reviewResponse.Allowed, reviewResponse.Warnings, err = validateSriovNetworkNodePolicy(&policy, ar.Request.Operation); err != nil {
 			reviewResponse.Result = &metav1.Status{
 				Reason: metav1.StatusReason(err.Error()),
 			}
+			span.RecordError(err)
+		}
+		// the v2 policies are converted to v1 before their validation, their VF ranges are encoded in the PF names
+		if ar.Request.Operation != v1.Delete && (ar.Request.RequestKind == nil || ar.Request.RequestKind.Version == "v1") {
+			reviewResponse.Warnings = append(reviewResponse.Warnings, policy.DeprecationWarnings()...)
+		}
+		span.End()
+	case "SriovNetwork":
+		// the deletion of the networks is not restricted
+		if ar.Request.Operation == v1.Delete {
+			break
+		}
+		network := sriovnetworkv1.SriovNetwork{}
+
+		err = json.Unmarshal(raw, &network)
+		if err != nil {
+			log.Log.Error(err, "failed to unmarshal object")
+			return toV1AdmissionResponse(err)
+		}
+		if network.Namespace == "" {
+			network.Namespace = ar.Request.Namespace
+		}
+
+		if err = validateSriovNetwork(&network); err != nil {
+			reviewResponse.Allowed = false
+			reviewResponse.Result = &metav1.Status{
+				Reason: metav1.StatusReason(err.Error()),
+			}
+		}
+	case "SriovIBNetwork":
+		if ar.Request.Operation == v1.Delete {
+			break
+		}
+		network := sriovnetworkv1.SriovIBNetwork{}
+
+		err = json.Unmarshal(raw, &network)
+		if err != nil {
+			log.Log.Error(err, "failed to unmarshal object")
+			return toV1AdmissionResponse(err)
+		}
+
+		if err = validateSriovIBNetwork(&network); err != nil {
+			reviewResponse.Allowed = false
+			reviewResponse.Result = &metav1.Status{
+				Reason: metav1.StatusReason(err.Error()),
+			}
+		}
+	case "Pod":
+		// the quotas are only enforced on the creation of the pods
+		if ar.Request.Operation != v1.Create {
+			break
+		}
+		pod := corev1.Pod{}
+
+		err = json.Unmarshal(raw, &pod)
+		if err != nil {
+			log.Log.Error(err, "failed to unmarshal object")
+			return toV1AdmissionResponse(err)
+		}
+		// the namespace is not set in the object of the pods created by a controller
+		if pod.Namespace == "" {
+			pod.Namespace = ar.Request.Namespace
+		}
+
+		if err = validatePodQuota(&pod); err != nil {
+			reviewResponse.Allowed = false
+			reviewResponse.Result = &metav1.Status{
+				Reason: metav1.StatusReason(err.Error()),
+			}
+		}
+	case "SriovNetworkPoolConfig":
+		if ar.Request.Operation == v1.Delete {
+			break
+		}
+		pool := sriovnetworkv1.SriovNetworkPoolConfig{}
+
+		err = json.Unmarshal(raw, &pool)
+		if err != nil {
+			log.Log.Error(err, "failed to unmarshal object")
+			return toV1AdmissionResponse(err)
+		}
+
+		if err = validateSriovNetworkPoolConfig(&pool); err != nil {
+			reviewResponse.Allowed = false
+			reviewResponse.Result = &metav1.Status{
+				Reason: metav1.StatusReason(err.Error()),
+			}
 		}
 	case "SriovOperatorConfig":
 		config := sriovnetworkv1.SriovOperatorConfig{}
@@ -87,6 +187,34 @@ func ValidateCustomResource(ar v1.AdmissionReview) *v1.AdmissionResponse {
 	return &reviewResponse
 }
 
+// ValidatePodNetworks warns on the creation of the pods whose SR-IOV resource requests don't match their network
+// attachments, the pods are always allowed
+func ValidatePodNetworks(ar v1.AdmissionReview) *v1.AdmissionResponse {
+	log.Log.V(2).Info("validating pod networks")
+	reviewResponse := v1.AdmissionResponse{Allowed: true}
+	if ar.Request.Kind.Kind != "Pod" || ar.Request.Operation != v1.Create {
+		return &reviewResponse
+	}
+
+	pod := corev1.Pod{}
+	if err := json.Unmarshal(ar.Request.Object.Raw, &pod); err != nil {
+		log.Log.Error(err, "failed to unmarshal object")
+		return &reviewResponse
+	}
+	// the namespace is not set in the object of the pods created by a controller
+	if pod.Namespace == "" {
+		pod.Namespace = ar.Request.Namespace
+	}
+
+	warnings, err := podNetworkWarnings(&pod)
+	if err != nil {
+		log.Log.Error(err, "failed to validate the pod networks", "namespace", pod.Namespace, "name", pod.Name)
+		return &reviewResponse
+	}
+	reviewResponse.Warnings = warnings
+	return &reviewResponse
+}
+
 func toV1AdmissionResponse(err error) *v1.AdmissionResponse {
 	return &v1.AdmissionResponse{
 		Result: &metav1.Status{
@@ -0,0 +1,82 @@
+package fakemetadata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Server is a fake cloud metadata service serving fixed documents, used to test the platforms reading
+// their metadata from an HTTP endpoint.
+// Example usage:
+//
+// ```
+// server := fakemetadata.New(map[string]string{"/openstack/latest/meta_data.json": `{"devices": []}`})
+// defer server.Close()
+// server.SetStatus("/openstack/latest/meta_data.json", http.StatusServiceUnavailable)
+// ```
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	documents map[string]string
+	statuses  map[string]int
+	requests  map[string]int
+}
+
+// New starts a fake metadata service serving the documents keyed by their path, the other paths are not found
+func New(documents map[string]string) *Server {
+	s := &Server{
+		documents: map[string]string{},
+		statuses:  map[string]int{},
+		requests:  map[string]int{},
+	}
+	for path, body := range documents {
+		s.documents[path] = body
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// SetDocument serves body at path
+func (s *Server) SetDocument(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[path] = body
+}
+
+// SetStatus answers the requests of path with the given status code instead of the document,
+// http.StatusOK serves the document again
+func (s *Server) SetStatus(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == http.StatusOK {
+		delete(s.statuses, path)
+		return
+	}
+	s.statuses[path] = status
+}
+
+// Requests returns the number of requests received for path
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	status, failing := s.statuses[r.URL.Path]
+	body, exists := s.documents[r.URL.Path]
+	s.mu.Unlock()
+
+	switch {
+	case failing:
+		w.WriteHeader(status)
+	case !exists:
+		http.NotFound(w, r)
+	default:
+		_, _ = w.Write([]byte(body))
+	}
+}